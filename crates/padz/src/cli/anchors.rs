@@ -0,0 +1,111 @@
+//! Where code anchors point on this machine.
+//!
+//! `padzapp` stores an anchor's path as an opaque string. The CLI decides what
+//! it means: a file the user names is taken relative to the working directory,
+//! then recorded relative to the project root — the directory holding `.padz`
+//! — so the note still points at the right file from any subdirectory, or
+//! from another clone of the repository. Files outside the project are
+//! recorded by absolute path.
+//!
+//! Paths are cleaned lexically rather than canonicalized, so an anchor can be
+//! removed after its file is gone and a symlinked checkout is not rewritten to
+//! its target.
+
+use padzapp::model::CodeAnchor;
+use std::path::{Component, Path, PathBuf};
+
+/// Turns an anchor as typed at `cwd` into the form stored on the pad.
+///
+/// When `must_exist` is set, a path that names no file is an error: linking a
+/// typo would leave a note pointing nowhere.
+pub fn to_stored(
+    anchor: CodeAnchor,
+    cwd: &Path,
+    project_root: &Path,
+    must_exist: bool,
+) -> Result<CodeAnchor, String> {
+    let absolute = clean(&cwd.join(&anchor.path));
+    if must_exist && !absolute.is_file() {
+        return Err(format!("No such file: {}", anchor.path));
+    }
+    let path = match absolute.strip_prefix(clean(project_root)) {
+        Ok(relative) => relative.to_path_buf(),
+        Err(_) => absolute,
+    };
+    Ok(CodeAnchor {
+        path: path.to_string_lossy().replace('\\', "/"),
+        line: anchor.line,
+    })
+}
+
+/// The file a stored anchor refers to.
+pub fn to_file(anchor: &CodeAnchor, project_root: &Path) -> PathBuf {
+    project_root.join(&anchor.path)
+}
+
+/// Resolves `.` and `..` without touching the filesystem.
+fn clean(path: &Path) -> PathBuf {
+    let mut out = PathBuf::new();
+    for component in path.components() {
+        match component {
+            Component::CurDir => {}
+            Component::ParentDir => {
+                out.pop();
+            }
+            other => out.push(other),
+        }
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn anchor(s: &str) -> CodeAnchor {
+        s.parse().unwrap()
+    }
+
+    #[test]
+    fn paths_are_stored_relative_to_the_project_root() {
+        let root = Path::new("/work/repo");
+        let cwd = Path::new("/work/repo/src/net");
+
+        let stored = to_stored(anchor("tls.go:42"), cwd, root, false).unwrap();
+        assert_eq!(stored.to_string(), "src/net/tls.go:42");
+
+        let stored = to_stored(anchor("../../README.md"), cwd, root, false).unwrap();
+        assert_eq!(stored.to_string(), "README.md");
+    }
+
+    #[test]
+    fn paths_outside_the_project_stay_absolute() {
+        let root = Path::new("/work/repo");
+        let stored = to_stored(anchor("../other/x.rs:3"), root, root, false).unwrap();
+        assert_eq!(stored.to_string(), "/work/other/x.rs:3");
+    }
+
+    #[test]
+    fn a_missing_file_is_refused_only_when_required() {
+        let temp = tempfile::tempdir().unwrap();
+        std::fs::write(temp.path().join("real.rs"), "").unwrap();
+
+        assert!(to_stored(anchor("real.rs:1"), temp.path(), temp.path(), true).is_ok());
+        let err = to_stored(anchor("typo.rs:1"), temp.path(), temp.path(), true).unwrap_err();
+        assert!(err.contains("typo.rs"), "got: {err}");
+        assert!(to_stored(anchor("typo.rs:1"), temp.path(), temp.path(), false).is_ok());
+    }
+
+    #[test]
+    fn stored_paths_resolve_against_the_project_root() {
+        let root = Path::new("/work/repo");
+        assert_eq!(
+            to_file(&anchor("src/main.rs:9"), root),
+            PathBuf::from("/work/repo/src/main.rs")
+        );
+        assert_eq!(
+            to_file(&anchor("/etc/hosts"), root),
+            PathBuf::from("/etc/hosts")
+        );
+    }
+}
@@ -0,0 +1,177 @@
+//! What this invocation can ask of the person running it.
+//!
+//! Under cron or CI nobody is at the other end: stdin is not a terminal to
+//! answer from and stdout is not one to draw on. Rather than every prompt and
+//! editor launch probing the process for itself, `create_app_state` detects
+//! the terminal once ([`Capabilities::detect`]) and hands the result to the
+//! handlers in [`AppState`](super::handlers::AppState). Without a terminal:
+//!
+//! - the editor is not opened; the command fails saying how to pass content
+//!   instead ([`Capabilities::ensure_editor`]);
+//! - nothing is asked: the lint re-open question is skipped and `fill` names
+//!   the values it needs `--set` for ([`Capabilities::can_prompt`]);
+//! - the default `auto` output draws plain text, with no color
+//!   ([`Capabilities::output_mode`]).
+//!
+//! With one, listed pads are links only if the terminal is known to open OSC 8
+//! hyperlinks ([`Capabilities::hyperlinks`]); others would print the escape
+//! sequence as text or drop it, and there is no asking a terminal which.
+//!
+//! Destructive commands never ask in the first place — `purge` and
+//! `scopes prune` act only with `--yes` — so they behave the same either way.
+//! padz has no pager.
+
+use standout::OutputMode;
+use std::io::IsTerminal;
+
+/// Whether stdin and stdout are terminals.
+///
+/// The default is the non-interactive one, so an `AppState` built in-process
+/// (as the tests build theirs) never blocks on a prompt or an editor.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub struct Capabilities {
+    /// Stdin is a terminal someone can type answers into.
+    pub stdin_tty: bool,
+    /// Stdout is a terminal, so output is read by a person.
+    pub stdout_tty: bool,
+    /// Stdout is a terminal that opens OSC 8 hyperlinks.
+    pub hyperlinks: bool,
+}
+
+impl Capabilities {
+    /// The capabilities of this process.
+    pub fn detect() -> Self {
+        let stdout_tty = std::io::stdout().is_terminal();
+        Self {
+            stdin_tty: std::io::stdin().is_terminal(),
+            stdout_tty,
+            hyperlinks: stdout_tty && opens_links(|name| std::env::var(name).ok()),
+        }
+    }
+
+    /// Both ends are terminals.
+    pub fn interactive() -> Self {
+        Self {
+            stdin_tty: true,
+            stdout_tty: true,
+            hyperlinks: false,
+        }
+    }
+
+    /// Neither end is a terminal.
+    pub fn non_interactive() -> Self {
+        Self::default()
+    }
+
+    /// Whether a question on stderr can be answered on stdin.
+    pub fn can_prompt(&self) -> bool {
+        self.stdin_tty
+    }
+
+    /// Whether a terminal editor has a terminal to run in.
+    pub fn can_open_editor(&self) -> bool {
+        self.stdin_tty && self.stdout_tty
+    }
+
+    /// Refuse to open the editor without a terminal, saying what to do instead.
+    pub fn ensure_editor(&self) -> Result<(), anyhow::Error> {
+        if !self.can_open_editor() {
+            anyhow::bail!(
+                "Not running in a terminal, so padz cannot open the editor; \
+                 pass the content as arguments or on stdin"
+            );
+        }
+        Ok(())
+    }
+
+    /// The output mode to render with: `auto` becomes plain `text` when
+    /// stdout is not a terminal. An explicit `--output` is left alone.
+    pub fn output_mode(&self, requested: OutputMode) -> OutputMode {
+        match requested {
+            OutputMode::Auto if !self.stdout_tty => OutputMode::Text,
+            other => other,
+        }
+    }
+}
+
+/// Whether the terminal the environment describes opens OSC 8 links. Only the
+/// ones known to are trusted; `FORCE_HYPERLINK` settles it either way.
+fn opens_links(var: impl Fn(&str) -> Option<String>) -> bool {
+    if let Some(force) = var("FORCE_HYPERLINK") {
+        return force != "0";
+    }
+    let term = var("TERM").unwrap_or_default();
+    if term == "dumb" {
+        return false;
+    }
+    let vte = var("VTE_VERSION").and_then(|v| v.parse::<u32>().ok());
+    matches!(
+        var("TERM_PROGRAM").as_deref(),
+        Some("iTerm.app" | "WezTerm" | "vscode" | "ghostty")
+    ) || matches!(term.as_str(), "xterm-kitty" | "xterm-ghostty" | "foot")
+        || ["WT_SESSION", "KONSOLE_VERSION", "KITTY_WINDOW_ID", "DOMTERM"]
+            .iter()
+            .any(|name| var(name).is_some())
+        // VTE terminals (GNOME Terminal, Tilix, ...) open links since 0.50.
+        || vte.is_some_and(|version| version >= 5000)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn without_a_terminal_nothing_is_asked_or_opened() {
+        let caps = Capabilities::non_interactive();
+        assert!(!caps.can_prompt());
+        let err = caps.ensure_editor().unwrap_err();
+        assert!(err.to_string().contains("on stdin"), "got: {err}");
+        assert!(Capabilities::interactive().ensure_editor().is_ok());
+    }
+
+    #[test]
+    fn a_redirected_stdout_still_allows_prompting() {
+        let caps = Capabilities {
+            stdin_tty: true,
+            stdout_tty: false,
+            hyperlinks: false,
+        };
+        assert!(caps.can_prompt());
+        assert!(!caps.can_open_editor());
+    }
+
+    #[test]
+    fn auto_output_is_plain_text_off_a_terminal() {
+        let caps = Capabilities::non_interactive();
+        assert_eq!(caps.output_mode(OutputMode::Auto), OutputMode::Text);
+        assert_eq!(caps.output_mode(OutputMode::Term), OutputMode::Term);
+        assert_eq!(caps.output_mode(OutputMode::Json), OutputMode::Json);
+        assert_eq!(
+            Capabilities::interactive().output_mode(OutputMode::Auto),
+            OutputMode::Auto
+        );
+    }
+
+    #[test]
+    fn only_known_terminals_get_links() {
+        let env = |vars: &'static [(&'static str, &'static str)]| {
+            move |name: &str| {
+                vars.iter()
+                    .find(|(key, _)| *key == name)
+                    .map(|(_, value)| value.to_string())
+            }
+        };
+        assert!(opens_links(env(&[("TERM_PROGRAM", "WezTerm")])));
+        assert!(opens_links(env(&[("VTE_VERSION", "7200")])));
+        assert!(!opens_links(env(&[("VTE_VERSION", "4600")])));
+        assert!(!opens_links(env(&[("TERM", "xterm-256color")])));
+        assert!(opens_links(env(&[
+            ("TERM", "xterm"),
+            ("FORCE_HYPERLINK", "1")
+        ])));
+        assert!(!opens_links(env(&[
+            ("TERM_PROGRAM", "iTerm.app"),
+            ("FORCE_HYPERLINK", "0")
+        ])));
+    }
+}
@@ -0,0 +1,34 @@
+//! `padz create --from "<command>"`: a command's output as a new pad.
+//!
+//! The command runs through `sh -c` in the current directory, within
+//! `command_timeout`, and what it printed (stdout, then stderr) becomes the
+//! pad's body. A failing command's output is kept like any other: the log of a
+//! failed deploy is as often the thing worth curating. Only a command that
+//! could not be started, or ran out of time, is an error.
+
+use super::subprocess::{self, Limit};
+use padzapp::error::Result;
+use std::process::Command;
+
+/// Runs `command` and returns what it printed, trailing whitespace trimmed.
+pub fn run(command: &str) -> Result<String> {
+    let output = subprocess::output(
+        Command::new("sh").arg("-c").arg(command),
+        Limit::Command,
+        &format!("'{}'", command),
+    )?;
+    let mut printed = String::from_utf8_lossy(&output.stdout).into_owned();
+    printed.push_str(&String::from_utf8_lossy(&output.stderr));
+    Ok(printed.trim_end().to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn output_is_kept_whether_the_command_succeeds_or_not() {
+        assert_eq!(run("printf 'a\\nb\\n\\n'").unwrap(), "a\nb");
+        assert_eq!(run("echo out; echo err >&2; exit 3").unwrap(), "out\nerr");
+    }
+}
@@ -1,18 +1,34 @@
-//! Writing the system clipboard.
+//! The system clipboard.
 //!
-//! A user-environment concern owned by the CLI: each supported platform shells
-//! out to its clipboard *write* tool (`pbcopy`, `xclip`/`xsel`, `clip`); any
-//! other platform is an error. The library never touches the clipboard — the
-//! CLI injects a [`ClipboardWriter`] into application state and handlers hand it
-//! semantic pad text at that boundary.
+//! A user-environment concern owned by the CLI. Each platform has a short list
+//! of clipboard [`Tool`]s (`pbcopy`/`pbpaste`, `wl-copy`, `xclip`, `xsel`,
+//! `clip`/PowerShell), tried in order, each run within `command_timeout` (see
+//! [`super::subprocess`]). Over SSH those tools would reach the remote
+//! machine's clipboard, not the user's, so writes go to the terminal instead as
+//! an OSC 52 escape sequence, which most terminal emulators (and tmux, passed
+//! through) put on the local clipboard. The same sequence is the fallback when
+//! no tool is installed.
 //!
-//! Write-only by design: padz copies pad text *to* the clipboard after a pad is
-//! saved or viewed, and never reads it back to pre-fill one. There is no
-//! clipboard read API here — see the `cli::input` docs for why the clipboard is
-//! not an input source.
+//! That choice is the [`Provider::Auto`] one. The `clipboard` setting names a
+//! [`Provider`] instead — `osc52`, or one tool by its copy command, such as
+//! `xclip` — for when detection guesses wrong: a terminal that handles OSC 52
+//! outside SSH, or a forwarded X display inside it.
+//!
+//! The library never touches the clipboard — the CLI injects a
+//! [`ClipboardWriter`] and a [`ClipboardReader`] into application state and
+//! handlers hand them semantic pad text at that boundary.
+//!
+//! padz copies pad text *to* the clipboard after a pad is saved or viewed. It
+//! reads the clipboard only when a command is told to, with
+//! `create --from-clipboard` or `copy --append`; the clipboard is never an
+//! implicit input source (see the `cli::input` docs). OSC 52 reads are left
+//! alone: terminals that allow them at all ask first, so over SSH a read is an
+//! error.
 
+use super::subprocess::{self, Limit};
 use padzapp::error::{PadzError, Result};
-use std::process::Command;
+use std::io::{IsTerminal, Write};
+use std::process::{Command, Stdio};
 
 /// A CLI-owned destination for semantic clipboard payloads.
 ///
@@ -23,143 +39,307 @@ pub trait ClipboardWriter {
     fn write(&self, text: &str) -> Result<()>;
 }
 
+/// A CLI-owned source of clipboard text, read only on request.
+pub trait ClipboardReader {
+    fn read(&self) -> Result<String>;
+}
+
 /// The real platform clipboard used by ordinary Padz invocations.
 #[derive(Debug, Default)]
-pub struct SystemClipboardWriter;
+pub struct SystemClipboardWriter(pub Provider);
 
 impl ClipboardWriter for SystemClipboardWriter {
     fn write(&self, text: &str) -> Result<()> {
-        copy_to_clipboard(text)
+        copy(self.0, text)
     }
 }
 
-/// Copies text to the system clipboard in an OS-specific way.
-/// - macOS: uses pbcopy
-/// - Linux: uses xclip or xsel
-/// - Windows: uses clip.exe
-pub fn copy_to_clipboard(text: &str) -> Result<()> {
-    #[cfg(target_os = "macos")]
-    {
-        copy_macos(text)
+/// Reads the real platform clipboard.
+#[derive(Debug, Default)]
+pub struct SystemClipboardReader(pub Provider);
+
+impl ClipboardReader for SystemClipboardReader {
+    fn read(&self) -> Result<String> {
+        paste(self.0)
     }
+}
 
-    #[cfg(target_os = "linux")]
-    {
-        copy_linux(text)
+/// A destination that drops every payload, for `--test-mode` runs: a black-box
+/// test must not overwrite the clipboard of the machine it runs on. Read, it is
+/// empty, for the same reason.
+#[derive(Debug, Default)]
+pub struct DiscardingClipboardWriter;
+
+impl ClipboardWriter for DiscardingClipboardWriter {
+    fn write(&self, _text: &str) -> Result<()> {
+        Ok(())
     }
+}
 
-    #[cfg(target_os = "windows")]
-    {
-        copy_windows(text)
+impl ClipboardReader for DiscardingClipboardWriter {
+    fn read(&self) -> Result<String> {
+        Ok(String::new())
     }
+}
 
-    #[cfg(not(any(target_os = "macos", target_os = "linux", target_os = "windows")))]
-    {
-        Err(PadzError::Api(
-            "Clipboard not supported on this platform".to_string(),
-        ))
+/// How padz reaches the clipboard.
+#[derive(Debug, Clone, Copy, Default)]
+pub enum Provider {
+    /// Detect it: the terminal over SSH, otherwise the first tool that runs,
+    /// falling back to the terminal.
+    #[default]
+    Auto,
+    /// One clipboard tool, and no other.
+    Tool(&'static Tool),
+    /// The terminal, through OSC 52. It can write the clipboard, not read it.
+    Osc52,
+}
+
+impl Provider {
+    /// The provider the `clipboard` setting names: `auto`, `osc52`, or a
+    /// tool's copy command among this platform's.
+    pub fn named(name: &str) -> Result<Self> {
+        match name {
+            "auto" => return Ok(Provider::Auto),
+            "osc52" => return Ok(Provider::Osc52),
+            _ => {}
+        }
+        TOOLS
+            .iter()
+            .find(|tool| tool.copy[0] == name)
+            .map(Provider::Tool)
+            .ok_or_else(|| {
+                let mut names = vec!["auto", "osc52"];
+                names.extend(TOOLS.iter().map(|tool| tool.copy[0]));
+                PadzError::Api(format!(
+                    "Unknown clipboard provider '{}'; use one of: {}",
+                    name,
+                    names.join(", ")
+                ))
+            })
     }
 }
 
+/// A clipboard command-line tool: the command that writes its stdin to the
+/// clipboard, and the one that prints the clipboard.
+#[derive(Debug)]
+pub struct Tool {
+    copy: &'static [&'static str],
+    paste: &'static [&'static str],
+}
+
 #[cfg(target_os = "macos")]
-fn copy_macos(text: &str) -> Result<()> {
-    use std::io::Write;
-    use std::process::Stdio;
+const TOOLS: &[Tool] = &[Tool {
+    copy: &["pbcopy"],
+    paste: &["pbpaste"],
+}];
+
+#[cfg(target_os = "linux")]
+const TOOLS: &[Tool] = &[
+    Tool {
+        copy: &["wl-copy"],
+        paste: &["wl-paste", "--no-newline"],
+    },
+    Tool {
+        copy: &["xclip", "-selection", "clipboard"],
+        paste: &["xclip", "-selection", "clipboard", "-o"],
+    },
+    Tool {
+        copy: &["xsel", "--clipboard", "--input"],
+        paste: &["xsel", "--clipboard", "--output"],
+    },
+];
 
-    let mut child = Command::new("pbcopy")
+#[cfg(target_os = "windows")]
+const TOOLS: &[Tool] = &[Tool {
+    copy: &["clip"],
+    paste: &["powershell", "-NoProfile", "-Command", "Get-Clipboard"],
+}];
+
+#[cfg(not(any(target_os = "macos", target_os = "linux", target_os = "windows")))]
+const TOOLS: &[Tool] = &[];
+
+/// The tools worth trying here. `wl-copy` needs a Wayland session, and the X
+/// tools an X display; without either they fail slowly or not at all.
+fn tools() -> impl Iterator<Item = &'static Tool> {
+    TOOLS.iter().filter(|tool| match tool.copy[0] {
+        "wl-copy" => std::env::var_os("WAYLAND_DISPLAY").is_some(),
+        "xclip" | "xsel" => std::env::var_os("DISPLAY").is_some(),
+        _ => true,
+    })
+}
+
+/// Whether padz runs in an SSH session, where the clipboard tools reach the
+/// wrong machine.
+fn over_ssh() -> bool {
+    std::env::var_os("SSH_TTY").is_some() || std::env::var_os("SSH_CONNECTION").is_some()
+}
+
+/// Copies text to the clipboard through `provider`. Detected, that is the
+/// first clipboard tool that runs, or an OSC 52 sequence over SSH or when none
+/// does.
+fn copy(provider: Provider, text: &str) -> Result<()> {
+    match provider {
+        Provider::Osc52 => {
+            if !std::io::stderr().is_terminal() {
+                return Err(PadzError::Api(
+                    "Cannot write the clipboard through OSC 52: stderr is not a terminal"
+                        .to_string(),
+                ));
+            }
+            write_osc52(text)
+        }
+        Provider::Tool(tool) => {
+            copy_with(tool, text).unwrap_or_else(|| Err(not_installed(tool.copy[0])))
+        }
+        Provider::Auto => {
+            if over_ssh() {
+                return copy_osc52(text);
+            }
+            for tool in tools() {
+                if let Some(copied) = copy_with(tool, text) {
+                    return copied;
+                }
+            }
+            copy_osc52(text)
+        }
+    }
+}
+
+/// Runs `tool`'s copy command on `text`; `None` when it is not installed.
+fn copy_with(tool: &Tool, text: &str) -> Option<Result<()>> {
+    let program = tool.copy[0];
+    let child = Command::new(program)
+        .args(&tool.copy[1..])
         .stdin(Stdio::piped())
         .spawn()
-        .map_err(|e| PadzError::Api(format!("Failed to spawn pbcopy: {}", e)))?;
+        .ok()?;
+    Some(feed(child, program, text))
+}
 
+fn feed(mut child: std::process::Child, program: &str, text: &str) -> Result<()> {
     if let Some(mut stdin) = child.stdin.take() {
         stdin
             .write_all(text.as_bytes())
-            .map_err(|e| PadzError::Api(format!("Failed to write to pbcopy: {}", e)))?;
+            .map_err(|e| PadzError::Api(format!("Failed to write to {}: {}", program, e)))?;
     }
-
-    let status = child
-        .wait()
-        .map_err(|e| PadzError::Api(format!("Failed to wait for pbcopy: {}", e)))?;
-
+    let status = subprocess::wait(child, Limit::Command, program)?.status;
     if status.success() {
         Ok(())
     } else {
-        Err(PadzError::Api("pbcopy exited with error".to_string()))
+        Err(PadzError::Api(format!("{} exited with error", program)))
     }
 }
 
-#[cfg(target_os = "linux")]
-fn copy_linux(text: &str) -> Result<()> {
-    use std::io::Write;
-    use std::process::Stdio;
-
-    // Try xclip first, then xsel
-    let result = Command::new("xclip")
-        .args(["-selection", "clipboard"])
-        .stdin(Stdio::piped())
-        .spawn();
-
-    let mut child = match result {
-        Ok(child) => child,
-        Err(_) => {
-            // Try xsel as fallback
-            Command::new("xsel")
-                .args(["--clipboard", "--input"])
-                .stdin(Stdio::piped())
-                .spawn()
-                .map_err(|e| {
-                    PadzError::Api(format!(
-                        "Failed to spawn xclip or xsel: {}. Install xclip or xsel.",
-                        e
-                    ))
-                })?
+/// Reads the clipboard through `provider`. Detected, that is the first
+/// clipboard tool that runs.
+fn paste(provider: Provider) -> Result<String> {
+    match provider {
+        Provider::Osc52 => Err(PadzError::Api(
+            "Cannot read the clipboard through OSC 52; pipe the text in instead".to_string(),
+        )),
+        Provider::Tool(tool) => {
+            paste_with(tool).unwrap_or_else(|| Err(not_installed(tool.paste[0])))
         }
-    };
+        Provider::Auto => {
+            if over_ssh() {
+                return Err(PadzError::Api(
+                    "Cannot read the clipboard over SSH; pipe the text in instead".to_string(),
+                ));
+            }
+            for tool in tools() {
+                if let Some(pasted) = paste_with(tool) {
+                    return pasted;
+                }
+            }
+            Err(PadzError::Api(no_tool_message("read")))
+        }
+    }
+}
 
-    if let Some(mut stdin) = child.stdin.take() {
-        stdin
-            .write_all(text.as_bytes())
-            .map_err(|e| PadzError::Api(format!("Failed to write to clipboard: {}", e)))?;
+/// Runs `tool`'s paste command; `None` when it is not installed.
+fn paste_with(tool: &Tool) -> Option<Result<String>> {
+    let program = tool.paste[0];
+    let mut command = Command::new(program);
+    command.args(&tool.paste[1..]);
+    let output = match subprocess::output(&mut command, Limit::Command, program) {
+        Ok(output) => output,
+        Err(PadzError::Api(_)) => return None,
+        Err(e) => return Some(Err(e)),
+    };
+    // wl-paste and xclip fail on an empty clipboard rather than print
+    // nothing.
+    if !output.status.success() {
+        return Some(Ok(String::new()));
     }
+    Some(Ok(String::from_utf8_lossy(&output.stdout).into_owned()))
+}
 
-    let status = child
-        .wait()
-        .map_err(|e| PadzError::Api(format!("Failed to wait for clipboard command: {}", e)))?;
+fn not_installed(program: &str) -> PadzError {
+    PadzError::Api(format!(
+        "The clipboard setting names {}, which is not installed",
+        program
+    ))
+}
 
-    if status.success() {
-        Ok(())
+fn no_tool_message(what: &str) -> String {
+    let names: Vec<&str> = TOOLS.iter().map(|tool| tool.copy[0]).collect();
+    if names.is_empty() {
+        "Clipboard not supported on this platform".to_string()
     } else {
-        Err(PadzError::Api(
-            "Clipboard command exited with error".to_string(),
-        ))
+        format!(
+            "No clipboard tool to {} the clipboard with; install one of: {}",
+            what,
+            names.join(", ")
+        )
     }
 }
 
-#[cfg(target_os = "windows")]
-fn copy_windows(text: &str) -> Result<()> {
-    use std::io::Write;
-    use std::process::Stdio;
-
-    let mut child = Command::new("clip")
-        .stdin(Stdio::piped())
-        .spawn()
-        .map_err(|e| PadzError::Api(format!("Failed to spawn clip: {}", e)))?;
-
-    if let Some(mut stdin) = child.stdin.take() {
-        stdin
-            .write_all(text.as_bytes())
-            .map_err(|e| PadzError::Api(format!("Failed to write to clip: {}", e)))?;
+/// Asks the terminal to put `text` on the clipboard. The OSC 52 sequence goes
+/// to stderr, so it never lands in output redirected to a file.
+fn copy_osc52(text: &str) -> Result<()> {
+    if !std::io::stderr().is_terminal() {
+        return Err(PadzError::Api(no_tool_message("write")));
     }
+    write_osc52(text)
+}
 
-    let status = child
-        .wait()
-        .map_err(|e| PadzError::Api(format!("Failed to wait for clip: {}", e)))?;
+fn write_osc52(text: &str) -> Result<()> {
+    let tmux = std::env::var_os("TMUX").is_some();
+    std::io::stderr()
+        .write_all(osc52(text, tmux).as_bytes())
+        .map_err(PadzError::Io)
+}
 
-    if status.success() {
-        Ok(())
+/// The OSC 52 sequence setting the clipboard to `text`, wrapped for tmux to
+/// pass through to the outer terminal when `tmux` is set.
+fn osc52(text: &str, tmux: bool) -> String {
+    let sequence = format!("\x1b]52;c;{}\x07", base64(text.as_bytes()));
+    if tmux {
+        format!("\x1bPtmux;{}\x1b\\", sequence.replace('\x1b', "\x1b\x1b"))
     } else {
-        Err(PadzError::Api("clip exited with error".to_string()))
+        sequence
+    }
+}
+
+/// Standard, padded base64: all OSC 52 needs, without another dependency.
+fn base64(bytes: &[u8]) -> String {
+    const ALPHABET: &[u8; 64] = b"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/";
+    let mut out = String::with_capacity(bytes.len().div_ceil(3) * 4);
+    for chunk in bytes.chunks(3) {
+        let n = chunk
+            .iter()
+            .enumerate()
+            .fold(0u32, |n, (i, &b)| n | ((b as u32) << (16 - 8 * i)));
+        for i in 0..4 {
+            if i <= chunk.len() {
+                out.push(ALPHABET[((n >> (18 - 6 * i)) & 63) as usize] as char);
+            } else {
+                out.push('=');
+            }
+        }
     }
+    out
 }
 
 /// Formats pad content for clipboard (title + blank line + content)
@@ -181,10 +361,38 @@ mod tests {
         assert_eq!(result, "My Title\n\nSome content");
     }
 
+    #[test]
+    fn osc52_carries_the_text_in_base64() {
+        assert_eq!(base64(b""), "");
+        assert_eq!(base64(b"pad"), "cGFk");
+        assert_eq!(base64(b"padz"), "cGFkeg==");
+        assert_eq!(base64(b"padz!"), "cGFkeiE=");
+        assert_eq!(osc52("padz", false), "\x1b]52;c;cGFkeg==\x07");
+        assert_eq!(
+            osc52("padz", true),
+            "\x1bPtmux;\x1b\x1b]52;c;cGFkeg==\x07\x1b\\"
+        );
+    }
+
+    #[test]
+    fn the_clipboard_setting_names_a_provider() {
+        assert!(matches!(Provider::named("auto"), Ok(Provider::Auto)));
+        assert!(matches!(Provider::named("osc52"), Ok(Provider::Osc52)));
+        for tool in TOOLS {
+            assert!(matches!(
+                Provider::named(tool.copy[0]),
+                Ok(Provider::Tool(_))
+            ));
+        }
+        let err = Provider::named("xclipboard").unwrap_err().to_string();
+        assert!(err.contains("Unknown clipboard provider 'xclipboard'"));
+        assert!(err.contains("osc52"));
+    }
+
     /// A real platform clipboard write.
     ///
-    /// This module is write-only, so the assertion is that the copy succeeds —
-    /// the text is not read back. Ignored by default: it depends on a working
+    /// The assertion is that the copy succeeds — the text is not read back,
+    /// which would take a second tool and prove less about the first. Ignored by default: it depends on a working
     /// clipboard tool (and, on Linux, an X display), which CI has no business
     /// requiring. Run it with `cargo test -p padz -- --ignored` on a desktop to
     /// check this adapter against the actual system clipboard.
@@ -192,7 +400,7 @@ mod tests {
     #[ignore = "requires a working system clipboard"]
     fn copy_writes_to_the_system_clipboard() {
         let text = "padz clipboard adapter test";
-        copy_to_clipboard(text).unwrap();
+        SystemClipboardWriter::default().write(text).unwrap();
     }
 
     #[test]
@@ -16,11 +16,17 @@
 //! 4. **Output Formatting**: Use standout templates for rendering
 //! 5. **Error Handling**: Convert errors to user-friendly messages and exit codes
 
+use super::deprecation;
 use super::handlers::AppState;
-use super::render::{peek_filter, terminal_provider, timeago_filter, TERMINAL};
+use super::object_store::GlobalStoreSync;
+use super::progress::TerminalProgress;
+use super::render::{
+    link_filter, narrow_function, peek_filter, terminal_provider, time_format_function,
+    timeago_filter, TERMINAL,
+};
 use super::setup::{
     build_command, get_grouped_help, invocation_default_command, parse_cli, Cli, Commands,
-    CompletionAction, CompletionShell, ConfigSubcommand,
+    CompletionAction, CompletionShell, ConfigSubcommand, DocsAction,
 };
 use clapfig::{Clapfig, ConfigAction, SearchMode, SearchPath};
 use padzapp::config::PadzConfig;
@@ -28,6 +34,7 @@ use padzapp::error::Result;
 use padzapp::init::initialize;
 use standout::cli::{App, RunResult};
 use standout::{embed_styles, embed_templates, MiniJinjaEngine};
+use std::io::IsTerminal;
 
 pub fn run() -> Result<()> {
     // Install padz's terminal-width policy before any rendering. Since 7.9.1 the
@@ -36,34 +43,169 @@ pub fn run() -> Result<()> {
     // clamp, ⏲ payback) without naming a width. Set only on the real binary path;
     // `TestHarness` injects its own width per test.
     standout_render::set_terminal_width_detector(super::render::detect_width);
+    super::interrupt::install();
 
     // parse_cli() uses standout's App which handles
     // help display (including topics) and errors automatically.
     // It also extracts the output mode from the --output flag.
-    let (cli, output_mode) = parse_cli();
+    let (cli, output_mode, command, options) = parse_cli();
+
+    // Commands that need no store are answered before one is opened
+    if let Some(result) = run_without_store(&cli) {
+        return result;
+    }
 
-    // Handle completion before context init (it doesn't need API)
-    if let Some(Commands::Completion { shell, action }) = &cli.command {
-        return match action {
-            CompletionAction::Install => handle_install(*shell),
-            CompletionAction::Print => handle_print(*shell),
-        };
+    // `explain` opens the store for the command it explains, as a dry run
+    if let Some(Commands::Explain { command }) = &cli.command {
+        return super::explain::run(&cli, command);
+    }
+
+    // `mcp` answers its client until the client hangs up, then uploads what
+    // it wrote to a global store in a bucket, as a command does
+    if let Some(Commands::Mcp {}) = &cli.command {
+        let mut app_state = create_app_state(&cli)?;
+        let global_sync = app_state.global_sync.take();
+        let served = super::mcp::serve(
+            &app_state,
+            std::io::stdin().lock(),
+            std::io::stdout().lock(),
+        );
+        if let Some(sync) = &global_sync {
+            sync.push();
+        }
+        return served;
     }
 
-    // Handle config via clapfig (needs paths but not full API)
-    if let Some(Commands::Config { action }) = &cli.command {
-        return handle_config(&cli, action);
+    // `list --watch` draws the listing until Ctrl-C, each time from a store
+    // opened afresh
+    if let Some(Commands::List { watch: true, .. }) = &cli.command {
+        return watch_listing(&cli, output_mode);
     }
 
     // Initialize app state for handlers
-    let app_state = create_app_state(&cli)?;
+    let mut app_state = create_app_state(&cli)?;
+    let global_sync = app_state.global_sync.take();
+    let global_dir = app_state.with_api(|api| api.paths().global.clone());
+
+    // Opt-in local usage counting. Best-effort: a usage file that cannot be
+    // written must never stand between the user and their command.
+    if app_state.usage_stats && !cli.dry_run {
+        if let Some(name) = &command {
+            let _ = app_state.with_api(|api| api.record_usage(name, &options));
+        }
+    }
+
+    // The opt-in release check is for someone at a terminal; under a pipe, cron
+    // or `--test-mode` it stays quiet and off the network.
+    let check_for_update = app_state.update_check
+        && app_state.capabilities.stdout_tty
+        && !matches!(cli.command, Some(Commands::SelfUpdate { .. }));
 
     // The same invocation-aware resolver ran during `parse_cli`, so the first
     // parse and this stateful dispatch parse agree without local argv surgery.
+    // Off a terminal, `auto` output is plain text.
+    let output_mode = app_state.capabilities.output_mode(output_mode);
+    show_links(&app_state, output_mode);
+    super::render::use_time_format(app_state.list.time_format);
     let app = build_dispatch_app(app_state);
     let cmd = build_command();
     let matches = app.parse_from(cmd, std::env::args());
-    handle_dispatch_result(app.dispatch(matches, output_mode))
+    let result = handle_dispatch_result(app.dispatch(matches, output_mode));
+    // Upload even after a failure: whatever the command did write is local
+    // now, and should not wait for the next global command to reach the bucket.
+    // A global command's own push covers its queued upload; any other command
+    // retries the queue (see [`super::queue`]).
+    match &global_sync {
+        Some(sync) => sync.push(),
+        None if !cli.dry_run && !matches!(cli.command, Some(Commands::FlushQueue { .. })) => {
+            super::queue::retry_pending(&global_dir)
+        }
+        None => {}
+    }
+    result?;
+
+    // On stderr so structured output on stdout stays exactly the command's result.
+    if cli.dry_run {
+        eprintln!("Dry run: nothing was written.");
+    }
+    if check_for_update {
+        super::self_update::notify(&global_dir);
+    }
+    Ok(())
+}
+
+/// Links listed pads to their `padz://` URIs when the terminal opens links
+/// and the output is for it.
+fn show_links(app_state: &AppState, output_mode: standout::OutputMode) {
+    let links = app_state.list.hyperlinks
+        && app_state.capabilities.hyperlinks
+        && matches!(
+            output_mode,
+            standout::OutputMode::Auto | standout::OutputMode::Term
+        );
+    super::render::show_links(links.then_some(app_state.scope));
+}
+
+/// Runs `list` until Ctrl-C, drawing it again whenever the scope's store
+/// changes (see [`super::watch`]). Each draw opens the store afresh, so it
+/// shows what other processes wrote.
+fn watch_listing(cli: &Cli, output_mode: standout::OutputMode) -> Result<()> {
+    let cancellation = super::interrupt::cancellation();
+    loop {
+        let app_state = create_app_state(cli)?;
+        let dir = app_state.with_api(|api| api.paths().scope_dir(app_state.scope))?;
+        let output_mode = app_state.capabilities.output_mode(output_mode);
+        if app_state.capabilities.stdout_tty {
+            super::watch::clear_screen();
+        }
+        show_links(&app_state, output_mode);
+        super::render::use_time_format(app_state.list.time_format);
+        let app = build_dispatch_app(app_state);
+        let matches = app.parse_from(build_command(), std::env::args());
+        handle_dispatch_result(app.dispatch(matches, output_mode))?;
+
+        let drawn = super::watch::Snapshot::of(&dir);
+        if !super::watch::wait_for_change(&dir, &drawn, &cancellation) {
+            return Ok(());
+        }
+    }
+}
+
+/// Runs the command if it needs no store; `None` when it does.
+///
+/// Startup is paid per command, not up front. Help and `--examples` are
+/// answered during [`parse_cli`], before this; the commands here never open
+/// the store, and only `config` loads configuration. Everything else goes
+/// through [`create_app_state`], where the store is initialized (schema
+/// migration, journal recovery, the scope registry), and only then may write.
+fn run_without_store(cli: &Cli) -> Option<Result<()>> {
+    let result = match cli.command.as_ref()? {
+        Commands::Completion {
+            shell,
+            install,
+            shell_flag,
+            action,
+        } => handle_completion(*shell, *shell_flag, *install, action.as_ref()),
+        // Rendered from the command tree alone
+        Commands::Docs { action } => handle_docs(action),
+        // The tour runs padz itself, in a sandbox of its own
+        Commands::Tour { no_pause } => super::tour::run(!no_pause),
+        // A prompt badge runs on every redraw: it locates the store but
+        // does not initialize it
+        Commands::PromptSegment {} => locate(cli).map(|(env, cwd)| {
+            let data = cli.data.as_ref().map(std::path::PathBuf::from);
+            super::prompt::run(&env, &cwd, cli.global, data);
+        }),
+        // Runs padz again, on the store the link points into
+        Commands::OpenUri { install: true, .. } => super::uri::install(),
+        Commands::OpenUri { uri, .. } => locate(cli).and_then(|(env, _)| {
+            super::uri::run(&env, uri.as_deref().unwrap_or_default(), cli.test_mode)
+        }),
+        // Via clapfig: needs paths, but not the API
+        Commands::Config { action } => handle_config(cli, action),
+        _ => return None,
+    };
+    Some(result)
 }
 
 /// Build the dispatch-ready App with templates, styles, command configuration, and app state
@@ -84,13 +226,15 @@ pub fn run() -> Result<()> {
 /// once for the binary, once for tests — is exactly the drift this avoids.
 pub fn build_dispatch_app(app_state: AppState) -> App {
     // Input policy depends on the configured mode, so construct the chains at
-    // assembly time before moving the durable state into Standout. These three
+    // assembly time before moving the durable state into Standout. These
     // commands are skipped by the Dispatch derive and registered explicitly so
     // Standout owns deepest-match resolution and the request-scoped Inputs bag.
     let mode = app_state.mode;
     let create_content = super::input::create_chain(mode);
     let edit_content = super::input::edit_chain(mode);
     let open_content = super::input::edit_chain(mode);
+    let append_content = super::input::amend_chain();
+    let prepend_content = super::input::amend_chain();
 
     App::builder()
         .app_state(app_state)
@@ -121,6 +265,18 @@ pub fn build_dispatch_app(app_state: AppState) -> App {
                 .input(super::input::EDIT_CONTENT, open_content)
         })
         .expect("Failed to configure open input")
+        .command_with("append", super::handlers::append__handler, |config| {
+            config
+                .template("modification_result")
+                .input(super::input::AMEND_CONTENT, append_content)
+        })
+        .expect("Failed to configure append input")
+        .command_with("prepend", super::handlers::prepend__handler, |config| {
+            config
+                .template("modification_result")
+                .input(super::input::AMEND_CONTENT, prepend_content)
+        })
+        .expect("Failed to configure prepend input")
         .build()
         .expect("Failed to build app")
 }
@@ -128,14 +284,18 @@ pub fn build_dispatch_app(app_state: AppState) -> App {
 /// The MiniJinja engine the listing family renders through.
 ///
 /// Standout's default engine already carries the framework filters (`col`, `tabular`,
-/// `nl`, …); this adds the three render-only seams the `list`/`search`/`peek` templates
+/// `nl`, …); this adds the six render-only seams the `list`/`search`/`peek` templates
 /// need and that MiniJinja cannot derive for itself:
 ///
 /// - `timeago` — clock arithmetic against `Utc::now()` (`created_at | timeago`).
 /// - `peek` — the body preview, delegating to `padzapp::peek` (`content | peek`).
+/// - `link` — the pad line as a hyperlink to its `padz://` URI, when links are on
+///   (`row | link(id)`).
+/// - `time_format()` — the `list.time_format` in force, which sizes the time column.
+/// - `narrow()` — whether the terminal is narrow enough for two-line pad lines.
 /// - `grouped_help()` — the clap-rendered command help shown only on an empty store.
 ///
-/// All three run exclusively on the template path, so structured output never sees a
+/// All six run exclusively on the template path, so structured output never sees a
 /// relative timestamp, a preview, or the help blob. Registering them here (rather than
 /// via a context provider) is what lets the templates read the core `DisplayPad` tree
 /// directly instead of a flattened row mirror.
@@ -144,6 +304,9 @@ fn listing_engine() -> MiniJinjaEngine {
     let env = engine.environment_mut();
     env.add_filter("timeago", timeago_filter);
     env.add_filter("peek", peek_filter);
+    env.add_filter("link", link_filter);
+    env.add_function("time_format", time_format_function);
+    env.add_function("narrow", narrow_function);
     env.add_function("grouped_help", get_grouped_help);
     engine
 }
@@ -203,13 +366,43 @@ fn handle_dispatch_result(result: RunResult) -> Result<()> {
 /// This is the composition root's *reading* half: it asks the process where it is
 /// (`current_dir`) and what machine it is on ([`env::resolve`](crate::cli::env::resolve)),
 /// then hands both to [`build_app_state`] as explicit values. Everything that
-/// actually decides anything lives there.
-fn create_app_state(cli: &Cli) -> Result<AppState> {
+/// actually decides anything lives there. It also detects whether there is a
+/// terminal ([`Capabilities`](crate::cli::capabilities::Capabilities)), which
+/// `build_app_state` leaves non-interactive, and on padz's first run on the
+/// machine sets up the global store ([`onboarding`](crate::cli::onboarding)).
+/// Under `--test-mode` it does none of this and runs in the
+/// [`sandbox`](crate::cli::env::sandbox) instead.
+pub(super) fn create_app_state(cli: &Cli) -> Result<AppState> {
+    let (env, cwd) = locate(cli)?;
+    if cli.test_mode {
+        // The state stays non-interactive whatever the test runner's terminal
+        // is, clipboard writes are dropped, and the clipboard reads as empty.
+        let state = build_app_state(cli, &env, &cwd)?;
+        let clipboard = std::rc::Rc::new(super::clipboard::DiscardingClipboardWriter);
+        return Ok(state
+            .with_clipboard_writer(clipboard.clone())
+            .with_clipboard_reader(clipboard));
+    }
+    let capabilities = super::capabilities::Capabilities::detect();
+    // Before the store is opened, which would leave traces of a first run.
+    if !cli.dry_run {
+        super::onboarding::run(&env.global_data_dir, &capabilities);
+    }
+    let state = build_app_state(cli, &env, &cwd)?;
+    Ok(state.with_capabilities(capabilities))
+}
+
+/// Where this invocation runs: the process's cwd and this machine's directories,
+/// or, under `--test-mode`, the [`sandbox`](crate::cli::env::sandbox) standing
+/// in for both.
+fn locate(cli: &Cli) -> Result<(padzapp::init::PadzEnv, std::path::PathBuf)> {
+    if cli.test_mode {
+        return Ok(crate::cli::env::sandbox()?);
+    }
     let cwd = std::env::current_dir().unwrap_or_else(|_| std::path::PathBuf::from("."));
     // Resolve the environment once, here at the composition root, and hand
     // explicit values to the library.
-    let env = crate::cli::env::resolve();
-    build_app_state(cli, &env, &cwd)
+    Ok((crate::cli::env::resolve(), cwd))
 }
 
 /// Build app state containing API, scope, and configuration for handlers, from an
@@ -228,7 +421,15 @@ pub fn build_app_state(
     env: &padzapp::init::PadzEnv,
     cwd: &std::path::Path,
 ) -> Result<AppState> {
-    let data_override = cli.data.as_ref().map(std::path::PathBuf::from);
+    // A remote store is read through its local copy, which then stands in for
+    // `--data`.
+    let remote_cache = match &cli.remote {
+        Some(target) => Some(fetch_remote(cli, env, target)?),
+        None => None,
+    };
+    let data_override = remote_cache
+        .clone()
+        .or_else(|| cli.data.as_ref().map(std::path::PathBuf::from));
 
     // `padz init` (plain, non-global) is a creation operation: "create a store HERE".
     // It should use cwd directly, not walk up to find an existing store.
@@ -251,10 +452,13 @@ pub fn build_app_state(
     // so the new pad is project-scoped rather than silently dropped into global.
     // Every other command operates on existing pads and reads fall back to global
     // cleanly; they pass `false`.
-    let auto_init_for_write = matches!(
-        cli.command,
-        Some(Commands::Create { .. }) | Some(Commands::Import { .. })
-    );
+    // A dry run must not create one either, so it reports against whichever
+    // store already exists.
+    let auto_init_for_write = !cli.dry_run
+        && matches!(
+            cli.command,
+            Some(Commands::Create { .. }) | Some(Commands::Import { .. })
+        );
 
     // Compute the local .padz dir BEFORE link resolution (used by link/unlink commands)
     let local_padz_dir = match &data_override {
@@ -278,19 +482,170 @@ pub fn build_app_state(
         eprintln!("Warning: {}", warning);
     }
 
+    // Every external program from here on (git, gpg, aws, the editor) runs
+    // under the configured time limits.
+    super::subprocess::configure(&padz_ctx.config);
+    super::editor::configure(&padz_ctx.config);
+
+    let mut api = padz_ctx.api;
+    // `create_template` is relative to the store it configures.
+    let create_template = match &padz_ctx.config.create_template {
+        Some(file) => Some(api.paths().scope_dir(padz_ctx.scope)?.join(file)),
+        None => None,
+    };
+    if cli.dry_run || remote_cache.is_some() {
+        api.arm_dry_run();
+    }
+    // Under `--test-mode` nothing runs that the test did not ask for: no
+    // progress on stderr, no bucket sync, no git.
+    if !cli.test_mode {
+        api.set_progress(std::rc::Rc::new(TerminalProgress::new()));
+    }
+    api.set_cancellation(super::interrupt::cancellation());
+    api.set_ignore_errors(cli.ignore_errors);
+    // Who is running padz. Under `--test-mode` neither git nor the
+    // environment is consulted, so only the config says.
+    let identity = if cli.test_mode {
+        super::identity::Identity::from(&padz_ctx.config.identity)
+    } else {
+        super::identity::resolve(&padz_ctx.config.identity, cwd)
+    };
+    api.set_ownership(padzapp::commands::ownership::Ownership {
+        identity: identity.name.clone(),
+        owner_only_edit: padz_ctx.config.owner_only_edit,
+        force: cli.force,
+    });
+    // `pin --evict` makes room past `max_pins`; the global `--force` is
+    // ownership's alone.
+    api.set_pin_limit(padzapp::commands::pinning::PinLimit {
+        max: padz_ctx.config.max_pins,
+        evict_oldest: matches!(cli.command, Some(Commands::Pin { evict: true, .. })),
+    });
+    // A global-scope command syncs with the bucket, if one is configured and
+    // `experimental.sync` is on: pull now, push after dispatch. A dry run
+    // leaves the local copy untouched.
+    let global_sync = match &padz_ctx.config.global_store {
+        Some(url)
+            if padz_ctx.config.experimental.sync
+                && padz_ctx.scope == padzapp::model::Scope::Global
+                && !cli.dry_run
+                && !cli.test_mode =>
+        {
+            let sync = GlobalStoreSync::new(
+                url,
+                padz_ctx.config.global_store_endpoint.clone(),
+                env.global_data_dir.clone(),
+            )?;
+            sync.pull();
+            Some(sync)
+        }
+        _ => None,
+    };
+    // Listeners hear about what was written; a dry run writes nothing, and
+    // under `--test-mode` nothing runs that the test did not ask for.
+    let listeners = super::events::EventSink::new(
+        padz_ctx.config.event_command.clone(),
+        padz_ctx.config.event_socket.clone().map(Into::into),
+    );
+    let events = listeners
+        .clone()
+        .filter(|_| !cli.dry_run && remote_cache.is_none() && !cli.test_mode);
+    if events.is_some() {
+        api.record_events();
+    }
+    // Only a create stamps a context, so only a create pays for running git.
+    // `create --branch` asks for one whatever the setting, and needs a branch.
+    match &cli.command {
+        Some(Commands::Create { branch: true, .. }) => {
+            let context = crate::cli::git_context::capture(cwd)
+                .filter(|context| context.branch.is_some())
+                .ok_or_else(|| {
+                    padzapp::error::PadzError::Api(
+                        "--branch needs a git branch checked out".to_string(),
+                    )
+                })?;
+            api.set_creation_context(Some(context));
+        }
+        Some(Commands::Create { .. }) if padz_ctx.config.capture_context && !cli.test_mode => {
+            api.set_creation_context(crate::cli::git_context::capture(cwd));
+        }
+        _ => {}
+    }
+
+    let clipboard = match padz_ctx.config.clipboard.as_deref() {
+        Some(name) => super::clipboard::Provider::named(name)?,
+        None => super::clipboard::Provider::Auto,
+    };
+
     Ok(AppState::new(
-        padz_ctx.api,
+        api,
         padz_ctx.scope,
         padz_ctx.config.import_extensions(),
         padz_ctx.config.mode,
         local_padz_dir,
-    ))
+    )
+    .with_usage_stats(padz_ctx.config.usage_stats)
+    .with_update_check(padz_ctx.config.update_check)
+    .with_lint_command(padz_ctx.config.lint_command.clone())
+    .with_create_template(create_template)
+    .with_duplicate_title(padz_ctx.config.on_duplicate_title)
+    .with_list_config(padz_ctx.config.list.clone())
+    .with_trash_config(padz_ctx.config.trash.clone())
+    .with_journal_config(padz_ctx.config.journal.clone())
+    .with_publish_config(padz_ctx.config.publish.clone())
+    .with_spelling(
+        env.global_data_dir.clone(),
+        padz_ctx.config.spell_language.clone(),
+    )
+    .with_global_sync(global_sync)
+    .with_events(events)
+    .with_listeners(listeners)
+    .with_signer(std::rc::Rc::new(super::signing::GpgSigner {
+        local_user: identity.email,
+    }))
+    .with_clipboard_writer(std::rc::Rc::new(super::clipboard::SystemClipboardWriter(
+        clipboard,
+    )))
+    .with_clipboard_reader(std::rc::Rc::new(super::clipboard::SystemClipboardReader(
+        clipboard,
+    )))
+    .with_cwd(cwd.to_path_buf()))
+}
+
+/// Refreshes the local copy of the remote store `target` and returns where
+/// it is (see [`super::remote`]).
+fn fetch_remote(
+    cli: &Cli,
+    env: &padzapp::init::PadzEnv,
+    target: &str,
+) -> Result<std::path::PathBuf> {
+    if !super::remote::is_read_only(cli.command.as_ref()) {
+        return Err(padzapp::error::PadzError::Api(
+            "Remote stores are read-only: this command needs a local store".into(),
+        ));
+    }
+    let remote = padzapp::commands::scopes::resolve_remote(&env.global_data_dir, target)?;
+    let cache = remote.cache_dir(&env.global_data_dir);
+    if let Err(e) = super::remote::fetch(&remote, &cache) {
+        if !cache.is_dir() {
+            return Err(padzapp::error::PadzError::Api(format!(
+                "Could not fetch {}: {}",
+                remote.url, e
+            )));
+        }
+        eprintln!(
+            "Warning: could not fetch {} ({}); showing the copy from {}",
+            remote.url,
+            e,
+            super::remote::fetched_at(&cache).unwrap_or_else(|| "an earlier fetch".into())
+        );
+    }
+    Ok(cache)
 }
 
 fn handle_config(cli: &Cli, subcommand: &Option<ConfigSubcommand>) -> Result<()> {
-    let cwd = std::env::current_dir().unwrap_or_else(|_| std::path::PathBuf::from("."));
+    let (env, cwd) = locate(cli)?;
     let data_override = cli.data.as_ref().map(std::path::PathBuf::from);
-    let env = crate::cli::env::resolve();
 
     // Resolve paths (lightweight version of initialize — just need dirs, not full API)
     let project_padz_dir = match data_override {
@@ -354,13 +709,66 @@ fn handle_config(cli: &Cli, subcommand: &Option<ConfigSubcommand>) -> Result<()>
     Ok(())
 }
 
+/// `padz completion [SHELL] [--install]`. The `install` and `print`
+/// subcommands and `--shell` are the earlier spelling, still accepted with a
+/// warning (see [`super::deprecation`]).
+fn handle_completion(
+    shell: Option<CompletionShell>,
+    shell_flag: Option<CompletionShell>,
+    install: bool,
+    legacy: Option<&CompletionAction>,
+) -> Result<()> {
+    match legacy {
+        Some(CompletionAction::Install) => deprecation::warn(&deprecation::COMPLETION_INSTALL),
+        Some(CompletionAction::Print) => deprecation::warn(&deprecation::COMPLETION_PRINT),
+        None => {}
+    }
+    if shell_flag.is_some() {
+        deprecation::warn(&deprecation::COMPLETION_SHELL_FLAG);
+    }
+    let shell = shell.or(shell_flag);
+    if install || matches!(legacy, Some(CompletionAction::Install)) {
+        handle_install(shell)
+    } else {
+        handle_print(shell)
+    }
+}
+
 fn handle_print(shell_override: Option<CompletionShell>) -> Result<()> {
     let shell = resolve_shell(shell_override)?;
     print!("{}", completion_script(shell)?);
+    // Someone reading the script at a terminal wants to know what to do with
+    // it; under `eval "$(...)"` stdout is a pipe and this stays quiet.
+    if std::io::stdout().is_terminal() {
+        for line in enable_hint(shell) {
+            eprintln!("{}", line);
+        }
+    }
     Ok(())
 }
 
-fn handle_install(shell_override: Option<CompletionShell>) -> Result<()> {
+/// How to load the printed script, for `padz completion` run at a terminal.
+fn enable_hint(shell: CompletionShell) -> Vec<String> {
+    let (rc, line) = match shell {
+        CompletionShell::Bash => ("~/.bashrc", "eval \"$(padz completion bash)\""),
+        CompletionShell::Zsh => ("~/.zshrc", "eval \"$(padz completion zsh)\""),
+        CompletionShell::Fish => (
+            "~/.config/fish/config.fish",
+            "padz completion fish | source",
+        ),
+    };
+    vec![
+        String::new(),
+        format!("To enable completions, add this line to {}:", rc),
+        format!("  {}", line),
+        format!(
+            "Or install the script once with: padz completion {} --install",
+            shell.as_complete_env()
+        ),
+    ]
+}
+
+pub(super) fn handle_install(shell_override: Option<CompletionShell>) -> Result<()> {
     let shell = resolve_shell(shell_override)?;
     let script = completion_script(shell)?;
 
@@ -386,7 +794,7 @@ fn resolve_shell(shell_override: Option<CompletionShell>) -> Result<CompletionSh
     })
 }
 
-fn detect_shell() -> Option<CompletionShell> {
+pub(super) fn detect_shell() -> Option<CompletionShell> {
     let shell = std::env::var("SHELL").ok()?;
     let name = std::path::Path::new(&shell).file_name()?.to_str()?;
     match name {
@@ -424,7 +832,7 @@ fn completion_script(shell: CompletionShell) -> Result<String> {
     })
 }
 
-fn xdg_data_home() -> Result<std::path::PathBuf> {
+pub(super) fn xdg_data_home() -> Result<std::path::PathBuf> {
     if let Ok(dir) = std::env::var("XDG_DATA_HOME") {
         if !dir.is_empty() {
             return Ok(std::path::PathBuf::from(dir));
@@ -456,6 +864,39 @@ fn install_path(shell: CompletionShell) -> Result<std::path::PathBuf> {
     })
 }
 
+fn handle_docs(action: &DocsAction) -> Result<()> {
+    match action {
+        DocsAction::InstallMan { dir } => handle_install_man(dir.clone()),
+    }
+}
+
+/// Write every man page (see [`super::man`]) to `dir`, by default the
+/// per-user `man1` directory that `man` searches next to `~/.local/bin`.
+fn handle_install_man(dir: Option<std::path::PathBuf>) -> Result<()> {
+    let dir = match dir {
+        Some(dir) => dir,
+        None => xdg_data_home()?.join("man/man1"),
+    };
+    std::fs::create_dir_all(&dir)?;
+    let pages = super::man::pages(super::examples::attach(build_command()));
+    for page in &pages {
+        std::fs::write(dir.join(&page.file_name), &page.roff)?;
+    }
+
+    println!("Installed {} man pages to {}", pages.len(), dir.display());
+    // An explicit $MANPATH replaces man's own search path, so a directory
+    // missing from it is never searched.
+    let root = dir.parent().unwrap_or(&dir).display().to_string();
+    match std::env::var("MANPATH") {
+        Ok(manpath) if !manpath.is_empty() && !manpath.split(':').any(|p| p == root) => {
+            println!("To read them, add this line to your shell's rc file:");
+            println!("  export MANPATH={}:\"$MANPATH\"", shell_quote(&root));
+        }
+        _ => println!("Read them with: man padz, man padz-list, ..."),
+    }
+    Ok(())
+}
+
 /// POSIX-shell single-quote a string so it is safe to paste into a shell line,
 /// including paths containing spaces, `$`, backticks, or embedded `'`. The
 /// embedded-quote form `'\''` closes, escapes, reopens. The returned string is
@@ -528,7 +969,7 @@ fn post_install_hint(shell: CompletionShell, path: &std::path::Path) -> Vec<Stri
 /// Probe zsh to check if `dir` is already on `$fpath`. Non-interactive zsh
 /// skips `.zshrc`, so we run an interactive instance — but bound it to a short
 /// timeout, because a slow/prompting `.zshrc` could otherwise hang
-/// `padz completion install` forever. Returns false on any failure (the
+/// `padz completion --install` forever. Returns false on any failure (the
 /// fallback path just asks the user to add the fpath line manually, which is
 /// always safe).
 fn zsh_dir_on_fpath(dir: &str) -> bool {
@@ -607,7 +1048,8 @@ fn macos_bash_completion_available() -> bool {
 
 #[cfg(test)]
 mod completion_tests {
-    use super::shell_quote;
+    use super::{enable_hint, shell_quote};
+    use crate::cli::setup::CompletionShell;
 
     #[test]
     fn safe_chars_unquoted() {
@@ -641,6 +1083,14 @@ mod completion_tests {
     fn empty_quoted() {
         assert_eq!(shell_quote(""), "''");
     }
+
+    #[test]
+    fn enable_hint_names_the_rc_file_and_the_install_flag() {
+        let hint = enable_hint(CompletionShell::Fish).join("\n");
+        assert!(hint.contains("~/.config/fish/config.fish"));
+        assert!(hint.contains("padz completion fish | source"));
+        assert!(hint.contains("padz completion fish --install"));
+    }
 }
 
 #[cfg(test)]
@@ -12,7 +12,7 @@ use std::path::PathBuf;
 ///
 /// This completer:
 /// - Detects --global/-g flag from command line to determine scope
-/// - Returns both numeric indexes (1, 2, p1, d1) and titles
+/// - Returns numeric indexes (1, 2, p1, d1), aliases and titles
 /// - Filters based on whether deleted pads should be included
 fn get_pad_candidates(include_deleted: bool) -> Vec<CompletionCandidate> {
     // Parse args to detect --global flag
@@ -64,6 +64,15 @@ fn get_pad_candidates(include_deleted: bool) -> Vec<CompletionCandidate> {
                     .display_order(Some(1)),
             );
         }
+
+        // Add the alias, which selects the pad exactly wherever an index does
+        if let Some(alias) = &dp.pad.metadata.alias {
+            candidates.push(
+                CompletionCandidate::new(alias.clone())
+                    .help(Some(title.clone().into()))
+                    .display_order(Some(0)),
+            );
+        }
     }
 
     candidates
@@ -0,0 +1,112 @@
+//! Deprecated spellings of commands and flags.
+//!
+//! The command line changes shape now and then: a subcommand turns into a
+//! flag, two commands fold into one. The old spelling keeps working for a
+//! while, declared here as a [`Deprecation`] naming its replacement, and the
+//! code that accepts it calls [`warn`]. The warning goes to stderr at most once
+//! a day per usage: the day's first use is recorded in `deprecations.json` next
+//! to the global store, so a script run every minute is reminded rather than
+//! flooded.
+
+use chrono::{DateTime, Duration, Utc};
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+/// File name of the warning record inside the global data directory.
+pub const DEPRECATIONS_FILE: &str = "deprecations.json";
+
+/// The least time, in hours, between two warnings about one usage.
+const WARN_EVERY_HOURS: i64 = 24;
+
+/// An old spelling still accepted, and what to type instead. Both are written
+/// as they follow `padz` on the command line.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct Deprecation {
+    pub usage: &'static str,
+    pub replacement: &'static str,
+}
+
+pub const COMPLETION_INSTALL: Deprecation = Deprecation {
+    usage: "completion install",
+    replacement: "completion [SHELL] --install",
+};
+
+pub const COMPLETION_PRINT: Deprecation = Deprecation {
+    usage: "completion print",
+    replacement: "completion [SHELL]",
+};
+
+pub const COMPLETION_SHELL_FLAG: Deprecation = Deprecation {
+    usage: "completion --shell",
+    replacement: "completion [SHELL]",
+};
+
+/// Tells the user, on stderr, that `deprecation` is on its way out, unless
+/// they were told today already.
+pub fn warn(deprecation: &Deprecation) {
+    if due(&super::env::global_data_dir(), deprecation, Utc::now()) {
+        eprintln!("{}", message(deprecation));
+    }
+}
+
+fn message(deprecation: &Deprecation) -> String {
+    format!(
+        "Warning: `padz {}` is deprecated; use `padz {}`.",
+        deprecation.usage, deprecation.replacement
+    )
+}
+
+/// Whether a warning about `deprecation` is due at `now`, recording it as
+/// given when it is. Best-effort: a record that cannot be read or written
+/// never silences a warning, it only lets it repeat.
+fn due(global_dir: &Path, deprecation: &Deprecation, now: DateTime<Utc>) -> bool {
+    let path = record_path(global_dir);
+    let mut warned: BTreeMap<String, DateTime<Utc>> = fs::read_to_string(&path)
+        .ok()
+        .and_then(|content| serde_json::from_str(&content).ok())
+        .unwrap_or_default();
+    if warned
+        .get(deprecation.usage)
+        .is_some_and(|last| now - *last < Duration::hours(WARN_EVERY_HOURS))
+    {
+        return false;
+    }
+    warned.insert(deprecation.usage.to_string(), now);
+    if let Ok(json) = serde_json::to_string_pretty(&warned) {
+        let _ = fs::create_dir_all(global_dir).and_then(|_| fs::write(&path, json));
+    }
+    true
+}
+
+fn record_path(global_dir: &Path) -> PathBuf {
+    global_dir.join(DEPRECATIONS_FILE)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    #[test]
+    fn a_usage_is_warned_about_once_a_day() {
+        let temp = TempDir::new().unwrap();
+        let dir = temp.path();
+        let now = Utc::now();
+        let later = |hours| now + Duration::hours(hours);
+
+        assert!(due(dir, &COMPLETION_INSTALL, now));
+        assert!(!due(dir, &COMPLETION_INSTALL, later(23)));
+        // Each usage keeps its own day.
+        assert!(due(dir, &COMPLETION_PRINT, now));
+        assert!(due(dir, &COMPLETION_INSTALL, later(24)));
+    }
+
+    #[test]
+    fn the_warning_names_the_replacement() {
+        assert_eq!(
+            message(&COMPLETION_PRINT),
+            "Warning: `padz completion print` is deprecated; use `padz completion [SHELL]`."
+        );
+    }
+}
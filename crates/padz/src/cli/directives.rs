@@ -0,0 +1,83 @@
+//! Filling in `{{today}}`-style directives when a pad is viewed.
+//!
+//! `padzapp::directives` finds the directives; the values come from here,
+//! because they need the clock and, for `{{shell "..."}}`, a subprocess.
+//! `today` and `now` are always filled in, in local time. Shell directives run
+//! only under `view --exec`: a pad is text anyone may have pasted in, and
+//! viewing it must not run commands unless asked to. Without the flag they are
+//! shown as written.
+//!
+//! A command runs through `sh -c` from the project root, and its output
+//! replaces the directive with the trailing newline trimmed. A failing command
+//! still shows what it printed (stdout, then stderr), so a broken runbook step
+//! is visible in place instead of silently empty.
+
+use super::subprocess::{self, Limit};
+use chrono::Local;
+use padzapp::directives::{self, Directive};
+use std::path::Path;
+use std::process::Command;
+
+/// Fills in the directives in `text`. Shell directives run in `cwd`, and only
+/// when `exec` is set; placeholders are left for `padz fill`.
+pub fn expand(text: &str, exec: bool, cwd: &Path) -> String {
+    directives::expand(text, |directive| resolve(directive, exec, cwd))
+}
+
+/// The value of one directive, or `None` to leave it as written.
+pub fn resolve(directive: &Directive, exec: bool, cwd: &Path) -> Option<String> {
+    match directive {
+        Directive::Today => Some(Local::now().format("%Y-%m-%d").to_string()),
+        Directive::Now => Some(Local::now().format("%Y-%m-%d %H:%M").to_string()),
+        Directive::Shell(command) if exec => Some(run(command, cwd)),
+        Directive::Shell(_) | Directive::Placeholder(_) => None,
+    }
+}
+
+/// Runs `command` and returns what it printed.
+fn run(command: &str, cwd: &Path) -> String {
+    match subprocess::output(
+        Command::new("sh").arg("-c").arg(command).current_dir(cwd),
+        Limit::Command,
+        &format!("'{}'", command),
+    ) {
+        Ok(output) => {
+            let mut printed = String::from_utf8_lossy(&output.stdout).into_owned();
+            printed.push_str(&String::from_utf8_lossy(&output.stderr));
+            printed.trim_end_matches('\n').to_string()
+        }
+        Err(e) => format!("padz: {}", e),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn shell_directives_run_only_with_exec() {
+        let temp = tempfile::tempdir().unwrap();
+        let text = "pods:\n{{shell \"printf 'a\\nb\\n'\"}}";
+
+        assert_eq!(expand(text, true, temp.path()), "pods:\na\nb");
+        assert_eq!(expand(text, false, temp.path()), text);
+    }
+
+    #[test]
+    fn commands_run_in_the_given_directory_and_show_failures() {
+        let temp = tempfile::tempdir().unwrap();
+        std::fs::write(temp.path().join("marker"), "").unwrap();
+
+        assert_eq!(expand("{{shell \"ls\"}}", true, temp.path()), "marker");
+        assert_eq!(
+            expand("{{shell \"echo oops >&2; exit 3\"}}", true, temp.path()),
+            "oops"
+        );
+    }
+
+    #[test]
+    fn today_is_a_local_date() {
+        let today = expand("{{today}}", false, Path::new("."));
+        assert_eq!(today, Local::now().format("%Y-%m-%d").to_string());
+    }
+}
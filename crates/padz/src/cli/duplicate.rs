@@ -0,0 +1,108 @@
+//! `create` with a title an active pad already has.
+//!
+//! Before a titled pad is created, the scope is checked for an active pad with
+//! exactly that title, and `on_duplicate_title` decides what happens: `open`
+//! opens that pad in the editor instead, `append` adds the new text to its
+//! end, `create` makes a second pad anyway, and `ask`, the default, puts the
+//! three to the user. Where no one can be asked (a pipe, a script, a test),
+//! `ask` creates, as `create` always did. A create with no text yet, the
+//! editor's, has nothing to append, so `append` opens the pad there.
+
+use super::capabilities::Capabilities;
+use padzapp::config::DuplicateTitle;
+use std::io::{BufRead, Write};
+
+/// What to do instead of, or before, creating a pad with a taken title.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Action {
+    Open,
+    Append,
+    Create,
+}
+
+/// What `policy` makes of a taken title. `ask` is called for `ask` with
+/// whether there is text to append, and `None` from it means create.
+pub fn action(
+    policy: DuplicateTitle,
+    has_text: bool,
+    ask: impl FnOnce(bool) -> Option<Action>,
+) -> Action {
+    let action = match policy {
+        DuplicateTitle::Ask => ask(has_text).unwrap_or(Action::Create),
+        DuplicateTitle::Open => Action::Open,
+        DuplicateTitle::Append => Action::Append,
+        DuplicateTitle::Create => Action::Create,
+    };
+    match action {
+        Action::Append if !has_text => Action::Open,
+        other => other,
+    }
+}
+
+/// Asks on stderr what to do about the pad already titled `title`. `None`
+/// when the user cannot be asked; an empty or unknown answer creates.
+pub fn ask(title: &str, has_text: bool, capabilities: &Capabilities) -> Option<Action> {
+    if !capabilities.can_prompt() {
+        return None;
+    }
+    let choices = if has_text {
+        "[o]pen it, [a]ppend to it, or [c]reate another?"
+    } else {
+        "[o]pen it, or [c]reate another?"
+    };
+    eprint!("A pad titled '{title}' already exists. {choices} [c] ");
+    let _ = std::io::stderr().flush();
+    let mut answer = String::new();
+    if std::io::stdin().lock().read_line(&mut answer).is_err() {
+        return None;
+    }
+    Some(answer_to_action(&answer, has_text))
+}
+
+fn answer_to_action(answer: &str, has_text: bool) -> Action {
+    match answer.trim().to_lowercase().as_str() {
+        "o" | "open" => Action::Open,
+        "a" | "append" if has_text => Action::Append,
+        _ => Action::Create,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn the_policy_decides_unless_it_says_ask() {
+        let never_asked = |_: bool| -> Option<Action> { panic!("asked") };
+        assert_eq!(
+            action(DuplicateTitle::Open, true, never_asked),
+            Action::Open
+        );
+        assert_eq!(
+            action(DuplicateTitle::Append, true, never_asked),
+            Action::Append
+        );
+        assert_eq!(
+            action(DuplicateTitle::Create, true, never_asked),
+            Action::Create
+        );
+        assert_eq!(
+            action(DuplicateTitle::Ask, true, |_| Some(Action::Append)),
+            Action::Append
+        );
+        // No one to ask: create, as before the check existed.
+        assert_eq!(action(DuplicateTitle::Ask, true, |_| None), Action::Create);
+    }
+
+    #[test]
+    fn with_nothing_to_append_the_pad_is_opened() {
+        assert_eq!(
+            action(DuplicateTitle::Append, false, |_| None),
+            Action::Open
+        );
+        assert_eq!(answer_to_action("a\n", false), Action::Create);
+        assert_eq!(answer_to_action("Append\n", true), Action::Append);
+        assert_eq!(answer_to_action("o\n", false), Action::Open);
+        assert_eq!(answer_to_action("\n", true), Action::Create);
+    }
+}
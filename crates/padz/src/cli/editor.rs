@@ -1,17 +1,25 @@
 //! Launching the user's text editor.
 //!
 //! This is a user-environment concern and therefore lives in the CLI, not in
-//! `padzapp`: it reads `$EDITOR`/`$VISUAL`, probes `PATH` for fallbacks, and
-//! spawns a child process that takes over the terminal. The library owns only
-//! the buffer format ([`padzapp::editor::EditorContent`]).
+//! `padzapp`: it reads the `editor` config key and `$EDITOR`/`$VISUAL`, probes
+//! `PATH` for fallbacks, and spawns a child process that takes over the
+//! terminal. The library owns only the buffer format
+//! ([`padzapp::editor::EditorContent`]).
+//!
+//! Like the subprocess limits, the `editor` key is read from config once, by
+//! `build_app_state` ([`configure`]).
 //!
 //! Selection is separated from launching so it can be tested without spawning
 //! anything: [`select_editor`] is a pure function of an [`EditorEnv`], and only
 //! [`open_in_editor`] touches the process table.
 
+use super::subprocess::{self, Limit};
+use once_cell::sync::Lazy;
+use padzapp::config::PadzConfig;
 use padzapp::error::{PadzError, Result};
 use std::path::Path;
 use std::process::Command;
+use std::sync::RwLock;
 
 /// Editors tried, in order, when neither `$EDITOR` nor `$VISUAL` is set.
 const FALLBACK_EDITORS: [&str; 3] = ["vim", "vi", "nano"];
@@ -22,6 +30,8 @@ const FALLBACK_EDITORS: [&str; 3] = ["vim", "vi", "nano"];
 /// by mutating the process environment (which is global and racy under a
 /// parallel test runner).
 pub struct EditorEnv {
+    /// The `editor` config key, if set and non-empty.
+    pub configured: Option<String>,
     /// The value of `$EDITOR`, if set and non-empty.
     pub editor: Option<String>,
     /// The value of `$VISUAL`, if set and non-empty.
@@ -34,6 +44,7 @@ impl EditorEnv {
     /// Reads the real process environment. The composition root for editing.
     pub fn from_process() -> Self {
         Self {
+            configured: CONFIGURED.read().unwrap_or_else(|e| e.into_inner()).clone(),
             editor: non_empty_var("EDITOR"),
             visual: non_empty_var("VISUAL"),
             is_on_path: which,
@@ -41,6 +52,14 @@ impl EditorEnv {
     }
 }
 
+static CONFIGURED: Lazy<RwLock<Option<String>>> = Lazy::new(|| RwLock::new(None));
+
+/// Use the `editor` set in `config`, if any, from now on.
+pub fn configure(config: &PadzConfig) {
+    let editor = config.editor.clone().filter(|e| !e.trim().is_empty());
+    *CONFIGURED.write().unwrap_or_else(|e| e.into_inner()) = editor;
+}
+
 /// Reads `name` from the environment, treating an empty value as unset —
 /// `EDITOR=` should fall through to `$VISUAL` rather than trying to run "".
 fn non_empty_var(name: &str) -> Option<String> {
@@ -56,9 +75,12 @@ fn which(name: &str) -> bool {
         .unwrap_or(false)
 }
 
-/// Picks the editor command to run: `$EDITOR`, then `$VISUAL`, then the first
-/// of [`FALLBACK_EDITORS`] found on `PATH`.
+/// Picks the editor command to run: the `editor` config key, then `$EDITOR`,
+/// then `$VISUAL`, then the first of [`FALLBACK_EDITORS`] found on `PATH`.
 pub fn select_editor(env: &EditorEnv) -> Result<String> {
+    if let Some(editor) = env.configured.clone() {
+        return Ok(editor);
+    }
     if let Some(editor) = env.editor.clone() {
         return Ok(editor);
     }
@@ -71,7 +93,7 @@ pub fn select_editor(env: &EditorEnv) -> Result<String> {
         }
     }
     Err(PadzError::Api(
-        "No editor found. Set $EDITOR environment variable.".to_string(),
+        "No editor found. Set $EDITOR, or the editor config key.".to_string(),
     ))
 }
 
@@ -84,16 +106,35 @@ pub fn open_in_editor<P: AsRef<Path>>(file_path: P) -> Result<()> {
     open_with(&editor, file_path.as_ref())
 }
 
+/// Opens `file_path` in the user's editor with the cursor on `line`.
+///
+/// Used for code anchors. The line is passed as `+N`, the convention vi, vim,
+/// nano, emacs and most terminal editors share.
+pub fn open_in_editor_at<P: AsRef<Path>>(file_path: P, line: Option<u32>) -> Result<()> {
+    let editor = select_editor(&EditorEnv::from_process())?;
+    open_with_at(&editor, file_path.as_ref(), line)
+}
+
 /// Runs `editor` against `path` and waits for it to close.
 ///
 /// Split out from [`open_in_editor`] so the spawn-and-wait behavior can be
 /// tested against a real child process without touching `$EDITOR` (which is
 /// process-global, and so racy to mutate under a parallel test runner).
 pub fn open_with(editor: &str, path: &Path) -> Result<()> {
-    let status = Command::new(editor)
+    open_with_at(editor, path, None)
+}
+
+/// [`open_with`], placing the cursor on `line` when one is given.
+pub fn open_with_at(editor: &str, path: &Path, line: Option<u32>) -> Result<()> {
+    let mut command = Command::new(editor);
+    if let Some(line) = line {
+        command.arg(format!("+{}", line));
+    }
+    let child = command
         .arg(path)
-        .status()
+        .spawn()
         .map_err(|e| PadzError::Api(format!("Failed to launch editor '{}': {}", editor, e)))?;
+    let status = subprocess::wait(child, Limit::Editor, &format!("editor '{}'", editor))?.status;
 
     if !status.success() {
         return Err(PadzError::Api(format!(
@@ -112,6 +153,7 @@ mod tests {
     /// An env with nothing set and nothing on PATH.
     fn bare_env() -> EditorEnv {
         EditorEnv {
+            configured: None,
             editor: None,
             visual: None,
             is_on_path: |_| false,
@@ -128,6 +170,16 @@ mod tests {
         assert_eq!(select_editor(&env).unwrap(), "emacs");
     }
 
+    #[test]
+    fn configured_editor_wins_over_the_environment() {
+        let env = EditorEnv {
+            configured: Some("hx".into()),
+            editor: Some("emacs".into()),
+            ..bare_env()
+        };
+        assert_eq!(select_editor(&env).unwrap(), "hx");
+    }
+
     #[test]
     fn visual_used_when_editor_unset() {
         let env = EditorEnv {
@@ -207,6 +259,28 @@ mod tests {
         );
     }
 
+    /// A line number reaches the editor as `+N`, ahead of the path.
+    #[cfg(unix)]
+    #[test]
+    fn open_with_at_passes_the_line_before_the_path() {
+        let temp = tempfile::tempdir().unwrap();
+        let file = temp.path().join("main.rs");
+        std::fs::write(&file, "").unwrap();
+        let record = temp.path().join("args");
+        let editor = script(
+            temp.path(),
+            "rec.sh",
+            &format!("#!/bin/sh\necho \"$@\" > {}\n", record.display()),
+        );
+
+        open_with_at(editor.to_str().unwrap(), &file, Some(42)).unwrap();
+
+        assert_eq!(
+            std::fs::read_to_string(&record).unwrap().trim(),
+            format!("+42 {}", file.display())
+        );
+    }
+
     /// An editor that isn't there at all reports the launch failure, naming the
     /// command so the user can see what their `$EDITOR` pointed at.
     #[test]
@@ -5,6 +5,9 @@
 //! place that does it. Everything below reads process state (`$PADZ_GLOBAL_DATA`)
 //! or asks the OS where user directories live — which is exactly why it lives
 //! in the CLI and not in the library.
+//!
+//! `--test-mode` swaps all of it for a [`sandbox`] under the temp directory, so
+//! a test driving the real binary never reads or writes the user's pads.
 
 use padzapp::init::PadzEnv;
 use std::path::PathBuf;
@@ -46,3 +49,25 @@ pub fn global_data_dir() -> PathBuf {
 fn home_dir() -> Option<PathBuf> {
     directories::BaseDirs::new().map(|bd| bd.home_dir().to_path_buf())
 }
+
+/// The environment and working directory `--test-mode` runs in.
+///
+/// Everything lives under `padz-test-mode/` in the temp directory: global pads
+/// in `global/`, and a `project/` working directory marked as a git root, so
+/// project pads land in `project/.padz`. The home boundary is the sandbox root,
+/// so discovery never walks out of it. A test isolates each run by pointing
+/// `$TMPDIR` at a fresh directory.
+pub fn sandbox() -> std::io::Result<(PadzEnv, PathBuf)> {
+    let root = std::env::temp_dir().join("padz-test-mode");
+    let global = root.join("global");
+    let project = root.join("project");
+    std::fs::create_dir_all(&global)?;
+    std::fs::create_dir_all(project.join(".git"))?;
+    Ok((
+        PadzEnv {
+            global_data_dir: global,
+            home_dir: Some(root),
+        },
+        project,
+    ))
+}
@@ -0,0 +1,195 @@
+//! Telling other programs what padz changed (`event_command`, `event_socket`).
+//!
+//! The store notes each change a command writes (see
+//! [`padzapp::store::events`]); once each API call returns, [`AppState::with_api`]
+//! hands what it noted to the [`EventSink`], one JSON object per line:
+//!
+//! ```text
+//! {"event":"pinned","id":"…","title":"Groceries","scope":"project","at":"2026-10-15T09:12:03Z"}
+//! ```
+//!
+//! `event_command` runs through `sh` with the lines on its stdin, within
+//! `command_timeout`; its output is discarded. `event_socket` names a Unix
+//! socket a listener is already bound to, and the lines are written to it.
+//! Either way emission is best-effort: a listener that is missing, failing or
+//! slow is reported on stderr and never fails the command, which has already
+//! written by then. Dry runs and `--test-mode` emit nothing.
+//!
+//! [`AppState::with_api`]: super::handlers::AppState::with_api
+
+use super::subprocess::{self, Limit};
+use padzapp::error::{PadzError, Result};
+use padzapp::store::events::StoreEvent;
+use std::io::Write;
+use std::path::{Path, PathBuf};
+use std::process::{Command, Stdio};
+
+/// How long a socket listener may take to accept the events.
+#[cfg(unix)]
+const SOCKET_TIMEOUT: std::time::Duration = std::time::Duration::from_secs(5);
+
+/// Where events go.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct EventSink {
+    command: Option<String>,
+    socket: Option<PathBuf>,
+}
+
+impl EventSink {
+    /// A sink for the configured destinations; `None` when there are none.
+    pub fn new(command: Option<String>, socket: Option<PathBuf>) -> Option<Self> {
+        (command.is_some() || socket.is_some()).then_some(Self { command, socket })
+    }
+
+    /// The destinations, as their settings name them.
+    pub fn describe(&self) -> Vec<String> {
+        let mut destinations = Vec::new();
+        if let Some(command) = &self.command {
+            destinations.push(format!("event_command: {}", command));
+        }
+        if let Some(socket) = &self.socket {
+            destinations.push(format!("event_socket: {}", socket.display()));
+        }
+        destinations
+    }
+
+    /// Hands `events` to every destination, warning on stderr about any that
+    /// could not take them.
+    pub fn emit(&self, events: &[StoreEvent]) {
+        if events.is_empty() {
+            return;
+        }
+        let lines = lines(events);
+        if let Some(command) = &self.command {
+            if let Err(e) = run_command(command, lines.clone()) {
+                eprintln!("Warning: event_command failed: {}", e);
+            }
+        }
+        if let Some(socket) = &self.socket {
+            if let Err(e) = send(socket, &lines) {
+                eprintln!(
+                    "Warning: could not send events to {}: {}",
+                    socket.display(),
+                    e
+                );
+            }
+        }
+    }
+}
+
+/// `events` as JSON lines.
+fn lines(events: &[StoreEvent]) -> Vec<u8> {
+    let mut out = Vec::new();
+    for event in events {
+        if let Ok(line) = serde_json::to_vec(event) {
+            out.extend(line);
+            out.push(b'\n');
+        }
+    }
+    out
+}
+
+fn run_command(command: &str, input: Vec<u8>) -> Result<()> {
+    let what = format!("event command '{}'", command);
+    let mut child = Command::new("sh")
+        .arg("-c")
+        .arg(command)
+        .stdin(Stdio::piped())
+        .stdout(Stdio::null())
+        .stderr(Stdio::piped())
+        .spawn()
+        .map_err(|e| PadzError::Api(format!("Failed to run {}: {}", what, e)))?;
+    // Fed from its own thread, so a command that never reads its stdin cannot
+    // block us on a full pipe; dropping the pipe is its end of input.
+    if let Some(mut stdin) = child.stdin.take() {
+        std::thread::spawn(move || {
+            let _ = stdin.write_all(&input);
+        });
+    }
+    let output = subprocess::wait(child, Limit::Command, &what)?;
+    if output.status.success() {
+        return Ok(());
+    }
+    Err(PadzError::Api(format!(
+        "{} exited with {}: {}",
+        what,
+        output.status,
+        String::from_utf8_lossy(&output.stderr).trim_end()
+    )))
+}
+
+#[cfg(unix)]
+fn send(socket: &Path, lines: &[u8]) -> std::io::Result<()> {
+    let mut stream = std::os::unix::net::UnixStream::connect(socket)?;
+    stream.set_write_timeout(Some(SOCKET_TIMEOUT))?;
+    stream.write_all(lines)
+}
+
+#[cfg(not(unix))]
+fn send(_socket: &Path, _lines: &[u8]) -> std::io::Result<()> {
+    Err(std::io::Error::new(
+        std::io::ErrorKind::Unsupported,
+        "Unix sockets are not available on this platform",
+    ))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use padzapp::model::{Metadata, Scope};
+    use padzapp::store::events::EventKind;
+
+    fn events() -> Vec<StoreEvent> {
+        let metadata = Metadata::new("Groceries".into());
+        vec![
+            StoreEvent::new(EventKind::Created, &metadata, Scope::Project),
+            StoreEvent::new(EventKind::Pinned, &metadata, Scope::Project),
+        ]
+    }
+
+    #[test]
+    fn a_sink_needs_a_destination() {
+        assert_eq!(EventSink::new(None, None), None);
+        assert!(EventSink::new(Some("true".into()), None).is_some());
+    }
+
+    #[test]
+    fn the_command_reads_one_json_object_per_line() {
+        let dir = tempfile::TempDir::new().unwrap();
+        let received = dir.path().join("events");
+        run_command(&format!("cat > '{}'", received.display()), lines(&events())).unwrap();
+
+        let text = std::fs::read_to_string(&received).unwrap();
+        let kinds: Vec<String> = text
+            .lines()
+            .map(|line| {
+                let value: serde_json::Value = serde_json::from_str(line).unwrap();
+                value["event"].as_str().unwrap().to_string()
+            })
+            .collect();
+        assert_eq!(kinds, vec!["created", "pinned"]);
+    }
+
+    #[test]
+    fn a_failing_command_is_an_error() {
+        assert!(run_command("echo nope >&2; exit 2", lines(&events())).is_err());
+    }
+
+    #[cfg(unix)]
+    #[test]
+    fn the_socket_receives_the_same_lines() {
+        use std::io::Read;
+        use std::os::unix::net::UnixListener;
+
+        let dir = tempfile::TempDir::new().unwrap();
+        let path = dir.path().join("padz.sock");
+        let listener = UnixListener::bind(&path).unwrap();
+        send(&path, &lines(&events())).unwrap();
+
+        let (mut stream, _) = listener.accept().unwrap();
+        let mut text = String::new();
+        stream.read_to_string(&mut text).unwrap();
+        assert_eq!(text.lines().count(), 2);
+        assert!(send(&dir.path().join("missing.sock"), b"").is_err());
+    }
+}
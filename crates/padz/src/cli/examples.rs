@@ -0,0 +1,640 @@
+//! Worked examples for each command, kept in one place.
+//!
+//! [`EXAMPLES`] maps a command path (`list`, `tag add`) to its examples. They
+//! reach the user three ways:
+//!
+//! - `padz <command> --examples` prints them, highlighted at a terminal
+//!   ([`render`]); `parse_cli` answers it before the arguments are checked, so
+//!   `padz delete --examples` works without naming a pad.
+//! - The man pages' EXAMPLES sections ([`super::man`]), from the command
+//!   tree [`attach`] hangs them on as each command's `after_long_help`.
+//! - Completion: [`describe`] adds a subcommand's first example to the
+//!   description shown beside it.
+//!
+//! Every example must parse against the real CLI; a unit test holds them to it.
+
+use clap::Command;
+
+/// One example: what it does, and the command line that does it.
+pub struct Example {
+    pub description: &'static str,
+    pub command: &'static str,
+}
+
+const fn ex(description: &'static str, command: &'static str) -> Example {
+    Example {
+        description,
+        command,
+    }
+}
+
+/// Examples by command path. Paths use command names, not aliases.
+pub const EXAMPLES: &[(&str, &[Example])] = &[
+    (
+        "create",
+        &[
+            ex(
+                "Create a pad and write it in your editor",
+                "padz create Meeting notes",
+            ),
+            ex(
+                "Create a pad from the title alone",
+                "padz create --no-editor Call the plumber",
+            ),
+            ex(
+                "Capture the output of a command",
+                "git log -5 | padz create",
+            ),
+            ex(
+                "Run a command and trim its output in your editor first",
+                "padz create --from \"kubectl describe pod api-0\"",
+            ),
+            ex(
+                "Keep what you just copied as a pad",
+                "padz create --from-clipboard",
+            ),
+            ex(
+                "Title the pad after the current git branch",
+                "padz create --auto-title git",
+            ),
+            ex(
+                "A note on this git branch, for padz list --branch",
+                "padz create --branch Review comments",
+            ),
+            ex(
+                "Nest a pad inside pad 2",
+                "padz create --inside 2 Follow-ups",
+            ),
+        ],
+    ),
+    (
+        "list",
+        &[
+            ex("List pads, newest first", "padz list"),
+            ex("Show a preview of each", "padz list --peek"),
+            ex("Only the pads tagged work", "padz list --tag work"),
+            ex(
+                "Pads of one project older than a month",
+                "padz list --where \"project=webapp and created>30d\"",
+            ),
+            ex("Deleted pads, to restore one", "padz list --deleted"),
+            ex(
+                "Notes written on the git branch checked out",
+                "padz list --branch",
+            ),
+            ex(
+                "Keep the list on screen, redrawn as pads change",
+                "padz list --watch",
+            ),
+        ],
+    ),
+    (
+        "search",
+        &[
+            ex("Search titles and bodies", "padz search invoice"),
+            ex(
+                "Search deleted and archived pads too",
+                "padz search --all invoice",
+            ),
+            ex(
+                "Search every registered project and the global store",
+                "padz search --all-scopes invoice",
+            ),
+        ],
+    ),
+    (
+        "peek",
+        &[
+            ex("Preview every pad", "padz peek"),
+            ex("Preview pads 1 to 3", "padz peek 1-3"),
+        ],
+    ),
+    (
+        "today",
+        &[ex(
+            "Write in today's note, filed under the Journal pad",
+            "padz today",
+        )],
+    ),
+    (
+        "journal ls",
+        &[ex("Every daily note, newest first", "padz journal ls")],
+    ),
+    (
+        "ref add",
+        &[ex(
+            "List global pad 2 in this project; edits go to the global pad",
+            "padz ref add 2",
+        )],
+    ),
+    (
+        "view",
+        &[
+            ex("Print pad 1 (and copy it to the clipboard)", "padz view 1"),
+            ex("Print two pads, separated", "padz view 1 3"),
+            ex(
+                "Print the pinned pad with its metadata",
+                "padz view p1 --meta",
+            ),
+        ],
+    ),
+    (
+        "copy",
+        &[
+            ex("Copy pad 1 to the clipboard", "padz copy 1"),
+            ex(
+                "Add pad 2 after what the clipboard holds",
+                "padz copy --append 2",
+            ),
+        ],
+    ),
+    (
+        "speak",
+        &[
+            ex("Read pad 2 aloud", "padz speak 2"),
+            ex(
+                "Record pad 2, slower, to an audio file",
+                "padz speak 2 --rate 150 --to notes.wav",
+            ),
+        ],
+    ),
+    (
+        "open",
+        &[
+            ex("Edit pad 1 in your editor", "padz open 1"),
+            ex("Edit it, then spell-check it", "padz open 1 --spell"),
+        ],
+    ),
+    (
+        "append",
+        &[
+            ex(
+                "Add a command's output to the end of pad 1",
+                "padz append 1",
+            ),
+            ex(
+                "Add a line without piping anything",
+                "padz append 1 -m \"call back Tom\"",
+            ),
+        ],
+    ),
+    (
+        "prepend",
+        &[ex(
+            "Put a new first line, and so a new title, on pad 1",
+            "padz prepend 1 -m \"Done: release notes\"",
+        )],
+    ),
+    (
+        "delete",
+        &[
+            ex(
+                "Delete pads 1 and 3 (they can be restored)",
+                "padz delete 1 3",
+            ),
+            ex("Delete every completed pad", "padz delete --completed"),
+            ex(
+                "Delete the pads untouched for two weeks",
+                "padz delete --where \"updated>2w\"",
+            ),
+        ],
+    ),
+    (
+        "restore",
+        &[ex(
+            "Restore the most recently deleted pad",
+            "padz restore d1",
+        )],
+    ),
+    (
+        "which",
+        &[ex(
+            "Show which file, store and scope pad 1 is",
+            "padz which 1",
+        )],
+    ),
+    (
+        "move",
+        &[
+            ex("Nest pads 2 and 3 under pad 1", "padz move 2 3 1"),
+            ex(
+                "Move pad 3 to the global store, keeping its pin and dates",
+                "padz mv 3 --to global",
+            ),
+        ],
+    ),
+    (
+        "pin",
+        &[
+            ex("Keep pad 4 at the top of the list, as p1", "padz pin 4"),
+            ex(
+                "Pin past max_pins, unpinning the pad pinned longest ago",
+                "padz pin 4 --evict",
+            ),
+        ],
+    ),
+    (
+        "unpin",
+        &[ex("Unpin the first pinned pad", "padz unpin p1")],
+    ),
+    (
+        "purge",
+        &[ex(
+            "Permanently remove every deleted pad",
+            "padz purge --yes",
+        )],
+    ),
+    (
+        "undo",
+        &[ex(
+            "Take back the last change, such as a delete or a purge",
+            "padz undo",
+        )],
+    ),
+    (
+        "export",
+        &[
+            ex("Export every pad to an archive", "padz export"),
+            ex(
+                "Export pads 1 to 3 into one file",
+                "padz export --single-file Notes 1-3",
+            ),
+            ex(
+                "One zip of every pad and its metadata, for padz import",
+                "padz export --format zip",
+            ),
+            ex(
+                "A spreadsheet of every pad's metadata, for an audit",
+                "padz export --csv --fields id,title,project,created,size,tags",
+            ),
+        ],
+    ),
+    (
+        "print",
+        &[
+            ex("Print pad 3, its tasks as boxes to tick", "padz print 3"),
+            ex(
+                "Lay out two pads in a PDF on letter paper",
+                "padz print 3 5 --to groceries.pdf --paper letter",
+            ),
+        ],
+    ),
+    (
+        "publish",
+        &[
+            ex("Share pad 2 as a secret gist", "padz publish 2 --gist"),
+            ex(
+                "Publish it as a public GitLab snippet",
+                "padz publish 2 --gitlab-snippet --public",
+            ),
+            ex(
+                "Send the edits made since to every published pad",
+                "padz publish --update",
+            ),
+        ],
+    ),
+    (
+        "release-notes",
+        &[
+            ex(
+                "Draft notes from the pads tagged release since the latest tag",
+                "padz release-notes",
+            ),
+            ex(
+                "Gather the api-tagged pads written since v1.2.0",
+                "padz release-notes --tag api --since v1.2.0",
+            ),
+        ],
+    ),
+    (
+        "import",
+        &[
+            ex("Import a directory of notes", "padz import ~/notes"),
+            ex("Import two files", "padz import todo.md ideas.txt"),
+            ex(
+                "Import a whole tree of notes, each titled after its file",
+                "padz import -r --filename-title ~/notes",
+            ),
+        ],
+    ),
+    (
+        "tag add",
+        &[ex(
+            "Tag pads 1 and 2 with work and urgent",
+            "padz tag add 1 2 work urgent",
+        )],
+    ),
+    (
+        "tag remove",
+        &[ex(
+            "Take the urgent tag off pad 1",
+            "padz tag remove 1 urgent",
+        )],
+    ),
+    (
+        "config",
+        &[
+            ex("Show every setting", "padz config"),
+            ex("Write new pads as Markdown", "padz config set format md"),
+        ],
+    ),
+    (
+        "completion",
+        &[
+            ex("Print the zsh completion script", "padz completion zsh"),
+            ex(
+                "Install completions for your shell",
+                "padz completion --install",
+            ),
+        ],
+    ),
+    (
+        "docs install-man",
+        &[ex("Install the man pages", "padz docs install-man")],
+    ),
+    ("tour", &[ex("Take the tour", "padz tour")]),
+    (
+        "explain",
+        &[ex(
+            "See which pads a delete would hit and which files it would write",
+            "padz explain delete --where \"tag=stale\"",
+        )],
+    ),
+    (
+        "mcp",
+        &[
+            ex("Serve this project's pads to an AI assistant", "padz mcp"),
+            ex("Serve the global pads instead", "padz -g mcp"),
+        ],
+    ),
+    (
+        "open-uri",
+        &[
+            ex(
+                "Open the pad a link points to",
+                "padz open-uri padz://project/550e8400-e29b-41d4-a716-446655440000",
+            ),
+            ex(
+                "Make padz the handler of padz:// links",
+                "padz open-uri --install",
+            ),
+        ],
+    ),
+];
+
+/// The examples of the command at `path` (`["tag", "add"]`); empty when it
+/// has none.
+pub fn for_command(path: &[&str]) -> &'static [Example] {
+    let key = path.join(" ");
+    EXAMPLES
+        .iter()
+        .find(|(command, _)| *command == key)
+        .map(|(_, examples)| *examples)
+        .unwrap_or(&[])
+}
+
+/// `cmd` with every subcommand's examples as its `after_long_help`, which
+/// the man pages render.
+pub fn attach(cmd: Command) -> Command {
+    walk(cmd, &mut Vec::new(), &|sub, examples| {
+        sub.after_long_help(plain(examples))
+    })
+}
+
+/// [`attach`], and each subcommand's first example added to its `about`, the
+/// description completion shows beside the candidate.
+pub fn describe(cmd: Command) -> Command {
+    walk(cmd, &mut Vec::new(), &|sub, examples| {
+        let first = examples[0].command;
+        let about = match sub.get_about() {
+            Some(about) => format!("{} (e.g. {})", about, first),
+            None => first.to_string(),
+        };
+        sub.about(about).after_long_help(plain(examples))
+    })
+}
+
+/// Apply `with` to every subcommand of `cmd` (at any depth) that has examples.
+fn walk(
+    mut cmd: Command,
+    path: &mut Vec<String>,
+    with: &dyn Fn(Command, &'static [Example]) -> Command,
+) -> Command {
+    let names: Vec<String> = cmd
+        .get_subcommands()
+        .map(|sub| sub.get_name().to_string())
+        .collect();
+    for name in names {
+        path.push(name.clone());
+        let examples = for_command(&path.iter().map(String::as_str).collect::<Vec<_>>());
+        let mut sub_path = path.clone();
+        cmd = cmd.mut_subcommand(&name, |sub| {
+            let sub = walk(sub, &mut sub_path, with);
+            if examples.is_empty() {
+                sub
+            } else {
+                with(sub, examples)
+            }
+        });
+        path.pop();
+    }
+    cmd
+}
+
+/// Examples as plain text: `# description`, then `$ command`.
+fn plain(examples: &[Example]) -> String {
+    examples
+        .iter()
+        .map(|example| format!("# {}\n$ {}", example.description, example.command))
+        .collect::<Vec<_>>()
+        .join("\n\n")
+}
+
+/// The `--examples` output for the command at `path`. Styled with
+/// `console`, so it is plain text when stdout is not a terminal.
+pub fn render(path: &[&str]) -> String {
+    let name = std::iter::once("padz")
+        .chain(path.iter().copied())
+        .collect::<Vec<_>>()
+        .join(" ");
+    let examples = for_command(path);
+    if examples.is_empty() {
+        return format!("No examples for `{}` yet; see `{} --help`.\n", name, name);
+    }
+    let mut out = format!("{}\n", console::style(format!("Examples: {}", name)).bold());
+    for example in examples {
+        out.push('\n');
+        out.push_str(&format!(
+            "  {}\n",
+            console::style(format!("# {}", example.description)).dim()
+        ));
+        out.push_str(&format!(
+            "  {} {}\n",
+            console::style("$").dim(),
+            highlight(example.command, path.len() + 1)
+        ));
+    }
+    out
+}
+
+/// The program and command path in bold, flags in cyan, the rest as typed.
+/// `command_words` is how many leading words name the command (`padz tag add`
+/// is three).
+fn highlight(command: &str, command_words: usize) -> String {
+    let mut in_padz = false;
+    let mut seen = 0;
+    command
+        .split(' ')
+        .map(|word| {
+            if word == "padz" {
+                in_padz = true;
+                seen = 0;
+            }
+            if in_padz && seen < command_words {
+                seen += 1;
+                return console::style(word).bold().to_string();
+            }
+            if word.starts_with('-') {
+                return console::style(word).cyan().to_string();
+            }
+            word.to_string()
+        })
+        .collect::<Vec<_>>()
+        .join(" ")
+}
+
+/// The command path `padz ... --examples` asks about, or `None` when the
+/// arguments do not ask for examples. Words before the first subcommand (the
+/// values of global flags) are skipped; the path ends at the first word that
+/// is not a subcommand. Aliases (`ls`) resolve to their command (`list`).
+pub fn requested(cmd: &Command, args: &[String]) -> Option<Vec<String>> {
+    if !args.iter().any(|arg| arg == "--examples") {
+        return None;
+    }
+    let mut path = Vec::new();
+    let mut current = cmd;
+    for arg in args.iter().filter(|arg| !arg.starts_with('-')) {
+        match current.find_subcommand(arg) {
+            Some(sub) => {
+                path.push(sub.get_name().to_string());
+                current = sub;
+            }
+            None if path.is_empty() => continue,
+            None => break,
+        }
+    }
+    Some(path)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::cli::setup::Cli;
+    use clap::{CommandFactory, Parser};
+
+    #[test]
+    fn every_example_parses_against_the_cli() {
+        for (command, examples) in EXAMPLES {
+            for example in *examples {
+                // A pipe's left side is the shell's business, not padz's.
+                let line = example.command.rsplit("| ").next().unwrap();
+                let argv: Vec<&str> = line.split(' ').collect();
+                assert_eq!(argv[0], "padz", "example of `{}`", command);
+                assert!(
+                    Cli::try_parse_from(&argv).is_ok(),
+                    "example `{}` of `{}` does not parse",
+                    example.command,
+                    command
+                );
+            }
+        }
+    }
+
+    #[test]
+    fn every_path_names_a_command() {
+        let cli = Cli::command();
+        for (command, _) in EXAMPLES {
+            let mut current = &cli;
+            for word in command.split(' ') {
+                current = current
+                    .get_subcommands()
+                    .find(|sub| sub.get_name() == word)
+                    .unwrap_or_else(|| panic!("`{}` is not a command", command));
+            }
+        }
+    }
+
+    #[test]
+    fn requested_resolves_aliases_and_skips_global_values() {
+        let cli = Cli::command();
+        let args = |line: &str| line.split(' ').map(String::from).collect::<Vec<_>>();
+
+        assert_eq!(requested(&cli, &args("list")), None);
+        assert_eq!(
+            requested(&cli, &args("ls --examples")),
+            Some(vec!["list".to_string()])
+        );
+        assert_eq!(
+            requested(&cli, &args("--data /tmp/x tag add --examples")),
+            Some(vec!["tag".to_string(), "add".to_string()])
+        );
+        assert_eq!(
+            requested(&cli, &args("delete --examples 3")),
+            Some(vec!["delete".to_string()])
+        );
+        assert_eq!(requested(&cli, &args("--examples")), Some(vec![]));
+    }
+
+    #[test]
+    fn render_lists_each_example_under_its_description() {
+        let text = console::strip_ansi_codes(&render(&["restore"])).to_string();
+        assert_eq!(
+            text,
+            "Examples: padz restore\n\n  # Restore the most recently deleted pad\n  $ padz restore d1\n"
+        );
+        assert!(render(&["recent"]).starts_with("No examples for `padz recent`"));
+    }
+
+    #[test]
+    fn attach_feeds_long_help_at_every_depth() {
+        let cmd = attach(Cli::command());
+        let restore = cmd.find_subcommand("restore").unwrap();
+        assert!(restore
+            .get_after_long_help()
+            .unwrap()
+            .to_string()
+            .contains("# Restore the most recently deleted pad\n$ padz restore d1"));
+        assert_eq!(
+            restore.get_about().unwrap().to_string(),
+            "Restore deleted pads"
+        );
+        let add = cmd
+            .find_subcommand("tag")
+            .and_then(|tag| tag.find_subcommand("add"))
+            .unwrap();
+        assert!(add.get_after_long_help().is_some());
+        assert!(cmd
+            .find_subcommand("recent")
+            .unwrap()
+            .get_after_long_help()
+            .is_none());
+    }
+
+    #[test]
+    fn describe_names_the_first_example_for_completion() {
+        let cmd = describe(Cli::command());
+        assert_eq!(
+            cmd.find_subcommand("restore")
+                .unwrap()
+                .get_about()
+                .unwrap()
+                .to_string(),
+            "Restore deleted pads (e.g. padz restore d1)"
+        );
+    }
+
+    #[test]
+    fn parser_accepts_the_examples_flag() {
+        assert!(Cli::try_parse_from(["padz", "list", "--examples"]).is_ok());
+    }
+}
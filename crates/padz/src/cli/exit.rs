@@ -0,0 +1,26 @@
+//! The exit status of a query that matched nothing.
+//!
+//! `padz search --quiet` prints nothing and answers with its exit status
+//! alone, as `grep -q` does: 0 when a pad matched, [`EXIT_NO_MATCH`] when none
+//! did, so `if padz search -q TODO; then ...` needs no output parsing.
+//! `search --count` exits the same way after printing its number.
+//!
+//! Handlers return data, not statuses, so the one that found nothing records
+//! it here, and `main` reads it once the command has otherwise succeeded.
+
+use std::sync::atomic::{AtomicBool, Ordering};
+
+/// Exit status of a query that matched no pad.
+pub const EXIT_NO_MATCH: i32 = 1;
+
+static NO_MATCH: AtomicBool = AtomicBool::new(false);
+
+/// Records that the query of this run matched no pad.
+pub fn record_no_match() {
+    NO_MATCH.store(true, Ordering::Relaxed);
+}
+
+/// Whether the query of this run matched no pad.
+pub fn no_match() -> bool {
+    NO_MATCH.load(Ordering::Relaxed)
+}
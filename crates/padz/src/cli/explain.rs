@@ -0,0 +1,286 @@
+//! `padz explain <COMMAND>`: what a command would do, without doing it.
+//!
+//! The command is not simulated by a second, descriptive code path that could
+//! drift from the real one. It runs as a `--dry-run`: selectors resolve,
+//! validation runs, and the store takes every write but holds it in memory
+//! (see [`padzapp::store::write_guard`]). What the run leaves behind is the
+//! explanation:
+//!
+//! - the scope and store it resolved to;
+//! - the pads its structured output names, with their titles;
+//! - the changes the store noted, as event listeners would hear them;
+//! - the store files its held writes would have written or removed;
+//! - the listeners (`event_command`, `event_socket`) that would hear them.
+//!
+//! The command's own output is set aside, and its clipboard writes dropped.
+//! A command that would fail is explained too: the error is what it would do.
+
+use super::clipboard::DiscardingClipboardWriter;
+use super::commands::build_dispatch_app;
+use super::handlers::AppState;
+use super::setup::{build_command, Cli, Commands};
+use padzapp::error::{PadzError, Result};
+use padzapp::model::Scope;
+use padzapp::store::events::StoreEvent;
+use standout::cli::RunResult;
+use standout::OutputMode;
+use std::cell::RefCell;
+use std::path::PathBuf;
+use std::rc::Rc;
+use uuid::Uuid;
+
+/// What the explained command's API calls changed and held back, noted by
+/// [`AppState::with_api`] as each call returns.
+#[derive(Debug, Default)]
+pub struct Explanation {
+    changes: RefCell<Vec<StoreEvent>>,
+    files: RefCell<Vec<PathBuf>>,
+}
+
+impl Explanation {
+    /// Adds `changes` to those noted so far. `files` is every file held back
+    /// since the run began, so it replaces the last list.
+    pub fn note(&self, changes: Vec<StoreEvent>, files: Vec<PathBuf>) {
+        self.changes.borrow_mut().extend(changes);
+        *self.files.borrow_mut() = files;
+    }
+}
+
+/// What `padz explain` reports.
+#[derive(Debug, Clone, PartialEq)]
+pub struct ExplainView {
+    /// The command as typed after `padz explain`.
+    pub command: String,
+    pub scope: Scope,
+    pub store: PathBuf,
+    /// The pads the command's result names, once each.
+    pub pads: Vec<(Uuid, String)>,
+    pub changes: Vec<StoreEvent>,
+    pub files: Vec<PathBuf>,
+    pub listeners: Vec<String>,
+    /// Why the command would fail, if it would.
+    pub error: Option<String>,
+}
+
+/// Runs `padz explain`: explains `command` run with the global flags `cli`
+/// was given, and prints the explanation.
+pub fn run(cli: &Cli, command: &[String]) -> Result<()> {
+    let argv = argv(cli, command);
+    let inner = Cli::try_parse_from(&argv).map_err(|e| PadzError::Api(e.to_string()))?;
+    match &inner.command {
+        Some(Commands::Explain { .. }) => {
+            return Err(PadzError::Api(
+                "`padz explain` explains other commands".into(),
+            ))
+        }
+        Some(Commands::Mcp {}) => {
+            return Err(PadzError::Api(
+                "`padz mcp` is a server; explain the commands it stands for instead".into(),
+            ))
+        }
+        Some(
+            Commands::Completion { .. }
+            | Commands::Docs { .. }
+            | Commands::Tour { .. }
+            | Commands::PromptSegment {}
+            | Commands::OpenUri { .. }
+            | Commands::Config { .. },
+        ) => {
+            return Err(PadzError::Api(format!(
+                "`padz {}` does not use the store; there is nothing to explain",
+                command.join(" ")
+            )))
+        }
+        _ => {}
+    }
+    let state = super::commands::create_app_state(&inner)?;
+    let view = explain(state, &argv, command.join(" "))?;
+    print!("{}", render(&view));
+    Ok(())
+}
+
+/// Runs `argv` on `state`, which must be a dry run, and reports what it did.
+pub fn explain(state: AppState, argv: &[String], command: String) -> Result<ExplainView> {
+    if !state.dry_run() {
+        return Err(PadzError::Api("Only a dry run can be explained".into()));
+    }
+    let scope = state.scope;
+    let store = state.with_api(|api| api.paths().scope_dir(scope))?;
+    let listeners = state
+        .listeners
+        .as_ref()
+        .map(|sink| sink.describe())
+        .unwrap_or_default();
+    let explanation = Rc::new(Explanation::default());
+    let state = state
+        .with_clipboard_writer(Rc::new(DiscardingClipboardWriter))
+        .with_explanation(explanation.clone());
+
+    let app = build_dispatch_app(state);
+    let matches = app.parse_from(build_command(), argv);
+    let (pads, error) = match app.dispatch(matches, OutputMode::Json) {
+        RunResult::Handled(output) => (named_pads(&output), None),
+        RunResult::Error(msg) => (
+            Vec::new(),
+            Some(msg.trim_start_matches("Error: ").trim_end().to_string()),
+        ),
+        _ => (Vec::new(), None),
+    };
+    let changes = explanation.changes.take();
+    let files = explanation.files.take();
+    Ok(ExplainView {
+        command,
+        scope,
+        store,
+        pads,
+        changes,
+        files,
+        listeners,
+        error,
+    })
+}
+
+/// The argv of the explained command: `command` as a dry run, under the
+/// global flags given to `explain` itself.
+fn argv(cli: &Cli, command: &[String]) -> Vec<String> {
+    let mut argv = vec!["padz".to_string(), "--dry-run".to_string()];
+    let flags = [
+        (cli.global, "--global"),
+        (cli.ignore_errors, "--ignore-errors"),
+        (cli.force, "--force"),
+        (cli.test_mode, "--test-mode"),
+    ];
+    for (set, flag) in flags {
+        if set {
+            argv.push(flag.to_string());
+        }
+    }
+    for (value, flag) in [(&cli.data, "--data"), (&cli.remote, "--remote")] {
+        if let Some(value) = value {
+            argv.push(flag.to_string());
+            argv.push(value.clone());
+        }
+    }
+    argv.extend(command.iter().cloned());
+    argv
+}
+
+/// The pads a structured result names: every object with `metadata` holding
+/// an `id` and a `title`, once each.
+fn named_pads(json: &str) -> Vec<(Uuid, String)> {
+    fn walk(value: &serde_json::Value, pads: &mut Vec<(Uuid, String)>) {
+        match value {
+            serde_json::Value::Object(map) => {
+                let meta = map.get("metadata");
+                let id = meta
+                    .and_then(|m| m.get("id"))
+                    .and_then(|id| id.as_str())
+                    .and_then(|id| Uuid::parse_str(id).ok());
+                let title = meta.and_then(|m| m.get("title")).and_then(|t| t.as_str());
+                if let (Some(id), Some(title)) = (id, title) {
+                    if !pads.iter().any(|(seen, _)| *seen == id) {
+                        pads.push((id, title.to_string()));
+                    }
+                }
+                map.values().for_each(|v| walk(v, pads));
+            }
+            serde_json::Value::Array(items) => items.iter().for_each(|v| walk(v, pads)),
+            _ => {}
+        }
+    }
+    let mut pads = Vec::new();
+    if let Ok(value) = serde_json::from_str(json) {
+        walk(&value, &mut pads);
+    }
+    pads
+}
+
+/// The explanation as text, a heading per part.
+fn render(view: &ExplainView) -> String {
+    let scope = match view.scope {
+        Scope::Project => "project",
+        Scope::Global => "global",
+    };
+    let mut out = format!("padz {}\n", view.command);
+    out.push_str(&format!("Scope: {} ({})\n", scope, view.store.display()));
+
+    let mut section = |heading: &str, lines: Vec<String>| {
+        if lines.is_empty() {
+            out.push_str(&format!("{}: none\n", heading));
+        } else {
+            out.push_str(&format!("{}:\n", heading));
+            for line in lines {
+                out.push_str(&format!("  {}\n", line));
+            }
+        }
+    };
+    section(
+        "Pads",
+        view.pads
+            .iter()
+            .map(|(id, title)| format!("{}  {}", short(id), title))
+            .collect(),
+    );
+    section(
+        "Changes",
+        view.changes
+            .iter()
+            .map(|event| {
+                let kind = serde_json::to_value(event.kind)
+                    .ok()
+                    .and_then(|v| v.as_str().map(str::to_string))
+                    .unwrap_or_default();
+                format!("{} {}  {}", kind, short(&event.id), event.title)
+            })
+            .collect(),
+    );
+    section(
+        "Writes",
+        view.files
+            .iter()
+            .map(|file| file.display().to_string())
+            .collect(),
+    );
+    // Listeners only hear about changes.
+    let listeners = if view.changes.is_empty() {
+        Vec::new()
+    } else {
+        view.listeners.clone()
+    };
+    section("Listeners", listeners);
+    if let Some(error) = &view.error {
+        out.push_str(&format!("Fails: {}\n", error));
+    }
+    out
+}
+
+/// The first eight hex digits of `id`, as `padz` shows uuids elsewhere.
+fn short(id: &Uuid) -> String {
+    id.simple().to_string()[..8].to_string()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn the_pads_a_result_names_are_read_once_each() {
+        let id = Uuid::new_v4();
+        let json = format!(
+            r#"{{"pads":[{{"pad":{{"metadata":{{"id":"{id}","title":"Groceries"}}}},
+                "children":[{{"pad":{{"metadata":{{"id":"{id}","title":"Groceries"}}}}}}]}}]}}"#
+        );
+        assert_eq!(named_pads(&json), vec![(id, "Groceries".to_string())]);
+        assert!(named_pads("not json").is_empty());
+    }
+
+    #[test]
+    fn the_global_flags_carry_over_to_the_explained_command() {
+        let cli = Cli::try_parse_from(["padz", "-g", "explain", "delete", "1"]).unwrap();
+        let command = vec!["delete".to_string(), "1".to_string()];
+        assert_eq!(
+            argv(&cli, &command),
+            ["padz", "--dry-run", "--global", "delete", "1"]
+        );
+    }
+}
@@ -0,0 +1,117 @@
+//! Values for `{{placeholder:name}}` fill-ins (`padz fill`).
+//!
+//! A pad with placeholders is a reusable snippet: `padz fill 3` asks for each
+//! placeholder's value, in the order they first appear, and prints the
+//! completed text (copying it to the clipboard, as `view` does). The pad
+//! itself is never changed.
+//!
+//! Values given as `--set name=value` are used as is and not asked for, so a
+//! fill can run from a script. Off a terminal there is no one to ask: every
+//! placeholder then needs a `--set`, and the missing ones are named in the
+//! error.
+
+use super::capabilities::Capabilities;
+use std::collections::HashMap;
+use std::io::{BufRead, Write};
+
+/// Parses `--set name=value` assignments. The value may be empty or contain
+/// `=`; the name may not be empty.
+pub fn parse_assignments(assignments: &[String]) -> Result<HashMap<String, String>, String> {
+    assignments
+        .iter()
+        .map(|assignment| match assignment.split_once('=') {
+            Some((name, value)) if !name.trim().is_empty() => {
+                Ok((name.trim().to_string(), value.to_string()))
+            }
+            _ => Err(format!(
+                "Invalid --set '{}': expected name=value",
+                assignment
+            )),
+        })
+        .collect()
+}
+
+/// Completes `given` with a value for every name in `names`, asking `ask`
+/// for the ones not given. `ask` returns `None` when it cannot ask; those
+/// names are reported together.
+pub fn complete(
+    names: &[String],
+    mut given: HashMap<String, String>,
+    mut ask: impl FnMut(&str) -> Option<String>,
+) -> Result<HashMap<String, String>, String> {
+    let mut missing = Vec::new();
+    for name in names {
+        if given.contains_key(name) {
+            continue;
+        }
+        match ask(name) {
+            Some(value) => {
+                given.insert(name.clone(), value);
+            }
+            None => missing.push(name.as_str()),
+        }
+    }
+    if missing.is_empty() {
+        Ok(given)
+    } else {
+        Err(format!(
+            "No value for {}: pass --set name=value",
+            missing.join(", ")
+        ))
+    }
+}
+
+/// Prompts for `name` on stderr and reads one line from a terminal stdin.
+/// `None` when the user cannot be asked or stdin cannot be read.
+pub fn prompt(name: &str, capabilities: &Capabilities) -> Option<String> {
+    if !capabilities.can_prompt() {
+        return None;
+    }
+    eprint!("{name}: ");
+    let _ = std::io::stderr().flush();
+    let mut answer = String::new();
+    match std::io::stdin().lock().read_line(&mut answer) {
+        Ok(0) | Err(_) => None,
+        Ok(_) => Some(answer.trim_end_matches(['\n', '\r']).to_string()),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn names(list: &[&str]) -> Vec<String> {
+        list.iter().map(|n| n.to_string()).collect()
+    }
+
+    #[test]
+    fn assignments_split_on_the_first_equals() {
+        let parsed = parse_assignments(&["host=db1".into(), "query=a=b".into()]).unwrap();
+        assert_eq!(parsed["host"], "db1");
+        assert_eq!(parsed["query"], "a=b");
+
+        assert!(parse_assignments(&["novalue".into()]).is_err());
+        assert!(parse_assignments(&["=x".into()]).is_err());
+    }
+
+    #[test]
+    fn only_missing_values_are_asked_for_in_order() {
+        let given = parse_assignments(&["user=root".into()]).unwrap();
+        let mut asked = Vec::new();
+        let values = complete(&names(&["user", "host", "port"]), given, |name| {
+            asked.push(name.to_string());
+            Some(format!("<{name}>"))
+        })
+        .unwrap();
+
+        assert_eq!(asked, vec!["host", "port"]);
+        assert_eq!(values["user"], "root");
+        assert_eq!(values["port"], "<port>");
+    }
+
+    #[test]
+    fn unanswerable_placeholders_are_named() {
+        let err = complete(&names(&["user", "host"]), HashMap::new(), |_| None).unwrap_err();
+        assert!(err.contains("user, host"), "got: {err}");
+    }
+}
@@ -0,0 +1,208 @@
+//! Capturing the working-tree state a pad is created in.
+//!
+//! With `capture_context = true`, `padz create` inside a git repository records
+//! the checked-out commit, the branch, whether tracked files had uncommitted
+//! changes, and the directory it ran from. Running `git` is a user-environment
+//! concern, so it happens here; `padzapp` only stores the result
+//! ([`padzapp::model::CreationContext`]).
+//!
+//! Capture is best-effort: outside a repository, in one with no commits yet, or
+//! without `git` on `PATH`, the pad is simply created without a context.
+//!
+//! `create --branch` captures the context whatever the setting, and `list
+//! --branch` lists the pads captured on the branch checked out ([`branch`]).
+//! `create --auto-title git` reads the same state for a title ([`title`]), and
+//! an unset `identity` is read from git's configuration ([`config`]).
+//! `release-notes` asks where the last release was cut ([`latest_tag`],
+//! [`committed_at`]) and what was committed since ([`commits_since`]).
+
+use super::subprocess::{self, Limit};
+use chrono::{DateTime, Utc};
+use padzapp::model::CreationContext;
+use std::path::Path;
+use std::process::Command;
+
+/// The git state of `cwd`, or `None` when it is not inside a repository with
+/// at least one commit.
+pub fn capture(cwd: &Path) -> Option<CreationContext> {
+    let commit = git(cwd, &["rev-parse", "HEAD"])?;
+    // `symbolic-ref` fails on a detached HEAD, which is exactly "no branch".
+    let branch = git(cwd, &["symbolic-ref", "--short", "-q", "HEAD"]);
+    // Untracked files (a fresh `.padz/` among them) do not make a tree dirty.
+    let dirty = git(cwd, &["status", "--porcelain", "--untracked-files=no"])
+        .is_some_and(|changes| !changes.is_empty());
+
+    Some(CreationContext {
+        cwd: cwd.to_string_lossy().into_owned(),
+        commit,
+        branch,
+        dirty,
+    })
+}
+
+/// The branch checked out in `cwd`; `None` on a detached HEAD or outside a
+/// repository.
+pub fn branch(cwd: &Path) -> Option<String> {
+    git(cwd, &["symbolic-ref", "--short", "-q", "HEAD"])
+}
+
+/// A title for a pad created in `cwd`: `notes: <branch>`, or on a detached
+/// HEAD the subject of the commit checked out. `None` when `cwd` is not inside
+/// a repository with at least one commit.
+pub fn title(cwd: &Path) -> Option<String> {
+    match git(cwd, &["symbolic-ref", "--short", "-q", "HEAD"]) {
+        Some(branch) if git(cwd, &["rev-parse", "-q", "--verify", "HEAD"]).is_some() => {
+            Some(format!("notes: {}", branch))
+        }
+        Some(_) => None,
+        None => git(cwd, &["log", "-1", "--format=%s"]).filter(|subject| !subject.is_empty()),
+    }
+}
+
+/// The value of git's configuration `key` (`user.name`) in `cwd`, or `None`
+/// when it is unset, blank, or there is no git.
+pub fn config(cwd: &Path, key: &str) -> Option<String> {
+    git(cwd, &["config", "--get", key]).filter(|value| !value.is_empty())
+}
+
+/// The most recent tag reachable from HEAD in `cwd`: where the last release
+/// was cut. `None` without one.
+pub fn latest_tag(cwd: &Path) -> Option<String> {
+    git(cwd, &["describe", "--tags", "--abbrev=0"]).filter(|tag| !tag.is_empty())
+}
+
+/// When the commit `reference` names in `cwd` was made; `None` when it names
+/// nothing there.
+pub fn committed_at(cwd: &Path, reference: &str) -> Option<DateTime<Utc>> {
+    let date = git(cwd, &["log", "-1", "--format=%cI", reference, "--"])?;
+    DateTime::parse_from_rfc3339(&date)
+        .ok()
+        .map(|date| date.with_timezone(&Utc))
+}
+
+/// The commits after `reference` up to HEAD in `cwd`, newest first, as their
+/// short id and subject. Merges are left out.
+pub fn commits_since(cwd: &Path, reference: &str) -> Vec<(String, String)> {
+    let range = format!("{}..HEAD", reference);
+    git(
+        cwd,
+        &["log", "--no-merges", "--format=%h%x1f%s", &range, "--"],
+    )
+    .unwrap_or_default()
+    .lines()
+    .filter_map(|line| line.split_once('\x1f'))
+    .map(|(id, subject)| (id.to_string(), subject.to_string()))
+    .collect()
+}
+
+/// Runs `git -C cwd <args>`, returning trimmed stdout on success.
+fn git(cwd: &Path, args: &[&str]) -> Option<String> {
+    let output = subprocess::output(
+        Command::new("git").arg("-C").arg(cwd).args(args),
+        Limit::Command,
+        "git",
+    )
+    .ok()?;
+    if !output.status.success() {
+        return None;
+    }
+    Some(String::from_utf8_lossy(&output.stdout).trim().to_string())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    /// Runs git with a throwaway identity, so commits work on any machine.
+    fn run_git(dir: &Path, args: &[&str]) -> bool {
+        Command::new("git")
+            .args(["-c", "user.name=padz", "-c", "user.email=padz@example.com"])
+            .arg("-C")
+            .arg(dir)
+            .args(args)
+            .output()
+            .is_ok_and(|o| o.status.success())
+    }
+
+    #[test]
+    fn outside_a_repository_there_is_nothing_to_capture() {
+        let temp = tempfile::tempdir().unwrap();
+        assert_eq!(capture(temp.path()), None);
+    }
+
+    #[test]
+    fn captures_commit_branch_and_dirty_state() {
+        let temp = tempfile::tempdir().unwrap();
+        let repo = temp.path();
+        // No git on this machine: nothing to exercise.
+        if !run_git(repo, &["init", "-q", "-b", "main"]) {
+            return;
+        }
+        std::fs::write(repo.join("a.txt"), "one").unwrap();
+        assert!(run_git(repo, &["add", "a.txt"]));
+        assert!(run_git(repo, &["commit", "-q", "-m", "first"]));
+
+        let clean = capture(repo).expect("a repository with a commit");
+        assert_eq!(clean.commit.len(), 40);
+        assert_eq!(clean.branch.as_deref(), Some("main"));
+        assert!(!clean.dirty);
+
+        // An untracked file leaves the tree clean; editing a tracked one does not.
+        std::fs::write(repo.join("b.txt"), "new").unwrap();
+        assert!(!capture(repo).unwrap().dirty);
+        std::fs::write(repo.join("a.txt"), "two").unwrap();
+        assert!(capture(repo).unwrap().dirty);
+
+        assert!(run_git(repo, &["checkout", "-q", "--detach"]));
+        assert_eq!(capture(repo).unwrap().branch, None);
+    }
+
+    #[test]
+    fn titles_come_from_the_branch_or_the_last_commit() {
+        let temp = tempfile::tempdir().unwrap();
+        let repo = temp.path();
+        assert_eq!(title(repo), None);
+        if !run_git(repo, &["init", "-q", "-b", "feature/login-refactor"]) {
+            return;
+        }
+        // A branch with no commits yet is no place to take a title from.
+        assert_eq!(title(repo), None);
+        assert!(run_git(
+            repo,
+            &["commit", "-q", "--allow-empty", "-m", "Split sessions"]
+        ));
+
+        assert_eq!(
+            title(repo).as_deref(),
+            Some("notes: feature/login-refactor")
+        );
+        assert!(run_git(repo, &["checkout", "-q", "--detach"]));
+        assert_eq!(title(repo).as_deref(), Some("Split sessions"));
+    }
+
+    #[test]
+    fn commits_are_read_from_the_last_tag() {
+        let temp = tempfile::tempdir().unwrap();
+        let repo = temp.path();
+        assert_eq!(latest_tag(repo), None);
+        if !run_git(repo, &["init", "-q", "-b", "main"]) {
+            return;
+        }
+        assert!(run_git(
+            repo,
+            &["commit", "-q", "--allow-empty", "-m", "First"]
+        ));
+        assert!(run_git(repo, &["tag", "v1.2.0"]));
+        assert!(run_git(
+            repo,
+            &["commit", "-q", "--allow-empty", "-m", "Retry twice"]
+        ));
+
+        assert_eq!(latest_tag(repo).as_deref(), Some("v1.2.0"));
+        assert!(committed_at(repo, "v1.2.0").is_some());
+        assert_eq!(committed_at(repo, "v9.9.9"), None);
+        let commits = commits_since(repo, "v1.2.0");
+        assert_eq!(commits.len(), 1);
+        assert_eq!(commits[0].1, "Retry twice");
+    }
+}
@@ -14,29 +14,64 @@
 // Allow non_snake_case for macro-generated __handler wrapper functions
 #![allow(non_snake_case)]
 
-use crate::cli::clipboard::{format_for_clipboard, ClipboardWriter, SystemClipboardWriter};
+use crate::cli::capabilities::Capabilities;
+use crate::cli::clipboard::{
+    format_for_clipboard, ClipboardReader, ClipboardWriter, SystemClipboardReader,
+    SystemClipboardWriter,
+};
+use crate::cli::duplicate::{self, Action};
 use crate::cli::errors::to_anyhow;
-use crate::cli::input::{RequestContent, CREATE_CONTENT, EDIT_CONTENT};
-use padzapp::api::{PadFilter, PadStatusFilter, PadzApi, TodoStatus};
-use padzapp::commands::{CmdOutcome, CmdResult, NestingMode, UpdateKind};
-use padzapp::config::PadzMode;
+use crate::cli::events::EventSink;
+use crate::cli::explain::Explanation;
+use crate::cli::input::{RequestContent, AMEND_CONTENT, CREATE_CONTENT, EDIT_CONTENT};
+use crate::cli::object_store::GlobalStoreSync;
+use crate::cli::signing::{signature_path, GpgSigner, Signer};
+use padzapp::api::{
+    Amend, ArchiveFormat, CompileOptions, CompileOrder, PadFilter, PadStatusFilter, PadzApi, Paper,
+    PrintFormat, PrintOptions, TodoStatus,
+};
+use padzapp::commands::{CmdResult, NestingMode};
+use padzapp::config::{
+    DuplicateTitle, JournalConfig, ListConfig, PadzMode, PublishConfig, TrashConfig,
+};
+use padzapp::directives::{self, Directive};
 use padzapp::error::PadzError;
-use padzapp::model::{extract_title_and_body, Scope};
+use padzapp::index::{DisplayIndex, DisplayPad};
+use padzapp::model::{extract_title_and_body, CodeAnchor, Scope};
+use padzapp::spell::{self, Dictionary};
 use padzapp::store::fs::FileStore;
 use standout::cli::{Artifact, CommandContext, CommandContextInput, Output};
 use standout_macros::handler;
 use std::cell::RefCell;
+use std::collections::HashSet;
+use std::io::Write;
 use std::rc::Rc;
 
+use super::setup::{AutoTitle, CompileSort, ExportArchive, ListGroupBy, ListSort, PrintPaper};
 use super::views::{
-    CopyView, ListRequest, Listing, Modification, ModificationAction, ModificationRequest,
-    PadContent, PadContentResult, PathView, UuidView,
+    CopyView, FilledPad, ListRequest, Listing, MaintainView, Modification, ModificationAction,
+    ModificationRequest, PadContent, PadContentResult, PadMeta, PathView, PublishedPad,
+    PublishedPads, ReleaseCommit, ReleaseNotes, SignatureStatus, SpokenPads, StatsView, StoreCheck,
+    UuidView, VerifyView,
 };
 use padzapp::commands::doctor::DoctorOutcome;
+use padzapp::commands::encrypt::EncryptOutcome;
+use padzapp::commands::grouping::PadGroup;
 use padzapp::commands::init::InitializationOutcome;
+use padzapp::commands::journal::JournalOutcome;
+use padzapp::commands::pinboard::PinboardOutcome;
 use padzapp::commands::purge::PurgeOutcome;
+use padzapp::commands::query::Query;
+use padzapp::commands::scopes::{PruneOutcome, RemoteAdded};
+use padzapp::commands::snip::SnipOutcome;
 use padzapp::commands::tagging::TaggingResult;
 use padzapp::commands::tags::{TagCatalogOutcome, TagRegistryOutcome};
+use padzapp::commands::timeline::TimelineOutcome;
+use padzapp::commands::tree::TreeOutcome;
+use padzapp::commands::undo::UndoOutcome;
+use padzapp::commands::which::WhichOutcome;
+use padzapp::queue::QueueReport;
+use padzapp::update::UpdateOutcome;
 
 // =============================================================================
 // App State Types (for standout's type-based app_state lookup)
@@ -48,8 +83,9 @@ pub struct ImportExtensions(pub Vec<String>);
 
 /// Shared application state injected via app_state.
 ///
-/// Contains the API instance wrapped in `RefCell` for interior mutability and
-/// the CLI-owned clipboard destination used for best-effort final writes.
+/// Contains the API instance wrapped in `RefCell` for interior mutability, the
+/// CLI-owned clipboard destination used for best-effort final writes, and the
+/// clipboard source read when a command asks for it.
 ///
 /// State is deliberately free of `OutputMode`: handlers return one typed result
 /// regardless of `--output`, and the output mode is resolved at the app-execution
@@ -57,11 +93,57 @@ pub struct ImportExtensions(pub Vec<String>);
 pub struct AppState {
     api: RefCell<PadzApi<FileStore>>,
     clipboard: Rc<dyn ClipboardWriter>,
+    clipboard_reader: Rc<dyn ClipboardReader>,
+    signer: Rc<dyn Signer>,
     pub scope: Scope,
     pub import_extensions: ImportExtensions,
     pub mode: PadzMode,
     /// The local `.padz/` directory (pre-link-resolution), used by link/unlink commands.
     pub local_padz_dir: std::path::PathBuf,
+    /// Whether the user opted into local usage counting (`usage_stats`).
+    pub usage_stats: bool,
+    /// Whether the user opted into the daily release check (`update_check`).
+    pub update_check: bool,
+    /// The directory padz was run from; code anchors the user types are
+    /// relative to it.
+    pub cwd: std::path::PathBuf,
+    /// Linter run on a pad after the editor saves it (`lint_command`).
+    pub lint_command: Option<String>,
+    /// The file new pads start from (`create_template`), resolved against the
+    /// store directory.
+    pub create_template: Option<std::path::PathBuf>,
+    /// What `create` does with a title an active pad already has
+    /// (`on_duplicate_title`).
+    pub on_duplicate_title: DuplicateTitle,
+    /// Where the spell-check dictionaries live (see [`crate::cli::spelling`]).
+    pub config_dir: std::path::PathBuf,
+    /// The dictionary language (`spell_language`).
+    pub spell_language: String,
+    /// The bucket this invocation's global store syncs with, if any; pushed to
+    /// once the command has run (see [`crate::cli::object_store`]).
+    pub global_sync: Option<GlobalStoreSync>,
+    /// Whether there is a terminal to prompt on and open the editor in
+    /// (see [`crate::cli::capabilities`]).
+    pub capabilities: Capabilities,
+    /// How listings are laid out (the `[list]` config table).
+    pub list: ListConfig,
+    /// When listings and `maintain` suggest emptying the trash (the
+    /// `[trash]` config table).
+    pub trash: TrashConfig,
+    /// Where `today` and `yesterday` file daily notes (the `[journal]`
+    /// config table).
+    pub journal: JournalConfig,
+    /// Where `publish` uploads to, and with which tokens (the `[publish]`
+    /// config table).
+    pub publish: PublishConfig,
+    /// Where the changes commands write are told (see [`crate::cli::events`]).
+    pub events: Option<EventSink>,
+    /// The configured listeners, told or not: a dry run tells them nothing,
+    /// but `explain` names them.
+    pub listeners: Option<EventSink>,
+    /// Set by `explain`: the changes and held writes of each API call are
+    /// noted here (see [`crate::cli::explain`]).
+    explanation: Option<Rc<Explanation>>,
 }
 
 impl AppState {
@@ -72,16 +154,157 @@ impl AppState {
         mode: PadzMode,
         local_padz_dir: std::path::PathBuf,
     ) -> Self {
+        let cwd = project_root_of(&local_padz_dir);
+        let config_dir = local_padz_dir.clone();
         Self {
             api: RefCell::new(api),
-            clipboard: Rc::new(SystemClipboardWriter),
+            clipboard: Rc::new(SystemClipboardWriter::default()),
+            clipboard_reader: Rc::new(SystemClipboardReader::default()),
+            signer: Rc::new(GpgSigner::default()),
             scope,
             import_extensions: ImportExtensions(import_extensions),
             mode,
             local_padz_dir,
+            usage_stats: false,
+            update_check: false,
+            cwd,
+            lint_command: None,
+            create_template: None,
+            on_duplicate_title: DuplicateTitle::Ask,
+            config_dir,
+            spell_language: "en".to_string(),
+            global_sync: None,
+            capabilities: Capabilities::default(),
+            list: ListConfig::default(),
+            trash: TrashConfig::default(),
+            journal: JournalConfig::default(),
+            publish: PublishConfig::default(),
+            events: None,
+            listeners: None,
+            explanation: None,
         }
     }
 
+    /// Set the directory padz was run from (defaults to the project root).
+    pub fn with_cwd(mut self, cwd: std::path::PathBuf) -> Self {
+        self.cwd = cwd;
+        self
+    }
+
+    /// The directory code anchors are stored relative to: the one holding the
+    /// local `.padz`.
+    pub fn project_root(&self) -> std::path::PathBuf {
+        project_root_of(&self.local_padz_dir)
+    }
+
+    /// Enable the opt-in local usage counter for this invocation.
+    pub fn with_usage_stats(mut self, enabled: bool) -> Self {
+        self.usage_stats = enabled;
+        self
+    }
+
+    /// Opt into the daily release check (see [`crate::cli::self_update`]).
+    pub fn with_update_check(mut self, enabled: bool) -> Self {
+        self.update_check = enabled;
+        self
+    }
+
+    /// Lint pads with `command` after each interactive edit.
+    pub fn with_lint_command(mut self, command: Option<String>) -> Self {
+        self.lint_command = command;
+        self
+    }
+
+    /// Start new pads from the text of `template`.
+    pub fn with_create_template(mut self, template: Option<std::path::PathBuf>) -> Self {
+        self.create_template = template;
+        self
+    }
+
+    /// Settle creates with a taken title as `policy` says.
+    pub fn with_duplicate_title(mut self, policy: DuplicateTitle) -> Self {
+        self.on_duplicate_title = policy;
+        self
+    }
+
+    /// Look for spell-check dictionaries under `config_dir`, in `language`.
+    pub fn with_spelling(mut self, config_dir: std::path::PathBuf, language: String) -> Self {
+        self.config_dir = config_dir;
+        self.spell_language = language;
+        self
+    }
+
+    /// Lay listings out as the `[list]` config table says.
+    pub fn with_list_config(mut self, list: ListConfig) -> Self {
+        self.list = list;
+        self
+    }
+
+    /// Warn about the trash past the `[trash]` config table's limits.
+    pub fn with_trash_config(mut self, trash: TrashConfig) -> Self {
+        self.trash = trash;
+        self
+    }
+
+    /// File daily notes as the `[journal]` config table says.
+    pub fn with_journal_config(mut self, journal: JournalConfig) -> Self {
+        self.journal = journal;
+        self
+    }
+
+    /// Publish pads as the `[publish]` config table says.
+    pub fn with_publish_config(mut self, publish: PublishConfig) -> Self {
+        self.publish = publish;
+        self
+    }
+
+    /// Tell `events` about every change written from now on. The API must
+    /// already be recording them.
+    pub fn with_events(mut self, events: Option<EventSink>) -> Self {
+        self.events = events;
+        self
+    }
+
+    /// Name the configured listeners, whether or not [`Self::with_events`]
+    /// tells them anything.
+    pub fn with_listeners(mut self, listeners: Option<EventSink>) -> Self {
+        self.listeners = listeners;
+        self
+    }
+
+    /// Note what every API call from now on changes and holds back in
+    /// `explanation`. Starts the API recording changes.
+    pub fn with_explanation(mut self, explanation: Rc<Explanation>) -> Self {
+        self.api.get_mut().record_events();
+        self.explanation = Some(explanation);
+        self
+    }
+
+    /// Sync the global store with a bucket around this invocation.
+    pub fn with_global_sync(mut self, sync: Option<GlobalStoreSync>) -> Self {
+        self.global_sync = sync;
+        self
+    }
+
+    /// The text a new pad starts from: the `create_template` file, with
+    /// `{{today}}` and `{{now}}` filled in. `None` when none is configured.
+    fn template_text(&self) -> Result<Option<String>, anyhow::Error> {
+        let Some(path) = &self.create_template else {
+            return Ok(None);
+        };
+        let text = std::fs::read_to_string(path).map_err(|e| {
+            anyhow::anyhow!("Could not read create_template {}: {}", path.display(), e)
+        })?;
+        let text = crate::cli::directives::expand(text.trim_end(), false, &self.cwd);
+        Ok(Some(text))
+    }
+
+    /// The spell-check dictionary for this invocation's language.
+    fn dictionary(&self) -> Result<Dictionary, anyhow::Error> {
+        crate::cli::spelling::load(&self.config_dir, &self.spell_language)
+            .map_err(anyhow::Error::msg)
+    }
+
     /// Replace the platform clipboard destination.
     ///
     /// Production assembly keeps the default system writer. In-process CLI tests
@@ -92,11 +315,39 @@ impl AppState {
         self
     }
 
+    /// Replace the platform clipboard source, as
+    /// [`Self::with_clipboard_writer`] replaces the destination.
+    pub fn with_clipboard_reader(mut self, clipboard: Rc<dyn ClipboardReader>) -> Self {
+        self.clipboard_reader = clipboard;
+        self
+    }
+
+    /// Replace the signature tool used by `export --sign` and `verify`.
+    ///
+    /// Production assembly keeps gpg. In-process CLI tests supply a fake so
+    /// signing can be exercised without a keyring.
+    pub fn with_signer(mut self, signer: Rc<dyn Signer>) -> Self {
+        self.signer = signer;
+        self
+    }
+
+    /// Set what this invocation can ask of the user (non-interactive by default).
+    pub fn with_capabilities(mut self, capabilities: Capabilities) -> Self {
+        self.capabilities = capabilities;
+        self
+    }
+
     /// Best-effort clipboard write, preserving Padz's established failure semantics.
     fn copy_to_clipboard(&self, text: &str) {
         let _ = self.clipboard.write(text);
     }
 
+    /// The clipboard's text, for the commands told to read it. Unlike a
+    /// write, a failed read is an error: the command has nothing to work on.
+    fn read_clipboard(&self) -> Result<String, anyhow::Error> {
+        self.clipboard_reader.read().map_err(to_anyhow)
+    }
+
     /// Whether todo status icons are part of this invocation's request.
     ///
     /// Todos mode asks for them implicitly; `force` covers commands that change
@@ -106,12 +357,59 @@ impl AppState {
         force || self.mode == PadzMode::Todos
     }
 
-    /// Access the API with mutable borrow
+    /// Whether this invocation is a `--dry-run`: store writes stay in memory.
+    pub fn dry_run(&self) -> bool {
+        self.api.borrow().is_dry_run()
+    }
+
+    /// Refuse to open the editor during a dry run or without a terminal.
+    ///
+    /// The editor works on the pad's real file, which a dry run never writes,
+    /// so there would be nothing to edit and nothing to report.
+    fn ensure_can_open_editor(&self) -> Result<(), anyhow::Error> {
+        if self.dry_run() {
+            anyhow::bail!(
+                "--dry-run cannot open the editor; pass the content as arguments or on stdin"
+            );
+        }
+        self.capabilities.ensure_editor()
+    }
+
+    /// Open `pad_path` in the editor, then lint it when a linter is configured,
+    /// offering the editor again while there are findings.
+    fn edit_pad_file(&self, pad_path: &std::path::Path) -> padzapp::error::Result<()> {
+        let Some(command) = self.lint_command.as_deref() else {
+            return crate::cli::editor::open_in_editor(pad_path);
+        };
+        crate::cli::lint::edit_until_clean(
+            || crate::cli::editor::open_in_editor(pad_path),
+            || crate::cli::lint::run(command, pad_path),
+            |findings| crate::cli::lint::ask_to_reopen(findings, &self.capabilities),
+        )
+    }
+
+    /// Access the API with mutable borrow.
+    ///
+    /// Whatever the store carried on past during `f` (a listing it could not
+    /// sync, say) is reported on stderr, as initialization warnings are, so
+    /// the result on stdout stays exactly the command's. What `f` changed is
+    /// told to the configured event listeners.
     pub fn with_api<F, R>(&self, f: F) -> R
     where
         F: FnOnce(&mut PadzApi<FileStore>) -> R,
     {
-        f(&mut self.api.borrow_mut())
+        let mut api = self.api.borrow_mut();
+        let result = f(&mut api);
+        for warning in api.take_warnings() {
+            eprintln!("Warning: {}", warning);
+        }
+        if let Some(events) = &self.events {
+            events.emit(&api.take_events());
+        }
+        if let Some(explanation) = &self.explanation {
+            explanation.note(api.take_events(), api.dry_run_files().unwrap_or_default());
+        }
+        result
     }
 }
 
@@ -122,6 +420,14 @@ fn get_state(ctx: &CommandContext) -> &AppState {
         .expect("AppState not initialized in app_state")
 }
 
+/// The directory holding `local_padz_dir`, or `.` for a bare relative path.
+fn project_root_of(local_padz_dir: &std::path::Path) -> std::path::PathBuf {
+    local_padz_dir
+        .parent()
+        .map(std::path::Path::to_path_buf)
+        .unwrap_or_else(|| std::path::PathBuf::from("."))
+}
+
 // =============================================================================
 // Scoped API - eliminates handler boilerplate
 // =============================================================================
@@ -224,6 +530,11 @@ impl<'a> ScopedApi<'a> {
         self.modification(ModificationAction::Restore, result, false)
     }
 
+    pub fn undo(&self) -> Result<Output<UndoOutcome>, anyhow::Error> {
+        let outcome = self.call(|api, scope| api.undo(scope))?;
+        Ok(Output::Render(outcome))
+    }
+
     pub fn archive_pads(&self, indexes: &[String]) -> Result<Output<Modification>, anyhow::Error> {
         let result = self.call(|api, scope| api.archive_pads(scope, indexes))?;
         self.modification(ModificationAction::Archive, result, false)
@@ -237,6 +548,56 @@ impl<'a> ScopedApi<'a> {
         self.modification(ModificationAction::Unarchive, result, false)
     }
 
+    pub fn name_pad(
+        &self,
+        index: &str,
+        alias: Option<&str>,
+    ) -> Result<Output<Modification>, anyhow::Error> {
+        let result = self.call(|api, scope| api.name_pad(scope, &[index], alias))?;
+        self.modification(ModificationAction::Name, result, false)
+    }
+
+    pub fn timeline(&self, indexes: &[String]) -> Result<Output<TimelineOutcome>, anyhow::Error> {
+        let outcome = self.call(|api, scope| api.timeline(scope, indexes))?;
+        Ok(Output::Render(outcome))
+    }
+
+    pub fn which(&self, indexes: &[String]) -> Result<Output<WhichOutcome>, anyhow::Error> {
+        let outcome = self.call(|api, scope| api.which(scope, indexes))?;
+        Ok(Output::Render(outcome))
+    }
+
+    /// Record (or, with `remove`, forget) code locations on the selected pads.
+    pub fn link_pads(
+        &self,
+        indexes: &[String],
+        targets: &[String],
+        remove: bool,
+    ) -> Result<Output<Modification>, anyhow::Error> {
+        let root = self.state.project_root();
+        let anchors = targets
+            .iter()
+            .map(|target| {
+                let anchor: CodeAnchor = target.parse().map_err(anyhow::Error::msg)?;
+                crate::cli::anchors::to_stored(anchor, &self.state.cwd, &root, !remove)
+                    .map_err(anyhow::Error::msg)
+            })
+            .collect::<Result<Vec<_>, anyhow::Error>>()?;
+
+        if remove {
+            let result = self.call(|api, scope| api.remove_anchors(scope, indexes, &anchors))?;
+            self.modification(ModificationAction::Unlink, result, false)
+        } else {
+            let result = self.call(|api, scope| api.add_anchors(scope, indexes, &anchors))?;
+            self.modification(ModificationAction::Link, result, false)
+        }
+    }
+
+    pub fn add_refs(&self, indexes: &[String]) -> Result<Output<Modification>, anyhow::Error> {
+        let result = self.call(|api, scope| api.add_refs(scope, indexes))?;
+        self.modification(ModificationAction::Ref, result, false)
+    }
+
     pub fn complete_pads(&self, indexes: &[String]) -> Result<Output<Modification>, anyhow::Error> {
         let result = self.call(|api, scope| api.complete_pads(scope, indexes))?;
         self.modification(ModificationAction::Complete, result, true)
@@ -247,12 +608,17 @@ impl<'a> ScopedApi<'a> {
         self.modification(ModificationAction::Reopen, result, true)
     }
 
+    /// Moves pads under a new parent, to the root, or — given `to` — to
+    /// another store.
     pub fn move_pads(
         &self,
         indexes: &[String],
         to_root: bool,
+        to: Option<&str>,
     ) -> Result<Output<Modification>, anyhow::Error> {
-        let result = if to_root {
+        let result = if let Some(to) = to {
+            self.call(|api, scope| api.relocate_pads(scope, indexes, to))?
+        } else if to_root {
             self.call(|api, scope| api.move_pads(scope, indexes, None))?
         } else {
             if indexes.len() < 2 {
@@ -280,11 +646,90 @@ impl<'a> ScopedApi<'a> {
         Ok(Output::Render(outcome))
     }
 
+    pub fn pinboard(&self) -> Result<Output<PinboardOutcome>, anyhow::Error> {
+        let outcome = self.call(|api, _scope| api.pinboard())?;
+        Ok(Output::Render(outcome))
+    }
+
     pub fn doctor(&self) -> Result<Output<DoctorOutcome>, anyhow::Error> {
         let outcome = self.call(|api, scope| api.doctor(scope))?;
         Ok(Output::Render(outcome))
     }
 
+    pub fn maintain(&self) -> Result<Output<MaintainView>, anyhow::Error> {
+        let outcome = self.call(|api, scope| api.maintain(scope))?;
+        let trash = self.call(|api, scope| api.trash_size(scope))?;
+        Ok(Output::Render(MaintainView {
+            outcome,
+            trash: trash.exceeds(&self.state.trash).then_some(trash),
+        }))
+    }
+
+    pub fn set_encryption(&self, enabled: bool) -> Result<Output<EncryptOutcome>, anyhow::Error> {
+        let outcome = self.call(|api, scope| api.set_encryption(scope, enabled))?;
+        Ok(Output::Render(outcome))
+    }
+
+    pub fn tree(&self) -> Result<Output<TreeOutcome>, anyhow::Error> {
+        let outcome = self.call(|api, _scope| api.tree())?;
+        Ok(Output::Render(outcome))
+    }
+
+    pub fn prune_scopes(&self, yes: bool) -> Result<Output<PruneOutcome>, anyhow::Error> {
+        let outcome = self.call(|api, _scope| api.prune_scopes(yes))?;
+        Ok(Output::Render(outcome))
+    }
+
+    pub fn add_remote_scope(
+        &self,
+        name: &str,
+        url: &str,
+    ) -> Result<Output<RemoteAdded>, anyhow::Error> {
+        let outcome = self.call(|api, _scope| api.add_remote_scope(name, url))?;
+        Ok(Output::Render(outcome))
+    }
+
+    /// Pad counts for the bound scope, plus the usage tally when asked for.
+    ///
+    /// The tally is reported as disabled rather than read when the user has not
+    /// opted in, so `--usage` never surfaces a stale file left from an earlier
+    /// opt-in.
+    pub fn stats(&self, usage: bool) -> Result<Output<StatsView>, anyhow::Error> {
+        let enabled = self.state.usage_stats;
+        let (pads, compression, usage) = self.call(|api, scope| {
+            let pads = api.stats(scope)?;
+            let compression = api.compression_stats(scope)?;
+            let usage = if usage {
+                Some(api.usage_report(enabled)?)
+            } else {
+                None
+            };
+            Ok((pads, compression, usage))
+        })?;
+        Ok(Output::Render(StatsView {
+            pads,
+            compression,
+            usage,
+        }))
+    }
+
+    /// Retries the queued operations; a dry run only lists them.
+    pub fn flush_queue(&self, list: bool) -> Result<Output<QueueReport>, anyhow::Error> {
+        let global_dir = self.state.with_api(|api| api.paths().global.clone());
+        let list = list || self.state.dry_run();
+        let report = crate::cli::queue::flush(&global_dir, list).map_err(to_anyhow)?;
+        Ok(Output::Render(report))
+    }
+
+    /// Installs the latest release over this binary; `--check` and a dry run
+    /// only report whether there is one.
+    pub fn self_update(&self, check: bool) -> Result<Output<UpdateOutcome>, anyhow::Error> {
+        let global_dir = self.state.with_api(|api| api.paths().global.clone());
+        let check = check || self.state.dry_run();
+        let outcome = crate::cli::self_update::run(&global_dir, check).map_err(to_anyhow)?;
+        Ok(Output::Render(outcome))
+    }
+
     pub fn init(&self) -> Result<Output<InitializationOutcome>, anyhow::Error> {
         let outcome = self.call(|api, scope| api.init(scope))?;
         Ok(Output::Render(outcome))
@@ -308,17 +753,129 @@ impl<'a> ScopedApi<'a> {
         &self,
         indexes: &[String],
         single_file: Option<&str>,
-        json: bool,
+        archive: Option<ArchiveFormat>,
         with_metadata: bool,
         nesting: NestingMode,
+        sign: bool,
     ) -> Result<Output<padzapp::commands::export::ExportReport>, anyhow::Error> {
         let result = if let Some(title) = single_file {
             self.call(|api, scope| api.export_pads_single_file(scope, indexes, title, nesting))?
-        } else if json {
-            self.call(|api, scope| api.export_pads_json(scope, indexes, nesting))?
+        } else if let Some(format) = archive {
+            self.call(|api, scope| api.export_pads_archive(scope, indexes, nesting, format))?
         } else {
             self.call(|api, scope| api.export_pads(scope, indexes, nesting, with_metadata))?
         };
+        self.export_output(result, sign)
+    }
+
+    pub fn export_pads_csv(
+        &self,
+        indexes: &[String],
+        nesting: NestingMode,
+        fields: &str,
+        sign: bool,
+    ) -> Result<Output<padzapp::commands::export::ExportReport>, anyhow::Error> {
+        let fields = padzapp::commands::csv::CsvField::parse_list(fields).map_err(to_anyhow)?;
+        let result =
+            self.call(|api, scope| api.export_pads_csv(scope, indexes, nesting, &fields))?;
+        self.export_output(result, sign)
+    }
+
+    pub fn export_pads_into_dir(
+        &self,
+        dir: &str,
+        resume: bool,
+    ) -> Result<Output<padzapp::commands::export::ExportReport>, anyhow::Error> {
+        let dir = std::path::PathBuf::from(dir);
+        let report = self.call(|api, scope| api.export_pads_into_dir(scope, &dir, resume))?;
+        Ok(Output::Render(report))
+    }
+
+    pub fn compile_pads(
+        &self,
+        indexes: &[String],
+        options: &padzapp::commands::compile::CompileOptions,
+    ) -> Result<Output<padzapp::commands::export::ExportReport>, anyhow::Error> {
+        let result = self.call(|api, scope| api.compile_pads(scope, indexes, options))?;
+        self.export_output(result, false)
+    }
+
+    /// Lay out pads for paper and send them to `job`'s printer, or without a
+    /// job place the document like any export.
+    pub fn print_pads(
+        &self,
+        indexes: &[String],
+        options: &padzapp::api::PrintOptions,
+        job: Option<padzapp::commands::export::PrintJob>,
+    ) -> Result<Output<padzapp::commands::export::ExportReport>, anyhow::Error> {
+        // Paper cannot be taken back, so a dry run never reaches the printer.
+        if job.is_some() && self.state.dry_run() {
+            anyhow::bail!("--dry-run does not print; write the document with --to FILE instead");
+        }
+        let result = self.call(|api, scope| api.print_pads(scope, indexes, options))?;
+        match (result, job) {
+            (padzapp::commands::export::ExportOutcome::Artifact(artifact), Some(job)) => {
+                super::printer::send(&artifact.bytes, job.printer.as_deref()).map_err(to_anyhow)?;
+                let mut report = artifact.report;
+                report.printed = Some(job);
+                Ok(Output::Render(report))
+            }
+            (result, _) => self.export_output(result, false),
+        }
+    }
+
+    /// Upload pads as new gists or snippets on `service`, or with `update`
+    /// send the edits made since to where they were published.
+    pub fn publish_pads(
+        &self,
+        indexes: &[String],
+        service: Option<padzapp::model::PublishService>,
+        update: bool,
+        public: bool,
+    ) -> Result<Output<PublishedPads>, anyhow::Error> {
+        // An upload cannot be held back like a store write.
+        if self.state.dry_run() {
+            anyhow::bail!("--dry-run does not publish; nothing was uploaded");
+        }
+        let config = &self.state.publish;
+        let uploads = match (update, service) {
+            (false, Some(service)) => self
+                .call(|api, scope| api.publish_uploads(scope, indexes, service))?
+                .into_iter()
+                .map(|upload| (service, upload))
+                .collect(),
+            (false, None) => anyhow::bail!("Publish to --gist or --gitlab-snippet"),
+            (true, service) => {
+                self.call(|api, scope| api.stale_publications(scope, indexes, service))?
+            }
+        };
+
+        let mut pads = Vec::with_capacity(uploads.len());
+        for (service, upload) in uploads {
+            let publication = if update {
+                crate::cli::publish::update(config, service, &upload)
+            } else {
+                crate::cli::publish::create(config, service, &upload, public)
+            }
+            .map_err(to_anyhow)?;
+            pads.push(PublishedPad {
+                title: upload.title.clone(),
+                service,
+                url: publication.url.clone(),
+            });
+            // Recorded one by one, so a failure further on leaves every
+            // upload already made on its pad.
+            self.call(|api, scope| api.record_publication(scope, upload.pad, publication))?;
+        }
+        Ok(Output::Render(PublishedPads { pads, update }))
+    }
+
+    /// Map an export-shaped outcome onto the output Standout places.
+    fn export_output(
+        &self,
+        result: padzapp::commands::export::ExportOutcome,
+        sign: bool,
+    ) -> Result<Output<padzapp::commands::export::ExportReport>, anyhow::Error> {
         Ok(match result {
             // An empty selection never enters the artifact path: Standout only
             // merges its receipt after a write, so `export.jinja` keys the empty
@@ -328,8 +885,14 @@ impl<'a> ScopedApi<'a> {
                     format,
                     exported: 0,
                     warnings: Vec::new(),
+                    signed: None,
+                    streamed: None,
+                    printed: None,
                 })
             }
+            padzapp::commands::export::ExportOutcome::Artifact(artifact) if sign => {
+                Output::Render(self.write_signed(artifact)?)
+            }
             // The core report rides along as the artifact's semantic report;
             // Standout owns the write and merges its own receipt afterwards.
             padzapp::commands::export::ExportOutcome::Artifact(artifact) => Output::Artifact(
@@ -340,13 +903,105 @@ impl<'a> ScopedApi<'a> {
         })
     }
 
+    /// Sign an export and write it, with its `.asc` signature, to the suggested
+    /// file name in the current directory.
+    ///
+    /// The signature has to land next to the archive, and only the handler knows
+    /// both paths when it does the write itself — so signed exports bypass the
+    /// framework's artifact placement. The signature is made before anything is
+    /// written, so a refused signing leaves no unsigned archive behind.
+    fn write_signed(
+        &self,
+        artifact: padzapp::commands::export::ExportArtifact,
+    ) -> Result<padzapp::commands::export::ExportReport, anyhow::Error> {
+        // Both files are written here, not held back like a store write.
+        if self.state.dry_run() {
+            anyhow::bail!("--dry-run does not sign; nothing was written");
+        }
+        let signature = self
+            .state
+            .signer
+            .detach_sign(&artifact.bytes)
+            .map_err(to_anyhow)?;
+        let destination = std::path::PathBuf::from(&artifact.suggested_filename);
+        let signature_file = signature_path(&destination);
+        std::fs::write(&destination, &artifact.bytes)?;
+        std::fs::write(&signature_file, signature)?;
+
+        let mut report = artifact.report;
+        report.signed = Some(padzapp::commands::export::SignedExport {
+            destination,
+            signature: signature_file,
+        });
+        Ok(report)
+    }
+
+    /// Check a file against its detached signature. Anything short of a good
+    /// signature is an error, so `padz verify f && restore f` is safe to script.
+    pub fn verify_signature(
+        &self,
+        file: &str,
+        signature: Option<&str>,
+    ) -> Result<Output<VerifyView>, anyhow::Error> {
+        let file = std::path::PathBuf::from(file);
+        let signature = signature
+            .map(std::path::PathBuf::from)
+            .unwrap_or_else(|| signature_path(&file));
+        if !signature.is_file() {
+            anyhow::bail!("No signature found at {}", signature.display());
+        }
+        let check = self
+            .state
+            .signer
+            .verify(&file, &signature)
+            .map_err(to_anyhow)?;
+        match check.status {
+            SignatureStatus::Good => Ok(Output::Render(VerifyView::Signature(check))),
+            SignatureStatus::Bad => anyhow::bail!(
+                "BAD signature: {} does not match {}",
+                check.file.display(),
+                check.signature.display()
+            ),
+            SignatureStatus::UnknownKey => anyhow::bail!(
+                "Cannot verify {}: the signing key is not in your keyring",
+                check.file.display()
+            ),
+            SignatureStatus::Invalid => {
+                anyhow::bail!("{} is not a usable signature", check.signature.display())
+            }
+        }
+    }
+
+    /// Check every bucket of the bound scope against its recorded checksums.
+    ///
+    /// Problems are rendered, not raised: the report is the useful output, and
+    /// each problem carries its own repair suggestion. With `accept`, the current
+    /// contents are recorded as correct before the check runs.
+    pub fn verify_store(&self, accept: bool) -> Result<Output<VerifyView>, anyhow::Error> {
+        let (accepted, report) = self.call(|api, scope| {
+            let accepted = if accept {
+                Some(api.accept_checksums(scope)?)
+            } else {
+                None
+            };
+            Ok((accepted, api.verify_store(scope)?))
+        })?;
+        Ok(Output::Render(VerifyView::Store(StoreCheck {
+            accepted,
+            report,
+        })))
+    }
+
     /// Return the core semantic import report for the CLI to render directly.
     pub fn import_pads(
         &self,
         paths: Vec<std::path::PathBuf>,
+        options: padzapp::commands::import::ImportOptions,
     ) -> Result<Output<padzapp::commands::import::ImportReport>, anyhow::Error> {
         let extensions = &self.state.import_extensions.0;
-        let report = self.call(move |api, scope| api.import_pads(scope, paths, extensions))?;
+        let report = self.call(move |api, scope| {
+            api.import_pads_with_options(scope, paths, extensions, options)
+        })?;
         Ok(Output::Render(report))
     }
 
@@ -414,8 +1069,18 @@ impl<'a> ScopedApi<'a> {
             || filter.tags.is_some()
             || !ids.is_empty();
         let result = self.call(|api, scope| api.get_pads(scope, filter, ids))?;
+        let mut pads = result.listed_pads;
+        if !self.state.list.repeat_pinned {
+            drop_repeated_pinned(&mut pads);
+        }
         Ok(Output::Render(Listing {
-            pads: result.listed_pads,
+            pads,
+            groups: Vec::new(),
+            unsearched: Vec::new(),
+            count: None,
+            trash: result
+                .trash
+                .filter(|trash| trash.exceeds(&self.state.trash)),
             request: ListRequest {
                 peek,
                 uuid: show_uuid,
@@ -423,19 +1088,168 @@ impl<'a> ScopedApi<'a> {
                 filtered,
                 deleted_help: show_deleted_help,
                 sections: show_all_sections,
+                max_title: self.state.list.max_title,
+                density: self.state.list.density,
             },
         }))
     }
 
+    /// `search --all-scopes`: the pads `filter` matches in every store, a
+    /// group per store with matches, and the stores left unsearched.
+    pub fn search_all_scopes(
+        &self,
+        filter: PadFilter,
+        show_uuid: bool,
+    ) -> Result<Output<Listing>, anyhow::Error> {
+        let outcome = self.call(|api, _scope| api.search_all_scopes(filter))?;
+        let groups = outcome
+            .searched
+            .into_iter()
+            .filter(|scope| !scope.pads.is_empty())
+            .map(|scope| {
+                let mut pads = scope.pads;
+                if !self.state.list.repeat_pinned {
+                    drop_repeated_pinned(&mut pads);
+                }
+                PadGroup {
+                    key: Some(match scope.project {
+                        Some(project) => project.display().to_string(),
+                        None => "global".to_string(),
+                    }),
+                    count: distinct_pads(&pads),
+                    pads,
+                }
+            })
+            .collect();
+        Ok(Output::Render(Listing {
+            pads: Vec::new(),
+            groups,
+            unsearched: outcome.unsearched,
+            count: None,
+            trash: None,
+            request: ListRequest {
+                uuid: show_uuid,
+                status: self.state.wants_status(false),
+                filtered: true,
+                max_title: self.state.list.max_title,
+                density: self.state.list.density,
+                ..Default::default()
+            },
+        }))
+    }
+
+    /// Moves a listing's pads into the groups `group_by` asks for.
+    pub fn group_listing(
+        &self,
+        listing: &mut Listing,
+        group_by: ListGroupBy,
+    ) -> Result<(), anyhow::Error> {
+        let pads = std::mem::take(&mut listing.pads);
+        listing.groups = self.call(|api, scope| api.group_pads(scope, pads, group_by.into()))?;
+        Ok(())
+    }
+
+    /// Writes a listing to `out` as JSON Lines, flushing each pad as it is
+    /// read (`list --jsonl`). A reader that stops reading early, as `head`
+    /// does, only ends the stream.
+    pub fn stream_listing(
+        &self,
+        filter: PadFilter,
+        ids: &[String],
+        out: &mut impl Write,
+    ) -> Result<Output<Listing>, anyhow::Error> {
+        let streamed = self.state.with_api(|api| {
+            api.stream_pads(self.state.scope, filter, ids, |pad| {
+                let mut line = serde_json::to_vec(pad)?;
+                line.push(b'\n');
+                out.write_all(&line)?;
+                out.flush()?;
+                Ok(())
+            })
+        });
+        match streamed {
+            Err(PadzError::Io(e)) if e.kind() == std::io::ErrorKind::BrokenPipe => {
+                Ok(Output::Silent)
+            }
+            other => other.map(|_| Output::Silent).map_err(to_anyhow),
+        }
+    }
+
+    /// Keeps the pads of a listing that `query` matches.
+    pub fn filter_listing(
+        &self,
+        listing: &mut Listing,
+        query: &Query,
+    ) -> Result<(), anyhow::Error> {
+        let pads = std::mem::take(&mut listing.pads);
+        listing.pads = self.call(|api, scope| api.filter_pads(scope, pads, query))?;
+        listing.request.filtered = true;
+        Ok(())
+    }
+
+    /// The pads a `--where` expression selects, as selectors for a bulk
+    /// command. Matching nothing is an error, as naming a missing pad is.
+    pub fn select_where(&self, expression: &str) -> Result<Vec<String>, anyhow::Error> {
+        let query = Query::parse(expression).map_err(to_anyhow)?;
+        let ids = self.call(|api, scope| api.select_pads(scope, &query))?;
+        if ids.is_empty() {
+            anyhow::bail!("No pad matches '{}'", expression);
+        }
+        Ok(ids)
+    }
+
+    pub fn recent_pads(
+        &self,
+        limit: usize,
+        show_uuid: bool,
+    ) -> Result<Output<Listing>, anyhow::Error> {
+        let result = self.call(|api, scope| api.recent_pads(scope, limit))?;
+        Ok(Output::Render(Listing {
+            pads: result.listed_pads,
+            groups: Vec::new(),
+            unsearched: Vec::new(),
+            count: None,
+            trash: None,
+            request: ListRequest {
+                uuid: show_uuid,
+                status: self.state.wants_status(false),
+                filtered: true,
+                max_title: self.state.list.max_title,
+                density: self.state.list.density,
+                ..Default::default()
+            },
+        }))
+    }
+
+    /// Best-effort access stamp for pads the user just read. A failure here
+    /// must never fail the read itself.
+    fn record_access<'p>(&self, pads: impl IntoIterator<Item = &'p padzapp::index::DisplayPad>) {
+        let ids: Vec<_> = pads.into_iter().map(|dp| dp.pad.metadata.id).collect();
+        let _ = self.call(|api, scope| api.record_access(scope, &ids));
+    }
+
     // --- View operations ---
 
+    /// View pads. With `reveal`, sealed `secret` fences are decrypted in the
+    /// returned content (and so in the clipboard copy); without it they stay
+    /// sealed, exactly as stored. With `spell`, each body is checked against
+    /// the configured dictionary. Directives in the body are filled in, running
+    /// `{{shell}}` ones only with `exec` (see [`crate::cli::directives`]).
+    #[allow(clippy::too_many_arguments)]
     pub fn view_pads(
         &self,
         indexes: &[String],
         show_uuid: bool,
         nesting: NestingMode,
+        reveal: bool,
+        open_links: bool,
+        show_meta: bool,
+        spell: bool,
+        exec: bool,
     ) -> Result<Output<PadContentResult>, anyhow::Error> {
         let result = self.call(|api, scope| api.view_pads(scope, indexes, nesting))?;
+        let dictionary = spell.then(|| self.state.dictionary()).transpose()?;
+        let root = self.state.project_root();
 
         let pads: Vec<PadContent> = result
             .listed_pads
@@ -443,21 +1257,68 @@ impl<'a> ScopedApi<'a> {
             .enumerate()
             .map(|(i, dp)| {
                 let depth = result.listed_depths.get(i).copied().unwrap_or(0);
+                let content = if reveal {
+                    self.call(|api, _scope| api.reveal_secrets(&dp.pad.content))?
+                } else {
+                    dp.pad.content.clone()
+                };
                 // Extract body (content minus title) to avoid double-title in output
-                let body = extract_title_and_body(&dp.pad.content)
-                    .map(|(_, b)| b)
+                let body = extract_title_and_body(&content)
+                    .map(|(_, b)| crate::cli::directives::expand(&b, exec, &root))
                     .unwrap_or_default();
 
-                PadContent {
+                Ok(PadContent {
                     title: dp.pad.metadata.title.clone(),
-                    content: body,
                     depth,
                     uuid: show_uuid.then(|| dp.pad.metadata.id.to_string()),
-                }
+                    anchors: dp
+                        .pad
+                        .metadata
+                        .anchors
+                        .iter()
+                        .map(ToString::to_string)
+                        .collect(),
+                    meta: show_meta.then(|| PadMeta {
+                        created_at: dp.pad.metadata.created_at,
+                        updated_at: dp.pad.metadata.updated_at,
+                        context: dp.pad.metadata.context.clone(),
+                        owner: dp.pad.metadata.owner.clone(),
+                    }),
+                    spelling: dictionary.as_ref().map(|d| spell::check(&body, d)),
+                    content: body,
+                })
             })
-            .collect();
+            .collect::<Result<_, anyhow::Error>>()?;
         let view = PadContentResult { pads, nesting };
 
+        // Jump to each linked location of the selected pads in turn; children
+        // shown by the tree view are not what the user asked to open.
+        if open_links {
+            self.state.capabilities.ensure_editor()?;
+            let roots = result
+                .listed_pads
+                .iter()
+                .enumerate()
+                .filter(|(i, _)| result.listed_depths.get(*i).copied().unwrap_or(0) == 0);
+            for (_, dp) in roots {
+                for anchor in &dp.pad.metadata.anchors {
+                    crate::cli::editor::open_in_editor_at(
+                        crate::cli::anchors::to_file(anchor, &root),
+                        anchor.line,
+                    )?;
+                }
+            }
+        }
+
+        self.record_access(
+            result
+                .listed_pads
+                .iter()
+                .enumerate()
+                .filter(|(i, _)| result.listed_depths.get(*i).copied().unwrap_or(0) == 0)
+                .map(|(_, dp)| dp),
+        );
+
         // `view` copies only the selected roots, in display order. Build one
         // payload and perform one CLI-owned write so multiple selectors do not
         // overwrite one another and rendered/structured output never becomes the
@@ -470,12 +1331,88 @@ impl<'a> ScopedApi<'a> {
         Ok(Output::Render(view))
     }
 
+    /// Fill in a pad's `{{placeholder:name}}` fill-ins from `assignments`
+    /// (`name=value`), prompting for the rest; print and copy the result
+    /// without changing the pad (see [`crate::cli::fill`]).
+    pub fn fill_pad(
+        &self,
+        index: &str,
+        assignments: &[String],
+    ) -> Result<Output<FilledPad>, anyhow::Error> {
+        let given = crate::cli::fill::parse_assignments(assignments).map_err(anyhow::Error::msg)?;
+        let result = self.call(|api, scope| api.view_pads(scope, &[index], NestingMode::Flat))?;
+        let dp = result
+            .listed_pads
+            .first()
+            .ok_or_else(|| anyhow::anyhow!("No pad found for '{}'", index))?;
+
+        let body = extract_title_and_body(&dp.pad.content)
+            .map(|(_, b)| b)
+            .unwrap_or_default();
+        let placeholders = directives::placeholders(&body);
+        let capabilities = self.state.capabilities;
+        let values = crate::cli::fill::complete(&placeholders, given, |name| {
+            crate::cli::fill::prompt(name, &capabilities)
+        })
+        .map_err(anyhow::Error::msg)?;
+        let root = self.state.project_root();
+        let content = directives::expand(&body, |directive| match directive {
+            Directive::Placeholder(name) => values.get(name).cloned(),
+            other => crate::cli::directives::resolve(other, false, &root),
+        });
+
+        self.record_access(std::iter::once(dp));
+        self.state.copy_to_clipboard(&content);
+        Ok(Output::Render(FilledPad {
+            title: dp.pad.metadata.title.clone(),
+            placeholders,
+            content,
+        }))
+    }
+
+    /// Read pads aloud, or record them to the audio file `to`.
+    pub fn speak_pads(
+        &self,
+        indexes: &[String],
+        voice: &crate::cli::speak::Voice,
+        to: Option<String>,
+    ) -> Result<Output<SpokenPads>, anyhow::Error> {
+        let result = self.call(|api, scope| api.view_pads(scope, indexes, NestingMode::Flat))?;
+        let mut chunks = Vec::new();
+        let mut titles = Vec::new();
+        for dp in &result.listed_pads {
+            let (title, body) = extract_title_and_body(&dp.pad.content).unwrap_or_default();
+            chunks.extend(crate::cli::speak::script(&title, &body));
+            titles.push(dp.pad.metadata.title.clone());
+        }
+        match &to {
+            Some(file) => crate::cli::speak::record(&chunks, voice, std::path::Path::new(file)),
+            None => crate::cli::speak::speak(&chunks, voice),
+        }
+        .map_err(to_anyhow)?;
+
+        self.record_access(&result.listed_pads);
+        Ok(Output::Render(SpokenPads { titles, file: to }))
+    }
+
+    /// Copy the body of the best-matching snippet (see
+    /// [`padzapp::commands::snip`]) to the clipboard.
+    pub fn snip(&self, query: &str) -> Result<Output<SnipOutcome>, anyhow::Error> {
+        let outcome = self.call(|api, scope| api.snip(scope, query))?;
+        let body = extract_title_and_body(&outcome.pad.content)
+            .map(|(_, b)| b)
+            .unwrap_or_default();
+        self.state.copy_to_clipboard(&body);
+        Ok(Output::Render(outcome))
+    }
+
     // --- Copy operations ---
 
     pub fn copy_pads(
         &self,
         indexes: &[String],
         nesting: NestingMode,
+        append: bool,
     ) -> Result<Output<CopyView>, anyhow::Error> {
         let result = self.call(|api, scope| api.view_pads(scope, indexes, nesting))?;
 
@@ -510,6 +1447,14 @@ impl<'a> ScopedApi<'a> {
             }
         }
 
+        // `--append` keeps what the clipboard holds, ahead of the pads and
+        // separated from them as root pads are from each other.
+        if append {
+            let held = self.state.read_clipboard()?;
+            if !held.trim().is_empty() {
+                clipboard_text = format!("{}\n---\n\n{}", held.trim_end(), clipboard_text);
+            }
+        }
         self.state.copy_to_clipboard(&clipboard_text);
 
         // Report using only the root-level (depth 0) pad titles. These are the
@@ -526,6 +1471,7 @@ impl<'a> ScopedApi<'a> {
         Ok(Output::Render(CopyView {
             root_pad_count: titles.len(),
             titles,
+            appended: append,
         }))
     }
 }
@@ -544,6 +1490,20 @@ fn view_clipboard_text(view: &PadContentResult) -> String {
         .join("\n---\n\n")
 }
 
+/// Drops the regular-list copy of each pinned root whose pinned copy is also
+/// listed (`list.repeat_pinned = false`). A pinned pad selected only by its
+/// regular index keeps its one line.
+fn drop_repeated_pinned(pads: &mut Vec<DisplayPad>) {
+    let pinned: std::collections::HashSet<_> = pads
+        .iter()
+        .filter(|dp| matches!(dp.index, DisplayIndex::Pinned(_)))
+        .map(|dp| dp.pad.metadata.id)
+        .collect();
+    pads.retain(|dp| {
+        !(matches!(dp.index, DisplayIndex::Regular(_)) && pinned.contains(&dp.pad.metadata.id))
+    });
+}
+
 /// Get a scoped API accessor from the command context
 fn api(ctx: &CommandContext) -> ScopedApi<'_> {
     ScopedApi {
@@ -626,10 +1586,10 @@ pub(crate) fn split_indexes_and_content(args: &[String]) -> (Vec<String>, Vec<St
 
 /// Create a pad.
 ///
-/// Where the text comes from — args, piped stdin, or the editor — is *not*
-/// decided here: `cli::input`'s chain resolves it before dispatch and this
-/// handler matches on the resulting [`RequestContent`]. The `editor` /
-/// `no_editor` flags are part of that chain's availability rules, so they are
+/// Where the text comes from — a `--from` command, args, piped stdin, or the
+/// editor — is *not* decided here: `cli::input`'s chain resolves it before
+/// dispatch and this handler matches on the resulting [`RequestContent`]. The
+/// `from` / `editor` / `no_editor` flags are part of that chain's availability rules, so they are
 /// not read here either. Both paths return the same core [`Modification`] the rest
 /// of the family does (rendered by `modification_result.jinja` with `action =
 /// "create"`): a success carries the created pad, and empty piped/editor content
@@ -640,13 +1600,34 @@ pub fn create(
     #[arg] inside: Option<String>,
     #[arg] format: Option<String>,
     #[arg] title: Vec<String>,
+    #[flag] blank: bool,
+    #[arg(name = "auto_title")] auto_title: Option<AutoTitle>,
 ) -> Result<Output<Modification>, anyhow::Error> {
     let state = get_state(ctx);
     let content = ctx.input::<RequestContent>(CREATE_CONTENT)?;
-    let title_arg = if title.is_empty() {
-        None
-    } else {
-        Some(title.join(" "))
+    // The clipboard's text is taken as piped text is, an empty one as an
+    // empty pipe.
+    let from_clipboard;
+    let content = match content {
+        RequestContent::Clipboard => {
+            let text = state.read_clipboard()?;
+            from_clipboard = match text.trim() {
+                "" => RequestContent::PipedEmpty,
+                trimmed => RequestContent::Piped(trimmed.to_string()),
+            };
+            &from_clipboard
+        }
+        other => other,
+    };
+    // The configured template fills a pad that would otherwise start empty.
+    let template = if blank { None } else { state.template_text()? };
+    // Typed words always win; an auto title stands in for them.
+    let title_arg = match auto_title {
+        _ if !title.is_empty() => Some(title.join(" ")),
+        Some(AutoTitle::Git) => Some(super::git_context::title(&state.cwd).ok_or_else(|| {
+            anyhow::anyhow!("--auto-title git needs a git repository with at least one commit")
+        })?),
+        None => None,
     };
     let inside = inside.as_deref();
     let format_ref = format.as_deref();
@@ -670,12 +1651,66 @@ pub fn create(
         })
     }
 
+    // Creates the pad, then opens the editor on its real file; `None` when the
+    // user saved it empty. A failed launch deletes the pad again.
+    fn edit_new_pad(
+        state: &AppState,
+        title: String,
+        body: String,
+        inside: Option<&str>,
+        format: Option<&str>,
+    ) -> std::result::Result<Option<padzapp::commands::CmdResult>, anyhow::Error> {
+        state.ensure_can_open_editor()?;
+        let create_result = do_create(state, title, body, inside, format)?;
+        let pad_id = create_result.affected_pads[0].pad.metadata.id;
+        let pad_path =
+            state.with_api(|api| api.editor_file(state.scope, pad_id).map_err(to_anyhow))?;
+
+        // Open editor on the real pad file in .padz/, or on an encrypted
+        // pad's working copy, which goes back into the pad either way.
+        let edited = state.edit_pad_file(&pad_path);
+        state.with_api(|api| {
+            api.finish_editor_file(state.scope, pad_id, &pad_path)
+                .map_err(to_anyhow)
+        })?;
+        if let Err(e) = edited {
+            // Editor failed - clean up the pad
+            let _ = state.with_api(|api| api.remove_pad(state.scope, pad_id));
+            return Err(to_anyhow(e));
+        }
+
+        // Pick the pad up from disk (title, status propagation, index).
+        let result = state.with_api(|api| {
+            api.finish_editor_create(state.scope, pad_id)
+                .map_err(to_anyhow)
+        })?;
+        match result.affected_pads.first() {
+            // Empty file - user aborted
+            None => return Ok(None),
+            Some(created) => copy_content_to_clipboard(state, &created.pad.content),
+        }
+        Ok(Some(result))
+    }
+
     let result = match content {
         // Quick-create: args used directly, no editor. The chain already joined
         // the args and expanded literal `\n`.
         RequestContent::Direct(expanded) => {
             let (title, body) =
                 extract_title_and_body(expanded).unwrap_or_else(|| (String::new(), String::new()));
+            // With no words typed, only an auto title is left to use.
+            let title = match &title_arg {
+                Some(auto) if title.is_empty() => auto.clone(),
+                _ => title,
+            };
+            // A taken title is settled with the text typed, not the template.
+            if let Some(settled) = settle_duplicate(ctx, &title, &body)? {
+                return Ok(settled);
+            }
+            let body = match template {
+                Some(template) if body.is_empty() => template,
+                _ => body,
+            };
             let result = do_create(state, title.clone(), body.clone(), inside, format_ref)?;
             // Propagate status now — content is non-empty, safe from reconciliation
             let parent_id = result.affected_pads[0].pad.metadata.parent_id;
@@ -686,7 +1721,7 @@ pub fn create(
             result
         }
 
-        // Piped content from stdin.
+        // Piped content from stdin, or the clipboard's.
         RequestContent::Piped(raw) => {
             let parsed = padzapp::editor::EditorContent::from_buffer(raw);
             // Determine title: title_arg override > parsed title > empty
@@ -695,6 +1730,9 @@ pub fn create(
                 (_, false) => parsed.title, // parsed has title, no CLI override
                 (None, true) => String::new(),
             };
+            if let Some(settled) = settle_duplicate(ctx, &final_title, &parsed.content)? {
+                return Ok(settled);
+            }
             let result = do_create(state, final_title, parsed.content, inside, format_ref)?;
             // Propagate status now — content is non-empty, safe from reconciliation
             let parent_id = result.affected_pads[0].pad.metadata.parent_id;
@@ -715,47 +1753,42 @@ pub fn create(
         // that was created to hold it.
         RequestContent::Editor => {
             let initial_title = title_arg.clone().unwrap_or_default();
-            let create_result = do_create(state, initial_title, String::new(), inside, format_ref)?;
-            let pad_path = create_result.pad_paths[0].clone();
-            let pad_id = create_result.affected_pads[0].pad.metadata.id;
-
-            // Open editor on the real pad file in .padz/
-            if let Err(e) = crate::cli::editor::open_in_editor(&pad_path) {
-                // Editor failed - clean up the pad
-                let _ = state.with_api(|api| api.remove_pad(state.scope, pad_id));
-                return Err(to_anyhow(e));
+            if let Some(settled) = settle_duplicate(ctx, &initial_title, "")? {
+                return Ok(settled);
+            }
+            let initial_body = template.unwrap_or_default();
+            match edit_new_pad(state, initial_title, initial_body, inside, format_ref)? {
+                Some(result) => result,
+                None => return Ok(aborted_create(ctx)),
             }
+        }
 
-            // Refresh pad from disk (re-reads content, updates title)
-            match state.with_api(|api| api.refresh_pad(state.scope, &pad_id).map_err(to_anyhow))? {
-                Some(pad) => {
-                    // Propagate status now — pad has real content after editor,
-                    // safe from reconciliation deleting the empty file.
-                    let parent_id = pad.metadata.parent_id;
-                    state.with_api(|api| {
-                        api.propagate_status(state.scope, parent_id)
-                            .map_err(to_anyhow)
-                    })?;
-                    // Copy to clipboard
-                    copy_content_to_clipboard(state, &pad.content);
-                    // Build result
-                    let display_pad = padzapp::index::DisplayPad {
-                        pad,
-                        index: padzapp::index::DisplayIndex::Regular(1),
-                        matches: None,
-                        children: Vec::new(),
-                    };
-                    CmdResult {
-                        affected_pads: vec![display_pad],
-                        ..Default::default()
-                    }
-                }
-                None => {
-                    // Empty file - user aborted
-                    return Ok(aborted_create(ctx));
+        // `--from`: the command's output, titled by the command unless a
+        // title was given, and curated in the editor unless `--no-editor`.
+        RequestContent::Command { command, edit } => {
+            let output = super::capture::run(command).map_err(to_anyhow)?;
+            if output.is_empty() {
+                return Ok(aborted_create(ctx));
+            }
+            let title = title_arg.clone().unwrap_or_else(|| command.clone());
+            if let Some(settled) = settle_duplicate(ctx, &title, &output)? {
+                return Ok(settled);
+            }
+            if *edit {
+                match edit_new_pad(state, title, output, inside, format_ref)? {
+                    Some(result) => result,
+                    None => return Ok(aborted_create(ctx)),
                 }
+            } else {
+                let result = do_create(state, title.clone(), output.clone(), inside, format_ref)?;
+                let parent_id = result.affected_pads[0].pad.metadata.parent_id;
+                state.with_api(|api| api.propagate_status(state.scope, parent_id))?;
+                state.copy_to_clipboard(&format_for_clipboard(&title, &output));
+                result
             }
         }
+
+        RequestContent::Clipboard => unreachable!("read above"),
     };
 
     Ok(Output::Render(api(ctx).modification_result(
@@ -765,6 +1798,50 @@ pub fn create(
     )))
 }
 
+/// Settles a `create` whose `title` an active pad already has, as
+/// `on_duplicate_title` says (see [`crate::cli::duplicate`]). `None` goes on
+/// to create the pad; otherwise the existing pad was opened or had `text`
+/// appended, and this reports that edit.
+fn settle_duplicate(
+    ctx: &CommandContext,
+    title: &str,
+    text: &str,
+) -> Result<Option<Output<Modification>>, anyhow::Error> {
+    let state = get_state(ctx);
+    if title.is_empty() {
+        return Ok(None);
+    }
+    let Some(existing) =
+        state.with_api(|api| api.pad_titled(state.scope, title).map_err(to_anyhow))?
+    else {
+        return Ok(None);
+    };
+    let action = duplicate::action(
+        state.on_duplicate_title,
+        !text.trim().is_empty(),
+        |has_text| duplicate::ask(title, has_text, &state.capabilities),
+    );
+    let target = [existing.to_string()];
+    match action {
+        Action::Create => Ok(None),
+        Action::Open => {
+            state.ensure_can_open_editor()?;
+            edit_in_editor(ctx, &target, false).map(Some)
+        }
+        Action::Append => {
+            let result = state.with_api(|api| {
+                api.amend_pads(state.scope, &target, text, Amend::Append)
+                    .map_err(to_anyhow)
+            })?;
+            Ok(Some(Output::Render(api(ctx).modification_result(
+                ModificationAction::Update,
+                result,
+                false,
+            ))))
+        }
+    }
+}
+
 /// The result of a `create` the user abandoned by supplying no content.
 ///
 /// No pad was created, so the outcome is a `create` [`Modification`] with no
@@ -798,7 +1875,28 @@ pub fn list(
     #[arg] tags: Vec<String>,
     #[flag] uuid: bool,
     #[flag(name = "show_status")] show_status: bool,
+    #[arg] sort: Option<ListSort>,
+    #[arg(name = "group_by")] group_by: Option<ListGroupBy>,
+    #[flag] count: bool,
+    #[arg] query: Option<String>,
+    #[flag] jsonl: bool,
+    #[flag] branch: bool,
 ) -> Result<Output<Listing>, anyhow::Error> {
+    let mut query = query
+        .as_deref()
+        .map(Query::parse)
+        .transpose()
+        .map_err(to_anyhow)?;
+    if branch {
+        let cwd = &get_state(ctx).cwd;
+        let name = super::git_context::branch(cwd)
+            .ok_or_else(|| anyhow::anyhow!("--branch needs a git branch checked out"))?;
+        let on_branch = Query::branch(&name);
+        query = Some(match query {
+            Some(query) => query.and(on_branch),
+            None => on_branch,
+        });
+    }
     let todo_status = if planned {
         Some(TodoStatus::Planned)
     } else if completed {
@@ -823,8 +1921,11 @@ pub fn list(
         todo_status,
         tags: if tags.is_empty() { None } else { Some(tags) },
     };
+    if jsonl {
+        return api(ctx).stream_listing(filter, &ids, &mut std::io::stdout().lock());
+    }
 
-    api(ctx).list_pads(
+    let mut output = api(ctx).list_pads(
         filter,
         peek,
         deleted || archived,
@@ -832,7 +1933,20 @@ pub fn list(
         &ids,
         uuid,
         show_status,
-    )
+    )?;
+    if let (Some(query), Output::Render(listing)) = (&query, &mut output) {
+        api(ctx).filter_listing(listing, query)?;
+    }
+    if let (Some(ListSort::Accessed), Output::Render(listing)) = (sort, &mut output) {
+        padzapp::commands::recent::sort_by_access(&mut listing.pads);
+    }
+    if let (Some(group_by), Output::Render(listing)) = (group_by, &mut output) {
+        api(ctx).group_listing(listing, group_by)?;
+    }
+    if let (true, Output::Render(listing)) = (count, &mut output) {
+        keep_count_only(listing);
+    }
+    Ok(output)
 }
 
 #[handler]
@@ -848,7 +1962,21 @@ pub fn peek(
         todo_status: None,
         tags: if tags.is_empty() { None } else { Some(tags) },
     };
-    api(ctx).list_pads(filter, true, false, false, &ids, uuid, false)
+    let output = api(ctx).list_pads(filter, true, false, false, &ids, uuid, false)?;
+    if let Output::Render(listing) = &output {
+        api(ctx).record_access(&listing.pads);
+    }
+    Ok(output)
+}
+
+/// The most recently read pads, newest first.
+#[handler]
+pub fn recent(
+    #[ctx] ctx: &CommandContext,
+    #[arg] limit: usize,
+    #[flag] uuid: bool,
+) -> Result<Output<Listing>, anyhow::Error> {
+    api(ctx).recent_pads(limit, uuid)
 }
 
 #[allow(clippy::too_many_arguments)]
@@ -862,6 +1990,9 @@ pub fn search(
     #[flag] completed: bool,
     #[arg] tags: Vec<String>,
     #[flag] uuid: bool,
+    #[flag] count: bool,
+    #[flag] quiet: bool,
+    #[flag] all_scopes: bool,
 ) -> Result<Output<Listing>, anyhow::Error> {
     let filter = PadFilter {
         status: if all {
@@ -882,13 +2013,50 @@ pub fn search(
         tags: if tags.is_empty() { None } else { Some(tags) },
     };
 
-    api(ctx).list_pads(filter, false, deleted || archived, all, &[], uuid, false)
+    let mut output = if all_scopes {
+        api(ctx).search_all_scopes(filter, uuid)?
+    } else {
+        api(ctx).list_pads(filter, false, deleted || archived, all, &[], uuid, false)?
+    };
+    if let (true, Output::Render(listing)) = (count || quiet, &mut output) {
+        if keep_count_only(listing) == 0 {
+            super::exit::record_no_match();
+        }
+        if quiet {
+            return Ok(Output::Silent);
+        }
+    }
+    Ok(output)
+}
+
+/// `--count`: replaces a listing's pads with how many distinct pads it held
+/// (a pinned pad is listed twice, pinned and in place), and returns that.
+fn keep_count_only(listing: &mut Listing) -> usize {
+    let matched = distinct_pads(&listing.pads)
+        + listing
+            .groups
+            .iter()
+            .map(|group| distinct_pads(&group.pads))
+            .sum::<usize>();
+    listing.pads.clear();
+    listing.groups.clear();
+    listing.count = Some(matched);
+    matched
+}
+
+/// How many distinct pads `pads` lists.
+fn distinct_pads(pads: &[DisplayPad]) -> usize {
+    pads.iter()
+        .map(|dp| dp.pad.metadata.id)
+        .collect::<HashSet<_>>()
+        .len()
 }
 
 // =============================================================================
 // Pad operations
 // =============================================================================
 
+#[allow(clippy::too_many_arguments)]
 #[handler]
 pub fn view(
     #[ctx] ctx: &CommandContext,
@@ -898,9 +2066,16 @@ pub fn view(
     #[flag] flat: bool,
     #[flag] tree: bool,
     #[flag] indented: bool,
+    #[flag] reveal: bool,
+    #[flag] open_links: bool,
+    #[flag] meta: bool,
+    #[flag] spell: bool,
+    #[flag] exec: bool,
 ) -> Result<Output<PadContentResult>, anyhow::Error> {
     let nesting = parse_nesting_mode(flat, tree, indented);
-    api(ctx).view_pads(&indexes, uuid, nesting)
+    api(ctx).view_pads(
+        &indexes, uuid, nesting, reveal, open_links, meta, spell, exec,
+    )
 }
 
 /// Copy selected pads to one ordered clipboard payload and return root-selection facts.
@@ -912,9 +2087,42 @@ pub fn copy(
     #[flag] flat: bool,
     #[flag] tree: bool,
     #[flag] indented: bool,
+    #[flag] append: bool,
 ) -> Result<Output<CopyView>, anyhow::Error> {
     let nesting = parse_nesting_mode(flat, tree, indented);
-    api(ctx).copy_pads(&indexes, nesting)
+    api(ctx).copy_pads(&indexes, nesting, append)
+}
+
+/// Print (and copy) a pad with its placeholders filled in.
+#[handler]
+pub fn fill(
+    #[ctx] ctx: &CommandContext,
+    #[arg] index: String,
+    #[arg] set: Vec<String>,
+) -> Result<Output<FilledPad>, anyhow::Error> {
+    api(ctx).fill_pad(&index, &set)
+}
+
+/// Read pads aloud with the system's speech synthesizer.
+#[handler]
+pub fn speak(
+    #[ctx] ctx: &CommandContext,
+    #[arg] indexes: Vec<String>,
+    #[arg] to: Option<String>,
+    #[arg] voice: Option<String>,
+    #[arg] rate: Option<u32>,
+) -> Result<Output<SpokenPads>, anyhow::Error> {
+    let voice = crate::cli::speak::Voice { name: voice, rate };
+    api(ctx).speak_pads(&indexes, &voice, to)
+}
+
+/// Copy the snippet whose title best matches the query.
+#[handler]
+pub fn snip(
+    #[ctx] ctx: &CommandContext,
+    #[arg] query: Vec<String>,
+) -> Result<Output<SnipOutcome>, anyhow::Error> {
+    api(ctx).snip(&query.join(" "))
 }
 
 /// Edit a pad.
@@ -926,6 +2134,7 @@ pub fn copy(
 pub fn edit(
     #[ctx] ctx: &CommandContext,
     #[arg] indexes: Vec<String>,
+    #[flag] spell: bool,
 ) -> Result<Output<Modification>, anyhow::Error> {
     let state = get_state(ctx);
     let content = ctx.input::<RequestContent>(EDIT_CONTENT)?;
@@ -975,15 +2184,39 @@ pub fn edit(
         // the abort as a warning, this has always been an error.
         RequestContent::PipedEmpty => return Err(anyhow::anyhow!("Aborted: empty content")),
 
-        // Fall through to the interactive editor below.
-        RequestContent::Editor => {}
+        // Fall through to the interactive editor below. The editor writes the
+        // pad's file directly, so ownership is checked before it opens.
+        RequestContent::Editor => {
+            state.ensure_can_open_editor()?;
+            state.with_api(|api| {
+                api.check_can_edit(state.scope, &index_args)
+                    .map_err(to_anyhow)
+            })?;
+        }
+
+        // Only `create` takes `--from`; the edit chain never resolves to it.
+        RequestContent::Command { .. } | RequestContent::Clipboard => {
+            unreachable!("edit has no --from or --from-clipboard")
+        }
     }
 
+    edit_in_editor(ctx, &index_args, spell)
+}
+
+/// Opens the first pad `index_args` selects in the editor and reports the
+/// edit, once the caller has checked that an editor may open.
+fn edit_in_editor(
+    ctx: &CommandContext,
+    index_args: &[String],
+    spell: bool,
+) -> Result<Output<Modification>, anyhow::Error> {
+    let state = get_state(ctx);
+
     // Interactive editor: open real pad file
     let view_result = state.with_api(|api| {
         api.view_pads(
             state.scope,
-            &index_args,
+            index_args,
             padzapp::commands::NestingMode::Flat,
         )
         .map_err(to_anyhow)
@@ -993,51 +2226,127 @@ pub fn edit(
         .listed_pads
         .first()
         .ok_or_else(|| anyhow::anyhow!("No pad found"))?;
-    let pad_id = pad.pad.metadata.id;
-    let display_index = pad.index.clone();
-    let display_path = state.with_api(|api| {
-        api.display_path_by_id(state.scope, pad_id)
+    // A reference opens its global source.
+    let (scope, pad_id) = state.with_api(|api| {
+        api.ref_target(state.scope, pad.pad.metadata.id)
             .map_err(to_anyhow)
     })?;
 
-    let pad_path =
-        state.with_api(|api| api.get_path_by_id(state.scope, pad_id).map_err(to_anyhow))?;
+    // The editor works on the pad's real file, so misspellings are listed
+    // before it opens rather than marked up inside the pad.
+    if spell {
+        let check = spell::check(&pad.pad.content, &state.dictionary()?);
+        for m in &check.misspellings {
+            eprintln!(
+                "Misspelled: {} (line {}, column {})",
+                m.word, m.line, m.column
+            );
+        }
+    }
 
-    // Open editor on the real pad file in .padz/
-    crate::cli::editor::open_in_editor(&pad_path)?;
+    // Made last, so nothing fails between a working copy's creation and its
+    // removal.
+    let pad_path = state.with_api(|api| api.editor_file(scope, pad_id).map_err(to_anyhow))?;
 
-    // Refresh pad from disk (re-reads content, updates title)
-    match state.with_api(|api| api.refresh_pad(state.scope, &pad_id).map_err(to_anyhow))? {
-        Some(pad) => {
-            copy_content_to_clipboard(state, &pad.content);
-            let title = pad.metadata.title.clone();
+    // Open editor on the real pad file in .padz/ (or an encrypted or
+    // compressed pad's working copy, written back before the pad is picked up)
+    let edited = state.edit_pad_file(&pad_path);
+    state.with_api(|api| {
+        api.finish_editor_file(scope, pad_id, &pad_path)
+            .map_err(to_anyhow)
+    })?;
+    edited?;
 
-            let display_pad = padzapp::index::DisplayPad {
-                pad,
-                index: display_index,
-                matches: None,
-                children: Vec::new(),
-            };
-            let result = CmdResult {
-                affected_pads: vec![display_pad],
-                outcomes: vec![CmdOutcome::Updated {
-                    path: display_path,
-                    title,
-                    update_kind: UpdateKind::Refresh,
-                }],
-                ..Default::default()
-            };
-            Ok(Output::Render(api(ctx).modification_result(
-                ModificationAction::Update,
-                result,
-                false,
-            )))
-        }
-        None => {
-            // User emptied the file
-            Ok(Output::<Modification>::Silent)
+    // Pick the pad up from disk (title, index, the refresh outcome).
+    let result = state.with_api(|api| api.finish_editor_edit(scope, pad_id).map_err(to_anyhow))?;
+    let Some(edited) = result.affected_pads.first() else {
+        // User emptied the file
+        return Ok(Output::<Modification>::Silent);
+    };
+    copy_content_to_clipboard(state, &edited.pad.content);
+    api(ctx).record_access([edited]);
+    Ok(Output::Render(api(ctx).modification_result(
+        ModificationAction::Update,
+        result,
+        false,
+    )))
+}
+
+/// Open today's note in the journal.
+#[handler]
+pub fn today(#[ctx] ctx: &CommandContext) -> Result<Output<Modification>, anyhow::Error> {
+    journal_day(ctx, chrono::Local::now().date_naive())
+}
+
+/// Open yesterday's note in the journal.
+#[handler]
+pub fn yesterday(#[ctx] ctx: &CommandContext) -> Result<Output<Modification>, anyhow::Error> {
+    journal_day(
+        ctx,
+        (chrono::Local::now() - chrono::Duration::days(1)).date_naive(),
+    )
+}
+
+/// Opens the journal's note for `date` in the editor, creating the journal
+/// pad and the note first when they do not exist yet.
+fn journal_day(
+    ctx: &CommandContext,
+    date: chrono::NaiveDate,
+) -> Result<Output<Modification>, anyhow::Error> {
+    let state = get_state(ctx);
+    state.ensure_can_open_editor()?;
+    let id = state.with_api(|api| {
+        api.journal_day(state.scope, &state.journal.parent, date)
+            .map_err(to_anyhow)
+    })?;
+    edit_in_editor(ctx, &[id.to_string()], false)
+}
+
+/// Add text to the end of a pad; see [`amend`].
+#[handler]
+pub fn append(
+    #[ctx] ctx: &CommandContext,
+    #[arg] index: String,
+) -> Result<Output<Modification>, anyhow::Error> {
+    amend(ctx, index, Amend::Append)
+}
+
+/// Add text to the start of a pad; see [`amend`].
+#[handler]
+pub fn prepend(
+    #[ctx] ctx: &CommandContext,
+    #[arg] index: String,
+) -> Result<Output<Modification>, anyhow::Error> {
+    amend(ctx, index, Amend::Prepend)
+}
+
+/// Adds the `-m` text or piped stdin, as resolved by `cli::input`'s amend
+/// chain, to pad `index`. There is no editor to fall back on, so with neither
+/// there is nothing to do.
+fn amend(
+    ctx: &CommandContext,
+    index: String,
+    at: Amend,
+) -> Result<Output<Modification>, anyhow::Error> {
+    let state = get_state(ctx);
+    let text = match ctx.input::<RequestContent>(AMEND_CONTENT)? {
+        RequestContent::Direct(text) | RequestContent::Piped(text) => text,
+        RequestContent::PipedEmpty => return Err(anyhow::anyhow!("Aborted: empty content")),
+        RequestContent::Editor | RequestContent::Command { .. } | RequestContent::Clipboard => {
+            return Err(anyhow::anyhow!(
+                "Nothing to add: pipe text in or pass it with -m"
+            ))
         }
-    }
+    };
+    let result = state.with_api(|api| {
+        api.amend_pads(state.scope, &[index], text, at)
+            .map_err(to_anyhow)
+    })?;
+    Ok(Output::Render(api(ctx).modification_result(
+        ModificationAction::Update,
+        result,
+        false,
+    )))
 }
 
 #[handler]
@@ -1045,9 +2354,12 @@ pub fn delete(
     #[ctx] ctx: &CommandContext,
     #[arg] indexes: Vec<String>,
     #[flag] completed: bool,
+    #[arg] query: Option<String>,
 ) -> Result<Output<Modification>, anyhow::Error> {
     if completed {
         api(ctx).delete_completed_pads()
+    } else if let Some(query) = query {
+        api(ctx).delete_pads(&api(ctx).select_where(&query)?)
     } else {
         api(ctx).delete_pads(&indexes)
     }
@@ -1061,6 +2373,11 @@ pub fn restore(
     api(ctx).restore_pads(&indexes)
 }
 
+#[handler]
+pub fn undo(#[ctx] ctx: &CommandContext) -> Result<Output<UndoOutcome>, anyhow::Error> {
+    api(ctx).undo()
+}
+
 #[handler]
 pub fn archive(
     #[ctx] ctx: &CommandContext,
@@ -1077,6 +2394,47 @@ pub fn unarchive(
     api(ctx).unarchive_pads(&indexes)
 }
 
+/// Give a pad an alias, or clear it with `--clear`.
+#[handler]
+pub fn name(
+    #[ctx] ctx: &CommandContext,
+    #[arg] index: String,
+    #[arg] alias: Option<String>,
+    #[flag] clear: bool,
+) -> Result<Output<Modification>, anyhow::Error> {
+    let alias = if clear { None } else { alias };
+    api(ctx).name_pad(&index, alias.as_deref())
+}
+
+/// Show each selected pad's activity timeline.
+#[handler]
+pub fn timeline(
+    #[ctx] ctx: &CommandContext,
+    #[arg] indexes: Vec<String>,
+) -> Result<Output<TimelineOutcome>, anyhow::Error> {
+    api(ctx).timeline(&indexes)
+}
+
+/// Show where each selected pad lives and what is recorded about it.
+#[handler]
+pub fn which(
+    #[ctx] ctx: &CommandContext,
+    #[arg] indexes: Vec<String>,
+) -> Result<Output<WhichOutcome>, anyhow::Error> {
+    api(ctx).which(&indexes)
+}
+
+/// Link pads to files and lines in the project, or unlink them with `--remove`.
+#[handler]
+pub fn link(
+    #[ctx] ctx: &CommandContext,
+    #[arg] index: String,
+    #[arg] targets: Vec<String>,
+    #[flag] remove: bool,
+) -> Result<Output<Modification>, anyhow::Error> {
+    api(ctx).link_pads(&[index], &targets, remove)
+}
+
 /// Pin pads to the top of the list.
 #[handler]
 pub fn pin(
@@ -1094,13 +2452,20 @@ pub fn unpin(
     api(ctx).unpin_pads(&indexes)
 }
 
+/// Pinned pads across scopes, whichever scope this invocation is bound to.
+#[handler]
+pub fn pinboard(#[ctx] ctx: &CommandContext) -> Result<Output<PinboardOutcome>, anyhow::Error> {
+    api(ctx).pinboard()
+}
+
 #[handler]
 pub fn move_pads(
     #[ctx] ctx: &CommandContext,
     #[arg] indexes: Vec<String>,
     #[flag] root: bool,
+    #[arg] to: Option<String>,
 ) -> Result<Output<Modification>, anyhow::Error> {
-    api(ctx).move_pads(&indexes, root)
+    api(ctx).move_pads(&indexes, root, to.as_deref())
 }
 
 /// Returns the file path of each selected pad.
@@ -1171,30 +2536,183 @@ pub fn export(
     #[ctx] ctx: &CommandContext,
     #[arg(name = "single_file")] single_file: Option<String>,
     #[flag] json: bool,
+    #[arg] format: Option<ExportArchive>,
     #[flag(name = "with_metadata")] with_metadata: bool,
+    #[arg] into: Option<String>,
+    #[flag] resume: bool,
     #[arg] indexes: Vec<String>,
     #[flag] flat: bool,
     #[flag] tree: bool,
     #[flag] indented: bool,
+    #[flag] sign: bool,
+    #[arg] query: Option<String>,
+    #[flag] csv: bool,
+    #[arg] fields: Option<String>,
 ) -> Result<Output<padzapp::commands::export::ExportReport>, anyhow::Error> {
+    if let Some(dir) = into {
+        return api(ctx).export_pads_into_dir(&dir, resume);
+    }
+    let indexes = match query {
+        Some(query) => api(ctx).select_where(&query)?,
+        None => indexes,
+    };
     let nesting = parse_nesting_mode(flat, tree, indented);
+    if csv {
+        let fields = fields
+            .as_deref()
+            .unwrap_or(padzapp::commands::csv::DEFAULT_FIELDS);
+        return api(ctx).export_pads_csv(&indexes, nesting, fields, sign);
+    }
+    // `--json` is the tar.gz container.
+    let archive = format
+        .map(ArchiveFormat::from)
+        .or(json.then_some(ArchiveFormat::TarGz));
     api(ctx).export_pads(
         &indexes,
         single_file.as_deref(),
-        json,
+        archive,
         with_metadata,
         nesting,
+        sign,
     )
 }
 
+/// Compile tagged (or selected) pads into one document.
+#[allow(clippy::too_many_arguments)]
+#[handler]
+pub fn compile(
+    #[ctx] ctx: &CommandContext,
+    #[arg] indexes: Vec<String>,
+    #[arg] tag: Vec<String>,
+    #[arg] to: String,
+    #[arg] title: String,
+    #[arg] sort: Option<CompileSort>,
+    #[flag] reverse: bool,
+) -> Result<Output<padzapp::commands::export::ExportReport>, anyhow::Error> {
+    let options = CompileOptions {
+        tags: tag,
+        order: sort.map_or(CompileOrder::Selection, CompileOrder::from),
+        reverse,
+        title,
+        destination: to,
+    };
+    api(ctx).compile_pads(&indexes, &options)
+}
+
+/// Print pads, or write them laid out for paper to a PostScript or PDF file.
+#[handler]
+pub fn print(
+    #[ctx] ctx: &CommandContext,
+    #[arg] indexes: Vec<String>,
+    #[arg] to: Option<String>,
+    #[arg] printer: Option<String>,
+    #[arg] paper: Option<PrintPaper>,
+) -> Result<Output<padzapp::commands::export::ExportReport>, anyhow::Error> {
+    let job = to
+        .is_none()
+        .then(|| padzapp::commands::export::PrintJob { printer });
+    let destination = to.unwrap_or_else(|| "padz.ps".to_string());
+    let options = PrintOptions {
+        format: PrintFormat::from_filename(&destination),
+        paper: paper.map_or(Paper::A4, Paper::from),
+        destination,
+    };
+    api(ctx).print_pads(&indexes, &options, job)
+}
+
+/// Publish pads as gists or GitLab snippets, or update the published copies.
+#[handler]
+pub fn publish(
+    #[ctx] ctx: &CommandContext,
+    #[arg] indexes: Vec<String>,
+    #[flag] gist: bool,
+    #[flag] gitlab_snippet: bool,
+    #[flag] update: bool,
+    #[flag] public: bool,
+) -> Result<Output<PublishedPads>, anyhow::Error> {
+    let service = if gist {
+        Some(padzapp::model::PublishService::Gist)
+    } else if gitlab_snippet {
+        Some(padzapp::model::PublishService::GitlabSnippet)
+    } else {
+        None
+    };
+    api(ctx).publish_pads(&indexes, service, update, public)
+}
+
+/// Draft release notes from the pads tagged `tag` since the release `since`
+/// (a git tag or commit; without it the latest tag), with the commits made
+/// since for context. Outside a repository, or before the first tag, every
+/// tagged pad is gathered.
+#[handler]
+pub fn release_notes(
+    #[ctx] ctx: &CommandContext,
+    #[arg] tag: Vec<String>,
+    #[arg] since: Option<String>,
+) -> Result<Output<ReleaseNotes>, anyhow::Error> {
+    let state = get_state(ctx);
+    let cwd = &state.cwd;
+    let since = since.or_else(|| super::git_context::latest_tag(cwd));
+    let released_at = match &since {
+        Some(reference) => Some(super::git_context::committed_at(cwd, reference).ok_or_else(
+            || anyhow::anyhow!("'{}' is not a tag or commit in this repository", reference),
+        )?),
+        None => None,
+    };
+    let notes = state.with_api(|api| {
+        api.release_notes(state.scope, &tag, released_at)
+            .map_err(to_anyhow)
+    })?;
+    let commits = since
+        .as_deref()
+        .map(|reference| super::git_context::commits_since(cwd, reference))
+        .unwrap_or_default()
+        .into_iter()
+        .map(|(id, subject)| ReleaseCommit { id, subject })
+        .collect();
+    Ok(Output::Render(ReleaseNotes {
+        since,
+        notes,
+        commits,
+    }))
+}
+
+/// Check an exported file against its detached signature.
+#[handler]
+pub fn verify(
+    #[ctx] ctx: &CommandContext,
+    #[arg] file: Option<String>,
+    #[arg] signature: Option<String>,
+    #[flag] accept: bool,
+) -> Result<Output<VerifyView>, anyhow::Error> {
+    match file {
+        Some(file) => api(ctx).verify_signature(&file, signature.as_deref()),
+        None => api(ctx).verify_store(accept),
+    }
+}
+
 /// Import requested paths and return mode-independent semantic facts.
 #[handler]
 pub fn import(
     #[ctx] ctx: &CommandContext,
     #[arg] paths: Vec<String>,
+    #[flag] recursive: bool,
+    #[flag] filename_title: bool,
+    #[flag] project: bool,
 ) -> Result<Output<padzapp::commands::import::ImportReport>, anyhow::Error> {
+    if project && get_state(ctx).scope == Scope::Global {
+        anyhow::bail!(
+            "No project store here to import into: run `padz init` first, or drop --project"
+        );
+    }
     let paths: Vec<std::path::PathBuf> = paths.into_iter().map(std::path::PathBuf::from).collect();
-    api(ctx).import_pads(paths)
+    api(ctx).import_pads(
+        paths,
+        padzapp::commands::import::ImportOptions {
+            recursive,
+            title_from_filename: filename_title,
+        },
+    )
 }
 
 #[handler]
@@ -1236,6 +2754,40 @@ pub fn doctor(#[ctx] ctx: &CommandContext) -> Result<Output<DoctorOutcome>, anyh
     api(ctx).doctor()
 }
 
+#[handler]
+pub fn maintain(#[ctx] ctx: &CommandContext) -> Result<Output<MaintainView>, anyhow::Error> {
+    api(ctx).maintain()
+}
+
+#[handler]
+pub fn tree(#[ctx] ctx: &CommandContext) -> Result<Output<TreeOutcome>, anyhow::Error> {
+    api(ctx).tree()
+}
+
+#[handler]
+pub fn stats(
+    #[ctx] ctx: &CommandContext,
+    #[flag] usage: bool,
+) -> Result<Output<StatsView>, anyhow::Error> {
+    api(ctx).stats(usage)
+}
+
+#[handler]
+pub fn flush_queue(
+    #[ctx] ctx: &CommandContext,
+    #[flag] list: bool,
+) -> Result<Output<QueueReport>, anyhow::Error> {
+    api(ctx).flush_queue(list)
+}
+
+#[handler]
+pub fn self_update(
+    #[ctx] ctx: &CommandContext,
+    #[flag] check: bool,
+) -> Result<Output<UpdateOutcome>, anyhow::Error> {
+    api(ctx).self_update(check)
+}
+
 #[handler]
 pub fn init(
     #[ctx] ctx: &CommandContext,
@@ -1292,6 +2844,19 @@ fn split_indexes_and_tags(args: &[String]) -> Result<(Vec<String>, Vec<String>),
     Ok((indexes, tags))
 }
 
+/// The pads and tags of `tag add`/`tag remove`: the pads a `--where` query
+/// selects, with every argument a tag, or the leading selectors of `args`.
+fn tagging_targets(
+    ctx: &CommandContext,
+    args: Vec<String>,
+    query: Option<String>,
+) -> Result<(Vec<String>, Vec<String>), anyhow::Error> {
+    match query {
+        Some(query) => Ok((api(ctx).select_where(&query)?, args)),
+        None => split_indexes_and_tags(&args),
+    }
+}
+
 pub mod tag {
     use super::*;
 
@@ -1299,8 +2864,9 @@ pub mod tag {
     pub fn add(
         #[ctx] ctx: &CommandContext,
         #[arg] args: Vec<String>,
+        #[arg] query: Option<String>,
     ) -> Result<Output<TaggingResult>, anyhow::Error> {
-        let (indexes, tags) = split_indexes_and_tags(&args)?;
+        let (indexes, tags) = tagging_targets(ctx, args, query)?;
         let state = get_state(ctx);
         let result = state.with_api(|api| {
             api.add_tags_to_pads(state.scope, &indexes, &tags)
@@ -1313,8 +2879,9 @@ pub mod tag {
     pub fn remove(
         #[ctx] ctx: &CommandContext,
         #[arg] args: Vec<String>,
+        #[arg] query: Option<String>,
     ) -> Result<Output<TaggingResult>, anyhow::Error> {
-        let (indexes, tags) = split_indexes_and_tags(&args)?;
+        let (indexes, tags) = tagging_targets(ctx, args, query)?;
         let state = get_state(ctx);
         let result = state.with_api(|api| {
             api.remove_tags_from_pads(state.scope, &indexes, &tags)
@@ -1356,6 +2923,68 @@ pub mod tag {
     }
 }
 
+/// Scope registry subcommand handlers
+pub mod journal {
+    use super::*;
+
+    #[handler]
+    pub fn ls(#[ctx] ctx: &CommandContext) -> Result<Output<JournalOutcome>, anyhow::Error> {
+        let state = get_state(ctx);
+        let outcome = state.with_api(|api| {
+            api.journal(state.scope, &state.journal.parent)
+                .map_err(to_anyhow)
+        })?;
+        Ok(Output::Render(outcome))
+    }
+}
+
+pub mod refs {
+    use super::*;
+
+    #[handler]
+    pub fn add(
+        #[ctx] ctx: &CommandContext,
+        #[arg] indexes: Vec<String>,
+    ) -> Result<Output<Modification>, anyhow::Error> {
+        api(ctx).add_refs(&indexes)
+    }
+}
+
+pub mod scopes {
+    use super::*;
+
+    #[handler]
+    pub fn add(
+        #[ctx] ctx: &CommandContext,
+        #[arg] name: String,
+        #[arg] url: String,
+    ) -> Result<Output<RemoteAdded>, anyhow::Error> {
+        api(ctx).add_remote_scope(&name, &url)
+    }
+
+    #[handler]
+    pub fn prune(
+        #[ctx] ctx: &CommandContext,
+        #[flag] yes: bool,
+    ) -> Result<Output<PruneOutcome>, anyhow::Error> {
+        api(ctx).prune_scopes(yes)
+    }
+}
+
+pub mod encrypt {
+    use super::*;
+
+    #[handler]
+    pub fn enable(#[ctx] ctx: &CommandContext) -> Result<Output<EncryptOutcome>, anyhow::Error> {
+        api(ctx).set_encryption(true)
+    }
+
+    #[handler]
+    pub fn disable(#[ctx] ctx: &CommandContext) -> Result<Output<EncryptOutcome>, anyhow::Error> {
+        api(ctx).set_encryption(false)
+    }
+}
+
 #[cfg(test)]
 mod tests {
     //! Direct typed-handler tests.
@@ -1368,6 +2997,7 @@ mod tests {
     use super::*;
     use padzapp::index::DisplayIndex;
     use padzapp::init::initialize;
+    use padzapp::usage::UsageReport;
     use standout_dispatch::Extensions;
     use std::rc::Rc;
     use tempfile::TempDir;
@@ -1453,6 +3083,11 @@ mod tests {
                 vec![],
                 false,
                 false,
+                None,
+                None,
+                false,
+                None,
+                false,
             )
             .unwrap(),
         );
@@ -1484,6 +3119,11 @@ mod tests {
                 vec![],
                 false,
                 false,
+                None,
+                None,
+                false,
+                None,
+                false,
             )
             .unwrap(),
         );
@@ -1512,6 +3152,11 @@ mod tests {
                 vec![],
                 true,  // uuid
                 false, // show_status
+                None,
+                None,
+                false,
+                None,
+                false,
             )
             .unwrap(),
         );
@@ -1537,6 +3182,8 @@ mod tests {
                 false,
                 vec![],
                 false,
+                false,
+                false,
             )
             .unwrap(),
         );
@@ -1547,6 +3194,44 @@ mod tests {
         assert_eq!(result.pads[0].pad.metadata.title, "Alpha");
     }
 
+    #[test]
+    fn jsonl_listing_writes_one_object_per_line_and_stops_at_a_closed_pipe() {
+        struct ClosedAfter(usize, Vec<u8>);
+        impl Write for ClosedAfter {
+            fn write(&mut self, buf: &[u8]) -> std::io::Result<usize> {
+                if self.1.iter().filter(|byte| **byte == b'\n').count() >= self.0 {
+                    return Err(std::io::ErrorKind::BrokenPipe.into());
+                }
+                self.1.extend_from_slice(buf);
+                Ok(buf.len())
+            }
+            fn flush(&mut self) -> std::io::Result<()> {
+                Ok(())
+            }
+        }
+
+        let app = TestApp::new(PadzMode::Notes);
+        app.seed("First", "one");
+        app.seed("Second", "two");
+
+        let mut out = ClosedAfter(usize::MAX, Vec::new());
+        api(&app.ctx)
+            .stream_listing(PadFilter::default(), &[], &mut out)
+            .unwrap();
+        let lines: Vec<serde_json::Value> = String::from_utf8(out.1)
+            .unwrap()
+            .lines()
+            .map(|line| serde_json::from_str(line).unwrap())
+            .collect();
+        assert_eq!(lines.len(), 2);
+        assert_eq!(lines[0]["index"], "1");
+        assert_eq!(lines[0]["title"], "Second");
+
+        let mut head = ClosedAfter(1, Vec::new());
+        let output = api(&app.ctx).stream_listing(PadFilter::default(), &[], &mut head);
+        assert!(matches!(output, Ok(Output::Silent)));
+    }
+
     // --- view -----------------------------------------------------------------
 
     #[test]
@@ -1563,6 +3248,11 @@ mod tests {
                 false,
                 false,
                 false,
+                false,
+                false,
+                false,
+                false,
+                false,
             )
             .unwrap(),
         );
@@ -1579,13 +3269,73 @@ mod tests {
         let app = TestApp::new(PadzMode::Notes);
         app.seed("Viewed", "the body");
 
-        // view's flags are (peek, uuid, flat, tree, indented).
-        let result =
-            rendered(view(&app.ctx, vec!["1".into()], false, true, false, false, false).unwrap());
+        // view's flags are (peek, uuid, flat, tree, indented, reveal).
+        let result = rendered(
+            view(
+                &app.ctx,
+                vec!["1".into()],
+                false,
+                true,
+                false,
+                false,
+                false,
+                false,
+                false,
+                false,
+                false,
+                false,
+            )
+            .unwrap(),
+        );
 
         assert!(result.pads[0].uuid.is_some());
     }
 
+    #[test]
+    fn view_keeps_secret_fences_sealed_unless_revealed() {
+        let app = TestApp::new(PadzMode::Notes);
+        app.seed("Staging", "Host is db.staging.\n\n```secret\nhunter2\n```");
+
+        let sealed = rendered(
+            view(
+                &app.ctx,
+                vec!["1".into()],
+                false,
+                false,
+                false,
+                false,
+                false,
+                false,
+                false,
+                false,
+                false,
+                false,
+            )
+            .unwrap(),
+        );
+        let revealed = rendered(
+            view(
+                &app.ctx,
+                vec!["1".into()],
+                false,
+                false,
+                false,
+                false,
+                false,
+                true,
+                false,
+                false,
+                false,
+                false,
+            )
+            .unwrap(),
+        );
+
+        assert!(!sealed.pads[0].content.contains("hunter2"));
+        assert!(sealed.pads[0].content.contains("Host is db.staging."));
+        assert!(revealed.pads[0].content.contains("hunter2"));
+    }
+
     #[test]
     fn view_clipboard_payload_joins_roots_once_and_excludes_children() {
         let view = PadContentResult {
@@ -1596,18 +3346,27 @@ mod tests {
                     content: "one".to_string(),
                     depth: 0,
                     uuid: None,
+                    anchors: Vec::new(),
+                    meta: None,
+                    spelling: None,
                 },
                 PadContent {
                     title: "Child".to_string(),
                     content: "nested".to_string(),
                     depth: 1,
                     uuid: None,
+                    anchors: Vec::new(),
+                    meta: None,
+                    spelling: None,
                 },
                 PadContent {
                     title: "Second".to_string(),
                     content: "two".to_string(),
                     depth: 0,
                     uuid: None,
+                    anchors: Vec::new(),
+                    meta: None,
+                    spelling: None,
                 },
             ],
         };
@@ -1618,6 +3377,31 @@ mod tests {
         );
     }
 
+    #[test]
+    fn view_marks_the_pad_as_recently_read() {
+        let app = TestApp::new(PadzMode::Notes);
+        app.seed("Read", "body");
+        app.seed("Unread", "body");
+
+        view(
+            &app.ctx,
+            vec!["2".into()],
+            false,
+            false,
+            false,
+            false,
+            false,
+        )
+        .unwrap();
+        let result = rendered(recent(&app.ctx, 10, false).unwrap());
+
+        assert_eq!(result.pads.len(), 1);
+        assert_eq!(result.pads[0].pad.metadata.title, "Read");
+        // Recency reorders nothing: the pad keeps its canonical index.
+        assert!(matches!(result.pads[0].index, DisplayIndex::Regular(2)));
+        assert!(result.request.filtered);
+    }
+
     // --- mutation -------------------------------------------------------------
 
     #[test]
@@ -1633,6 +3417,38 @@ mod tests {
         assert!(result.pads[0].pad.metadata.is_pinned);
     }
 
+    #[test]
+    fn pinboard_returns_pinned_pads_with_their_scope() {
+        let app = TestApp::new(PadzMode::Notes);
+        app.seed("Loose", "body");
+        app.seed("Pinned", "body");
+        pin(&app.ctx, vec!["1".into()]).unwrap();
+
+        let result = rendered(pinboard(&app.ctx).unwrap());
+
+        assert_eq!(result.entries.len(), 1);
+        assert_eq!(result.entries[0].scope, Scope::Project);
+        assert_eq!(result.entries[0].pad.pad.metadata.title, "Pinned");
+        assert!(matches!(
+            result.entries[0].pad.index,
+            DisplayIndex::Pinned(1)
+        ));
+    }
+
+    #[test]
+    fn stats_counts_pads_and_reports_usage_as_disabled_by_default() {
+        let app = TestApp::new(PadzMode::Notes);
+        app.seed("One", "body");
+        app.seed("Two", "body");
+        pin(&app.ctx, vec!["1".into()]).unwrap();
+
+        let result = rendered(stats(&app.ctx, true).unwrap());
+
+        assert_eq!(result.pads.active, 2);
+        assert_eq!(result.pads.pinned, 1);
+        assert_eq!(result.usage, Some(UsageReport::Disabled));
+    }
+
     #[test]
     fn complete_requests_status_icons_even_in_notes_mode() {
         let app = TestApp::new(PadzMode::Notes);
@@ -1650,7 +3466,7 @@ mod tests {
         let app = TestApp::new(PadzMode::Todos);
         app.seed("Still open", "body");
 
-        let result = rendered(delete(&app.ctx, vec![], true).unwrap());
+        let result = rendered(delete(&app.ctx, vec![], true, None).unwrap());
 
         assert!(result.pads.is_empty());
         assert_eq!(
@@ -1715,6 +3531,11 @@ mod tests {
                 vec![],
                 false,
                 false,
+                None,
+                None,
+                false,
+                None,
+                false,
             )
             .unwrap(),
         );
@@ -0,0 +1,199 @@
+//! The lines above the command list in `padz --help`.
+//!
+//! A new user's first question is which store a command will act on. Top-level
+//! help answers it before anything else: the scope the current directory
+//! resolves to, as `--global`, `--data` and `--remote` would select it, the
+//! pads in it, and what running `padz` alone does there:
+//!
+//! ```text
+//! Scope: project, /home/me/app/.padz (4 pads, 1 pinned; 2 archived)
+//! `padz` alone lists these pads; `… | padz` creates one from what is piped in.
+//! ```
+//!
+//! Help is printed while the arguments are parsed, before any app state
+//! exists, so the scope flags are read from the raw arguments. Like the prompt
+//! segment (see [`super::prompt`]), the store is located without being
+//! initialized and counted from its indexes alone: asking for help never
+//! writes. Whatever cannot be worked out is left out, and under `--test-mode`
+//! there is no header at all.
+
+use padzapp::commands::stats::{self, PadCounts};
+use padzapp::init::{find_git_root, locate_project_store, PadzEnv};
+use padzapp::model::Scope;
+use padzapp::store::fs::FileStore;
+use std::path::{Path, PathBuf};
+
+/// What `padz` does with no command, as [`super::setup`] decides it.
+const NAKED_INVOCATION: &str =
+    "`padz` alone lists these pads; `… | padz` creates one from what is piped in.";
+
+/// The global flags that choose a scope, as found among raw arguments.
+#[derive(Debug, Default, PartialEq, Eq)]
+struct ScopeFlags {
+    global: bool,
+    data: Option<PathBuf>,
+    remote: Option<String>,
+    test_mode: bool,
+}
+
+impl ScopeFlags {
+    fn from_args(args: &[String]) -> Self {
+        let mut flags = Self::default();
+        let mut args = args.iter();
+        while let Some(arg) = args.next() {
+            match arg.as_str() {
+                "-g" | "--global" => flags.global = true,
+                "--test-mode" => flags.test_mode = true,
+                "--data" => flags.data = args.next().map(PathBuf::from),
+                "--remote" => flags.remote = args.next().cloned(),
+                _ => {
+                    if let Some(data) = arg.strip_prefix("--data=") {
+                        flags.data = Some(PathBuf::from(data));
+                    } else if let Some(remote) = arg.strip_prefix("--remote=") {
+                        flags.remote = Some(remote.to_string());
+                    }
+                }
+            }
+        }
+        flags
+    }
+}
+
+/// The header for help asked for with `args`, ending in a blank line; empty
+/// when there is nothing to say.
+pub fn header(args: &[String]) -> String {
+    let flags = ScopeFlags::from_args(args);
+    if flags.test_mode {
+        return String::new();
+    }
+    let Ok(cwd) = std::env::current_dir() else {
+        return String::new();
+    };
+    describe(&super::env::resolve(), &cwd, flags)
+        .map(|lines| format!("{}\n\n", lines))
+        .unwrap_or_default()
+}
+
+fn describe(env: &PadzEnv, cwd: &Path, flags: ScopeFlags) -> Option<String> {
+    if let Some(remote) = flags.remote {
+        return Some(format!(
+            "Scope: remote store {} (read-only)\n`padz --remote {}` alone lists its pads.",
+            remote, remote
+        ));
+    }
+    let project = locate_project_store(env, cwd, flags.global, flags.data).ok()?;
+    let (root, scope, name) = match &project {
+        Some(dir) => (dir.clone(), Scope::Project, "project"),
+        None => (env.global_data_dir.clone(), Scope::Global, "global"),
+    };
+    let counts = root
+        .is_dir()
+        .then(|| {
+            let store = FileStore::new_fs(project.clone(), env.global_data_dir.clone());
+            stats::from_index(&store, scope).ok()
+        })
+        .flatten();
+
+    let mut lines = format!(
+        "Scope: {}, {} ({})",
+        name,
+        root.display(),
+        counts.as_ref().map_or("no pads yet".into(), format_counts)
+    );
+    // Outside any project, a new pad still goes into the enclosing repository.
+    if project.is_none() && !flags.global {
+        match find_git_root(cwd, env.home_dir.as_deref()) {
+            Some(repo) => lines.push_str(&format!(
+                "\nNo project store yet: the first pad created here starts one in {}.",
+                repo.join(".padz").display()
+            )),
+            None => lines.push_str("\nNo project store here; `padz init` starts one."),
+        }
+    }
+    lines.push('\n');
+    lines.push_str(NAKED_INVOCATION);
+    Some(lines)
+}
+
+/// `4 pads, 1 pinned; 2 archived, 1 deleted`; a count of zero is left out.
+fn format_counts(counts: &PadCounts) -> String {
+    let mut out = match counts.active {
+        0 => "no pads yet".to_string(),
+        1 => "1 pad".to_string(),
+        n => format!("{} pads", n),
+    };
+    if counts.pinned > 0 {
+        out.push_str(&format!(", {} pinned", counts.pinned));
+    }
+    let mut put_away = Vec::new();
+    if counts.archived > 0 {
+        put_away.push(format!("{} archived", counts.archived));
+    }
+    if counts.deleted > 0 {
+        put_away.push(format!("{} deleted", counts.deleted));
+    }
+    if !put_away.is_empty() {
+        out.push_str("; ");
+        out.push_str(&put_away.join(", "));
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    fn args(args: &[&str]) -> Vec<String> {
+        args.iter().map(|a| a.to_string()).collect()
+    }
+
+    #[test]
+    fn scope_flags_are_read_from_raw_arguments() {
+        assert_eq!(
+            ScopeFlags::from_args(&args(&["-g", "--help"])),
+            ScopeFlags {
+                global: true,
+                ..ScopeFlags::default()
+            }
+        );
+        let flags = ScopeFlags::from_args(&args(&["--data", "/tmp/w", "--remote=devbox", "-h"]));
+        assert_eq!(flags.data, Some(PathBuf::from("/tmp/w")));
+        assert_eq!(flags.remote.as_deref(), Some("devbox"));
+    }
+
+    #[test]
+    fn format_counts_leaves_out_zero_counts() {
+        let counts = |active, pinned, archived, deleted| PadCounts {
+            active,
+            pinned,
+            archived,
+            deleted,
+        };
+        assert_eq!(
+            format_counts(&counts(4, 1, 2, 0)),
+            "4 pads, 1 pinned; 2 archived"
+        );
+        assert_eq!(format_counts(&counts(1, 0, 0, 3)), "1 pad; 3 deleted");
+        assert_eq!(format_counts(&counts(0, 0, 0, 0)), "no pads yet");
+    }
+
+    #[test]
+    fn outside_a_project_help_names_the_global_store_and_where_one_would_start() {
+        let temp = TempDir::new().unwrap();
+        let env = PadzEnv {
+            global_data_dir: temp.path().join("global"),
+            home_dir: Some(temp.path().to_path_buf()),
+        };
+        let repo = temp.path().join("repo");
+        std::fs::create_dir_all(repo.join(".git")).unwrap();
+
+        let header = describe(&env, &repo, ScopeFlags::default()).unwrap();
+        assert!(header.starts_with("Scope: global,"));
+        assert!(header.contains("(no pads yet)"));
+        assert!(header.contains(&repo.join(".padz").display().to_string()));
+        // Nothing was written to find that out.
+        assert!(!env.global_data_dir.exists());
+        assert!(!repo.join(".padz").exists());
+    }
+}
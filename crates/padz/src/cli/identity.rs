@@ -0,0 +1,82 @@
+//! Who is running padz (`identity.name`, `identity.email`).
+//!
+//! The name is recorded as the owner of each pad created (see
+//! [`padzapp::commands::ownership`]); the email picks the gpg key
+//! `export --sign` signs with. A key left unset in `padz.toml` is read from
+//! git's `user.name` or `user.email`, as git would for a commit made where
+//! padz runs, and a name still unknown is the OS user's (`$USER`, `$USERNAME`
+//! on Windows). Under `--test-mode` only the configuration is consulted.
+
+use super::git_context;
+use padzapp::config::IdentityConfig;
+use std::path::Path;
+
+/// Who a command runs as; either part may be unknown.
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct Identity {
+    pub name: Option<String>,
+    pub email: Option<String>,
+}
+
+impl From<&IdentityConfig> for Identity {
+    fn from(configured: &IdentityConfig) -> Self {
+        Self {
+            name: known(configured.name.clone()),
+            email: known(configured.email.clone()),
+        }
+    }
+}
+
+/// `configured`, its gaps filled from git's configuration in `cwd` and then
+/// from the OS user.
+pub fn resolve(configured: &IdentityConfig, cwd: &Path) -> Identity {
+    let Identity { name, email } = configured.into();
+    let name = name
+        .or_else(|| git_context::config(cwd, "user.name"))
+        .or_else(|| known(std::env::var("USER").ok()))
+        .or_else(|| known(std::env::var("USERNAME").ok()));
+    let email = email.or_else(|| git_context::config(cwd, "user.email"));
+    Identity { name, email }
+}
+
+/// `value`, unless it is missing or blank.
+fn known(value: Option<String>) -> Option<String> {
+    value.filter(|value| !value.trim().is_empty())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::process::Command;
+
+    #[test]
+    fn unset_keys_come_from_git_and_set_ones_win() {
+        let dir = tempfile::TempDir::new().unwrap();
+        let git = |args: &[&str]| {
+            Command::new("git")
+                .arg("-C")
+                .arg(dir.path())
+                .args(args)
+                .output()
+                .is_ok_and(|o| o.status.success())
+        };
+        if !git(&["init", "-q"]) {
+            return; // no git on this machine
+        }
+        assert!(git(&["config", "user.name", "Ana Lima"]));
+        assert!(git(&["config", "user.email", "ana@example.com"]));
+
+        let from_git = resolve(&IdentityConfig::default(), dir.path());
+        assert_eq!(from_git.name.as_deref(), Some("Ana Lima"));
+        assert_eq!(from_git.email.as_deref(), Some("ana@example.com"));
+
+        let configured = IdentityConfig {
+            name: Some("ana".into()),
+            email: Some(" ".into()),
+        };
+        let resolved = resolve(&configured, dir.path());
+        assert_eq!(resolved.name.as_deref(), Some("ana"));
+        assert_eq!(resolved.email.as_deref(), Some("ana@example.com"));
+        assert_eq!(Identity::from(&configured).email, None);
+    }
+}
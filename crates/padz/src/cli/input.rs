@@ -1,4 +1,4 @@
-//! Request-scoped input resolution for `create` and `edit`.
+//! Request-scoped input resolution for `create`, `edit`, `append` and `prepend`.
 //!
 //! # Why this module exists
 //!
@@ -13,10 +13,16 @@
 //! declares when it is available, the chain tries them in order, and the
 //! `.default(...)` arm is the editor. Reading the chain is reading the policy.
 //!
-//! # The precedence (unchanged from before this module existed)
+//! # The precedence
 //!
 //! For `create`:
 //!
+//! 0. **Command** — `--from <CMD>`: the command's output is the text, opened
+//!    in the editor first unless `--no-editor` is set. Naming a command is the
+//!    most explicit source there is, so it wins over every other; the handler
+//!    runs it, as it does the editor. `--from-clipboard` stands in the same
+//!    place, the two being exclusive: the clipboard's text is the pad, taken
+//!    as piped text is. Clap rejects giving both.
 //! 1. **Direct** — the title args, used verbatim with the editor skipped. Only
 //!    when `--no-editor` is set, or todos mode was given title args, and never
 //!    when `--editor` forces the editor. **Stdin is not read at all on this
@@ -28,6 +34,10 @@
 //! For `edit` the same shape holds; only the Direct rule differs (todos mode
 //! with trailing content words after the index selectors).
 //!
+//! `append` and `prepend` take `-m <TEXT>` as their Direct source, then piped
+//! stdin. They never open an editor: the chain's default is still
+//! [`RequestContent::Editor`], and the handler reports it as nothing to add.
+//!
 //! # What is deliberately *not* a Standout primitive here
 //!
 //! - **`StdinSource`**: the framework source returns `None` for empty piped
@@ -43,9 +53,11 @@
 //!   half-created pad. Those are pad-lifecycle concerns the handler must own,
 //!   so the chain resolves to [`RequestContent::Editor`] and stops there; it
 //!   does not try to launch anything.
-//! - **`ClipboardSource`**: padz does not, and did not, read the clipboard as a
-//!   create/edit input source. The clipboard is written to after a pad is
-//!   saved, never read from to prefill one.
+//! - **`ClipboardSource`**: the clipboard is read only when `--from-clipboard`
+//!   asks for it, never tried as a fallback. The chain resolves the flag to
+//!   [`RequestContent::Clipboard`] and the handler reads through the
+//!   app state's clipboard reader, which tests replace as they replace the
+//!   writer.
 
 use clap::ArgMatches;
 use padzapp::config::PadzMode;
@@ -63,6 +75,9 @@ pub const CREATE_CONTENT: &str = "create_content";
 /// The name the `edit` content input is registered and looked up under.
 pub const EDIT_CONTENT: &str = "edit_content";
 
+/// The name the `append`/`prepend` text input is registered and looked up under.
+pub const AMEND_CONTENT: &str = "amend_content";
+
 /// Where a create/edit request's text comes from, resolved before dispatch.
 ///
 /// This is the whole of what the handlers learn about the shell: they match on
@@ -83,12 +98,57 @@ pub enum RequestContent {
     PipedEmpty,
     /// No non-interactive source offered text; open the editor on the pad file.
     Editor,
+    /// `create --from`: run `command` and take what it prints, opening the
+    /// editor on it first when `edit` is set.
+    Command { command: String, edit: bool },
+    /// `create --from-clipboard`: read the clipboard and take its text as
+    /// piped text is taken.
+    Clipboard,
 }
 
 // =============================================================================
 // Sources
 // =============================================================================
 
+/// `create --from`: the command whose output becomes the pad.
+struct FromCommandSource;
+
+impl InputCollector<RequestContent> for FromCommandSource {
+    // See `CreateDirectSource::name` — the string is the kind mapping.
+    fn name(&self) -> &'static str {
+        "argument"
+    }
+
+    fn is_available(&self, matches: &ArgMatches) -> bool {
+        one(matches, "from").is_some()
+    }
+
+    fn collect(&self, matches: &ArgMatches) -> Result<Option<RequestContent>, InputError> {
+        Ok(one(matches, "from").map(|command| RequestContent::Command {
+            command,
+            edit: !flag(matches, "no_editor"),
+        }))
+    }
+}
+
+/// `create --from-clipboard`: the clipboard's text becomes the pad.
+struct FromClipboardSource;
+
+impl InputCollector<RequestContent> for FromClipboardSource {
+    // See `CreateDirectSource::name` — the string is the kind mapping.
+    fn name(&self) -> &'static str {
+        "argument"
+    }
+
+    fn is_available(&self, matches: &ArgMatches) -> bool {
+        flag(matches, "from_clipboard")
+    }
+
+    fn collect(&self, _matches: &ArgMatches) -> Result<Option<RequestContent>, InputError> {
+        Ok(Some(RequestContent::Clipboard))
+    }
+}
+
 /// The `create` quick-path source: title args used verbatim, editor skipped.
 ///
 /// Availability mirrors the original `skip_editor` rule exactly:
@@ -155,6 +215,24 @@ impl InputCollector<RequestContent> for EditDirectSource {
     }
 }
 
+/// The `append`/`prepend` quick path: `-m <TEXT>`, used as given.
+struct MessageSource;
+
+impl InputCollector<RequestContent> for MessageSource {
+    // See `CreateDirectSource::name` — the string is the kind mapping.
+    fn name(&self) -> &'static str {
+        "argument"
+    }
+
+    fn is_available(&self, matches: &ArgMatches) -> bool {
+        one(matches, "message").is_some()
+    }
+
+    fn collect(&self, matches: &ArgMatches) -> Result<Option<RequestContent>, InputError> {
+        Ok(one(matches, "message").map(|raw| RequestContent::Direct(expand_newlines(&raw))))
+    }
+}
+
 /// Piped stdin, with padz's abort-on-empty semantics.
 ///
 /// Reuses the framework's [`StdinReader`] abstraction — so
@@ -217,9 +295,12 @@ impl InputCollector<RequestContent> for PipedSource {
 // Chains
 // =============================================================================
 
-/// The `create` content chain: direct args, then piped stdin, then the editor.
+/// The `create` content chain: a `--from` command or the clipboard, direct
+/// args, then piped stdin, then the editor.
 pub(super) fn create_chain(mode: PadzMode) -> InputChain<RequestContent> {
     InputChain::new()
+        .try_source(FromCommandSource)
+        .try_source(FromClipboardSource)
         .try_source(CreateDirectSource { mode })
         .try_source(PipedSource::from_process())
         .default(RequestContent::Editor)
@@ -233,6 +314,15 @@ pub(super) fn edit_chain(mode: PadzMode) -> InputChain<RequestContent> {
         .default(RequestContent::Editor)
 }
 
+/// The `append`/`prepend` chain: `-m` text, then piped stdin. The default
+/// means neither was given; these commands have no editor to fall back on.
+pub(super) fn amend_chain() -> InputChain<RequestContent> {
+    InputChain::new()
+        .try_source(MessageSource)
+        .try_source(PipedSource::from_process())
+        .default(RequestContent::Editor)
+}
+
 // =============================================================================
 // ArgMatches helpers
 // =============================================================================
@@ -241,6 +331,10 @@ fn flag(matches: &ArgMatches, name: &str) -> bool {
     matches.try_get_one::<bool>(name).ok().flatten() == Some(&true)
 }
 
+fn one(matches: &ArgMatches, name: &str) -> Option<String> {
+    matches.try_get_one::<String>(name).ok().flatten().cloned()
+}
+
 fn title_args(matches: &ArgMatches) -> Vec<String> {
     many(matches, "title")
 }
@@ -297,6 +391,12 @@ mod tests {
                     .long("no-editor")
                     .action(clap::ArgAction::SetTrue),
             )
+            .arg(clap::Arg::new("from").long("from"))
+            .arg(
+                clap::Arg::new("from_clipboard")
+                    .long("from-clipboard")
+                    .action(clap::ArgAction::SetTrue),
+            )
             .arg(
                 clap::Arg::new("title")
                     .num_args(0..)
@@ -321,6 +421,8 @@ mod tests {
     /// A create chain with an injected stdin, so no test needs a pty.
     fn create_chain_with(mode: PadzMode, stdin: MockStdin) -> InputChain<RequestContent> {
         InputChain::new()
+            .try_source(FromCommandSource)
+            .try_source(FromClipboardSource)
             .try_source(CreateDirectSource { mode })
             .try_source(PipedSource::with_reader(stdin))
             .default(RequestContent::Editor)
@@ -347,6 +449,53 @@ mod tests {
         assert_eq!(source, InputSourceKind::Arg);
     }
 
+    /// `--from` wins over piped stdin and the direct path alike, and keeps the
+    /// editor unless `--no-editor` is set.
+    #[test]
+    fn from_command_wins_over_every_other_source() {
+        let (value, source) = resolve_for_test(
+            create_chain_with(PadzMode::Todos, MockStdin::piped("IGNORED")),
+            &create_matches(&["--from", "kubectl get pods", "Pods"]),
+        );
+        assert_eq!(
+            value,
+            RequestContent::Command {
+                command: "kubectl get pods".into(),
+                edit: true,
+            }
+        );
+        assert_eq!(source, InputSourceKind::Arg);
+
+        let (value, _) = resolve_for_test(
+            create_chain_with(PadzMode::Notes, MockStdin::terminal()),
+            &create_matches(&["--no-editor", "--from", "date"]),
+        );
+        assert_eq!(
+            value,
+            RequestContent::Command {
+                command: "date".into(),
+                edit: false,
+            }
+        );
+    }
+
+    /// `--from-clipboard` is read even with stdin piped and `--no-editor`
+    /// set; without the flag the clipboard is never a source.
+    #[test]
+    fn from_clipboard_wins_over_piped_stdin() {
+        let (value, _) = resolve_for_test(
+            create_chain_with(PadzMode::Notes, MockStdin::piped("IGNORED")),
+            &create_matches(&["--from-clipboard", "--no-editor", "Title"]),
+        );
+        assert_eq!(value, RequestContent::Clipboard);
+
+        let (value, _) = resolve_for_test(
+            create_chain_with(PadzMode::Notes, MockStdin::terminal()),
+            &create_matches(&[]),
+        );
+        assert_eq!(value, RequestContent::Editor);
+    }
+
     /// Todos mode with title args skips the editor; notes mode does not.
     #[test]
     fn todos_mode_with_title_takes_the_direct_path() {
@@ -518,4 +667,41 @@ mod tests {
         );
         assert_eq!(value, RequestContent::Direct("Inline".into()));
     }
+
+    /// `-m` beats a pipe; without either, the chain lands on its default.
+    #[test]
+    fn amend_takes_the_message_then_stdin() {
+        let amend_matches = |args: &[&str]| {
+            clap::Command::new("append")
+                .arg(clap::Arg::new("index"))
+                .arg(clap::Arg::new("message").short('m').long("message"))
+                .try_get_matches_from(std::iter::once("append").chain(args.iter().copied()))
+                .expect("test args parse")
+        };
+        let amend_chain_with = |stdin: MockStdin| {
+            InputChain::new()
+                .try_source(MessageSource)
+                .try_source(PipedSource::with_reader(stdin))
+                .default(RequestContent::Editor)
+        };
+
+        let (value, source) = resolve_for_test(
+            amend_chain_with(MockStdin::piped("PIPED")),
+            &amend_matches(&["1", "-m", "one\\ntwo"]),
+        );
+        assert_eq!(value, RequestContent::Direct("one\ntwo".into()));
+        assert_eq!(source, InputSourceKind::Arg);
+
+        let (value, _) = resolve_for_test(
+            amend_chain_with(MockStdin::piped("PIPED")),
+            &amend_matches(&["1"]),
+        );
+        assert_eq!(value, RequestContent::Piped("PIPED".into()));
+
+        let (value, _) = resolve_for_test(
+            amend_chain_with(MockStdin::terminal()),
+            &amend_matches(&["1"]),
+        );
+        assert_eq!(value, RequestContent::Editor);
+    }
 }
@@ -0,0 +1,44 @@
+//! Ctrl-C during a long operation.
+//!
+//! Killed outright, padz could stop between two writes of a bulk import or
+//! migrate. Instead, the first Ctrl-C only cancels the [`Cancellation`] every
+//! API operation checks (see `padzapp::cancel`): the operation stops at the
+//! next pad, its transaction drops what it had written, and padz exits with
+//! status 130. A second Ctrl-C exits at once, for an operation stuck on
+//! something it cannot check in between (a hung `aws` call).
+
+use once_cell::sync::Lazy;
+use padzapp::cancel::Cancellation;
+use std::sync::atomic::AtomicBool;
+use std::sync::Arc;
+
+/// Exit status of a command stopped by Ctrl-C, as shells report it.
+pub const EXIT_INTERRUPTED: i32 = 130;
+
+static FLAG: Lazy<Arc<AtomicBool>> = Lazy::new(|| Arc::new(AtomicBool::new(false)));
+
+/// Route Ctrl-C to [`cancellation`]. Called once, by `run()`; without it (as
+/// in tests) the token is never cancelled.
+pub fn install() {
+    use signal_hook::consts::SIGINT;
+    use signal_hook::flag;
+
+    // Registered first, so it sees the flag as it was before this SIGINT: set
+    // means this is the second Ctrl-C.
+    let installed =
+        flag::register_conditional_shutdown(SIGINT, EXIT_INTERRUPTED, Arc::clone(&FLAG))
+            .and_then(|_| flag::register(SIGINT, Arc::clone(&FLAG)));
+    if let Err(e) = installed {
+        eprintln!("Warning: Ctrl-C will stop padz abruptly: {}", e);
+    }
+}
+
+/// The token Ctrl-C cancels.
+pub fn cancellation() -> Cancellation {
+    Cancellation::from_flag(Arc::clone(&FLAG))
+}
+
+/// Whether Ctrl-C was pressed during this run.
+pub fn interrupted() -> bool {
+    cancellation().is_cancelled()
+}
@@ -0,0 +1,154 @@
+//! Linting a pad after the editor saves it.
+//!
+//! With `lint_command` set in config (say `markdownlint` or `vale`), every
+//! interactive `create` and `open` runs the command on the pad's file once the
+//! editor closes. The pad is already saved by then — a linter advises, it never
+//! blocks a save — so findings are printed to stderr and, on a terminal, the
+//! user is offered the editor again to fix them. Declining keeps the pad as it
+//! is.
+//!
+//! The command runs through `sh` with the file path appended as its last
+//! argument, so `lint_command = "vale --minAlertLevel=error"` works as typed.
+//! A zero exit means clean; anything else is a finding, reported with whatever
+//! the linter printed.
+
+use super::capabilities::Capabilities;
+use super::subprocess::{self, Limit};
+use padzapp::error::{PadzError, Result};
+use std::io::{BufRead, Write};
+use std::path::Path;
+use std::process::Command;
+
+/// What a linter run found.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum LintResult {
+    Clean,
+    /// The linter exited non-zero; its combined stdout and stderr.
+    Findings(String),
+}
+
+/// Runs `command` on `path`.
+///
+/// Errors only when the shell cannot be started; a linter that is missing from
+/// `PATH` makes `sh` exit non-zero, which surfaces as a finding naming it.
+pub fn run(command: &str, path: &Path) -> Result<LintResult> {
+    let output = subprocess::output(
+        Command::new("sh")
+            .arg("-c")
+            .arg(format!("{command} \"$1\""))
+            .arg("padz-lint")
+            .arg(path),
+        Limit::Command,
+        &format!("linter '{}'", command),
+    )?;
+    if output.status.success() {
+        return Ok(LintResult::Clean);
+    }
+    let mut findings = String::from_utf8_lossy(&output.stdout).into_owned();
+    findings.push_str(&String::from_utf8_lossy(&output.stderr));
+    Ok(LintResult::Findings(findings.trim_end().to_string()))
+}
+
+/// Opens the editor, lints, and re-opens while the user asks to fix findings.
+///
+/// `edit` opens the editor and `lint` checks the result; `reopen` sees the
+/// findings and decides whether to go round again. Split into closures so the
+/// loop can be tested without a terminal or a child process.
+pub fn edit_until_clean(
+    mut edit: impl FnMut() -> Result<()>,
+    mut lint: impl FnMut() -> Result<LintResult>,
+    mut reopen: impl FnMut(&str) -> bool,
+) -> Result<()> {
+    loop {
+        edit()?;
+        match lint()? {
+            LintResult::Clean => return Ok(()),
+            LintResult::Findings(findings) => {
+                if !reopen(&findings) {
+                    return Ok(());
+                }
+            }
+        }
+    }
+}
+
+/// Prints `findings` to stderr and, when the user can be asked, asks whether
+/// to re-open the editor. Defaults to yes; anything but an explicit no re-opens.
+pub fn ask_to_reopen(findings: &str, capabilities: &Capabilities) -> bool {
+    eprintln!("Linter findings:");
+    if !findings.is_empty() {
+        eprintln!("{findings}");
+    }
+    if !capabilities.can_prompt() {
+        return false;
+    }
+    eprint!("Re-open the editor to fix them? [Y/n] ");
+    let _ = std::io::stderr().flush();
+    let mut answer = String::new();
+    if std::io::stdin().lock().read_line(&mut answer).is_err() {
+        return false;
+    }
+    !matches!(answer.trim().to_lowercase().as_str(), "n" | "no")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn exit_status_decides_clean_or_findings() {
+        let temp = tempfile::tempdir().unwrap();
+        let pad = temp.path().join("pad.md");
+        std::fs::write(&pad, "# Title\n\nTODO fix\n").unwrap();
+
+        assert_eq!(run("grep -q Title", &pad).unwrap(), LintResult::Clean);
+        // The linter's own output is the finding.
+        assert_eq!(
+            run("! grep -n TODO", &pad).unwrap(),
+            LintResult::Findings("3:TODO fix".into())
+        );
+    }
+
+    #[test]
+    fn the_editor_reopens_until_clean_or_declined() {
+        let mut edits = 0;
+        let mut lints = vec![
+            LintResult::Findings("one".into()),
+            LintResult::Findings("two".into()),
+            LintResult::Clean,
+        ]
+        .into_iter();
+        edit_until_clean(
+            || {
+                edits += 1;
+                Ok(())
+            },
+            || Ok(lints.next().unwrap()),
+            |_| true,
+        )
+        .unwrap();
+        assert_eq!(edits, 3);
+
+        let mut edits = 0;
+        edit_until_clean(
+            || {
+                edits += 1;
+                Ok(())
+            },
+            || Ok(LintResult::Findings("still wrong".into())),
+            |_| false,
+        )
+        .unwrap();
+        assert_eq!(edits, 1);
+    }
+
+    #[test]
+    fn an_editor_failure_stops_the_loop() {
+        let result = edit_until_clean(
+            || Err(PadzError::Api("editor crashed".into())),
+            || panic!("nothing to lint after a failed edit"),
+            |_| true,
+        );
+        assert!(result.is_err());
+    }
+}
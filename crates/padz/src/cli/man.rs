@@ -0,0 +1,301 @@
+//! Man pages: `padz.1`, and one page per subcommand (`padz-list.1`,
+//! `padz-export.1`, `padz-tag-add.1`, ...).
+//!
+//! Pages are rendered from the clap command tree, the same one `--help` and
+//! the completions come from, so a flag cannot be documented in one and
+//! missing from the other. Hidden commands and flags stay out of the pages.
+//! A command's EXAMPLES section is its `after_long_help`, when it has one;
+//! `padz docs install-man` fills those in from [`super::examples`].
+//!
+//! `padz docs install-man` writes the pages into the user's manpath (see
+//! `commands::handle_docs`).
+
+use clap::{Arg, ArgAction, Command};
+
+/// The version stamped in every page footer.
+const VERSION: &str = env!("CARGO_PKG_VERSION");
+
+/// One rendered page: its file name (`padz-list.1`) and its roff source.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ManPage {
+    pub file_name: String,
+    pub roff: String,
+}
+
+/// The page of `cmd` and of each of its visible subcommands, parents first.
+pub fn pages(mut cmd: Command) -> Vec<ManPage> {
+    // Building propagates global flags (`--global`, `--dry-run`, ...) down to
+    // the subcommands, so each page lists every flag its command accepts.
+    cmd.build();
+    let mut pages = Vec::new();
+    let mut path = vec![cmd.get_name().to_string()];
+    collect(&cmd, &mut path, &mut pages);
+    pages
+}
+
+fn collect(cmd: &Command, path: &mut Vec<String>, pages: &mut Vec<ManPage>) {
+    pages.push(ManPage {
+        file_name: format!("{}.1", path.join("-")),
+        roff: render(cmd, path),
+    });
+    for sub in visible_subcommands(cmd) {
+        path.push(sub.get_name().to_string());
+        collect(sub, path, pages);
+        path.pop();
+    }
+}
+
+fn visible_subcommands(cmd: &Command) -> impl Iterator<Item = &Command> {
+    cmd.get_subcommands().filter(|sub| !sub.is_hide_set())
+}
+
+/// The flags and arguments a page documents: visible ones, without the
+/// `--help`/`--version` clap adds to every command.
+fn documented_args(cmd: &Command) -> impl Iterator<Item = &Arg> {
+    cmd.get_arguments().filter(|arg| {
+        !arg.is_hide_set()
+            && !matches!(
+                arg.get_action(),
+                ArgAction::Help | ArgAction::HelpShort | ArgAction::HelpLong | ArgAction::Version
+            )
+    })
+}
+
+fn render(cmd: &Command, path: &[String]) -> String {
+    let page = path.join("-");
+    let mut out = String::new();
+
+    out.push_str(&format!(
+        ".TH \"{}\" 1 \"\" \"padz {}\" \"padz manual\"\n",
+        escape(&page.to_uppercase()),
+        VERSION
+    ));
+
+    out.push_str(".SH NAME\n");
+    match cmd.get_about() {
+        Some(about) => out.push_str(&format!(
+            "{} \\- {}\n",
+            escape(&page),
+            escape(&about.to_string())
+        )),
+        None => out.push_str(&format!("{}\n", escape(&page))),
+    }
+
+    out.push_str(".SH SYNOPSIS\n");
+    out.push_str(&synopsis(cmd, path));
+
+    if let Some(description) = cmd.get_long_about().or(cmd.get_about()) {
+        out.push_str(".SH DESCRIPTION\n");
+        out.push_str(&paragraphs(&description.to_string()));
+    }
+
+    let args: Vec<&Arg> = documented_args(cmd).collect();
+    if !args.is_empty() {
+        out.push_str(".SH OPTIONS\n");
+        for arg in args {
+            out.push_str(".TP\n");
+            out.push_str(&arg_header(arg));
+            out.push('\n');
+            out.push_str(&arg_description(arg));
+        }
+    }
+
+    let subcommands: Vec<&Command> = visible_subcommands(cmd).collect();
+    if !subcommands.is_empty() {
+        out.push_str(".SH COMMANDS\n");
+        for sub in subcommands {
+            out.push_str(".TP\n");
+            out.push_str(&format!(
+                "\\fB{}\\-{}\\fR(1)\n",
+                escape(&page),
+                escape(sub.get_name())
+            ));
+            if let Some(about) = sub.get_about() {
+                out.push_str(&line(&about.to_string()));
+            }
+        }
+    }
+
+    if let Some(examples) = cmd.get_after_long_help() {
+        out.push_str(".SH EXAMPLES\n.nf\n");
+        for example in examples.to_string().lines() {
+            out.push_str(&line(example));
+        }
+        out.push_str(".fi\n");
+    }
+
+    if path.len() > 1 {
+        out.push_str(".SH SEE ALSO\n");
+        out.push_str(&format!(
+            "\\fB{}\\fR(1)\n",
+            escape(&path[..path.len() - 1].join("-"))
+        ));
+    }
+
+    out
+}
+
+/// `padz list [OPTIONS] [IDS]...`: the command path, then its positionals.
+fn synopsis(cmd: &Command, path: &[String]) -> String {
+    let mut parts = vec![format!("\\fB{}\\fR", escape(&path.join(" ")))];
+    if documented_args(cmd).any(|arg| !arg.is_positional()) {
+        parts.push("[OPTIONS]".to_string());
+    }
+    for arg in documented_args(cmd).filter(|arg| arg.is_positional()) {
+        let name = escape(&value_name(arg));
+        let mut part = if arg.is_required_set() {
+            format!("<{}>", name)
+        } else {
+            format!("[{}]", name)
+        };
+        if matches!(arg.get_action(), ArgAction::Append) {
+            part.push_str("...");
+        }
+        parts.push(part);
+    }
+    if cmd.has_subcommands() {
+        parts.push("<COMMAND>".to_string());
+    }
+    format!("{}\n", parts.join(" "))
+}
+
+/// `\fB\-g\fR, \fB\-\-global\fR`, or `<IDS>` for a positional, with the value
+/// a flag takes.
+fn arg_header(arg: &Arg) -> String {
+    if arg.is_positional() {
+        return format!("<{}>", escape(&value_name(arg)));
+    }
+    let mut names = Vec::new();
+    if let Some(short) = arg.get_short() {
+        names.push(format!("\\fB\\-{}\\fR", escape(&short.to_string())));
+    }
+    if let Some(long) = arg.get_long() {
+        names.push(format!("\\fB\\-\\-{}\\fR", escape(long)));
+    }
+    let mut header = names.join(", ");
+    if arg.get_action().takes_values() {
+        header.push_str(&format!(" <{}>", escape(&value_name(arg))));
+    }
+    header
+}
+
+fn arg_description(arg: &Arg) -> String {
+    let mut text = arg
+        .get_long_help()
+        .or(arg.get_help())
+        .map(|help| help.to_string())
+        .unwrap_or_default();
+    let values: Vec<String> = arg
+        .get_possible_values()
+        .iter()
+        .filter(|_| arg.get_action().takes_values())
+        .filter(|value| !value.is_hide_set())
+        .map(|value| value.get_name().to_string())
+        .collect();
+    if !values.is_empty() {
+        if !text.is_empty() {
+            text.push(' ');
+        }
+        text.push_str(&format!("[possible values: {}]", values.join(", ")));
+    }
+    paragraphs(&text)
+}
+
+fn value_name(arg: &Arg) -> String {
+    arg.get_value_names()
+        .and_then(|names| names.first())
+        .map(|name| name.to_string())
+        .unwrap_or_else(|| arg.get_id().as_str().to_uppercase())
+}
+
+/// Text with blank-line-separated paragraphs, as roff.
+fn paragraphs(text: &str) -> String {
+    let mut out = String::new();
+    for (i, paragraph) in text.trim().split("\n\n").enumerate() {
+        if i > 0 {
+            out.push_str(".PP\n");
+        }
+        for text_line in paragraph.lines() {
+            out.push_str(&line(text_line));
+        }
+    }
+    out
+}
+
+/// One line of running text. A line starting with `.` or `'` would be read
+/// as a request, so it is guarded with `\&`.
+fn line(text: &str) -> String {
+    let escaped = escape(text);
+    if escaped.starts_with('.') || escaped.starts_with('\'') {
+        format!("\\&{}\n", escaped)
+    } else {
+        format!("{}\n", escaped)
+    }
+}
+
+/// Escape roff's special characters: backslashes, and hyphens, which roff
+/// would otherwise typeset as (uncopyable) dashes.
+fn escape(text: &str) -> String {
+    text.replace('\\', "\\e").replace('-', "\\-")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::cli::setup::build_command;
+
+    fn page<'a>(pages: &'a [ManPage], file_name: &str) -> &'a ManPage {
+        pages
+            .iter()
+            .find(|page| page.file_name == file_name)
+            .unwrap_or_else(|| panic!("no page {}", file_name))
+    }
+
+    #[test]
+    fn one_page_per_subcommand() {
+        let pages = pages(build_command());
+        assert_eq!(pages[0].file_name, "padz.1");
+        page(&pages, "padz-list.1");
+        page(&pages, "padz-export.1");
+        assert!(pages.iter().all(|page| page.file_name.ends_with(".1")));
+    }
+
+    #[test]
+    fn pages_document_global_flags_but_not_hidden_ones() {
+        let pages = pages(build_command());
+        let list = &page(&pages, "padz-list.1").roff;
+        assert!(list.starts_with(".TH \"PADZ\\-LIST\" 1"));
+        assert!(list.contains("\\fBpadz list\\fR [OPTIONS]"));
+        assert!(list.contains("\\fB\\-g\\fR, \\fB\\-\\-global\\fR"));
+        assert!(list.contains("\\fBpadz\\fR(1)"));
+        assert!(!list.contains("test\\-mode"));
+        assert!(!list.contains("\\-\\-help"));
+    }
+
+    #[test]
+    fn parent_pages_list_their_subcommands() {
+        let pages = pages(build_command());
+        let root = &page(&pages, "padz.1").roff;
+        assert!(root.contains(".SH COMMANDS"));
+        assert!(root.contains("\\fBpadz\\-list\\fR(1)"));
+        assert!(!root.contains(".SH SEE ALSO"));
+    }
+
+    #[test]
+    fn examples_come_from_after_long_help() {
+        let cmd = Command::new("padz").subcommand(
+            Command::new("list")
+                .about("List pads")
+                .after_long_help("padz list --deleted\n.hidden"),
+        );
+        let pages = pages(cmd);
+        let list = &page(&pages, "padz-list.1").roff;
+        assert!(list.contains(".SH EXAMPLES\n.nf\npadz list \\-\\-deleted\n\\&.hidden\n.fi\n"));
+    }
+
+    #[test]
+    fn escape_guards_backslashes_and_hyphens() {
+        assert_eq!(escape("a-b\\c"), "a\\-b\\ec");
+        assert_eq!(line("'quoted"), "\\&'quoted\n");
+    }
+}
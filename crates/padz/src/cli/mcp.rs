@@ -0,0 +1,386 @@
+//! `padz mcp`: padz as a Model Context Protocol server.
+//!
+//! An AI assistant starts `padz mcp` and talks to it over stdin and stdout,
+//! one JSON-RPC 2.0 message per line. The server offers four tools, each a
+//! thin call into the same [`PadzApi`](padzapp::api::PadzApi) the commands
+//! use, on the scope the invocation resolved (`padz -g mcp` serves the global
+//! store):
+//!
+//! - `list_pads`: the pads of a bucket, optionally only those with given tags;
+//! - `search_pads`: the pads whose title or body matches a query;
+//! - `read_pad`: one pad's full text, selected as on the command line
+//!   (`3`, `p1`, a UUID, a title);
+//! - `create_pad`: a new pad, optionally nested under another.
+//!
+//! Pads are described as JSON ([`PadSummary`]); a failed call is reported
+//! back to the assistant as a tool error, never as a protocol one. Stdout
+//! carries the protocol alone, so warnings stay on stderr, and `--dry-run`
+//! holds for the whole session.
+
+use super::handlers::AppState;
+use padzapp::api::{PadFilter, PadStatusFilter};
+use padzapp::commands::NestingMode;
+use padzapp::error::{PadzError, Result};
+use padzapp::index::{DisplayIndex, DisplayPad};
+use serde::Serialize;
+use serde_json::{json, Value};
+use std::io::{BufRead, Write};
+
+/// The protocol revision spoken.
+const PROTOCOL_VERSION: &str = "2024-11-05";
+
+/// JSON-RPC's error codes for an unparseable message, an unknown method and
+/// bad parameters.
+const PARSE_ERROR: i64 = -32700;
+const METHOD_NOT_FOUND: i64 = -32601;
+const INVALID_PARAMS: i64 = -32602;
+
+/// A pad as the tools describe it.
+#[derive(Debug, Clone, PartialEq, Serialize)]
+pub struct PadSummary {
+    /// The index to select it by, e.g. `3` or `2.1`.
+    pub index: String,
+    pub id: String,
+    pub title: String,
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub tags: Vec<String>,
+    pub pinned: bool,
+    pub created_at: String,
+    pub updated_at: String,
+}
+
+/// Answers each message on `input` on `output` until `input` closes.
+pub fn serve(state: &AppState, input: impl BufRead, mut output: impl Write) -> Result<()> {
+    for line in input.lines() {
+        let line = line?;
+        if line.trim().is_empty() {
+            continue;
+        }
+        let reply = match serde_json::from_str::<Value>(&line) {
+            Ok(message) => handle(state, &message),
+            Err(e) => Some(error(Value::Null, PARSE_ERROR, &e.to_string())),
+        };
+        if let Some(reply) = reply {
+            writeln!(output, "{}", reply)?;
+            output.flush()?;
+        }
+    }
+    Ok(())
+}
+
+/// The reply to one message; `None` for a notification, which gets none.
+pub fn handle(state: &AppState, message: &Value) -> Option<Value> {
+    let id = message.get("id")?.clone();
+    let method = message.get("method").and_then(Value::as_str).unwrap_or("");
+    let params = message.get("params").cloned().unwrap_or(Value::Null);
+    let reply = match method {
+        "initialize" => result(
+            id,
+            json!({
+                "protocolVersion": PROTOCOL_VERSION,
+                "capabilities": { "tools": {} },
+                "serverInfo": { "name": "padz", "version": env!("CARGO_PKG_VERSION") },
+            }),
+        ),
+        "ping" => result(id, json!({})),
+        "tools/list" => result(id, json!({ "tools": tools() })),
+        "tools/call" => match params.get("name").and_then(Value::as_str) {
+            Some(name) => {
+                let arguments = params.get("arguments").cloned().unwrap_or(json!({}));
+                result(id, call(state, name, &arguments))
+            }
+            None => error(id, INVALID_PARAMS, "tools/call needs a tool name"),
+        },
+        _ => error(id, METHOD_NOT_FOUND, &format!("Unknown method: {}", method)),
+    };
+    Some(reply)
+}
+
+fn result(id: Value, result: Value) -> Value {
+    json!({ "jsonrpc": "2.0", "id": id, "result": result })
+}
+
+fn error(id: Value, code: i64, message: &str) -> Value {
+    json!({ "jsonrpc": "2.0", "id": id, "error": { "code": code, "message": message } })
+}
+
+/// The tools, with the JSON schemas of their arguments.
+fn tools() -> Value {
+    let status = json!({
+        "type": "string",
+        "enum": ["active", "archived", "deleted", "all"],
+        "description": "Which pads: active (the default), archived, deleted or all",
+    });
+    json!([
+        {
+            "name": "list_pads",
+            "description": "List the pads in the store, newest first, with the index to select each by",
+            "inputSchema": {
+                "type": "object",
+                "properties": {
+                    "status": status,
+                    "tags": {
+                        "type": "array",
+                        "items": { "type": "string" },
+                        "description": "Only pads with all of these tags",
+                    },
+                },
+            },
+        },
+        {
+            "name": "search_pads",
+            "description": "Find the pads whose title or body matches a query",
+            "inputSchema": {
+                "type": "object",
+                "properties": {
+                    "query": { "type": "string" },
+                    "status": status,
+                },
+                "required": ["query"],
+            },
+        },
+        {
+            "name": "read_pad",
+            "description": "Read a pad's full text: its title on the first line, then its body",
+            "inputSchema": {
+                "type": "object",
+                "properties": {
+                    "pad": {
+                        "type": "string",
+                        "description": "An index from list_pads (3, p1, 2.1), a UUID, or a title",
+                    },
+                },
+                "required": ["pad"],
+            },
+        },
+        {
+            "name": "create_pad",
+            "description": "Create a pad",
+            "inputSchema": {
+                "type": "object",
+                "properties": {
+                    "title": { "type": "string" },
+                    "content": { "type": "string", "description": "The body, below the title" },
+                    "parent": {
+                        "type": "string",
+                        "description": "Index of a pad to nest the new one under",
+                    },
+                },
+                "required": ["title"],
+            },
+        },
+    ])
+}
+
+/// Runs tool `name`, as a tool result: its text, or the error it failed with.
+fn call(state: &AppState, name: &str, arguments: &Value) -> Value {
+    let text = match name {
+        "list_pads" => list(state, arguments, None),
+        "search_pads" => {
+            string(arguments, "query").and_then(|query| list(state, arguments, Some(query)))
+        }
+        "read_pad" => read(state, arguments),
+        "create_pad" => create(state, arguments),
+        _ => Err(PadzError::Api(format!("Unknown tool: {}", name))),
+    };
+    match text {
+        Ok(text) => json!({ "content": [{ "type": "text", "text": text }] }),
+        Err(e) => {
+            json!({ "content": [{ "type": "text", "text": e.to_string() }], "isError": true })
+        }
+    }
+}
+
+fn list(state: &AppState, arguments: &Value, query: Option<String>) -> Result<String> {
+    let status = match arguments.get("status").and_then(Value::as_str) {
+        None | Some("active") => PadStatusFilter::Active,
+        Some("archived") => PadStatusFilter::Archived,
+        Some("deleted") => PadStatusFilter::Deleted,
+        Some("all") => PadStatusFilter::All,
+        Some(other) => return Err(PadzError::Api(format!("Unknown status: {}", other))),
+    };
+    let tags: Option<Vec<String>> = match arguments.get("tags") {
+        Some(tags) => Some(
+            serde_json::from_value(tags.clone())
+                .map_err(|_| PadzError::Api("tags must be a list of strings".to_string()))?,
+        ),
+        None => None,
+    };
+    let filter = PadFilter {
+        status,
+        search_term: query,
+        todo_status: None,
+        tags,
+    };
+    let listed = state.with_api(|api| api.get_pads(state.scope, filter, &[] as &[String]))?;
+    let mut pads = Vec::new();
+    for pad in &listed.listed_pads {
+        summarize(pad, "", &mut pads);
+    }
+    Ok(serde_json::to_string_pretty(&pads)?)
+}
+
+/// Adds `pad` and the pads nested under it to `out`. A pinned pad is listed
+/// once, at its regular index, not again at its pinned one.
+fn summarize(pad: &DisplayPad, parent: &str, out: &mut Vec<PadSummary>) {
+    if matches!(pad.index, DisplayIndex::Pinned(_)) && parent.is_empty() {
+        return;
+    }
+    let index = if parent.is_empty() {
+        pad.index.to_string()
+    } else {
+        format!("{}.{}", parent, pad.index)
+    };
+    let metadata = &pad.pad.metadata;
+    out.push(PadSummary {
+        index: index.clone(),
+        id: metadata.id.to_string(),
+        title: metadata.title.clone(),
+        tags: metadata.tags.clone(),
+        pinned: metadata.is_pinned,
+        created_at: metadata.created_at.to_rfc3339(),
+        updated_at: metadata.updated_at.to_rfc3339(),
+    });
+    for child in &pad.children {
+        summarize(child, &index, out);
+    }
+}
+
+fn read(state: &AppState, arguments: &Value) -> Result<String> {
+    let selector = string(arguments, "pad")?;
+    let viewed = state
+        .with_api(|api| api.view_pads(state.scope, &[selector.as_str()], NestingMode::Flat))?;
+    viewed
+        .listed_pads
+        .first()
+        .map(|pad| pad.pad.content.clone())
+        .ok_or_else(|| PadzError::Api(format!("No pad matches {}", selector)))
+}
+
+fn create(state: &AppState, arguments: &Value) -> Result<String> {
+    let title = string(arguments, "title")?;
+    let content = arguments
+        .get("content")
+        .and_then(Value::as_str)
+        .unwrap_or("")
+        .to_string();
+    let parent = arguments.get("parent").and_then(Value::as_str);
+    let created = state.with_api(|api| api.create_pad(state.scope, title, content, parent))?;
+    let mut pads = Vec::new();
+    if let Some(pad) = created.affected_pads.first() {
+        summarize(pad, "", &mut pads);
+    }
+    Ok(serde_json::to_string_pretty(&pads)?)
+}
+
+fn string(arguments: &Value, key: &str) -> Result<String> {
+    arguments
+        .get(key)
+        .and_then(Value::as_str)
+        .map(str::to_string)
+        .ok_or_else(|| PadzError::Api(format!("Missing argument: {}", key)))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use padzapp::config::PadzMode;
+    use padzapp::init::{initialize, PadzEnv};
+    use tempfile::TempDir;
+
+    fn state(temp: &TempDir) -> AppState {
+        let root = temp.path().to_path_buf();
+        let env = PadzEnv {
+            global_data_dir: root.join("global-data"),
+            home_dir: None,
+        };
+        let padz_ctx = initialize(&env, &root, false, Some(root.clone()), true).unwrap();
+        AppState::new(
+            padz_ctx.api,
+            padz_ctx.scope,
+            Vec::new(),
+            PadzMode::default(),
+            root.join(".padz"),
+        )
+    }
+
+    fn call_tool(state: &AppState, name: &str, arguments: Value) -> Value {
+        let request = json!({
+            "jsonrpc": "2.0",
+            "id": 1,
+            "method": "tools/call",
+            "params": { "name": name, "arguments": arguments },
+        });
+        handle(state, &request).unwrap()["result"].clone()
+    }
+
+    fn text(result: &Value) -> &str {
+        result["content"][0]["text"].as_str().unwrap()
+    }
+
+    #[test]
+    fn a_session_creates_lists_searches_and_reads_pads() {
+        let temp = TempDir::new().unwrap();
+        let state = state(&temp);
+        let input = concat!(
+            r#"{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}"#,
+            "\n",
+            r#"{"jsonrpc":"2.0","method":"notifications/initialized"}"#,
+            "\n",
+            r#"{"jsonrpc":"2.0","id":2,"method":"tools/list"}"#,
+            "\n",
+        );
+        let mut output = Vec::new();
+        serve(&state, input.as_bytes(), &mut output).unwrap();
+        let replies: Vec<Value> = String::from_utf8(output)
+            .unwrap()
+            .lines()
+            .map(|line| serde_json::from_str(line).unwrap())
+            .collect();
+        // The notification gets no reply.
+        assert_eq!(replies.len(), 2);
+        assert_eq!(replies[0]["result"]["serverInfo"]["name"], "padz");
+        assert_eq!(replies[1]["result"]["tools"].as_array().unwrap().len(), 4);
+
+        let created = call_tool(
+            &state,
+            "create_pad",
+            json!({ "title": "Groceries", "content": "Milk" }),
+        );
+        assert!(text(&created).contains("Groceries"));
+        call_tool(&state, "create_pad", json!({ "title": "Taxes" }));
+
+        let listed: Vec<Value> =
+            serde_json::from_str(text(&call_tool(&state, "list_pads", json!({})))).unwrap();
+        assert_eq!(listed.len(), 2);
+        assert_eq!(listed[0]["title"], "Taxes");
+
+        let found: Vec<Value> = serde_json::from_str(text(&call_tool(
+            &state,
+            "search_pads",
+            json!({ "query": "milk" }),
+        )))
+        .unwrap();
+        assert_eq!(found.len(), 1);
+        assert_eq!(found[0]["title"], "Groceries");
+
+        let read = call_tool(&state, "read_pad", json!({ "pad": "2" }));
+        assert!(text(&read).contains("Milk"));
+    }
+
+    #[test]
+    fn a_failed_call_is_a_tool_error_and_an_unknown_method_a_protocol_one() {
+        let temp = TempDir::new().unwrap();
+        let state = state(&temp);
+        let failed = call_tool(&state, "read_pad", json!({}));
+        assert_eq!(failed["isError"], true);
+        assert!(text(&failed).contains("Missing argument: pad"));
+
+        let reply = handle(
+            &state,
+            &json!({ "jsonrpc": "2.0", "id": 3, "method": "resources/list" }),
+        )
+        .unwrap();
+        assert_eq!(reply["error"]["code"], METHOD_NOT_FOUND);
+    }
+}
@@ -42,11 +42,12 @@
 //! After saving from editor, pad content is automatically copied to clipboard.
 //! Use `--no-editor` flag to skip opening the editor.
 //!
-//! **The clipboard is not an input source.** Padz writes pad text *to* the
-//! clipboard after a pad is saved or viewed; it never reads the clipboard to
-//! pre-fill one. Earlier revisions of this doc described a "clipboard fallback"
-//! that pre-filled the editor — that path was never wired, and the claim is
-//! recorded here only to keep it from being reintroduced as new behavior.
+//! **The clipboard is not a fallback input source.** Padz writes pad text *to*
+//! the clipboard after a pad is saved or viewed, and reads it only when a flag
+//! asks: `create --from-clipboard` and `copy --append`. Earlier revisions of
+//! this doc described a "clipboard fallback" that pre-filled the editor — that
+//! path was never wired, and the claim is recorded here only to keep it from
+//! being reintroduced as new behavior.
 //!
 //! ### View Copies to Clipboard
 //!
@@ -69,17 +70,77 @@
 //! - `views`: The typed, mode-independent view each handler returns
 //! - `render`: Render-time view derivation for standout's templates
 //! - `setup`: Argument parsing via clap, help text, and naked-invocation resolution
+//! - `help_context`: The scope, its pad counts and the naked-invocation behavior atop `--help`
+//! - `signing`: Detached gpg signatures for exports, and their verification
+//! - `anchors`: Where a pad's linked code locations live on this machine
+//! - `git_context`: The git state recorded on new pads when `capture_context` is on
+//! - `identity`: Who is running padz, from `identity.*` config, git, or the OS user
+//! - `lint`: The `lint_command` run on a pad after the editor saves it
+//! - `events`: What padz changed, told to the `event_command` and `event_socket`
+//! - `spelling`: Where the spell-check dictionary comes from on this machine
+//! - `directives`: The values of `{{today}}` and `{{shell "..."}}` in viewed pads
+//! - `capture`: Running the command of `create --from` for the new pad's text
+//! - `duplicate`: What `create` does with a title an active pad already has
+//! - `fill`: Values for a pad's `{{placeholder:name}}` fill-ins (`padz fill`)
+//! - `remote`: Fetching a `--remote` store over sftp into its local cache
+//! - `object_store`: Syncing the global store with an S3 bucket (`global_store`)
+//! - `queue`: Retrying operations that failed for lack of network
+//! - `progress`: The progress bar (or log lines) of long operations
+//! - `interrupt`: Ctrl-C cancels the running operation instead of killing padz
+//! - `exit`: The exit status of `search --quiet` and `--count` when nothing matched
+//! - `subprocess`: Running external programs within their configured time limits
+//! - `capabilities`: Whether there is a terminal to prompt on and open the editor in
+//! - `self_update`: `padz self-update` and the opt-in daily release check
+//! - `examples`: The examples registry behind `--examples`, man pages and completion
+//! - `man`: Man pages for padz and each subcommand (`padz docs install-man`)
+//! - `onboarding`: Setting up the global store on the first run, and the welcome note
+//! - `tour`: `padz tour`, the guided walkthrough run on a scratch store
+//! - `prompt`: `padz prompt-segment`, the cached pad-count badge for shell prompts
 
+pub mod anchors;
+pub mod capabilities;
+pub mod capture;
 pub mod clipboard;
 pub mod commands;
 mod complete;
+pub mod deprecation;
+pub mod directives;
+pub mod duplicate;
 pub mod editor;
 pub mod env;
 pub mod errors;
+pub mod events;
+pub mod examples;
+pub mod exit;
+pub mod explain;
+pub mod fill;
+pub mod git_context;
 pub mod handlers;
+pub mod help_context;
+pub mod identity;
 pub mod input;
+pub mod interrupt;
+pub mod lint;
+pub mod man;
+pub mod mcp;
+pub mod object_store;
+pub mod onboarding;
+pub mod printer;
+pub mod progress;
+pub mod prompt;
+pub mod publish;
+pub mod queue;
+pub mod remote;
 pub mod render;
+pub mod self_update;
 pub mod setup;
+pub mod signing;
+pub mod speak;
+pub mod spelling;
+pub mod subprocess;
+pub mod tour;
+pub mod uri;
 pub mod views;
+pub mod watch;
 
 pub use commands::run;
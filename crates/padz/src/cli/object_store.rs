@@ -0,0 +1,269 @@
+//! Keeping the global store in an S3-compatible bucket (`global_store`).
+//!
+//! The sync is experimental: it runs only with `experimental.sync` set (see
+//! `padzapp::config::ExperimentalFlags`).
+//!
+//! `padzapp::store::object` decides what to sync and settles conflicts; this
+//! module reaches the bucket, through the `aws` CLI (`aws s3api`), so the
+//! user's usual AWS profile, credentials and region apply unchanged.
+//! `global_store_endpoint` points it at another S3-compatible service.
+//!
+//! A command bound to the global scope pulls before it runs and pushes after.
+//! Syncing is best-effort: when the bucket cannot be reached the command runs
+//! against the local copy with a warning. A failed upload is queued
+//! (`padzapp::queue`) and retried after later commands, project ones
+//! included, or with `padz flush-queue`.
+
+use padzapp::cancel::Cancellation;
+use padzapp::error::{PadzError, Result};
+use padzapp::queue::{queue_path, Operation, OperationQueue};
+use padzapp::store::object::{self, Conflict, Object, ObjectClient, ObjectLocation, SyncReport};
+use std::cell::Cell;
+use std::fs;
+use std::path::PathBuf;
+use std::process::{Command, Output};
+
+use super::progress::TerminalProgress;
+use super::subprocess::{self, Limit};
+
+/// The bucket a global store syncs with, and the store itself.
+pub struct GlobalStoreSync {
+    url: String,
+    root: PathBuf,
+    client: AwsCli,
+    progress: TerminalProgress,
+    cancel: Cancellation,
+}
+
+impl GlobalStoreSync {
+    /// Sync for the global store at `root`, kept at `url` (`s3://bucket/prefix`).
+    pub fn new(url: &str, endpoint: Option<String>, root: PathBuf) -> Result<Self> {
+        let location = ObjectLocation::parse(url)?;
+        Ok(Self {
+            url: url.to_string(),
+            client: AwsCli {
+                location,
+                endpoint,
+                scratch: root.join(".sync"),
+                temp_files: Cell::new(0),
+            },
+            root,
+            progress: TerminalProgress::new(),
+            cancel: super::interrupt::cancellation(),
+        })
+    }
+
+    /// Brings in what other machines wrote. Problems are warnings.
+    pub fn pull(&self) {
+        if let Err(e) = self.finish(object::pull(
+            &self.root,
+            &self.client,
+            &self.progress,
+            &self.cancel,
+        )) {
+            eprintln!(
+                "Warning: could not sync the global store from {}: {}",
+                self.url, e
+            );
+        }
+    }
+
+    /// Uploads what this command changed. A failed upload is a warning, and
+    /// is queued for a retry (see [`super::queue`]); one that goes through
+    /// also settles any upload queued earlier.
+    pub fn push(&self) {
+        let operation = Operation::GlobalStorePush {
+            url: self.url.clone(),
+            endpoint: self.client.endpoint.clone(),
+        };
+        let path = queue_path(&self.root);
+        let Ok(mut queue) = OperationQueue::load(&path) else {
+            return;
+        };
+        match self.try_push() {
+            Ok(()) => {
+                if !queue.complete(&operation) {
+                    return;
+                }
+            }
+            Err(e) => {
+                eprintln!(
+                    "Warning: could not sync the global store to {}: {} (queued; `padz flush-queue` retries it)",
+                    self.url, e
+                );
+                queue.record_failure(operation, &e);
+            }
+        }
+        if let Err(e) = queue.save(&path) {
+            eprintln!("Warning: could not update the operation queue: {}", e);
+        }
+    }
+
+    /// Uploads what changed, reporting conflicts as warnings and returning
+    /// any failure for the caller to handle.
+    pub fn try_push(&self) -> std::result::Result<(), String> {
+        self.finish(object::push(
+            &self.root,
+            &self.client,
+            &self.progress,
+            &self.cancel,
+        ))
+        .map_err(|e| e.to_string())
+    }
+
+    fn finish(&self, result: Result<SyncReport>) -> Result<()> {
+        let _ = fs::remove_dir_all(&self.client.scratch);
+        for conflict in result?.conflicts {
+            eprintln!("Warning: {}", describe(&conflict));
+        }
+        Ok(())
+    }
+}
+
+fn describe(conflict: &Conflict) -> String {
+    match conflict {
+        Conflict::Merged { key } => {
+            format!("{key} changed here and on another machine; merged both")
+        }
+        Conflict::KeptBoth { key, .. } => format!(
+            "{key} changed here and on another machine; the other version is now a separate pad"
+        ),
+        Conflict::KeptLocal { key } => {
+            format!("{key} changed here and on another machine; kept this machine's version")
+        }
+    }
+}
+
+/// [`ObjectClient`] over `aws s3api`.
+struct AwsCli {
+    location: ObjectLocation,
+    endpoint: Option<String>,
+    /// Where object bodies pass through on their way to and from the CLI.
+    scratch: PathBuf,
+    temp_files: Cell<usize>,
+}
+
+impl AwsCli {
+    fn s3api(&self, operation: &str) -> Command {
+        let mut command = Command::new("aws");
+        command
+            .arg("s3api")
+            .arg(operation)
+            .arg("--bucket")
+            .arg(&self.location.bucket)
+            .arg("--output")
+            .arg("json");
+        if let Some(endpoint) = &self.endpoint {
+            command.arg("--endpoint-url").arg(endpoint);
+        }
+        command
+    }
+
+    fn temp_file(&self) -> Result<PathBuf> {
+        fs::create_dir_all(&self.scratch).map_err(PadzError::Io)?;
+        let n = self.temp_files.get() + 1;
+        self.temp_files.set(n);
+        Ok(self.scratch.join(format!("object-{n}")))
+    }
+}
+
+impl ObjectClient for AwsCli {
+    fn list(&self) -> Result<Vec<(String, String)>> {
+        let prefix = self.location.key("");
+        let output = run(self.s3api("list-objects-v2").arg("--prefix").arg(&prefix))?;
+        let listing: serde_json::Value = serde_json::from_slice(&output.stdout)?;
+        let objects = listing["Contents"].as_array().cloned().unwrap_or_default();
+        Ok(objects
+            .iter()
+            .filter_map(|object| {
+                let key = object["Key"].as_str()?.strip_prefix(&prefix)?;
+                let etag = object["ETag"].as_str()?;
+                Some((key.to_string(), etag.to_string()))
+            })
+            .collect())
+    }
+
+    fn get(&self, key: &str) -> Result<Option<Object>> {
+        let file = self.temp_file()?;
+        let output = aws(self
+            .s3api("get-object")
+            .arg("--key")
+            .arg(self.location.key(key))
+            .arg(&file))?;
+        if failed_with(&output, &["NoSuchKey"]) {
+            return Ok(None);
+        }
+        let output = check(output)?;
+        let response: serde_json::Value = serde_json::from_slice(&output.stdout)?;
+        let body = fs::read(&file).map_err(PadzError::Io)?;
+        Ok(Some(Object {
+            body,
+            etag: etag_of(&response)?,
+        }))
+    }
+
+    fn put(&self, key: &str, body: &[u8], expected: Option<&str>) -> Result<Option<String>> {
+        let file = self.temp_file()?;
+        fs::write(&file, body).map_err(PadzError::Io)?;
+        let mut command = self.s3api("put-object");
+        command
+            .arg("--key")
+            .arg(self.location.key(key))
+            .arg("--body")
+            .arg(&file);
+        match expected {
+            Some(etag) => command.arg("--if-match").arg(etag),
+            None => command.arg("--if-none-match").arg("*"),
+        };
+        let output = aws(&mut command)?;
+        if failed_with(
+            &output,
+            &["PreconditionFailed", "ConditionalRequestConflict"],
+        ) {
+            return Ok(None);
+        }
+        let output = check(output)?;
+        let response: serde_json::Value = serde_json::from_slice(&output.stdout)?;
+        etag_of(&response).map(Some)
+    }
+
+    fn delete(&self, key: &str) -> Result<()> {
+        run(self
+            .s3api("delete-object")
+            .arg("--key")
+            .arg(self.location.key(key)))?;
+        Ok(())
+    }
+}
+
+fn run(command: &mut Command) -> Result<Output> {
+    check(aws(command)?)
+}
+
+/// Runs an `aws` call within `network_timeout`, whatever its exit status.
+fn aws(command: &mut Command) -> Result<Output> {
+    subprocess::output(command, Limit::Network, "the aws CLI")
+}
+
+fn check(output: Output) -> Result<Output> {
+    if output.status.success() {
+        Ok(output)
+    } else {
+        Err(PadzError::Api(
+            String::from_utf8_lossy(&output.stderr).trim().to_string(),
+        ))
+    }
+}
+
+/// Whether the CLI failed with one of the S3 error `codes`.
+fn failed_with(output: &Output, codes: &[&str]) -> bool {
+    let stderr = String::from_utf8_lossy(&output.stderr);
+    !output.status.success() && codes.iter().any(|code| stderr.contains(code))
+}
+
+fn etag_of(response: &serde_json::Value) -> Result<String> {
+    response["ETag"]
+        .as_str()
+        .map(str::to_string)
+        .ok_or_else(|| PadzError::Api("the aws CLI returned no ETag".into()))
+}
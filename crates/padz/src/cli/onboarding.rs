@@ -0,0 +1,79 @@
+//! The first run on a machine.
+//!
+//! Before anything else, [`padzapp::init::bootstrap`] sets up the global store
+//! if padz has never run here: its directories and an empty scope registry.
+//! When it did, and someone is at the terminal, padz says where pads will live
+//! and where to learn more, on stderr so the command's own output is
+//! untouched, and offers to install shell completions. Every later run finds
+//! the store in place and skips all of it.
+//!
+//! Dry runs and `--test-mode` skip the bootstrap, so they write nothing and
+//! say nothing.
+
+use super::capabilities::Capabilities;
+use super::setup::CompletionShell;
+use std::io::{BufRead, IsTerminal, Write};
+use std::path::Path;
+
+/// Sets up the global store at `global_dir` if this is padz's first run, and
+/// welcomes the user. Best-effort: a failure is a warning, never an error.
+pub fn run(global_dir: &Path, capabilities: &Capabilities) {
+    match padzapp::init::bootstrap(global_dir) {
+        Ok(true) if std::io::stderr().is_terminal() => {
+            eprintln!("{}", welcome(global_dir));
+            if let Some(shell) = super::commands::detect_shell() {
+                offer_completions(shell, capabilities);
+            }
+        }
+        Ok(_) => {}
+        Err(e) => eprintln!("Warning: could not set up {}: {}", global_dir.display(), e),
+    }
+}
+
+/// The note shown on the first run.
+fn welcome(global_dir: &Path) -> String {
+    format!(
+        "Welcome to padz! Global pads live in {}.\n\
+         Inside a git repository, pads go to a project store (.padz/) at its root; \
+         -g picks the global one.\n\
+         `padz tour` walks through the basics, and `padz help` lists every command.\n",
+        global_dir.display()
+    )
+}
+
+fn offer_completions(shell: CompletionShell, capabilities: &Capabilities) {
+    if !capabilities.can_prompt() {
+        eprintln!(
+            "Enable shell completions with: padz completion {} --install\n",
+            shell.as_complete_env()
+        );
+        return;
+    }
+    eprint!(
+        "Install shell completions for {} now? [y/N] ",
+        shell.as_complete_env()
+    );
+    let _ = std::io::stderr().flush();
+    let mut answer = String::new();
+    if std::io::stdin().lock().read_line(&mut answer).is_err() {
+        return;
+    }
+    if matches!(answer.trim().to_lowercase().as_str(), "y" | "yes") {
+        if let Err(e) = super::commands::handle_install(Some(shell)) {
+            eprintln!("Warning: completions were not installed: {}", e);
+        }
+    }
+    eprintln!();
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn the_welcome_names_the_global_store_and_the_tour() {
+        let note = welcome(Path::new("/home/me/.local/share/padz"));
+        assert!(note.contains("/home/me/.local/share/padz"));
+        assert!(note.contains("padz tour"));
+    }
+}
@@ -0,0 +1,41 @@
+//! Sending a `padz print` document to the printer.
+//!
+//! The document is PostScript (see [`padzapp::commands::print`]), which `lpr`
+//! takes on stdin on macOS and on Linux with CUPS. With no `lpr`, `print --to`
+//! writes the file for whatever prints on the system.
+
+use super::subprocess::{self, Limit};
+use padzapp::error::{PadzError, Result};
+use std::io::Write;
+use std::process::{Command, Stdio};
+
+/// Queues `document` on `printer`, or the system's default one.
+pub fn send(document: &[u8], printer: Option<&str>) -> Result<()> {
+    let mut command = Command::new("lpr");
+    if let Some(printer) = printer {
+        command.arg("-P").arg(printer);
+    }
+    let mut child = command
+        .stdin(Stdio::piped())
+        .stderr(Stdio::piped())
+        .spawn()
+        .map_err(|e| match e.kind() {
+            std::io::ErrorKind::NotFound => PadzError::Api(
+                "lpr not found; write the document with `padz print --to FILE` instead".to_string(),
+            ),
+            _ => PadzError::Api(format!("Failed to run lpr: {}", e)),
+        })?;
+    if let Some(mut stdin) = child.stdin.take() {
+        stdin
+            .write_all(document)
+            .map_err(|e| PadzError::Api(format!("Failed to write to lpr: {}", e)))?;
+    }
+    let output = subprocess::wait(child, Limit::Command, "lpr")?;
+    if !output.status.success() {
+        return Err(PadzError::Api(format!(
+            "lpr failed: {}",
+            String::from_utf8_lossy(&output.stderr).trim()
+        )));
+    }
+    Ok(())
+}
@@ -0,0 +1,146 @@
+//! Showing the progress of long operations on stderr.
+//!
+//! [`TerminalProgress`] is the padz CLI's [`Progress`] reporter. Operations
+//! report every item; what reaches the user is throttled:
+//!
+//! - on a terminal, a bar redrawn at most every 100ms, and cleared when the
+//!   operation ends,
+//! - otherwise (piped, under cron, in CI), a plain log line every few seconds.
+//!
+//! Operations that finish quickly show nothing at all: the point is that a
+//! long one never looks hung, not to decorate every `padz import`.
+
+use padzapp::progress::Progress;
+use std::cell::RefCell;
+use std::io::{IsTerminal, Write};
+use std::time::{Duration, Instant};
+
+/// How long an operation runs before a bar appears.
+const BAR_AFTER: Duration = Duration::from_millis(300);
+/// Minimum time between redraws of the bar.
+const REDRAW_EVERY: Duration = Duration::from_millis(100);
+/// How long an operation runs before its first log line, and between lines.
+const LOG_EVERY: Duration = Duration::from_secs(5);
+const BAR_WIDTH: usize = 30;
+
+pub struct TerminalProgress {
+    tty: bool,
+    task: RefCell<Option<Task>>,
+}
+
+struct Task {
+    name: String,
+    started: Instant,
+    last_shown: Option<Instant>,
+}
+
+impl TerminalProgress {
+    pub fn new() -> Self {
+        Self {
+            tty: std::io::stderr().is_terminal(),
+            task: RefCell::new(None),
+        }
+    }
+
+    fn clear(&self, task: &Task) {
+        if self.tty && task.last_shown.is_some() {
+            let mut stderr = std::io::stderr();
+            let _ = write!(stderr, "\r\x1b[2K");
+            let _ = stderr.flush();
+        }
+    }
+}
+
+impl Default for TerminalProgress {
+    fn default() -> Self {
+        Self::new()
+    }
+}
+
+impl Progress for TerminalProgress {
+    fn update(&self, name: &str, done: usize, total: usize) {
+        let mut current = self.task.borrow_mut();
+        if current.as_ref().is_some_and(|task| task.name != name) {
+            if let Some(previous) = current.take() {
+                self.clear(&previous);
+            }
+        }
+        let task = current.get_or_insert_with(|| Task {
+            name: name.to_string(),
+            started: Instant::now(),
+            last_shown: None,
+        });
+
+        let now = Instant::now();
+        let (wait, every) = if self.tty {
+            (BAR_AFTER, REDRAW_EVERY)
+        } else {
+            (LOG_EVERY, LOG_EVERY)
+        };
+        let due = match task.last_shown {
+            Some(last) => now.duration_since(last) >= every,
+            None => now.duration_since(task.started) >= wait,
+        };
+        if !due {
+            return;
+        }
+        task.last_shown = Some(now);
+        let mut stderr = std::io::stderr();
+        if self.tty {
+            let _ = write!(
+                stderr,
+                "\r\x1b[2K{} {} {}/{}",
+                name,
+                bar(done, total, BAR_WIDTH),
+                done,
+                total
+            );
+            let _ = stderr.flush();
+        } else {
+            let _ = writeln!(stderr, "{}: {}/{}", name, done, total);
+        }
+    }
+
+    fn finish(&self, name: &str) {
+        let mut current = self.task.borrow_mut();
+        if current.as_ref().is_some_and(|task| task.name == name) {
+            if let Some(task) = current.take() {
+                self.clear(&task);
+            }
+        }
+    }
+}
+
+impl Drop for TerminalProgress {
+    /// An operation that failed midway never reports its end; don't leave
+    /// its bar under the error message.
+    fn drop(&mut self) {
+        if let Some(task) = self.task.get_mut().take() {
+            self.clear(&task);
+        }
+    }
+}
+
+/// `[#####.....]`, filled in proportion to `done` of `total`.
+fn bar(done: usize, total: usize, width: usize) -> String {
+    let filled = if total == 0 {
+        width
+    } else {
+        (done.min(total) * width) / total
+    };
+    format!("[{}{}]", "#".repeat(filled), ".".repeat(width - filled))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn bar_fills_in_proportion() {
+        assert_eq!(bar(0, 4, 4), "[....]");
+        assert_eq!(bar(1, 4, 4), "[#...]");
+        assert_eq!(bar(4, 4, 4), "[####]");
+        assert_eq!(bar(9, 4, 4), "[####]");
+        assert_eq!(bar(0, 0, 4), "[####]");
+    }
+}
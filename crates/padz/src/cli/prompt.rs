@@ -0,0 +1,160 @@
+//! `padz prompt-segment`: a pad-count badge for a shell prompt.
+//!
+//! Prints `✎3 ⚲1` (active pads, then the pinned ones among them) for the
+//! scope the current directory resolves to, and nothing when that scope has
+//! no active pads. A prompt runs it on every redraw, so it takes the shortest
+//! path padz has: the store is located without being initialized (no config,
+//! migration or registry write; see [`padzapp::init::locate_project_store`]),
+//! and the counts are read from the bucket indexes alone
+//! ([`padzapp::commands::stats::from_index`]).
+//!
+//! The printed segment is also cached at the scope's root for [`CACHE_TTL`],
+//! so a prompt redrawn several times a second reads one small file. A cache
+//! older than the active index is stale whatever its age: a pad created in
+//! this shell shows at the next prompt.
+//!
+//! Failures print nothing: a broken store must not break the prompt.
+
+use padzapp::commands::stats::{self, PadCounts};
+use padzapp::init::{locate_project_store, PadzEnv};
+use padzapp::model::Scope;
+use padzapp::store::fs::FileStore;
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::time::{Duration, SystemTime};
+
+/// How long a cached segment is reused while the index is unchanged.
+pub const CACHE_TTL: Duration = Duration::from_secs(5);
+
+/// The cache, at the scope root (next to `tags.json`).
+const CACHE_FILE: &str = "prompt-segment";
+
+/// Print the segment for the scope `cwd` resolves to, as the global flags
+/// (`--global`, `--data`) would select it.
+pub fn run(env: &PadzEnv, cwd: &Path, global: bool, data: Option<PathBuf>) {
+    if let Some(segment) = segment_for(env, cwd, global, data) {
+        if !segment.is_empty() {
+            println!("{}", segment);
+        }
+    }
+}
+
+fn segment_for(env: &PadzEnv, cwd: &Path, global: bool, data: Option<PathBuf>) -> Option<String> {
+    let project = locate_project_store(env, cwd, global, data).ok()?;
+    let (root, scope) = match &project {
+        Some(dir) => (dir.clone(), Scope::Project),
+        None => (env.global_data_dir.clone(), Scope::Global),
+    };
+    if let Some(cached) = read_cache(&root, SystemTime::now()) {
+        return Some(cached);
+    }
+
+    let store = FileStore::new_fs(project, env.global_data_dir.clone());
+    let segment = format_segment(&stats::from_index(&store, scope).ok()?);
+    write_cache(&root, &segment);
+    Some(segment)
+}
+
+/// `✎3 ⚲1`; a count of zero is left out.
+fn format_segment(counts: &PadCounts) -> String {
+    let mut parts = Vec::new();
+    if counts.active > 0 {
+        parts.push(format!("✎{}", counts.active));
+    }
+    if counts.pinned > 0 {
+        parts.push(format!("⚲{}", counts.pinned));
+    }
+    parts.join(" ")
+}
+
+/// The cached segment, if it is younger than [`CACHE_TTL`] and than the
+/// active bucket's index.
+fn read_cache(root: &Path, now: SystemTime) -> Option<String> {
+    let cache = root.join(CACHE_FILE);
+    let written = fs::metadata(&cache).and_then(|m| m.modified()).ok()?;
+    let fresh = now.duration_since(written).is_ok_and(|age| age < CACHE_TTL);
+    let index_changed = fs::metadata(root.join("active").join("data.json"))
+        .and_then(|m| m.modified())
+        .is_ok_and(|changed| changed > written);
+    if !fresh || index_changed {
+        return None;
+    }
+    fs::read_to_string(cache).ok()
+}
+
+/// Best-effort, and only into a store that exists: a prompt in a directory
+/// with no pads must not create a global store. Written aside and renamed,
+/// so a prompt in another shell never reads half a cache.
+fn write_cache(root: &Path, segment: &str) {
+    if !root.is_dir() {
+        return;
+    }
+    let tmp = root.join(format!(".{}-{}.tmp", CACHE_FILE, std::process::id()));
+    if fs::write(&tmp, segment).is_ok() && fs::rename(&tmp, root.join(CACHE_FILE)).is_err() {
+        let _ = fs::remove_file(&tmp);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    fn env(temp: &TempDir) -> PadzEnv {
+        PadzEnv {
+            global_data_dir: temp.path().join("global"),
+            home_dir: None,
+        }
+    }
+
+    #[test]
+    fn format_segment_leaves_out_zero_counts() {
+        let counts = |active, pinned| PadCounts {
+            active,
+            pinned,
+            ..PadCounts::default()
+        };
+        assert_eq!(format_segment(&counts(3, 1)), "✎3 ⚲1");
+        assert_eq!(format_segment(&counts(2, 0)), "✎2");
+        assert_eq!(format_segment(&counts(0, 0)), "");
+    }
+
+    fn touch(path: &Path, modified: SystemTime) {
+        fs::File::options()
+            .write(true)
+            .open(path)
+            .unwrap()
+            .set_modified(modified)
+            .unwrap();
+    }
+
+    #[test]
+    fn a_fresh_cache_is_reused_until_the_index_changes() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path();
+        let index = root.join("active").join("data.json");
+        fs::create_dir_all(root.join("active")).unwrap();
+        fs::write(&index, "{}").unwrap();
+        write_cache(root, "✎1");
+        let now = SystemTime::now();
+
+        touch(&index, now - Duration::from_secs(1));
+        assert_eq!(read_cache(root, now).as_deref(), Some("✎1"));
+        assert_eq!(read_cache(root, now + CACHE_TTL), None);
+
+        touch(&index, now + Duration::from_secs(1));
+        assert_eq!(read_cache(root, now), None);
+    }
+
+    #[test]
+    fn no_store_means_no_segment_and_nothing_written() {
+        let temp = TempDir::new().unwrap();
+        let env = env(&temp);
+
+        assert_eq!(
+            segment_for(&env, temp.path(), false, None).as_deref(),
+            Some("")
+        );
+        assert!(!env.global_data_dir.exists());
+    }
+}
@@ -0,0 +1,282 @@
+//! Uploading pads for `padz publish`: GitHub gists and GitLab snippets.
+//!
+//! Which pads go up, and recording where they went, is
+//! [`padzapp::commands::publish`]'s; this module speaks the two APIs. Like
+//! `self-update`, it fetches with `curl`, one call per pad under
+//! `network_timeout`. The request, token header included, is handed to curl
+//! as a config file on stdin, so the token never shows in the process list.
+//!
+//! A new gist is secret and a new snippet private unless `--public` is given.
+//! An update keeps the upload where it is and renames its file when the pad's
+//! title changed.
+
+use super::subprocess::{self, Limit};
+use padzapp::api::Upload;
+use padzapp::config::PublishConfig;
+use padzapp::error::{PadzError, Result};
+use padzapp::model::{Publication, PublishService};
+use serde_json::{json, Value};
+use std::io::Write;
+use std::process::{Command, Stdio};
+
+const GITHUB_API: &str = "https://api.github.com";
+
+/// One call to a service's API.
+#[derive(Debug, Clone, PartialEq)]
+struct Request {
+    method: &'static str,
+    url: String,
+    headers: Vec<String>,
+    body: Value,
+}
+
+/// Uploads `upload` as a new gist or snippet on `service`.
+pub fn create(
+    config: &PublishConfig,
+    service: PublishService,
+    upload: &Upload,
+    public: bool,
+) -> Result<Publication> {
+    let token = token(config, service)?;
+    let request = match service {
+        PublishService::Gist => Request {
+            method: "POST",
+            url: format!("{}/gists", GITHUB_API),
+            headers: github_headers(&token),
+            body: json!({
+                "description": upload.title,
+                "public": public,
+                "files": { upload.filename.as_str(): { "content": upload.content } },
+            }),
+        },
+        PublishService::GitlabSnippet => Request {
+            method: "POST",
+            url: format!("{}/api/v4/snippets", gitlab_api(config)),
+            headers: gitlab_headers(&token),
+            body: json!({
+                "title": upload.title,
+                "visibility": if public { "public" } else { "private" },
+                "files": [{ "file_path": upload.filename, "content": upload.content }],
+            }),
+        },
+    };
+    let response = send(&request, service)?;
+    let (id, url) = published(&response, service)?;
+    Ok(upload.published(service, id, url))
+}
+
+/// Sends the pad's current text to where `upload` was published before.
+pub fn update(
+    config: &PublishConfig,
+    service: PublishService,
+    upload: &Upload,
+) -> Result<Publication> {
+    let previous = upload
+        .previous
+        .as_ref()
+        .ok_or_else(|| PadzError::Api(format!("'{}' was never published", upload.title)))?;
+    let token = token(config, service)?;
+    let request = match service {
+        PublishService::Gist => Request {
+            method: "PATCH",
+            url: format!("{}/gists/{}", GITHUB_API, previous.id),
+            headers: github_headers(&token),
+            body: json!({
+                "description": upload.title,
+                "files": {
+                    previous.filename.as_str(): {
+                        "filename": upload.filename,
+                        "content": upload.content,
+                    },
+                },
+            }),
+        },
+        PublishService::GitlabSnippet => Request {
+            method: "PUT",
+            url: format!("{}/api/v4/snippets/{}", gitlab_api(config), previous.id),
+            headers: gitlab_headers(&token),
+            body: json!({
+                "title": upload.title,
+                "files": [{
+                    "action": "update",
+                    "previous_path": previous.filename,
+                    "file_path": upload.filename,
+                    "content": upload.content,
+                }],
+            }),
+        },
+    };
+    let response = send(&request, service)?;
+    let url = published(&response, service)
+        .map(|(_, url)| url)
+        .unwrap_or_else(|_| previous.url.clone());
+    Ok(upload.published(service, previous.id.clone(), url))
+}
+
+/// The configured token, else the one in the environment.
+fn token(config: &PublishConfig, service: PublishService) -> Result<String> {
+    let (configured, variable, key) = match service {
+        PublishService::Gist => (&config.github_token, "GITHUB_TOKEN", "publish.github_token"),
+        PublishService::GitlabSnippet => {
+            (&config.gitlab_token, "GITLAB_TOKEN", "publish.gitlab_token")
+        }
+    };
+    configured
+        .clone()
+        .or_else(|| std::env::var(variable).ok())
+        .filter(|token| !token.trim().is_empty())
+        .ok_or_else(|| {
+            PadzError::Api(format!(
+                "No token to publish a {} with; set `{}` or ${}",
+                service, key, variable
+            ))
+        })
+}
+
+fn github_headers(token: &str) -> Vec<String> {
+    vec![
+        format!("Authorization: Bearer {}", token),
+        "Accept: application/vnd.github+json".to_string(),
+        "X-GitHub-Api-Version: 2022-11-28".to_string(),
+    ]
+}
+
+fn gitlab_headers(token: &str) -> Vec<String> {
+    vec![format!("PRIVATE-TOKEN: {}", token)]
+}
+
+fn gitlab_api(config: &PublishConfig) -> &str {
+    config.gitlab_url.trim_end_matches('/')
+}
+
+/// The curl config that makes `request`, the status code written last.
+fn curl_config(request: &Request) -> String {
+    let mut config = String::new();
+    let mut line = |key: &str, value: &str| {
+        config.push_str(&format!("{} = \"{}\"\n", key, quote(value)));
+    };
+    line("url", &request.url);
+    line("request", request.method);
+    for header in &request.headers {
+        line("header", header);
+    }
+    line("header", "Content-Type: application/json");
+    line("data-binary", &request.body.to_string());
+    line("write-out", "\n%{http_code}");
+    config
+}
+
+/// `value` escaped for a double-quoted curl config string.
+fn quote(value: &str) -> String {
+    value
+        .replace('\\', "\\\\")
+        .replace('"', "\\\"")
+        .replace('\n', "\\n")
+}
+
+/// Makes `request` and returns the JSON the service answered with.
+fn send(request: &Request, service: PublishService) -> Result<Value> {
+    let mut child = Command::new("curl")
+        .args(["-sS", "--config", "-"])
+        .stdin(Stdio::piped())
+        .stdout(Stdio::piped())
+        .stderr(Stdio::piped())
+        .spawn()
+        .map_err(|e| match e.kind() {
+            std::io::ErrorKind::NotFound => {
+                PadzError::Api("curl not found; padz publishes with curl".to_string())
+            }
+            _ => PadzError::Api(format!("Failed to run curl: {}", e)),
+        })?;
+    if let Some(mut stdin) = child.stdin.take() {
+        stdin
+            .write_all(curl_config(request).as_bytes())
+            .map_err(|e| PadzError::Api(format!("Failed to write to curl: {}", e)))?;
+    }
+    let output = subprocess::wait(child, Limit::Network, "curl")?;
+    if !output.status.success() {
+        return Err(PadzError::Api(format!(
+            "Could not reach the {} API: {}",
+            service,
+            String::from_utf8_lossy(&output.stderr).trim()
+        )));
+    }
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    let (body, status) = stdout.rsplit_once('\n').unwrap_or(("", stdout.as_ref()));
+    let response: Value = serde_json::from_str(body).unwrap_or(Value::Null);
+    if !status.trim().starts_with('2') {
+        let reason = response
+            .get("message")
+            .or_else(|| response.get("error"))
+            .map(|m| m.as_str().map_or_else(|| m.to_string(), str::to_string))
+            .unwrap_or_else(|| format!("HTTP {}", status.trim()));
+        return Err(PadzError::Api(format!(
+            "The {} API refused the upload: {}",
+            service, reason
+        )));
+    }
+    Ok(response)
+}
+
+/// The id and address the service gave the upload.
+fn published(response: &Value, service: PublishService) -> Result<(String, String)> {
+    let url_field = match service {
+        PublishService::Gist => "html_url",
+        PublishService::GitlabSnippet => "web_url",
+    };
+    let id = match response.get("id") {
+        Some(Value::String(id)) => Some(id.clone()),
+        Some(Value::Number(id)) => Some(id.to_string()),
+        _ => None,
+    };
+    let url = response.get(url_field).and_then(Value::as_str);
+    match (id, url) {
+        (Some(id), Some(url)) => Ok((id, url.to_string())),
+        _ => Err(PadzError::Api(format!(
+            "The {} API answered without an address for the upload",
+            service
+        ))),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn a_request_is_quoted_for_curl() {
+        let request = Request {
+            method: "POST",
+            url: "https://api.github.com/gists".to_string(),
+            headers: github_headers("t0ken"),
+            body: json!({ "description": "Say \"hi\"", "files": { "a.md": { "content": "a\nb" } } }),
+        };
+        let config = curl_config(&request);
+        assert!(config.contains("request = \"POST\"\n"));
+        assert!(config.contains("header = \"Authorization: Bearer t0ken\"\n"));
+        assert!(config.contains(
+            r#"data-binary = "{\"description\":\"Say \\\"hi\\\"\",\"files\":{\"a.md\":{\"content\":\"a\\nb\"}}}""#
+        ));
+        assert!(config.contains("write-out = \"\\n%{http_code}\"\n"));
+    }
+
+    #[test]
+    fn each_service_answers_with_its_own_address_field() {
+        let gist = json!({ "id": "aa5a315d61ae9438b18d", "html_url": "https://gist.github.com/aa5a315d61ae9438b18d" });
+        assert_eq!(
+            published(&gist, PublishService::Gist).unwrap(),
+            (
+                "aa5a315d61ae9438b18d".to_string(),
+                "https://gist.github.com/aa5a315d61ae9438b18d".to_string()
+            )
+        );
+        let snippet = json!({ "id": 42, "web_url": "https://gitlab.com/-/snippets/42" });
+        assert_eq!(
+            published(&snippet, PublishService::GitlabSnippet)
+                .unwrap()
+                .0,
+            "42"
+        );
+        assert!(published(&gist, PublishService::GitlabSnippet).is_err());
+    }
+}
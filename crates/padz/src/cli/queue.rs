@@ -0,0 +1,69 @@
+//! Retrying queued operations (`padz flush-queue`, and after every command).
+//!
+//! `padzapp::queue` keeps the failed operations; this module runs them. Every
+//! command that is not itself a dry run or `flush-queue` retries whatever is
+//! pending once it has run, warning when something is still stuck, so a
+//! queue normally empties itself as soon as the network is back.
+
+use padzapp::error::Result;
+use padzapp::queue::{queue_path, Operation, OperationQueue, QueueReport};
+use std::path::Path;
+
+use super::object_store::GlobalStoreSync;
+
+/// Retries every operation queued under `global_dir`, recording the outcome.
+/// With `list_only`, reports what is pending without retrying.
+pub fn flush(global_dir: &Path, list_only: bool) -> Result<QueueReport> {
+    let path = queue_path(global_dir);
+    let mut queue = OperationQueue::load(&path)?;
+    if queue.pending.is_empty() {
+        return Ok(QueueReport::Empty);
+    }
+    if list_only {
+        return Ok(QueueReport::Pending {
+            pending: queue.pending,
+        });
+    }
+
+    let mut sent = Vec::new();
+    for pending in queue.pending.clone() {
+        match run(global_dir, &pending.operation) {
+            Ok(()) => {
+                queue.complete(&pending.operation);
+                sent.push(pending);
+            }
+            Err(e) => queue.record_failure(pending.operation, &e),
+        }
+    }
+    queue.save(&path)?;
+    Ok(QueueReport::Flushed {
+        sent,
+        pending: queue.pending,
+    })
+}
+
+/// The retry run after a command: quiet when nothing is queued or everything
+/// went through, one warning otherwise.
+pub fn retry_pending(global_dir: &Path) {
+    if !queue_path(global_dir).exists() {
+        return;
+    }
+    match flush(global_dir, false) {
+        Ok(QueueReport::Flushed { pending, .. }) if !pending.is_empty() => eprintln!(
+            "Warning: {} queued operation(s) still pending; `padz flush-queue --list` shows them",
+            pending.len()
+        ),
+        Ok(_) => {}
+        Err(e) => eprintln!("Warning: could not read the operation queue: {}", e),
+    }
+}
+
+fn run(global_dir: &Path, operation: &Operation) -> std::result::Result<(), String> {
+    match operation {
+        Operation::GlobalStorePush { url, endpoint } => {
+            GlobalStoreSync::new(url, endpoint.clone(), global_dir.to_path_buf())
+                .map_err(|e| e.to_string())?
+                .try_push()
+        }
+    }
+}
@@ -0,0 +1,143 @@
+//! Fetching remote stores (`--remote`) into their local cache.
+//!
+//! `padz --remote devbox list` copies the store from the host with `sftp`
+//! (using the user's ssh config, keys and agent) into the cache that
+//! `padzapp::store::remote` places under the global data dir, then runs the
+//! command against the copy. The copy is fetched whole into a staging
+//! directory and swapped in only when complete, so an interrupted fetch
+//! leaves the previous copy intact.
+//!
+//! When the host cannot be reached, the previous copy is used with a warning
+//! saying how old it is; with no previous copy the command fails.
+//!
+//! A remote scope is read-only: a change to the copy would be overwritten by
+//! the next fetch, and never reach the host. Commands that change pads are
+//! refused up front, and the store is opened in dry-run mode so even
+//! bookkeeping writes (access tracking) stay in memory.
+
+use chrono::{DateTime, Local};
+use padzapp::store::location::LOCATION_FILE;
+use padzapp::store::remote::RemoteStore;
+use std::fs;
+use std::io::Write;
+use std::path::Path;
+use std::process::{Command, Stdio};
+
+use super::setup::{Commands, TagCommands};
+use super::subprocess::{self, Limit};
+
+/// Whether `command` only reads pads, and so may run against a remote copy.
+/// No command at all is `list`.
+pub fn is_read_only(command: Option<&Commands>) -> bool {
+    matches!(
+        command,
+        None | Some(
+            Commands::List { .. }
+                | Commands::Recent { .. }
+                | Commands::Search { .. }
+                | Commands::Peek { .. }
+                | Commands::View { .. }
+                | Commands::Copy { .. }
+                | Commands::Fill { .. }
+                | Commands::Snip { .. }
+                | Commands::Timeline { .. }
+                | Commands::Uuid { .. }
+                | Commands::Export { .. }
+                | Commands::Stats { .. }
+                | Commands::Tag(TagCommands::List { .. })
+        )
+    )
+}
+
+/// Refreshes `cache` from `remote`. On failure, returns what went wrong and
+/// leaves any previous copy in place.
+pub fn fetch(remote: &RemoteStore, cache: &Path) -> Result<(), String> {
+    let parent = cache.parent().ok_or("cache directory has no parent")?;
+    fs::create_dir_all(parent).map_err(|e| e.to_string())?;
+    let staging = parent.join(".padz.fetching");
+    if staging.exists() {
+        fs::remove_dir_all(&staging).map_err(|e| e.to_string())?;
+    }
+
+    let mut sftp = Command::new("sftp");
+    sftp.arg("-q").arg("-b").arg("-");
+    if let Some(port) = remote.port {
+        sftp.arg("-P").arg(port.to_string());
+    }
+    let mut child = sftp
+        .arg(&remote.destination)
+        .stdin(Stdio::piped())
+        .stdout(Stdio::null())
+        .stderr(Stdio::piped())
+        .spawn()
+        .map_err(|e| format!("failed to run sftp: {}", e))?;
+    if let Some(mut stdin) = child.stdin.take() {
+        writeln!(
+            stdin,
+            "get -R {} {}",
+            quote(&remote.path),
+            quote(&staging.to_string_lossy())
+        )
+        .map_err(|e| e.to_string())?;
+    }
+    let output = subprocess::wait(child, Limit::Network, "sftp").map_err(|e| e.to_string())?;
+    if !output.status.success() {
+        let _ = fs::remove_dir_all(&staging);
+        let stderr = String::from_utf8_lossy(&output.stderr);
+        return Err(match stderr.trim() {
+            "" => format!("sftp exited with {}", output.status),
+            message => message.to_string(),
+        });
+    }
+
+    // The host's record of where the store lives means nothing here; left in
+    // place, every open of the copy would report a moved project.
+    let _ = fs::remove_file(staging.join(LOCATION_FILE));
+    if cache.exists() {
+        fs::remove_dir_all(cache).map_err(|e| e.to_string())?;
+    }
+    fs::rename(&staging, cache).map_err(|e| e.to_string())
+}
+
+/// When `cache` was last refreshed, for the stale-copy warning.
+pub fn fetched_at(cache: &Path) -> Option<String> {
+    let modified = fs::metadata(cache).ok()?.modified().ok()?;
+    let local: DateTime<Local> = modified.into();
+    Some(local.format("%Y-%m-%d %H:%M").to_string())
+}
+
+/// Quotes `path` for an sftp batch command.
+fn quote(path: &str) -> String {
+    format!("\"{}\"", path.replace('\\', "\\\\").replace('"', "\\\""))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use clap::Parser;
+
+    fn command(args: &[&str]) -> Option<Commands> {
+        let argv = std::iter::once("padz").chain(args.iter().copied());
+        super::super::setup::Cli::try_parse_from(argv)
+            .unwrap()
+            .command
+    }
+
+    #[test]
+    fn only_reading_commands_run_against_a_remote() {
+        assert!(is_read_only(None));
+        assert!(is_read_only(command(&["list"]).as_ref()));
+        assert!(is_read_only(command(&["view", "1"]).as_ref()));
+        assert!(is_read_only(command(&["tag", "list"]).as_ref()));
+
+        assert!(!is_read_only(command(&["create", "x"]).as_ref()));
+        assert!(!is_read_only(command(&["delete", "1"]).as_ref()));
+        assert!(!is_read_only(command(&["tag", "add", "1", "x"]).as_ref()));
+    }
+
+    #[test]
+    fn batch_paths_are_quoted() {
+        assert_eq!(quote("/srv/my app/.padz"), "\"/srv/my app/.padz\"");
+        assert_eq!(quote("a\"b"), "\"a\\\"b\"");
+    }
+}
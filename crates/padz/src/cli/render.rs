@@ -8,17 +8,26 @@
 //! After the epic that collapsed padz's presentation tiers, this module is exactly three
 //! things:
 //!
-//! ## 1. Two MiniJinja filters (the listing render path)
+//! ## 1. Three MiniJinja filters and two functions (the listing render path)
 //!
 //! Handlers return core types and `list.jinja` walks the core [`DisplayPad`] tree with a
 //! recursive loop (`{% for pad in pads recursive %}` + `loop.depth0`), so depth and
 //! section fall out of the tree itself. The only per-value derivation a template cannot
-//! do lives in two filters, registered on the engine in [`super::commands`]:
+//! do lives in three filters and two functions, registered on the engine in
+//! [`super::commands`]:
 //!
 //! - [`timeago_filter`] — clock arithmetic against `Utc::now()` (a template has no
-//!   clock). Yields a *number and a unit* ([`TimeAgo`]); the template composes the label.
+//!   clock; tests pin it with [`freeze_clock`]). Yields a number, a unit and the
+//!   label `list.time_format` asks for ([`TimeAgo`]); the template adds the glyph.
+//!   The `time_format()` function names that format, so a template can size the
+//!   time column for it.
+//! - `narrow()` — whether the terminal is below [`NARROW_WIDTH`], which switches the
+//!   pad line to its two-line layout. Like `time_format()` it is a function, not a
+//!   filter: a template reads it once, before it lays out a row.
 //! - [`peek_filter`] — delegates to `padzapp::peek::format_as_peek`, which owns the
 //!   preview rules.
+//! - [`link_filter`] — makes a laid-out pad line an OSC 8 hyperlink to the pad's
+//!   `padz://` URI, when [`show_links`] turned links on for this terminal.
 //!
 //! The modification family (`modification_result.jinja`) and the tagging family
 //! (`tagging.jinja`, `tag_catalog.jinja`, `tag_registry.jinja`) also render straight from
@@ -52,14 +61,19 @@
 //! above. When Standout grows a width-returning template function (or `_match_lines` no
 //! longer needs per-segment truncation), this provider and [`TERMINAL`] can go.
 
-use chrono::{DateTime, Utc};
+use chrono::{DateTime, Local, TimeZone, Utc};
 use minijinja::Value;
+use padzapp::config::TimeFormat;
+use padzapp::model::Scope;
 use padzapp::peek::{format_as_peek, PeekResult};
 use serde::Serialize;
 use standout::context::RenderContext;
+use std::sync::RwLock;
 
 /// Minimum terminal width — below this we stop shrinking and let the terminal wrap.
-pub const MIN_LINE_WIDTH: usize = 30;
+pub const MIN_LINE_WIDTH: usize = 20;
+/// Below this effective width a pad line is laid out on two lines (see [`narrow`]).
+pub const NARROW_WIDTH: usize = 60;
 /// Default width when no terminal is detected and COLUMNS is unset (e.g. piped output).
 pub const DEFAULT_LINE_WIDTH: usize = 80;
 
@@ -73,9 +87,9 @@ pub const TERMINAL: &str = "terminal";
 /// 2. Actual terminal width via `terminal_size`
 /// 3. `DEFAULT_LINE_WIDTH` (80)
 ///
-/// The raw width is clamped to at least `MIN_LINE_WIDTH` (30) *before* the `⏲`
+/// The raw width is clamped to at least `MIN_LINE_WIDTH` (20) *before* the `⏲`
 /// payback below subtracts 1, so the effective minimum this function returns is
-/// `MIN_LINE_WIDTH - 1` (29), not `MIN_LINE_WIDTH` — the
+/// `MIN_LINE_WIDTH - 1` (19), not `MIN_LINE_WIDTH` — the
 /// `line_width_reads_columns_and_clamps_to_the_minimum` unit test pins this. Keep that
 /// ordering in mind when adjusting width policy.
 ///
@@ -85,7 +99,7 @@ pub const TERMINAL: &str = "terminal";
 /// `⏲` is Unicode *Narrow*, not East-Asian *ambiguous*, so no ambiguous-width policy
 /// pays this back — the `-1` is the only thing that does. Because this payback lands
 /// after the clamp (`raw.max(MIN_LINE_WIDTH).saturating_sub(1)`), it is what pulls the
-/// effective floor to 29.
+/// effective floor to 19.
 ///
 /// This reads `$COLUMNS` rather than `RenderContext::terminal_width` on purpose: the
 /// context field is `None` whenever output is piped, which is exactly the case tests
@@ -106,6 +120,14 @@ pub fn line_width() -> usize {
     raw.max(MIN_LINE_WIDTH).saturating_sub(1)
 }
 
+/// Whether the terminal is too narrow for a pad line's one-line layout: a tmux
+/// split or a side pane, where the index, status and time columns would leave
+/// the title a handful of characters. Below [`NARROW_WIDTH`] the pad line puts
+/// the title on a line of its own, with tags and time under it.
+pub fn narrow() -> bool {
+    line_width() < NARROW_WIDTH
+}
+
 /// padz's terminal-width detector, installed with `set_terminal_width_detector`.
 ///
 /// standout 7.9.1's default detector reads the tty via `terminal_size` and does not
@@ -121,22 +143,56 @@ pub fn detect_width() -> Option<usize> {
 // timeago derivation
 // =============================================================================
 
-/// How long ago something happened, as a number and a unit — never as a sentence.
+/// The instant `timeago` measures against when frozen; `None` means the system clock.
+static FROZEN_NOW: RwLock<Option<DateTime<Utc>>> = RwLock::new(None);
+
+/// Freezes the clock [`timeago_filter`] measures against, or (with `None`) returns
+/// it to the system clock. The golden rendering tests freeze it so ages render the
+/// same on every run; nothing on the production path calls this.
+pub fn freeze_clock(now: Option<DateTime<Utc>>) {
+    *FROZEN_NOW.write().unwrap_or_else(|e| e.into_inner()) = now;
+}
+
+fn now() -> DateTime<Utc> {
+    FROZEN_NOW
+        .read()
+        .unwrap_or_else(|e| e.into_inner())
+        .unwrap_or_else(Utc::now)
+}
+
+/// How [`timeago_filter`] labels an age; [`TimeFormat::Short`] until
+/// `commands::run` applies `list.time_format`.
+static TIME_FORMAT: RwLock<TimeFormat> = RwLock::new(TimeFormat::Short);
+
+/// Sets how ages are labelled, for every template that renders one.
+pub fn use_time_format(format: TimeFormat) {
+    *TIME_FORMAT.write().unwrap_or_else(|e| e.into_inner()) = format;
+}
+
+fn time_format() -> TimeFormat {
+    *TIME_FORMAT.read().unwrap_or_else(|e| e.into_inner())
+}
+
+/// How long ago something happened: a number, a unit, and the label the
+/// configured [`TimeFormat`] makes of them — never a sentence.
 ///
-/// The template composes the label (and picks the glyph); this type only does the
-/// clock arithmetic, which needs `Utc::now()` and so cannot happen in a template.
-#[derive(Debug, Clone, Copy, Serialize)]
+/// The template adds the glyph and any wording around the label; this type only
+/// does the clock arithmetic, which needs `Utc::now()` and so cannot happen in a
+/// template.
+#[derive(Debug, Clone, Serialize)]
 pub struct TimeAgo {
     pub value: u64,
     pub unit: char,
+    pub label: String,
 }
 
 impl TimeAgo {
     fn since(timestamp: DateTime<Utc>) -> Self {
-        let secs = Utc::now()
-            .signed_duration_since(timestamp)
-            .num_seconds()
-            .max(0) as u64;
+        Self::between(timestamp, now(), time_format())
+    }
+
+    fn between(timestamp: DateTime<Utc>, now: DateTime<Utc>, format: TimeFormat) -> Self {
+        let secs = now.signed_duration_since(timestamp).num_seconds().max(0) as u64;
         let (value, unit) = if secs < 60 {
             (secs, 's')
         } else if secs < 3600 {
@@ -152,10 +208,62 @@ impl TimeAgo {
         } else {
             (secs / (86400 * 365), 'y')
         };
-        Self { value, unit }
+        let label = match format {
+            TimeFormat::Short => format!("{}{}", value, unit),
+            TimeFormat::Humanized => humanized(value, unit),
+            TimeFormat::Absolute => {
+                absolute(timestamp.with_timezone(&Local), now.with_timezone(&Local))
+            }
+        };
+        Self { value, unit, label }
+    }
+}
+
+/// `3 hours`, `1 day`: the count with its unit spelled out.
+fn humanized(value: u64, unit: char) -> String {
+    let name = match unit {
+        's' => "second",
+        'm' => "minute",
+        'h' => "hour",
+        'd' => "day",
+        'w' => "week",
+        'M' => "month",
+        _ => "year",
+    };
+    format!("{} {}{}", value, name, if value == 1 { "" } else { "s" })
+}
+
+/// The time of day when `timestamp` falls on `now`'s day, its date otherwise.
+fn absolute<Tz: TimeZone>(timestamp: DateTime<Tz>, now: DateTime<Tz>) -> String
+where
+    Tz::Offset: std::fmt::Display,
+{
+    if timestamp.date_naive() == now.date_naive() {
+        timestamp.format("%H:%M").to_string()
+    } else {
+        timestamp.format("%Y-%m-%d").to_string()
     }
 }
 
+// =============================================================================
+// hyperlinks
+// =============================================================================
+
+/// The scope [`link_filter`] links pad lines into; `None`, the default, is no links.
+static LINKS: RwLock<Option<Scope>> = RwLock::new(None);
+
+/// Turns pad-line hyperlinks on, for pads of `scope`, or off. `commands::run`
+/// turns them on for a styled render to a terminal that opens them, unless
+/// `list.hyperlinks` is off; every other path, tests included, leaves them off.
+pub fn show_links(scope: Option<Scope>) {
+    *LINKS.write().unwrap_or_else(|e| e.into_inner()) = scope;
+}
+
+/// `text` as an OSC 8 hyperlink to `uri`.
+fn hyperlink(text: &str, uri: &str) -> String {
+    format!("\x1b]8;;{}\x1b\\{}\x1b]8;;\x1b\\", uri, text)
+}
+
 // =============================================================================
 // Context provider (the documented `_match_lines` width residue)
 // =============================================================================
@@ -192,10 +300,11 @@ fn peek_body(content: &str) -> Option<PeekResult> {
 // MiniJinja filters (the listing render path)
 // =============================================================================
 
-/// `timeago` filter: turns a serialized `created_at` timestamp into `{value, unit}`.
+/// `timeago` filter: turns a serialized `created_at` timestamp into
+/// `{value, unit, label}`.
 ///
 /// The input is the RFC3339 string serde produces for `DateTime<Utc>`. The template
-/// composes the label (`"3m ⏲"`); this only does the clock arithmetic, which needs
+/// adds the glyph (`"3m ⏲"`); this only does the clock arithmetic, which needs
 /// `Utc::now()` and so cannot live in the template. A value that is not a parseable
 /// timestamp renders as `undefined` rather than aborting the whole listing.
 pub fn timeago_filter(value: &str) -> Value {
@@ -205,6 +314,17 @@ pub fn timeago_filter(value: &str) -> Value {
     }
 }
 
+/// `narrow()` function: whether pad lines take the two-line layout ([`narrow`]).
+pub fn narrow_function() -> bool {
+    narrow()
+}
+
+/// `time_format()` function: the configured [`TimeFormat`] by name (`"short"`,
+/// `"humanized"`, `"absolute"`), which `_layout.jinja` sizes the time column by.
+pub fn time_format_function() -> String {
+    time_format().to_string()
+}
+
 /// `peek` filter: previews a pad's body, or `undefined` when it has none.
 ///
 /// Wraps [`peek_body`] so the preview rules stay in `padzapp::peek` and never leak into
@@ -217,10 +337,34 @@ pub fn peek_filter(content: &str) -> Value {
     }
 }
 
+/// `link` filter: links a pad line, already laid out, to the pad's URI.
+///
+/// It wraps the whole row rather than the title because `tabular` measures and
+/// truncates its cells: an escape sequence inside one would count as width.
+/// Wrapped afterwards, the row keeps its layout and the title still clicks.
+pub fn link_filter(row: String, id: &str) -> String {
+    let scope = *LINKS.read().unwrap_or_else(|e| e.into_inner());
+    match (scope, uuid::Uuid::parse_str(id)) {
+        (Some(scope), Ok(id)) => hyperlink(&row, &padzapp::index::pad_uri(scope, &id)),
+        _ => row,
+    }
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
 
+    #[test]
+    fn a_hyperlink_is_an_osc8_pair_around_the_text() {
+        assert_eq!(
+            hyperlink(
+                " 1. Groceries",
+                "padz://project/550e8400-e29b-41d4-a716-446655440000"
+            ),
+            "\x1b]8;;padz://project/550e8400-e29b-41d4-a716-446655440000\x1b\\ 1. Groceries\x1b]8;;\x1b\\"
+        );
+    }
+
     // =========================================================================
     // peek / timeago filters (the listing render path)
     // =========================================================================
@@ -280,6 +424,33 @@ mod tests {
         }
     }
 
+    /// Each format labels the same age its own way; short is what the pad line
+    /// always showed.
+    #[test]
+    fn each_time_format_labels_the_age() {
+        let now = Utc::now();
+        let labels = |secs: i64, format| {
+            TimeAgo::between(now - chrono::Duration::seconds(secs), now, format).label
+        };
+        assert_eq!(labels(34, TimeFormat::Short), "34s");
+        assert_eq!(labels(3 * 3600, TimeFormat::Short), "3h");
+        assert_eq!(labels(2 * 86_400, TimeFormat::Short), "2d");
+        assert_eq!(labels(1, TimeFormat::Humanized), "1 second");
+        assert_eq!(labels(3 * 3600, TimeFormat::Humanized), "3 hours");
+        assert_eq!(labels(86_400 * 7, TimeFormat::Humanized), "1 week");
+        assert_eq!(labels(86_400 * 60, TimeFormat::Humanized), "2 months");
+    }
+
+    /// Absolute labels read the time for today and the date for any other day.
+    #[test]
+    fn an_absolute_label_is_a_time_today_and_a_date_before() {
+        let now = Utc.with_ymd_and_hms(2026, 10, 15, 18, 0, 0).unwrap();
+        let morning = Utc.with_ymd_and_hms(2026, 10, 15, 9, 5, 0).unwrap();
+        let last_week = Utc.with_ymd_and_hms(2026, 10, 8, 23, 59, 0).unwrap();
+        assert_eq!(absolute(morning, now), "09:05");
+        assert_eq!(absolute(last_week, now), "2026-10-08");
+    }
+
     /// A clock skewed into the future must not underflow into a huge age.
     #[test]
     fn a_future_timestamp_clamps_to_zero() {
@@ -304,6 +475,10 @@ mod tests {
             std::env::set_var("COLUMNS", columns);
             assert_eq!(line_width(), expected, "COLUMNS={columns}");
         }
+        for (columns, narrow_layout) in [("40", true), ("60", true), ("61", false), ("80", false)] {
+            std::env::set_var("COLUMNS", columns);
+            assert_eq!(narrow(), narrow_layout, "COLUMNS={columns}");
+        }
         match restore {
             Some(v) => std::env::set_var("COLUMNS", v),
             None => std::env::remove_var("COLUMNS"),
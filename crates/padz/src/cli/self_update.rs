@@ -0,0 +1,228 @@
+//! Installing new releases: `padz self-update` and the opt-in update check.
+//!
+//! Releases are the GitHub releases `install.sh` installs from: one
+//! `padz-<target>.tar.gz` per platform, with its SHA-256 beside it as
+//! `padz-<target>.tar.gz.sha256`, which the `release-checksums` workflow
+//! uploads. Like `install.sh`, padz fetches them with `curl`, and learns the
+//! latest tag from the `/releases/latest` redirect rather than the rate-limited
+//! API. Each fetch runs under `network_timeout`.
+//!
+//! Deciding — which version is newer, whether the checksum matches, where the
+//! binary is in the archive, whether a check is due — is
+//! [`padzapp::update`]'s; this module fetches and replaces the executable.
+//!
+//! The update check ([`notify`]) is off unless `update_check` is set. It runs
+//! after a command on a terminal, asks at most once a day, gives GitHub
+//! [`CHECK_SECONDS`] to answer, and says nothing when it cannot tell.
+
+use chrono::Utc;
+use padzapp::error::{PadzError, Result};
+use padzapp::update::{self, UpdateCheck, UpdateOutcome};
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::process::Command;
+
+use super::subprocess::{self, Limit};
+
+const REPO: &str = "arthur-debert/padz";
+const BIN: &str = "padz";
+/// The version of this binary.
+const CURRENT: &str = env!("CARGO_PKG_VERSION");
+/// How long the update check may hold up the command it follows.
+pub const CHECK_SECONDS: &str = "5";
+
+/// The release target built for this platform, named as `install.sh` names it.
+fn target() -> Option<&'static str> {
+    match (std::env::consts::OS, std::env::consts::ARCH) {
+        ("macos", "aarch64") => Some("aarch64-apple-darwin"),
+        ("macos", "x86_64") => Some("x86_64-apple-darwin"),
+        ("linux", "x86_64") => Some("x86_64-linux-gnu"),
+        ("linux", "aarch64") => Some("aarch64-linux-gnu"),
+        _ => None,
+    }
+}
+
+/// `curl -fsSL <args>`, returning what it wrote to stdout.
+fn curl(args: &[&str]) -> Result<Vec<u8>> {
+    let output = subprocess::output(
+        Command::new("curl").arg("-fsSL").args(args),
+        Limit::Network,
+        "curl",
+    )?;
+    if !output.status.success() {
+        return Err(PadzError::Api(format!(
+            "curl {} failed: {}",
+            args.last().unwrap_or(&""),
+            String::from_utf8_lossy(&output.stderr).trim()
+        )));
+    }
+    Ok(output.stdout)
+}
+
+/// The tag at the end of a `/releases/tag/<tag>` URL.
+fn tag_from_url(url: &str) -> Option<String> {
+    let (_, tag) = url.trim().rsplit_once("/tag/")?;
+    (!tag.is_empty()).then(|| tag.to_string())
+}
+
+/// The tag of the latest release. `extra` goes to curl first (a time limit).
+fn latest_tag(extra: &[&str]) -> Result<String> {
+    let url = format!("https://github.com/{}/releases/latest", REPO);
+    let mut args = extra.to_vec();
+    args.extend([
+        "-I",
+        "-o",
+        "/dev/null",
+        "-w",
+        "%{url_effective}",
+        url.as_str(),
+    ]);
+    let effective = String::from_utf8_lossy(&curl(&args)?).into_owned();
+    tag_from_url(&effective).ok_or_else(|| {
+        PadzError::Api(format!(
+            "Could not tell the latest release from {} (it led to {})",
+            url, effective
+        ))
+    })
+}
+
+/// Records a check of the releases, so the daily check counts it.
+fn record(global_dir: &Path, latest: Option<&str>) {
+    let check = UpdateCheck {
+        checked_at: Utc::now(),
+        latest: latest.map(str::to_string),
+    };
+    let _ = check.save(&update::update_check_path(global_dir));
+}
+
+/// Finds the latest release and, unless `check_only`, installs it over the
+/// running binary once its checksum matches.
+pub fn run(global_dir: &Path, check_only: bool) -> Result<UpdateOutcome> {
+    let tag = latest_tag(&[])?;
+    record(global_dir, Some(&tag));
+    let latest = tag.trim_start_matches('v').to_string();
+    let current = CURRENT.to_string();
+    if !update::is_newer(&tag, CURRENT) {
+        return Ok(UpdateOutcome::UpToDate { current });
+    }
+    if check_only {
+        return Ok(UpdateOutcome::Available { current, latest });
+    }
+
+    let target = target().ok_or_else(|| {
+        PadzError::Api(format!(
+            "No padz release is built for {}-{}",
+            std::env::consts::OS,
+            std::env::consts::ARCH
+        ))
+    })?;
+    let archive_name = format!("{}-{}.tar.gz", BIN, target);
+    let url = format!(
+        "https://github.com/{}/releases/download/{}/{}",
+        REPO, tag, archive_name
+    );
+    let archive = curl(&[url.as_str()])?;
+    let checksum = published_checksum(&archive_name, curl(&[format!("{}.sha256", url).as_str()]))?;
+    update::verify_checksum(&archive, &checksum)?;
+    let binary = update::extract_binary(&archive, BIN)?;
+    let path = replace_current_exe(&binary)?;
+
+    Ok(UpdateOutcome::Installed {
+        previous: current,
+        installed: latest,
+        path,
+    })
+}
+
+/// The checksum file fetched for `archive_name`. A release without one is
+/// refused rather than installed unverified.
+fn published_checksum(archive_name: &str, fetched: Result<Vec<u8>>) -> Result<String> {
+    fetched
+        .map(|checksum| String::from_utf8_lossy(&checksum).into_owned())
+        .map_err(|e| {
+            PadzError::Api(format!(
+                "{} has no checksum to verify it against, so it was not installed ({})",
+                archive_name, e
+            ))
+        })
+}
+
+/// Writes `binary` next to the running executable and renames it over it, so
+/// the swap is atomic and a failed write leaves the old binary in place.
+fn replace_current_exe(binary: &[u8]) -> Result<PathBuf> {
+    let exe = std::env::current_exe()?;
+    let exe = fs::canonicalize(&exe).unwrap_or(exe);
+    let staged = exe.with_file_name(format!(".{}-update-{}", BIN, std::process::id()));
+    let swap = || -> std::io::Result<()> {
+        fs::write(&staged, binary)?;
+        #[cfg(unix)]
+        {
+            use std::os::unix::fs::PermissionsExt;
+            fs::set_permissions(&staged, fs::Permissions::from_mode(0o755))?;
+        }
+        fs::rename(&staged, &exe)
+    };
+    swap().map_err(|e| {
+        let _ = fs::remove_file(&staged);
+        PadzError::Api(format!(
+            "Could not replace {}: {} (if a package manager installed padz, update it there)",
+            exe.display(),
+            e
+        ))
+    })?;
+    Ok(exe)
+}
+
+/// The opt-in update check, run after a command: when a day has passed since
+/// the last one, asks for the latest release and prints one line on stderr if
+/// it is newer. A check that fails is recorded too, so an offline machine
+/// asks once a day rather than on every command.
+pub fn notify(global_dir: &Path) {
+    let path = update::update_check_path(global_dir);
+    if !UpdateCheck::due(UpdateCheck::load(&path).as_ref(), Utc::now()) {
+        return;
+    }
+    let latest = latest_tag(&["--max-time", CHECK_SECONDS]).ok();
+    record(global_dir, latest.as_deref());
+    if let Some(tag) = latest.filter(|tag| update::is_newer(tag, CURRENT)) {
+        eprintln!(
+            "padz {} is available (you have {}); run `padz self-update` to install it",
+            tag.trim_start_matches('v'),
+            CURRENT
+        );
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn the_latest_tag_is_read_off_the_redirect() {
+        assert_eq!(
+            tag_from_url("https://github.com/arthur-debert/padz/releases/tag/v1.10.0\n"),
+            Some("v1.10.0".to_string())
+        );
+        assert_eq!(
+            tag_from_url("https://github.com/arthur-debert/padz/releases/latest"),
+            None
+        );
+        assert_eq!(tag_from_url("https://github.com/x/releases/tag/"), None);
+    }
+
+    #[test]
+    fn a_release_without_a_checksum_is_not_installed() {
+        let missing = Err(PadzError::Api(
+            "curl padz-x86_64-linux-gnu.tar.gz.sha256 failed: 404".into(),
+        ));
+        let err = published_checksum("padz-x86_64-linux-gnu.tar.gz", missing)
+            .unwrap_err()
+            .to_string();
+        assert!(err.contains("padz-x86_64-linux-gnu.tar.gz has no checksum"));
+        assert!(err.contains("not installed"));
+        assert!(err.contains("404"));
+
+        let published = published_checksum("padz.tar.gz", Ok(b"abc123  padz.tar.gz\n".to_vec()));
+        assert_eq!(published.unwrap(), "abc123  padz.tar.gz\n");
+    }
+}
@@ -3,6 +3,7 @@ use super::complete::{
 };
 use clap::{CommandFactory, FromArgMatches, Parser, Subcommand, ValueEnum};
 use once_cell::sync::Lazy;
+use padzapp::api::{ArchiveFormat, CompileOrder, GroupBy, Paper};
 use standout::cli::{
     render_help_with_topics, App, CommandGroup, DefaultCommandContext, Dispatch, HelpConfig,
 };
@@ -30,6 +31,111 @@ impl CompletionShell {
     }
 }
 
+/// Alternative orderings for `list --sort`.
+///
+/// Canonical display order is the default and needs no value; these only
+/// reorder the listing, never the indexes the pads carry.
+#[derive(Copy, Clone, Debug, PartialEq, Eq, ValueEnum)]
+pub enum ListSort {
+    /// Most recently viewed, opened or peeked at first
+    Accessed,
+}
+
+/// Where `create --auto-title` takes a title from.
+#[derive(Copy, Clone, Debug, PartialEq, Eq, ValueEnum)]
+pub enum AutoTitle {
+    /// The current branch ("notes: <branch>"), or the last commit's subject
+    /// on a detached HEAD
+    Git,
+}
+
+/// Groupings for `list --group-by`: section headers over the listing, each
+/// with its pad count.
+#[derive(Copy, Clone, Debug, PartialEq, Eq, ValueEnum)]
+pub enum ListGroupBy {
+    /// The project each pad was created in
+    Project,
+    /// The day each pad was created
+    Day,
+    /// The week each pad was created
+    Week,
+}
+
+impl From<ListGroupBy> for GroupBy {
+    fn from(group_by: ListGroupBy) -> Self {
+        match group_by {
+            ListGroupBy::Project => GroupBy::Project,
+            ListGroupBy::Day => GroupBy::Day,
+            ListGroupBy::Week => GroupBy::Week,
+        }
+    }
+}
+
+/// Orderings for `compile --sort`.
+///
+/// Without `--sort`, pads named on the command line keep the order they were
+/// given in, and tag-only compiles run oldest first.
+#[derive(Copy, Clone, Debug, PartialEq, Eq, ValueEnum)]
+pub enum CompileSort {
+    /// Oldest first
+    Created,
+    /// Least recently updated first
+    Updated,
+    /// Alphabetically by title
+    Title,
+}
+
+impl From<CompileSort> for CompileOrder {
+    fn from(sort: CompileSort) -> Self {
+        match sort {
+            CompileSort::Created => CompileOrder::Created,
+            CompileSort::Updated => CompileOrder::Updated,
+            CompileSort::Title => CompileOrder::Title,
+        }
+    }
+}
+
+/// Sheet sizes for `print --paper`.
+#[derive(Copy, Clone, Debug, PartialEq, Eq, ValueEnum)]
+pub enum PrintPaper {
+    /// 210 × 297 mm
+    A4,
+    /// 8.5 × 11 in
+    Letter,
+}
+
+impl From<PrintPaper> for Paper {
+    fn from(paper: PrintPaper) -> Self {
+        match paper {
+            PrintPaper::A4 => Paper::A4,
+            PrintPaper::Letter => Paper::Letter,
+        }
+    }
+}
+
+/// Containers for `export --format`, each holding every pad's text and
+/// metadata for `padz import` to read back.
+#[derive(Copy, Clone, Debug, PartialEq, Eq, ValueEnum)]
+pub enum ExportArchive {
+    /// A .tar.gz of the pad files and db.json, as --json writes
+    #[value(name = "tar.gz")]
+    TarGz,
+    /// The same files in a .zip
+    Zip,
+    /// One .json file, the pads' text inline
+    Json,
+}
+
+impl From<ExportArchive> for ArchiveFormat {
+    fn from(archive: ExportArchive) -> Self {
+        match archive {
+            ExportArchive::TarGz => ArchiveFormat::TarGz,
+            ExportArchive::Zip => ArchiveFormat::Zip,
+            ExportArchive::Json => ArchiveFormat::Json,
+        }
+    }
+}
+
 /// Returns the version string, including git hash and commit date for non-release builds.
 /// Format for releases: "v0.8.10"
 /// Format for dev builds: "v0.8.10\ndev: abc1234 2024-01-15 14:30"
@@ -58,7 +164,7 @@ fn get_version() -> &'static str {
     bin_name = "padz",
     version = get_version(),
     disable_help_subcommand = true,
-    after_help = "Enable shell completions:\n  padz completion install"
+    after_help = "Enable shell completions:\n  padz completion --install"
 )]
 #[command(about = "Context-aware command-line note-taking tool", long_about = None)]
 pub struct Cli {
@@ -66,7 +172,7 @@ pub struct Cli {
     pub command: Option<Commands>,
 
     /// Operate on global pads
-    #[arg(short, long, global = true, conflicts_with = "data")]
+    #[arg(short, long, global = true, conflicts_with_all = ["data", "remote"])]
     pub global: bool,
 
     /// Verbose output
@@ -74,8 +180,32 @@ pub struct Cli {
     pub verbose: bool,
 
     /// Override data directory path (e.g., for git worktrees)
-    #[arg(long, global = true, value_name = "PATH", conflicts_with = "global")]
+    #[arg(long, global = true, value_name = "PATH", conflicts_with_all = ["global", "remote"])]
     pub data: Option<String>,
+
+    /// Read pads from a remote store: a name from `padz scopes add`, or ssh://host/path
+    #[arg(long, global = true, value_name = "REMOTE")]
+    pub remote: Option<String>,
+
+    /// Show what the command would do without writing to the store
+    #[arg(long, global = true)]
+    pub dry_run: bool,
+
+    /// List what can be read of a damaged store, warning about the rest, instead of failing
+    #[arg(long, global = true)]
+    pub ignore_errors: bool,
+
+    /// Edit or delete pads someone else created, when `owner_only_edit` is on
+    #[arg(long, global = true)]
+    pub force: bool,
+
+    /// Show examples of the command instead of running it
+    #[arg(long, global = true)]
+    pub examples: bool,
+
+    /// Run in a throwaway sandbox under $TMPDIR, for black-box tests
+    #[arg(long, global = true, hide = true, conflicts_with_all = ["data", "remote"])]
+    pub test_mode: bool,
 }
 
 // Help topics registry - loaded from topics directory
@@ -83,9 +213,14 @@ static HELP_TOPICS: Lazy<TopicRegistry> = Lazy::new(|| {
     let mut registry = TopicRegistry::new();
     // Topics are embedded at compile time from the topics directory
     // We manually add them since include_str! requires compile-time paths
-    let topic_content = include_str!("topics/scopes.txt");
-    if let Some(topic) = parse_topic_file("scopes", topic_content) {
-        registry.add_topic(topic);
+    let topics = [
+        ("scopes", include_str!("topics/scopes.txt")),
+        ("where", include_str!("topics/where.txt")),
+    ];
+    for (name, content) in topics {
+        if let Some(topic) = parse_topic_file(name, content) {
+            registry.add_topic(topic);
+        }
     }
     registry
 });
@@ -154,38 +289,81 @@ fn parse_topic_file(name: &str, content: &str) -> Option<standout::topics::Topic
     ))
 }
 
-/// Builds the clap Command for use with CompleteEnv.
-/// This is called by the completion system before normal parsing.
+/// Builds the clap Command.
 pub fn build_command() -> clap::Command {
     Cli::command()
 }
 
-/// Parses command-line arguments using standout's App.
-/// This handles help display (including topics) and errors automatically.
-/// It also adds the `--output` flag, which standout defines over the full mode set:
-/// `auto`, `term`, `text`, `term-debug`, `json`, `yaml`, `xml`, and `csv`.
-/// Returns the parsed CLI and the output mode extracted from the matches.
+/// Builds the clap Command for use with CompleteEnv, with each command's
+/// first example in its description (see [`super::examples::describe`]).
+/// This is called by the completion system before normal parsing.
+pub fn build_completion_command() -> clap::Command {
+    super::examples::describe(Cli::command())
+}
+
+/// Parses the process arguments with standout's App, which also handles help
+/// (including topics) and errors, and adds the `--output` flag over standout's
+/// full mode set: `auto`, `term`, `text`, `term-debug`, `json`, `yaml`, `xml`
+/// and `csv`.
+///
+/// Returns the parsed CLI, its output mode, the name of the subcommand invoked
+/// (`None` when padz falls back to its default command) and the options given
+/// on the command line ([`options_given`]); the last two feed the opt-in usage
+/// counter.
 ///
-/// The mode is extracted by standout's own [`App::extract_output_mode`] rather than by
-/// a local match. Standout owns the `--output` value set: a hand-written parser here is
-/// a copy that silently rots when standout adds a mode. It did — the local copy knew
-/// only `json`, so `--output yaml|xml|csv` parsed as valid clap values and then fell
-/// through to `Auto`, quietly rendering the human template (ANSI, glyphs, width
-/// truncation) to callers who had asked for machine-readable data. Delegating keeps the
-/// mode set defined in exactly one place.
-pub fn parse_cli() -> (Cli, OutputMode) {
+/// The mode comes from standout's own [`App::extract_output_mode`], not a local
+/// match: a local copy once knew only `json`, so `--output yaml|xml|csv` fell
+/// through to `Auto` and rendered the human template to callers who had asked
+/// for data. The mode set stays defined in one place.
+pub fn parse_cli() -> (Cli, OutputMode, Option<String>, Vec<String>) {
     // Intercept top-level help to show grouped output
     if should_show_custom_help() {
+        let args: Vec<String> = std::env::args().skip(1).collect();
+        print!("{}", super::help_context::header(&args));
         println!("{}", render_custom_help());
         std::process::exit(0);
     }
 
+    // `--examples` is answered before the arguments are validated, so the
+    // pads a command requires need not be named to see how it is used.
+    let args: Vec<String> = std::env::args().skip(1).collect();
+    if let Some(path) = super::examples::requested(&Cli::command(), &args) {
+        let path: Vec<&str> = path.iter().map(String::as_str).collect();
+        print!("{}", super::examples::render(&path));
+        std::process::exit(0);
+    }
+
     let app: App = app_with_topics();
     let matches = app.parse_with(Cli::command());
     let output_mode = app.extract_output_mode(&matches);
 
+    let command = matches.subcommand_name().map(str::to_string);
+    let options = options_given(&app.augment_command_with_help(Cli::command()), &matches);
+
     let cli = Cli::from_arg_matches(&matches).expect("Failed to parse CLI arguments");
-    (cli, output_mode)
+    (cli, output_mode, command, options)
+}
+
+/// The long names of the options given on the command line, however they
+/// were spelled (`-g` counts as `--global`), sorted and without their values:
+/// what the usage tally records besides the command.
+pub(super) fn options_given(cmd: &clap::Command, matches: &clap::ArgMatches) -> Vec<String> {
+    let mut given: Vec<String> = cmd
+        .get_arguments()
+        .filter(|arg| {
+            matches.value_source(arg.get_id().as_str())
+                == Some(clap::parser::ValueSource::CommandLine)
+        })
+        .filter_map(|arg| arg.get_long().map(|long| format!("--{long}")))
+        .collect();
+    if let Some((name, sub_matches)) = matches.subcommand() {
+        if let Some(sub) = cmd.find_subcommand(name) {
+            given.extend(options_given(sub, sub_matches));
+        }
+    }
+    given.sort();
+    given.dedup();
+    given
 }
 
 /// Returns the help output as a styled string (used for empty list display).
@@ -206,23 +384,39 @@ fn should_show_custom_help() -> bool {
         "search",
         "peek",
         "pk",
+        "recent",
+        "today",
+        "yesterday",
+        "journal",
+        "ref",
         "view",
         "v",
+        "fill",
+        "snip",
+        "speak",
         "edit",
         "e",
         "open",
         "o",
+        "append",
+        "prepend",
         "delete",
         "rm",
         "move",
         "mv",
         "restore",
+        "undo",
         "archive",
         "unarchive",
+        "name",
+        "link",
+        "timeline",
+        "which",
         "pin",
         "p",
         "unpin",
         "u",
+        "pinboard",
         "path",
         "uuid",
         "complete",
@@ -230,14 +424,32 @@ fn should_show_custom_help() -> bool {
         "reopen",
         "purge",
         "export",
+        "compile",
+        "print",
+        "publish",
+        "release-notes",
+        "verify",
         "import",
         "clone",
         "migrate",
         "tag",
         "doctor",
+        "maintain",
+        "encrypt",
+        "scopes",
+        "tree",
+        "stats",
+        "flush-queue",
         "config",
         "init",
+        "self-update",
         "completion",
+        "docs",
+        "tour",
+        "prompt-segment",
+        "explain",
+        "open-uri",
+        "mcp",
     ];
 
     // `padz help` with no further args
@@ -269,6 +481,13 @@ fn command_groups() -> Vec<CommandGroup> {
                 Some("create".into()),
                 Some("list".into()),
                 Some("search".into()),
+                Some("recent".into()),
+                Some("today".into()),
+                Some("yesterday".into()),
+                Some("journal".into()),
+                Some("ref".into()),
+                Some("snip".into()),
+                Some("release-notes".into()),
             ],
         },
         CommandGroup {
@@ -276,17 +495,26 @@ fn command_groups() -> Vec<CommandGroup> {
             help: Some("These commands accept one or more pad ids (<id>...)".into()),
             commands: vec![
                 Some("open".into()),
+                Some("append".into()),
+                Some("prepend".into()),
                 Some("view".into()),
                 Some("copy".into()),
+                Some("fill".into()),
                 Some("peek".into()),
+                Some("speak".into()),
                 Some("move".into()),
                 Some("delete".into()),
                 None,
                 Some("archive".into()),
                 Some("unarchive".into()),
+                Some("name".into()),
+                Some("link".into()),
+                Some("timeline".into()),
+                Some("which".into()),
                 None,
                 Some("pin".into()),
                 Some("unpin".into()),
+                Some("pinboard".into()),
                 Some("path".into()),
                 Some("uuid".into()),
                 None,
@@ -295,6 +523,10 @@ fn command_groups() -> Vec<CommandGroup> {
                 None,
                 Some("import".into()),
                 Some("export".into()),
+                Some("compile".into()),
+                Some("print".into()),
+                Some("publish".into()),
+                Some("verify".into()),
                 Some("clone".into()),
                 Some("migrate".into()),
                 None,
@@ -307,11 +539,25 @@ fn command_groups() -> Vec<CommandGroup> {
             commands: vec![
                 Some("purge".into()),
                 Some("restore".into()),
+                Some("undo".into()),
                 None,
                 Some("completion".into()),
+                Some("docs".into()),
+                Some("tour".into()),
+                Some("prompt-segment".into()),
+                Some("explain".into()),
+                Some("open-uri".into()),
+                Some("mcp".into()),
                 Some("help".into()),
                 Some("doctor".into()),
+                Some("maintain".into()),
+                Some("encrypt".into()),
+                Some("scopes".into()),
+                Some("tree".into()),
+                Some("stats".into()),
+                Some("flush-queue".into()),
                 Some("config".into()),
+                Some("self-update".into()),
             ],
         },
     ]
@@ -331,7 +577,7 @@ fn render_custom_help() -> String {
         .unwrap_or_else(|e| format!("Help rendering error: {}", e));
 
     help.push_str("\n\nEnable shell completions:\n");
-    help.push_str("  padz completion install");
+    help.push_str("  padz completion --install");
 
     help
 }
@@ -364,6 +610,27 @@ pub enum Commands {
         #[arg(long, short = 'f')]
         format: Option<String>,
 
+        /// Run <COMMAND> and open its output in the editor as the new pad
+        #[arg(long, value_name = "COMMAND")]
+        from: Option<String>,
+
+        /// Create the pad from the clipboard's text
+        #[arg(long, conflicts_with = "from")]
+        from_clipboard: bool,
+
+        /// Start from an empty pad, not the configured `create_template`
+        #[arg(long)]
+        blank: bool,
+
+        /// Title the pad from its surroundings when no title is given
+        #[arg(long, value_enum, value_name = "SOURCE")]
+        auto_title: Option<AutoTitle>,
+
+        /// Record the git branch checked out, for `list --branch` (even
+        /// without `capture_context`)
+        #[arg(long)]
+        branch: bool,
+
         /// Title words (joined with spaces, optional - opens empty editor if not provided)
         #[arg(trailing_var_arg = true)]
         title: Vec<String>,
@@ -420,8 +687,73 @@ pub enum Commands {
         /// Show status icons (even in notes mode)
         #[arg(long)]
         show_status: bool,
+
+        /// Reorder the listing (indexes are unchanged)
+        #[arg(long, value_enum)]
+        sort: Option<ListSort>,
+
+        /// Split the listing under a header per project, day or week
+        #[arg(long, value_enum)]
+        group_by: Option<ListGroupBy>,
+
+        /// Print only the number of pads listed
+        #[arg(long, conflicts_with_all = ["peek", "group_by"])]
+        count: bool,
+
+        /// Show only pads matching a query, e.g. 'project=webapp and created>30d'
+        /// (see `padz help where`)
+        #[arg(long = "where", value_name = "QUERY")]
+        query: Option<String>,
+
+        /// Stream pads as JSON Lines, one object per pad, each written as soon
+        /// as it is read (for very large stores)
+        #[arg(long, conflicts_with_all = ["search", "peek", "sort", "group_by", "count", "query"])]
+        jsonl: bool,
+
+        /// Show only pads created on the git branch checked out (see
+        /// `create --branch`)
+        #[arg(long, conflicts_with = "jsonl")]
+        branch: bool,
+
+        /// Keep the listing on screen, drawn again whenever the store changes
+        /// (Ctrl-C to stop)
+        #[arg(long, conflicts_with = "jsonl")]
+        watch: bool,
     },
 
+    /// List the most recently viewed, opened or peeked-at pads
+    #[command(display_order = 5)]
+    #[dispatch(pure, template = "list")]
+    Recent {
+        /// How many pads to show
+        #[arg(short = 'n', long, default_value_t = 10)]
+        limit: usize,
+
+        /// Show short UUIDs next to pad titles
+        #[arg(long)]
+        uuid: bool,
+    },
+
+    /// Open today's note in the journal, creating it if need be
+    #[command(display_order = 5)]
+    #[dispatch(pure, template = "modification_result")]
+    Today,
+
+    /// Open yesterday's note in the journal, creating it if need be
+    #[command(display_order = 5)]
+    #[dispatch(pure, template = "modification_result")]
+    Yesterday,
+
+    /// Daily notes kept by `today` and `yesterday`
+    #[command(subcommand, display_order = 5)]
+    #[dispatch(nested)]
+    Journal(JournalCommands),
+
+    /// List global pads in this project without copying them
+    #[command(subcommand, display_order = 5)]
+    #[dispatch(nested)]
+    Ref(RefCommands),
+
     /// Search pads (dedicated command)
     #[command(display_order = 3)]
     #[dispatch(pure, template = "list")]
@@ -452,6 +784,18 @@ pub enum Commands {
         /// Show short UUIDs next to pad titles
         #[arg(long)]
         uuid: bool,
+
+        /// Print only the number of matching pads (exits 1 when there are none)
+        #[arg(long, conflicts_with = "quiet")]
+        count: bool,
+
+        /// Print nothing: exit 0 if any pad matches, 1 if none does
+        #[arg(short, long)]
+        quiet: bool,
+
+        /// Search the global store and every registered project, grouped by store
+        #[arg(long)]
+        all_scopes: bool,
     },
 
     /// Peek at pad content previews
@@ -499,6 +843,26 @@ pub enum Commands {
         /// Recursively include children with 4-space indentation per level
         #[arg(long, conflicts_with_all = ["flat", "tree"])]
         indented: bool,
+
+        /// Decrypt `secret` fences in the output
+        #[arg(long)]
+        reveal: bool,
+
+        /// Open each linked file in the editor at its line
+        #[arg(long)]
+        open_links: bool,
+
+        /// Show when the pad was written and the git state it was created in
+        #[arg(long)]
+        meta: bool,
+
+        /// Highlight misspelled words (dictionary: `spell_language` config)
+        #[arg(long)]
+        spell: bool,
+
+        /// Run `{{shell "..."}}` directives in the pad and show their output
+        #[arg(long)]
+        exec: bool,
     },
 
     /// Copy one or more pads to the clipboard without printing their contents
@@ -524,6 +888,53 @@ pub enum Commands {
         /// Recursively include children with 4-space indentation per level
         #[arg(long, conflicts_with_all = ["flat", "tree"])]
         indented: bool,
+
+        /// Add to what the clipboard already holds instead of replacing it
+        #[arg(long)]
+        append: bool,
+    },
+
+    /// Fill in a pad's {{placeholder:name}} fill-ins; prints and copies the result
+    #[command(display_order = 10)]
+    #[dispatch(pure, template = "fill")]
+    Fill {
+        /// Index of the pad (e.g. 3 p1 ar2)
+        #[arg(add = all_pads_completer())]
+        index: String,
+
+        /// A placeholder's value instead of a prompt (can be repeated)
+        #[arg(long, value_name = "NAME=VALUE")]
+        set: Vec<String>,
+    },
+
+    /// Read pads aloud with the system's speech synthesizer (say, espeak)
+    #[command(display_order = 10)]
+    #[dispatch(pure, template = "speak")]
+    Speak {
+        /// Indexes of the pads (e.g. 1 p1 ar2)
+        #[arg(required = true, num_args = 1.., add = all_pads_completer())]
+        indexes: Vec<String>,
+
+        /// Record to this audio file instead (say: .aiff or .m4a; espeak: .wav)
+        #[arg(long, value_name = "FILE")]
+        to: Option<String>,
+
+        /// The synthesizer's voice (`say -v ?` or `espeak --voices` lists them)
+        #[arg(long)]
+        voice: Option<String>,
+
+        /// Speaking rate in words per minute
+        #[arg(long, value_name = "WPM")]
+        rate: Option<u32>,
+    },
+
+    /// Copy the `snippet`-tagged pad whose title best matches the query
+    #[command(display_order = 10)]
+    #[dispatch(pure, template = "snip")]
+    Snip {
+        /// Fuzzy title query (e.g. "dkrun" for "docker run")
+        #[arg(required = true, num_args = 1..)]
+        query: Vec<String>,
     },
 
     /// Edit a pad in the editor
@@ -533,6 +944,9 @@ pub enum Commands {
         /// Indexes of the pads (e.g. 1 p1 d1)
         #[arg(required = true, num_args = 1.., add = active_pads_completer())]
         indexes: Vec<String>,
+        /// List misspelled words before the editor opens
+        #[arg(long)]
+        spell: bool,
     },
 
     /// Open a pad in the editor (alias for edit)
@@ -545,6 +959,36 @@ pub enum Commands {
         /// Indexes of the pads (e.g. 1 p1 d1)
         #[arg(required = true, num_args = 1.., add = all_pads_completer())]
         indexes: Vec<String>,
+
+        /// List misspelled words before the editor opens
+        #[arg(long)]
+        spell: bool,
+    },
+
+    /// Add text to the end of a pad, from stdin or -m, without the editor
+    #[command(display_order = 12)]
+    #[dispatch(skip)]
+    Append {
+        /// Index of the pad (e.g. 1 p1)
+        #[arg(add = active_pads_completer())]
+        index: String,
+
+        /// The text to add, instead of reading stdin
+        #[arg(short = 'm', long, value_name = "TEXT")]
+        message: Option<String>,
+    },
+
+    /// Add text to the start of a pad, from stdin or -m; its first line becomes the title
+    #[command(display_order = 12)]
+    #[dispatch(skip)]
+    Prepend {
+        /// Index of the pad (e.g. 1 p1)
+        #[arg(add = active_pads_completer())]
+        index: String,
+
+        /// The text to add, instead of reading stdin
+        #[arg(short = 'm', long, value_name = "TEXT")]
+        message: Option<String>,
     },
 
     /// Delete one or more pads (protected pads must be unpinned first)
@@ -552,12 +996,16 @@ pub enum Commands {
     #[dispatch(pure, template = "modification_result")]
     Delete {
         /// Indexes of the pads (e.g. 1 3 5)
-        #[arg(num_args = 1.., add = active_pads_completer(), required_unless_present = "completed")]
+        #[arg(num_args = 1.., add = active_pads_completer(), required_unless_present_any = ["completed", "query"])]
         indexes: Vec<String>,
 
         /// Delete all pads marked as completed
         #[arg(long = "completed", conflicts_with = "indexes")]
         completed: bool,
+
+        /// Delete every pad matching a query, e.g. 'status=done and updated>2w'
+        #[arg(long = "where", value_name = "QUERY", conflicts_with_all = ["indexes", "completed"])]
+        query: Option<String>,
     },
 
     /// Restore deleted pads
@@ -569,6 +1017,11 @@ pub enum Commands {
         indexes: Vec<String>,
     },
 
+    /// Undo the last change a command made (run again to redo it)
+    #[command(display_order = 14)]
+    #[dispatch(pure, template = "undo")]
+    Undo,
+
     /// Archive pads (move to cold storage)
     #[command(display_order = 15)]
     #[dispatch(pure, template = "modification_result")]
@@ -587,6 +1040,58 @@ pub enum Commands {
         indexes: Vec<String>,
     },
 
+    /// Give a pad an alias usable anywhere an index is accepted
+    #[command(display_order = 16)]
+    #[dispatch(pure, template = "modification_result")]
+    Name {
+        /// Index of the pad (e.g. 3 p1 ar2)
+        #[arg(add = all_pads_completer())]
+        index: String,
+
+        /// The alias (letters, digits, '-' and '_'; e.g. runbook-tls)
+        #[arg(required_unless_present = "clear")]
+        alias: Option<String>,
+
+        /// Remove the pad's alias
+        #[arg(long, conflicts_with = "alias")]
+        clear: bool,
+    },
+
+    /// Link a pad to files and lines in the project (e.g. src/main.go:42)
+    #[command(display_order = 16)]
+    #[dispatch(pure, template = "modification_result")]
+    Link {
+        /// Index of the pad (e.g. 3 p1)
+        #[arg(add = active_pads_completer())]
+        index: String,
+
+        /// Locations as path or path:line, relative to the current directory
+        #[arg(required = true, num_args = 1.., value_hint = clap::ValueHint::FilePath)]
+        targets: Vec<String>,
+
+        /// Remove these locations instead of adding them
+        #[arg(long)]
+        remove: bool,
+    },
+
+    /// Show what happened to pads over time (created, edited, pinned, ...)
+    #[command(display_order = 16)]
+    #[dispatch(pure, template = "timeline")]
+    Timeline {
+        /// Indexes of the pads (e.g. 1 p1 d1)
+        #[arg(required = true, num_args = 1.., add = all_pads_completer())]
+        indexes: Vec<String>,
+    },
+
+    /// Show where pads live: scope, store, file, UUID, checksum and context
+    #[command(display_order = 16)]
+    #[dispatch(pure, template = "which")]
+    Which {
+        /// Indexes of the pads (e.g. 1 p1 d1)
+        #[arg(required = true, num_args = 1.., add = all_pads_completer())]
+        indexes: Vec<String>,
+    },
+
     /// Pin one or more pads (makes them delete-protected)
     #[command(alias = "p", display_order = 17)]
     #[dispatch(pure, template = "modification_result")]
@@ -594,6 +1099,10 @@ pub enum Commands {
         /// Indexes of the pads (e.g. 1 3 5)
         #[arg(required = true, num_args = 1.., add = active_pads_completer())]
         indexes: Vec<String>,
+
+        /// Pin past `max_pins` by unpinning the pads pinned longest ago
+        #[arg(long)]
+        evict: bool,
     },
 
     /// Unpin one or more pads
@@ -605,18 +1114,27 @@ pub enum Commands {
         indexes: Vec<String>,
     },
 
-    /// Move one or more pads to a new parent
+    /// Show pinned pads from the project and global scopes together
+    #[command(display_order = 18)]
+    #[dispatch(pure, template = "pinboard")]
+    Pinboard,
+
+    /// Move one or more pads to a new parent, or to another store
     #[command(alias = "mv", display_order = 13)]
     #[dispatch(pure, handler = handlers::move_pads__handler, template = "modification_result")]
     Move {
         /// Indexes of the pads (e.g. 1 2)
-        /// If --root is NOT specified, the last argument is the destination.
+        /// If neither --root nor --to is specified, the last argument is the destination.
         #[arg(required = true, num_args = 1.., add = active_pads_completer())]
         indexes: Vec<String>,
 
         /// Move to the root level (detach from any parent)
         #[arg(long, short = 'r')]
         root: bool,
+
+        /// Move to another store: global, project, a registered project's directory name, or a path
+        #[arg(long, value_name = "TARGET", conflicts_with = "root")]
+        to: Option<String>,
     },
 
     /// Print the file path to one or more pads
@@ -673,7 +1191,7 @@ pub enum Commands {
         recursive: bool,
     },
 
-    /// Export pads to a tar.gz archive (or single file with --single-file)
+    /// Export pads to a tar.gz archive (zip or json with --format, one file with --single-file)
     #[command(display_order = 21)]
     #[dispatch(pure, template = "export")]
     Export {
@@ -687,6 +1205,11 @@ pub enum Commands {
         #[arg(long, conflicts_with_all = ["single_file", "with_metadata"])]
         json: bool,
 
+        /// Export as one artifact preserving full metadata, like --json, in
+        /// the container named: tar.gz, zip, or json (a single bundle file)
+        #[arg(long, value_name = "FORMAT", conflicts_with_all = ["single_file", "json", "with_metadata", "into", "csv"])]
+        format: Option<ExportArchive>,
+
         /// Embed metadata inline in each exported file (md frontmatter /
         /// lex annotations). Exported files use each pad's native extension;
         /// .txt pads are exported without metadata (txt has no metadata
@@ -694,6 +1217,18 @@ pub enum Commands {
         #[arg(long = "with-metadata", conflicts_with_all = ["single_file", "json"])]
         with_metadata: bool,
 
+        /// With --json: write the archive's contents unpacked into this
+        /// directory, one pad at a time with progress, instead of building a
+        /// .tar.gz in memory. For very large stores; exports every active and
+        /// archived pad
+        #[arg(long, value_name = "DIR", requires = "json", conflicts_with_all = ["indexes", "sign"])]
+        into: Option<String>,
+
+        /// Continue an --into export that was interrupted, skipping the pads
+        /// its manifest already lists
+        #[arg(long, requires = "into")]
+        resume: bool,
+
         /// Indexes of the pads (e.g. 1 2) - if omitted, exports all active pads
         #[arg(required = false, num_args = 0.., add = active_pads_completer())]
         indexes: Vec<String>,
@@ -709,6 +1244,138 @@ pub enum Commands {
         /// Recursively include children with 4-space indentation per level
         #[arg(long, conflicts_with_all = ["flat", "tree"])]
         indented: bool,
+
+        /// Sign the export with gpg: writes the file and a detached `.asc`
+        /// signature next to it, in the current directory
+        #[arg(long)]
+        sign: bool,
+
+        /// Export every pad matching a query, e.g. 'tag=release'
+        #[arg(long = "where", value_name = "QUERY", conflicts_with_all = ["indexes", "into"])]
+        query: Option<String>,
+
+        /// Export the pads' metadata, without their bodies, as a CSV
+        /// spreadsheet with one row per pad
+        #[arg(long, conflicts_with_all = ["single_file", "json", "with_metadata", "into"])]
+        csv: bool,
+
+        /// With --csv: the columns, in order, from id, title, project,
+        /// created, updated, size, tags, status, pinned and parent
+        /// [default: id,title,project,created,size,tags]
+        #[arg(long, value_name = "FIELDS", requires = "csv")]
+        fields: Option<String>,
+    },
+
+    /// Assemble tagged pads into one markdown document (e.g. NOTES.md)
+    #[command(display_order = 21)]
+    #[dispatch(pure, template = "export")]
+    Compile {
+        /// Pads to include, in this order (filtered by --tag when both are given)
+        #[arg(required_unless_present = "tag", num_args = 0.., add = active_pads_completer())]
+        indexes: Vec<String>,
+
+        /// Include pads with this tag (can be repeated, uses AND logic)
+        #[arg(long, short = 't', num_args = 1..)]
+        tag: Vec<String>,
+
+        /// File to write; `.md` compiles markdown, anything else plain text.
+        /// (The global `--output-file-path` overrides it.)
+        #[arg(long, value_name = "FILE", default_value = "NOTES.md")]
+        to: String,
+
+        /// Top-level heading of the document
+        #[arg(long, default_value = "Notes")]
+        title: String,
+
+        /// Order of the pads (default: as given, else oldest first)
+        #[arg(long, value_enum)]
+        sort: Option<CompileSort>,
+
+        /// Reverse the order
+        #[arg(long)]
+        reverse: bool,
+    },
+
+    /// Print pads, their markdown laid out on paper, or write them as PostScript or PDF
+    #[command(display_order = 21)]
+    #[dispatch(pure, template = "export")]
+    Print {
+        /// Pads to print, each starting a new page
+        #[arg(required = true, num_args = 1.., add = active_pads_completer())]
+        indexes: Vec<String>,
+
+        /// Write this file instead of printing; `.pdf` writes PDF, anything
+        /// else PostScript
+        #[arg(long, value_name = "FILE", conflicts_with = "printer")]
+        to: Option<String>,
+
+        /// Printer to send to (default: the system's)
+        #[arg(long)]
+        printer: Option<String>,
+
+        /// Paper size (default: a4)
+        #[arg(long, value_enum)]
+        paper: Option<PrintPaper>,
+    },
+
+    /// Publish pads as GitHub gists or GitLab snippets, or send later edits
+    /// to where they were published
+    #[command(display_order = 21)]
+    #[dispatch(pure, template = "publish")]
+    Publish {
+        /// Pads to publish, one gist or snippet each; with --update, the
+        /// published pads to update (default: all of them)
+        #[arg(required_unless_present = "update", add = active_pads_completer())]
+        indexes: Vec<String>,
+
+        /// Publish as a secret GitHub gist (token: publish.github_token)
+        #[arg(long, conflicts_with = "gitlab_snippet", required_unless_present_any = ["gitlab_snippet", "update"])]
+        gist: bool,
+
+        /// Publish as a private GitLab snippet (token: publish.gitlab_token)
+        #[arg(long)]
+        gitlab_snippet: bool,
+
+        /// Upload the edits made since publishing; --gist or
+        /// --gitlab-snippet narrows it to that service
+        #[arg(long)]
+        update: bool,
+
+        /// Make a new gist or snippet public
+        #[arg(long, conflicts_with = "update")]
+        public: bool,
+    },
+
+    /// Draft release notes from the pads tagged for them since the last
+    /// release, with the commits made since
+    #[command(display_order = 21)]
+    #[dispatch(pure, template = "release_notes")]
+    ReleaseNotes {
+        /// Gather pads with this tag (can be repeated, uses AND logic)
+        #[arg(long, short = 't', num_args = 1.., default_value = "release")]
+        tag: Vec<String>,
+
+        /// The git tag or commit the last release was cut at (default: the
+        /// latest tag)
+        #[arg(long, value_name = "REF")]
+        since: Option<String>,
+    },
+
+    /// Check the store's pads against their recorded checksums, or an exported
+    /// file against its detached signature
+    #[command(display_order = 22)]
+    #[dispatch(pure, template = "verify")]
+    Verify {
+        /// An exported file to check; omit it to check the store
+        file: Option<String>,
+
+        /// The signature file (defaults to <FILE>.asc)
+        #[arg(long, value_name = "PATH", requires = "file")]
+        signature: Option<String>,
+
+        /// Accept the pads' current contents as correct, then check the store
+        #[arg(long, conflicts_with = "file")]
+        accept: bool,
     },
 
     /// Import files as pads
@@ -718,6 +1385,18 @@ pub enum Commands {
         /// Paths to files or directories to import
         #[arg(required = true, num_args = 1..)]
         paths: Vec<String>,
+
+        /// Import the subdirectories of a directory too (hidden ones, like .git, are skipped)
+        #[arg(long, short = 'r')]
+        recursive: bool,
+
+        /// Title each pad after its file name instead of its first line
+        #[arg(long)]
+        filename_title: bool,
+
+        /// Fail rather than fall back to the global store when there is no project store
+        #[arg(long, conflicts_with = "global")]
+        project: bool,
     },
 
     /// Copy pads to (or from) another padz store (source is kept)
@@ -766,6 +1445,44 @@ pub enum Commands {
     #[dispatch(pure, template = "doctor")]
     Doctor,
 
+    /// Remove files padz left behind that no pad refers to
+    #[command(display_order = 30)]
+    #[dispatch(pure, template = "maintain")]
+    Maintain,
+
+    /// Keep pad bodies encrypted on disk
+    #[command(subcommand, display_order = 30)]
+    #[dispatch(nested)]
+    Encrypt(EncryptCommands),
+
+    /// Manage the registry of project stores padz has opened
+    #[command(subcommand, display_order = 30)]
+    #[dispatch(nested)]
+    Scopes(ScopeCommands),
+
+    /// Show every store, with its pinned and most recent pads
+    #[command(display_order = 30)]
+    #[dispatch(pure, template = "tree")]
+    Tree,
+
+    /// Show pad counts, and local command usage when it is enabled
+    #[command(display_order = 30)]
+    #[dispatch(pure, template = "stats")]
+    Stats {
+        /// Include the local command usage tally (see the `usage_stats` config key)
+        #[arg(long)]
+        usage: bool,
+    },
+
+    /// Retry operations that failed for lack of network (uploads to `global_store`)
+    #[command(display_order = 30)]
+    #[dispatch(pure, template = "flush_queue")]
+    FlushQueue {
+        /// List what is pending without retrying
+        #[arg(long)]
+        list: bool,
+    },
+
     /// Manage configuration
     #[command(display_order = 31)]
     #[dispatch(skip)]
@@ -787,16 +1504,95 @@ pub enum Commands {
         unlink: bool,
     },
 
-    /// Shell completion setup
+    /// Install the latest padz release over this one
+    #[command(display_order = 33, name = "self-update")]
+    #[dispatch(pure, template = "self_update")]
+    SelfUpdate {
+        /// Only report whether a newer release exists
+        #[arg(long)]
+        check: bool,
+    },
+
+    /// Print the shell completion script, or install it with --install
     #[command(display_order = 34, name = "completion")]
     #[dispatch(skip)]
     Completion {
         /// Shell to target (auto-detected from $SHELL if omitted)
-        #[arg(long, short, value_enum)]
+        #[arg(value_enum)]
         shell: Option<CompletionShell>,
 
+        /// Write the script where the shell loads completions from
+        #[arg(long)]
+        install: bool,
+
+        /// Deprecated: name the shell as an argument instead
+        #[arg(
+            long = "shell",
+            short = 's',
+            value_enum,
+            hide = true,
+            conflicts_with = "shell"
+        )]
+        shell_flag: Option<CompletionShell>,
+
+        /// Deprecated: `install` and `print` are `--install` and the default
+        #[command(subcommand)]
+        action: Option<CompletionAction>,
+    },
+
+    /// Install padz's documentation (man pages)
+    #[command(display_order = 35)]
+    #[dispatch(skip)]
+    Docs {
         #[command(subcommand)]
-        action: CompletionAction,
+        action: DocsAction,
+    },
+
+    /// A guided tour of padz, run on a scratch store
+    #[command(display_order = 36)]
+    #[dispatch(skip)]
+    Tour {
+        /// Run every step without waiting for Enter
+        #[arg(long)]
+        no_pause: bool,
+    },
+
+    /// Print pad counts for a shell prompt, e.g. "✎3 ⚲1" (cached, quick)
+    #[command(display_order = 37, name = "prompt-segment")]
+    #[dispatch(skip)]
+    PromptSegment {},
+
+    /// Show what a command would do: the pads it resolves, the changes, files and listeners
+    #[command(display_order = 38)]
+    #[dispatch(skip)]
+    Explain {
+        /// The command, as it would follow `padz` (e.g. delete 1)
+        #[arg(
+            required = true,
+            num_args = 1..,
+            trailing_var_arg = true,
+            allow_hyphen_values = true,
+            value_name = "COMMAND"
+        )]
+        command: Vec<String>,
+    },
+
+    /// Serve the store to AI assistants over stdio, as a Model Context Protocol server
+    #[command(display_order = 40)]
+    #[dispatch(skip)]
+    Mcp {},
+
+    /// Open the pad a padz:// link points to; --install makes padz open those links
+    #[command(display_order = 39, name = "open-uri")]
+    #[dispatch(skip)]
+    OpenUri {
+        /// The pad's URI: padz://project/<uuid> or padz://global/<uuid>
+        #[arg(required_unless_present = "install", value_name = "URI")]
+        uri: Option<String>,
+
+        /// Register padz as the system's handler for padz:// links (macOS, Linux)
+        #[arg(long, conflicts_with = "uri")]
+        install: bool,
     },
 }
 
@@ -837,6 +1633,10 @@ pub enum TagCommands {
         /// Pad selectors followed by tag names (e.g. 1 2 feature work)
         #[arg(required = true, num_args = 1..)]
         args: Vec<String>,
+
+        /// Tag every pad matching a query instead; all arguments are tag names
+        #[arg(long = "where", value_name = "QUERY")]
+        query: Option<String>,
     },
 
     /// Remove tags from pads
@@ -846,6 +1646,10 @@ pub enum TagCommands {
         /// Pad selectors followed by tag names (e.g. 1 2 feature work)
         #[arg(required = true, num_args = 1..)]
         args: Vec<String>,
+
+        /// Untag every pad matching a query instead; all arguments are tag names
+        #[arg(long = "where", value_name = "QUERY")]
+        query: Option<String>,
     },
 
     /// Rename a tag (updates all pads)
@@ -876,13 +1680,89 @@ pub enum TagCommands {
     },
 }
 
+/// Journal subcommands
+#[derive(Subcommand, Dispatch, Debug)]
+#[dispatch(handlers = handlers::journal)]
+pub enum JournalCommands {
+    /// List the daily notes, newest first
+    #[command(alias = "list", display_order = 30)]
+    #[dispatch(pure, template = "journal")]
+    Ls,
+}
+
+/// Reference subcommands
+#[derive(Subcommand, Dispatch, Debug)]
+#[dispatch(handlers = handlers::refs)]
+pub enum RefCommands {
+    /// Add global pads to this project's listing; edits go to the global pad
+    #[command(display_order = 30)]
+    #[dispatch(pure, template = "modification_result")]
+    Add {
+        /// Global pad IDs (e.g. 1, 2 3, 1-3)
+        #[arg(required = true, num_args = 1..)]
+        indexes: Vec<String>,
+    },
+}
+
+/// Scope registry subcommands
+#[derive(Subcommand, Dispatch, Debug)]
+#[dispatch(handlers = handlers::scopes)]
+pub enum ScopeCommands {
+    /// Register a remote store (ssh://[user@]host[:port]/path) for `--remote <NAME>`
+    #[command(display_order = 30)]
+    #[dispatch(pure, template = "scope_remote")]
+    Add {
+        /// Name to use with --remote
+        name: String,
+        /// Address of the remote store
+        url: String,
+    },
+
+    /// Find registered projects whose directories no longer exist, and forget them
+    #[command(display_order = 30)]
+    #[dispatch(pure, template = "scope_prune")]
+    Prune {
+        /// Remove the orphans found (without it, they are only listed)
+        #[arg(long, short = 'y')]
+        yes: bool,
+    },
+}
+
+/// Encryption at rest subcommands
+#[derive(Subcommand, Dispatch, Debug)]
+#[dispatch(handlers = handlers::encrypt)]
+pub enum EncryptCommands {
+    /// Encrypt every pad body in the scope, and those written from now on
+    #[command(display_order = 30)]
+    #[dispatch(pure, template = "encrypt")]
+    Enable,
+
+    /// Decrypt every pad body in the scope, and write them in the clear again
+    #[command(display_order = 30)]
+    #[dispatch(pure, template = "encrypt")]
+    Disable,
+}
+
+/// Docs subcommands
+#[derive(Subcommand, Debug)]
+pub enum DocsAction {
+    /// Write man pages for padz and each subcommand (padz-list.1, ...)
+    InstallMan {
+        /// Directory to write them to (default: $XDG_DATA_HOME/man/man1)
+        #[arg(long, value_name = "DIR")]
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
 /// Completion subcommands
 #[derive(Subcommand, Debug)]
 pub enum CompletionAction {
     /// Install completion script to the standard location
+    #[command(hide = true)]
     Install,
 
     /// Print completion script to stdout
+    #[command(hide = true)]
     Print,
 }
 
@@ -893,63 +1773,121 @@ mod tests {
     use standout::cli::validate_command_groups;
 
     #[test]
-    fn test_completion_install_no_shell() {
-        let cli = Cli::try_parse_from(["padz", "completion", "install"]).unwrap();
+    fn test_completion_shell_argument() {
+        let cli = Cli::try_parse_from(["padz", "completion", "zsh"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::Completion {
+                shell: Some(CompletionShell::Zsh),
+                install: false,
+                shell_flag: None,
+                action: None,
+            })
+        ));
+    }
+
+    #[test]
+    fn test_completion_no_shell() {
+        let cli = Cli::try_parse_from(["padz", "completion"]).unwrap();
         assert!(matches!(
             cli.command,
             Some(Commands::Completion {
                 shell: None,
-                action: CompletionAction::Install,
+                install: false,
+                shell_flag: None,
+                action: None,
             })
         ));
     }
 
     #[test]
-    fn test_completion_install_with_shell() {
-        let cli =
-            Cli::try_parse_from(["padz", "completion", "--shell", "bash", "install"]).unwrap();
+    fn test_completion_install_flag() {
+        let cli = Cli::try_parse_from(["padz", "completion", "fish", "--install"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::Completion {
+                shell: Some(CompletionShell::Fish),
+                install: true,
+                shell_flag: None,
+                action: None,
+            })
+        ));
+
+        let cli = Cli::try_parse_from(["padz", "completion", "--install"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::Completion {
+                shell: None,
+                install: true,
+                ..
+            })
+        ));
+    }
+
+    #[test]
+    fn test_completion_legacy_install_no_shell() {
+        let cli = Cli::try_parse_from(["padz", "completion", "install"]).unwrap();
         assert!(matches!(
             cli.command,
             Some(Commands::Completion {
-                shell: Some(CompletionShell::Bash),
-                action: CompletionAction::Install,
+                shell: None,
+                shell_flag: None,
+                action: Some(CompletionAction::Install),
+                ..
             })
         ));
     }
 
     #[test]
-    fn test_completion_print() {
-        let cli = Cli::try_parse_from(["padz", "completion", "print"]).unwrap();
+    fn test_completion_legacy_install_with_shell() {
+        let cli =
+            Cli::try_parse_from(["padz", "completion", "--shell", "bash", "install"]).unwrap();
         assert!(matches!(
             cli.command,
             Some(Commands::Completion {
                 shell: None,
-                action: CompletionAction::Print,
+                shell_flag: Some(CompletionShell::Bash),
+                action: Some(CompletionAction::Install),
+                ..
             })
         ));
     }
 
     #[test]
-    fn test_completion_print_with_shell() {
+    fn test_completion_legacy_print_with_shell() {
         let cli = Cli::try_parse_from(["padz", "completion", "--shell", "zsh", "print"]).unwrap();
         assert!(matches!(
             cli.command,
             Some(Commands::Completion {
-                shell: Some(CompletionShell::Zsh),
-                action: CompletionAction::Print,
+                shell: None,
+                shell_flag: Some(CompletionShell::Zsh),
+                action: Some(CompletionAction::Print),
+                ..
             })
         ));
     }
 
     #[test]
-    fn test_completion_install_fish() {
+    fn test_completion_rejects_two_shells() {
+        assert!(Cli::try_parse_from(["padz", "completion", "bash", "--shell", "zsh"]).is_err());
+    }
+
+    #[test]
+    fn test_docs_install_man() {
+        let cli = Cli::try_parse_from(["padz", "docs", "install-man"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::Docs {
+                action: DocsAction::InstallMan { dir: None },
+            })
+        ));
+
         let cli =
-            Cli::try_parse_from(["padz", "completion", "--shell", "fish", "install"]).unwrap();
+            Cli::try_parse_from(["padz", "docs", "install-man", "--dir", "/tmp/man1"]).unwrap();
         assert!(matches!(
             cli.command,
-            Some(Commands::Completion {
-                shell: Some(CompletionShell::Fish),
-                action: CompletionAction::Install,
+            Some(Commands::Docs {
+                action: DocsAction::InstallMan { dir: Some(_) },
             })
         ));
     }
@@ -962,6 +1900,131 @@ mod tests {
         validate_command_groups(&cmd, &command_groups()).unwrap();
     }
 
+    #[test]
+    fn test_list_sort_accessed_parses() {
+        let cli = Cli::try_parse_from(["padz", "list", "--sort", "accessed"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::List {
+                sort: Some(ListSort::Accessed),
+                ..
+            })
+        ));
+    }
+
+    #[test]
+    fn test_branch_flags_parse() {
+        let cli = Cli::try_parse_from(["padz", "create", "--branch", "Login", "notes"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::Create { branch: true, ref title, .. }) if title == &["Login", "notes"]
+        ));
+        let cli = Cli::try_parse_from(["padz", "ls", "--branch"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::List { branch: true, .. })
+        ));
+        assert!(Cli::try_parse_from(["padz", "ls", "--branch", "--jsonl"]).is_err());
+    }
+
+    #[test]
+    fn test_list_watch_does_not_stream() {
+        let cli = Cli::try_parse_from(["padz", "ls", "--watch", "--peek"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::List {
+                watch: true,
+                peek: true,
+                ..
+            })
+        ));
+        assert!(Cli::try_parse_from(["padz", "ls", "--watch", "--jsonl"]).is_err());
+    }
+
+    #[test]
+    fn test_compile_needs_tags_or_indexes() {
+        let cli =
+            Cli::try_parse_from(["padz", "compile", "--tag", "docs", "--sort", "title"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::Compile {
+                sort: Some(CompileSort::Title),
+                ref to,
+                ..
+            }) if to == "NOTES.md"
+        ));
+        assert!(Cli::try_parse_from(["padz", "compile"]).is_err());
+    }
+
+    #[test]
+    fn test_speak_takes_a_voice_and_rate() {
+        let cli =
+            Cli::try_parse_from(["padz", "speak", "2", "--voice", "en", "--rate", "160"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::Speak {
+                rate: Some(160),
+                to: None,
+                ..
+            })
+        ));
+        assert!(Cli::try_parse_from(["padz", "speak"]).is_err());
+    }
+
+    #[test]
+    fn test_print_writes_a_file_or_prints() {
+        let cli = Cli::try_parse_from(["padz", "print", "1", "--to", "list.pdf"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::Print { ref to, paper: None, .. }) if to.as_deref() == Some("list.pdf")
+        ));
+        assert!(Cli::try_parse_from(["padz", "print"]).is_err());
+        assert!(
+            Cli::try_parse_from(["padz", "print", "1", "--to", "a.ps", "--printer", "office"])
+                .is_err()
+        );
+    }
+
+    #[test]
+    fn test_publish_needs_a_service_or_update() {
+        let cli = Cli::try_parse_from(["padz", "publish", "2", "--gist"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::Publish {
+                gist: true,
+                update: false,
+                ..
+            })
+        ));
+        let cli = Cli::try_parse_from(["padz", "publish", "--update"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::Publish { ref indexes, update: true, .. }) if indexes.is_empty()
+        ));
+        assert!(Cli::try_parse_from(["padz", "publish", "2"]).is_err());
+        assert!(Cli::try_parse_from(["padz", "publish", "--gist"]).is_err());
+        assert!(
+            Cli::try_parse_from(["padz", "publish", "2", "--gist", "--gitlab-snippet"]).is_err()
+        );
+        assert!(Cli::try_parse_from(["padz", "publish", "--update", "--public"]).is_err());
+    }
+
+    #[test]
+    fn test_release_notes_gather_the_release_tag_by_default() {
+        let cli = Cli::try_parse_from(["padz", "release-notes"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::ReleaseNotes { ref tag, since: None }) if tag == &["release"]
+        ));
+        let cli = Cli::try_parse_from(["padz", "release-notes", "-t", "api", "--since", "v1.2.0"])
+            .unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::ReleaseNotes { ref tag, since: Some(ref since) })
+                if tag == &["api"] && since == "v1.2.0"
+        ));
+    }
+
     #[test]
     fn test_data_option_parses() {
         let cli = Cli::try_parse_from(["padz", "--data", "/path/to/.padz", "list"]).unwrap();
@@ -1002,6 +2065,35 @@ mod tests {
         assert_eq!(app.extract_output_mode(&matches), OutputMode::Json);
     }
 
+    #[test]
+    fn test_options_given_are_long_names_without_values() {
+        let app = app_with_topics();
+        let command = app.augment_command_with_help(Cli::command());
+        let matches = command
+            .clone()
+            .try_get_matches_from([
+                "padz",
+                "-g",
+                "list",
+                "--peek",
+                "--output",
+                "json",
+                "groceries",
+            ])
+            .unwrap();
+
+        assert_eq!(
+            options_given(&command, &matches),
+            vec!["--global", "--output", "--peek"]
+        );
+
+        let matches = command
+            .clone()
+            .try_get_matches_from(["padz", "list"])
+            .unwrap();
+        assert!(options_given(&command, &matches).is_empty());
+    }
+
     #[test]
     fn test_init_link_and_unlink_conflict_at_the_parser_seam() {
         let result = Cli::try_parse_from([
@@ -0,0 +1,169 @@
+//! Detached signatures for exports.
+//!
+//! A user-environment concern owned by the CLI, like the clipboard: signing
+//! needs the user's private key, so padz shells out to `gpg` rather than
+//! holding keys itself. The library never signs — it only reports the files a
+//! signed export produced. The CLI injects a [`Signer`] into application state
+//! so in-process tests can sign and verify without a keyring.
+//!
+//! Signatures are ASCII-armored and written next to the file they cover, as
+//! `<file>.asc`. They are made with the key of `identity.email` when there is
+//! one (see [`super::identity`]), else with gpg's default key.
+
+use super::subprocess::{self, Limit};
+use super::views::{SignatureCheck, SignatureStatus};
+use padzapp::error::{PadzError, Result};
+use std::io::Write;
+use std::path::{Path, PathBuf};
+use std::process::{Command, Stdio};
+
+/// Extension of a detached signature file.
+pub const SIGNATURE_EXT: &str = "asc";
+
+/// A CLI-owned producer and checker of detached signatures.
+pub trait Signer {
+    /// An armored detached signature over `bytes`, made with the default key.
+    fn detach_sign(&self, bytes: &[u8]) -> Result<String>;
+
+    /// Check `signature` against the contents of `file`.
+    fn verify(&self, file: &Path, signature: &Path) -> Result<SignatureCheck>;
+}
+
+/// The user's `gpg` and keyring, signing with the secret key of `local_user`
+/// (gpg's `--local-user`: an email, name or key id), or the default key.
+#[derive(Debug, Default)]
+pub struct GpgSigner {
+    pub local_user: Option<String>,
+}
+
+impl Signer for GpgSigner {
+    fn detach_sign(&self, bytes: &[u8]) -> Result<String> {
+        let mut command = Command::new("gpg");
+        if let Some(user) = &self.local_user {
+            command.arg("--local-user").arg(user);
+        }
+        let mut child = command
+            .args(["--detach-sign", "--armor", "--output", "-"])
+            .stdin(Stdio::piped())
+            .stdout(Stdio::piped())
+            .spawn()
+            .map_err(|e| PadzError::Api(format!("Failed to spawn gpg: {}", e)))?;
+
+        if let Some(mut stdin) = child.stdin.take() {
+            stdin
+                .write_all(bytes)
+                .map_err(|e| PadzError::Api(format!("Failed to write to gpg: {}", e)))?;
+        }
+
+        let output = subprocess::wait(child, Limit::Command, "gpg")?;
+
+        if !output.status.success() {
+            return Err(PadzError::Api("gpg could not sign the export".to_string()));
+        }
+        String::from_utf8(output.stdout)
+            .map_err(|_| PadzError::Api("gpg returned a non-text signature".to_string()))
+    }
+
+    fn verify(&self, file: &Path, signature: &Path) -> Result<SignatureCheck> {
+        // `--status-fd 1` gives machine-readable `[GNUPG:]` lines on stdout; the
+        // exit status alone cannot tell a bad signature from a missing key.
+        let output = subprocess::output(
+            Command::new("gpg")
+                .args(["--status-fd", "1", "--verify"])
+                .arg(signature)
+                .arg(file),
+            Limit::Command,
+            "gpg",
+        )?;
+
+        let (status, signer) = parse_status(&String::from_utf8_lossy(&output.stdout));
+        Ok(SignatureCheck {
+            file: file.to_path_buf(),
+            signature: signature.to_path_buf(),
+            status,
+            signer,
+        })
+    }
+}
+
+/// Where the detached signature for `file` lives.
+pub fn signature_path(file: &Path) -> PathBuf {
+    let mut name = file.as_os_str().to_os_string();
+    name.push(".");
+    name.push(SIGNATURE_EXT);
+    PathBuf::from(name)
+}
+
+/// Read gpg's `--status-fd` output into a verdict and the signer's user id.
+///
+/// A `GOODSIG` only counts with its `VALIDSIG` companion; anything else gpg
+/// reports is a failure of one kind or another.
+fn parse_status(status: &str) -> (SignatureStatus, Option<String>) {
+    let mut good_uid = None;
+    let mut valid = false;
+    for line in status.lines() {
+        let Some(rest) = line.strip_prefix("[GNUPG:] ") else {
+            continue;
+        };
+        let mut parts = rest.splitn(3, ' ');
+        match parts.next() {
+            Some("GOODSIG") => good_uid = parts.nth(1).map(str::to_string),
+            Some("VALIDSIG") => valid = true,
+            Some("BADSIG") => return (SignatureStatus::Bad, parts.nth(1).map(str::to_string)),
+            Some("NO_PUBKEY") => return (SignatureStatus::UnknownKey, None),
+            _ => {}
+        }
+    }
+    match (good_uid, valid) {
+        (Some(uid), true) => (SignatureStatus::Good, Some(uid)),
+        _ => (SignatureStatus::Invalid, None),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn signature_sits_next_to_the_file() {
+        assert_eq!(
+            signature_path(Path::new("out/padz-export.tar.gz")),
+            PathBuf::from("out/padz-export.tar.gz.asc")
+        );
+    }
+
+    #[test]
+    fn good_signature_reports_the_signer() {
+        let status = "[GNUPG:] NEWSIG\n\
+            [GNUPG:] GOODSIG 0123456789ABCDEF Ada Lovelace <ada@example.com>\n\
+            [GNUPG:] VALIDSIG FINGERPRINT 2024-01-01 1704067200 0 4 0 1 10 00 FINGERPRINT\n";
+
+        assert_eq!(
+            parse_status(status),
+            (
+                SignatureStatus::Good,
+                Some("Ada Lovelace <ada@example.com>".to_string())
+            )
+        );
+    }
+
+    #[test]
+    fn tampered_file_is_bad() {
+        let status = "[GNUPG:] BADSIG 0123456789ABCDEF Ada Lovelace <ada@example.com>\n";
+
+        assert_eq!(parse_status(status).0, SignatureStatus::Bad);
+    }
+
+    #[test]
+    fn missing_public_key_is_distinguished() {
+        let status = "[GNUPG:] ERRSIG 0123456789ABCDEF 1 10 00 1704067200 9 -\n\
+            [GNUPG:] NO_PUBKEY 0123456789ABCDEF\n";
+
+        assert_eq!(parse_status(status).0, SignatureStatus::UnknownKey);
+    }
+
+    #[test]
+    fn no_verdict_is_invalid() {
+        assert_eq!(parse_status("").0, SignatureStatus::Invalid);
+    }
+}
@@ -0,0 +1,322 @@
+//! `padz speak`: reading pads aloud with the system's speech synthesizer.
+//!
+//! A pad is first turned into what a listener needs ([`script`]): markdown
+//! markers dropped, tasks read as "To do" or "Done", links as their text and
+//! fenced code announced rather than spelled out. Every line ends in a stop, so
+//! a list is heard as a list. The text is then cut into chunks of whole
+//! sentences, and each chunk is one run of the synthesizer: speech starts
+//! after the first chunk rather than the whole pad, Ctrl-C stops between two,
+//! and no run outlasts `command_timeout` (see [`super::subprocess`]).
+//!
+//! The synthesizer is `say` on macOS and `espeak-ng` or `espeak` elsewhere.
+//! `--to FILE` records the pads in one run instead: `say` picks the audio
+//! format from the extension (`.aiff`, `.m4a`), espeak writes WAV.
+
+use super::subprocess::{self, Limit};
+use padzapp::error::{PadzError, Result};
+use std::io::Write;
+use std::path::Path;
+use std::process::{Command, Stdio};
+
+/// The longest chunk spoken in one run, in characters: half a minute of
+/// speech at the slowest common rates.
+const CHUNK: usize = 240;
+
+/// How the synthesizer should sound. `None` leaves its default.
+#[derive(Debug, Clone, Default)]
+pub struct Voice {
+    pub name: Option<String>,
+    /// Words per minute.
+    pub rate: Option<u32>,
+}
+
+/// A speech synthesizer and the flags it takes.
+struct Engine {
+    program: &'static str,
+    /// Read the text from stdin.
+    stdin: &'static [&'static str],
+    voice: &'static str,
+    rate: &'static str,
+    /// Write the audio to the file that follows.
+    file: &'static str,
+}
+
+#[cfg(target_os = "macos")]
+const ENGINES: &[Engine] = &[Engine {
+    program: "say",
+    stdin: &["-f", "-"],
+    voice: "-v",
+    rate: "-r",
+    file: "-o",
+}];
+
+#[cfg(not(target_os = "macos"))]
+const ENGINES: &[Engine] = &[
+    Engine {
+        program: "espeak-ng",
+        stdin: &["--stdin"],
+        voice: "-v",
+        rate: "-s",
+        file: "-w",
+    },
+    Engine {
+        program: "espeak",
+        stdin: &["--stdin"],
+        voice: "-v",
+        rate: "-s",
+        file: "-w",
+    },
+];
+
+/// Speaks `chunks` one after the other.
+pub fn speak(chunks: &[String], voice: &Voice) -> Result<()> {
+    let engine = engine()?;
+    let cancellation = super::interrupt::cancellation();
+    for chunk in chunks {
+        cancellation.check()?;
+        run(engine, voice, chunk, None)?;
+    }
+    Ok(())
+}
+
+/// Records `chunks` into the audio file `path`.
+pub fn record(chunks: &[String], voice: &Voice, path: &Path) -> Result<()> {
+    run(engine()?, voice, &chunks.join("\n"), Some(path))
+}
+
+/// The first synthesizer on `PATH`.
+fn engine() -> Result<&'static Engine> {
+    let path = std::env::var_os("PATH").unwrap_or_default();
+    ENGINES
+        .iter()
+        .find(|engine| std::env::split_paths(&path).any(|dir| dir.join(engine.program).is_file()))
+        .ok_or_else(|| {
+            let names: Vec<&str> = ENGINES.iter().map(|e| e.program).collect();
+            PadzError::Api(format!(
+                "No speech synthesizer found; padz speaks with {}",
+                names.join(" or ")
+            ))
+        })
+}
+
+fn run(engine: &Engine, voice: &Voice, text: &str, file: Option<&Path>) -> Result<()> {
+    let mut command = Command::new(engine.program);
+    if let Some(name) = &voice.name {
+        command.arg(engine.voice).arg(name);
+    }
+    if let Some(rate) = voice.rate {
+        command.arg(engine.rate).arg(rate.to_string());
+    }
+    if let Some(file) = file {
+        command.arg(engine.file).arg(file);
+    }
+    let mut child = command
+        .args(engine.stdin)
+        .stdin(Stdio::piped())
+        .stderr(Stdio::piped())
+        .spawn()
+        .map_err(|e| PadzError::Api(format!("Failed to run {}: {}", engine.program, e)))?;
+    if let Some(mut stdin) = child.stdin.take() {
+        stdin
+            .write_all(text.as_bytes())
+            .map_err(|e| PadzError::Api(format!("Failed to write to {}: {}", engine.program, e)))?;
+    }
+    let output = subprocess::wait(child, Limit::Command, engine.program)?;
+    if !output.status.success() {
+        return Err(PadzError::Api(format!(
+            "{} failed: {}",
+            engine.program,
+            String::from_utf8_lossy(&output.stderr).trim()
+        )));
+    }
+    Ok(())
+}
+
+/// A pad read for the ear, in chunks of whole sentences no longer than
+/// [`CHUNK`] (a longer sentence is cut between words).
+pub fn script(title: &str, body: &str) -> Vec<String> {
+    let mut sentences = vec![stop(plain(title))];
+    let mut fenced = false;
+    for line in body.lines() {
+        let line = line.trim();
+        if line.starts_with("```") || line.starts_with("~~~") {
+            if !fenced {
+                sentences.push("Code block skipped.".to_string());
+            }
+            fenced = !fenced;
+            continue;
+        }
+        if fenced || line.is_empty() || is_rule(line) {
+            continue;
+        }
+        let line = line.trim_start_matches('#').trim_start();
+        let line = line.strip_prefix('>').map_or(line, str::trim_start);
+        let spoken = match item(line) {
+            (Some(task), text) => format!("{}: {}", task, plain(text)),
+            (None, text) => plain(text),
+        };
+        if !spoken.is_empty() {
+            sentences.extend(split_sentences(&stop(spoken)));
+        }
+    }
+    chunks(sentences)
+}
+
+/// A list item's text, and for a task how it is announced.
+fn item(line: &str) -> (Option<&'static str>, &str) {
+    let Some(rest) = ["- ", "* ", "+ "]
+        .iter()
+        .find_map(|m| line.strip_prefix(m))
+        .or_else(|| {
+            let digits = line.chars().take_while(|c| c.is_ascii_digit()).count();
+            (digits > 0)
+                .then(|| &line[digits..])
+                .and_then(|rest| rest.strip_prefix(". ").or_else(|| rest.strip_prefix(") ")))
+        })
+    else {
+        return (None, line);
+    };
+    for (task, said) in [("[ ]", "To do"), ("[x]", "Done"), ("[X]", "Done")] {
+        if let Some(text) = rest.strip_prefix(task) {
+            return (Some(said), text.trim_start());
+        }
+    }
+    (None, rest.trim_start())
+}
+
+fn is_rule(line: &str) -> bool {
+    let compact: String = line.chars().filter(|c| !c.is_whitespace()).collect();
+    compact.len() >= 3
+        && ['-', '*', '_']
+            .iter()
+            .any(|m| compact.chars().all(|c| c == *m))
+}
+
+/// `text` without inline markup; a link is read as its text.
+fn plain(text: &str) -> String {
+    let mut out = String::with_capacity(text.len());
+    let mut rest = text;
+    while let Some(open) = rest.find('[') {
+        let link = rest[open + 1..]
+            .split_once("](")
+            .and_then(|(label, tail)| tail.find(')').map(|close| (label, &tail[close + 1..])));
+        match link {
+            Some((label, tail)) => {
+                out.push_str(&rest[..open]);
+                out.push_str(label);
+                rest = tail;
+            }
+            None => {
+                out.push_str(&rest[..=open]);
+                rest = &rest[open + 1..];
+            }
+        }
+    }
+    out.push_str(rest);
+    out.replace("**", "")
+        .replace("__", "")
+        .replace('`', "")
+        .trim()
+        .to_string()
+}
+
+/// `text` ending in a stop, so the synthesizer pauses after it.
+fn stop(mut text: String) -> String {
+    if !text.ends_with(['.', '!', '?', ':', ';']) {
+        text.push('.');
+    }
+    text
+}
+
+/// Splits after each `.`, `!` or `?` that a space follows.
+fn split_sentences(text: &str) -> Vec<String> {
+    let mut sentences = Vec::new();
+    let mut start = 0;
+    let chars: Vec<(usize, char)> = text.char_indices().collect();
+    for window in chars.windows(2) {
+        let ((i, c), (_, next)) = (window[0], window[1]);
+        if matches!(c, '.' | '!' | '?') && next == ' ' {
+            sentences.push(text[start..=i].trim().to_string());
+            start = i + 1;
+        }
+    }
+    let last = text[start..].trim();
+    if !last.is_empty() {
+        sentences.push(last.to_string());
+    }
+    sentences
+}
+
+/// Groups `sentences` into chunks of at most [`CHUNK`] characters.
+fn chunks(sentences: Vec<String>) -> Vec<String> {
+    let mut chunks: Vec<String> = Vec::new();
+    let mut chunk = String::new();
+    for sentence in sentences {
+        for piece in cut(&sentence) {
+            if !chunk.is_empty() && chunk.chars().count() + 1 + piece.chars().count() > CHUNK {
+                chunks.push(std::mem::take(&mut chunk));
+            }
+            if !chunk.is_empty() {
+                chunk.push(' ');
+            }
+            chunk.push_str(&piece);
+        }
+    }
+    if !chunk.is_empty() {
+        chunks.push(chunk);
+    }
+    chunks
+}
+
+/// A sentence longer than [`CHUNK`], cut between words.
+fn cut(sentence: &str) -> Vec<String> {
+    let mut pieces = Vec::new();
+    let mut piece = String::new();
+    for word in sentence.split_whitespace() {
+        if !piece.is_empty() && piece.chars().count() + 1 + word.chars().count() > CHUNK {
+            pieces.push(std::mem::take(&mut piece));
+        }
+        if !piece.is_empty() {
+            piece.push(' ');
+        }
+        piece.push_str(word);
+    }
+    if !piece.is_empty() {
+        pieces.push(piece);
+    }
+    pieces
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn a_pad_is_read_without_its_markup() {
+        let body = "## Before Friday\n- [ ] call **Ana**\n- [x] book the [venue](https://example.com)\n\n```\nrm -rf /\n```\n---\n1. Bring cake";
+        assert_eq!(
+            script("Party", body),
+            [
+                "Party. Before Friday. To do: call Ana. Done: book the venue. \
+              Code block skipped. Bring cake."
+            ]
+        );
+    }
+
+    #[test]
+    fn long_text_is_chunked_between_sentences() {
+        let sentence = "This sentence is about fifty characters in length.";
+        let body = vec![sentence; 10].join(" ");
+        let chunks = script("Long", &body);
+        assert!(chunks.len() > 1);
+        for chunk in &chunks {
+            assert!(chunk.chars().count() <= CHUNK);
+            assert!(chunk.ends_with('.'));
+        }
+        assert_eq!(chunks.join(" "), format!("Long. {}", body));
+
+        let run_on = vec!["word"; 100].join(" ");
+        let chunks = script("Run-on", &run_on);
+        assert!(chunks.iter().all(|c| c.chars().count() <= CHUNK));
+    }
+}
@@ -0,0 +1,96 @@
+//! Finding the spell-check dictionary on this machine.
+//!
+//! `padzapp::spell` checks text against a word set; where the words come from
+//! is decided here. For `spell_language = "<lang>"` the dictionary is the first
+//! of:
+//!
+//! 1. `<config dir>/dictionaries/<lang>.dic`, then `<lang>.txt` — a plain word
+//!    list or a hunspell `.dic`;
+//! 2. for English only, the system word list (`/usr/share/dict/words`).
+//!
+//! `<config dir>/dictionaries/personal.dic`, when present, is added on top:
+//! the place for names and project jargon. The config dir is padz's global
+//! data directory, where the global `padz.toml` lives.
+
+use padzapp::spell::Dictionary;
+use std::path::{Path, PathBuf};
+
+/// The word list most Unix systems ship.
+const SYSTEM_WORDS: &str = "/usr/share/dict/words";
+
+/// The personal dictionary's file name, next to the language dictionaries.
+const PERSONAL: &str = "personal.dic";
+
+/// Where the dictionaries for padz live under `config_dir`.
+pub fn dictionaries_dir(config_dir: &Path) -> PathBuf {
+    config_dir.join("dictionaries")
+}
+
+/// Loads the dictionary for `language`, plus the personal dictionary.
+pub fn load(config_dir: &Path, language: &str) -> Result<Dictionary, String> {
+    load_with_system(config_dir, language, Path::new(SYSTEM_WORDS))
+}
+
+/// [`load`] with the system word list at `system_words`, so the lookup order
+/// can be tested without depending on what the machine has installed.
+fn load_with_system(
+    config_dir: &Path,
+    language: &str,
+    system_words: &Path,
+) -> Result<Dictionary, String> {
+    let dir = dictionaries_dir(config_dir);
+    let mut candidates = vec![
+        dir.join(format!("{language}.dic")),
+        dir.join(format!("{language}.txt")),
+    ];
+    if language == "en" || language.starts_with("en_") || language.starts_with("en-") {
+        candidates.push(system_words.to_path_buf());
+    }
+
+    let mut dictionary = candidates
+        .iter()
+        .find_map(|path| std::fs::read_to_string(path).ok())
+        .map(|list| Dictionary::parse(&list))
+        .ok_or_else(|| {
+            format!(
+                "No dictionary for '{}': put a word list at {}",
+                language,
+                candidates[0].display()
+            )
+        })?;
+    if let Ok(personal) = std::fs::read_to_string(dir.join(PERSONAL)) {
+        dictionary.extend(Dictionary::parse(&personal));
+    }
+    Ok(dictionary)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn a_language_file_wins_and_the_personal_list_is_added() {
+        let temp = tempfile::tempdir().unwrap();
+        let dir = dictionaries_dir(temp.path());
+        std::fs::create_dir_all(&dir).unwrap();
+        std::fs::write(dir.join("pt.dic"), "2\nolá\nmundo/S\n").unwrap();
+        std::fs::write(dir.join(PERSONAL), "padz\n").unwrap();
+
+        let dict = load_with_system(temp.path(), "pt", Path::new("/nonexistent")).unwrap();
+        assert!(dict.contains("mundo"));
+        assert!(dict.contains("padz"));
+    }
+
+    #[test]
+    fn english_falls_back_to_the_system_list() {
+        let temp = tempfile::tempdir().unwrap();
+        let system = temp.path().join("words");
+        std::fs::write(&system, "hello\n").unwrap();
+
+        let dict = load_with_system(temp.path(), "en_GB", &system).unwrap();
+        assert!(dict.contains("hello"));
+
+        let err = load_with_system(temp.path(), "de", &system).unwrap_err();
+        assert!(err.contains("de.dic"), "got: {err}");
+    }
+}
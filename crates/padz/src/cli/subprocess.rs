@@ -0,0 +1,206 @@
+//! Waiting on external programs, within a time limit.
+//!
+//! Every program padz runs can hang: a linter waiting on stdin, a `{{shell}}`
+//! directive that never returns, gpg prompting for a passphrase nobody will
+//! type, an `aws` call on a dead connection. Each waits here instead, bounded
+//! by the config key of its [`Limit`]; past it the program is killed and the
+//! caller gets [`PadzError::TimedOut`], naming the key to raise.
+//!
+//! The limits are read from config once, by `build_app_state` ([`configure`]);
+//! until then (and in tests that never call it) the config defaults apply.
+
+use once_cell::sync::Lazy;
+use padzapp::config::PadzConfig;
+use padzapp::error::{PadzError, Result};
+use std::io::Read;
+use std::process::{Child, Command, Output, Stdio};
+use std::sync::RwLock;
+use std::thread;
+use std::time::{Duration, Instant};
+
+/// How often a running program is checked on.
+const POLL: Duration = Duration::from_millis(25);
+
+/// Which limit a program runs under.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Limit {
+    /// The user's editor (`editor_timeout`).
+    Editor,
+    /// Short-lived helpers: linter, directives, `create --from`, git, gpg
+    /// (`command_timeout`).
+    Command,
+    /// One call over the network: aws, sftp (`network_timeout`).
+    Network,
+}
+
+impl Limit {
+    /// The config key that sets this limit.
+    pub fn setting(self) -> &'static str {
+        match self {
+            Limit::Editor => "editor_timeout",
+            Limit::Command => "command_timeout",
+            Limit::Network => "network_timeout",
+        }
+    }
+
+    /// The limit in seconds; `0` means none.
+    fn seconds(self) -> u64 {
+        let timeouts = TIMEOUTS.read().unwrap_or_else(|e| e.into_inner());
+        match self {
+            Limit::Editor => timeouts.editor,
+            Limit::Command => timeouts.command,
+            Limit::Network => timeouts.network,
+        }
+    }
+}
+
+/// The configured limits, in seconds.
+#[derive(Debug, Clone, Copy)]
+struct Timeouts {
+    editor: u64,
+    command: u64,
+    network: u64,
+}
+
+impl From<&PadzConfig> for Timeouts {
+    fn from(config: &PadzConfig) -> Self {
+        Self {
+            editor: config.editor_timeout,
+            command: config.command_timeout,
+            network: config.network_timeout,
+        }
+    }
+}
+
+static TIMEOUTS: Lazy<RwLock<Timeouts>> =
+    Lazy::new(|| RwLock::new(Timeouts::from(&PadzConfig::default())));
+
+/// Use the limits in `config` from now on.
+pub fn configure(config: &PadzConfig) {
+    *TIMEOUTS.write().unwrap_or_else(|e| e.into_inner()) = Timeouts::from(config);
+}
+
+/// Runs `command` like [`Command::output`] — no stdin, output captured —
+/// within `limit`. `what` names the program in errors ("gpg", "linter 'vale'").
+pub fn output(command: &mut Command, limit: Limit, what: &str) -> Result<Output> {
+    let child = command
+        .stdin(Stdio::null())
+        .stdout(Stdio::piped())
+        .stderr(Stdio::piped())
+        .spawn()
+        .map_err(|e| PadzError::Api(format!("Failed to run {}: {}", what, e)))?;
+    wait(child, limit, what)
+}
+
+/// Waits for `child` within `limit`, collecting whatever it was spawned to
+/// pipe to us; output it inherited stays empty in the result.
+pub fn wait(mut child: Child, limit: Limit, what: &str) -> Result<Output> {
+    // Drained on their own threads, so a chatty program never blocks on a
+    // full pipe while we wait for it to exit.
+    let stdout = child.stdout.take().map(drain);
+    let stderr = child.stderr.take().map(drain);
+
+    let seconds = limit.seconds();
+    let deadline = (seconds > 0).then(|| Instant::now() + Duration::from_secs(seconds));
+    let status = loop {
+        match child.try_wait() {
+            Ok(Some(status)) => break status,
+            Ok(None) => {}
+            Err(e) => {
+                let _ = child.kill();
+                let _ = child.wait();
+                return Err(PadzError::Api(format!(
+                    "Failed to wait for {}: {}",
+                    what, e
+                )));
+            }
+        }
+        if deadline.is_some_and(|deadline| Instant::now() >= deadline) {
+            let _ = child.kill();
+            let _ = child.wait();
+            return Err(PadzError::TimedOut {
+                command: what.to_string(),
+                seconds,
+                setting: limit.setting().to_string(),
+            });
+        }
+        thread::sleep(POLL);
+    };
+
+    Ok(Output {
+        status,
+        stdout: stdout.map(join).unwrap_or_default(),
+        stderr: stderr.map(join).unwrap_or_default(),
+    })
+}
+
+fn drain<R: Read + Send + 'static>(mut pipe: R) -> thread::JoinHandle<Vec<u8>> {
+    thread::spawn(move || {
+        let mut buf = Vec::new();
+        let _ = pipe.read_to_end(&mut buf);
+        buf
+    })
+}
+
+fn join(handle: thread::JoinHandle<Vec<u8>>) -> Vec<u8> {
+    handle.join().unwrap_or_default()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn a_program_that_finishes_in_time_reports_its_output() {
+        let output = output(
+            Command::new("sh").arg("-c").arg("echo out; echo err >&2"),
+            Limit::Command,
+            "sh",
+        )
+        .unwrap();
+        assert!(output.status.success());
+        assert_eq!(output.stdout, b"out\n");
+        assert_eq!(output.stderr, b"err\n");
+    }
+
+    #[test]
+    fn a_program_past_its_limit_is_killed() {
+        configure(&PadzConfig {
+            network_timeout: 1,
+            ..PadzConfig::default()
+        });
+        let started = Instant::now();
+        let err = output(
+            Command::new("sh").arg("-c").arg("sleep 30"),
+            Limit::Network,
+            "sleepy",
+        )
+        .unwrap_err();
+        configure(&PadzConfig::default());
+
+        assert!(started.elapsed() < Duration::from_secs(10));
+        match err {
+            PadzError::TimedOut {
+                command,
+                seconds,
+                setting,
+            } => {
+                assert_eq!(command, "sleepy");
+                assert_eq!(seconds, 1);
+                assert_eq!(setting, "network_timeout");
+            }
+            other => panic!("expected a timeout, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn a_missing_program_fails_to_run() {
+        let err = output(
+            &mut Command::new("padz-no-such-program"),
+            Limit::Command,
+            "nothing",
+        )
+        .unwrap_err();
+        assert!(err.to_string().contains("Failed to run nothing"));
+    }
+}
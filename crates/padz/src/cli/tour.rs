@@ -0,0 +1,183 @@
+//! `padz tour`: a walkthrough of padz that runs the real commands.
+//!
+//! Each step says what a command does, then runs it: this binary, re-invoked
+//! with `--test-mode` and `$TMPDIR` pointed at a directory of the tour's own, so
+//! the pads it makes live in a throwaway sandbox (see [`super::env::sandbox`])
+//! and the user's pads are never read or touched. The directory is removed
+//! when the tour ends, finished or quit. Because every step is a real run, the
+//! tour shows what the commands do today rather than what they once did.
+//!
+//! At a terminal the tour waits for Enter before each step, and `q` quits;
+//! without one, or with `--no-pause`, it runs straight through.
+
+use padzapp::error::{PadzError, Result};
+use std::io::{BufRead, IsTerminal, Write};
+use std::path::{Path, PathBuf};
+use std::process::Command;
+
+/// One stop of the tour: what it shows, and the padz arguments that show it.
+struct Step {
+    intro: &'static str,
+    args: &'static [&'static str],
+}
+
+const STEPS: &[Step] = &[
+    Step {
+        intro: "Create a pad. Without --no-editor, padz would open your editor for the body.",
+        args: &["create", "--no-editor", "Groceries: milk, eggs, coffee"],
+    },
+    Step {
+        intro: "Make two more, so there is something to list.",
+        args: &["create", "--no-editor", "Call the plumber"],
+    },
+    Step {
+        intro: "",
+        args: &["create", "--no-editor", "Ideas for the weekend"],
+    },
+    Step {
+        intro: "List them. The newest pad is 1; these numbers are how you name pads.",
+        args: &["list"],
+    },
+    Step {
+        intro: "Pin the groceries, pad 3. Pinned pads stay on top, as p1, p2, ...",
+        args: &["pin", "3"],
+    },
+    Step {
+        intro: "",
+        args: &["list"],
+    },
+    Step {
+        intro: "Search titles and bodies.",
+        args: &["search", "milk"],
+    },
+    Step {
+        intro: "Delete the plumber, now pad 2. Deleting is soft: the pad is kept as d1.",
+        args: &["delete", "2"],
+    },
+    Step {
+        intro: "",
+        args: &["list", "--deleted"],
+    },
+    Step {
+        intro: "Changed your mind? Restore it.",
+        args: &["restore", "d1"],
+    },
+    Step {
+        intro: "",
+        args: &["list"],
+    },
+];
+
+/// The tour's `$TMPDIR`, removed on drop whatever way the tour ends.
+struct TourDir(PathBuf);
+
+impl TourDir {
+    fn create() -> Result<Self> {
+        let dir = std::env::temp_dir().join(format!("padz-tour-{}", std::process::id()));
+        std::fs::create_dir_all(&dir)?;
+        Ok(Self(dir))
+    }
+}
+
+impl Drop for TourDir {
+    fn drop(&mut self) {
+        let _ = std::fs::remove_dir_all(&self.0);
+    }
+}
+
+/// Run the tour. `pause` waits for Enter before each step; it only applies at
+/// a terminal.
+pub fn run(pause: bool) -> Result<()> {
+    let exe = std::env::current_exe()
+        .map_err(|e| PadzError::Api(format!("cannot locate current executable: {e}")))?;
+    let dir = TourDir::create()?;
+    let pause = pause && std::io::stdin().is_terminal();
+
+    println!("Welcome to padz. This tour runs real padz commands on a scratch store,");
+    println!("which is deleted at the end: your own pads are not touched.");
+    for step in STEPS {
+        println!();
+        if !step.intro.is_empty() {
+            println!("{}", step.intro);
+        }
+        println!("$ padz {}", shell_words(step.args));
+        if pause && !wait_for_enter() {
+            println!("Tour ended. Run `padz tour` to pick it up from the start.");
+            return Ok(());
+        }
+        run_step(&exe, &dir.0, step.args)?;
+    }
+    println!();
+    println!("That's the tour. `padz help` lists every command, and the scratch store");
+    println!("is gone. Start your own with: padz create");
+    Ok(())
+}
+
+/// Asks on stderr; false when the user quits, stdin closes or Ctrl-C was hit.
+fn wait_for_enter() -> bool {
+    eprint!("[Enter to run, q to quit] ");
+    let _ = std::io::stderr().flush();
+    let mut answer = String::new();
+    match std::io::stdin().lock().read_line(&mut answer) {
+        Ok(0) | Err(_) => false,
+        Ok(_) => !answer.trim().eq_ignore_ascii_case("q") && !super::interrupt::interrupted(),
+    }
+}
+
+fn run_step(exe: &Path, tmp: &Path, args: &[&str]) -> Result<()> {
+    let status = Command::new(exe)
+        .arg("--test-mode")
+        .args(args)
+        .env("TMPDIR", tmp)
+        .status()
+        .map_err(|e| PadzError::Api(format!("failed to invoke {}: {e}", exe.display())))?;
+    if !status.success() {
+        return Err(PadzError::Api(format!(
+            "The tour step `padz {}` failed ({})",
+            shell_words(args),
+            status
+        )));
+    }
+    Ok(())
+}
+
+/// Arguments as they would be typed: quoted when they hold spaces.
+fn shell_words(args: &[&str]) -> String {
+    args.iter()
+        .map(|arg| {
+            if arg.contains(' ') {
+                format!("\"{}\"", arg)
+            } else {
+                arg.to_string()
+            }
+        })
+        .collect::<Vec<_>>()
+        .join(" ")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::cli::setup::Cli;
+    use clap::Parser;
+
+    #[test]
+    fn every_step_is_a_valid_command_line() {
+        for step in STEPS {
+            let argv = ["padz", "--test-mode"].iter().chain(step.args);
+            assert!(
+                Cli::try_parse_from(argv).is_ok(),
+                "tour step `padz {}` does not parse",
+                shell_words(step.args)
+            );
+        }
+    }
+
+    #[test]
+    fn shell_words_quotes_arguments_with_spaces() {
+        assert_eq!(
+            shell_words(&["create", "--no-editor", "Call the plumber"]),
+            "create --no-editor \"Call the plumber\""
+        );
+    }
+}
@@ -0,0 +1,211 @@
+//! `padz open-uri`: opening a pad from a `padz://` link clicked in another app.
+//!
+//! The URI ([`padzapp::index::pad_uri`]) names the pad's scope and UUID. The
+//! store holding it is found with [`padzapp::commands::uri::locate`], and
+//! padz runs itself on that store, `open <uuid>` with `--global` or
+//! `--data <store>`, the way the tour runs its steps. The child shares this
+//! terminal, so the editor opens in it.
+//!
+//! `--install` makes `padz open-uri` the system's handler for `padz://`
+//! links:
+//!
+//! - Linux: a `padz-uri.desktop` entry in `$XDG_DATA_HOME/applications`,
+//!   started in a terminal, set as the `x-scheme-handler/padz` default with
+//!   `xdg-mime`.
+//! - macOS: a link arrives as an Apple event, not as an argument, so the
+//!   handler is a small AppleScript app, `~/Applications/Padz Links.app`, that
+//!   runs `padz open-uri` in Terminal. `osacompile` builds it, its
+//!   `Info.plist` claims the scheme, and Launch Services is told about it.
+
+use padzapp::commands::uri::locate;
+use padzapp::error::{PadzError, Result};
+use padzapp::index::parse_pad_uri;
+use padzapp::init::PadzEnv;
+use std::path::PathBuf;
+use std::process::Command;
+
+/// Opens the pad `uri` points to. `test_mode` passes `--test-mode` on, so
+/// the child runs in the same sandbox.
+pub fn run(env: &PadzEnv, uri: &str, test_mode: bool) -> Result<()> {
+    let (scope, id) = parse_pad_uri(uri).map_err(PadzError::Api)?;
+    let store = locate(&env.global_data_dir, scope, &id)?;
+
+    let exe = current_exe()?;
+    let mut command = Command::new(&exe);
+    if test_mode {
+        command.arg("--test-mode");
+    }
+    match &store {
+        Some(padz_dir) => command.arg("--data").arg(padz_dir),
+        None => command.arg("--global"),
+    };
+    let status = command
+        .arg("open")
+        .arg(id.to_string())
+        .status()
+        .map_err(|e| PadzError::Api(format!("failed to invoke {}: {e}", exe.display())))?;
+    if !status.success() {
+        return Err(PadzError::Api(format!(
+            "Could not open {} ({})",
+            uri, status
+        )));
+    }
+    Ok(())
+}
+
+fn current_exe() -> Result<PathBuf> {
+    std::env::current_exe()
+        .map_err(|e| PadzError::Api(format!("cannot locate current executable: {e}")))
+}
+
+/// Runs a registration tool, failing with what it printed.
+#[cfg(any(target_os = "linux", target_os = "macos"))]
+fn run_tool(program: &str, args: &[&str]) -> Result<()> {
+    let mut command = Command::new(program);
+    command.args(args);
+    let output =
+        super::subprocess::output(&mut command, super::subprocess::Limit::Command, program)?;
+    if !output.status.success() {
+        return Err(PadzError::Api(format!(
+            "{} failed: {}",
+            program,
+            String::from_utf8_lossy(&output.stderr).trim()
+        )));
+    }
+    Ok(())
+}
+
+/// Name of the desktop entry, which `xdg-mime` refers to it by.
+#[cfg(target_os = "linux")]
+const DESKTOP_FILE: &str = "padz-uri.desktop";
+
+#[cfg(target_os = "linux")]
+pub fn install() -> Result<()> {
+    let dir = super::commands::xdg_data_home()?.join("applications");
+    std::fs::create_dir_all(&dir)?;
+    let path = dir.join(DESKTOP_FILE);
+    std::fs::write(&path, desktop_entry(&current_exe()?))?;
+    run_tool(
+        "xdg-mime",
+        &["default", DESKTOP_FILE, "x-scheme-handler/padz"],
+    )?;
+    println!("padz:// links now open with padz ({})", path.display());
+    Ok(())
+}
+
+#[cfg(target_os = "macos")]
+pub fn install() -> Result<()> {
+    let home = std::env::var("HOME")
+        .map_err(|_| PadzError::Api("$HOME not set; cannot determine install path".into()))?;
+    let app = PathBuf::from(home)
+        .join("Applications")
+        .join("Padz Links.app");
+    let app_path = app.to_string_lossy();
+
+    let script = apple_script(&current_exe()?);
+    let mut args = vec!["-o", app_path.as_ref()];
+    for line in script.lines() {
+        args.extend(["-e", line]);
+    }
+    run_tool("osacompile", &args)?;
+
+    let plist = app.join("Contents").join("Info.plist");
+    run_tool(
+        "plutil",
+        &[
+            "-replace",
+            "CFBundleURLTypes",
+            "-json",
+            r#"[{"CFBundleURLName":"padz","CFBundleURLSchemes":["padz"]}]"#,
+            plist.to_string_lossy().as_ref(),
+        ],
+    )?;
+    run_tool(
+        "/System/Library/Frameworks/CoreServices.framework/Frameworks/LaunchServices.framework/Support/lsregister",
+        &["-f", app_path.as_ref()],
+    )?;
+    println!("padz:// links now open with padz ({})", app.display());
+    Ok(())
+}
+
+#[cfg(not(any(target_os = "linux", target_os = "macos")))]
+pub fn install() -> Result<()> {
+    Err(PadzError::Api(
+        "padz can register itself for padz:// links on macOS and Linux only".into(),
+    ))
+}
+
+/// The desktop entry that hands a `padz://` link to `exe open-uri`, in a
+/// terminal for the editor to run in.
+#[cfg(any(target_os = "linux", test))]
+fn desktop_entry(exe: &std::path::Path) -> String {
+    // Exec quotes the way the desktop entry spec asks: double quotes, with
+    // the characters a shell would expand escaped by a backslash, which the
+    // file's own string escaping doubles. A literal `%` is `%%`.
+    let mut quoted = String::from("\"");
+    for c in exe.to_string_lossy().chars() {
+        match c {
+            '"' | '`' | '$' | '\\' => {
+                quoted.push_str("\\\\");
+                quoted.push(c);
+            }
+            '%' => quoted.push_str("%%"),
+            c => quoted.push(c),
+        }
+    }
+    quoted.push('"');
+    format!(
+        "[Desktop Entry]\n\
+         Type=Application\n\
+         Name=padz\n\
+         Comment=Open padz:// links\n\
+         Exec={} open-uri %u\n\
+         Terminal=true\n\
+         NoDisplay=true\n\
+         MimeType=x-scheme-handler/padz;\n",
+        quoted
+    )
+}
+
+/// The AppleScript app that receives `padz://` links and runs `exe open-uri`
+/// on each in a Terminal window.
+#[cfg(any(target_os = "macos", test))]
+fn apple_script(exe: &std::path::Path) -> String {
+    let exe = exe
+        .to_string_lossy()
+        .replace('\\', "\\\\")
+        .replace('"', "\\\"");
+    format!(
+        "on open location theURL\n\
+         \ttell application \"Terminal\"\n\
+         \t\tactivate\n\
+         \t\tdo script (quoted form of \"{}\") & \" open-uri \" & (quoted form of theURL)\n\
+         \tend tell\n\
+         end open location\n",
+        exe
+    )
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::path::Path;
+
+    #[test]
+    fn the_desktop_entry_claims_the_scheme_and_quotes_the_binary() {
+        let entry = desktop_entry(Path::new("/opt/my tools/padz"));
+        assert!(entry.contains("Exec=\"/opt/my tools/padz\" open-uri %u\n"));
+        assert!(entry.contains("MimeType=x-scheme-handler/padz;\n"));
+        assert!(entry.contains("Terminal=true\n"));
+        assert!(desktop_entry(Path::new("/bin/pa$dz")).contains("\"/bin/pa\\\\$dz\""));
+    }
+
+    #[test]
+    fn the_apple_script_opens_the_link_in_terminal() {
+        let script = apple_script(Path::new("/usr/local/bin/padz"));
+        assert!(script.starts_with("on open location theURL\n"));
+        assert!(script.contains(
+            "do script (quoted form of \"/usr/local/bin/padz\") & \" open-uri \" & (quoted form of theURL)"
+        ));
+    }
+}
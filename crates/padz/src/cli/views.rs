@@ -36,8 +36,20 @@
 //! (peek previews, uuids, status icons), not how to draw it — a mode-independent fact
 //! about the invocation, so it rides in structured output too.
 
+use chrono::{DateTime, Utc};
+use padzapp::commands::grouping::PadGroup;
+use padzapp::commands::maintain::MaintainOutcome;
+use padzapp::commands::release_notes::ReleaseNote;
+use padzapp::commands::search_all::UnsearchedScope;
+use padzapp::commands::stats::{PadCounts, TrashSize};
+use padzapp::commands::verify::IntegrityReport;
 use padzapp::commands::{CmdNotice, CmdOutcome, NestingMode};
+use padzapp::config::ListDensity;
 use padzapp::index::DisplayPad;
+use padzapp::model::{CreationContext, PublishService};
+use padzapp::spell::SpellCheck;
+use padzapp::store::compression::CompressionStats;
+use padzapp::usage::UsageReport;
 use serde::{Deserialize, Serialize};
 
 /// Filesystem paths of the selected pads, one per selector match (`path` command).
@@ -64,11 +76,143 @@ pub struct UuidView {
 /// Only selected roots contribute to the count and titles. Descendants remain in the
 /// clipboard payload according to the requested nesting mode, but they are not
 /// additional user selections. The clipboard write itself is a handler side effect;
-/// this view carries only what the confirmation line reports.
+/// this view carries only what the confirmation line reports. `appended` is set
+/// when the pads were added after what the clipboard already held (`--append`).
 #[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
 pub struct CopyView {
     pub root_pad_count: usize,
     pub titles: Vec<String>,
+    pub appended: bool,
+}
+
+/// A pad's body with its placeholders filled in (`fill` command).
+///
+/// `content` is what was printed and copied; the pad itself is unchanged.
+/// `placeholders` names the ones that were filled, in order of first use.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct FilledPad {
+    pub title: String,
+    pub placeholders: Vec<String>,
+    pub content: String,
+}
+
+/// Pads read aloud (`speak` command).
+///
+/// The speaking is a handler side effect; this view names the pads read and,
+/// with `--to`, the audio file they were recorded to instead.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct SpokenPads {
+    pub titles: Vec<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub file: Option<String>,
+}
+
+/// Pads uploaded by `publish` (the uploading is a handler side effect).
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct PublishedPads {
+    pub pads: Vec<PublishedPad>,
+    /// Whether this was `publish --update`: `pads` then lists the copies
+    /// brought up to date, and none means all of them were.
+    pub update: bool,
+}
+
+/// One pad `publish` uploaded, and where it can be read.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct PublishedPad {
+    pub title: String,
+    pub service: PublishService,
+    pub url: String,
+}
+
+/// A release notes draft: the tagged pads written since the last release,
+/// and the commits made since for context.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ReleaseNotes {
+    /// The tag or commit the last release was cut at; `None` gathers every
+    /// tagged pad and lists no commits.
+    pub since: Option<String>,
+    pub notes: Vec<ReleaseNote>,
+    pub commits: Vec<ReleaseCommit>,
+}
+
+/// One commit made since the last release.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ReleaseCommit {
+    /// The abbreviated commit id.
+    pub id: String,
+    pub subject: String,
+}
+
+/// What `verify` found when checking a detached signature.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "snake_case")]
+pub enum SignatureStatus {
+    /// The signature is valid for the file's current contents.
+    Good,
+    /// The signature does not match: the file changed after signing.
+    Bad,
+    /// The signing key is not in the keyring, so nothing could be checked.
+    UnknownKey,
+    /// No usable signature (unreadable, not a signature, or no verdict).
+    Invalid,
+}
+
+/// The result of checking a file against its detached signature (`verify`).
+///
+/// A pure CLI summary: signing is a CLI concern, so there is no core type.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct SignatureCheck {
+    pub file: std::path::PathBuf,
+    pub signature: std::path::PathBuf,
+    pub status: SignatureStatus,
+    /// The signer's user id, when gpg named one.
+    pub signer: Option<String>,
+}
+
+/// A store integrity check, with how many checksums `--accept` re-recorded first.
+///
+/// The core report is carried verbatim (flattened); `accepted` is absent when the
+/// user did not ask to accept anything.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct StoreCheck {
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub accepted: Option<usize>,
+    #[serde(flatten)]
+    pub report: IntegrityReport,
+}
+
+/// What `verify` checked: an exported file's signature, or the store itself.
+///
+/// Tagged by `kind` so `verify.jinja` and structured consumers can tell the two
+/// apart without probing fields.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(tag = "kind", rename_all = "snake_case")]
+pub enum VerifyView {
+    Signature(SignatureCheck),
+    Store(StoreCheck),
+}
+
+/// Pad counts for the bound scope, what compression at rest saves there, and the
+/// usage tally when `--usage` asked for it (`stats` command).
+///
+/// Every part is a core type carried verbatim; the view exists only because the
+/// usage part is optional per invocation.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct StatsView {
+    pub pads: PadCounts,
+    pub compression: CompressionStats,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub usage: Option<UsageReport>,
+}
+
+/// Store upkeep (`maintain` command): the core outcome, carried verbatim
+/// (flattened), and the trash when it is past a `[trash]` config limit.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct MaintainView {
+    #[serde(flatten)]
+    pub outcome: MaintainOutcome,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub trash: Option<TrashSize>,
 }
 
 /// What the user asked a listing to show.
@@ -91,6 +235,10 @@ pub struct ListRequest {
     pub deleted_help: bool,
     /// Group results under lifecycle section headers (`--all`).
     pub sections: bool,
+    /// Cut titles longer than this many characters (`list.max_title`).
+    pub max_title: Option<usize>,
+    /// Spacing between pads (`list.density`).
+    pub density: ListDensity,
 }
 
 /// What the user asked a modification to show.
@@ -113,6 +261,23 @@ pub struct ModificationRequest {
 #[derive(Debug, Clone, Serialize)]
 pub struct Listing {
     pub pads: Vec<DisplayPad>,
+    /// `list --group-by`: the same pads split under their group keys, each in
+    /// its canonical order. A grouped listing carries its pads here only, and
+    /// `pads` is empty.
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub groups: Vec<PadGroup>,
+    /// `search --all-scopes`: the stores whose matches are missing, so the
+    /// groups are partial results.
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub unsearched: Vec<UnsearchedScope>,
+    /// `--count`: how many pads matched. A counted listing is this number
+    /// alone, and `pads` is empty.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub count: Option<usize>,
+    /// The trash, when it is past a `[trash]` config limit and worth a
+    /// reminder to purge it.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub trash: Option<TrashSize>,
     pub request: ListRequest,
 }
 
@@ -153,6 +318,10 @@ pub enum ModificationAction {
     Restore,
     Archive,
     Unarchive,
+    Name,
+    Link,
+    Unlink,
+    Ref,
     Complete,
     Reopen,
     Move,
@@ -171,6 +340,28 @@ pub struct PadContent {
     /// Present only when `--uuid` was passed.
     #[serde(skip_serializing_if = "Option::is_none")]
     pub uuid: Option<String>,
+    /// Code locations the pad is linked to (`path` or `path:line`).
+    #[serde(default)]
+    pub anchors: Vec<String>,
+    /// Present only when `--meta` was passed.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub meta: Option<PadMeta>,
+    /// Present only when `--spell` was passed: the body's misspellings, and
+    /// the body split into segments for in-place highlighting.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub spelling: Option<SpellCheck>,
+}
+
+/// When and where a pad was written, as `view --meta` shows it.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct PadMeta {
+    pub created_at: DateTime<Utc>,
+    pub updated_at: DateTime<Utc>,
+    /// The working-tree state captured at creation, if any.
+    pub context: Option<CreationContext>,
+    /// Who created the pad, if recorded.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub owner: Option<String>,
 }
 
 /// Full content of the viewed pads.
@@ -0,0 +1,105 @@
+//! `list --watch`: the listing drawn again whenever the store changes.
+//!
+//! Another terminal creating a pad, the editor saving one, a sync pulling
+//! the bucket: all of them end up as files written under the scope's store
+//! directory, so that directory is what is watched. With no file-notification
+//! API to lean on short of a platform crate, it is polled: every [`POLL`]
+//! its files are fingerprinted by path, size and modification time
+//! ([`Snapshot`]), and any difference redraws. The fingerprint is taken after
+//! each draw, so whatever the listing itself touches does not set off the
+//! next one.
+//!
+//! The first Ctrl-C ends the watch (see [`super::interrupt`]).
+
+use padzapp::cancel::Cancellation;
+use std::collections::BTreeMap;
+use std::io::Write;
+use std::path::{Path, PathBuf};
+use std::time::{Duration, SystemTime};
+
+/// How often the store is checked for changes.
+pub const POLL: Duration = Duration::from_millis(500);
+
+/// Every file under a directory, with its size and modification time.
+#[derive(Debug, Clone, PartialEq, Eq, Default)]
+pub struct Snapshot(BTreeMap<PathBuf, (u64, Option<SystemTime>)>);
+
+impl Snapshot {
+    /// Fingerprints `dir`. Files that vanish while it is read are left out;
+    /// the next snapshot sees them gone.
+    pub fn of(dir: &Path) -> Self {
+        let mut files = BTreeMap::new();
+        let mut pending = vec![dir.to_path_buf()];
+        while let Some(dir) = pending.pop() {
+            let Ok(entries) = std::fs::read_dir(&dir) else {
+                continue;
+            };
+            for entry in entries.flatten() {
+                let Ok(metadata) = entry.metadata() else {
+                    continue;
+                };
+                if metadata.is_dir() {
+                    pending.push(entry.path());
+                } else {
+                    files.insert(entry.path(), (metadata.len(), metadata.modified().ok()));
+                }
+            }
+        }
+        Snapshot(files)
+    }
+}
+
+/// Waits until `dir` no longer matches `drawn`. `false` when Ctrl-C came
+/// first.
+pub fn wait_for_change(dir: &Path, drawn: &Snapshot, cancellation: &Cancellation) -> bool {
+    loop {
+        std::thread::sleep(POLL);
+        if cancellation.is_cancelled() {
+            return false;
+        }
+        if Snapshot::of(dir) != *drawn {
+            return true;
+        }
+    }
+}
+
+/// Clears the terminal and puts the cursor at its top, for the next draw.
+pub fn clear_screen() {
+    let mut stdout = std::io::stdout();
+    let _ = stdout.write_all(b"\x1b[2J\x1b[H");
+    let _ = stdout.flush();
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn a_snapshot_changes_when_a_file_is_written_or_removed() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::create_dir(dir.path().join("active")).unwrap();
+        let pad = dir.path().join("active").join("pad.txt");
+        std::fs::write(&pad, "Groceries").unwrap();
+        let drawn = Snapshot::of(dir.path());
+        assert_eq!(Snapshot::of(dir.path()), drawn);
+
+        std::fs::write(&pad, "Groceries\n\nmilk").unwrap();
+        let written = Snapshot::of(dir.path());
+        assert_ne!(written, drawn);
+
+        std::fs::remove_file(&pad).unwrap();
+        assert_ne!(Snapshot::of(dir.path()), written);
+    }
+
+    #[test]
+    fn waiting_stops_at_ctrl_c() {
+        let dir = tempfile::tempdir().unwrap();
+        let cancellation = Cancellation::new();
+        cancellation.cancel();
+        assert!(!wait_for_change(
+            dir.path(),
+            &Snapshot::of(dir.path()),
+            &cancellation
+        ));
+    }
+}
@@ -77,6 +77,8 @@
 //!    integration: command wiring, input chains, templates, styles, output modes,
 //!    output files. In process, and **serial**, because the seams they drive
 //!    (env vars, cwd, terminal detectors, default readers) are process-global.
+//!    `tests/golden_render.rs` is the same layer pinning whole listing renders to
+//!    files under `tests/fixtures/golden/` (re-record with `PADZ_BLESS=1`).
 //! 4. **Subprocess E2E** (`tests/*_e2e.rs`) — only boundaries a harness cannot
 //!    model: a real editor/clipboard process, completion installation, `main.rs`'s
 //!    own wiring, or `std::process::exit` codes. Each retained file says which
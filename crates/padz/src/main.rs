@@ -10,13 +10,23 @@ use padz::cli;
 fn main() {
     // Handle shell completions before normal CLI processing.
     // When COMPLETE=<shell> is set, this intercepts the request and exits.
-    clap_complete::CompleteEnv::with_factory(cli::setup::build_command).complete();
+    clap_complete::CompleteEnv::with_factory(cli::setup::build_completion_command).complete();
 
     if let Err(e) = cli::run() {
+        // Stopped by Ctrl-C: whatever failed did so because it was told to
+        // stop, and rolled back its writes (see `cli::interrupt`).
+        if cli::interrupt::interrupted() {
+            eprintln!("Interrupted.");
+            std::process::exit(cli::interrupt::EXIT_INTERRUPTED);
+        }
         // `cli::errors::render` styles the errors that carry structured data
         // (see `padzapp::error::PadzError::AmbiguousTitle`) and falls back to
         // `Display` for the rest.
         eprintln!("Error: {}", cli::errors::render(&e));
         std::process::exit(1);
     }
+    // `search --quiet` answers with its status alone (see `cli::exit`).
+    if cli::exit::no_match() {
+        std::process::exit(cli::exit::EXIT_NO_MATCH);
+    }
 }
@@ -23,27 +23,46 @@
 
 mod support;
 
+use padz::cli::explain;
 use padz::cli::handlers;
-use padz::cli::input::{RequestContent, CREATE_CONTENT, EDIT_CONTENT};
-use padz::cli::views::{CopyView, PathView, UuidView};
+use padz::cli::input::{RequestContent, AMEND_CONTENT, CREATE_CONTENT, EDIT_CONTENT};
+use padz::cli::setup::ExportArchive;
+use padz::cli::signing::signature_path;
+use padz::cli::views::{
+    CopyView, FilledPad, PathView, ReleaseNotes, SignatureStatus, UuidView, VerifyView,
+};
 use padz::cli::views::{Listing, Modification, ModificationAction, PadContentResult};
+use padzapp::api::PadFilter;
 use padzapp::commands::doctor::DoctorOutcome;
 use padzapp::commands::export::{ExportFormat, ExportReport, ExportWarning};
 use padzapp::commands::import::{
     ImportDiagnostic, ImportReport, ImportSourceKind, ImportSourceStatus, ImportStatus,
 };
 use padzapp::commands::init::InitializationOutcome;
+use padzapp::commands::journal::JournalOutcome;
+use padzapp::commands::maintain::MaintainOutcome;
 use padzapp::commands::metadata_apply::MetadataWarningReason;
 use padzapp::commands::purge::PurgeOutcome;
+use padzapp::commands::scopes::{PruneOutcome, RemoteAdded};
+use padzapp::commands::snip::SnipOutcome;
 use padzapp::commands::tagging::{TaggingOutcome, TaggingResult};
 use padzapp::commands::tags::{TagCatalogOutcome, TagRegistryOutcome};
+use padzapp::commands::timeline::TimelineOutcome;
 use padzapp::commands::transfer::{
     TransferDirection, TransferMode, TransferReport, TransferSelection, TransferStatus,
 };
+use padzapp::commands::tree::TreeOutcome;
+use padzapp::commands::undo::Undone;
+use padzapp::commands::verify::IntegrityProblem;
 use padzapp::commands::{CmdNotice, CmdOutcome, NestingMode, UpdateKind};
-use padzapp::model::{Scope, TodoStatus};
+use padzapp::config::{DuplicateTitle, JournalConfig, TrashConfig};
+use padzapp::model::{CreationContext, PadEventKind, Scope, TodoStatus};
+use padzapp::queue::{queue_path, Operation, OperationQueue, QueueReport};
+use padzapp::store::events::EventKind;
+use padzapp::store::Bucket;
 use standout::cli::Output;
-use support::Fixture;
+use std::rc::Rc;
+use support::{FixedSigner, Fixture};
 
 /// Unwraps the `Output::Render` payload every padz read/modify handler returns.
 #[track_caller]
@@ -110,6 +129,11 @@ fn list_maps_no_filters_to_every_active_pad() {
         vec![],
         false,
         false,
+        None,
+        None,
+        false,
+        None,
+        false,
     ));
 
     let mut got = titles(&result);
@@ -139,6 +163,11 @@ fn list_maps_search_argument_to_a_filtered_result() {
         vec![],
         false,
         false,
+        None,
+        None,
+        false,
+        None,
+        false,
     ));
 
     assert_eq!(titles(&result), vec!["alpha"]);
@@ -170,6 +199,11 @@ fn list_maps_peek_flag_onto_the_request_not_the_pads() {
         vec![],
         false,
         false,
+        None,
+        None,
+        false,
+        None,
+        false,
     ));
 
     assert!(
@@ -208,11 +242,109 @@ fn search_maps_its_term_to_matching_pads_only() {
         false,
         vec![],
         false,
+        false,
+        false,
+        false,
     ));
 
     assert_eq!(titles(&result), vec!["meeting notes"]);
 }
 
+#[test]
+fn search_count_and_quiet_keep_only_the_number_of_matches() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "meeting notes", "");
+    fx.seed_pad(&state, "meeting agenda", "");
+    let ctx = support::ctx_with_state(state);
+    rendered(handlers::pin(&ctx, vec!["1".to_string()]));
+
+    let counted = rendered(handlers::search(
+        &ctx,
+        "meeting".to_string(),
+        false,
+        false,
+        false,
+        false,
+        vec![],
+        false,
+        true,
+        false,
+        false,
+    ));
+    // The pinned pad is listed twice but counted once.
+    assert_eq!(counted.count, Some(2));
+    assert!(counted.pads.is_empty());
+
+    let quiet = handlers::search(
+        &ctx,
+        "nothing like this".to_string(),
+        false,
+        false,
+        false,
+        false,
+        vec![],
+        false,
+        false,
+        true,
+        false,
+    )
+    .unwrap();
+    assert!(matches!(quiet, Output::Silent));
+}
+
+#[test]
+fn search_all_scopes_groups_the_matches_of_every_store() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "meeting notes", "");
+    fx.seed_pad(&state, "shopping list", "");
+    state
+        .with_api(|api| api.create_pad(Scope::Global, "meeting with legal".into(), "".into(), None))
+        .unwrap();
+    let ctx = support::ctx_with_state(state);
+
+    let listing = rendered(handlers::search(
+        &ctx,
+        "meeting".to_string(),
+        false,
+        false,
+        false,
+        false,
+        vec![],
+        false,
+        false,
+        false,
+        true,
+    ));
+
+    assert!(listing.pads.is_empty());
+    assert!(listing.unsearched.is_empty());
+    let groups: Vec<(String, Vec<String>)> = listing
+        .groups
+        .iter()
+        .map(|group| {
+            let titles = group
+                .pads
+                .iter()
+                .map(|dp| dp.pad.metadata.title.clone())
+                .collect();
+            (group.key.clone().unwrap(), titles)
+        })
+        .collect();
+    let project = std::fs::canonicalize(fx.project()).unwrap();
+    assert_eq!(
+        groups,
+        vec![
+            ("global".to_string(), vec!["meeting with legal".to_string()]),
+            (
+                project.display().to_string(),
+                vec!["meeting notes".to_string()]
+            ),
+        ]
+    );
+}
+
 // =============================================================================
 // Content family — view
 // =============================================================================
@@ -232,6 +364,11 @@ fn view_maps_a_selector_to_that_pads_title_and_body() {
         false,
         false,
         false,
+        false,
+        false,
+        false,
+        false,
+        false,
     ));
 
     assert_eq!(result.pads.len(), 1);
@@ -258,11 +395,94 @@ fn view_includes_the_uuid_only_when_requested() {
         false,
         false,
         false,
+        false,
+        false,
+        false,
+        false,
+        false,
     ));
 
     assert!(result.pads[0].uuid.is_some());
 }
 
+#[test]
+fn view_spell_reports_words_missing_from_the_dictionary() {
+    let fx = Fixture::new();
+    let dictionaries = fx.root().join("global").join("dictionaries");
+    std::fs::create_dir_all(&dictionaries).unwrap();
+    std::fs::write(dictionaries.join("en.dic"), "mix\nand\nbake\nthen\nserve\n").unwrap();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "recipe", "mix and bake\nthen srve");
+    let ctx = support::ctx_with_state(state);
+
+    let result: PadContentResult = rendered(handlers::view(
+        &ctx,
+        vec!["1".to_string()],
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        true, // spell
+        false,
+    ));
+
+    let check = result.pads[0]
+        .spelling
+        .as_ref()
+        .expect("--spell attaches a check");
+    let words: Vec<_> = check.misspellings.iter().map(|m| m.word.as_str()).collect();
+    assert_eq!(words, vec!["srve"]);
+    assert_eq!(check.misspellings[0].line, 2);
+}
+
+#[test]
+fn view_runs_shell_directives_only_with_exec() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "runbook", "pods:\n{{shell \"echo web-1\"}}");
+    let ctx = support::ctx_with_state(state);
+
+    let shown: PadContentResult = rendered(handlers::view(
+        &ctx,
+        vec!["1".to_string()],
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+    ));
+    assert!(
+        shown.pads[0].content.contains("{{shell \"echo web-1\"}}"),
+        "without --exec the directive is shown as written"
+    );
+
+    let executed: PadContentResult = rendered(handlers::view(
+        &ctx,
+        vec!["1".to_string()],
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        true, // exec
+    ));
+    assert!(executed.pads[0].content.contains("pods:\nweb-1"));
+    assert!(!executed.pads[0].content.contains("{{"));
+}
+
 #[test]
 fn indented_view_returns_raw_content_plus_nesting_facts() {
     let fx = Fixture::new();
@@ -279,6 +499,11 @@ fn indented_view_returns_raw_content_plus_nesting_facts() {
         false,
         false,
         true,
+        false,
+        false,
+        false,
+        false,
+        false,
     ));
 
     assert_eq!(result.nesting, NestingMode::Indented);
@@ -289,6 +514,45 @@ fn indented_view_returns_raw_content_plus_nesting_facts() {
     assert!(!result.pads[1].content.starts_with(' '));
 }
 
+#[test]
+fn view_meta_reports_the_creation_context_only_when_requested() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    let context = CreationContext {
+        cwd: "/work/repo".to_string(),
+        commit: "0123456789abcdef0123456789abcdef01234567".to_string(),
+        branch: Some("main".to_string()),
+        dirty: true,
+    };
+    state.with_api(|api| api.set_creation_context(Some(context.clone())));
+    fx.seed_pad(&state, "flaky test", "");
+    let ctx = support::ctx_with_state(state);
+
+    let view = |meta: bool| -> PadContentResult {
+        rendered(handlers::view(
+            &ctx,
+            vec!["1".to_string()],
+            false,
+            false,
+            false,
+            false,
+            false,
+            false,
+            false,
+            meta,
+            false,
+            false,
+        ))
+    };
+
+    assert!(view(false).pads[0].meta.is_none());
+    let meta = view(true).pads[0]
+        .meta
+        .clone()
+        .expect("--meta asked for it");
+    assert_eq!(meta.context, Some(context));
+}
+
 #[test]
 fn view_of_an_unknown_selector_is_an_error_not_an_empty_result() {
     let fx = Fixture::new();
@@ -302,6 +566,11 @@ fn view_of_an_unknown_selector_is_an_error_not_an_empty_result() {
         false,
         false,
         false,
+        false,
+        false,
+        false,
+        false,
+        false,
     )
     .expect_err("viewing a pad that does not exist must fail");
 
@@ -314,6 +583,100 @@ fn view_of_an_unknown_selector_is_an_error_not_an_empty_result() {
     );
 }
 
+// =============================================================================
+// Content family — fill
+// =============================================================================
+
+#[test]
+fn fill_completes_placeholders_and_leaves_the_pad_alone() {
+    let fx = Fixture::new();
+    let (state, clipboard) = fx.app_state_with_recording_clipboard_for(&["fill", "1"]);
+    fx.seed_pad(
+        &state,
+        "ssh",
+        "ssh {{placeholder:user}}@{{placeholder:host}} # {{placeholder:user}}",
+    );
+    let ctx = support::ctx_with_state(state);
+
+    let filled: FilledPad = rendered(handlers::fill(
+        &ctx,
+        "1".to_string(),
+        vec!["host=db1".to_string(), "user=root".to_string()],
+    ));
+
+    assert_eq!(filled.placeholders, vec!["user", "host"]);
+    assert_eq!(filled.content, "ssh root@db1 # root");
+    assert_eq!(clipboard.writes(), vec!["ssh root@db1 # root"]);
+    let viewed: CopyView = rendered(handlers::copy(
+        &ctx,
+        vec!["1".to_string()],
+        false,
+        false,
+        false,
+        false,
+        false,
+    ));
+    assert_eq!(viewed.root_pad_count, 1);
+    assert!(clipboard.writes()[1].contains("{{placeholder:host}}"));
+}
+
+#[test]
+fn fill_without_a_terminal_names_the_missing_values() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(
+        &state,
+        "ssh",
+        "ssh {{placeholder:user}}@{{placeholder:host}}",
+    );
+    let ctx = support::ctx_with_state(state);
+
+    let err = handlers::fill(&ctx, "1".to_string(), vec!["user=root".to_string()])
+        .expect_err("host has no value and there is no one to ask");
+    assert!(err.to_string().contains("host"), "got: {err}");
+}
+
+#[test]
+fn snip_copies_the_best_matching_snippet_body() {
+    let fx = Fixture::new();
+    let (state, clipboard) = fx.app_state_with_recording_clipboard_for(&["snip", "dkrun"]);
+    fx.seed_pad(&state, "docker run", "docker run --rm -it ubuntu");
+    fx.seed_pad(&state, "deploy runbook", "not a snippet");
+    state
+        .with_api(|api| api.add_tags_to_pads(state.scope, &["2"], &["snippet".into()]))
+        .unwrap();
+    let ctx = support::ctx_with_state(state);
+
+    let outcome: SnipOutcome = rendered(handlers::snip(&ctx, vec!["d".into(), "run".into()]));
+
+    assert_eq!(outcome.pad.metadata.title, "docker run");
+    assert_eq!(outcome.matched, 1, "untagged pads are not snippets");
+    assert_eq!(clipboard.writes(), vec!["docker run --rm -it ubuntu"]);
+}
+
+#[test]
+fn release_notes_outside_a_repository_gather_every_tagged_pad() {
+    let fx = Fixture::new();
+    let state = fx.app_state_for(&["release-notes"]);
+    fx.seed_pad(&state, "Retries", "The rate limiter retries twice now.");
+    fx.seed_pad(&state, "Scratch", "not for users");
+    state
+        .with_api(|api| api.add_tags_to_pads(state.scope, &["2"], &["release".into()]))
+        .unwrap();
+    let ctx = support::ctx_with_state(state);
+
+    let notes: ReleaseNotes = rendered(handlers::release_notes(&ctx, vec!["release".into()], None));
+
+    assert_eq!(notes.since, None, "no repository, so no last release");
+    assert_eq!(notes.notes.len(), 1);
+    assert_eq!(notes.notes[0].title, "Retries");
+    assert!(notes.commits.is_empty());
+
+    let err = handlers::release_notes(&ctx, vec!["release".into()], Some("v1.2.0".into()))
+        .expect_err("there is no v1.2.0 outside a repository");
+    assert!(err.to_string().contains("v1.2.0"));
+}
+
 // =============================================================================
 // Content family — copy
 // =============================================================================
@@ -332,6 +695,7 @@ fn copy_maps_a_single_root_to_typed_facts_and_one_semantic_write() {
         false,
         false,
         false,
+        false,
     ));
 
     assert_eq!(
@@ -339,6 +703,7 @@ fn copy_maps_a_single_root_to_typed_facts_and_one_semantic_write() {
         CopyView {
             root_pad_count: 1,
             titles: vec!["single".to_string()],
+            appended: false,
         }
     );
     assert_eq!(clipboard.writes(), vec!["single\n\nbody"]);
@@ -359,6 +724,7 @@ fn copy_maps_multiple_roots_in_display_order() {
         false,
         false,
         false,
+        false,
     ));
 
     assert_eq!(result.root_pad_count, 2);
@@ -384,6 +750,7 @@ fn copy_keeps_nested_content_in_the_payload_but_counts_only_the_root() {
         false,
         false,
         false,
+        false,
     ));
 
     assert_eq!(result.root_pad_count, 1);
@@ -394,6 +761,40 @@ fn copy_keeps_nested_content_in_the_payload_but_counts_only_the_root() {
     );
 }
 
+#[test]
+fn copy_append_keeps_what_the_clipboard_held() {
+    let fx = Fixture::new();
+    let (state, clipboard) = fx.app_state_with_recording_clipboard_for(&["copy", "1"]);
+    fx.seed_pad(&state, "first", "one");
+    fx.seed_pad(&state, "second", "two");
+    let ctx = support::ctx_with_state(state);
+
+    rendered::<CopyView>(handlers::copy(
+        &ctx,
+        vec!["2".to_string()],
+        false,
+        false,
+        false,
+        false,
+        false,
+    ));
+    let result: CopyView = rendered(handlers::copy(
+        &ctx,
+        vec!["1".to_string()],
+        false,
+        false,
+        false,
+        false,
+        true,
+    ));
+
+    assert!(result.appended);
+    assert_eq!(
+        clipboard.writes().last().unwrap(),
+        "first\n\none\n---\n\nsecond\n\ntwo"
+    );
+}
+
 // =============================================================================
 // Modification family — the semantic action each maps to
 // =============================================================================
@@ -411,7 +812,7 @@ fn modification_handlers_report_their_semantic_action() {
             handlers::pin(ctx, vec!["1".to_string()])
         }),
         ("delete", ModificationAction::Delete, |ctx| {
-            handlers::delete(ctx, vec!["1".to_string()], false)
+            handlers::delete(ctx, vec!["1".to_string()], false, None)
         }),
         ("archive", ModificationAction::Archive, |ctx| {
             handlers::archive(ctx, vec!["1".to_string()])
@@ -507,7 +908,7 @@ fn restore_maps_a_deleted_selector_back_to_active() {
     fx.seed_pad(&state, "gone", "");
     let ctx = support::ctx_with_state(state);
 
-    rendered(handlers::delete(&ctx, vec!["1".to_string()], false));
+    rendered(handlers::delete(&ctx, vec!["1".to_string()], false, None));
     let result = rendered(handlers::restore(&ctx, vec!["d1".to_string()]));
 
     assert_eq!(result.action, ModificationAction::Restore);
@@ -515,18 +916,147 @@ fn restore_maps_a_deleted_selector_back_to_active() {
 }
 
 #[test]
-fn move_without_root_needs_a_source_and_a_destination() {
+fn undo_takes_back_the_last_delete_and_again_redoes_it() {
     let fx = Fixture::new();
     let state = fx.app_state();
-    fx.seed_pad(&state, "only", "");
+    fx.seed_pad(&state, "gone", "");
     let ctx = support::ctx_with_state(state);
 
-    let err = handlers::move_pads(&ctx, vec!["1".to_string()], false)
-        .expect_err("a one-argument move has no destination and must fail");
-
-    assert!(
-        err.to_string().contains("at least 2"),
-        "the error should say what the user must supply, got: {err}"
+    rendered(handlers::delete(&ctx, vec!["1".to_string()], false, None));
+    let undone = rendered(handlers::undo(&ctx));
+    assert_eq!(undone.pads[0].title, "gone");
+    assert_eq!(
+        undone.pads[0].undone,
+        Undone::Returned {
+            from: Bucket::Deleted,
+            to: Bucket::Active,
+        }
+    );
+
+    let redone = rendered(handlers::undo(&ctx));
+    assert_eq!(
+        redone.pads[0].undone,
+        Undone::Returned {
+            from: Bucket::Active,
+            to: Bucket::Deleted,
+        }
+    );
+}
+
+#[test]
+fn name_sets_an_alias_that_later_selects_the_pad_and_clear_removes_it() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "TLS runbook", "");
+    let ctx = support::ctx_with_state(state);
+
+    let named = rendered(handlers::name(
+        &ctx,
+        "1".to_string(),
+        Some("runbook-tls".to_string()),
+        false,
+    ));
+    assert_eq!(named.action, ModificationAction::Name);
+    assert_eq!(
+        named.pads[0].pad.metadata.alias.as_deref(),
+        Some("runbook-tls")
+    );
+
+    let pinned = rendered(handlers::pin(&ctx, vec!["runbook-tls".to_string()]));
+    assert_eq!(pinned.pads[0].pad.metadata.title, "TLS runbook");
+
+    let cleared = rendered(handlers::name(&ctx, "runbook-tls".to_string(), None, true));
+    assert_eq!(cleared.pads[0].pad.metadata.alias, None);
+}
+
+#[test]
+fn link_records_project_relative_anchors_that_view_reports() {
+    let fx = Fixture::new();
+    std::fs::create_dir_all(fx.project().join("src")).unwrap();
+    std::fs::write(fx.project().join("src/tls.go"), "package tls\n").unwrap();
+    let state = fx.app_state().with_cwd(fx.project().join("src"));
+    fx.seed_pad(&state, "Flaky handshake", "");
+    let ctx = support::ctx_with_state(state);
+
+    let linked = rendered(handlers::link(
+        &ctx,
+        "1".to_string(),
+        vec!["tls.go:42".to_string()],
+        false,
+    ));
+    assert_eq!(linked.action, ModificationAction::Link);
+
+    let viewed: PadContentResult = rendered(handlers::view(
+        &ctx,
+        vec!["1".to_string()],
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+    ));
+    assert_eq!(viewed.pads[0].anchors, vec!["src/tls.go:42"]);
+
+    // A file that does not exist is refused rather than recorded.
+    let err = handlers::link(&ctx, "1".to_string(), vec!["nope.go:1".to_string()], false)
+        .expect_err("linking a missing file must fail");
+    assert!(err.to_string().contains("nope.go"), "got: {err}");
+
+    let unlinked = rendered(handlers::link(
+        &ctx,
+        "1".to_string(),
+        vec!["tls.go:42".to_string()],
+        true,
+    ));
+    assert_eq!(unlinked.action, ModificationAction::Unlink);
+    assert!(unlinked.pads[0].pad.metadata.anchors.is_empty());
+}
+
+#[test]
+fn timeline_lists_creation_then_what_happened_since() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "Release notes", "");
+    let ctx = support::ctx_with_state(state);
+
+    rendered(handlers::pin(&ctx, vec!["1".to_string()]));
+    rendered(handlers::unpin(&ctx, vec!["p1".to_string()]));
+
+    let outcome: TimelineOutcome = rendered(handlers::timeline(&ctx, vec!["1".to_string()]));
+    let kinds: Vec<_> = outcome.timelines[0]
+        .events
+        .iter()
+        .map(|e| e.kind.clone())
+        .collect();
+    assert_eq!(
+        kinds,
+        vec![
+            PadEventKind::Created,
+            PadEventKind::Pinned,
+            PadEventKind::Unpinned
+        ]
+    );
+    assert_eq!(outcome.timelines[0].pad.pad.metadata.title, "Release notes");
+}
+
+#[test]
+fn move_without_root_needs_a_source_and_a_destination() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "only", "");
+    let ctx = support::ctx_with_state(state);
+
+    let err = handlers::move_pads(&ctx, vec!["1".to_string()], false, None)
+        .expect_err("a one-argument move has no destination and must fail");
+
+    assert!(
+        err.to_string().contains("at least 2"),
+        "the error should say what the user must supply, got: {err}"
     );
 }
 
@@ -543,8 +1073,14 @@ fn move_to_root_needs_only_the_sources() {
         &ctx,
         vec!["1".to_string(), "2".to_string()],
         false,
+        None,
+    ));
+    let result = rendered(handlers::move_pads(
+        &ctx,
+        vec!["1.1".to_string()],
+        true,
+        None,
     ));
-    let result = rendered(handlers::move_pads(&ctx, vec!["1.1".to_string()], true));
 
     assert_eq!(result.action, ModificationAction::Move);
     assert!(
@@ -565,6 +1101,7 @@ fn same_parent_move_maps_the_nested_no_op_path() {
         &ctx,
         vec!["1.1".to_string(), "1".to_string()],
         false,
+        None,
     ));
 
     assert!(result.pads.is_empty());
@@ -580,6 +1117,49 @@ fn same_parent_move_maps_the_nested_no_op_path() {
     assert!(result.outcomes.is_empty());
 }
 
+#[test]
+fn move_to_global_relocates_pads_keeping_their_pins() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "runbook", "steps");
+    fx.seed_pad(&state, "scratch", "");
+    let ctx = support::ctx_with_state(state);
+    rendered(handlers::pin(&ctx, vec!["2".to_string()]));
+
+    let result = rendered(handlers::move_pads(
+        &ctx,
+        vec!["2".to_string()],
+        false,
+        Some("global".to_string()),
+    ));
+
+    assert_eq!(result.action, ModificationAction::Move);
+    assert_eq!(
+        result.outcomes,
+        vec![CmdOutcome::Relocated {
+            path: vec![padzapp::index::DisplayIndex::Regular(1)],
+            title: "runbook".to_string(),
+            project: None,
+        }]
+    );
+    assert!(result.pads[0].pad.metadata.is_pinned);
+    assert!(result.pads[0].pad.content.contains("steps"));
+    let left = rendered(handlers::search(
+        &ctx,
+        "runbook".to_string(),
+        false,
+        false,
+        true,
+        false,
+        vec![],
+        false,
+        false,
+        false,
+        false,
+    ));
+    assert!(left.pads.is_empty(), "the pad left the project store");
+}
+
 #[test]
 fn mixed_complete_maps_changed_pads_and_requested_status_no_ops() {
     let fx = Fixture::new();
@@ -618,7 +1198,7 @@ fn empty_delete_completed_maps_the_core_no_op() {
     fx.seed_pad(&state, "still open", "");
     let ctx = support::ctx_with_state(state);
 
-    let result = rendered(handlers::delete(&ctx, vec![], true));
+    let result = rendered(handlers::delete(&ctx, vec![], true, None));
 
     assert!(result.pads.is_empty());
     assert_eq!(result.notices, vec![CmdNotice::NoCompletedPads]);
@@ -680,7 +1260,7 @@ fn tag_assignment_maps_requested_tags_counts_and_no_op_kind() {
     let ctx = support::ctx_with_state(state);
     let args = vec!["1".into(), "work".into(), "rust".into()];
 
-    let changed: TaggingResult = rendered(handlers::tag::add(&ctx, args.clone()));
+    let changed: TaggingResult = rendered(handlers::tag::add(&ctx, args.clone(), None));
     assert_eq!(changed.affected_pads.len(), 1);
     match changed.outcome {
         TaggingOutcome::Assigned {
@@ -693,7 +1273,7 @@ fn tag_assignment_maps_requested_tags_counts_and_no_op_kind() {
         other => panic!("expected assigned outcome, got {other:?}"),
     }
 
-    let no_op: TaggingResult = rendered(handlers::tag::add(&ctx, args));
+    let no_op: TaggingResult = rendered(handlers::tag::add(&ctx, args, None));
     assert_eq!(no_op.affected_pads.len(), 1);
     match no_op.outcome {
         TaggingOutcome::AllAlreadyPresent {
@@ -714,9 +1294,9 @@ fn tag_removal_maps_requested_tags_counts_and_no_op_kind() {
     fx.seed_pad(&state, "target", "");
     let ctx = support::ctx_with_state(state);
     let args = vec!["1".into(), "work".into()];
-    rendered(handlers::tag::add(&ctx, args.clone()));
+    rendered(handlers::tag::add(&ctx, args.clone(), None));
 
-    let changed: TaggingResult = rendered(handlers::tag::remove(&ctx, args.clone()));
+    let changed: TaggingResult = rendered(handlers::tag::remove(&ctx, args.clone(), None));
     assert!(matches!(
         changed.outcome,
         TaggingOutcome::Removed {
@@ -725,7 +1305,7 @@ fn tag_removal_maps_requested_tags_counts_and_no_op_kind() {
         } if requested_tags == vec!["work"]
     ));
 
-    let no_op: TaggingResult = rendered(handlers::tag::remove(&ctx, args));
+    let no_op: TaggingResult = rendered(handlers::tag::remove(&ctx, args, None));
     assert!(matches!(
         no_op.outcome,
         TaggingOutcome::NonePresent {
@@ -755,6 +1335,7 @@ fn tag_registry_handlers_map_names_and_affected_pad_counts() {
             old_name: "old".into(),
             new_name: "new".into(),
             affected_pads: 1,
+            pads: vec!["target".into()],
         }
     );
 
@@ -825,6 +1406,101 @@ fn uuid_maps_single_multiple_and_range_selectors_in_order() {
 // Export artifacts
 // =============================================================================
 
+#[test]
+fn where_queries_select_the_pads_to_list_tag_and_delete() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "keep", "");
+    fx.seed_pad(&state, "stale", "");
+    let ctx = support::ctx_with_state(state);
+    let list_where = |query: &str| {
+        titles(&rendered(handlers::list(
+            &ctx,
+            vec![],
+            None,
+            false,
+            false,
+            false,
+            false,
+            false,
+            false,
+            false,
+            vec![],
+            false,
+            false,
+            None,
+            None,
+            false,
+            Some(query.into()),
+            false,
+        )))
+    };
+    rendered(handlers::tag::add(
+        &ctx,
+        vec!["1".into(), "old".into()],
+        None,
+    ));
+
+    assert_eq!(list_where("tag=old"), vec!["stale"]);
+
+    let tagged: TaggingResult = rendered(handlers::tag::add(
+        &ctx,
+        vec!["fresh".into()],
+        Some("tag!=old and created<1d".into()),
+    ));
+    assert_eq!(tagged.affected_pads.len(), 1);
+    assert_eq!(tagged.affected_pads[0].pad.metadata.title, "keep");
+
+    let deleted = rendered(handlers::delete(
+        &ctx,
+        vec![],
+        false,
+        Some("tag=old".into()),
+    ));
+    assert_eq!(deleted.pads[0].pad.metadata.title, "stale");
+    assert_eq!(list_where("tag=fresh"), vec!["keep"]);
+
+    assert!(handlers::delete(&ctx, vec![], false, Some("tag=old".into())).is_err());
+    assert!(handlers::delete(&ctx, vec![], false, Some("colour=red".into())).is_err());
+}
+
+#[test]
+fn compile_merges_tagged_pads_into_the_requested_destination() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "Setup", "Run make.");
+    fx.seed_pad(&state, "Scratch", "not for docs");
+    let ctx = support::ctx_with_state(state);
+    rendered(handlers::tag::add(
+        &ctx,
+        vec!["2".into(), "docs".into()],
+        None,
+    ));
+
+    let Output::Artifact(artifact) = handlers::compile(
+        &ctx,
+        vec![],
+        vec!["docs".into()],
+        "docs/NOTES.md".into(),
+        "Project notes".into(),
+        None,
+        false,
+    )
+    .expect("compile handler failed") else {
+        panic!("expected an artifact");
+    };
+
+    let text = String::from_utf8(artifact.bytes().to_vec()).unwrap();
+    assert!(text.starts_with("# Project notes\n\n## Setup"));
+    assert!(!text.contains("Scratch"));
+    assert!(artifact
+        .suggested_destination()
+        .is_some_and(|path| path.to_string_lossy() == "docs/NOTES.md"));
+    let report: &ExportReport = artifact.report().expect("artifact report");
+    assert_eq!(report.format, ExportFormat::Compiled);
+    assert_eq!(report.exported, 1);
+}
+
 #[test]
 fn export_maps_core_bytes_suggestion_and_report_without_writing() {
     let fx = Fixture::new();
@@ -832,10 +1508,24 @@ fn export_maps_core_bytes_suggestion_and_report_without_writing() {
     fx.seed_pad(&state, "plain text", "body");
     let ctx = support::ctx_with_state(state);
 
-    let Output::Artifact(artifact) =
-        handlers::export(&ctx, None, false, true, vec![], false, false, false)
-            .expect("export handler failed")
-    else {
+    let Output::Artifact(artifact) = handlers::export(
+        &ctx,
+        None,
+        false,
+        None,
+        true,
+        None,
+        false,
+        vec![],
+        false,
+        false,
+        false,
+        false,
+        None,
+        false,
+        None,
+    )
+    .expect("export handler failed") else {
         panic!("expected an artifact");
     };
 
@@ -854,24 +1544,204 @@ fn export_maps_core_bytes_suggestion_and_report_without_writing() {
 }
 
 #[test]
-fn empty_export_stays_a_non_artifact_result() {
+fn export_format_zip_is_one_zip_artifact() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "Plans", "body");
+    let ctx = support::ctx_with_state(state);
+
+    let Output::Artifact(artifact) = handlers::export(
+        &ctx,
+        None,
+        false,
+        Some(ExportArchive::Zip),
+        false,
+        None,
+        false,
+        vec![],
+        false,
+        false,
+        false,
+        false,
+        None,
+        false,
+        None,
+    )
+    .expect("export handler failed") else {
+        panic!("expected an artifact");
+    };
+
+    assert_eq!(&artifact.bytes()[..2], b"PK", "zip magic");
+    assert!(artifact
+        .suggested_destination()
+        .is_some_and(|path| path.to_string_lossy().ends_with(".zip")));
+    let report: &ExportReport = artifact.report().expect("artifact report");
+    assert_eq!(report.format, ExportFormat::ZipArchive);
+    assert_eq!(report.exported, 1);
+}
+
+#[test]
+fn csv_export_is_an_artifact_of_the_requested_columns() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "Budget, 2026", "body");
+    let ctx = support::ctx_with_state(state);
+
+    let Output::Artifact(artifact) = handlers::export(
+        &ctx,
+        None,
+        false,
+        None,
+        false,
+        None,
+        false,
+        vec![],
+        false,
+        false,
+        false,
+        false,
+        None,
+        true,
+        Some("title,tags".to_string()),
+    )
+    .expect("export handler failed") else {
+        panic!("expected an artifact");
+    };
+
+    assert!(artifact
+        .suggested_destination()
+        .is_some_and(|path| path.to_string_lossy().ends_with(".csv")));
+    assert_eq!(
+        String::from_utf8_lossy(artifact.bytes()),
+        "title,tags\n\"Budget, 2026\",\n"
+    );
+    let report: &ExportReport = artifact.report().expect("artifact report");
+    assert_eq!(report.format, ExportFormat::Csv);
+    assert_eq!(report.exported, 1);
+}
+
+#[test]
+fn empty_export_stays_a_non_artifact_result() {
+    let fx = Fixture::new();
+    let ctx = fx.ctx();
+
+    let report = rendered(handlers::export(
+        &ctx,
+        None,
+        false,
+        None,
+        false,
+        None,
+        false,
+        vec![],
+        false,
+        false,
+        false,
+        false,
+        None,
+        false,
+        None,
+    ));
+
+    assert_eq!(report.format, ExportFormat::Archive);
+    assert_eq!(report.exported, 0);
+    assert!(report.warnings.is_empty());
+}
+
+#[test]
+fn dry_run_refuses_a_signed_export() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "Groceries", "Milk");
+    let ctx = support::ctx_with_state(
+        fx.app_state_for(&["--dry-run", "export"])
+            .with_signer(Rc::new(FixedSigner(SignatureStatus::Good))),
+    );
+
+    let err = handlers::export(
+        &ctx,
+        None,
+        false,
+        None,
+        false,
+        None,
+        false,
+        vec![],
+        false,
+        false,
+        false,
+        true,
+        None,
+        false,
+        None,
+    )
+    .expect_err("the archive and its signature would be written outside the store");
+
+    assert!(err.to_string().contains("--dry-run"));
+}
+
+#[test]
+fn verify_returns_the_check_only_for_a_good_signature() {
+    let fx = Fixture::new();
+    let dir = tempfile::TempDir::new().unwrap();
+    let archive = dir.path().join("padz-export.json.tar.gz");
+    std::fs::write(&archive, b"archive").unwrap();
+    std::fs::write(signature_path(&archive), "sig").unwrap();
+    let archive = archive.to_str().unwrap().to_string();
+
+    let good = support::ctx_with_state(
+        fx.app_state()
+            .with_signer(Rc::new(FixedSigner(SignatureStatus::Good))),
+    );
+    let VerifyView::Signature(check) =
+        rendered(handlers::verify(&good, Some(archive.clone()), None, false))
+    else {
+        panic!("a file argument checks its signature");
+    };
+    assert_eq!(check.status, SignatureStatus::Good);
+    assert_eq!(check.signer.as_deref(), Some("Test Signer"));
+
+    let bad = support::ctx_with_state(
+        fx.app_state()
+            .with_signer(Rc::new(FixedSigner(SignatureStatus::Bad))),
+    );
+    let err = handlers::verify(&bad, Some(archive), None, false)
+        .expect_err("a bad signature must fail the command");
+    assert!(err.to_string().contains("BAD signature"));
+}
+
+#[test]
+fn verify_without_a_signature_file_is_an_error() {
     let fx = Fixture::new();
     let ctx = fx.ctx();
 
-    let report = rendered(handlers::export(
-        &ctx,
-        None,
-        false,
-        false,
-        vec![],
-        false,
-        false,
-        false,
-    ));
+    let err = handlers::verify(&ctx, Some("nowhere.tar.gz".to_string()), None, false)
+        .expect_err("no signature, nothing to verify");
 
-    assert_eq!(report.format, ExportFormat::Archive);
-    assert_eq!(report.exported, 0);
-    assert!(report.warnings.is_empty());
+    assert!(err.to_string().contains("No signature found"));
+}
+
+#[test]
+fn verify_without_a_file_reports_tampered_pads_until_accepted() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "Groceries", "Milk");
+    let ctx = support::ctx_with_state(state);
+    let paths: PathView = rendered(handlers::path(&ctx, vec!["1".to_string()]));
+    std::fs::write(&paths.paths[0], "Groceries\n\nEdited by hand\n").unwrap();
+
+    let VerifyView::Store(check) = rendered(handlers::verify(&ctx, None, None, false)) else {
+        panic!("no file argument checks the store");
+    };
+    assert_eq!(check.report.issues.len(), 1);
+    assert_eq!(check.report.issues[0].problem, IntegrityProblem::Mismatch);
+    assert_eq!(check.accepted, None);
+
+    let VerifyView::Store(check) = rendered(handlers::verify(&ctx, None, None, true)) else {
+        panic!("no file argument checks the store");
+    };
+    assert_eq!(check.accepted, Some(1));
+    assert!(check.report.issues.is_empty());
 }
 
 // =============================================================================
@@ -890,7 +1760,13 @@ fn import_maps_core_source_and_metadata_warning_facts() {
     let state = fx.app_state_for(&["import", source.to_str().unwrap()]);
     let ctx = support::ctx_with_state(state);
 
-    let result: ImportReport = rendered(handlers::import(&ctx, vec![source.display().to_string()]));
+    let result: ImportReport = rendered(handlers::import(
+        &ctx,
+        vec![source.display().to_string()],
+        false,
+        false,
+        false,
+    ));
 
     assert_eq!(result.status, ImportStatus::PartialSuccess);
     assert_eq!(result.total_imported, 1);
@@ -1002,10 +1878,185 @@ fn doctor_maps_a_healthy_store_to_a_clean_result() {
         DoctorOutcome::Clean {
             missing_files: 0,
             recovered_files: 0,
+            remapped_paths: 0,
         }
     );
 }
 
+#[test]
+fn maintain_finds_nothing_to_collect_in_a_healthy_store() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "note", "");
+    let ctx = support::ctx_with_state(state);
+
+    let result = rendered(handlers::maintain(&ctx));
+
+    assert_eq!(result.outcome, MaintainOutcome::Clean);
+    assert_eq!(result.trash, None);
+}
+
+#[test]
+fn list_and_maintain_mention_a_trash_past_its_limit() {
+    let fx = Fixture::new();
+    let state = fx.app_state().with_trash_config(TrashConfig {
+        warn_pads: 1,
+        warn_bytes: 0,
+    });
+    fx.seed_pad(&state, "one", "");
+    fx.seed_pad(&state, "two", "");
+    fx.seed_pad(&state, "kept", "");
+    let ctx = support::ctx_with_state(state);
+    rendered(handlers::delete(
+        &ctx,
+        vec!["2".to_string(), "3".to_string()],
+        false,
+        None,
+    ));
+
+    let listing = rendered(handlers::list(
+        &ctx,
+        vec![],
+        None,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        vec![],
+        false,
+        false,
+        None,
+        None,
+        false,
+        None,
+        false,
+    ));
+    assert_eq!(listing.trash.map(|trash| trash.pads), Some(2));
+
+    let maintained = rendered(handlers::maintain(&ctx));
+    assert_eq!(maintained.trash, listing.trash);
+}
+
+#[test]
+fn tree_shows_the_project_with_its_pinned_pad() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "Groceries", "");
+    fx.seed_pad(&state, "Standup", "");
+    let ctx = support::ctx_with_state(state);
+    rendered(handlers::pin(&ctx, vec!["1".to_string()]));
+
+    let tree: TreeOutcome = rendered(handlers::tree(&ctx));
+
+    assert_eq!(tree.global.active, 0);
+    assert_eq!(tree.projects.len(), 1);
+    let project = &tree.projects[0];
+    assert_eq!(project.active, 2);
+    assert_eq!(project.pinned.len(), 1);
+    assert_eq!(project.pinned[0].title, "Standup");
+    assert_eq!(project.recent.len(), 1);
+    assert_eq!(project.recent[0].title, "Groceries");
+}
+
+#[test]
+fn scopes_prune_lists_gone_projects_and_forgets_them_with_yes() {
+    let fx = Fixture::new();
+    let gone = fx.root().join("gone").join(".padz");
+    std::fs::create_dir_all(&gone).unwrap();
+    padzapp::store::registry::register(&fx.root().join("global"), &gone).unwrap();
+    std::fs::remove_dir_all(fx.root().join("gone")).unwrap();
+    // Opening the fixture's project registers it too; it still exists.
+    let ctx = support::ctx_with_state(fx.app_state());
+
+    let PruneOutcome::Found { orphans } = rendered(handlers::scopes::prune(&ctx, false)) else {
+        panic!("expected the gone project to be found");
+    };
+    assert_eq!(orphans.len(), 1);
+    assert!(orphans[0].project.ends_with("gone"));
+
+    let pruned: PruneOutcome = rendered(handlers::scopes::prune(&ctx, true));
+    assert!(matches!(pruned, PruneOutcome::Pruned { .. }));
+    let again: PruneOutcome = rendered(handlers::scopes::prune(&ctx, true));
+    assert_eq!(again, PruneOutcome::Clean);
+}
+
+#[test]
+fn scopes_add_registers_a_remote_under_its_name() {
+    let fx = Fixture::new();
+    let global = fx.root().join("global");
+    let ctx = support::ctx_with_state(fx.app_state());
+
+    let added: RemoteAdded = rendered(handlers::scopes::add(
+        &ctx,
+        "devbox".into(),
+        "ssh://me@devbox/srv/app".into(),
+    ));
+
+    assert_eq!(added.cache, global.join("remotes/devbox/srv_app/.padz"));
+    let remote = padzapp::commands::scopes::resolve_remote(&global, "devbox").unwrap();
+    assert_eq!(remote.destination, "me@devbox");
+    handlers::scopes::add(&ctx, "devbox".into(), "devbox:/srv/app".into())
+        .expect_err("only ssh:// addresses are remotes");
+}
+
+#[test]
+fn journal_ls_lists_the_days_under_the_configured_journal() {
+    let fx = Fixture::new();
+    let state = fx.app_state().with_journal_config(JournalConfig {
+        parent: "Log".to_string(),
+    });
+    let day = |d| chrono::NaiveDate::from_ymd_opt(2026, 10, d).unwrap();
+    let scope = state.scope;
+    for d in [14, 15] {
+        state
+            .with_api(|api| api.journal_day(scope, "Log", day(d)))
+            .unwrap();
+    }
+    let ctx = support::ctx_with_state(state);
+
+    let outcome: JournalOutcome = rendered(handlers::journal::ls(&ctx));
+
+    assert!(outcome.journal.is_some());
+    let titles: Vec<_> = outcome
+        .days
+        .iter()
+        .map(|dp| dp.pad.metadata.title.as_str())
+        .collect();
+    assert_eq!(titles, ["2026-10-15", "2026-10-14"]);
+}
+
+#[test]
+fn flush_queue_list_shows_pending_operations_without_retrying() {
+    let fx = Fixture::new();
+    let ctx = support::ctx_with_state(fx.app_state());
+    assert_eq!(
+        rendered(handlers::flush_queue(&ctx, true)),
+        QueueReport::Empty
+    );
+
+    let path = queue_path(&fx.root().join("global"));
+    let mut queue = OperationQueue::default();
+    queue.record_failure(
+        Operation::GlobalStorePush {
+            url: "s3://notes/padz".into(),
+            endpoint: None,
+        },
+        "Could not connect to the endpoint URL",
+    );
+    queue.save(&path).unwrap();
+
+    let QueueReport::Pending { pending } = rendered(handlers::flush_queue(&ctx, true)) else {
+        panic!("expected the queued upload to be listed");
+    };
+    assert_eq!(pending.len(), 1);
+    assert_eq!(pending[0].attempts, 1);
+    // Listing leaves the queue as it was.
+    assert_eq!(OperationQueue::load(&path).unwrap(), queue);
+}
+
 #[test]
 fn purge_maps_selected_pads_and_counts() {
     let fx = Fixture::new();
@@ -1052,6 +2103,53 @@ fn purge_maps_a_nested_selection_with_its_complete_path() {
     assert_eq!(selected_pads[0].pad.pad.metadata.title, "child");
 }
 
+// =============================================================================
+// Explain — a dry run, reported
+// =============================================================================
+
+#[test]
+fn explain_reports_a_delete_without_making_it() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "Groceries", "milk");
+    drop(state);
+
+    let args = ["--dry-run", "delete", "1"];
+    let argv: Vec<String> = fx.argv(&args).into_iter().map(String::from).collect();
+    let view = explain::explain(fx.app_state_for(&args), &argv, "delete 1".into())
+        .expect("a dry run explains");
+
+    assert_eq!(view.scope, Scope::Project);
+    assert_eq!(view.pads.len(), 1);
+    assert_eq!(view.pads[0].1, "Groceries");
+    assert_eq!(view.changes.len(), 1);
+    assert_eq!(view.changes[0].kind, EventKind::Deleted);
+    assert!(view
+        .files
+        .iter()
+        .any(|file| file.ends_with("deleted/data.json")));
+    assert_eq!(view.error, None);
+
+    // Nothing was deleted.
+    let state = fx.app_state();
+    let active = state
+        .with_api(|api| api.get_pads(state.scope, PadFilter::default(), &["1"]))
+        .unwrap();
+    assert_eq!(active.listed_pads[0].pad.metadata.title, "Groceries");
+}
+
+#[test]
+fn explain_reports_why_a_command_would_fail() {
+    let fx = Fixture::new();
+    let args = ["--dry-run", "delete", "7"];
+    let argv: Vec<String> = fx.argv(&args).into_iter().map(String::from).collect();
+    let view = explain::explain(fx.app_state_for(&args), &argv, "delete 7".into()).unwrap();
+
+    assert!(view.changes.is_empty());
+    assert!(view.files.is_empty());
+    assert!(view.error.is_some());
+}
+
 // =============================================================================
 // Input-chain consumers — create / edit
 // =============================================================================
@@ -1069,13 +2167,88 @@ fn create_with_direct_content_splits_title_from_body() {
         RequestContent::Direct("the title\nthe body".to_string()),
     );
 
-    let result = created(handlers::create(&ctx, None, None, vec![]));
+    let result = created(handlers::create(&ctx, None, None, vec![], false, None));
 
     assert_eq!(result.action, ModificationAction::Create);
     assert_eq!(result.pads[0].pad.metadata.title, "the title");
     assert!(result.pads[0].pad.content.contains("the body"));
 }
 
+#[test]
+fn create_starts_from_the_configured_template_unless_blank() {
+    let fx = Fixture::new();
+    let template = fx.root().join("bug.md");
+    let skeleton = "Steps to reproduce:";
+    std::fs::write(&template, format!("{skeleton}\n\nExpected:\n")).unwrap();
+    let create = |blank: bool| {
+        let state = fx
+            .app_state_for(&["create"])
+            .with_create_template(Some(template.clone()));
+        let ctx = support::ctx_with_input(
+            state,
+            CREATE_CONTENT,
+            RequestContent::Direct("Login loops".to_string()),
+        );
+        created(handlers::create(&ctx, None, None, vec![], blank, None))
+    };
+
+    let templated = create(false);
+    assert_eq!(templated.pads[0].pad.metadata.title, "Login loops");
+    assert!(templated.pads[0].pad.content.contains(skeleton));
+
+    let blank = create(true);
+    assert!(!blank.pads[0].pad.content.contains(skeleton));
+}
+
+#[test]
+fn create_from_a_command_titles_the_pad_with_the_command() {
+    let fx = Fixture::new();
+    let ctx = support::ctx_with_input(
+        fx.app_state_for(&["create"]),
+        CREATE_CONTENT,
+        RequestContent::Command {
+            command: "echo pod is ready".to_string(),
+            edit: false,
+        },
+    );
+
+    let result = created(handlers::create(&ctx, None, None, vec![], false, None));
+
+    assert_eq!(result.pads[0].pad.metadata.title, "echo pod is ready");
+    assert!(result.pads[0].pad.content.contains("pod is ready"));
+}
+
+#[test]
+fn create_with_a_taken_title_appends_when_configured_to() {
+    let fx = Fixture::new();
+    let create = |text: &str, policy: DuplicateTitle| {
+        let ctx = support::ctx_with_input(
+            fx.app_state_for(&["create"]).with_duplicate_title(policy),
+            CREATE_CONTENT,
+            RequestContent::Direct(text.to_string()),
+        );
+        rendered(handlers::create(&ctx, None, None, vec![], false, None))
+    };
+
+    let first = create("Groceries\nmilk", DuplicateTitle::Append);
+    assert_eq!(first.action, ModificationAction::Create);
+    let appended = create("Groceries\neggs", DuplicateTitle::Append);
+    assert_eq!(appended.action, ModificationAction::Update);
+    assert_eq!(
+        appended.pads[0].pad.metadata.id,
+        first.pads[0].pad.metadata.id
+    );
+    assert!(appended.pads[0].pad.content.contains("milk"));
+    assert!(appended.pads[0].pad.content.contains("eggs"));
+
+    // Without a terminal there is no one to ask, so `ask` creates another.
+    let second = create("Groceries\nbread", DuplicateTitle::Ask);
+    assert_eq!(second.action, ModificationAction::Create);
+    let state = fx.app_state();
+    let counts = state.with_api(|api| api.stats(state.scope)).unwrap();
+    assert_eq!(counts.active, 2);
+}
+
 #[test]
 fn create_maps_typed_format_values_to_core_format_overrides() {
     for (format, expected_extension) in [("md", "md"), ("markdown", "md"), ("text", "txt")] {
@@ -1098,6 +2271,8 @@ fn create_maps_typed_format_values_to_core_format_overrides() {
             None,
             Some(format.to_string()),
             vec![],
+            false,
+            None,
         ));
         let id = result.pads[0].pad.metadata.id;
         let expected_path = fx
@@ -1113,13 +2288,80 @@ fn create_maps_typed_format_values_to_core_format_overrides() {
     }
 }
 
+#[test]
+fn dry_run_create_reports_the_pad_without_writing_it() {
+    let fx = Fixture::new();
+    let ctx = support::ctx_with_input(
+        fx.app_state_for(&["--dry-run", "create"]),
+        CREATE_CONTENT,
+        RequestContent::Direct("the title\nthe body".to_string()),
+    );
+
+    let result = created(handlers::create(&ctx, None, None, vec![], false, None));
+
+    assert_eq!(result.pads[0].pad.metadata.title, "the title");
+    let state = fx.app_state();
+    let counts = state
+        .with_api(|api| api.stats(state.scope))
+        .expect("stats on the real store");
+    assert_eq!(counts.active, 0, "a dry run leaves the store untouched");
+}
+
+#[test]
+fn dry_run_refuses_to_open_the_editor() {
+    let fx = Fixture::new();
+    let ctx = support::ctx_with_input(
+        fx.app_state_for(&["--dry-run", "create"]),
+        CREATE_CONTENT,
+        RequestContent::Editor,
+    );
+
+    let err = handlers::create(&ctx, None, None, vec![], false, None)
+        .expect_err("the editor needs a real file, which a dry run never writes");
+
+    assert!(err.to_string().contains("--dry-run"));
+}
+
+#[test]
+fn without_a_terminal_create_does_not_open_the_editor() {
+    let fx = Fixture::new();
+    let state = fx.app_state_for(&["create"]);
+    let ctx = support::ctx_with_input(state, CREATE_CONTENT, RequestContent::Editor);
+
+    let err = handlers::create(&ctx, None, None, vec![], false, None)
+        .expect_err("there is no terminal to run the editor in");
+
+    assert!(err.to_string().contains("on stdin"), "got: {err}");
+    let listed = rendered(handlers::list(
+        &ctx,
+        vec![],
+        None,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        false,
+        vec![],
+        false,
+        false,
+        None,
+        None,
+        false,
+        None,
+        false,
+    ));
+    assert!(listed.pads.is_empty(), "no pad is left behind");
+}
+
 #[test]
 fn create_with_an_empty_pipe_aborts_without_creating_a_pad() {
     let fx = Fixture::new();
     let state = fx.app_state_for(&["create"]);
     let ctx = support::ctx_with_input(state, CREATE_CONTENT, RequestContent::PipedEmpty);
 
-    let result = rendered(handlers::create(&ctx, None, None, vec![]));
+    let result = rendered(handlers::create(&ctx, None, None, vec![], false, None));
 
     // An aborted create is a `create` modification that affected no pads — the
     // shape `modification_result.jinja` renders as the empty-content warning.
@@ -1145,6 +2387,11 @@ fn create_with_an_empty_pipe_aborts_without_creating_a_pad() {
         vec![],
         false,
         false,
+        None,
+        None,
+        false,
+        None,
+        false,
     ));
     assert!(listed.pads.is_empty());
 }
@@ -1158,7 +2405,7 @@ fn create_piped_content_takes_its_title_from_the_first_line() {
         RequestContent::Piped("piped title\npiped body".to_string()),
     );
 
-    let result = created(handlers::create(&ctx, None, None, vec![]));
+    let result = created(handlers::create(&ctx, None, None, vec![], false, None));
 
     assert_eq!(result.pads[0].pad.metadata.title, "piped title");
 }
@@ -1177,6 +2424,8 @@ fn create_prefers_the_title_argument_over_the_piped_title() {
         None,
         None,
         vec!["argument".to_string(), "title".to_string()],
+        false,
+        None,
     ));
 
     assert_eq!(
@@ -1194,7 +2443,8 @@ fn edit_without_a_selector_is_an_error() {
         RequestContent::Direct("new text".to_string()),
     );
 
-    let err = handlers::edit(&ctx, vec![]).expect_err("edit needs to know which pad to change");
+    let err =
+        handlers::edit(&ctx, vec![], false).expect_err("edit needs to know which pad to change");
 
     assert!(err.to_string().contains("No pad index"), "got: {err}");
 }
@@ -1210,7 +2460,7 @@ fn edit_with_direct_content_replaces_the_selected_pads_text() {
         RequestContent::Direct("after\nnew body".to_string()),
     );
 
-    let result = rendered(handlers::edit(&ctx, vec!["1".to_string()]));
+    let result = rendered(handlers::edit(&ctx, vec!["1".to_string()], false));
 
     assert_eq!(result.pads[0].pad.metadata.title, "after");
     assert!(result.pads[0].pad.content.contains("new body"));
@@ -1236,7 +2486,7 @@ fn edit_maps_a_nested_canonical_path_without_parsing_prose() {
         RequestContent::Direct("edited child".to_string()),
     );
 
-    let result = rendered(handlers::edit(&ctx, vec!["1.1".to_string()]));
+    let result = rendered(handlers::edit(&ctx, vec!["1.1".to_string()], false));
 
     assert_eq!(
         result.outcomes,
@@ -1250,3 +2500,71 @@ fn edit_maps_a_nested_canonical_path_without_parsing_prose() {
         }]
     );
 }
+
+#[test]
+fn append_and_prepend_keep_what_the_pad_already_holds() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "log", "first");
+    let ctx = support::ctx_with_input(
+        state,
+        AMEND_CONTENT,
+        RequestContent::Piped("second".to_string()),
+    );
+
+    let appended = rendered(handlers::append(&ctx, "1".to_string()));
+    assert_eq!(appended.action, ModificationAction::Update);
+    assert_eq!(appended.pads[0].pad.metadata.title, "log");
+    assert_eq!(appended.pads[0].pad.content, "log\n\nfirst\nsecond");
+
+    let prepended = rendered(handlers::prepend(&ctx, "1".to_string()));
+    assert_eq!(prepended.pads[0].pad.metadata.title, "second");
+    assert_eq!(
+        prepended.pads[0].pad.content,
+        "second\n\nlog\n\nfirst\nsecond"
+    );
+}
+
+#[test]
+fn a_reference_lists_a_global_pad_and_edits_reach_it() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    state
+        .with_api(|api| api.create_pad(Scope::Global, "Runbook".into(), "restart".into(), None))
+        .unwrap();
+    let ctx = support::ctx_with_input(
+        state,
+        AMEND_CONTENT,
+        RequestContent::Piped("then check the logs".to_string()),
+    );
+
+    let added = rendered(handlers::refs::add(&ctx, vec!["1".to_string()]));
+    assert_eq!(added.action, ModificationAction::Ref);
+    assert!(added.pads[0].pad.metadata.source.is_some());
+
+    let appended = rendered(handlers::append(&ctx, "1".to_string()));
+    assert_eq!(
+        appended.pads[0].pad.content,
+        "Runbook\n\nrestart\nthen check the logs"
+    );
+    let global = fx
+        .app_state()
+        .with_api(|api| api.view_pads(Scope::Global, &["1"], NestingMode::Flat))
+        .unwrap();
+    assert_eq!(
+        global.listed_pads[0].pad.content,
+        appended.pads[0].pad.content
+    );
+}
+
+#[test]
+fn append_without_text_does_not_open_an_editor() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "log", "");
+    let ctx = support::ctx_with_input(state, AMEND_CONTENT, RequestContent::Editor);
+
+    let err = handlers::append(&ctx, "1".to_string()).expect_err("nothing was given to add");
+
+    assert!(err.to_string().contains("Nothing to add"), "got: {err}");
+}
@@ -40,6 +40,8 @@
 
 mod support;
 
+use clap::Parser;
+use padz::cli::clipboard::ClipboardWriter;
 use standout::cli::{ExitStatus, OutputKind, RunErrorKind, SuccessKind};
 use standout_test::{serial, TestHarness};
 use support::Fixture;
@@ -239,6 +241,130 @@ fn an_explicit_command_keeps_precedence_over_piped_stdin() {
     );
 }
 
+/// Argv tokens the generated invocations below are spliced from: commands
+/// that take selectors, selector syntax good and bad, flags, and words that
+/// could be mistaken for a title. `create` and its alias `n` are left out, and
+/// so is `--data`, which would point the run at a store outside the fixture.
+const ARGV_TOKENS: &[&str] = &[
+    "list",
+    "view",
+    "peek",
+    "search",
+    "edit",
+    "delete",
+    "pin",
+    "open",
+    "1",
+    "p1",
+    "d2",
+    "ar3",
+    "1-3",
+    "3.1",
+    "0",
+    "-1",
+    "99999999999999999999",
+    "766d5dab",
+    "groceries",
+    "é",
+    "",
+    "-",
+    "--",
+    "--peek",
+    "--all",
+    "-g",
+    "--dry-run",
+    "--output",
+    "json",
+    "--yes",
+    "-n",
+    "help",
+];
+
+/// The ids of every pad in the fixture's store, in any bucket, and how many of
+/// them are active.
+fn store_ids(fx: &Fixture) -> (std::collections::BTreeSet<String>, usize) {
+    use padzapp::api::{PadFilter, PadStatusFilter};
+    fn collect(pads: &[padzapp::index::DisplayPad], ids: &mut std::collections::BTreeSet<String>) {
+        for dp in pads {
+            ids.insert(dp.pad.metadata.id.to_string());
+            collect(&dp.children, ids);
+        }
+    }
+    let state = fx.app_state();
+    let no_ids: &[&str] = &[];
+    let listed = |status| {
+        state
+            .with_api(|api| {
+                let filter = PadFilter {
+                    status,
+                    ..PadFilter::default()
+                };
+                api.get_pads(state.scope, filter, no_ids)
+            })
+            .expect("listing the fixture store")
+            .listed_pads
+    };
+    let mut ids = std::collections::BTreeSet::new();
+    collect(&listed(PadStatusFilter::All), &mut ids);
+    (ids, listed(PadStatusFilter::Active).len())
+}
+
+/// Generated argv, run through the real dispatch with stdin piped and with it
+/// a terminal, must be a usage error or a routed command, never a panic; and
+/// only a naked invocation with piped stdin may create a pad, through the
+/// invocation-aware default command. A selector typo must not quietly turn
+/// into a new pad.
+#[test]
+#[serial]
+fn generated_invocations_never_create_by_accident() {
+    let fx = Fixture::new();
+    let mut state: u64 = 0x2545_f491_4f6c_dd1d;
+    let mut next = move || {
+        state ^= state << 13;
+        state ^= state >> 7;
+        state ^= state << 17;
+        state as usize
+    };
+
+    for round in 0..600 {
+        let piped = round % 2 == 0;
+        let (before, active) = store_ids(&fx);
+        if active < 3 {
+            let seeding = fx.app_state();
+            for title in ["Groceries", "Standup", "Release"] {
+                fx.seed_pad(&seeding, title, "body");
+            }
+            continue;
+        }
+
+        let len = next() % 5;
+        let args: Vec<&str> = (0..len)
+            .map(|_| ARGV_TOKENS[next() % ARGV_TOKENS.len()])
+            .collect();
+        let argv = fx.argv(&args);
+        let naked = padz::cli::setup::Cli::try_parse_from(argv.iter().copied())
+            .is_ok_and(|cli| cli.command.is_none());
+
+        let (app, cmd) = fx.read_app();
+        let harness = TestHarness::new().no_color();
+        let harness = if piped {
+            harness.piped_stdin("Intruder\n\nfrom a pipe")
+        } else {
+            harness.interactive_stdin()
+        };
+        let result = harness.run(&app, cmd, argv);
+        drop(result);
+
+        let (after, _) = store_ids(&fx);
+        let created = after.difference(&before).count();
+        assert!(
+            created == 0 || (piped && naked),
+            "{args:?} with {} stdin created {created} pad(s)",
+            if piped { "piped" } else { "terminal" }
+        );
+    }
+}
+
 #[test]
 #[serial]
 fn search_flag_reaches_the_handler_as_a_filter() {
@@ -279,8 +405,8 @@ fn initialization_preserves_text_and_exposes_structured_facts() {
         text.stdout(),
         format!(
             "Initialized padz store at {}\n\nTip: Enable shell completions for padz:\n  \
-             eval \"$(padz completions bash)\"  # add to ~/.bashrc\n  \
-             eval \"$(padz completions zsh)\"   # add to ~/.zshrc\n",
+             eval \"$(padz completion bash)\"  # add to ~/.bashrc\n  \
+             eval \"$(padz completion zsh)\"   # add to ~/.zshrc\n",
             fx.project().join(".padz").display()
         )
     );
@@ -1184,7 +1310,7 @@ fn tag_registry_mutations_preserve_names_counts_and_human_wording() {
     rename.assert_success();
     assert_eq!(
         rename.stdout(),
-        "Renamed tag 'old' to 'new'\nUpdated 1 pad\n"
+        "Renamed tag 'old' to 'new'\nUpdated 1 pad\n  target\n"
     );
     drop(rename);
 
@@ -1534,6 +1660,27 @@ fn whitespace_only_pipe_aborts() {
     assert_eq!(pads(listed.stdout()), vec![]);
 }
 
+/// `--from-clipboard` takes the clipboard's text as a pipe's, and leaves
+/// stdin unread.
+#[test]
+#[serial]
+fn from_clipboard_creates_from_the_clipboard_text() {
+    let fx = Fixture::new();
+    let (app, cmd, clipboard) = fx.app_with_recording_clipboard(&["create"]);
+    clipboard.write("Copied\n\nFrom elsewhere\n").unwrap();
+
+    let result = TestHarness::new().no_color().piped_stdin("IGNORED").run(
+        &app,
+        cmd,
+        fx.argv(&["create", "--output", "json", "--from-clipboard"]),
+    );
+
+    assert_eq!(
+        pads(result.stdout()),
+        vec![("Copied".to_string(), "Copied\n\nFrom elsewhere".to_string())]
+    );
+}
+
 #[test]
 #[serial]
 fn piped_create_nests_under_inside() {
@@ -1878,6 +2025,44 @@ fn a_semantic_pin_notice_renders_compatible_human_wording() {
     result.assert_stdout_contains("Pad p1 is already pinned");
 }
 
+/// Making room past `max_pins` is `pin --evict`'s; the global `--force` only
+/// overrides `owner_only_edit` and must not unpin anything.
+#[test]
+#[serial]
+fn only_evict_pins_past_max_pins() {
+    let fx = Fixture::new();
+    std::fs::write(
+        fx.project().join(".padz").join("padz.toml"),
+        "max_pins = 1\n",
+    )
+    .expect("failed to write padz.toml");
+    let state = fx.app_state();
+    fx.seed_pad(&state, "old pin", "");
+    fx.seed_pad(&state, "new pin", "");
+    state
+        .with_api(|api| api.pin_pads(state.scope, &["2"]))
+        .unwrap();
+    drop(state);
+
+    let (app, cmd) = fx.read_app();
+    let forced = TestHarness::new().no_color().text_output().run(
+        &app,
+        cmd,
+        fx.argv(&["--force", "pin", "1"]),
+    );
+    forced.assert_error();
+    drop(forced);
+
+    let (app, cmd) = fx.read_app();
+    let evicted = TestHarness::new().no_color().text_output().run(
+        &app,
+        cmd,
+        fx.argv(&["pin", "1", "--evict"]),
+    );
+    evicted.assert_success();
+    evicted.assert_stdout_contains("Unpinned 'old pin' to stay within max_pins");
+}
+
 #[test]
 #[serial]
 fn semantic_pin_notice_is_machine_readable() {
@@ -2072,6 +2257,99 @@ fn a_pinned_pad_is_indexed_p1_in_the_pinned_block_and_numbered_below() {
     );
 }
 
+/// The `[list]` config table: a pinned pad shown in the pinned block only, long
+/// titles cut at `max_title`, and a blank line between pads when comfortable.
+#[test]
+#[serial]
+fn the_list_config_table_shapes_the_listing() {
+    let fx = Fixture::new();
+    std::fs::write(
+        fx.project().join(".padz").join("padz.toml"),
+        "[list]\nmax_title = 12\ndensity = \"comfortable\"\nrepeat_pinned = false\n",
+    )
+    .unwrap();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "a rather long pinned title", "");
+    fx.seed_pad(&state, "short", "");
+    drop(state);
+
+    let (app, cmd) = fx.read_app();
+    TestHarness::new()
+        .no_color()
+        .terminal_width(80)
+        .text_output()
+        .run(&app, cmd, fx.argv(&["pin", "2"]))
+        .assert_success();
+
+    let (app, cmd) = fx.read_app();
+    let out = TestHarness::new()
+        .no_color()
+        .terminal_width(80)
+        .text_output()
+        .run(&app, cmd, fx.argv(&["list"]));
+
+    out.assert_success();
+    let lines: Vec<&str> = out.stdout().lines().collect();
+    assert_eq!(lines.len(), 3, "two pads, one blank line: {}", out.stdout());
+    assert!(lines[0].contains("p1.") && lines[0].contains("a rather lo…"));
+    assert!(lines[1].is_empty());
+    assert!(lines[2].contains(" 1.") && lines[2].contains("short"));
+}
+
+/// `--group-by` puts a header with the group's pad count over its pads, and
+/// structured output carries the groups instead of a flat `pads`.
+#[test]
+#[serial]
+fn group_by_day_heads_the_listing_with_a_dated_count() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "first", "");
+    fx.seed_pad(&state, "second", "");
+    drop(state);
+    let today = chrono::Utc::now().format("%Y-%m-%d").to_string();
+
+    let (app, cmd) = fx.read_app();
+    let out = TestHarness::new()
+        .no_color()
+        .terminal_width(80)
+        .text_output()
+        .run(&app, cmd, fx.argv(&["list", "--group-by", "day"]));
+    out.assert_success();
+    let lines: Vec<&str> = out.stdout().lines().collect();
+    assert_eq!(lines[0], format!("{} (2)", today), "{}", out.stdout());
+    assert!(lines[1].contains("second") && lines[2].contains("first"));
+
+    let (app, cmd) = fx.read_app();
+    let json = TestHarness::new().run(
+        &app,
+        cmd,
+        fx.argv(&["list", "--group-by", "day", "--output", "json"]),
+    );
+    let value: serde_json::Value = serde_json::from_str(json.stdout()).unwrap();
+    assert_eq!(value["groups"][0]["key"], today.as_str());
+    assert_eq!(value["groups"][0]["count"], 2);
+    assert_eq!(value["pads"].as_array().map(Vec::len), Some(0));
+}
+
+#[test]
+#[serial]
+fn list_count_prints_the_number_alone() {
+    let fx = Fixture::new();
+    let state = fx.app_state();
+    fx.seed_pad(&state, "first", "");
+    fx.seed_pad(&state, "second", "");
+    drop(state);
+
+    let (app, cmd) = fx.read_app();
+    let out = TestHarness::new()
+        .no_color()
+        .terminal_width(80)
+        .text_output()
+        .run(&app, cmd, fx.argv(&["list", "--count"]));
+    out.assert_success();
+    assert_eq!(out.stdout(), "2\n");
+}
+
 /// Status glyphs are a template lookup keyed by the serialized TodoStatus, and
 /// they appear only when the listing asked for them.
 #[test]
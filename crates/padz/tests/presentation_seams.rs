@@ -71,3 +71,25 @@ fn pin_template_owns_the_compatible_human_sentence() {
     result.assert_stdout_contains("Pinned 1 pad...");
     result.assert_stdout_contains("p1. target");
 }
+
+/// Templates compose index selectors (`p1.`, `ar2`, `d1.3`) from a
+/// DisplayIndex's `type` and `value`, through `L.INDEX_PREFIX`. That map must
+/// agree with the core registry the parser reads, or listings would print
+/// indexes the CLI then refuses.
+#[test]
+fn layout_index_prefixes_match_the_core_registry() {
+    let layout = include_str!("../src/cli/templates/_layout.jinja");
+    let line = layout
+        .lines()
+        .find(|l| l.contains("set INDEX_PREFIX"))
+        .expect("_layout.jinja defines INDEX_PREFIX");
+    for ns in padzapp::index::INDEX_NAMESPACES {
+        let entry = format!("\"{}\": \"{}\"", ns.kind, ns.prefix);
+        assert!(line.contains(&entry), "INDEX_PREFIX is missing {entry}");
+    }
+    assert_eq!(
+        line.matches("\": \"").count(),
+        padzapp::index::INDEX_NAMESPACES.len(),
+        "INDEX_PREFIX has entries the core does not know"
+    );
+}
@@ -82,6 +82,9 @@ fn help_render_and_exit_contracts_stay_at_the_process_boundary() {
     assert!(root_help.contains("PER PAD(S)"));
     assert!(root_help.contains("LEARN MORE"));
     assert!(root_help.contains("scopes:"));
+    // Above the commands: the store they would act on, here the global one.
+    assert!(root_help.starts_with("Scope: global,"));
+    assert!(root_help.contains("No project store yet"));
 
     let topic = fixture.command().args(["help", "scopes"]).output().unwrap();
     assert!(topic.status.success());
@@ -114,6 +117,52 @@ fn help_render_and_exit_contracts_stay_at_the_process_boundary() {
         .failure();
 }
 
+/// Every file and directory under `dir`, with its size and modification time.
+fn snapshot(dir: &Path) -> Vec<(PathBuf, u64, std::time::SystemTime)> {
+    let mut entries = Vec::new();
+    for entry in fs::read_dir(dir).unwrap() {
+        let path = entry.unwrap().path();
+        let meta = fs::metadata(&path).unwrap();
+        entries.push((path.clone(), meta.len(), meta.modified().unwrap()));
+        if meta.is_dir() {
+            entries.extend(snapshot(&path));
+        }
+    }
+    entries.sort();
+    entries
+}
+
+/// Process-only boundary: startup is paid per command, so help loads no
+/// config, reads no more of the store than its indexes (to name the scope),
+/// and writes nothing. With the home, data, config and temp directories all
+/// inside the fixture, any write would show there.
+#[test]
+fn help_performs_no_filesystem_writes() {
+    let fixture = Fixture::new();
+    let before = snapshot(&fixture.root);
+
+    for args in [
+        &["--help"][..],
+        &["help"],
+        &["list", "--help"],
+        &["help", "create"],
+        &["create", "--examples"],
+    ] {
+        fixture
+            .command()
+            .args(args)
+            .env("HOME", &fixture.root)
+            .env("XDG_CONFIG_HOME", &fixture.root)
+            .env("XDG_DATA_HOME", &fixture.root)
+            .env("XDG_CACHE_HOME", &fixture.root)
+            .env("TMPDIR", &fixture.root)
+            .assert()
+            .success();
+    }
+
+    assert_eq!(snapshot(&fixture.root), before);
+}
+
 /// Process-only boundary: `config set` is intercepted by clapfig before
 /// dispatch. A later process must reload the value that the first process
 /// persisted; direct format semantics are covered below the CLI.
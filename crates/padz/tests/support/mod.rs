@@ -17,11 +17,14 @@
 #![allow(dead_code)] // Each test binary uses its own subset of these helpers.
 
 use clap::Parser;
-use padz::cli::clipboard::ClipboardWriter;
+use padz::cli::capabilities::Capabilities;
+use padz::cli::clipboard::{ClipboardReader, ClipboardWriter};
 use padz::cli::commands::{build_app_state, build_dispatch_app};
 use padz::cli::handlers::AppState;
 use padz::cli::input::RequestContent;
 use padz::cli::setup::{build_command, Cli};
+use padz::cli::signing::Signer;
+use padz::cli::views::{SignatureCheck, SignatureStatus};
 use padzapp::init::{create_bucket_layout, PadzEnv};
 use standout::cli::App;
 use standout::input::{InputSourceKind, Inputs, ResolvedInput};
@@ -45,7 +48,8 @@ pub struct Fixture {
 ///
 /// Copy tests inspect the vector to prove both write count and byte ordering. It
 /// implements Padz's application-owned write seam because Standout's harness
-/// clipboard override is intentionally an input reader, not an output sink.
+/// clipboard override is intentionally an input reader, not an output sink. Read
+/// back, it holds its last write, as a real clipboard would.
 #[derive(Clone, Default)]
 pub struct RecordingClipboard {
     writes: Rc<RefCell<Vec<String>>>,
@@ -64,6 +68,30 @@ impl ClipboardWriter for RecordingClipboard {
     }
 }
 
+impl ClipboardReader for RecordingClipboard {
+    fn read(&self) -> padzapp::error::Result<String> {
+        Ok(self.writes.borrow().last().cloned().unwrap_or_default())
+    }
+}
+
+/// A signer with a fixed verdict, standing in for gpg and a keyring.
+pub struct FixedSigner(pub SignatureStatus);
+
+impl Signer for FixedSigner {
+    fn detach_sign(&self, bytes: &[u8]) -> padzapp::error::Result<String> {
+        Ok(format!("signature over {} bytes", bytes.len()))
+    }
+
+    fn verify(&self, file: &Path, signature: &Path) -> padzapp::error::Result<SignatureCheck> {
+        Ok(SignatureCheck {
+            file: file.to_path_buf(),
+            signature: signature.to_path_buf(),
+            status: self.0,
+            signer: (self.0 == SignatureStatus::Good).then(|| "Test Signer".to_string()),
+        })
+    }
+}
+
 impl Fixture {
     /// Creates an initialized project store (`<temp>/project/.padz`) and an
     /// isolated global store (`<temp>/global`).
@@ -182,7 +210,8 @@ impl Fixture {
         let clipboard = RecordingClipboard::default();
         let state = self
             .app_state_for(args)
-            .with_clipboard_writer(Rc::new(clipboard.clone()));
+            .with_clipboard_writer(Rc::new(clipboard.clone()))
+            .with_clipboard_reader(Rc::new(clipboard.clone()));
         (state, clipboard)
     }
 
@@ -192,9 +221,16 @@ impl Fixture {
     ///
     /// `args` is the invocation whose *app state* is being built, not the argv the
     /// harness will run; pass the latter to `TestHarness::run` via [`argv`](Self::argv).
+    ///
+    /// The app claims a terminal ([`Capabilities::interactive`]): whether stdin
+    /// really is one is the harness's call (`interactive_stdin`/`piped_stdin`),
+    /// and the editor arm must stay reachable when it says so.
     pub fn app(&self, args: &[&str]) -> (App, clap::Command) {
         (
-            build_dispatch_app(self.app_state_for(args)),
+            build_dispatch_app(
+                self.app_state_for(args)
+                    .with_capabilities(Capabilities::interactive()),
+            ),
             build_command(),
         )
     }
@@ -205,6 +241,7 @@ impl Fixture {
         args: &[&str],
     ) -> (App, clap::Command, RecordingClipboard) {
         let (state, clipboard) = self.app_state_with_recording_clipboard_for(args);
+        let state = state.with_capabilities(Capabilities::interactive());
         (build_dispatch_app(state), build_command(), clipboard)
     }
 
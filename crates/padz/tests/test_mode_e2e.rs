@@ -0,0 +1,130 @@
+//! Black-box runs of the real binary under `--test-mode`.
+//!
+//! Boundary: `main.rs` and the composition root's process reads. Everything the
+//! in-process groups prove is handed an explicit environment and cwd; only a
+//! child process can show that `--test-mode` replaces the ones padz would have
+//! read (cwd, `$PADZ_GLOBAL_DATA`, the home directory) with its sandbox, so a
+//! scripted run of the shipped binary cannot touch the user's pads.
+//!
+//! [`Sandbox`] is the helper for such runs: every command it builds passes
+//! `--test-mode` and points `$TMPDIR` at a fresh directory, which is where the
+//! binary puts its sandbox.
+
+use assert_cmd::Command;
+use std::fs;
+use std::path::PathBuf;
+use tempfile::TempDir;
+
+/// A fresh `$TMPDIR` for `padz --test-mode` runs, shared by every command
+/// built from it and removed on drop.
+struct Sandbox {
+    tmp: TempDir,
+}
+
+impl Sandbox {
+    fn new() -> Self {
+        Self {
+            tmp: TempDir::new().expect("temporary sandbox"),
+        }
+    }
+
+    /// `padz --test-mode <args>`, run from a directory outside the sandbox so
+    /// a cwd leak would show.
+    fn padz(&self, args: &[&str]) -> Command {
+        let mut command = Command::new(assert_cmd::cargo::cargo_bin!("padz"));
+        command
+            .arg("--test-mode")
+            .args(args)
+            .env("TMPDIR", self.tmp.path())
+            .env("EDITOR", "/usr/bin/false")
+            .current_dir(self.tmp.path());
+        command
+    }
+
+    /// The directory the binary uses as its sandbox.
+    fn root(&self) -> PathBuf {
+        self.tmp.path().join("padz-test-mode")
+    }
+
+    fn stdout(&self, args: &[&str]) -> String {
+        let output = self.padz(args).output().unwrap();
+        assert!(
+            output.status.success(),
+            "padz {args:?} failed: {}",
+            String::from_utf8_lossy(&output.stderr)
+        );
+        String::from_utf8(output.stdout).expect("process output is UTF-8")
+    }
+}
+
+#[test]
+fn test_mode_keeps_pads_in_the_sandbox_across_invocations() {
+    let sandbox = Sandbox::new();
+
+    sandbox
+        .padz(&["create", "--no-editor", "sandboxed pad"])
+        .assert()
+        .success();
+
+    assert!(sandbox.stdout(&["list"]).contains("sandboxed pad"));
+    assert!(
+        sandbox.root().join("project").join(".padz").is_dir(),
+        "project pads belong in the sandbox's project"
+    );
+    assert!(
+        !sandbox.tmp.path().join(".padz").exists(),
+        "the real cwd must not gain a store"
+    );
+}
+
+#[test]
+fn test_mode_ignores_the_global_data_dir_of_the_environment() {
+    let sandbox = Sandbox::new();
+    let host_global = TempDir::new().unwrap();
+
+    sandbox
+        .padz(&["-g", "create", "--no-editor", "global in the sandbox"])
+        .env("PADZ_GLOBAL_DATA", host_global.path())
+        .assert()
+        .success();
+
+    assert_eq!(fs::read_dir(host_global.path()).unwrap().count(), 0);
+    assert!(sandbox
+        .stdout(&["-g", "list"])
+        .contains("global in the sandbox"));
+}
+
+#[test]
+fn test_mode_rejects_an_explicit_data_dir() {
+    let sandbox = Sandbox::new();
+    let output = sandbox.padz(&["--data", "/tmp", "list"]).output().unwrap();
+    assert!(!output.status.success());
+}
+
+#[test]
+fn tour_runs_its_steps_in_a_sandbox_it_removes() {
+    let sandbox = Sandbox::new();
+
+    let stdout = sandbox.stdout(&["tour", "--no-pause"]);
+    assert!(stdout.contains("$ padz restore d1"));
+    assert!(stdout.contains("Call the plumber"));
+
+    let leftovers: Vec<_> = fs::read_dir(sandbox.tmp.path())
+        .unwrap()
+        .map(|entry| entry.unwrap().file_name())
+        .filter(|name| name.to_string_lossy().starts_with("padz-tour-"))
+        .collect();
+    assert!(leftovers.is_empty(), "left behind: {leftovers:?}");
+}
+
+#[test]
+fn prompt_segment_counts_the_sandbox_pads() {
+    let sandbox = Sandbox::new();
+    assert_eq!(sandbox.stdout(&["prompt-segment"]), "");
+
+    sandbox.stdout(&["create", "--no-editor", "One"]);
+    sandbox.stdout(&["create", "--no-editor", "Two"]);
+    sandbox.stdout(&["pin", "1"]);
+
+    assert_eq!(sandbox.stdout(&["prompt-segment"]), "✎2 ⚲1\n");
+}
@@ -2,9 +2,9 @@
 
 use crate::commands;
 use crate::error::{PadzError, Result};
-use crate::index::parse_index_or_range;
-use crate::model::Scope;
-use crate::store::DataStore;
+use crate::index::{parse_index_or_range, PadSelector};
+use crate::model::{CodeAnchor, Scope, TodoStatus};
+use crate::store::{self, Bucket, DataStore};
 
 use super::selectors::{
     parse_selectors, parse_selectors_for_archived, parse_selectors_for_deleted,
@@ -25,7 +25,46 @@ impl<S: DataStore> PadzApi<S> {
         } else {
             None
         };
-        commands::create::run(&mut self.store, scope, title, content, parent_selector)
+        let content = self.seal_secrets(content)?;
+        commands::create::run_with_context(
+            &mut self.store,
+            scope,
+            title,
+            content,
+            parent_selector,
+            self.creation_context.clone(),
+            self.ownership.identity.clone(),
+        )
+    }
+
+    /// The active pad already titled `title`, which `create` checks for
+    /// before it makes another (see [`commands::create::titled`]).
+    pub fn pad_titled(&self, scope: Scope, title: &str) -> Result<Option<uuid::Uuid>> {
+        commands::create::titled(&self.store, scope, title)
+    }
+
+    /// The note for `date` in the journal pad titled `journal`, both created
+    /// if they do not exist yet (see [`commands::journal`]).
+    pub fn journal_day(
+        &mut self,
+        scope: Scope,
+        journal: &str,
+        date: chrono::NaiveDate,
+    ) -> Result<uuid::Uuid> {
+        let context = self.creation_context.clone();
+        let owner = self.ownership.identity.clone();
+        store::transaction(&mut self.store, |store| {
+            commands::journal::open_day(store, scope, journal, date, context, owner)
+        })
+    }
+
+    /// The daily notes in the journal pad titled `journal`, newest first.
+    pub fn journal(
+        &self,
+        scope: Scope,
+        journal: &str,
+    ) -> Result<commands::journal::JournalOutcome> {
+        commands::journal::list(&self.store, scope, journal)
     }
 
     pub fn get_pads<I: AsRef<str>>(
@@ -39,7 +78,26 @@ impl<S: DataStore> PadzApi<S> {
         } else {
             parse_selectors(ids)?
         };
-        commands::get::run(&self.store, scope, filter, &selectors)
+        let mut result = commands::get::run(&self.store, scope, filter, &selectors)?;
+        commands::refs::follow(&self.store, scope, &mut result.listed_pads)?;
+        Ok(result)
+    }
+
+    /// Hands the pads of a listing to `emit` one at a time, each body read
+    /// only when its turn comes (see [`commands::get::stream`]).
+    pub fn stream_pads<I: AsRef<str>>(
+        &self,
+        scope: Scope,
+        filter: PadFilter,
+        ids: &[I],
+        emit: impl FnMut(&commands::get::StreamedPad) -> Result<()>,
+    ) -> Result<usize> {
+        let selectors = if ids.is_empty() {
+            vec![]
+        } else {
+            parse_selectors(ids)?
+        };
+        commands::get::stream(&self.store, scope, filter, &selectors, &self.cancel, emit)
     }
 
     pub fn view_pads<I: AsRef<str>>(
@@ -49,7 +107,31 @@ impl<S: DataStore> PadzApi<S> {
         nesting: commands::NestingMode,
     ) -> Result<commands::CmdResult> {
         let selectors = parse_selectors(indexes)?;
-        commands::view::run(&self.store, scope, &selectors, nesting)
+        let mut result = commands::view::run(&self.store, scope, &selectors, nesting)?;
+        commands::refs::follow(&self.store, scope, &mut result.listed_pads)?;
+        Ok(result)
+    }
+
+    /// Adds references in the project to the global pads `indexes` select
+    /// (see [`commands::refs`]).
+    pub fn add_refs<I: AsRef<str>>(
+        &mut self,
+        scope: Scope,
+        indexes: &[I],
+    ) -> Result<commands::CmdResult> {
+        let selectors = parse_selectors(indexes)?;
+        let context = self.creation_context.clone();
+        let owner = self.ownership.identity.clone();
+        store::transaction(&mut self.store, |store| {
+            commands::refs::add(store, scope, &selectors, context, owner)
+        })
+    }
+
+    /// The scope and id that reads and writes of pad `id` go to: a
+    /// reference's global source, or the pad itself.
+    pub fn ref_target(&self, scope: Scope, id: uuid::Uuid) -> Result<(Scope, uuid::Uuid)> {
+        let pad = self.store.get_pad(&id, scope, Bucket::Active)?;
+        Ok(commands::refs::target(&pad.metadata, scope))
     }
 
     pub fn delete_pads<I: AsRef<str>>(
@@ -58,14 +140,29 @@ impl<S: DataStore> PadzApi<S> {
         indexes: &[I],
     ) -> Result<commands::CmdResult> {
         let selectors = parse_selectors(indexes)?;
-        commands::delete::run(&mut self.store, scope, &selectors)
+        commands::ownership::check(&self.store, scope, &selectors, true, &self.ownership)?;
+        store::transaction(&mut self.store, |store| {
+            commands::delete::run(store, scope, &selectors)
+        })
     }
 
     /// Soft-deletes all active pads marked as Done (completed).
     ///
     /// Returns a semantic `NoCompletedPads` notice when there are no matches.
     pub fn delete_completed_pads(&mut self, scope: Scope) -> Result<commands::CmdResult> {
-        commands::delete::run_completed(&mut self.store, scope)
+        if self.ownership.enforced() {
+            let done: Vec<PadSelector> = self
+                .store
+                .list_metadata(scope, Bucket::Active)?
+                .into_iter()
+                .filter(|metadata| metadata.status == TodoStatus::Done)
+                .map(|metadata| PadSelector::Uuid(metadata.id))
+                .collect();
+            commands::ownership::check(&self.store, scope, &done, true, &self.ownership)?;
+        }
+        store::transaction(&mut self.store, |store| {
+            commands::delete::run_completed(store, scope)
+        })
     }
 
     /// Applies typed updates and returns affected pads plus semantic update facts.
@@ -74,7 +171,29 @@ impl<S: DataStore> PadzApi<S> {
         scope: Scope,
         updates: &[commands::PadUpdate],
     ) -> Result<commands::CmdResult> {
-        commands::update::run(&mut self.store, scope, updates)
+        let updates = updates
+            .iter()
+            .cloned()
+            .map(|mut update| {
+                update.content = self.seal_secrets(update.content)?;
+                Ok(update)
+            })
+            .collect::<Result<Vec<_>>>()?;
+        let selectors: Vec<PadSelector> = updates
+            .iter()
+            .map(|update| {
+                PadSelector::Path(
+                    update
+                        .path
+                        .clone()
+                        .unwrap_or_else(|| vec![update.index.clone()]),
+                )
+            })
+            .collect();
+        commands::ownership::check(&self.store, scope, &selectors, false, &self.ownership)?;
+        store::transaction(&mut self.store, |store| {
+            commands::update::run(store, scope, &updates)
+        })
     }
 
     /// Updates pads with raw content (e.g., from piped stdin), returning affected
@@ -86,7 +205,36 @@ impl<S: DataStore> PadzApi<S> {
         raw_content: &str,
     ) -> Result<commands::CmdResult> {
         let selectors = parse_selectors(indexes)?;
-        commands::update::run_from_content(&mut self.store, scope, &selectors, raw_content)
+        commands::ownership::check(&self.store, scope, &selectors, false, &self.ownership)?;
+        let raw_content = self.seal_secrets(raw_content.to_string())?;
+        store::transaction(&mut self.store, |store| {
+            commands::update::run_from_content(store, scope, &selectors, &raw_content)
+        })
+    }
+
+    /// Adds `text` to the end or the start of the selected pads without
+    /// replacing what is there (see [`commands::update::run_amend`]).
+    pub fn amend_pads<I: AsRef<str>>(
+        &mut self,
+        scope: Scope,
+        indexes: &[I],
+        text: &str,
+        at: commands::update::Amend,
+    ) -> Result<commands::CmdResult> {
+        let selectors = parse_selectors(indexes)?;
+        commands::ownership::check(&self.store, scope, &selectors, false, &self.ownership)?;
+        let text = self.seal_secrets(text.to_string())?;
+        store::transaction(&mut self.store, |store| {
+            commands::update::run_amend(store, scope, &selectors, &text, at)
+        })
+    }
+
+    /// Fails if the selected pads may not be edited by whoever runs this API
+    /// (see [`Self::set_ownership`]). The editor flow asks before it opens the
+    /// pad, since the edit itself is made outside the API.
+    pub fn check_can_edit<I: AsRef<str>>(&self, scope: Scope, indexes: &[I]) -> Result<()> {
+        let selectors = parse_selectors(indexes)?;
+        commands::ownership::check(&self.store, scope, &selectors, false, &self.ownership)
     }
 
     pub fn restore_pads<I: AsRef<str>>(
@@ -95,7 +243,9 @@ impl<S: DataStore> PadzApi<S> {
         indexes: &[I],
     ) -> Result<commands::CmdResult> {
         let selectors = parse_selectors_for_deleted(indexes)?;
-        commands::restore::run(&mut self.store, scope, &selectors)
+        store::transaction(&mut self.store, |store| {
+            commands::restore::run(store, scope, &selectors)
+        })
     }
 
     /// Permanently deletes pads.
@@ -111,14 +261,46 @@ impl<S: DataStore> PadzApi<S> {
         include_done: bool,
     ) -> Result<commands::purge::PurgeOutcome> {
         let selectors = parse_selectors(indexes)?;
-        commands::purge::run(
-            &mut self.store,
-            scope,
-            &selectors,
-            recursive,
-            confirmed,
-            include_done,
-        )
+        if self.ownership.enforced() {
+            // With no selectors purge takes the whole deleted bucket, and the
+            // done pads too when asked.
+            let targets: Vec<PadSelector> = if selectors.is_empty() {
+                let mut ids = self.store.list_metadata(scope, Bucket::Deleted)?;
+                if include_done {
+                    ids.extend(
+                        self.store
+                            .list_metadata(scope, Bucket::Active)?
+                            .into_iter()
+                            .filter(|metadata| metadata.status == TodoStatus::Done),
+                    );
+                }
+                ids.into_iter()
+                    .map(|metadata| PadSelector::Uuid(metadata.id))
+                    .collect()
+            } else {
+                selectors.clone()
+            };
+            commands::ownership::check_in(
+                &self.store,
+                scope,
+                &targets,
+                true,
+                commands::helpers::TitleBucket::Deleted,
+                &self.ownership,
+            )?;
+        }
+        store::transaction(&mut self.store, |store| {
+            commands::purge::run(store, scope, &selectors, recursive, confirmed, include_done)
+        })
+    }
+
+    /// Takes back the last change committed to `scope`; run again, redoes it
+    /// (see [`commands::undo`]).
+    pub fn undo(&mut self, scope: Scope) -> Result<commands::undo::UndoOutcome> {
+        if let Some(record) = self.store.last_change(scope)? {
+            commands::ownership::check_undo(&record, &self.ownership)?;
+        }
+        store::transaction(&mut self.store, |store| commands::undo::run(store, scope))
     }
 
     pub fn archive_pads<I: AsRef<str>>(
@@ -127,7 +309,9 @@ impl<S: DataStore> PadzApi<S> {
         indexes: &[I],
     ) -> Result<commands::CmdResult> {
         let selectors = parse_selectors(indexes)?;
-        commands::archive::run(&mut self.store, scope, &selectors)
+        store::transaction(&mut self.store, |store| {
+            commands::archive::run(store, scope, &selectors)
+        })
     }
 
     pub fn unarchive_pads<I: AsRef<str>>(
@@ -136,7 +320,48 @@ impl<S: DataStore> PadzApi<S> {
         indexes: &[I],
     ) -> Result<commands::CmdResult> {
         let selectors = parse_selectors_for_archived(indexes)?;
-        commands::unarchive::run(&mut self.store, scope, &selectors)
+        store::transaction(&mut self.store, |store| {
+            commands::unarchive::run(store, scope, &selectors)
+        })
+    }
+
+    /// Gives one pad an alias, or clears it when `alias` is `None`.
+    pub fn name_pad<I: AsRef<str>>(
+        &mut self,
+        scope: Scope,
+        indexes: &[I],
+        alias: Option<&str>,
+    ) -> Result<commands::CmdResult> {
+        let selectors = parse_selectors(indexes)?;
+        store::transaction(&mut self.store, |store| {
+            commands::naming::run(store, scope, &selectors, alias)
+        })
+    }
+
+    /// Records code locations on the selected pads.
+    pub fn add_anchors<I: AsRef<str>>(
+        &mut self,
+        scope: Scope,
+        indexes: &[I],
+        anchors: &[CodeAnchor],
+    ) -> Result<commands::CmdResult> {
+        let selectors = parse_selectors(indexes)?;
+        store::transaction(&mut self.store, |store| {
+            commands::anchors::add(store, scope, &selectors, anchors)
+        })
+    }
+
+    /// Forgets code locations previously recorded on the selected pads.
+    pub fn remove_anchors<I: AsRef<str>>(
+        &mut self,
+        scope: Scope,
+        indexes: &[I],
+        anchors: &[CodeAnchor],
+    ) -> Result<commands::CmdResult> {
+        let selectors = parse_selectors(indexes)?;
+        store::transaction(&mut self.store, |store| {
+            commands::anchors::remove(store, scope, &selectors, anchors)
+        })
     }
 }
 
@@ -160,6 +385,94 @@ mod tests {
         assert_eq!(result.affected_pads[0].pad.metadata.title, "Test Title");
     }
 
+    #[test]
+    fn test_api_create_pad_stamps_the_creation_context() {
+        let mut api = make_api();
+        let context = crate::model::CreationContext {
+            cwd: "/work/repo".into(),
+            commit: "abc123".into(),
+            branch: None,
+            dirty: false,
+        };
+        api.set_creation_context(Some(context.clone()));
+
+        let result = api
+            .create_pad(Scope::Project, "Noted".into(), "".into(), None)
+            .unwrap();
+
+        assert_eq!(result.affected_pads[0].pad.metadata.context, Some(context));
+    }
+
+    #[test]
+    fn test_api_owner_only_edit_guards_others_pads() {
+        use crate::commands::ownership::Ownership;
+
+        let mut api = make_api();
+        let mut ownership = Ownership {
+            identity: Some("ana".into()),
+            owner_only_edit: true,
+            force: false,
+        };
+        api.set_ownership(ownership.clone());
+        let result = api
+            .create_pad(Scope::Project, "Ana's".into(), "".into(), None)
+            .unwrap();
+        assert_eq!(
+            result.affected_pads[0].pad.metadata.owner.as_deref(),
+            Some("ana")
+        );
+
+        ownership.identity = Some("bo".into());
+        api.set_ownership(ownership.clone());
+        assert!(api.check_can_edit(Scope::Project, &["1"]).is_err());
+        assert!(api
+            .update_pads_from_content(Scope::Project, &["1"], "Bo's")
+            .is_err());
+        assert!(api.delete_pads(Scope::Project, &["1"]).is_err());
+
+        ownership.force = true;
+        api.set_ownership(ownership);
+        api.delete_pads(Scope::Project, &["1"]).unwrap();
+    }
+
+    #[test]
+    fn test_api_owner_only_edit_guards_purge_and_undo() {
+        use crate::commands::ownership::Ownership;
+
+        let mut api = make_api();
+        let ana = Ownership {
+            identity: Some("ana".into()),
+            owner_only_edit: true,
+            force: false,
+        };
+        let bo = Ownership {
+            identity: Some("bo".into()),
+            ..ana.clone()
+        };
+        api.set_ownership(ana.clone());
+        api.create_pad(Scope::Project, "Ana's".into(), "".into(), None)
+            .unwrap();
+
+        // Undoing ana's create would remove her pad.
+        api.set_ownership(bo.clone());
+        assert!(api.undo(Scope::Project).is_err());
+
+        api.set_ownership(ana);
+        api.delete_pads(Scope::Project, &["1"]).unwrap();
+        api.set_ownership(bo.clone());
+        assert!(api
+            .purge_pads(Scope::Project, &["d1"], false, true, false)
+            .is_err());
+        assert!(api
+            .purge_pads(Scope::Project, &[] as &[&str], false, true, false)
+            .is_err());
+
+        api.set_ownership(Ownership { force: true, ..bo });
+        api.purge_pads(Scope::Project, &["d1"], false, true, false)
+            .unwrap();
+        api.undo(Scope::Project).unwrap();
+    }
+
     #[test]
     fn test_api_create_pad_with_parent_string() {
         let mut api = make_api();
@@ -0,0 +1,188 @@
+//! Encryption at rest: switching a scope's mode, and the editor's working
+//! copy of an encrypted or compressed pad (`FileStore`-specific).
+
+use crate::commands;
+use crate::error::Result;
+use crate::model::Scope;
+use crate::store::backend::StorageBackend;
+use crate::store::fs::FileStore;
+use crate::store::{compression, encryption};
+use crate::store::{Bucket, DataStore};
+use std::fs;
+use std::io::Write;
+use std::path::{Path, PathBuf};
+
+use super::PadzApi;
+
+impl PadzApi<FileStore> {
+    /// Turns encryption at rest on or off for `scope` and rewrites its bodies
+    /// in the new form (see [`commands::encrypt`]); a dry run only counts them.
+    pub fn set_encryption(
+        &mut self,
+        scope: Scope,
+        enabled: bool,
+    ) -> Result<commands::encrypt::EncryptOutcome> {
+        let dir = self.paths.scope_dir(scope)?;
+        if enabled {
+            // Fail before the store is marked, not on its first write.
+            self.secret_key()?;
+        }
+        commands::encrypt::run(&mut self.store, scope, &dir, enabled, self.dry_run)
+    }
+
+    /// The file the editor should open for active pad `id`: the pad's own
+    /// file, or, when its body is encrypted or compressed, a plain working
+    /// copy that only the user can read, in a fresh directory of its own under
+    /// the temp directory. Hand it back to [`Self::finish_editor_file`] once
+    /// the editor closes, whether or not it succeeded.
+    pub fn editor_file(&self, scope: Scope, id: uuid::Uuid) -> Result<PathBuf> {
+        let path = self.store.get_pad_path(&id, scope, Bucket::Active)?;
+        if !encryption::is_encrypted(&path) && !compression::is_compressed(&path) {
+            return Ok(path);
+        }
+        let content = self.store.get_pad(&id, scope, Bucket::Active)?.content;
+        // The shared temp directory is anyone's to plant a file or symlink
+        // in, so the copy goes in a directory made for it, which only the
+        // user can enter, and is created, never opened.
+        let dir = tempfile::Builder::new().prefix("padz-").tempdir()?.keep();
+        let copy = dir.join(path.with_extension("").file_name().unwrap_or_default());
+        if let Err(e) = write_private(&copy, &content) {
+            let _ = fs::remove_dir_all(&dir);
+            return Err(e);
+        }
+        Ok(copy)
+    }
+
+    /// Writes what the editor left in `file`, a working copy from
+    /// [`Self::editor_file`], back to pad `id` in the form the store keeps it
+    /// in, and removes the copy and its directory. Nothing to do when the
+    /// editor worked on the pad's own file.
+    pub fn finish_editor_file(&mut self, scope: Scope, id: uuid::Uuid, file: &Path) -> Result<()> {
+        let own = self.store.get_pad_path(&id, scope, Bucket::Active);
+        if own.as_deref().is_ok_and(|own| own == file) {
+            return Ok(());
+        }
+        let edited = fs::read_to_string(file);
+        fs::remove_file(file)?;
+        if let Some(dir) = file.parent() {
+            fs::remove_dir(dir)?;
+        }
+        let edited = edited?;
+        own?;
+        // Like a file the editor left untouched, an unchanged copy is not an
+        // edit: the pad keeps its time.
+        if edited != self.store.get_pad(&id, scope, Bucket::Active)?.content {
+            self.store
+                .active
+                .backend
+                .write_content(&id, scope, &edited)?;
+        }
+        Ok(())
+    }
+}
+
+/// Writes `content` to a file at `path` that only the owner can read,
+/// failing if anything is there already.
+fn write_private(path: &Path, content: &str) -> Result<()> {
+    let mut options = fs::OpenOptions::new();
+    options.write(true).create_new(true);
+    #[cfg(unix)]
+    {
+        use std::os::unix::fs::OpenOptionsExt;
+        options.mode(0o600);
+    }
+    options.open(path)?.write_all(content.as_bytes())?;
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::encrypt::EncryptOutcome;
+    use crate::secrets;
+    use crate::test_utils::TestEnv;
+
+    fn api_over(env: &TestEnv) -> PadzApi<FileStore> {
+        let paths = commands::PadzPaths {
+            project: Some(env.root.clone()),
+            global: env.root.clone(),
+            home: None,
+        };
+        PadzApi::new(
+            FileStore::new_fs(Some(env.root.clone()), env.root.clone())
+                .with_encryption(secrets::key_path(&env.root)),
+            paths,
+        )
+    }
+
+    #[test]
+    fn an_encrypted_pad_is_edited_through_a_working_copy() {
+        let env = TestEnv::new();
+        let mut api = api_over(&env);
+        let created = api
+            .create_pad(Scope::Project, "Plans".into(), "Launch".into(), None)
+            .unwrap();
+        let id = created.affected_pads[0].pad.metadata.id;
+
+        let outcome = api.set_encryption(Scope::Project, true).unwrap();
+        assert_eq!(
+            outcome,
+            EncryptOutcome::Switched {
+                enabled: true,
+                rewritten: 1
+            }
+        );
+        let own = api.get_path_by_id(Scope::Project, id).unwrap();
+        let copy = api.editor_file(Scope::Project, id).unwrap();
+        assert_ne!(copy, own);
+        assert!(std::fs::read_to_string(&copy).unwrap().contains("Launch"));
+
+        std::fs::write(&copy, "Plans\n\nLaunch on friday").unwrap();
+        #[cfg(unix)]
+        {
+            use std::os::unix::fs::PermissionsExt;
+            let mode = |path: &Path| std::fs::metadata(path).unwrap().permissions().mode() & 0o777;
+            assert_eq!(mode(&copy), 0o600);
+            assert_eq!(mode(copy.parent().unwrap()), 0o700);
+        }
+        api.finish_editor_file(Scope::Project, id, &copy).unwrap();
+        assert!(!copy.exists());
+        assert!(!copy.parent().unwrap().exists());
+        let edited = api.finish_editor_edit(Scope::Project, id).unwrap();
+        assert!(edited.affected_pads[0].pad.content.contains("friday"));
+        assert!(!String::from_utf8_lossy(&std::fs::read(&own).unwrap()).contains("friday"));
+    }
+
+    #[test]
+    fn a_compressed_pad_is_edited_through_a_working_copy() {
+        let env = TestEnv::new();
+        let paths = commands::PadzPaths {
+            project: Some(env.root.clone()),
+            global: env.root.clone(),
+            home: None,
+        };
+        let mut api = PadzApi::new(
+            FileStore::new_fs(Some(env.root.clone()), env.root.clone()).with_compression(Some(100)),
+            paths,
+        );
+        let log = "error: flaky test\n".repeat(50);
+        let created = api
+            .create_pad(Scope::Project, "Build log".into(), log.clone(), None)
+            .unwrap();
+        let id = created.affected_pads[0].pad.metadata.id;
+        let own = api.get_path_by_id(Scope::Project, id).unwrap();
+        assert!(compression::is_compressed(&own));
+
+        let copy = api.editor_file(Scope::Project, id).unwrap();
+        assert_ne!(copy, own);
+        std::fs::write(&copy, format!("Build log\n\n{}fixed", log)).unwrap();
+        api.finish_editor_file(Scope::Project, id, &copy).unwrap();
+        assert!(!copy.exists());
+
+        let edited = api.finish_editor_edit(Scope::Project, id).unwrap();
+        assert!(edited.affected_pads[0].pad.content.ends_with("fixed"));
+        assert!(compression::is_compressed(
+            &api.get_path_by_id(Scope::Project, id).unwrap()
+        ));
+    }
+}
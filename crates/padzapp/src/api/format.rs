@@ -1,10 +1,12 @@
-//! `FileStore`-specific API methods — format overrides for pad creation.
+//! `FileStore`-specific API methods — format overrides for pad creation, and
+//! arming a dry run and listing the files it held back.
 
 use crate::commands;
 use crate::config::normalize_format;
 use crate::error::{PadzError, Result};
 use crate::index::parse_index_or_range;
 use crate::model::Scope;
+use crate::secrets::{self, SecretKey};
 use crate::store::fs::FileStore;
 
 use super::PadzApi;
@@ -25,11 +27,114 @@ impl PadzApi<FileStore> {
         } else {
             None
         };
+        let content = self.seal_secrets(content)?;
         let prev_format = self.store.format_ext().to_string();
         let normalized = normalize_format(format);
         self.store.set_format(&normalized);
-        let result = commands::create::run(&mut self.store, scope, title, content, parent_selector);
+        let result = commands::create::run_with_context(
+            &mut self.store,
+            scope,
+            title,
+            content,
+            parent_selector,
+            self.creation_context.clone(),
+            self.ownership.identity.clone(),
+        );
         self.store.set_format(&prev_format);
         result
     }
+
+    /// Turn the rest of this API's life into a dry run: every command resolves,
+    /// validates and reports as usual, but no store write reaches disk.
+    ///
+    /// Arm before the first command; writes already made are not undone.
+    /// A dry run creates no secret key either: with none on disk yet, secret
+    /// fences are sealed with a throwaway key.
+    pub fn arm_dry_run(&mut self) {
+        self.store.arm_dry_run();
+        self.dry_run = true;
+        if self.secret_key.is_none() && !secrets::key_path(&self.paths.global).exists() {
+            self.secret_key = Some(SecretKey::generate());
+        }
+    }
+
+    /// The store files the dry run has held writes for so far: what it would
+    /// have written or removed. Empty when no dry run is armed.
+    pub fn dry_run_files(&self) -> Result<Vec<std::path::PathBuf>> {
+        if !self.dry_run {
+            return Ok(Vec::new());
+        }
+        self.store.held_files()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::api::PadFilter;
+    use crate::test_utils::TestEnv;
+
+    fn api_over(env: &TestEnv) -> PadzApi<FileStore> {
+        let paths = commands::PadzPaths {
+            project: Some(env.root.clone()),
+            global: env.root.clone(),
+            home: None,
+        };
+        PadzApi::new(
+            FileStore::new_fs(Some(env.root.clone()), env.root.clone()),
+            paths,
+        )
+    }
+
+    fn titles(api: &PadzApi<FileStore>) -> Vec<String> {
+        let no_ids: &[&str] = &[];
+        let mut titles: Vec<String> = api
+            .get_pads(Scope::Project, PadFilter::default(), no_ids)
+            .unwrap()
+            .listed_pads
+            .into_iter()
+            .map(|dp| dp.pad.metadata.title)
+            .collect();
+        titles.sort();
+        titles
+    }
+
+    #[test]
+    fn dry_run_reports_changes_without_writing_them() {
+        let env = TestEnv::new();
+        api_over(&env)
+            .create_pad(Scope::Project, "Kept".into(), "".into(), None)
+            .unwrap();
+
+        let mut dry = api_over(&env);
+        dry.arm_dry_run();
+        let created = dry
+            .create_pad(Scope::Project, "Imagined".into(), "".into(), None)
+            .unwrap();
+        dry.delete_pads(Scope::Project, &["2"]).unwrap();
+
+        assert!(dry.is_dry_run());
+        assert_eq!(created.affected_pads[0].pad.metadata.title, "Imagined");
+        assert_eq!(titles(&dry), vec!["Imagined".to_string()]);
+        assert_eq!(titles(&api_over(&env)), vec!["Kept".to_string()]);
+    }
+
+    #[test]
+    fn dry_run_seals_secrets_without_creating_a_key() {
+        let env = TestEnv::new();
+        let mut dry = api_over(&env);
+        dry.arm_dry_run();
+
+        let created = dry
+            .create_pad(
+                Scope::Project,
+                "Staging".into(),
+                "```secret\nhunter2\n```".into(),
+                None,
+            )
+            .unwrap();
+
+        assert!(!created.affected_pads[0].pad.content.contains("hunter2"));
+        assert!(!secrets::key_path(&env.root).exists());
+    }
 }
@@ -32,12 +32,18 @@
 //! unchanged from before the split. Methods are grouped by domain:
 //!
 //! - [`crud`] — create / get / view / delete / update / restore / purge / archive
-//! - [`status`] — pin / unpin / complete / reopen / move / propagate
-//! - [`transfer`] — export / import / clone / migrate
+//!   / name / code anchors / the daily journal / references to global pads
+//! - [`status`] — pin / unpin / pinboard / complete / reopen / move / propagate
+//! - [`transfer`] — export / compile / print / publish / import / clone / migrate
 //! - [`tags`] — tag registry CRUD + per-pad tagging
 //! - [`init`] — store initialization and linking
-//! - [`util`] — paths, uuids, refresh, remove, doctor
-//! - [`format`] — `FileStore`-specific create-with-format override
+//! - [`util`] — paths, uuids, refresh, remove, access tracking, timelines,
+//!   provenance (`which`), listing groups and `--where` queries, stats,
+//!   doctor, maintenance, the store tree, store integrity
+//! - [`secrets`] — sealing and revealing `secret` fences
+//! - [`format`] — `FileStore`-specific create-with-format override and dry runs
+//! - [`encryption`] — `FileStore`-specific encryption at rest and the editor's
+//!   working copy of an encrypted pad
 //! - [`selectors`] — internal input-normalization (private)
 //!
 //! ## Selectors: Multi-IDs and Ranges
@@ -45,12 +51,20 @@
 //! Users often need to act on batches of items (`padz delete 1-3`).
 //! See [`selectors`] for the parsing/normalization layer.
 
+use crate::cancel::Cancellation;
 use crate::commands;
+use crate::error::StoreWarning;
+use crate::model::CreationContext;
+use crate::progress::{NoProgress, Progress};
+use crate::secrets::SecretKey;
 use crate::store::DataStore;
+use std::rc::Rc;
 
 mod crud;
+mod encryption;
 mod format;
 mod init;
+mod secrets;
 mod selectors;
 mod status;
 mod tags;
@@ -67,26 +81,139 @@ pub(crate) mod test_support;
 pub struct PadzApi<S: DataStore> {
     store: S,
     paths: commands::PadzPaths,
+    /// Key for `secret` fences. `None` means "load (or create) it from the
+    /// global data directory the first time a secret is written or revealed".
+    secret_key: Option<SecretKey>,
+    /// Set by [`PadzApi::arm_dry_run`]: writes stay in memory, including writes
+    /// to any other store an operation opens (clone/migrate peers).
+    dry_run: bool,
+    /// Set by [`PadzApi::set_creation_context`]: stamped on every pad this API
+    /// creates.
+    creation_context: Option<CreationContext>,
+    /// Set by [`PadzApi::set_ownership`]: who creates pads, and whether the
+    /// pads others created are off limits.
+    ownership: commands::ownership::Ownership,
+    /// Set by [`PadzApi::set_pin_limit`]: how many pads `pin_pads` may leave
+    /// pinned.
+    pin_limit: commands::pinning::PinLimit,
+    /// Set by [`PadzApi::set_progress`]: told about every item a long
+    /// operation (import, export, clone, migrate) handles.
+    progress: Rc<dyn Progress>,
+    /// Set by [`PadzApi::set_cancellation`]: checked by the same operations
+    /// before every item.
+    cancel: Cancellation,
 }
 
 impl<S: DataStore> PadzApi<S> {
     pub fn new(store: S, paths: commands::PadzPaths) -> Self {
-        Self { store, paths }
+        Self {
+            store,
+            paths,
+            secret_key: None,
+            dry_run: false,
+            creation_context: None,
+            ownership: commands::ownership::Ownership::default(),
+            pin_limit: commands::pinning::PinLimit::default(),
+            progress: Rc::new(NoProgress),
+            cancel: Cancellation::new(),
+        }
+    }
+
+    /// Report the progress of long operations to `progress` (see
+    /// [`crate::progress`]). Without one, nothing is reported.
+    pub fn set_progress(&mut self, progress: Rc<dyn Progress>) {
+        self.progress = progress;
+    }
+
+    /// The non-fatal problems met since the last call (see [`StoreWarning`]),
+    /// oldest first. The client decides how to surface them.
+    pub fn take_warnings(&self) -> Vec<StoreWarning> {
+        self.store.take_warnings()
+    }
+
+    /// List what can be read of a damaged store, with a warning for each
+    /// problem, instead of failing (see [`DataStore::set_ignore_errors`]).
+    pub fn set_ignore_errors(&mut self, ignore: bool) {
+        self.store.set_ignore_errors(ignore);
+    }
+
+    /// Note what every write changes from now on, for [`Self::take_events`]
+    /// (see [`crate::store::events`]).
+    pub fn record_events(&mut self) {
+        self.store.record_events();
+    }
+
+    /// The changes written since the last call, oldest first. Empty unless
+    /// [`Self::record_events`] was called.
+    pub fn take_events(&mut self) -> Vec<crate::store::events::StoreEvent> {
+        self.store.take_events()
+    }
+
+    /// Stop long operations as soon as `cancel` is cancelled (see
+    /// [`crate::cancel`]). Without one, they always run to the end.
+    pub fn set_cancellation(&mut self, cancel: Cancellation) {
+        self.cancel = cancel;
+    }
+
+    /// Record `context` on pads created from now on. The client gathers it
+    /// (padz itself runs no git), so a library caller that never sets one
+    /// creates pads without it.
+    pub fn set_creation_context(&mut self, context: Option<CreationContext>) {
+        self.creation_context = context;
+    }
+
+    /// Stamp pads created from now on with `ownership.identity`, and, when
+    /// `ownership.owner_only_edit` is on, refuse to edit or delete pads
+    /// someone else created unless `ownership.force` is set (see
+    /// [`commands::ownership`]).
+    pub fn set_ownership(&mut self, ownership: commands::ownership::Ownership) {
+        self.ownership = ownership;
+    }
+
+    /// Keep every scope within `limit` pinned pads when pinning (see
+    /// [`commands::pinning`]). Without one, any number may be pinned.
+    pub fn set_pin_limit(&mut self, limit: commands::pinning::PinLimit) {
+        self.pin_limit = limit;
+    }
+
+    /// Use `key` for secret fences instead of the key file in the global
+    /// data directory.
+    pub fn with_secret_key(mut self, key: SecretKey) -> Self {
+        self.secret_key = Some(key);
+        self
     }
 
     pub fn paths(&self) -> &commands::PadzPaths {
         &self.paths
     }
+
+    pub fn is_dry_run(&self) -> bool {
+        self.dry_run
+    }
 }
 
 pub use crate::model::TodoStatus;
+pub use commands::compile::{CompileOptions, CompileOrder};
 pub use commands::doctor::DoctorOutcome;
+pub use commands::export::ArchiveFormat;
 pub use commands::get::{PadFilter, PadStatusFilter};
+pub use commands::grouping::{GroupBy, PadGroup};
 pub use commands::import::ImportReport;
 pub use commands::init::InitializationOutcome;
+pub use commands::journal::JournalOutcome;
+pub use commands::maintain::MaintainOutcome;
+pub use commands::pinboard::{PinboardEntry, PinboardOutcome};
+pub use commands::print::{Paper, PrintFormat, PrintOptions};
+pub use commands::publish::Upload;
 pub use commands::purge::{PurgeOutcome, PurgeSelection};
+pub use commands::query::Query;
 pub use commands::tagging::{TaggingOutcome, TaggingResult};
 pub use commands::tags::{TagCatalogOutcome, TagRegistryOutcome};
+pub use commands::timeline::{Timeline, TimelineOutcome};
+pub use commands::tree::TreeOutcome;
+pub use commands::update::Amend;
+pub use commands::verify::{IntegrityIssue, IntegrityProblem, IntegrityReport};
+pub use commands::which::{Provenance, WhichOutcome};
 pub use commands::{CmdResult, PadUpdate, PadzPaths};
 
 #[cfg(test)]
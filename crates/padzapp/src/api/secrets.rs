@@ -0,0 +1,195 @@
+//! Sealing and revealing `secret` fences (see [`crate::secrets`]).
+//!
+//! Content is sealed on its way into the store — create, update, import, and
+//! the post-editor refresh — so a plaintext fence never outlives the write
+//! that introduced it. Reading is unchanged: pads come back sealed, and a client
+//! asks for [`PadzApi::reveal_secrets`] explicitly.
+
+use crate::error::Result;
+use crate::model::Scope;
+use crate::secrets::{self, SecretKey};
+use crate::store::{Bucket, DataStore};
+use std::collections::HashSet;
+use std::path::PathBuf;
+use uuid::Uuid;
+
+use super::PadzApi;
+
+impl<S: DataStore> PadzApi<S> {
+    /// Decrypt every sealed secret fence in `content`.
+    ///
+    /// Content without secret fences is returned as-is, without touching the key.
+    pub fn reveal_secrets(&self, content: &str) -> Result<String> {
+        if !secrets::has_secrets(content) {
+            return Ok(content.to_string());
+        }
+        secrets::reveal(content, &self.secret_key()?)
+    }
+
+    /// Encrypt any plaintext secret fences in `content` before it is stored.
+    pub(super) fn seal_secrets(&self, content: String) -> Result<String> {
+        self.sealer().seal(content)
+    }
+
+    /// What [`Self::seal_secrets`] does, detached from the API, for sealing
+    /// inside a store transaction.
+    pub(super) fn sealer(&self) -> Sealer {
+        Sealer {
+            key: self.secret_key.clone(),
+            key_path: secrets::key_path(&self.paths.global),
+        }
+    }
+
+    pub(super) fn secret_key(&self) -> Result<SecretKey> {
+        self.sealer().key()
+    }
+}
+
+/// Seals secret fences with the API's key, loading (or creating) it only when
+/// a fence needs it.
+pub(super) struct Sealer {
+    key: Option<SecretKey>,
+    key_path: PathBuf,
+}
+
+impl Sealer {
+    pub(super) fn seal(&self, content: String) -> Result<String> {
+        if !secrets::has_unsealed(&content) {
+            return Ok(content);
+        }
+        secrets::seal(&content, &self.key()?)
+    }
+
+    fn key(&self) -> Result<SecretKey> {
+        match &self.key {
+            Some(key) => Ok(key.clone()),
+            None => SecretKey::load_or_create(&self.key_path),
+        }
+    }
+}
+
+/// The ids of every pad in `scope`, in any bucket.
+pub(super) fn pad_ids<S: DataStore>(store: &S, scope: Scope) -> Result<HashSet<Uuid>> {
+    let mut ids = HashSet::new();
+    for bucket in [Bucket::Active, Bucket::Archived, Bucket::Deleted] {
+        ids.extend(
+            store
+                .list_pads(scope, bucket)?
+                .into_iter()
+                .map(|pad| pad.metadata.id),
+        );
+    }
+    Ok(ids)
+}
+
+/// Seals the pads in `scope` that are not among `known`: the ones a bulk
+/// write such as an import just added, whose text came in from outside.
+pub(super) fn seal_new_pads<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    known: &HashSet<Uuid>,
+    sealer: &Sealer,
+) -> Result<()> {
+    for bucket in [Bucket::Active, Bucket::Archived, Bucket::Deleted] {
+        for mut pad in store.list_pads(scope, bucket)? {
+            if known.contains(&pad.metadata.id) || !secrets::has_unsealed(&pad.content) {
+                continue;
+            }
+            pad.content = sealer.seal(pad.content)?;
+            store.save_pad(&pad, scope, bucket)?;
+        }
+    }
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use crate::api::test_support::make_api;
+    use crate::api::PadUpdate;
+    use crate::index::DisplayIndex;
+    use crate::model::Scope;
+    use crate::secrets::SecretKey;
+    use crate::store::Bucket;
+    use crate::store::DataStore;
+
+    const BODY: &str = "Host is db.staging.\n\n```secret\nhunter2\n```";
+
+    #[test]
+    fn test_create_seals_secret_fences() {
+        let mut api = make_api().with_secret_key(SecretKey::generate());
+
+        let result = api
+            .create_pad(Scope::Project, "Staging".into(), BODY.into(), None)
+            .unwrap();
+
+        let id = result.affected_pads[0].pad.metadata.id;
+        let stored = api
+            .store
+            .get_pad(&id, Scope::Project, Bucket::Active)
+            .unwrap();
+        assert!(!stored.content.contains("hunter2"));
+        assert!(api
+            .reveal_secrets(&stored.content)
+            .unwrap()
+            .contains("hunter2"));
+    }
+
+    #[test]
+    fn test_update_seals_secret_fences() {
+        let mut api = make_api().with_secret_key(SecretKey::generate());
+        api.create_pad(Scope::Project, "Staging".into(), "".into(), None)
+            .unwrap();
+
+        let result = api
+            .update_pads(
+                Scope::Project,
+                &[PadUpdate::new(
+                    DisplayIndex::Regular(1),
+                    "Staging".into(),
+                    BODY.into(),
+                )],
+            )
+            .unwrap();
+
+        assert!(!result.affected_pads[0].pad.content.contains("hunter2"));
+    }
+
+    #[test]
+    fn test_import_seals_secret_fences() {
+        let mut api = make_api().with_secret_key(SecretKey::generate());
+        api.create_pad(Scope::Project, "Existing".into(), "".into(), None)
+            .unwrap();
+        let temp_dir = tempfile::tempdir().unwrap();
+        let file_path = temp_dir.path().join("staging.md");
+        std::fs::write(&file_path, format!("Staging\n\n{BODY}")).unwrap();
+
+        api.import_pads(Scope::Project, vec![file_path], &[".md".to_string()])
+            .unwrap();
+
+        let stored = api
+            .store
+            .list_pads(Scope::Project, Bucket::Active)
+            .unwrap()
+            .into_iter()
+            .find(|pad| pad.metadata.title == "Staging")
+            .unwrap();
+        assert!(!stored.content.contains("hunter2"));
+        assert!(stored.content.contains("padz-sealed:v1:"));
+        assert!(api
+            .reveal_secrets(&stored.content)
+            .unwrap()
+            .contains("hunter2"));
+    }
+
+    #[test]
+    fn test_reveal_without_secrets_needs_no_key() {
+        // The default API would load the key from /tmp/global; content with no
+        // fences must not get that far.
+        let api = make_api();
+
+        assert_eq!(
+            api.reveal_secrets("Plain\n\nbody").unwrap(),
+            "Plain\n\nbody"
+        );
+    }
+}
@@ -5,7 +5,7 @@
 //! bare numbers for commands that operate on the Deleted or Archived bucket.
 
 use crate::error::Result;
-use crate::index::{parse_index_or_range, PadSelector};
+use crate::index::{parse_index_or_range, DisplayIndex, PadSelector};
 use uuid::Uuid;
 
 pub(super) fn parse_selectors<I: AsRef<str>>(inputs: &[I]) -> Result<Vec<PadSelector>> {
@@ -48,7 +48,7 @@ pub(super) fn parse_selectors<I: AsRef<str>>(inputs: &[I]) -> Result<Vec<PadSele
 pub(super) fn parse_selectors_for_deleted<I: AsRef<str>>(inputs: &[I]) -> Result<Vec<PadSelector>> {
     let normalized: Vec<String> = inputs
         .iter()
-        .map(|s| normalize_to_deleted_index(s.as_ref()))
+        .map(|s| normalize_bare_index(s.as_ref(), DisplayIndex::Deleted))
         .collect();
 
     parse_selectors(&normalized)
@@ -61,7 +61,7 @@ pub(super) fn parse_selectors_for_archived<I: AsRef<str>>(
 ) -> Result<Vec<PadSelector>> {
     let normalized: Vec<String> = inputs
         .iter()
-        .map(|s| normalize_to_archived_index(s.as_ref()))
+        .map(|s| normalize_bare_index(s.as_ref(), DisplayIndex::Archived))
         .collect();
 
     parse_selectors(&normalized)
@@ -75,75 +75,36 @@ pub(super) fn canonicalize_or_self(p: &std::path::Path) -> std::path::PathBuf {
     p.canonicalize().unwrap_or_else(|_| p.to_path_buf())
 }
 
-/// Normalizes an index string to an archived index if it's a bare number.
-/// "3" -> "ar3", "ar3" -> "ar3", "p1" -> "p1", "3-5" -> "ar3-ar5"
+/// Rewrites a bare number into the namespace `into` builds, for commands
+/// that only ever work on one bucket:
+/// "3" -> "d3", "d3" -> "d3", "p1" -> "p1", "3-5" -> "d3-d5" (for deleted).
+/// Only the *last* segment of a path is rewritten: "1.2" -> "1.d2".
 /// UUIDs (full or short hex) pass through unchanged.
-fn normalize_to_archived_index(s: &str) -> String {
+fn normalize_bare_index(s: &str, into: fn(usize) -> DisplayIndex) -> String {
     if Uuid::parse_str(s).is_ok() || looks_like_short_uuid(s) {
         return s.to_string();
     }
     if let Some(dash_pos) = s.find('-') {
         if dash_pos > 0 {
-            let start_str = &s[..dash_pos];
-            let end_str = &s[dash_pos + 1..];
-            let normalized_start = normalize_path_for_archived(start_str);
-            let normalized_end = normalize_path_for_archived(end_str);
+            let normalized_start = normalize_bare_path(&s[..dash_pos], into);
+            let normalized_end = normalize_bare_path(&s[dash_pos + 1..], into);
             return format!("{}-{}", normalized_start, normalized_end);
         }
     }
-    normalize_path_for_archived(s)
+    normalize_bare_path(s, into)
 }
 
-fn normalize_path_for_archived(s: &str) -> String {
+fn normalize_bare_path(s: &str, into: fn(usize) -> DisplayIndex) -> String {
     let mut parts: Vec<String> = s.split('.').map(|s| s.to_string()).collect();
     if let Some(last) = parts.last_mut() {
-        if last.chars().all(|c| c.is_ascii_digit()) && !last.is_empty() {
-            *last = format!("ar{}", last);
+        let is_bare = !last.is_empty() && last.chars().all(|c| c.is_ascii_digit());
+        if let (true, Ok(DisplayIndex::Regular(n))) = (is_bare, last.parse::<DisplayIndex>()) {
+            *last = into(n).to_string();
         }
     }
     parts.join(".")
 }
 
-/// Normalizes an index string to a deleted index if it's a bare number.
-/// "3" -> "d3", "d3" -> "d3", "p1" -> "p1", "3-5" -> "d3-d5"
-/// UUIDs (full or short hex) pass through unchanged.
-fn normalize_to_deleted_index(s: &str) -> String {
-    if Uuid::parse_str(s).is_ok() || looks_like_short_uuid(s) {
-        return s.to_string();
-    }
-    if let Some(dash_pos) = s.find('-') {
-        if dash_pos > 0 {
-            let start_str = &s[..dash_pos];
-            let end_str = &s[dash_pos + 1..];
-            let normalized_start = normalize_path_for_deleted(start_str);
-            let normalized_end = normalize_path_for_deleted(end_str);
-            return format!("{}-{}", normalized_start, normalized_end);
-        }
-    }
-    normalize_path_for_deleted(s)
-}
-
-/// Normalizes a path string (e.g., "1", "1.2", "p1") for deleted operations.
-/// The *last* segment of a path, if it's a bare number, gets prefixed with 'd'.
-/// "3" -> "d3", "1.2" -> "1.d2", "p1.2" -> "p1.d2", "d1.2" -> "d1.d2"
-fn normalize_path_for_deleted(s: &str) -> String {
-    let mut parts: Vec<String> = s.split('.').map(|s| s.to_string()).collect();
-    if let Some(last) = parts.last_mut() {
-        *last = normalize_single_to_deleted(last);
-    }
-    parts.join(".")
-}
-
-/// Normalizes a single index (not a range) to deleted format.
-/// "3" -> "d3", "d3" -> "d3", "p1" -> "p1"
-fn normalize_single_to_deleted(s: &str) -> String {
-    if s.chars().all(|c| c.is_ascii_digit()) && !s.is_empty() {
-        format!("d{}", s)
-    } else {
-        s.to_string()
-    }
-}
-
 /// A short UUID is a hex string that isn't parseable as a DisplayIndex.
 /// Non-empty, all hex digits, and contains at least one non-digit (otherwise
 /// it would parse as a Regular DI).
@@ -256,66 +217,90 @@ mod tests {
 
     #[test]
     fn test_normalize_to_deleted_preserves_short_uuid() {
-        assert_eq!(normalize_to_deleted_index("766d5dab"), "766d5dab");
+        assert_eq!(
+            normalize_bare_index("766d5dab", DisplayIndex::Deleted),
+            "766d5dab"
+        );
     }
 
     #[test]
     fn test_normalize_to_deleted_preserves_full_uuid() {
         assert_eq!(
-            normalize_to_deleted_index("550e8400-e29b-41d4-a716-446655440000"),
+            normalize_bare_index(
+                "550e8400-e29b-41d4-a716-446655440000",
+                DisplayIndex::Deleted
+            ),
             "550e8400-e29b-41d4-a716-446655440000"
         );
     }
 
     #[test]
     fn test_normalize_to_archived_preserves_short_uuid() {
-        assert_eq!(normalize_to_archived_index("766d5dab"), "766d5dab");
+        assert_eq!(
+            normalize_bare_index("766d5dab", DisplayIndex::Archived),
+            "766d5dab"
+        );
     }
 
     #[test]
     fn test_normalize_to_archived_preserves_full_uuid() {
         assert_eq!(
-            normalize_to_archived_index("550e8400-e29b-41d4-a716-446655440000"),
+            normalize_bare_index(
+                "550e8400-e29b-41d4-a716-446655440000",
+                DisplayIndex::Archived
+            ),
             "550e8400-e29b-41d4-a716-446655440000"
         );
     }
 
     #[test]
-    fn test_normalize_single_to_deleted() {
-        assert_eq!(normalize_single_to_deleted("1"), "d1");
-        assert_eq!(normalize_single_to_deleted("42"), "d42");
+    fn test_normalize_single_index_to_deleted() {
+        assert_eq!(normalize_bare_index("1", DisplayIndex::Deleted), "d1");
+        assert_eq!(normalize_bare_index("42", DisplayIndex::Deleted), "d42");
 
-        assert_eq!(normalize_single_to_deleted("d1"), "d1");
-        assert_eq!(normalize_single_to_deleted("d42"), "d42");
+        assert_eq!(normalize_bare_index("d1", DisplayIndex::Deleted), "d1");
+        assert_eq!(normalize_bare_index("d42", DisplayIndex::Deleted), "d42");
 
-        assert_eq!(normalize_single_to_deleted("p1"), "p1");
-        assert_eq!(normalize_single_to_deleted("p99"), "p99");
+        assert_eq!(normalize_bare_index("p1", DisplayIndex::Deleted), "p1");
+        assert_eq!(normalize_bare_index("p99", DisplayIndex::Deleted), "p99");
 
-        assert_eq!(normalize_single_to_deleted(""), "");
+        assert_eq!(normalize_bare_index("", DisplayIndex::Deleted), "");
 
-        assert_eq!(normalize_single_to_deleted("abc"), "abc");
+        assert_eq!(normalize_bare_index("abc", DisplayIndex::Deleted), "abc");
     }
 
     #[test]
     fn test_normalize_to_deleted_index_ranges() {
-        assert_eq!(normalize_to_deleted_index("3-5"), "d3-d5");
-        assert_eq!(normalize_to_deleted_index("1-10"), "d1-d10");
+        assert_eq!(normalize_bare_index("3-5", DisplayIndex::Deleted), "d3-d5");
+        assert_eq!(
+            normalize_bare_index("1-10", DisplayIndex::Deleted),
+            "d1-d10"
+        );
 
-        assert_eq!(normalize_to_deleted_index("d3-d5"), "d3-d5");
+        assert_eq!(
+            normalize_bare_index("d3-d5", DisplayIndex::Deleted),
+            "d3-d5"
+        );
 
-        assert_eq!(normalize_to_deleted_index("3-d5"), "d3-d5");
-        assert_eq!(normalize_to_deleted_index("d3-5"), "d3-d5");
+        assert_eq!(normalize_bare_index("3-d5", DisplayIndex::Deleted), "d3-d5");
+        assert_eq!(normalize_bare_index("d3-5", DisplayIndex::Deleted), "d3-d5");
 
-        assert_eq!(normalize_to_deleted_index("3"), "d3");
-        assert_eq!(normalize_to_deleted_index("d3"), "d3");
+        assert_eq!(normalize_bare_index("3", DisplayIndex::Deleted), "d3");
+        assert_eq!(normalize_bare_index("d3", DisplayIndex::Deleted), "d3");
 
-        assert_eq!(normalize_to_deleted_index("1.2"), "1.d2");
-        assert_eq!(normalize_to_deleted_index("p1.2"), "p1.d2");
-        assert_eq!(normalize_to_deleted_index("d1.2"), "d1.d2");
-        assert_eq!(normalize_to_deleted_index("1.p2"), "1.p2");
-        assert_eq!(normalize_to_deleted_index("1.d2"), "1.d2");
-        assert_eq!(normalize_to_deleted_index("1.2-1.4"), "1.d2-1.d4");
-        assert_eq!(normalize_to_deleted_index("d1.2-d1.4"), "d1.d2-d1.d4");
+        assert_eq!(normalize_bare_index("1.2", DisplayIndex::Deleted), "1.d2");
+        assert_eq!(normalize_bare_index("p1.2", DisplayIndex::Deleted), "p1.d2");
+        assert_eq!(normalize_bare_index("d1.2", DisplayIndex::Deleted), "d1.d2");
+        assert_eq!(normalize_bare_index("1.p2", DisplayIndex::Deleted), "1.p2");
+        assert_eq!(normalize_bare_index("1.d2", DisplayIndex::Deleted), "1.d2");
+        assert_eq!(
+            normalize_bare_index("1.2-1.4", DisplayIndex::Deleted),
+            "1.d2-1.d4"
+        );
+        assert_eq!(
+            normalize_bare_index("d1.2-d1.4", DisplayIndex::Deleted),
+            "d1.d2-d1.d4"
+        );
     }
 
     #[test]
@@ -4,7 +4,7 @@ use crate::commands;
 use crate::error::{PadzError, Result};
 use crate::index::parse_index_or_range;
 use crate::model::Scope;
-use crate::store::DataStore;
+use crate::store::{self, DataStore};
 
 use super::selectors::parse_selectors;
 use super::PadzApi;
@@ -16,7 +16,10 @@ impl<S: DataStore> PadzApi<S> {
         indexes: &[I],
     ) -> Result<commands::CmdResult> {
         let selectors = parse_selectors(indexes)?;
-        commands::pinning::pin(&mut self.store, scope, &selectors)
+        let limit = self.pin_limit;
+        store::transaction(&mut self.store, |store| {
+            commands::pinning::pin_within(store, scope, &selectors, limit)
+        })
     }
 
     pub fn unpin_pads<I: AsRef<str>>(
@@ -25,7 +28,20 @@ impl<S: DataStore> PadzApi<S> {
         indexes: &[I],
     ) -> Result<commands::CmdResult> {
         let selectors = parse_selectors(indexes)?;
-        commands::pinning::unpin(&mut self.store, scope, &selectors)
+        store::transaction(&mut self.store, |store| {
+            commands::pinning::unpin(store, scope, &selectors)
+        })
+    }
+
+    /// Pinned pads from the project scope (when there is one) and the global
+    /// scope, independent of which scope the caller is bound to.
+    pub fn pinboard(&self) -> Result<commands::pinboard::PinboardOutcome> {
+        let mut scopes = Vec::new();
+        if self.paths.project.is_some() {
+            scopes.push(Scope::Project);
+        }
+        scopes.push(Scope::Global);
+        commands::pinboard::run(&self.store, &scopes)
     }
 
     /// Marks pads as Done, reporting already-done selectors as semantic no-ops.
@@ -35,7 +51,9 @@ impl<S: DataStore> PadzApi<S> {
         indexes: &[I],
     ) -> Result<commands::CmdResult> {
         let selectors = parse_selectors(indexes)?;
-        commands::status::complete(&mut self.store, scope, &selectors)
+        store::transaction(&mut self.store, |store| {
+            commands::status::complete(store, scope, &selectors)
+        })
     }
 
     /// Reopens pads, reporting already-planned selectors as semantic no-ops.
@@ -45,7 +63,9 @@ impl<S: DataStore> PadzApi<S> {
         indexes: &[I],
     ) -> Result<commands::CmdResult> {
         let selectors = parse_selectors(indexes)?;
-        commands::status::reopen(&mut self.store, scope, &selectors)
+        store::transaction(&mut self.store, |store| {
+            commands::status::reopen(store, scope, &selectors)
+        })
     }
 
     /// Moves pads under a parent (or root), reporting same-parent moves as
@@ -66,7 +86,9 @@ impl<S: DataStore> PadzApi<S> {
         } else {
             None
         };
-        commands::move_pads::run(&mut self.store, scope, &selectors, parent_selector.as_ref())
+        store::transaction(&mut self.store, |store| {
+            commands::move_pads::run(store, scope, &selectors, parent_selector.as_ref())
+        })
     }
 
     /// Propagate todo status changes upward from a child's parent.
@@ -76,7 +98,9 @@ impl<S: DataStore> PadzApi<S> {
     /// triggers reconciliation (via `list_pads`), which garbage-collects empty
     /// files — a problem when the pad hasn't been filled yet (editor flow).
     pub fn propagate_status(&mut self, scope: Scope, parent_id: Option<uuid::Uuid>) -> Result<()> {
-        crate::todos::propagate_status_change(&mut self.store, scope, parent_id)
+        store::transaction(&mut self.store, |store| {
+            crate::todos::propagate_status_change(store, scope, parent_id)
+        })
     }
 }
 
@@ -101,6 +125,22 @@ mod tests {
         assert!(!result.affected_pads[0].pad.metadata.is_pinned);
     }
 
+    #[test]
+    fn test_api_pinboard_spans_project_and_global() {
+        let mut api = make_api();
+        api.create_pad(Scope::Project, "Here".into(), "".into(), None)
+            .unwrap();
+        api.create_pad(Scope::Global, "Everywhere".into(), "".into(), None)
+            .unwrap();
+        api.pin_pads(Scope::Project, &["1"]).unwrap();
+        api.pin_pads(Scope::Global, &["1"]).unwrap();
+
+        let board = api.pinboard().unwrap();
+
+        let scopes: Vec<Scope> = board.entries.iter().map(|e| e.scope).collect();
+        assert_eq!(scopes, vec![Scope::Project, Scope::Global]);
+    }
+
     #[test]
     fn test_api_move_pads() {
         let mut api = make_api();
@@ -8,7 +8,7 @@ use crate::commands::tagging::TaggingResult;
 use crate::commands::tags::{TagCatalogOutcome, TagRegistryOutcome};
 use crate::error::Result;
 use crate::model::Scope;
-use crate::store::DataStore;
+use crate::store::{self, DataStore};
 
 use super::selectors::parse_selectors;
 use super::PadzApi;
@@ -36,7 +36,9 @@ impl<S: DataStore> PadzApi<S> {
 
     /// Delete a tag and report how many pads the cascade changed.
     pub fn delete_tag(&mut self, scope: Scope, name: &str) -> Result<TagRegistryOutcome> {
-        commands::tags::delete_tag(&mut self.store, scope, name)
+        store::transaction(&mut self.store, |store| {
+            commands::tags::delete_tag(store, scope, name)
+        })
     }
 
     /// Rename a tag and report how many pads were updated.
@@ -46,7 +48,9 @@ impl<S: DataStore> PadzApi<S> {
         old_name: &str,
         new_name: &str,
     ) -> Result<TagRegistryOutcome> {
-        commands::tags::rename_tag(&mut self.store, scope, old_name, new_name)
+        store::transaction(&mut self.store, |store| {
+            commands::tags::rename_tag(store, scope, old_name, new_name)
+        })
     }
 
     /// Add requested tags and distinguish changed pads from an all-present no-op.
@@ -57,7 +61,9 @@ impl<S: DataStore> PadzApi<S> {
         tags: &[String],
     ) -> Result<TaggingResult> {
         let selectors = parse_selectors(indexes)?;
-        commands::tagging::add_tags(&mut self.store, scope, &selectors, tags)
+        store::transaction(&mut self.store, |store| {
+            commands::tagging::add_tags(store, scope, &selectors, tags)
+        })
     }
 
     /// Remove requested tags and distinguish changed pads from a none-present no-op.
@@ -68,7 +74,9 @@ impl<S: DataStore> PadzApi<S> {
         tags: &[String],
     ) -> Result<TaggingResult> {
         let selectors = parse_selectors(indexes)?;
-        commands::tagging::remove_tags(&mut self.store, scope, &selectors, tags)
+        store::transaction(&mut self.store, |store| {
+            commands::tagging::remove_tags(store, scope, &selectors, tags)
+        })
     }
 }
 
@@ -140,6 +148,7 @@ mod tests {
                 old_name: "old-name".into(),
                 new_name: "new-name".into(),
                 affected_pads: 0,
+                pads: Vec::new(),
             }
         );
     }
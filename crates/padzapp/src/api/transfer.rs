@@ -1,9 +1,11 @@
-//! Export/import to files, and clone/migrate between stores.
+//! Export/import to files, printing, publishing, and clone/migrate between stores.
 
 use crate::commands;
 use crate::error::{PadzError, Result};
-use crate::model::Scope;
-use crate::store::DataStore;
+use crate::index::PadSelector;
+use crate::model::{Publication, PublishService, Scope};
+use crate::secrets;
+use crate::store::{self, Bucket, DataStore};
 
 use super::selectors::{canonicalize_or_self, parse_selectors};
 use super::PadzApi;
@@ -17,7 +19,15 @@ impl<S: DataStore> PadzApi<S> {
         with_metadata: bool,
     ) -> Result<commands::export::ExportOutcome> {
         let selectors = parse_selectors(indexes)?;
-        commands::export::run(&self.store, scope, &selectors, nesting, with_metadata)
+        commands::export::run(
+            &self.store,
+            scope,
+            &selectors,
+            nesting,
+            with_metadata,
+            &*self.progress,
+            &self.cancel,
+        )
     }
 
     pub fn export_pads_single_file<I: AsRef<str>>(
@@ -31,14 +41,142 @@ impl<S: DataStore> PadzApi<S> {
         commands::export::run_single_file(&self.store, scope, &selectors, title, nesting)
     }
 
-    pub fn export_pads_json<I: AsRef<str>>(
+    /// The selected pads with their full metadata, in one `format` artifact
+    /// that `padz import` reads back (see [`commands::export::run_archive`]).
+    pub fn export_pads_archive<I: AsRef<str>>(
         &self,
         scope: Scope,
         indexes: &[I],
         nesting: commands::NestingMode,
+        format: commands::export::ArchiveFormat,
+    ) -> Result<commands::export::ExportOutcome> {
+        let selectors = parse_selectors(indexes)?;
+        let options = commands::export::ArchiveOptions {
+            format,
+            project: self.home(scope).map(|dir| dir.display().to_string()),
+        };
+        commands::export::run_archive(
+            &self.store,
+            scope,
+            &selectors,
+            nesting,
+            &options,
+            &*self.progress,
+            &self.cancel,
+        )
+    }
+
+    /// The metadata of the pads selected by `indexes` (every active and
+    /// archived pad when empty) as a spreadsheet of `fields`.
+    pub fn export_pads_csv<I: AsRef<str>>(
+        &self,
+        scope: Scope,
+        indexes: &[I],
+        nesting: commands::NestingMode,
+        fields: &[commands::csv::CsvField],
+    ) -> Result<commands::export::ExportOutcome> {
+        let selectors = parse_selectors(indexes)?;
+        let roots = self.known_projects()?;
+        let projects = commands::csv::Projects {
+            roots: &roots,
+            home: self.home(scope),
+        };
+        commands::csv::run(&self.store, scope, &selectors, nesting, fields, projects)
+    }
+
+    /// Stream every active and archived pad into `dir` (see
+    /// [`commands::export::run_into_dir`]).
+    pub fn export_pads_into_dir(
+        &self,
+        scope: Scope,
+        dir: &std::path::Path,
+        resume: bool,
+    ) -> Result<commands::export::ExportReport> {
+        commands::export::run_into_dir(
+            &self.store,
+            scope,
+            dir,
+            resume,
+            self.dry_run,
+            &*self.progress,
+            &self.cancel,
+        )
+    }
+
+    /// Assemble the pads selected by `indexes` and/or `options.tags` into one
+    /// document, returned as an artifact for the caller to place.
+    pub fn compile_pads<I: AsRef<str>>(
+        &self,
+        scope: Scope,
+        indexes: &[I],
+        options: &commands::compile::CompileOptions,
+    ) -> Result<commands::export::ExportOutcome> {
+        let selectors = if indexes.is_empty() {
+            vec![]
+        } else {
+            parse_selectors(indexes)?
+        };
+        commands::compile::run(&self.store, scope, &selectors, options)
+    }
+
+    /// The pads tagged with all of `tags` and updated since `since`, for a
+    /// release's notes (see [`commands::release_notes`]).
+    pub fn release_notes(
+        &self,
+        scope: Scope,
+        tags: &[String],
+        since: Option<chrono::DateTime<chrono::Utc>>,
+    ) -> Result<Vec<commands::release_notes::ReleaseNote>> {
+        commands::release_notes::run(&self.store, scope, tags, since)
+    }
+
+    /// Lay out the pads selected by `indexes` for paper, returned as an
+    /// artifact for the caller to print or place.
+    pub fn print_pads<I: AsRef<str>>(
+        &self,
+        scope: Scope,
+        indexes: &[I],
+        options: &commands::print::PrintOptions,
     ) -> Result<commands::export::ExportOutcome> {
         let selectors = parse_selectors(indexes)?;
-        commands::export::run_json(&self.store, scope, &selectors, nesting)
+        commands::print::run(&self.store, scope, &selectors, options)
+    }
+
+    /// The pads selected by `indexes`, ready to publish on `service` for the
+    /// first time. The client uploads them and hands each publication back
+    /// to [`Self::record_publication`].
+    pub fn publish_uploads<I: AsRef<str>>(
+        &self,
+        scope: Scope,
+        indexes: &[I],
+        service: PublishService,
+    ) -> Result<Vec<commands::publish::Upload>> {
+        let selectors = parse_selectors(indexes)?;
+        commands::publish::uploads(&self.store, scope, &selectors, service)
+    }
+
+    /// Published pads edited since their upload, among `indexes` or, with
+    /// none, the whole scope; `service` narrows them to one service.
+    pub fn stale_publications<I: AsRef<str>>(
+        &self,
+        scope: Scope,
+        indexes: &[I],
+        service: Option<PublishService>,
+    ) -> Result<Vec<(PublishService, commands::publish::Upload)>> {
+        let selectors = parse_selectors(indexes)?;
+        commands::publish::stale(&self.store, scope, &selectors, service)
+    }
+
+    /// Remember where pad `id` was published.
+    pub fn record_publication(
+        &mut self,
+        scope: Scope,
+        id: uuid::Uuid,
+        publication: Publication,
+    ) -> Result<()> {
+        store::transaction(&mut self.store, |store| {
+            commands::publish::record(store, scope, id, publication)
+        })
     }
 
     /// Import independent filesystem sources into `scope` and retain partial
@@ -49,7 +187,38 @@ impl<S: DataStore> PadzApi<S> {
         paths: Vec<std::path::PathBuf>,
         import_exts: &[String],
     ) -> Result<commands::import::ImportReport> {
-        commands::import::run(&mut self.store, scope, paths, import_exts)
+        self.import_pads_with_options(scope, paths, import_exts, Default::default())
+    }
+
+    /// [`Self::import_pads`], importing as `options` say: the subdirectories of
+    /// directory sources too, titles from file names.
+    ///
+    /// Secret fences in the imported text are sealed before the import is
+    /// committed, as for any other write.
+    pub fn import_pads_with_options(
+        &mut self,
+        scope: Scope,
+        paths: Vec<std::path::PathBuf>,
+        import_exts: &[String],
+        options: commands::import::ImportOptions,
+    ) -> Result<commands::import::ImportReport> {
+        let progress = &*self.progress;
+        let cancel = &self.cancel;
+        let sealer = self.sealer();
+        store::transaction(&mut self.store, |store| {
+            let existing = super::secrets::pad_ids(store, scope)?;
+            let report = commands::import::run_with_options(
+                store,
+                scope,
+                paths,
+                import_exts,
+                options,
+                progress,
+                cancel,
+            )?;
+            super::secrets::seal_new_pads(store, scope, &existing, &sealer)?;
+            Ok(report)
+        })
     }
 
     /// Copy or migrate the requested selection into a resolved peer store.
@@ -69,6 +238,9 @@ impl<S: DataStore> PadzApi<S> {
             .map(|index| index.as_ref().to_string())
             .collect();
         let selectors = parse_selectors(&requested)?;
+        if mode == commands::transfer::TransferMode::Migrate {
+            check_migrate(&self.store, scope, &selectors, &self.ownership)?;
+        }
         let dest_padz =
             commands::transfer::resolve_target_dir(dest_path, self.paths.home.as_deref())?;
         // Refuse to transfer to the same store. For migrate the user would
@@ -81,20 +253,114 @@ impl<S: DataStore> PadzApi<S> {
                 dest_padz.display()
             )));
         }
-        let mut dest_store = commands::transfer::open_target_store(&dest_padz)?;
-        commands::transfer::run(
-            &mut self.store,
-            scope,
-            &mut dest_store,
-            Scope::Project,
-            &selectors,
-            commands::transfer::TransferRequest {
-                operation: mode,
-                direction: commands::transfer::TransferDirection::To,
-                peer_store: dest_padz,
-                requested_selection: requested_selection(requested),
-            },
-        )
+        let mut dest_store = commands::transfer::open_target_store(&dest_padz)?
+            .with_encryption(secrets::key_path(&self.paths.global));
+        if self.dry_run {
+            dest_store.arm_dry_run();
+        }
+        let progress = &*self.progress;
+        let cancel = &self.cancel;
+        in_transactions(&mut self.store, &mut dest_store, |source, dest| {
+            commands::transfer::run(
+                source,
+                scope,
+                dest,
+                Scope::Project,
+                &selectors,
+                commands::transfer::TransferRequest {
+                    operation: mode,
+                    direction: commands::transfer::TransferDirection::To,
+                    peer_store: dest_padz,
+                    requested_selection: requested_selection(requested),
+                },
+                progress,
+                cancel,
+            )
+        })
+    }
+
+    /// Moves the pads `indexes` select to the store `to` names, reporting each
+    /// with its index there (see [`commands::transfer::relocated`]). `to` is
+    /// `global`, `project` (this project's store), the directory name of a
+    /// registered project, or a path as `--to` takes for `migrate`.
+    pub fn relocate_pads<I: AsRef<str>>(
+        &mut self,
+        scope: Scope,
+        indexes: &[I],
+        to: &str,
+    ) -> Result<commands::CmdResult> {
+        let requested: Vec<String> = indexes
+            .iter()
+            .map(|index| index.as_ref().to_string())
+            .collect();
+        let selectors = parse_selectors(&requested)?;
+        check_migrate(&self.store, scope, &selectors, &self.ownership)?;
+        let (dest_padz, project) = self.destination(to)?;
+        let current_padz = self.paths.scope_dir(scope)?;
+        if canonicalize_or_self(&current_padz) == canonicalize_or_self(&dest_padz) {
+            return Err(PadzError::Api(format!(
+                "The pads are already in '{}'",
+                dest_padz.display()
+            )));
+        }
+        let mut dest_store = commands::transfer::open_target_store(&dest_padz)?
+            .with_encryption(secrets::key_path(&self.paths.global));
+        if self.dry_run {
+            dest_store.arm_dry_run();
+        }
+        let progress = &*self.progress;
+        let cancel = &self.cancel;
+        in_transactions(&mut self.store, &mut dest_store, |source, dest| {
+            let report = commands::transfer::run(
+                source,
+                scope,
+                dest,
+                Scope::Project,
+                &selectors,
+                commands::transfer::TransferRequest {
+                    operation: commands::transfer::TransferMode::Migrate,
+                    direction: commands::transfer::TransferDirection::To,
+                    peer_store: dest_padz,
+                    requested_selection: requested_selection(requested),
+                },
+                progress,
+                cancel,
+            )?;
+            commands::transfer::relocated(&report, dest, Scope::Project, project)
+        })
+    }
+
+    /// The store directory `to` names for [`Self::relocate_pads`], with its
+    /// project directory (`None` for the global store).
+    fn destination(&self, to: &str) -> Result<(std::path::PathBuf, Option<std::path::PathBuf>)> {
+        let padz_dir = match to {
+            "global" => {
+                // Made on first use, as any write to the global store would.
+                if !self.dry_run {
+                    crate::init::create_bucket_layout(&self.paths.global)?;
+                }
+                return Ok((self.paths.global.clone(), None));
+            }
+            "project" => self.paths.scope_dir(Scope::Project)?,
+            name => {
+                let registered =
+                    store::registry::load(&self.paths.global)?
+                        .into_iter()
+                        .find(|entry| {
+                            entry.remote.is_none()
+                                && entry.project_root().file_name().is_some_and(|n| n == name)
+                        });
+                match registered {
+                    Some(entry) => entry.path,
+                    None => commands::transfer::resolve_target_dir(
+                        std::path::Path::new(name),
+                        self.paths.home.as_deref(),
+                    )?,
+                }
+            }
+        };
+        let project = padz_dir.parent().map(std::path::Path::to_path_buf);
+        Ok((padz_dir, project))
     }
 
     /// Copy or migrate the requested selection from a resolved peer store.
@@ -117,27 +383,88 @@ impl<S: DataStore> PadzApi<S> {
                 source_padz.display()
             )));
         }
-        let mut source_store = commands::transfer::open_target_store(&source_padz)?;
+        let mut source_store = commands::transfer::open_target_store(&source_padz)?
+            .with_encryption(secrets::key_path(&self.paths.global));
+        if self.dry_run {
+            source_store.arm_dry_run();
+        }
         let requested: Vec<String> = indexes
             .iter()
             .map(|index| index.as_ref().to_string())
             .collect();
         let selectors =
             parse_selectors(&requested).map_err(|e| PadzError::Api(format!("{}", e)))?;
-        commands::transfer::run(
-            &mut source_store,
-            Scope::Project,
-            &mut self.store,
-            scope,
-            &selectors,
-            commands::transfer::TransferRequest {
-                operation: mode,
-                direction: commands::transfer::TransferDirection::From,
-                peer_store: source_padz,
-                requested_selection: requested_selection(requested),
-            },
-        )
+        if mode == commands::transfer::TransferMode::Migrate {
+            check_migrate(&source_store, Scope::Project, &selectors, &self.ownership)?;
+        }
+        let progress = &*self.progress;
+        let cancel = &self.cancel;
+        in_transactions(&mut source_store, &mut self.store, |source, dest| {
+            commands::transfer::run(
+                source,
+                Scope::Project,
+                dest,
+                scope,
+                &selectors,
+                commands::transfer::TransferRequest {
+                    operation: mode,
+                    direction: commands::transfer::TransferDirection::From,
+                    peer_store: source_padz,
+                    requested_selection: requested_selection(requested),
+                },
+                progress,
+                cancel,
+            )
+        })
+    }
+}
+
+/// Fails unless whoever runs the API may take the pads `selectors` name out of
+/// `store`, as a migrate does (see [`commands::ownership`]). No selectors
+/// means every pad that is not deleted, as in [`commands::transfer::run`].
+fn check_migrate<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    ownership: &commands::ownership::Ownership,
+) -> Result<()> {
+    if !ownership.enforced() {
+        return Ok(());
+    }
+    let mut targets = selectors.to_vec();
+    if targets.is_empty() {
+        for bucket in [Bucket::Active, Bucket::Archived] {
+            targets.extend(
+                store
+                    .list_metadata(scope, bucket)?
+                    .into_iter()
+                    .map(|metadata| PadSelector::Uuid(metadata.id)),
+            );
+        }
     }
+    commands::ownership::check_in(
+        store,
+        scope,
+        &targets,
+        false,
+        commands::helpers::TitleBucket::Any,
+        ownership,
+    )
+}
+
+/// Run a transfer as one transaction on each store, so a failed or cancelled
+/// one leaves both as they were. The destination commits first: if the source
+/// then fails to commit, a migrate leaves the pads in both stores rather than
+/// in neither.
+fn in_transactions<Src, Dst, T, F>(source: &mut Src, dest: &mut Dst, f: F) -> Result<T>
+where
+    Src: DataStore,
+    Dst: DataStore,
+    F: FnOnce(&mut Src, &mut Dst) -> Result<T>,
+{
+    store::transaction(source, |source| {
+        store::transaction(dest, |dest| f(source, dest))
+    })
 }
 
 fn requested_selection(selectors: Vec<String>) -> commands::transfer::TransferSelection {
@@ -156,7 +483,9 @@ mod tests {
     use crate::commands::transfer::TransferMode;
     use crate::model::Scope;
     use crate::store::Bucket;
+    use crate::test_utils::CancelAfter;
     use std::path::PathBuf;
+    use std::rc::Rc;
 
     /// Initialize a `.padz/<bucket>` layout at `dir` so it looks like an
     /// initialized store on disk — what `open_target_store` requires.
@@ -195,6 +524,29 @@ mod tests {
         assert_eq!(result.status, commands::import::ImportStatus::FullSuccess);
     }
 
+    #[test]
+    fn test_api_import_pads_cancelled_midway_imports_nothing() {
+        let mut api = make_api();
+        let ctrl_c = CancelAfter::new(1);
+        api.set_cancellation(ctrl_c.cancel.clone());
+        api.set_progress(Rc::new(ctrl_c));
+        let temp_dir = tempfile::tempdir().unwrap();
+        let first = temp_dir.path().join("first.md");
+        let second = temp_dir.path().join("second.md");
+        std::fs::write(&first, "First\n\nContent").unwrap();
+        std::fs::write(&second, "Second\n\nContent").unwrap();
+
+        let err = api
+            .import_pads(Scope::Project, vec![first, second], &[".md".to_string()])
+            .unwrap_err();
+
+        assert!(matches!(err, PadzError::Interrupted));
+        let listed = api
+            .get_pads(Scope::Project, Default::default(), &[] as &[String])
+            .unwrap();
+        assert!(listed.listed_pads.is_empty());
+    }
+
     // ------------------------------------------------------------------------
     // transfer_pads_to
     // ------------------------------------------------------------------------
@@ -276,6 +628,119 @@ mod tests {
         assert!(err.to_string().to_lowercase().contains("target"));
     }
 
+    #[test]
+    fn test_transfer_pads_to_cancelled_migrate_leaves_both_stores_untouched() {
+        let mut api = make_api();
+        api.create_pad(Scope::Project, "A".into(), "alpha".into(), None)
+            .unwrap();
+        api.create_pad(Scope::Project, "B".into(), "beta".into(), None)
+            .unwrap();
+        let ctrl_c = CancelAfter::new(1);
+        api.set_cancellation(ctrl_c.cancel.clone());
+        api.set_progress(Rc::new(ctrl_c));
+
+        let temp = tempfile::tempdir().unwrap();
+        let dest_padz = temp.path().join(".padz");
+        init_layout(&dest_padz);
+
+        let empty: &[&str] = &[];
+        let err = api
+            .transfer_pads_to(Scope::Project, empty, &dest_padz, TransferMode::Migrate)
+            .unwrap_err();
+
+        assert!(matches!(err, PadzError::Interrupted));
+        let source = api
+            .get_pads(Scope::Project, Default::default(), &[] as &[String])
+            .unwrap();
+        assert_eq!(source.listed_pads.len(), 2);
+        let dest = commands::transfer::open_target_store(&dest_padz).unwrap();
+        assert!(dest
+            .list_pads(Scope::Project, Bucket::Active)
+            .unwrap()
+            .is_empty());
+    }
+
+    #[test]
+    fn test_moving_or_migrating_others_pads_needs_force() {
+        use crate::commands::ownership::Ownership;
+
+        let temp = tempfile::tempdir().unwrap();
+        let here = temp.path().join("here").join(".padz");
+        let there = temp.path().join("there").join(".padz");
+        init_layout(&here);
+        init_layout(&there);
+        let mut api = make_api_at(here);
+        let ana = Ownership {
+            identity: Some("ana".into()),
+            owner_only_edit: true,
+            force: false,
+        };
+        api.set_ownership(ana.clone());
+        api.create_pad(Scope::Project, "Ana's".into(), "".into(), None)
+            .unwrap();
+
+        let bo = Ownership {
+            identity: Some("bo".into()),
+            ..ana
+        };
+        api.set_ownership(bo.clone());
+        let err = api
+            .relocate_pads(Scope::Project, &["1"], there.to_str().unwrap())
+            .unwrap_err();
+        assert!(err.to_string().contains("belongs to ana"));
+        let empty: &[&str] = &[];
+        assert!(api
+            .transfer_pads_to(Scope::Project, empty, &there, TransferMode::Migrate)
+            .is_err());
+        // A clone leaves the pad where it is.
+        let copy = temp.path().join("copy").join(".padz");
+        init_layout(&copy);
+        api.transfer_pads_to(Scope::Project, &["1"], &copy, TransferMode::Clone)
+            .unwrap();
+
+        api.set_ownership(Ownership { force: true, ..bo });
+        api.relocate_pads(Scope::Project, &["1"], there.to_str().unwrap())
+            .unwrap();
+    }
+
+    #[test]
+    fn test_relocate_pads_reports_where_each_pad_went() {
+        let temp = tempfile::tempdir().unwrap();
+        let here = temp.path().join("here").join(".padz");
+        let there = temp.path().join("there").join(".padz");
+        init_layout(&here);
+        init_layout(&there);
+        let mut api = make_api_at(here.clone());
+        api.create_pad(Scope::Project, "Stays".into(), "".into(), None)
+            .unwrap();
+        api.create_pad(Scope::Project, "Goes".into(), "".into(), None)
+            .unwrap();
+
+        let err = api
+            .relocate_pads(Scope::Project, &["1"], here.to_str().unwrap())
+            .unwrap_err();
+        assert!(err.to_string().contains("already in"));
+
+        let result = api
+            .relocate_pads(Scope::Project, &["1"], there.to_str().unwrap())
+            .unwrap();
+
+        let root = temp.path().canonicalize().unwrap();
+        assert_eq!(
+            result.outcomes,
+            vec![commands::CmdOutcome::Relocated {
+                path: vec![crate::index::DisplayIndex::Regular(1)],
+                title: "Goes".into(),
+                project: Some(root.join("there")),
+            }]
+        );
+        let left = api
+            .get_pads(Scope::Project, Default::default(), &[] as &[String])
+            .unwrap();
+        assert_eq!(left.listed_pads.len(), 1);
+        assert_eq!(left.listed_pads[0].pad.metadata.title, "Stays");
+    }
+
     // ------------------------------------------------------------------------
     // transfer_pads_from
     // ------------------------------------------------------------------------
@@ -1,18 +1,87 @@
-//! Low-level / ancillary API methods: path queries, pad refresh/remove, doctor.
+//! Low-level / ancillary API methods: path queries, pad refresh/remove, stats,
+//! usage counts, doctor, maintenance, the scope registry, integrity checks.
 
 use crate::commands;
 use crate::error::Result;
-use crate::index::{DisplayIndex, PadSelector};
+use crate::index::{DisplayIndex, DisplayPad, PadSelector};
 use crate::model::{Pad, Scope};
-use crate::store::DataStore;
+use crate::store::{self, DataStore};
+use crate::usage;
 
 use super::selectors::parse_selectors;
 use super::PadzApi;
 
 impl<S: DataStore> PadzApi<S> {
     /// Reconciles the store and returns a clean/repaired outcome with direct counts.
+    ///
+    /// For a project store that moved (see [`store::location`]), the paths
+    /// recorded on its pads are remapped and the new location is recorded.
     pub fn doctor(&mut self, scope: Scope) -> Result<commands::doctor::DoctorOutcome> {
-        commands::doctor::run(&mut self.store, scope)
+        let project_dir = match scope {
+            Scope::Project => self.paths.project.clone(),
+            Scope::Global => None,
+        };
+        let relocation = project_dir
+            .as_deref()
+            .and_then(|dir| store::location::check(dir).ok().flatten());
+        let outcome = commands::doctor::run(&mut self.store, scope, relocation.as_ref())?;
+        if let (Some(dir), Some(_)) = (&project_dir, &relocation) {
+            if !self.dry_run {
+                store::location::record(dir)?;
+            }
+        }
+        Ok(outcome)
+    }
+
+    /// Removes the files padz left behind in this scope that no pad refers to
+    /// (see [`store::gc`]); a dry run only lists them.
+    pub fn maintain(&self, scope: Scope) -> Result<commands::maintain::MaintainOutcome> {
+        let dir = self.paths.scope_dir(scope)?;
+        commands::maintain::run(&dir, chrono::Utc::now(), self.dry_run)
+    }
+
+    /// What the deleted bucket of `scope` holds (see
+    /// [`commands::stats::TrashSize`]).
+    pub fn trash_size(&self, scope: Scope) -> Result<commands::stats::TrashSize> {
+        commands::stats::trash(&self.store, scope)
+    }
+
+    /// Finds registered project stores whose directories are gone and, when
+    /// `confirmed`, drops them from the registry (see [`store::registry`]).
+    pub fn prune_scopes(&self, confirmed: bool) -> Result<commands::scopes::PruneOutcome> {
+        commands::scopes::prune(&self.store, &self.paths.global, confirmed, self.dry_run)
+    }
+
+    /// The global store and every registered project store, each with its
+    /// pinned and most recent pads (see [`commands::tree`]).
+    pub fn tree(&self) -> Result<commands::tree::TreeOutcome> {
+        commands::tree::run(&self.paths.global)
+    }
+
+    /// Searches the global store and every registered project store at once,
+    /// reporting those that could not be searched (see
+    /// [`commands::search_all`]).
+    pub fn search_all_scopes(
+        &self,
+        filter: commands::get::PadFilter,
+    ) -> Result<commands::search_all::AllScopesOutcome> {
+        commands::search_all::run(&self.paths.global, filter, Default::default())
+    }
+
+    /// Registers the remote store at `url` under `name` (see [`store::remote`]).
+    pub fn add_remote_scope(&self, name: &str, url: &str) -> Result<commands::scopes::RemoteAdded> {
+        commands::scopes::add_remote(&self.paths.global, name, url, self.dry_run)
+    }
+
+    /// Checks every pad's content against the checksum recorded when padz last
+    /// wrote it. Read-only: drift is reported, never repaired.
+    pub fn verify_store(&self, scope: Scope) -> Result<commands::verify::IntegrityReport> {
+        commands::verify::run(&self.store, scope)
+    }
+
+    /// Accepts the current contents as correct; returns how many checksums changed.
+    pub fn accept_checksums(&mut self, scope: Scope) -> Result<usize> {
+        commands::verify::accept(&mut self.store, scope)
     }
 
     pub fn pad_paths<I: AsRef<str>>(
@@ -48,7 +117,8 @@ impl<S: DataStore> PadzApi<S> {
         Ok(resolved.remove(0).0)
     }
 
-    /// Re-reads a pad from disk and syncs metadata (title, updated_at).
+    /// Re-reads a pad from disk and syncs metadata (title, updated_at), sealing
+    /// any secret fences the editor left in plain text.
     /// Returns None if the file content is empty (pad is hard-deleted).
     pub fn refresh_pad(&mut self, scope: Scope, id: &uuid::Uuid) -> Result<Option<Pad>> {
         use crate::store::Bucket;
@@ -58,12 +128,201 @@ impl<S: DataStore> PadzApi<S> {
             return Ok(None);
         }
         let mut updated = pad;
-        let content = updated.content.clone();
+        let content = self.seal_secrets(updated.content.clone())?;
         updated.update_from_raw(&content);
         self.store.save_pad(&updated, scope, Bucket::Active)?;
         Ok(Some(updated))
     }
 
+    /// Picks up a pad created empty for the editor once the editor closed.
+    ///
+    /// Refreshes it from disk and propagates its todo status to its parent,
+    /// which could not happen while the file was empty. The result carries
+    /// the pad at its display index; it has no affected pads when the user
+    /// left the file empty, which discards the pad.
+    pub fn finish_editor_create(
+        &mut self,
+        scope: Scope,
+        id: uuid::Uuid,
+    ) -> Result<commands::CmdResult> {
+        let Some(pad) = self.refresh_pad(scope, &id)? else {
+            return Ok(commands::CmdResult::default());
+        };
+        self.propagate_status(scope, pad.metadata.parent_id)?;
+        let path = self.display_path_by_id(scope, id)?;
+        Ok(commands::CmdResult::default().with_affected_pads(vec![editor_display_pad(pad, &path)]))
+    }
+
+    /// Picks up an existing pad after the editor closed on its file.
+    ///
+    /// Like [`Self::finish_editor_create`], with an `Updated` outcome for the
+    /// refresh; no affected pads when the user emptied the file.
+    pub fn finish_editor_edit(
+        &mut self,
+        scope: Scope,
+        id: uuid::Uuid,
+    ) -> Result<commands::CmdResult> {
+        let Some(pad) = self.refresh_pad(scope, &id)? else {
+            return Ok(commands::CmdResult::default());
+        };
+        let path = self.display_path_by_id(scope, id)?;
+        let outcome = commands::CmdOutcome::Updated {
+            path: path.clone(),
+            title: pad.metadata.title.clone(),
+            update_kind: commands::UpdateKind::Refresh,
+        };
+        let mut result =
+            commands::CmdResult::default().with_affected_pads(vec![editor_display_pad(pad, &path)]);
+        result.outcomes.push(outcome);
+        Ok(result)
+    }
+
+    /// Records that the given pads were just read (viewed, opened, peeked at).
+    pub fn record_access(&mut self, scope: Scope, ids: &[uuid::Uuid]) -> Result<()> {
+        commands::recent::touch(&mut self.store, scope, ids)
+    }
+
+    /// The most recently read active pads, newest first.
+    pub fn recent_pads(&self, scope: Scope, limit: usize) -> Result<commands::CmdResult> {
+        commands::recent::run(&self.store, scope, limit)
+    }
+
+    /// The `snippet`-tagged pad whose title best matches `query`.
+    pub fn snip(&self, scope: Scope, query: &str) -> Result<commands::snip::SnipOutcome> {
+        commands::snip::run(&self.store, scope, query)
+    }
+
+    /// What happened to the selected pads, oldest event first.
+    pub fn timeline<I: AsRef<str>>(
+        &self,
+        scope: Scope,
+        indexes: &[I],
+    ) -> Result<commands::timeline::TimelineOutcome> {
+        let selectors = parse_selectors(indexes)?;
+        commands::timeline::run(&self.store, scope, &selectors)
+    }
+
+    /// Where the selected pads live and what is recorded about them.
+    pub fn which<I: AsRef<str>>(
+        &self,
+        scope: Scope,
+        indexes: &[I],
+    ) -> Result<commands::which::WhichOutcome> {
+        let selectors = parse_selectors(indexes)?;
+        let store_dir = self.paths.scope_dir(scope)?;
+        commands::which::run(&self.store, scope, store_dir, &selectors)
+    }
+
+    /// Pad counts per lifecycle bucket.
+    pub fn stats(&self, scope: Scope) -> Result<commands::stats::PadCounts> {
+        commands::stats::run(&self.store, scope)
+    }
+
+    /// Splits a root listing into groups (see [`commands::grouping`]). Pads
+    /// are placed in the registered projects by where they were created.
+    pub fn group_pads(
+        &self,
+        scope: Scope,
+        pads: Vec<DisplayPad>,
+        by: commands::grouping::GroupBy,
+    ) -> Result<Vec<commands::grouping::PadGroup>> {
+        let projects = match by {
+            commands::grouping::GroupBy::Project => self.known_projects()?,
+            _ => Vec::new(),
+        };
+        Ok(commands::grouping::group(
+            pads,
+            by,
+            &projects,
+            self.home(scope),
+        ))
+    }
+
+    /// Keeps the pads `query` matches, with the parents of matching children
+    /// (see [`commands::query`]).
+    pub fn filter_pads(
+        &self,
+        scope: Scope,
+        pads: Vec<DisplayPad>,
+        query: &commands::query::Query,
+    ) -> Result<Vec<DisplayPad>> {
+        let projects = self.known_projects()?;
+        let context = commands::query::QueryContext {
+            now: chrono::Utc::now(),
+            projects: &projects,
+            home: self.home(scope),
+        };
+        Ok(commands::query::filter(pads, query, &context))
+    }
+
+    /// The UUIDs of the active pads `query` matches, at any depth, to act on
+    /// as selectors.
+    pub fn select_pads(&self, scope: Scope, query: &commands::query::Query) -> Result<Vec<String>> {
+        let listed = commands::get::run(&self.store, scope, Default::default(), &[])?.listed_pads;
+        let projects = self.known_projects()?;
+        let context = commands::query::QueryContext {
+            now: chrono::Utc::now(),
+            projects: &projects,
+            home: self.home(scope),
+        };
+        let mut ids: Vec<String> = Vec::new();
+        for dp in commands::query::matching(&listed, query, &context) {
+            // A pinned pad is listed twice, pinned and in place.
+            let id = dp.pad.metadata.id.to_string();
+            if !ids.contains(&id) {
+                ids.push(id);
+            }
+        }
+        Ok(ids)
+    }
+
+    /// The roots of the registered local projects.
+    pub(super) fn known_projects(&self) -> Result<Vec<std::path::PathBuf>> {
+        Ok(store::registry::load(&self.paths.global)?
+            .iter()
+            .filter(|entry| entry.remote.is_none())
+            .map(|entry| entry.project_root())
+            .collect())
+    }
+
+    /// The project that pads with no creation context belong to in `scope`.
+    pub(super) fn home(&self, scope: Scope) -> Option<&std::path::Path> {
+        match scope {
+            Scope::Project => self.paths.project.as_deref().and_then(|dir| dir.parent()),
+            Scope::Global => None,
+        }
+    }
+
+    /// What compression at rest saves in this scope (see
+    /// [`crate::store::compression`]).
+    pub fn compression_stats(
+        &self,
+        scope: Scope,
+    ) -> Result<crate::store::compression::CompressionStats> {
+        let dir = self.paths.scope_dir(scope)?;
+        Ok(crate::store::compression::usage(&dir)?)
+    }
+
+    /// Counts one invocation of `command`, passed `options` (long names, no
+    /// values), in the global usage log.
+    ///
+    /// The caller decides whether counting is enabled; this always records.
+    pub fn record_usage(&self, command: &str, options: &[String]) -> Result<()> {
+        let path = usage::usage_path(&self.paths.global);
+        let mut log = usage::UsageLog::load(&path)?;
+        log.record(command, options);
+        log.save(&path)
+    }
+
+    /// The usage tally, or [`usage::UsageReport::Disabled`] when counting is off.
+    pub fn usage_report(&self, enabled: bool) -> Result<usage::UsageReport> {
+        if !enabled {
+            return Ok(usage::UsageReport::Disabled);
+        }
+        let log = usage::UsageLog::load(&usage::usage_path(&self.paths.global))?;
+        Ok(usage::UsageReport::from_log(log))
+    }
+
     /// Hard-deletes a pad (file + metadata). Used for cleanup of aborted creates.
     pub fn remove_pad(&mut self, scope: Scope, id: uuid::Uuid) -> Result<()> {
         use crate::store::Bucket;
@@ -71,10 +330,22 @@ impl<S: DataStore> PadzApi<S> {
     }
 }
 
+/// `pad` as listed at the end of its display `path`.
+fn editor_display_pad(pad: Pad, path: &[DisplayIndex]) -> DisplayPad {
+    DisplayPad {
+        pad,
+        index: path.last().cloned().unwrap_or(DisplayIndex::Regular(1)),
+        matches: None,
+        children: Vec::new(),
+    }
+}
+
 #[cfg(test)]
 mod tests {
     use crate::api::test_support::make_api;
     use crate::api::PadFilter;
+    use crate::commands::{CmdOutcome, UpdateKind};
+    use crate::index::DisplayIndex;
     use crate::model::Scope;
     use crate::store::backend::StorageBackend;
     use std::path::PathBuf;
@@ -89,7 +360,8 @@ mod tests {
             result,
             crate::commands::doctor::DoctorOutcome::Clean {
                 missing_files: 0,
-                recovered_files: 0
+                recovered_files: 0,
+                remapped_paths: 0
             }
         );
     }
@@ -148,6 +420,89 @@ mod tests {
         assert_eq!(pad.content, "New Title\n\nNew body");
     }
 
+    #[test]
+    fn test_api_finish_editor_create_reports_the_child_at_its_index() {
+        let mut api = make_api();
+        api.create_pad(Scope::Project, "Parent".into(), "".into(), None)
+            .unwrap();
+        api.create_pad(Scope::Project, "Sibling".into(), "".into(), Some("1"))
+            .unwrap();
+        let child_id = api
+            .create_pad(Scope::Project, "".into(), "".into(), Some("1"))
+            .unwrap()
+            .affected_pads[0]
+            .pad
+            .metadata
+            .id;
+        api.store
+            .active
+            .backend
+            .write_content(&child_id, Scope::Project, "Written in the editor")
+            .unwrap();
+
+        let result = api.finish_editor_create(Scope::Project, child_id).unwrap();
+
+        assert_eq!(result.affected_pads.len(), 1);
+        let child = &result.affected_pads[0];
+        assert_eq!(child.pad.metadata.title, "Written in the editor");
+        assert_eq!(
+            api.display_path_by_id(Scope::Project, child_id).unwrap(),
+            vec![DisplayIndex::Regular(1), child.index.clone()]
+        );
+        assert!(result.outcomes.is_empty());
+    }
+
+    #[test]
+    fn test_api_finish_editor_edit_reports_the_refresh() {
+        let mut api = make_api();
+        let pad_id = api
+            .create_pad(Scope::Project, "Before".into(), "Body".into(), None)
+            .unwrap()
+            .affected_pads[0]
+            .pad
+            .metadata
+            .id;
+        api.store
+            .active
+            .backend
+            .write_content(&pad_id, Scope::Project, "After\n\nBody")
+            .unwrap();
+
+        let result = api.finish_editor_edit(Scope::Project, pad_id).unwrap();
+
+        assert_eq!(result.affected_pads[0].index, DisplayIndex::Regular(1));
+        assert_eq!(
+            result.outcomes,
+            vec![CmdOutcome::Updated {
+                path: vec![DisplayIndex::Regular(1)],
+                title: "After".into(),
+                update_kind: UpdateKind::Refresh,
+            }]
+        );
+    }
+
+    #[test]
+    fn test_api_finish_editor_edit_of_an_emptied_pad_affects_nothing() {
+        let mut api = make_api();
+        let pad_id = api
+            .create_pad(Scope::Project, "Doomed".into(), "Body".into(), None)
+            .unwrap()
+            .affected_pads[0]
+            .pad
+            .metadata
+            .id;
+        api.store
+            .active
+            .backend
+            .write_content(&pad_id, Scope::Project, "  \n")
+            .unwrap();
+
+        let result = api.finish_editor_edit(Scope::Project, pad_id).unwrap();
+
+        assert!(result.affected_pads.is_empty());
+        assert!(result.outcomes.is_empty());
+    }
+
     #[test]
     fn test_api_refresh_pad_empty_deletes() {
         let mut api = make_api();
@@ -171,6 +526,35 @@ mod tests {
         assert_eq!(list.listed_pads.len(), 0);
     }
 
+    #[test]
+    fn test_api_record_access_feeds_recent_pads() {
+        let mut api = make_api();
+        let id = api
+            .create_pad(Scope::Project, "Seen".into(), "".into(), None)
+            .unwrap()
+            .affected_pads[0]
+            .pad
+            .metadata
+            .id;
+        api.create_pad(Scope::Project, "Unseen".into(), "".into(), None)
+            .unwrap();
+
+        api.record_access(Scope::Project, &[id]).unwrap();
+
+        let recent = api.recent_pads(Scope::Project, 10).unwrap();
+        assert_eq!(recent.listed_pads.len(), 1);
+        assert_eq!(recent.listed_pads[0].pad.metadata.title, "Seen");
+    }
+
+    #[test]
+    fn test_api_usage_report_disabled_reads_nothing() {
+        let api = make_api();
+
+        let report = api.usage_report(false).unwrap();
+
+        assert_eq!(report, crate::usage::UsageReport::Disabled);
+    }
+
     #[test]
     fn test_api_remove_pad() {
         let mut api = make_api();
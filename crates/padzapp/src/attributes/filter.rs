@@ -18,6 +18,8 @@ pub enum FilterOp {
     Contains,
     /// List contains ALL specified values (for List attributes, AND logic).
     ContainsAll,
+    /// List contains NONE of the specified values (for List attributes).
+    Excludes,
 }
 
 /// A filter condition on an attribute.
@@ -63,6 +65,11 @@ impl AttrFilter {
         Self::new(attr, FilterOp::ContainsAll, AttrValue::List(values))
     }
 
+    /// Convenience: create an excludes filter for lists.
+    pub fn excludes(attr: impl Into<String>, value: String) -> Self {
+        Self::new(attr, FilterOp::Excludes, AttrValue::List(vec![value]))
+    }
+
     /// Check if this filter matches the given metadata.
     ///
     /// Returns `true` if the metadata's attribute value satisfies the filter condition.
@@ -77,6 +84,10 @@ impl AttrFilter {
             FilterOp::Ne => !self.values_equal(&attr_value, &self.value),
             FilterOp::Contains => self.list_contains(&attr_value, &self.value),
             FilterOp::ContainsAll => self.list_contains_all(&attr_value, &self.value),
+            FilterOp::Excludes => {
+                matches!(attr_value, AttrValue::List(_))
+                    && !self.list_contains(&attr_value, &self.value)
+            }
         }
     }
 
@@ -210,6 +221,15 @@ mod tests {
         assert!(!filter.matches(&meta_with_tags(vec![])));
     }
 
+    #[test]
+    fn filter_excludes_tag() {
+        let filter = AttrFilter::excludes("tags", "archive".into());
+
+        assert!(filter.matches(&meta_with_tags(vec!["rust"])));
+        assert!(filter.matches(&meta_with_tags(vec![])));
+        assert!(!filter.matches(&meta_with_tags(vec!["rust", "archive"])));
+    }
+
     #[test]
     fn filter_unknown_attr_returns_false() {
         let filter = AttrFilter::eq("unknown", AttrValue::Bool(true));
@@ -0,0 +1,82 @@
+//! # Cancellation
+//!
+//! Operations that walk every pad in a store can be asked to stop partway
+//! through: the user hits Ctrl-C during a large import. Stopping between two
+//! writes must not leave the store half-updated, so these operations check a
+//! shared [`Cancellation`] before each item and, once it is set, return
+//! [`PadzError::Interrupted`] instead of going on.
+//!
+//! Returning an error is what makes the stop clean. The API runs writing
+//! operations inside a store transaction ([`crate::store::transaction`]),
+//! which drops every write held so far when its body fails, and a streamed
+//! export keeps its manifest so `--resume` picks up where it stopped.
+//!
+//! The library never installs signal handlers; the client decides what
+//! cancels an operation and calls [`Cancellation::cancel`]. The API holds one
+//! token for every operation it runs ([`crate::api::PadzApi::set_cancellation`]);
+//! the default one is never cancelled.
+
+use crate::error::{PadzError, Result};
+use std::sync::atomic::{AtomicBool, Ordering};
+use std::sync::Arc;
+
+/// A flag shared between whoever cancels an operation and the operation.
+///
+/// Clones share the flag, and setting it is async-signal-safe (a single
+/// atomic store), so a clone can be handed to a signal handler.
+#[derive(Debug, Clone, Default)]
+pub struct Cancellation(Arc<AtomicBool>);
+
+impl Cancellation {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// A token driven by an existing flag, such as one a signal handler sets.
+    pub fn from_flag(flag: Arc<AtomicBool>) -> Self {
+        Self(flag)
+    }
+
+    /// Ask every operation checking this token to stop.
+    pub fn cancel(&self) {
+        self.0.store(true, Ordering::SeqCst);
+    }
+
+    pub fn is_cancelled(&self) -> bool {
+        self.0.load(Ordering::SeqCst)
+    }
+
+    /// `Err(PadzError::Interrupted)` once cancelled; operations call this
+    /// before each item and propagate it with `?`.
+    pub fn check(&self) -> Result<()> {
+        if self.is_cancelled() {
+            Err(PadzError::Interrupted)
+        } else {
+            Ok(())
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn clones_share_the_flag() {
+        let cancel = Cancellation::new();
+        let handed_out = cancel.clone();
+        assert!(cancel.check().is_ok());
+
+        handed_out.cancel();
+        assert!(cancel.is_cancelled());
+        assert!(matches!(cancel.check(), Err(PadzError::Interrupted)));
+    }
+
+    #[test]
+    fn from_flag_follows_the_flag() {
+        let flag = Arc::new(AtomicBool::new(false));
+        let cancel = Cancellation::from_flag(flag.clone());
+        flag.store(true, Ordering::SeqCst);
+        assert!(cancel.is_cancelled());
+    }
+}
@@ -0,0 +1,169 @@
+//! Code anchors: the files and lines a pad is about.
+//!
+//! `padz link 3 src/server.go:42` records the location on the pad, so a
+//! debugging note stays tied to the code it describes. Anchors are plain data
+//! here: the client decides what a path is relative to, whether the file must
+//! exist, and how to open it — padz only keeps the list.
+
+use crate::commands::helpers::{indexed_pads, resolve_selectors, TitleBucket};
+use crate::commands::CmdResult;
+use crate::error::{PadzError, Result};
+use crate::index::{DisplayPad, PadSelector};
+use crate::model::{CodeAnchor, Scope};
+use crate::store::{Bucket, DataStore};
+
+/// Adds `anchors` to each selected pad, skipping any it already has.
+pub fn add<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    anchors: &[CodeAnchor],
+) -> Result<CmdResult> {
+    change(store, scope, selectors, anchors, |existing, anchor| {
+        if !existing.contains(anchor) {
+            existing.push(anchor.clone());
+        }
+    })
+}
+
+/// Removes `anchors` from each selected pad; ones it lacks are ignored.
+pub fn remove<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    anchors: &[CodeAnchor],
+) -> Result<CmdResult> {
+    change(store, scope, selectors, anchors, |existing, anchor| {
+        existing.retain(|a| a != anchor)
+    })
+}
+
+fn change<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    anchors: &[CodeAnchor],
+    apply: impl Fn(&mut Vec<CodeAnchor>, &CodeAnchor),
+) -> Result<CmdResult> {
+    if anchors.is_empty() {
+        return Err(PadzError::Api("No code locations specified".to_string()));
+    }
+
+    let resolved = resolve_selectors(store, scope, selectors, false, TitleBucket::Active)?;
+    let mut affected = Vec::with_capacity(resolved.len());
+    for (_, uuid) in resolved {
+        let mut pad = store.get_pad(&uuid, scope, Bucket::Active)?;
+        let before = pad.metadata.anchors.clone();
+        for anchor in anchors {
+            apply(&mut pad.metadata.anchors, anchor);
+        }
+        if pad.metadata.anchors != before {
+            store.save_pad(&pad, scope, Bucket::Active)?;
+        }
+        affected.push(uuid);
+    }
+
+    let indexed = indexed_pads(store, scope)?;
+    let mut result = CmdResult::default();
+    for uuid in affected {
+        if let Some(dp) = super::helpers::find_pad_by_uuid(&indexed, uuid, |_| true) {
+            result.affected_pads.push(DisplayPad {
+                pad: dp.pad.clone(),
+                index: dp.index.clone(),
+                matches: None,
+                children: Vec::new(),
+            });
+        }
+    }
+    Ok(result)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::create;
+    use crate::index::DisplayIndex;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn store_with_one_pad() -> BucketedStore<MemBackend> {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        create::run(
+            &mut store,
+            Scope::Project,
+            "Flaky TLS".into(),
+            "".into(),
+            None,
+        )
+        .unwrap();
+        store
+    }
+
+    fn first() -> Vec<PadSelector> {
+        vec![PadSelector::Path(vec![DisplayIndex::Regular(1)])]
+    }
+
+    fn anchors(specs: &[&str]) -> Vec<CodeAnchor> {
+        specs.iter().map(|s| s.parse().unwrap()).collect()
+    }
+
+    #[test]
+    fn test_add_keeps_order_and_skips_duplicates() {
+        let mut store = store_with_one_pad();
+        add(
+            &mut store,
+            Scope::Project,
+            &first(),
+            &anchors(&["src/tls.go:42", "README.md"]),
+        )
+        .unwrap();
+        let result = add(
+            &mut store,
+            Scope::Project,
+            &first(),
+            &anchors(&["src/tls.go:42", "src/tls.go:57"]),
+        )
+        .unwrap();
+
+        let stored = &result.affected_pads[0].pad.metadata.anchors;
+        assert_eq!(
+            stored.iter().map(ToString::to_string).collect::<Vec<_>>(),
+            vec!["src/tls.go:42", "README.md", "src/tls.go:57"]
+        );
+    }
+
+    #[test]
+    fn test_remove_drops_only_the_named_anchors() {
+        let mut store = store_with_one_pad();
+        add(
+            &mut store,
+            Scope::Project,
+            &first(),
+            &anchors(&["a.rs:1", "a.rs:2"]),
+        )
+        .unwrap();
+
+        let result = remove(
+            &mut store,
+            Scope::Project,
+            &first(),
+            &anchors(&["a.rs:1", "never-added.rs"]),
+        )
+        .unwrap();
+        assert_eq!(
+            result.affected_pads[0].pad.metadata.anchors,
+            anchors(&["a.rs:2"])
+        );
+    }
+
+    #[test]
+    fn test_anchors_are_required() {
+        let mut store = store_with_one_pad();
+        assert!(add(&mut store, Scope::Project, &first(), &[]).is_err());
+    }
+}
@@ -1,8 +1,9 @@
 use crate::commands::CmdResult;
 use crate::error::Result;
 use crate::index::{DisplayIndex, DisplayPad};
-use crate::model::{Pad, Scope};
+use crate::model::{CreationContext, Pad, Scope};
 use crate::store::{Bucket, DataStore};
+use uuid::Uuid;
 
 pub fn run<S: DataStore>(
     store: &mut S,
@@ -10,8 +11,24 @@ pub fn run<S: DataStore>(
     title: String,
     content: String,
     parent_selector: Option<crate::index::PadSelector>,
+) -> Result<CmdResult> {
+    run_with_context(store, scope, title, content, parent_selector, None, None)
+}
+
+/// [`run`], stamping the new pad with the working-tree state it was created in
+/// and the identity of whoever created it.
+pub fn run_with_context<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    title: String,
+    content: String,
+    parent_selector: Option<crate::index::PadSelector>,
+    context: Option<CreationContext>,
+    owner: Option<String>,
 ) -> Result<CmdResult> {
     let mut pad = Pad::new(title, content);
+    pad.metadata.context = context;
+    pad.metadata.owner = owner;
 
     if let Some(selector) = parent_selector {
         // Resolve parent
@@ -67,6 +84,18 @@ pub fn run<S: DataStore>(
     Ok(result)
 }
 
+/// The active pad titled exactly `title`, anywhere in the scope: what a new
+/// pad with that title would duplicate. When several share it, the oldest
+/// wins, as [`super::journal::find_by_title`] picks.
+pub fn titled<S: DataStore>(store: &S, scope: Scope, title: &str) -> Result<Option<Uuid>> {
+    Ok(store
+        .list_metadata(scope, Bucket::Active)?
+        .into_iter()
+        .filter(|meta| meta.title == title)
+        .min_by_key(|meta| (meta.created_at, meta.id))
+        .map(|meta| meta.id))
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -75,6 +104,37 @@ mod tests {
     use crate::store::bucketed::BucketedStore;
     use crate::store::mem_backend::MemBackend;
 
+    #[test]
+    fn records_the_creation_context_when_given() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        let context = CreationContext {
+            cwd: "/work/repo/src".to_string(),
+            commit: "0123456789abcdef0123456789abcdef01234567".to_string(),
+            branch: Some("main".to_string()),
+            dirty: true,
+        };
+        let result = run_with_context(
+            &mut store,
+            Scope::Project,
+            "Why does TLS fail".into(),
+            "".into(),
+            None,
+            Some(context.clone()),
+            Some("ana".into()),
+        )
+        .unwrap();
+
+        let id = result.affected_pads[0].pad.metadata.id;
+        let stored = store.get_pad(&id, Scope::Project, Bucket::Active).unwrap();
+        assert_eq!(stored.metadata.context, Some(context));
+        assert_eq!(stored.metadata.owner.as_deref(), Some("ana"));
+    }
+
     #[test]
     fn creates_nested_pad() {
         let mut store = BucketedStore::new(
@@ -105,6 +165,41 @@ mod tests {
         assert_eq!(child.metadata.parent_id, Some(parent.metadata.id));
     }
 
+    #[test]
+    fn a_title_is_found_nested_or_not_but_only_exactly() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        run(
+            &mut store,
+            Scope::Project,
+            "Groceries".into(),
+            "".into(),
+            None,
+        )
+        .unwrap();
+        let parent_sel = PadSelector::Path(vec![DisplayIndex::Regular(1)]);
+        let child = run(
+            &mut store,
+            Scope::Project,
+            "Milk".into(),
+            "".into(),
+            Some(parent_sel),
+        )
+        .unwrap()
+        .affected_pads[0]
+            .pad
+            .metadata
+            .id;
+
+        assert_eq!(titled(&store, Scope::Project, "Milk").unwrap(), Some(child));
+        assert_eq!(titled(&store, Scope::Project, "milk").unwrap(), None);
+        assert_eq!(titled(&store, Scope::Project, "Bread").unwrap(), None);
+    }
+
     #[test]
     fn parent_not_found_returns_error() {
         let mut store = BucketedStore::new(
@@ -1,12 +1,13 @@
 use crate::error::Result;
 use crate::model::Scope;
-use crate::store::DataStore;
+use crate::store::location::Relocation;
+use crate::store::{Bucket, DataStore};
 use serde::Serialize;
 
 /// Semantic result of reconciling a store's index and content files.
 ///
 /// Serializes directly as a CLI/structured payload (the presentation tier that
-/// once mirrored this was removed): the `status` tag plus the two counts are the
+/// once mirrored this was removed): the `status` tag plus the counts are the
 /// facts a client renders or inspects without parsing English.
 #[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
 #[serde(tag = "status", rename_all = "snake_case")]
@@ -14,28 +15,66 @@ pub enum DoctorOutcome {
     Clean {
         missing_files: usize,
         recovered_files: usize,
+        remapped_paths: usize,
     },
     Repaired {
         missing_files: usize,
         recovered_files: usize,
+        remapped_paths: usize,
     },
 }
 
-pub fn run<S: DataStore>(store: &mut S, scope: Scope) -> Result<DoctorOutcome> {
+/// Reconciles the store and, when the project moved (`relocation`), points the
+/// paths recorded on its pads at the new location.
+pub fn run<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    relocation: Option<&Relocation>,
+) -> Result<DoctorOutcome> {
     let report = store.doctor(scope)?;
-    if report.fixed_missing_files == 0 && report.recovered_files == 0 {
+    let remapped_paths = match relocation {
+        Some(relocation) => remap_paths(store, scope, relocation)?,
+        None => 0,
+    };
+    if report.fixed_missing_files == 0 && report.recovered_files == 0 && remapped_paths == 0 {
         Ok(DoctorOutcome::Clean {
             missing_files: 0,
             recovered_files: 0,
+            remapped_paths: 0,
         })
     } else {
         Ok(DoctorOutcome::Repaired {
             missing_files: report.fixed_missing_files,
             recovered_files: report.recovered_files,
+            remapped_paths,
         })
     }
 }
 
+/// Rewrites each pad's creation directory that lay inside the moved project;
+/// returns how many pads changed.
+fn remap_paths<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    relocation: &Relocation,
+) -> Result<usize> {
+    let mut remapped = 0;
+    for bucket in [Bucket::Active, Bucket::Archived, Bucket::Deleted] {
+        for mut metadata in store.list_metadata(scope, bucket)? {
+            let Some(context) = metadata.context.as_mut() else {
+                continue;
+            };
+            let Some(cwd) = relocation.remap(std::path::Path::new(&context.cwd)) else {
+                continue;
+            };
+            context.cwd = cwd.to_string_lossy().into_owned();
+            store.save_metadata(&metadata, scope, bucket)?;
+            remapped += 1;
+        }
+    }
+    Ok(remapped)
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -66,13 +105,14 @@ mod tests {
             MemBackend::new(),
         );
 
-        let result = run(&mut store, Scope::Project).unwrap();
+        let result = run(&mut store, Scope::Project, None).unwrap();
 
         assert_eq!(
             result,
             DoctorOutcome::Clean {
                 missing_files: 0,
-                recovered_files: 0
+                recovered_files: 0,
+                remapped_paths: 0
             }
         );
     }
@@ -88,13 +128,14 @@ mod tests {
             .unwrap();
 
         let mut store = bucketed_with_active(backend);
-        let result = run(&mut store, Scope::Project).unwrap();
+        let result = run(&mut store, Scope::Project, None).unwrap();
 
         assert_eq!(
             result,
             DoctorOutcome::Repaired {
                 missing_files: 0,
-                recovered_files: 1
+                recovered_files: 1,
+                remapped_paths: 0
             }
         );
     }
@@ -119,18 +160,28 @@ mod tests {
                 title: "Zombie".to_string(),
                 status: crate::model::TodoStatus::Planned,
                 tags: Vec::new(),
+                last_accessed_at: None,
+                checksum: None,
+                alias: None,
+                anchors: Vec::new(),
+                context: None,
+                owner: None,
+                source: None,
+                published: Vec::new(),
+                history: Vec::new(),
             },
         );
         backend.save_index(Scope::Project, &index).unwrap();
 
         let mut store = bucketed_with_active(backend);
-        let result = run(&mut store, Scope::Project).unwrap();
+        let result = run(&mut store, Scope::Project, None).unwrap();
 
         assert_eq!(
             result,
             DoctorOutcome::Repaired {
                 missing_files: 1,
-                recovered_files: 0
+                recovered_files: 0,
+                remapped_paths: 0
             }
         );
     }
@@ -161,19 +212,88 @@ mod tests {
                 title: "Zombie".to_string(),
                 status: crate::model::TodoStatus::Planned,
                 tags: Vec::new(),
+                last_accessed_at: None,
+                checksum: None,
+                alias: None,
+                anchors: Vec::new(),
+                context: None,
+                owner: None,
+                source: None,
+                published: Vec::new(),
+                history: Vec::new(),
             },
         );
         backend.save_index(Scope::Project, &index).unwrap();
 
         let mut store = bucketed_with_active(backend);
-        let result = run(&mut store, Scope::Project).unwrap();
+        let result = run(&mut store, Scope::Project, None).unwrap();
 
         assert_eq!(
             result,
             DoctorOutcome::Repaired {
                 missing_files: 1,
-                recovered_files: 1
+                recovered_files: 1,
+                remapped_paths: 0
+            }
+        );
+    }
+
+    #[test]
+    fn doctor_remaps_paths_after_a_move() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        for (title, cwd) in [("inside", "/code/app/src"), ("outside", "/elsewhere")] {
+            crate::commands::create::run(
+                &mut store,
+                Scope::Project,
+                title.into(),
+                "body".into(),
+                None,
+            )
+            .unwrap();
+            let pad = store
+                .list_pads(Scope::Project, Bucket::Active)
+                .unwrap()
+                .into_iter()
+                .find(|p| p.metadata.title == title)
+                .unwrap();
+            let mut metadata = pad.metadata;
+            metadata.context = Some(crate::model::CreationContext {
+                cwd: cwd.into(),
+                commit: "abc123".into(),
+                branch: None,
+                dirty: false,
+            });
+            store
+                .save_metadata(&metadata, Scope::Project, Bucket::Active)
+                .unwrap();
+        }
+
+        let relocation = Relocation {
+            from: "/code/app".into(),
+            to: "/work/app".into(),
+        };
+        let result = run(&mut store, Scope::Project, Some(&relocation)).unwrap();
+
+        assert_eq!(
+            result,
+            DoctorOutcome::Repaired {
+                missing_files: 0,
+                recovered_files: 0,
+                remapped_paths: 1
             }
         );
+        let cwds: Vec<String> = store
+            .list_pads(Scope::Project, Bucket::Active)
+            .unwrap()
+            .into_iter()
+            .map(|p| p.metadata.context.unwrap().cwd)
+            .collect();
+        assert!(cwds.contains(&"/work/app/src".to_string()));
+        assert!(cwds.contains(&"/elsewhere".to_string()));
     }
 }
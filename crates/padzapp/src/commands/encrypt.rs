@@ -0,0 +1,93 @@
+//! Encryption at rest (`padz encrypt enable|disable`).
+//!
+//! Switches one scope's store between encrypted and plain bodies (see
+//! [`crate::store::encryption`]) and rewrites every body, in every bucket, in
+//! the new form. The mode is switched before the bodies are rewritten, so an
+//! interrupted run leaves a store whose every pad still reads, and running the
+//! command again finishes the job.
+
+use crate::error::Result;
+use crate::model::Scope;
+use crate::store::encryption;
+use crate::store::fs::FileStore;
+use serde::Serialize;
+use std::path::Path;
+
+/// Semantic result of `encrypt enable|disable`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(tag = "status", rename_all = "snake_case")]
+pub enum EncryptOutcome {
+    /// The scope was in that mode already, every body in its form.
+    Unchanged { enabled: bool },
+    /// The mode was switched, or finished, and `rewritten` bodies were
+    /// rewritten; a dry run only counts them.
+    Switched { enabled: bool, rewritten: usize },
+}
+
+/// Turns encryption on or off for `scope`, whose store is at `scope_root`.
+pub fn run(
+    store: &mut FileStore,
+    scope: Scope,
+    scope_root: &Path,
+    enabled: bool,
+    dry_run: bool,
+) -> Result<EncryptOutcome> {
+    let was_enabled = encryption::is_enabled(scope_root);
+    if !dry_run {
+        encryption::set_enabled(scope_root, enabled)?;
+    }
+    store.set_encrypted(scope, enabled);
+    let rewritten = store.rewrite_bodies(scope, dry_run)?;
+    if was_enabled == enabled && rewritten == 0 {
+        return Ok(EncryptOutcome::Unchanged { enabled });
+    }
+    Ok(EncryptOutcome::Switched { enabled, rewritten })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::model::Pad;
+    use crate::store::{Bucket, DataStore};
+    use tempfile::TempDir;
+
+    #[test]
+    fn enabling_twice_is_unchanged_the_second_time() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path().join("project");
+        let mut store = FileStore::new_fs(Some(root.clone()), temp.path().join("global"))
+            .with_encryption(temp.path().join("secret.key"));
+        for (title, bucket) in [("Live", Bucket::Active), ("Old", Bucket::Archived)] {
+            let pad = Pad::new(title.into(), "body".into());
+            store.save_pad(&pad, Scope::Project, bucket).unwrap();
+        }
+
+        let dry = run(&mut store, Scope::Project, &root, true, true).unwrap();
+        assert_eq!(
+            dry,
+            EncryptOutcome::Switched {
+                enabled: true,
+                rewritten: 2
+            }
+        );
+        assert!(!encryption::is_enabled(&root));
+
+        let outcome = run(&mut store, Scope::Project, &root, true, false).unwrap();
+        assert_eq!(dry, outcome);
+        assert!(encryption::is_enabled(&root));
+        assert_eq!(
+            run(&mut store, Scope::Project, &root, true, false).unwrap(),
+            EncryptOutcome::Unchanged { enabled: true }
+        );
+
+        let off = run(&mut store, Scope::Project, &root, false, false).unwrap();
+        assert_eq!(
+            off,
+            EncryptOutcome::Switched {
+                enabled: false,
+                rewritten: 2
+            }
+        );
+        assert!(!encryption::is_enabled(&root));
+    }
+}
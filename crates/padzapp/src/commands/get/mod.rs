@@ -9,8 +9,10 @@ mod attr_filter;
 mod search;
 mod selector_filter;
 mod status_filter;
+mod stream;
 
 pub use status_filter::PadStatusFilter;
+pub use stream::{stream, StreamedPad};
 
 #[derive(Debug, Clone)]
 pub struct PadFilter {
@@ -41,7 +43,18 @@ pub fn run<S: DataStore>(
     selectors: &[PadSelector],
 ) -> Result<CmdResult> {
     let indexed = super::helpers::indexed_pads(store, scope)?;
+    let trash = super::stats::TrashSize::of(&indexed);
+    let mut result = CmdResult::default().with_listed_pads(select(indexed, filter, selectors)?);
+    result.trash = Some(trash);
+    Ok(result)
+}
 
+/// The pads of an indexed tree that `filter` and `selectors` keep.
+fn select(
+    indexed: Vec<DisplayPad>,
+    filter: PadFilter,
+    selectors: &[PadSelector],
+) -> Result<Vec<DisplayPad>> {
     // 0. Filter by ID selectors (if any)
     let indexed = if selectors.is_empty() {
         indexed
@@ -74,7 +87,7 @@ pub fn run<S: DataStore>(
         filtered = search::apply_search(filtered, term);
     }
 
-    Ok(CmdResult::default().with_listed_pads(filtered))
+    Ok(filtered)
 }
 
 #[cfg(test)]
@@ -1,143 +1,27 @@
-use crate::error::{PadzError, Result};
-use crate::index::{DisplayIndex, DisplayPad, PadSelector};
+use crate::commands::helpers::{linearize_tree, selector_matches, TitleBucket};
+use crate::error::Result;
+use crate::index::{DisplayPad, PadSelector};
 
 /// Filters the tree to only include pads that match the given selectors.
 /// Each matched pad is returned with its full subtree of children.
+///
+/// Matching is the resolver's own ([`selector_matches`]), across every
+/// bucket: `get` narrows by status afterwards. Unlike resolution, a title
+/// that matches several pads selects them all.
 pub(super) fn filter_by_selectors(
     pads: Vec<DisplayPad>,
     selectors: &[PadSelector],
 ) -> Result<Vec<DisplayPad>> {
-    let linearized = linearize_for_filter(&pads);
-    let mut matched = Vec::new();
+    let linearized = linearize_tree(&pads);
+    let mut matched: Vec<DisplayPad> = Vec::new();
 
     for selector in selectors {
-        match selector {
-            PadSelector::Path(path) => {
-                if let Some(dp) = find_by_path(&linearized, path) {
-                    if !matched
-                        .iter()
-                        .any(|m: &DisplayPad| m.pad.metadata.id == dp.pad.metadata.id)
-                    {
-                        matched.push(dp.clone());
-                    }
-                } else {
-                    let s: Vec<String> = path.iter().map(|idx| idx.to_string()).collect();
-                    return Err(PadzError::Api(format!(
-                        "Index {} not found in current scope",
-                        s.join(".")
-                    )));
-                }
-            }
-            PadSelector::Range(start_path, end_path) => {
-                let start_idx = linearized
-                    .iter()
-                    .position(|(p, _)| p == start_path)
-                    .ok_or_else(|| {
-                        let s: Vec<String> = start_path.iter().map(|idx| idx.to_string()).collect();
-                        PadzError::Api(format!("Range start {} not found", s.join(".")))
-                    })?;
-                let end_idx = linearized
-                    .iter()
-                    .position(|(p, _)| p == end_path)
-                    .ok_or_else(|| {
-                        let s: Vec<String> = end_path.iter().map(|idx| idx.to_string()).collect();
-                        PadzError::Api(format!("Range end {} not found", s.join(".")))
-                    })?;
-
-                if start_idx > end_idx {
-                    return Err(PadzError::Api(
-                        "Invalid range: start appears after end".into(),
-                    ));
-                }
-
-                for (_, dp) in linearized.iter().take(end_idx + 1).skip(start_idx) {
-                    if !matched
-                        .iter()
-                        .any(|m: &DisplayPad| m.pad.metadata.id == dp.pad.metadata.id)
-                    {
-                        matched.push((*dp).clone());
-                    }
-                }
-            }
-            PadSelector::Uuid(uuid) => {
-                let found = linearized
-                    .iter()
-                    .find(|(_, dp)| dp.pad.metadata.id == *uuid);
-
-                match found {
-                    Some((_, dp)) => {
-                        if !matched
-                            .iter()
-                            .any(|m: &DisplayPad| m.pad.metadata.id == dp.pad.metadata.id)
-                        {
-                            matched.push((*dp).clone());
-                        }
-                    }
-                    None => {
-                        return Err(PadzError::Api(format!("No pad found with UUID {}", uuid)));
-                    }
-                }
-            }
-            PadSelector::ShortUuid(hex) => {
-                let matches: Vec<&&DisplayPad> = linearized
-                    .iter()
-                    .filter(|(_, dp)| {
-                        dp.pad
-                            .metadata
-                            .id
-                            .to_string()
-                            .replace('-', "")
-                            .starts_with(hex.as_str())
-                    })
-                    .map(|(_, dp)| dp)
-                    .collect();
-
-                match matches.len() {
-                    0 => {
-                        return Err(PadzError::Api(format!(
-                            "No pad found with UUID prefix {}",
-                            hex
-                        )));
-                    }
-                    1 => {
-                        if !matched
-                            .iter()
-                            .any(|m: &DisplayPad| m.pad.metadata.id == matches[0].pad.metadata.id)
-                        {
-                            matched.push((*matches[0]).clone());
-                        }
-                    }
-                    n => {
-                        return Err(PadzError::Api(format!(
-                            "UUID prefix \"{}\" matches {} pads. Use more characters to be unique.",
-                            hex, n
-                        )));
-                    }
-                }
-            }
-            PadSelector::Title(term) => {
-                let term_lower = term.to_lowercase();
-                let matches: Vec<&DisplayPad> = linearized
-                    .iter()
-                    .filter(|(_, dp)| dp.pad.metadata.title.to_lowercase().contains(&term_lower))
-                    .map(|(_, dp)| *dp)
-                    .collect();
-
-                if matches.is_empty() {
-                    return Err(PadzError::Api(format!(
-                        "No pad found matching \"{}\"",
-                        term
-                    )));
-                }
-
-                for dp in matches {
-                    if !matched
-                        .iter()
-                        .any(|m: &DisplayPad| m.pad.metadata.id == dp.pad.metadata.id)
-                    {
-                        matched.push(dp.clone());
-                    }
-                }
+        for (_, dp) in selector_matches(&linearized, selector, TitleBucket::Any)? {
+            if !matched
+                .iter()
+                .any(|m| m.pad.metadata.id == dp.pad.metadata.id)
+            {
+                matched.push((*dp).clone());
             }
         }
     }
@@ -145,40 +29,6 @@ pub(super) fn filter_by_selectors(
     Ok(matched)
 }
 
-/// Linearize the tree into (path, &DisplayPad) pairs for selector resolution.
-fn linearize_for_filter(roots: &[DisplayPad]) -> Vec<(Vec<DisplayIndex>, &DisplayPad)> {
-    let mut result = Vec::new();
-    for pad in roots {
-        linearize_recursive(pad, Vec::new(), &mut result);
-    }
-    result
-}
-
-fn linearize_recursive<'a>(
-    pad: &'a DisplayPad,
-    parent_path: Vec<DisplayIndex>,
-    result: &mut Vec<(Vec<DisplayIndex>, &'a DisplayPad)>,
-) {
-    let mut current_path = parent_path;
-    current_path.push(pad.index.clone());
-
-    result.push((current_path.clone(), pad));
-
-    for child in &pad.children {
-        linearize_recursive(child, current_path.clone(), result);
-    }
-}
-
-fn find_by_path<'a>(
-    linearized: &[(Vec<DisplayIndex>, &'a DisplayPad)],
-    path: &[DisplayIndex],
-) -> Option<&'a DisplayPad> {
-    linearized
-        .iter()
-        .find(|(p, _)| p == path)
-        .map(|(_, dp)| *dp)
-}
-
 #[cfg(test)]
 mod tests {
     use crate::commands::create;
@@ -599,7 +449,7 @@ mod tests {
 
         let err = res.unwrap_err().to_string();
         assert!(
-            err.contains("Invalid range") && err.contains("start appears after end"),
+            err.contains("Invalid range: 3 appears after 1"),
             "got: {err}"
         );
     }
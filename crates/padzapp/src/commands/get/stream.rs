@@ -0,0 +1,189 @@
+//! A listing read one pad at a time (`list --jsonl`).
+//!
+//! Most of a large listing's time goes into reading bodies. A streamed
+//! listing indexes and filters the pads from their metadata alone (the bucket
+//! indexes, see [`DataStore::list_metadata`]), then reads each body only when
+//! its pad's turn comes, so the first pad reaches the reader long before the
+//! last body is read. A search needs every body up front, so a streamed
+//! listing takes no search term.
+//!
+//! Each pad is streamed once, at the first place the listing shows it: a
+//! pinned pad under its pinned index, not again in place.
+
+use super::{select, PadFilter};
+use crate::cancel::Cancellation;
+use crate::error::{PadzError, Result};
+use crate::index::{current_ordering_key, index_pads, DisplayPad, PadSelector};
+use crate::model::{Metadata, Pad, Scope};
+use crate::store::{Bucket, DataStore};
+use serde::Serialize;
+use std::collections::{HashMap, HashSet};
+use uuid::Uuid;
+
+/// One pad of a streamed listing.
+#[derive(Debug, Clone, Serialize)]
+pub struct StreamedPad {
+    /// The pad's index as typed on the command line: `3`, `p1`, `2.1`.
+    pub index: String,
+    #[serde(flatten)]
+    pub metadata: Metadata,
+    pub content: String,
+}
+
+/// Hands each pad `filter` and `selectors` keep to `emit`, in listing order,
+/// and returns how many there were.
+pub fn stream<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    filter: PadFilter,
+    selectors: &[PadSelector],
+    cancel: &Cancellation,
+    mut emit: impl FnMut(&StreamedPad) -> Result<()>,
+) -> Result<usize> {
+    if filter.search_term.is_some() {
+        return Err(PadzError::Api(
+            "A streamed listing cannot search: a search reads every pad first".to_string(),
+        ));
+    }
+
+    let mut buckets: HashMap<Uuid, Bucket> = HashMap::new();
+    let mut read = |bucket: Bucket| -> Result<Vec<Pad>> {
+        Ok(store
+            .list_metadata(scope, bucket)?
+            .into_iter()
+            .map(|metadata| {
+                buckets.insert(metadata.id, bucket);
+                Pad {
+                    metadata,
+                    content: String::new(),
+                }
+            })
+            .collect())
+    };
+    let indexed = index_pads(
+        read(Bucket::Active)?,
+        read(Bucket::Archived)?,
+        read(Bucket::Deleted)?,
+        current_ordering_key(),
+    );
+    let listed = select(indexed, filter, selectors)?;
+
+    let mut order = Vec::new();
+    flatten(&listed, "", &mut order);
+    let mut streamed = HashSet::new();
+    for (index, id) in order {
+        if !streamed.insert(id) {
+            continue;
+        }
+        if cancel.is_cancelled() {
+            return Err(PadzError::Interrupted);
+        }
+        let pad = store.get_pad(&id, scope, buckets[&id])?;
+        emit(&StreamedPad {
+            index,
+            metadata: pad.metadata,
+            content: pad.content,
+        })?;
+    }
+    Ok(streamed.len())
+}
+
+/// Every pad of the tree with its full index, parents before their children.
+fn flatten(pads: &[DisplayPad], prefix: &str, order: &mut Vec<(String, Uuid)>) {
+    for dp in pads {
+        let index = format!("{}{}", prefix, dp.index);
+        order.push((index.clone(), dp.pad.metadata.id));
+        flatten(&dp.children, &format!("{}.", index), order);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, pinning};
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    #[test]
+    fn pads_stream_once_in_listing_order_with_their_bodies() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        create::run(&mut store, Scope::Project, "Older".into(), "".into(), None).unwrap();
+        create::run(
+            &mut store,
+            Scope::Project,
+            "Newer".into(),
+            "body".into(),
+            None,
+        )
+        .unwrap();
+        let older = PadSelector::Title("Older".into());
+        create::run(
+            &mut store,
+            Scope::Project,
+            "Child".into(),
+            "".into(),
+            Some(older),
+        )
+        .unwrap();
+        pinning::pin(
+            &mut store,
+            Scope::Project,
+            &[PadSelector::Title("Newer".into())],
+        )
+        .unwrap();
+
+        let mut lines = Vec::new();
+        let count = stream(
+            &store,
+            Scope::Project,
+            PadFilter::default(),
+            &[],
+            &Cancellation::new(),
+            |pad| {
+                lines.push((
+                    pad.index.clone(),
+                    pad.metadata.title.clone(),
+                    pad.content.clone(),
+                ));
+                Ok(())
+            },
+        )
+        .unwrap();
+
+        assert_eq!(count, 3);
+        // The pinned pad comes first, and only there.
+        assert_eq!((lines[0].0.as_str(), lines[0].1.as_str()), ("p1", "Newer"));
+        assert!(lines[0].2.ends_with("body"));
+        let older = lines.iter().position(|line| line.1 == "Older").unwrap();
+        assert_eq!(lines[older + 1].1, "Child");
+        assert_eq!(lines[older + 1].0, format!("{}.1", lines[older].0));
+    }
+
+    #[test]
+    fn a_search_cannot_be_streamed() {
+        let store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        let filter = PadFilter {
+            search_term: Some("x".into()),
+            ..PadFilter::default()
+        };
+        let result = stream(
+            &store,
+            Scope::Project,
+            filter,
+            &[],
+            &Cancellation::new(),
+            |_| Ok(()),
+        );
+        assert!(result.is_err());
+    }
+}
@@ -0,0 +1,171 @@
+//! Grouping a listing (`list --group-by`).
+//!
+//! A grouped listing is the same root listing, split into groups of pads that
+//! share a key: the project a pad was created in, or the day or ISO week
+//! of its creation. Groups come in the order their first pad appears, and
+//! every pad keeps its canonical index and its place within its group, so a
+//! grouped listing only adds headers to the order the user already knows.
+//!
+//! A pad's project is read from its creation context (see
+//! [`crate::model::CreationContext`]): the registered project whose directory
+//! holds the recorded working directory, or that directory itself when no
+//! registered project does. In a project store, a pad with no context belongs
+//! to the store's own project.
+
+use crate::index::DisplayPad;
+use crate::model::Metadata;
+use serde::Serialize;
+use std::path::{Path, PathBuf};
+
+/// What a listing is grouped by.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum GroupBy {
+    Project,
+    Day,
+    Week,
+}
+
+/// The pads of a listing that share a key.
+#[derive(Debug, Clone, Serialize)]
+pub struct PadGroup {
+    /// The project directory, the day (`2026-10-15`) or the ISO week
+    /// (`2026-W42`); `None` for pads with no recorded project.
+    pub key: Option<String>,
+    /// Distinct top-level pads in the group.
+    pub count: usize,
+    pub pads: Vec<DisplayPad>,
+}
+
+/// Splits root pads into groups by `by`. `projects` are the known project
+/// roots; `home` is the project that pads without a creation context belong
+/// to, if any.
+pub fn group(
+    pads: Vec<DisplayPad>,
+    by: GroupBy,
+    projects: &[PathBuf],
+    home: Option<&Path>,
+) -> Vec<PadGroup> {
+    let mut groups: Vec<PadGroup> = Vec::new();
+    for pad in pads {
+        let key = key_of(&pad, by, projects, home);
+        match groups.iter_mut().find(|group| group.key == key) {
+            Some(group) => {
+                // A pinned pad is listed twice, pinned and in place: count it once.
+                let id = pad.pad.metadata.id;
+                if !group.pads.iter().any(|listed| listed.pad.metadata.id == id) {
+                    group.count += 1;
+                }
+                group.pads.push(pad);
+            }
+            None => groups.push(PadGroup {
+                key,
+                count: 1,
+                pads: vec![pad],
+            }),
+        }
+    }
+    groups
+}
+
+fn key_of(
+    pad: &DisplayPad,
+    by: GroupBy,
+    projects: &[PathBuf],
+    home: Option<&Path>,
+) -> Option<String> {
+    let created = pad.pad.metadata.created_at;
+    match by {
+        GroupBy::Day => Some(created.format("%Y-%m-%d").to_string()),
+        GroupBy::Week => Some(created.format("%G-W%V").to_string()),
+        GroupBy::Project => {
+            project(&pad.pad.metadata, projects, home).map(|dir| dir.to_string_lossy().into_owned())
+        }
+    }
+}
+
+/// The project a pad belongs to: the one its creation context points at, or
+/// `home` for a pad with no context.
+pub fn project(metadata: &Metadata, projects: &[PathBuf], home: Option<&Path>) -> Option<PathBuf> {
+    match &metadata.context {
+        Some(context) => Some(project_of(Path::new(&context.cwd), projects)),
+        None => home.map(Path::to_path_buf),
+    }
+}
+
+/// The deepest known project holding `cwd`, or `cwd` itself.
+fn project_of(cwd: &Path, projects: &[PathBuf]) -> PathBuf {
+    projects
+        .iter()
+        .filter(|project| cwd.starts_with(project))
+        .max_by_key(|project| project.components().count())
+        .cloned()
+        .unwrap_or_else(|| cwd.to_path_buf())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::index::DisplayIndex;
+    use crate::model::{CreationContext, Pad};
+    use chrono::{TimeZone, Utc};
+
+    fn pad(index: usize, day: u32, cwd: Option<&str>) -> DisplayPad {
+        let mut pad = Pad::new(format!("Pad {}", index), String::new());
+        pad.metadata.created_at = Utc.with_ymd_and_hms(2026, 10, day, 9, 0, 0).unwrap();
+        pad.metadata.context = cwd.map(|cwd| CreationContext {
+            cwd: cwd.to_string(),
+            commit: String::new(),
+            branch: None,
+            dirty: false,
+        });
+        DisplayPad {
+            pad,
+            index: DisplayIndex::Regular(index),
+            matches: None,
+            children: Vec::new(),
+        }
+    }
+
+    fn keys(groups: &[PadGroup]) -> Vec<(Option<&str>, usize)> {
+        groups
+            .iter()
+            .map(|group| (group.key.as_deref(), group.count))
+            .collect()
+    }
+
+    #[test]
+    fn days_and_weeks_group_in_listing_order() {
+        // 2026-10-15 is a Thursday, the 12th its week's Monday.
+        let pads = vec![pad(1, 15, None), pad(2, 12, None), pad(3, 15, None)];
+
+        let by_day = group(pads.clone(), GroupBy::Day, &[], None);
+        assert_eq!(
+            keys(&by_day),
+            vec![(Some("2026-10-15"), 2), (Some("2026-10-12"), 1)]
+        );
+        assert_eq!(by_day[0].pads[1].index, DisplayIndex::Regular(3));
+
+        let by_week = group(pads, GroupBy::Week, &[], None);
+        assert_eq!(keys(&by_week), vec![(Some("2026-W42"), 3)]);
+    }
+
+    #[test]
+    fn projects_come_from_the_creation_context() {
+        let projects = vec![PathBuf::from("/work/api"), PathBuf::from("/work")];
+        let pads = vec![
+            pad(1, 15, Some("/work/api/src")),
+            pad(2, 15, Some("/tmp")),
+            pad(3, 15, None),
+            pad(4, 15, Some("/work/api")),
+        ];
+
+        let groups = group(pads.clone(), GroupBy::Project, &projects, None);
+        assert_eq!(
+            keys(&groups),
+            vec![(Some("/work/api"), 2), (Some("/tmp"), 1), (None, 1)]
+        );
+
+        let home = group(pads, GroupBy::Project, &projects, Some(Path::new("/work")));
+        assert_eq!(home[2].key.as_deref(), Some("/work"));
+    }
+}
@@ -9,6 +9,7 @@ mod tree_search;
 pub use indexing::{bucket_for_index, indexed_pads};
 pub use nesting::{collect_nested_pads, NestedPad};
 pub use pad_fetching::{pads_by_selectors, pads_with_paths_by_selectors};
+pub(crate) use selector_resolve::{linearize_tree, selector_matches};
 pub use selector_resolve::{resolve_selectors, TitleBucket};
 pub use tree_search::{find_pad_by_uuid, get_descendant_ids};
 
@@ -7,10 +7,11 @@ use uuid::Uuid;
 use super::fmt_path;
 use super::indexing::indexed_pads;
 
-/// Bucket scope for `PadSelector::Title` matching.
+/// Bucket scope for the searching selectors: `PadSelector::Title` and
+/// `PadSelector::ShortUuid`.
 ///
-/// Other selector variants (Path, Range, Uuid, ShortUuid) carry their bucket
-/// information intrinsically and are unaffected by this filter.
+/// The naming variants (Path, Range, Uuid) carry their bucket information
+/// intrinsically and are unaffected by this filter. See [`in_scope`].
 #[derive(Debug, Clone, Copy, PartialEq, Eq)]
 pub enum TitleBucket {
     /// Only match pads in the active bucket (Regular + Pinned roots).
@@ -23,6 +24,9 @@ pub enum TitleBucket {
     Any,
 }
 
+/// A pad of the linearized tree with its full display path.
+pub(crate) type Located<'a> = (Vec<DisplayIndex>, &'a DisplayPad);
+
 /// Maximum number of ambiguous matches to list inline in the error message.
 /// Above this, we fall back to just reporting the count.
 const AMBIGUITY_LIST_THRESHOLD: usize = 5;
@@ -41,144 +45,173 @@ pub fn resolve_selectors<S: DataStore>(
     let mut results = Vec::new();
 
     for selector in selectors {
-        match selector {
-            PadSelector::Path(path) => {
-                if let Some((_, pad)) = find_in_linearized(&linearized, path) {
-                    check_protection(pad, check_delete_protection)?;
-                    results.push((path.clone(), pad.pad.metadata.id));
-                } else {
-                    let s: Vec<String> = path.iter().map(|idx| idx.to_string()).collect();
-                    return Err(PadzError::Api(format!(
-                        "Index {} not found in current scope",
-                        s.join(".")
-                    )));
-                }
+        let matches = selector_matches(&linearized, selector, title_bucket)?;
+
+        if let PadSelector::Title(term) = selector {
+            if matches.len() > 1 {
+                return Err(ambiguous_title(term, &matches));
             }
-            PadSelector::Range(start_path, end_path) => {
-                let start_idx = linearized
-                    .iter()
-                    .position(|(p, _)| p == start_path)
-                    .ok_or_else(|| {
-                        PadzError::Api(format!("Range start {} not found", fmt_path(start_path)))
-                    })?;
-                let end_idx = linearized
-                    .iter()
-                    .position(|(p, _)| p == end_path)
-                    .ok_or_else(|| {
-                        PadzError::Api(format!("Range end {} not found", fmt_path(end_path)))
-                    })?;
-
-                if start_idx > end_idx {
-                    return Err(PadzError::Api(format!(
-                        "Invalid range: {} appears after {} in the list",
-                        fmt_path(start_path),
-                        fmt_path(end_path)
-                    )));
-                }
+        }
 
-                for (path, pad) in linearized.iter().take(end_idx + 1).skip(start_idx) {
-                    check_protection(pad, check_delete_protection)?;
-                    results.push((path.clone(), pad.pad.metadata.id));
-                }
+        for (path, dp) in matches {
+            check_protection(dp, check_delete_protection)?;
+            results.push((path.clone(), dp.pad.metadata.id));
+        }
+    }
+
+    Ok(results)
+}
+
+/// The in-scope pads `selector` names, in list order.
+///
+/// This is the one place selectors are matched against the tree: both
+/// [`resolve_selectors`] and `get`'s selector filter go through it, so every
+/// resolution path applies the same [`in_scope`] check and reports the same
+/// errors. Errors when nothing matches, when a range is malformed, or when a
+/// UUID prefix is not unique. A title may match several pads; whether that is
+/// ambiguous is the caller's decision.
+pub(crate) fn selector_matches<'l, 'a>(
+    linearized: &'l [Located<'a>],
+    selector: &PadSelector,
+    bucket: TitleBucket,
+) -> Result<Vec<&'l Located<'a>>> {
+    let scoped: Vec<&'l Located<'a>> = linearized
+        .iter()
+        .filter(|(path, _)| in_scope(selector, path, bucket))
+        .collect();
+
+    match selector {
+        PadSelector::Path(path) => match scoped.into_iter().find(|(p, _)| p == path) {
+            Some(found) => Ok(vec![found]),
+            None => Err(PadzError::Api(format!(
+                "Index {} not found in current scope",
+                fmt_path(path)
+            ))),
+        },
+        PadSelector::Range(start_path, end_path) => {
+            let start_idx = scoped
+                .iter()
+                .position(|(p, _)| p == start_path)
+                .ok_or_else(|| {
+                    PadzError::Api(format!("Range start {} not found", fmt_path(start_path)))
+                })?;
+            let end_idx = scoped
+                .iter()
+                .position(|(p, _)| p == end_path)
+                .ok_or_else(|| {
+                    PadzError::Api(format!("Range end {} not found", fmt_path(end_path)))
+                })?;
+
+            if start_idx > end_idx {
+                return Err(PadzError::Api(format!(
+                    "Invalid range: {} appears after {} in the list",
+                    fmt_path(start_path),
+                    fmt_path(end_path)
+                )));
             }
-            PadSelector::Uuid(uuid) => {
-                let found = linearized
-                    .iter()
-                    .find(|(_, dp)| dp.pad.metadata.id == *uuid);
-
-                match found {
-                    Some((path, dp)) => {
-                        check_protection(dp, check_delete_protection)?;
-                        results.push((path.clone(), dp.pad.metadata.id));
-                    }
-                    None => {
-                        return Err(PadzError::Api(format!("No pad found with UUID {}", uuid)));
-                    }
-                }
+
+            Ok(scoped[start_idx..=end_idx].to_vec())
+        }
+        PadSelector::Uuid(uuid) => match scoped
+            .into_iter()
+            .find(|(_, dp)| dp.pad.metadata.id == *uuid)
+        {
+            Some(found) => Ok(vec![found]),
+            None => Err(PadzError::Api(format!("No pad found with UUID {}", uuid))),
+        },
+        PadSelector::ShortUuid(hex) => {
+            let matches: Vec<&'l Located<'a>> = scoped
+                .into_iter()
+                .filter(|(_, dp)| {
+                    dp.pad
+                        .metadata
+                        .id
+                        .to_string()
+                        .replace('-', "")
+                        .starts_with(hex.as_str())
+                })
+                .collect();
+
+            match matches.len() {
+                0 => Err(PadzError::Api(format!(
+                    "No pad found with UUID prefix {}",
+                    hex
+                ))),
+                1 => Ok(matches),
+                n => Err(PadzError::Api(format!(
+                    "UUID prefix \"{}\" matches {} pads. Use more characters to be unique.",
+                    hex, n
+                ))),
             }
-            PadSelector::ShortUuid(hex) => {
-                let matches: Vec<&(Vec<DisplayIndex>, &DisplayPad)> = linearized
-                    .iter()
-                    .filter(|(_, dp)| {
-                        dp.pad
-                            .metadata
-                            .id
-                            .to_string()
-                            .replace('-', "")
-                            .starts_with(hex.as_str())
-                    })
-                    .collect();
-
-                match matches.len() {
-                    0 => {
-                        return Err(PadzError::Api(format!(
-                            "No pad found with UUID prefix {}",
-                            hex
-                        )));
-                    }
-                    1 => {
-                        let (path, dp) = matches[0];
-                        check_protection(dp, check_delete_protection)?;
-                        results.push((path.clone(), dp.pad.metadata.id));
-                    }
-                    n => {
-                        return Err(PadzError::Api(format!(
-                            "UUID prefix \"{}\" matches {} pads. Use more characters to be unique.",
-                            hex, n
-                        )));
-                    }
-                }
+        }
+        PadSelector::Title(term) => {
+            // An exact alias names one pad, like a UUID: it wins over title
+            // matches and the bucket does not apply.
+            let alias = term.trim().to_lowercase();
+            if let Some(named) = linearized
+                .iter()
+                .find(|(_, dp)| dp.pad.metadata.alias.as_deref() == Some(alias.as_str()))
+            {
+                return Ok(vec![named]);
             }
-            PadSelector::Title(term) => {
-                let term_lower = term.to_lowercase();
-                let matches: Vec<&(Vec<DisplayIndex>, &DisplayPad)> = linearized
-                    .iter()
-                    .filter(|(path, _)| path_in_bucket(path, title_bucket))
-                    .filter(|(_, dp)| dp.pad.metadata.title.to_lowercase().contains(&term_lower))
-                    .collect();
-
-                match matches.len() {
-                    0 => {
-                        return Err(PadzError::Api(format!(
-                            "No pad found matching \"{}\"",
-                            term
-                        )))
-                    }
-                    1 => {
-                        let (path, dp) = matches[0];
-                        check_protection(dp, check_delete_protection)?;
-                        results.push((path.clone(), dp.pad.metadata.id));
-                    }
-                    n if n <= AMBIGUITY_LIST_THRESHOLD => {
-                        let candidates = matches
-                            .iter()
-                            .map(|(path, dp)| AmbiguityCandidate {
-                                index: fmt_path(path),
-                                title: dp.pad.metadata.title.clone(),
-                            })
-                            .collect();
-                        return Err(PadzError::AmbiguousTitle {
-                            term: term.clone(),
-                            total: n,
-                            candidates,
-                        });
-                    }
-                    n => {
-                        // Above the threshold, enumerating the matches helps
-                        // nobody — report the count alone and leave
-                        // `candidates` empty.
-                        return Err(PadzError::AmbiguousTitle {
-                            term: term.clone(),
-                            total: n,
-                            candidates: Vec::new(),
-                        });
-                    }
-                }
+
+            let term_lower = term.to_lowercase();
+            let matches: Vec<&'l Located<'a>> = scoped
+                .into_iter()
+                .filter(|(_, dp)| dp.pad.metadata.title.to_lowercase().contains(&term_lower))
+                .collect();
+
+            if matches.is_empty() {
+                return Err(PadzError::Api(format!(
+                    "No pad found matching \"{}\"",
+                    term
+                )));
             }
+            Ok(matches)
         }
     }
+}
 
-    Ok(results)
+/// Scope validation, shared by every resolution path: may `selector` select
+/// the pad at `path` when the command works on `bucket`?
+///
+/// Paths and ranges are written in display indexes whose bucket is explicit
+/// (`3`, `ar1`, `d2`), and a full UUID names exactly one pad, so those are
+/// always in scope. Searches — a title or a UUID prefix — only see pads in
+/// `bucket`: a prefix shared with a deleted pad must neither select the
+/// deleted pad nor make the active one ambiguous. (A title that is exactly a
+/// pad's alias is a name, not a search; [`selector_matches`] settles it
+/// before this filter is consulted.)
+///
+/// Scope in the project/global sense needs no check here: the tree being
+/// searched is built from one scope's store only.
+pub(crate) fn in_scope(selector: &PadSelector, path: &[DisplayIndex], bucket: TitleBucket) -> bool {
+    match selector {
+        PadSelector::Path(_) | PadSelector::Range(..) | PadSelector::Uuid(_) => true,
+        PadSelector::ShortUuid(_) | PadSelector::Title(_) => path_in_bucket(path, bucket),
+    }
+}
+
+fn ambiguous_title(term: &str, matches: &[&Located<'_>]) -> PadzError {
+    let total = matches.len();
+    // Above the threshold, enumerating the matches helps nobody — report the
+    // count alone and leave `candidates` empty.
+    let candidates = if total <= AMBIGUITY_LIST_THRESHOLD {
+        matches
+            .iter()
+            .map(|(path, dp)| AmbiguityCandidate {
+                index: fmt_path(path),
+                title: dp.pad.metadata.title.clone(),
+            })
+            .collect()
+    } else {
+        Vec::new()
+    };
+    PadzError::AmbiguousTitle {
+        term: term.to_string(),
+        total,
+        candidates,
+    }
 }
 
 /// Is this pad in the bucket we're filtering to?
@@ -219,7 +252,8 @@ fn check_protection(dp: &DisplayPad, enabled: bool) -> Result<()> {
     Ok(())
 }
 
-fn linearize_tree(roots: &[DisplayPad]) -> Vec<(Vec<DisplayIndex>, &DisplayPad)> {
+/// Flattens the tree depth-first, pairing every pad with its display path.
+pub(crate) fn linearize_tree(roots: &[DisplayPad]) -> Vec<Located<'_>> {
     let mut result = Vec::new();
     for pad in roots {
         linearize_recursive(pad, Vec::new(), &mut result);
@@ -242,13 +276,6 @@ fn linearize_recursive<'a>(
     }
 }
 
-fn find_in_linearized<'a>(
-    linearized: &'a [(Vec<DisplayIndex>, &'a DisplayPad)],
-    path: &[DisplayIndex],
-) -> Option<&'a (Vec<DisplayIndex>, &'a DisplayPad)> {
-    linearized.iter().find(|(p, _)| p == path)
-}
-
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -1423,4 +1450,203 @@ mod tests {
         assert!(rendered.contains("more specific"));
         assert!(!rendered.contains('\x1b'));
     }
+
+    // --- Scope validation (shared by every resolution path) ------------------
+
+    enum Expect {
+        Title(&'static str),
+        Fails(&'static str),
+    }
+
+    #[test]
+    fn test_scope_validation_table() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        let newest = || vec![PadSelector::Path(vec![DisplayIndex::Regular(1)])];
+        create::run(
+            &mut store,
+            Scope::Project,
+            "Report archived".into(),
+            "".into(),
+            None,
+        )
+        .unwrap();
+        crate::commands::archive::run(&mut store, Scope::Project, &newest()).unwrap();
+        create::run(
+            &mut store,
+            Scope::Project,
+            "Report deleted".into(),
+            "".into(),
+            None,
+        )
+        .unwrap();
+        crate::commands::delete::run(&mut store, Scope::Project, &newest()).unwrap();
+        create::run(
+            &mut store,
+            Scope::Project,
+            "Report draft".into(),
+            "".into(),
+            None,
+        )
+        .unwrap();
+        create::run(
+            &mut store,
+            Scope::Global,
+            "Report global".into(),
+            "".into(),
+            None,
+        )
+        .unwrap();
+
+        let mut titles = std::collections::HashMap::new();
+        for scope in [Scope::Project, Scope::Global] {
+            for bucket in [Bucket::Active, Bucket::Archived, Bucket::Deleted] {
+                for pad in store.list_pads(scope, bucket).unwrap() {
+                    titles.insert(pad.metadata.id, pad.metadata.title);
+                }
+            }
+        }
+        let id_of = |title: &str| *titles.iter().find(|(_, t)| *t == title).unwrap().0;
+        let prefix_of = |title: &str| id_of(title).simple().to_string()[..8].to_string();
+        let deleted = id_of("Report deleted");
+        let global = id_of("Report global");
+
+        let title = |t: &str| PadSelector::Title(t.to_string());
+        let short = |h: String| PadSelector::ShortUuid(h);
+        use Expect::*;
+        use TitleBucket::{Active, Any, Archived, Deleted};
+        let cases = [
+            // Titles see only the requested bucket.
+            (
+                "title/active",
+                Scope::Project,
+                title("report"),
+                Active,
+                Title("Report draft"),
+            ),
+            (
+                "title/archived",
+                Scope::Project,
+                title("report"),
+                Archived,
+                Title("Report archived"),
+            ),
+            (
+                "title/deleted",
+                Scope::Project,
+                title("report"),
+                Deleted,
+                Title("Report deleted"),
+            ),
+            (
+                "title/any",
+                Scope::Project,
+                title("report"),
+                Any,
+                Fails("Report deleted"),
+            ),
+            // UUID prefixes are searches too, and are scoped the same way.
+            (
+                "prefix/out of bucket",
+                Scope::Project,
+                short(prefix_of("Report deleted")),
+                Active,
+                Fails("No pad found with UUID prefix"),
+            ),
+            (
+                "prefix/in bucket",
+                Scope::Project,
+                short(prefix_of("Report deleted")),
+                Deleted,
+                Title("Report deleted"),
+            ),
+            (
+                "prefix/scoping disambiguates",
+                Scope::Project,
+                short(String::new()),
+                Active,
+                Title("Report draft"),
+            ),
+            (
+                "prefix/any",
+                Scope::Project,
+                short(String::new()),
+                Any,
+                Fails("matches 3 pads"),
+            ),
+            // Full UUIDs and indexes name one pad; the bucket does not apply.
+            (
+                "uuid/any bucket",
+                Scope::Project,
+                PadSelector::Uuid(deleted),
+                Active,
+                Title("Report deleted"),
+            ),
+            (
+                "path/any bucket",
+                Scope::Project,
+                PadSelector::Path(vec![DisplayIndex::Deleted(1)]),
+                Active,
+                Title("Report deleted"),
+            ),
+            // Project resolution never sees global pads, and vice versa.
+            (
+                "project/no global title",
+                Scope::Project,
+                title("global"),
+                Any,
+                Fails("No pad found matching"),
+            ),
+            (
+                "project/no global uuid",
+                Scope::Project,
+                PadSelector::Uuid(global),
+                Any,
+                Fails("No pad found with UUID"),
+            ),
+            (
+                "project/no global prefix",
+                Scope::Project,
+                short(prefix_of("Report global")),
+                Any,
+                Fails("No pad found with UUID prefix"),
+            ),
+            (
+                "global/title",
+                Scope::Global,
+                title("report"),
+                Any,
+                Title("Report global"),
+            ),
+            (
+                "global/no project uuid",
+                Scope::Global,
+                PadSelector::Uuid(deleted),
+                Any,
+                Fails("No pad found with UUID"),
+            ),
+        ];
+
+        for (name, scope, selector, bucket, expect) in cases {
+            let result = resolve_selectors(&store, scope, &[selector], false, bucket);
+            match (expect, result) {
+                (Title(want), Ok(resolved)) => {
+                    assert_eq!(resolved.len(), 1, "{name}");
+                    assert_eq!(titles[&resolved[0].1], want, "{name}");
+                }
+                (Fails(fragment), Err(err)) => {
+                    let msg = err.to_string();
+                    assert!(msg.contains(fragment), "{name}: got {msg:?}");
+                }
+                (Title(want), Err(err)) => panic!("{name}: expected {want:?}, got error {err}"),
+                (Fails(fragment), Ok(resolved)) => {
+                    panic!("{name}: expected error {fragment:?}, got {resolved:?}")
+                }
+            }
+        }
+    }
 }
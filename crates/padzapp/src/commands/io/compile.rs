@@ -0,0 +1,293 @@
+//! Compiling tagged pads into one curated document.
+//!
+//! `padz compile --tag docs --to NOTES.md` is how scratch notes graduate into a
+//! committed document: the pads carrying the tags are merged, in a chosen order,
+//! into the same markdown (or text) layout `export --single-file` writes. Unlike
+//! export, a compile is meant to be re-run — the destination is a stable file
+//! name rather than a timestamped or title-derived one, so regenerating the
+//! document updates it in place.
+//!
+//! Only active pads are compiled. Children are compiled like any other pad when
+//! they carry the tags; they are not pulled in through their parents, so the tag
+//! alone decides what goes in.
+
+use super::export::{
+    merge_pads_to_single_file, ExportArtifact, ExportFormat, ExportOutcome, ExportReport,
+    SingleFileFormat,
+};
+use crate::commands::helpers::{
+    find_pad_by_uuid, indexed_pads, resolve_selectors, NestedPad, TitleBucket,
+};
+use crate::error::{PadzError, Result};
+use crate::index::{DisplayIndex, DisplayPad, PadSelector};
+use crate::model::{Pad, Scope};
+use crate::store::{Bucket, DataStore};
+use serde::{Deserialize, Serialize};
+
+/// The order pads appear in a compiled document.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "snake_case")]
+pub enum CompileOrder {
+    /// Oldest first: the order the notes were written in.
+    Created,
+    /// Least recently updated first.
+    Updated,
+    /// Alphabetically by title.
+    Title,
+    /// The order the pads were selected in; without a selection, as `Created`.
+    Selection,
+}
+
+/// What to compile and where it is meant to go.
+#[derive(Debug, Clone)]
+pub struct CompileOptions {
+    /// Pads must carry all of these tags. Empty means no tag filter.
+    pub tags: Vec<String>,
+    pub order: CompileOrder,
+    /// Reverse the order after sorting.
+    pub reverse: bool,
+    /// The document's top-level heading.
+    pub title: String,
+    /// The suggested destination; its extension picks markdown or text.
+    pub destination: String,
+}
+
+pub fn run<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    options: &CompileOptions,
+) -> Result<ExportOutcome> {
+    if selectors.is_empty() && options.tags.is_empty() {
+        return Err(PadzError::Api(
+            "Nothing to compile: give tags, pad indexes, or both".to_string(),
+        ));
+    }
+
+    let mut pads = if selectors.is_empty() {
+        store.list_pads(scope, Bucket::Active)?
+    } else {
+        let resolved = resolve_selectors(store, scope, selectors, false, TitleBucket::Active)?;
+        let mut pads: Vec<Pad> = Vec::with_capacity(resolved.len());
+        for (_, uuid) in resolved {
+            if !pads.iter().any(|p| p.metadata.id == uuid) {
+                pads.push(store.get_pad(&uuid, scope, Bucket::Active)?);
+            }
+        }
+        pads
+    };
+    pads.retain(|pad| {
+        options
+            .tags
+            .iter()
+            .all(|tag| pad.metadata.tags.contains(tag))
+    });
+
+    if pads.is_empty() {
+        return Ok(ExportOutcome::Empty {
+            format: ExportFormat::Compiled,
+        });
+    }
+
+    sort(&mut pads, options.order, !selectors.is_empty());
+    if options.reverse {
+        pads.reverse();
+    }
+
+    let indexed = indexed_pads(store, scope)?;
+    let nested: Vec<NestedPad> = pads
+        .into_iter()
+        .map(|pad| {
+            let index = find_pad_by_uuid(&indexed, pad.metadata.id, |idx| {
+                matches!(idx, DisplayIndex::Regular(_))
+            })
+            .map(|dp| dp.index.clone())
+            .unwrap_or(DisplayIndex::Regular(0));
+            NestedPad {
+                pad: DisplayPad {
+                    pad,
+                    index,
+                    matches: None,
+                    children: Vec::new(),
+                },
+                depth: 0,
+            }
+        })
+        .collect();
+
+    let format = SingleFileFormat::from_filename(&options.destination);
+    let result = merge_pads_to_single_file(&nested, &options.title, format);
+
+    Ok(ExportOutcome::Artifact(ExportArtifact {
+        bytes: result.content.into_bytes(),
+        suggested_filename: options.destination.clone(),
+        report: ExportReport {
+            format: ExportFormat::Compiled,
+            exported: nested.len(),
+            warnings: Vec::new(),
+            signed: None,
+            streamed: None,
+            printed: None,
+        },
+    }))
+}
+
+/// Sorts `pads` by `order`. A selection is already in selection order, so
+/// `Selection` only sorts when there was none.
+fn sort(pads: &mut [Pad], order: CompileOrder, selected: bool) {
+    match order {
+        CompileOrder::Selection if selected => {}
+        CompileOrder::Created | CompileOrder::Selection => {
+            pads.sort_by_key(|p| p.metadata.created_at)
+        }
+        CompileOrder::Updated => pads.sort_by_key(|p| p.metadata.updated_at),
+        CompileOrder::Title => pads.sort_by(|a, b| {
+            a.metadata
+                .title
+                .to_lowercase()
+                .cmp(&b.metadata.title.to_lowercase())
+        }),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, tagging, tags};
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn options(tags: &[&str], order: CompileOrder) -> CompileOptions {
+        CompileOptions {
+            tags: tags.iter().map(|t| t.to_string()).collect(),
+            order,
+            reverse: false,
+            title: "Project notes".into(),
+            destination: "NOTES.md".into(),
+        }
+    }
+
+    fn store_with_docs() -> BucketedStore<MemBackend> {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        for (title, body) in [
+            ("Setup", "Run make."),
+            ("Scratch", "not for docs"),
+            ("Architecture", "# Layers\n\nThree of them."),
+        ] {
+            create::run(&mut store, Scope::Project, title.into(), body.into(), None).unwrap();
+        }
+        tags::create_tag(&mut store, Scope::Project, "docs").unwrap();
+        // Newest first: 1 = Architecture, 3 = Setup.
+        let docs = vec![
+            PadSelector::Path(vec![DisplayIndex::Regular(1)]),
+            PadSelector::Path(vec![DisplayIndex::Regular(3)]),
+        ];
+        tagging::add_tags(&mut store, Scope::Project, &docs, &["docs".to_string()]).unwrap();
+        store
+    }
+
+    fn document(outcome: ExportOutcome) -> (String, ExportReport) {
+        match outcome {
+            ExportOutcome::Artifact(artifact) => {
+                (String::from_utf8(artifact.bytes).unwrap(), artifact.report)
+            }
+            ExportOutcome::Empty { .. } => panic!("expected a document"),
+        }
+    }
+
+    #[test]
+    fn test_compiles_tagged_pads_oldest_first() {
+        let store = store_with_docs();
+        let (text, report) = document(
+            run(
+                &store,
+                Scope::Project,
+                &[],
+                &options(&["docs"], CompileOrder::Created),
+            )
+            .unwrap(),
+        );
+
+        assert_eq!(report.format, ExportFormat::Compiled);
+        assert_eq!(report.exported, 2);
+        assert!(text.starts_with("# Project notes\n\n## Setup"));
+        assert!(!text.contains("Scratch"));
+        let setup = text.find("## Setup").unwrap();
+        let architecture = text.find("## Architecture").unwrap();
+        assert!(setup < architecture);
+        // Headings inside a pad nest under the pad's own heading.
+        assert!(text.contains("### Layers"));
+    }
+
+    #[test]
+    fn test_ordering_controls() {
+        let store = store_with_docs();
+        let (text, _) = document(
+            run(
+                &store,
+                Scope::Project,
+                &[],
+                &options(&["docs"], CompileOrder::Title),
+            )
+            .unwrap(),
+        );
+        assert!(text.find("## Architecture").unwrap() < text.find("## Setup").unwrap());
+
+        let mut reversed = options(&["docs"], CompileOrder::Title);
+        reversed.reverse = true;
+        let (text, _) = document(run(&store, Scope::Project, &[], &reversed).unwrap());
+        assert!(text.find("## Setup").unwrap() < text.find("## Architecture").unwrap());
+    }
+
+    #[test]
+    fn test_selection_order_is_kept_and_filtered_by_tags() {
+        let store = store_with_docs();
+        let selected = vec![
+            PadSelector::Path(vec![DisplayIndex::Regular(1)]),
+            PadSelector::Path(vec![DisplayIndex::Regular(2)]),
+            PadSelector::Path(vec![DisplayIndex::Regular(3)]),
+        ];
+        let (text, report) = document(
+            run(
+                &store,
+                Scope::Project,
+                &selected,
+                &options(&["docs"], CompileOrder::Selection),
+            )
+            .unwrap(),
+        );
+        assert_eq!(report.exported, 2);
+        assert!(text.find("## Architecture").unwrap() < text.find("## Setup").unwrap());
+    }
+
+    #[test]
+    fn test_no_matching_pads_is_empty_and_no_filter_is_an_error() {
+        let store = store_with_docs();
+        let outcome = run(
+            &store,
+            Scope::Project,
+            &[],
+            &options(&["missing"], CompileOrder::Created),
+        )
+        .unwrap();
+        assert!(matches!(
+            outcome,
+            ExportOutcome::Empty {
+                format: ExportFormat::Compiled
+            }
+        ));
+
+        assert!(run(
+            &store,
+            Scope::Project,
+            &[],
+            &options(&[], CompileOrder::Created)
+        )
+        .is_err());
+    }
+}
@@ -1,3 +1,4 @@
+use crate::cancel::Cancellation;
 use crate::commands::metadata_schema::{Archive, PadEntry, TagRegistryEntry, SCHEMA_VERSION};
 use crate::commands::NestingMode;
 use crate::error::{PadzError, Result};
@@ -5,7 +6,8 @@ use crate::index::DisplayIndex;
 use crate::index::DisplayPad;
 use crate::index::PadSelector;
 use crate::model::Scope;
-use crate::store::{Bucket, DataStore};
+use crate::progress::{track, Progress};
+use crate::store::{plain_path, Bucket, DataStore};
 use chrono::Utc;
 use flate2::write::GzEncoder;
 use flate2::Compression;
@@ -13,7 +15,9 @@ use pulldown_cmark::{Event, HeadingLevel, Options, Parser, Tag, TagEnd};
 use pulldown_cmark_to_cmark::cmark;
 use serde::Serialize;
 use std::collections::HashSet;
+use std::fs;
 use std::io::Write;
+use std::path::Path;
 use uuid::Uuid;
 
 use crate::commands::helpers::{
@@ -57,6 +61,16 @@ pub enum ExportFormat {
     MetadataArchive,
     JsonArchive,
     SingleFile,
+    /// A curated document assembled by [`compile`](super::compile).
+    Compiled,
+    /// A metadata spreadsheet written by [`csv`](super::csv).
+    Csv,
+    /// The JSON archive's layout in a zip (see [`ArchiveFormat::Zip`]).
+    ZipArchive,
+    /// `db.json` with the pads inline (see [`ArchiveFormat::Json`]).
+    JsonBundle,
+    /// Pads laid out for paper by [`print`](super::print).
+    Printed,
 }
 
 /// A semantic warning discovered while producing an export.
@@ -75,12 +89,49 @@ pub enum ExportWarning {
 ///
 /// This is the value a shell adapter hands to its output framework as the
 /// artifact's semantic report; it carries no destination, since only the
-/// framework knows where the bytes finally landed.
+/// framework knows where the bytes finally landed. The one exception is
+/// [`SignedExport`], filled in by a client that signed and placed the artifact
+/// itself.
 #[derive(Debug, Clone, PartialEq, Eq, Serialize)]
 pub struct ExportReport {
     pub format: ExportFormat,
     pub exported: usize,
     pub warnings: Vec<ExportWarning>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub signed: Option<SignedExport>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub streamed: Option<StreamedExport>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub printed: Option<PrintJob>,
+}
+
+/// Where a client wrote a signed export and its detached signature.
+///
+/// The core never signs — that takes the user's key and, usually, an external
+/// tool — so it never sets this either. It exists so the fact rides in the same
+/// report in every output mode.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct SignedExport {
+    pub destination: std::path::PathBuf,
+    pub signature: std::path::PathBuf,
+}
+
+/// Where a streamed export ([`run_into_dir`]) wrote its pads. Unlike an
+/// artifact, the core writes these files itself: holding tens of thousands of
+/// pads in one byte vector is what streaming avoids.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct StreamedExport {
+    pub directory: std::path::PathBuf,
+    /// Pads already written by an interrupted run and skipped on resume.
+    pub resumed: usize,
+}
+
+/// Where a client sent a printed document instead of writing it. Like
+/// signing, sending to a printer is the client's to do.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct PrintJob {
+    /// The printer named; `None` is the system's default.
+    pub printer: Option<String>,
 }
 
 /// Exact export bytes plus the core's suggested destination and report facts.
@@ -111,6 +162,8 @@ pub fn run<S: DataStore>(
     selectors: &[PadSelector],
     nesting: NestingMode,
     with_metadata: bool,
+    progress: &dyn Progress,
+    cancel: &Cancellation,
 ) -> Result<ExportOutcome> {
     // 1. Resolve pads
     let pads = resolve_pads(store, scope, selectors)?;
@@ -136,9 +189,9 @@ pub fn run<S: DataStore>(
     // 3. Produce archive bytes. Destination selection and writing belong to
     // the caller (the Padz CLI delegates them to Standout).
     let warnings = if with_metadata {
-        write_archive_with_metadata(&mut bytes, store, scope, &nested)?
+        write_archive_with_metadata(&mut bytes, store, scope, &nested, progress, cancel)?
     } else {
-        write_archive(&mut bytes, &nested)?;
+        write_archive(&mut bytes, &nested, progress, cancel)?;
         Vec::new()
     };
 
@@ -153,11 +206,14 @@ pub fn run<S: DataStore>(
             },
             exported: pads.len(),
             warnings,
+            signed: None,
+            streamed: None,
+            printed: None,
         },
     }))
 }
 
-fn resolve_nested<S: DataStore>(
+pub(super) fn resolve_nested<S: DataStore>(
     store: &S,
     scope: Scope,
     pads: &[DisplayPad],
@@ -175,7 +231,7 @@ fn resolve_nested<S: DataStore>(
     }
 }
 
-fn resolve_pads<S: DataStore>(
+pub(super) fn resolve_pads<S: DataStore>(
     store: &S,
     scope: Scope,
     selectors: &[PadSelector],
@@ -190,11 +246,17 @@ fn resolve_pads<S: DataStore>(
     }
 }
 
-fn write_archive<W: Write>(writer: W, pads: &[NestedPad]) -> Result<()> {
+fn write_archive<W: Write>(
+    writer: W,
+    pads: &[NestedPad],
+    progress: &dyn Progress,
+    cancel: &Cancellation,
+) -> Result<()> {
     let enc = GzEncoder::new(writer, Compression::default());
     let mut tar = tar::Builder::new(enc);
 
-    for np in pads {
+    for np in track(progress, "Exporting", pads.iter()) {
+        cancel.check()?;
         let dp = &np.pad;
         let title = &dp.pad.metadata.title;
         let safe_title = sanitize_filename(title);
@@ -228,6 +290,8 @@ pub(crate) fn write_archive_with_metadata<W: Write, S: DataStore>(
     store: &S,
     scope: Scope,
     pads: &[NestedPad],
+    progress: &dyn Progress,
+    cancel: &Cancellation,
 ) -> Result<Vec<ExportWarning>> {
     use crate::commands::inline_metadata::{serialize_lex_metadata, serialize_md_frontmatter};
 
@@ -237,7 +301,8 @@ pub(crate) fn write_archive_with_metadata<W: Write, S: DataStore>(
     let mut seen: HashSet<Uuid> = HashSet::new();
     let mut skipped_txt: Vec<String> = Vec::new();
 
-    for np in pads {
+    for np in track(progress, "Exporting", pads.iter()) {
+        cancel.check()?;
         let dp = &np.pad;
         let meta = &dp.pad.metadata;
         if !seen.insert(meta.id) {
@@ -255,7 +320,7 @@ pub(crate) fn write_archive_with_metadata<W: Write, S: DataStore>(
             })
             .unwrap_or((Bucket::Active, std::path::PathBuf::new()));
 
-        let ext = source_path
+        let ext = plain_path(&source_path)
             .extension()
             .and_then(|e| e.to_str())
             .map(str::to_ascii_lowercase)
@@ -297,7 +362,8 @@ pub(crate) fn write_archive_with_metadata<W: Write, S: DataStore>(
     })
 }
 
-fn sanitize_filename(name: &str) -> String {
+/// `name` with every character a file name cannot safely hold replaced by `_`.
+pub(crate) fn sanitize_filename(name: &str) -> String {
     name.chars()
         .map(|c| {
             if c.is_alphanumeric() || c == ' ' || c == '-' || c == '_' {
@@ -340,6 +406,9 @@ pub fn run_single_file<S: DataStore>(
             format: ExportFormat::SingleFile,
             exported: pads.len(),
             warnings: Vec::new(),
+            signed: None,
+            streamed: None,
+            printed: None,
         },
     }))
 }
@@ -511,6 +580,45 @@ fn bump_heading_level_by(level: HeadingLevel, amount: usize) -> HeadingLevel {
     }
 }
 
+/// The container of a full-metadata export (`export --format`). Each holds
+/// the same [`Archive`] and is read back by `padz import`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub enum ArchiveFormat {
+    /// `db.json` and the pad files in a `.tar.gz`, as `--json` writes.
+    #[default]
+    TarGz,
+    /// The same layout in a `.zip`.
+    Zip,
+    /// `db.json` alone, each pad's text inline.
+    Json,
+}
+
+impl ArchiveFormat {
+    fn extension(self) -> &'static str {
+        match self {
+            ArchiveFormat::TarGz => "json.tar.gz",
+            ArchiveFormat::Zip => "zip",
+            ArchiveFormat::Json => "json",
+        }
+    }
+
+    fn report_format(self) -> ExportFormat {
+        match self {
+            ArchiveFormat::TarGz => ExportFormat::JsonArchive,
+            ArchiveFormat::Zip => ExportFormat::ZipArchive,
+            ArchiveFormat::Json => ExportFormat::JsonBundle,
+        }
+    }
+}
+
+/// How [`run_archive`] packs an export.
+#[derive(Debug, Clone, Default)]
+pub struct ArchiveOptions {
+    pub format: ArchiveFormat,
+    /// The project the pads come from, recorded in [`Archive::project`].
+    pub project: Option<String>,
+}
+
 /// Run JSON-format export: tar.gz containing raw pad files + `db.json` with
 /// full metadata.
 ///
@@ -520,53 +628,104 @@ pub fn run_json<S: DataStore>(
     scope: Scope,
     selectors: &[PadSelector],
     nesting: NestingMode,
+    progress: &dyn Progress,
+    cancel: &Cancellation,
 ) -> Result<ExportOutcome> {
+    run_archive(
+        store,
+        scope,
+        selectors,
+        nesting,
+        &ArchiveOptions::default(),
+        progress,
+        cancel,
+    )
+}
+
+/// [`run_json`], in the container `options` name.
+pub fn run_archive<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    nesting: NestingMode,
+    options: &ArchiveOptions,
+    progress: &dyn Progress,
+    cancel: &Cancellation,
+) -> Result<ExportOutcome> {
+    let format = options.format.report_format();
     let pads = resolve_pads(store, scope, selectors)?;
 
     if pads.is_empty() {
-        return Ok(ExportOutcome::Empty {
-            format: ExportFormat::JsonArchive,
-        });
+        return Ok(ExportOutcome::Empty { format });
     }
 
     let nested = resolve_nested(store, scope, &pads, nesting)?;
 
     let now = Utc::now();
-    let filename = format!("padz-{}.json.tar.gz", now.format("%Y-%m-%d_%H-%M-%S"));
-    let mut bytes = Vec::new();
-    write_json_archive(&mut bytes, store, scope, &nested, now)?;
+    let filename = format!(
+        "padz-{}.{}",
+        now.format("%Y-%m-%d_%H-%M-%S"),
+        options.format.extension()
+    );
+    let (mut archive, files) = collect_archive(store, scope, &nested, now, progress, cancel)?;
+    archive.project = options.project.clone();
+
+    let bytes = match options.format {
+        ArchiveFormat::TarGz => {
+            let mut bytes = Vec::new();
+            write_json_archive(&mut bytes, &archive, &files)?;
+            bytes
+        }
+        ArchiveFormat::Zip => {
+            let mut zip = super::zip::ZipWriter::new(now);
+            for (name, content) in &files {
+                zip.add(name, content.as_bytes())?;
+            }
+            zip.add("padz/db.json", &archive_json(&archive)?)?;
+            zip.finish()?
+        }
+        ArchiveFormat::Json => {
+            for (entry, (_, content)) in archive.pads.iter_mut().zip(&files) {
+                entry.content = Some(content.to_string());
+            }
+            archive_json(&archive)?
+        }
+    };
 
     Ok(ExportOutcome::Artifact(ExportArtifact {
         bytes,
         suggested_filename: filename,
         report: ExportReport {
-            format: ExportFormat::JsonArchive,
+            format,
             exported: pads.len(),
             warnings: Vec::new(),
+            signed: None,
+            streamed: None,
+            printed: None,
         },
     }))
 }
 
-pub(crate) fn write_json_archive<W: Write, S: DataStore>(
-    writer: W,
+/// The archive descriptor for `pads`, and each listed pad's file: its path
+/// in the archive and its text, in the order of [`Archive::pads`].
+fn collect_archive<'a, S: DataStore>(
     store: &S,
     scope: Scope,
-    pads: &[NestedPad],
+    pads: &'a [NestedPad],
     exported_at: chrono::DateTime<Utc>,
-) -> Result<()> {
-    let enc = GzEncoder::new(writer, Compression::default());
-    let mut tar = tar::Builder::new(enc);
-
-    // 1. Write each pad file (raw content, preserving extension).
-    //
+    progress: &dyn Progress,
+    cancel: &Cancellation,
+) -> Result<(Archive, Vec<(String, &'a str)>)> {
     // Dedupe by UUID: pinned pads appear twice in the indexed tree (once with
     // a Pinned index, once with a Regular one). In the archive we want exactly
     // one file + db entry per pad.
     let mut pad_entries = Vec::with_capacity(pads.len());
+    let mut files = Vec::with_capacity(pads.len());
     let mut referenced_tags: HashSet<String> = HashSet::new();
     let mut seen: HashSet<Uuid> = HashSet::new();
 
-    for np in pads {
+    for np in track(progress, "Exporting", pads.iter()) {
+        cancel.check()?;
         let dp = &np.pad;
         let meta = &dp.pad.metadata;
         if !seen.insert(meta.id) {
@@ -590,22 +749,14 @@ pub(crate) fn write_json_archive<W: Write, S: DataStore>(
             })
             .unwrap_or_else(|| ("Active".to_string(), std::path::PathBuf::new()));
 
-        let ext = source_path
+        let ext = plain_path(&source_path)
             .extension()
             .and_then(|e| e.to_str())
             .map(str::to_owned)
             .unwrap_or_else(|| "txt".to_string());
 
         let file_name = format!("pads/pad-{}.{}", meta.id, ext);
-        let entry_path = format!("padz/{}", file_name);
-        let content_bytes = dp.pad.content.as_bytes();
-
-        let mut header = tar::Header::new_gnu();
-        header.set_size(content_bytes.len() as u64);
-        header.set_mode(0o644);
-        header.set_cksum();
-        tar.append_data(&mut header, entry_path, content_bytes)
-            .map_err(PadzError::Io)?;
+        files.push((format!("padz/{}", file_name), dp.pad.content.as_str()));
 
         let metadata_value = serde_json::to_value(meta)
             .map_err(|e| PadzError::Api(format!("Failed to serialize pad metadata: {}", e)))?;
@@ -614,10 +765,11 @@ pub(crate) fn write_json_archive<W: Write, S: DataStore>(
             file: file_name,
             bucket: bucket_name,
             metadata: metadata_value,
+            content: None,
         });
     }
 
-    // 2. Collect the referenced subset of the tag registry.
+    // Only the referenced subset of the tag registry travels.
     let all_tags = store.load_tags(scope).unwrap_or_default();
     let tags: Vec<TagRegistryEntry> = all_tags
         .into_iter()
@@ -628,28 +780,206 @@ pub(crate) fn write_json_archive<W: Write, S: DataStore>(
         })
         .collect();
 
-    // 3. Write db.json
     let archive = Archive {
         schema_version: SCHEMA_VERSION,
         exported_at,
         padz_version: env!("CARGO_PKG_VERSION").to_string(),
+        project: None,
         pads: pad_entries,
         tags,
     };
-    let json = serde_json::to_vec_pretty(&archive)
-        .map_err(|e| PadzError::Api(format!("Failed to serialize archive: {}", e)))?;
+    Ok((archive, files))
+}
 
-    let mut header = tar::Header::new_gnu();
-    header.set_size(json.len() as u64);
-    header.set_mode(0o644);
-    header.set_cksum();
-    tar.append_data(&mut header, "padz/db.json", json.as_slice())
-        .map_err(PadzError::Io)?;
+fn archive_json(archive: &Archive) -> Result<Vec<u8>> {
+    serde_json::to_vec_pretty(archive)
+        .map_err(|e| PadzError::Api(format!("Failed to serialize archive: {}", e)))
+}
+
+/// Write the pad files and then `db.json` into a tar.gz.
+fn write_json_archive<W: Write>(
+    writer: W,
+    archive: &Archive,
+    files: &[(String, &str)],
+) -> Result<()> {
+    let enc = GzEncoder::new(writer, Compression::default());
+    let mut tar = tar::Builder::new(enc);
+    let json = archive_json(archive)?;
+    let entries = files
+        .iter()
+        .map(|(name, content)| (name.as_str(), content.as_bytes()))
+        .chain(std::iter::once(("padz/db.json", json.as_slice())));
+
+    for (name, bytes) in entries {
+        let mut header = tar::Header::new_gnu();
+        header.set_size(bytes.len() as u64);
+        header.set_mode(0o644);
+        header.set_cksum();
+        tar.append_data(&mut header, name, bytes)
+            .map_err(PadzError::Io)?;
+    }
 
     tar.finish().map_err(PadzError::Io)?;
     Ok(())
 }
 
+/// Manifest of a finished streamed export: the JSON archive's `db.json`.
+pub const STREAM_MANIFEST: &str = "db.json";
+/// Manifest of a streamed export still in progress, listing the pads written
+/// so far; renamed to [`STREAM_MANIFEST`] when the export completes.
+pub const STREAM_PARTIAL_MANIFEST: &str = "db.json.partial";
+/// Pads written between manifest checkpoints. An interrupted export redoes at
+/// most this many on resume.
+const CHECKPOINT_EVERY: usize = 100;
+
+/// Stream every active and archived pad into `dir`, in the JSON archive
+/// layout unpacked: `pads/pad-<uuid>.<ext>` plus `db.json`.
+///
+/// Pads are read one at a time from the metadata index and written straight
+/// out, so memory stays flat however large the store; each is reported to
+/// `progress`. The manifest is checkpointed as [`STREAM_PARTIAL_MANIFEST`]
+/// along the way, and when `cancel` stops the export; with `resume`, an
+/// interrupted export picks up from it, skipping pads it already lists.
+/// `dry_run` counts what would be written without touching `dir`.
+pub fn run_into_dir<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    dir: &Path,
+    resume: bool,
+    dry_run: bool,
+    progress: &dyn Progress,
+    cancel: &Cancellation,
+) -> Result<ExportReport> {
+    if dir.join(STREAM_MANIFEST).exists() {
+        return Err(PadzError::Api(format!(
+            "{} already holds a finished export",
+            dir.display()
+        )));
+    }
+    let partial = dir.join(STREAM_PARTIAL_MANIFEST);
+    let mut archive = match (resume, partial.exists()) {
+        (true, true) => {
+            let json = fs::read(&partial).map_err(PadzError::Io)?;
+            serde_json::from_slice(&json)
+                .map_err(|e| PadzError::Api(format!("Invalid {}: {}", partial.display(), e)))?
+        }
+        (false, true) => {
+            return Err(PadzError::Api(format!(
+                "{} holds an interrupted export; pass --resume to continue it",
+                dir.display()
+            )))
+        }
+        _ => Archive {
+            schema_version: SCHEMA_VERSION,
+            exported_at: Utc::now(),
+            padz_version: env!("CARGO_PKG_VERSION").to_string(),
+            project: None,
+            pads: Vec::new(),
+            tags: Vec::new(),
+        },
+    };
+    // A listed pad whose file is missing was checkpointed but not synced to
+    // disk before the interruption; drop it so it is written again.
+    archive.pads.retain(|entry| dir.join(&entry.file).is_file());
+    let done: HashSet<String> = archive.pads.iter().map(|e| e.file.clone()).collect();
+    let resumed = done.len();
+
+    let mut pending = Vec::new();
+    for bucket in [Bucket::Active, Bucket::Archived] {
+        for meta in store.list_metadata(scope, bucket)? {
+            pending.push((bucket, meta));
+        }
+    }
+    if pending.is_empty() {
+        return Ok(ExportReport {
+            format: ExportFormat::JsonArchive,
+            exported: 0,
+            warnings: Vec::new(),
+            signed: None,
+            streamed: None,
+            printed: None,
+        });
+    }
+    if !dry_run {
+        fs::create_dir_all(dir.join("pads")).map_err(PadzError::Io)?;
+    }
+
+    let mut referenced_tags: HashSet<String> = HashSet::new();
+    let mut written = 0;
+    for (bucket, meta) in track(progress, "Exporting", pending.into_iter()) {
+        if cancel.is_cancelled() {
+            // Keep what was written, so `--resume` starts from here.
+            if !dry_run {
+                write_manifest(&partial, &archive)?;
+            }
+            return Err(PadzError::Interrupted);
+        }
+        referenced_tags.extend(meta.tags.iter().cloned());
+        let source_path = store.get_pad_path(&meta.id, scope, bucket)?;
+        let ext = plain_path(&source_path)
+            .extension()
+            .and_then(|e| e.to_str())
+            .unwrap_or("txt");
+        let file_name = format!("pads/pad-{}.{}", meta.id, ext);
+        if !done.contains(&file_name) {
+            if !dry_run {
+                let pad = store.get_pad(&meta.id, scope, bucket)?;
+                fs::write(dir.join(&file_name), pad.content.as_bytes()).map_err(PadzError::Io)?;
+                let metadata = serde_json::to_value(&pad.metadata).map_err(|e| {
+                    PadzError::Api(format!("Failed to serialize pad metadata: {}", e))
+                })?;
+                archive.pads.push(PadEntry {
+                    file: file_name,
+                    bucket: bucket_label(bucket),
+                    metadata,
+                    content: None,
+                });
+            }
+            written += 1;
+            if !dry_run && written % CHECKPOINT_EVERY == 0 {
+                write_manifest(&partial, &archive)?;
+            }
+        }
+    }
+
+    if !dry_run {
+        archive.tags = store
+            .load_tags(scope)
+            .unwrap_or_default()
+            .into_iter()
+            .filter(|t| referenced_tags.contains(&t.name))
+            .map(|t| TagRegistryEntry {
+                name: t.name,
+                created_at: t.created_at,
+            })
+            .collect();
+        write_manifest(&partial, &archive)?;
+        fs::rename(&partial, dir.join(STREAM_MANIFEST)).map_err(PadzError::Io)?;
+    }
+
+    Ok(ExportReport {
+        format: ExportFormat::JsonArchive,
+        exported: written,
+        warnings: Vec::new(),
+        signed: None,
+        streamed: Some(StreamedExport {
+            directory: dir.to_path_buf(),
+            resumed,
+        }),
+        printed: None,
+    })
+}
+
+/// Write the manifest atomically, so an interruption mid-write leaves the
+/// previous checkpoint intact.
+fn write_manifest(path: &Path, archive: &Archive) -> Result<()> {
+    let json = serde_json::to_vec_pretty(archive)
+        .map_err(|e| PadzError::Api(format!("Failed to serialize archive: {}", e)))?;
+    let tmp = path.with_extension("tmp");
+    fs::write(&tmp, json).map_err(PadzError::Io)?;
+    fs::rename(&tmp, path).map_err(PadzError::Io)
+}
+
 fn bucket_label(b: Bucket) -> String {
     match b {
         Bucket::Active => "Active",
@@ -698,8 +1028,10 @@ mod tests {
     use crate::commands::create;
     use crate::index::{DisplayIndex, PadSelector};
     use crate::model::Scope;
+    use crate::progress::NoProgress;
     use crate::store::bucketed::BucketedStore;
     use crate::store::mem_backend::MemBackend;
+    use crate::test_utils::CancelAfter;
 
     #[test]
     fn test_resolve_pads_exports_active_by_default() {
@@ -749,7 +1081,13 @@ mod tests {
         let pads = resolve_pads(&store, Scope::Project, &[]).unwrap();
 
         let mut buf = Vec::new();
-        write_archive(&mut buf, &flat_nested(&pads)).unwrap();
+        write_archive(
+            &mut buf,
+            &flat_nested(&pads),
+            &NoProgress,
+            &Cancellation::new(),
+        )
+        .unwrap();
 
         assert!(!buf.is_empty());
         // Could verify tar content but that requires untarring.
@@ -950,7 +1288,16 @@ mod tests {
             MemBackend::new(),
         );
         // No pads created
-        let res = run(&store, Scope::Project, &[], NestingMode::Flat, false).unwrap();
+        let res = run(
+            &store,
+            Scope::Project,
+            &[],
+            NestingMode::Flat,
+            false,
+            &NoProgress,
+            &Cancellation::new(),
+        )
+        .unwrap();
         assert!(matches!(
             res,
             ExportOutcome::Empty {
@@ -1230,4 +1577,110 @@ mod tests {
         // Title at depth 5 should cap at H6
         assert!(output.contains("###### Deep"));
     }
+
+    #[test]
+    fn test_streamed_export_resumes_from_its_manifest() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        for title in ["A", "B", "C"] {
+            create::run(
+                &mut store,
+                Scope::Project,
+                title.into(),
+                "body".into(),
+                None,
+            )
+            .unwrap();
+        }
+        let temp = tempfile::TempDir::new().unwrap();
+        let dir = temp.path().join("export");
+        let export = |resume| {
+            run_into_dir(
+                &store,
+                Scope::Project,
+                &dir,
+                resume,
+                false,
+                &NoProgress,
+                &Cancellation::new(),
+            )
+        };
+
+        let report = export(false).unwrap();
+        assert_eq!(report.exported, 3);
+        let archive: Archive =
+            serde_json::from_slice(&fs::read(dir.join(STREAM_MANIFEST)).unwrap()).unwrap();
+        assert_eq!(archive.pads.len(), 3);
+        assert!(export(true).is_err());
+
+        // Interrupt it: back to a checkpoint with one pad file never written.
+        fs::rename(dir.join(STREAM_MANIFEST), dir.join(STREAM_PARTIAL_MANIFEST)).unwrap();
+        fs::remove_file(dir.join(&archive.pads[0].file)).unwrap();
+        assert!(export(false).is_err());
+
+        let report = export(true).unwrap();
+        assert_eq!(report.exported, 1);
+        assert_eq!(report.streamed.unwrap().resumed, 2);
+        assert!(dir.join(&archive.pads[0].file).is_file());
+        let archive: Archive =
+            serde_json::from_slice(&fs::read(dir.join(STREAM_MANIFEST)).unwrap()).unwrap();
+        assert_eq!(archive.pads.len(), 3);
+        assert!(!dir.join(STREAM_PARTIAL_MANIFEST).exists());
+    }
+
+    #[test]
+    fn test_cancelled_streamed_export_checkpoints_and_resumes() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        for title in ["A", "B", "C"] {
+            create::run(
+                &mut store,
+                Scope::Project,
+                title.into(),
+                "body".into(),
+                None,
+            )
+            .unwrap();
+        }
+        let temp = tempfile::TempDir::new().unwrap();
+        let dir = temp.path().join("export");
+
+        let ctrl_c = CancelAfter::new(1);
+        let err = run_into_dir(
+            &store,
+            Scope::Project,
+            &dir,
+            false,
+            false,
+            &ctrl_c,
+            &ctrl_c.cancel,
+        )
+        .unwrap_err();
+        assert!(matches!(err, PadzError::Interrupted));
+        assert!(!dir.join(STREAM_MANIFEST).exists());
+        let partial: Archive =
+            serde_json::from_slice(&fs::read(dir.join(STREAM_PARTIAL_MANIFEST)).unwrap()).unwrap();
+        assert_eq!(partial.pads.len(), 1);
+
+        let report = run_into_dir(
+            &store,
+            Scope::Project,
+            &dir,
+            true,
+            false,
+            &NoProgress,
+            &Cancellation::new(),
+        )
+        .unwrap();
+        assert_eq!(report.exported, 2);
+        assert_eq!(report.streamed.unwrap().resumed, 1);
+    }
 }
@@ -1,10 +1,16 @@
 //! Semantic import pipeline for plain files, directories, inline metadata,
 //! and full-fidelity JSON archives.
 //!
+//! A plain file becomes one active pad, titled after its first line (or, with
+//! [`ImportOptions::title_from_filename`], its file name) and created when the
+//! file was last modified, so imported notes sort among the others by age.
+//! Inline metadata that names a creation time wins over the file's.
+//!
 //! Every requested source produces one typed report. Recoverable source and
 //! archive-entry failures remain local so independent inputs continue, while
 //! metadata and tag-registry effects stay observable without authored prose.
 
+use crate::cancel::Cancellation;
 use crate::commands::inline_metadata::{parse_lex_metadata, parse_md_frontmatter};
 use crate::commands::metadata_apply::{
     apply_metadata_defensively, parse_bucket_or_active, MetadataApplicationWarning,
@@ -13,8 +19,10 @@ use crate::commands::metadata_apply::{
 use crate::commands::metadata_schema::{Archive, PadEntry};
 use crate::error::{PadzError, Result};
 use crate::model::{parse_pad_content, Pad, Scope};
+use crate::progress::{track, Progress};
 use crate::store::{Bucket, DataStore};
 use crate::tags::TagEntry;
+use chrono::{DateTime, Utc};
 use flate2::read::GzDecoder;
 use serde::{Deserialize, Serialize};
 use std::collections::{HashMap, HashSet};
@@ -126,6 +134,17 @@ pub struct ImportSourceReport {
     pub diagnostics: Vec<ImportDiagnostic>,
 }
 
+/// How plain files and directories are turned into pads.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub struct ImportOptions {
+    /// Descend into a directory source's subdirectories, except hidden ones
+    /// (`.git`, `.padz`). Off, only the files directly inside it are imported.
+    pub recursive: bool,
+    /// Title each pad after its file name, without the extension, keeping all
+    /// of the text as its body, rather than after the text's first line.
+    pub title_from_filename: bool,
+}
+
 /// Complete semantic import report, independent of any presentation client.
 #[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
 pub struct ImportReport {
@@ -138,18 +157,48 @@ pub struct ImportReport {
 ///
 /// Recoverable directory-entry inspection and file-import failures are
 /// retained as diagnostics so a partly imported directory cannot appear fully
-/// successful.
+/// successful. Cancelling `cancel` stops the import with
+/// [`PadzError::Interrupted`], leaving the caller's transaction to drop what
+/// was written.
 pub fn run<S: DataStore>(
     store: &mut S,
     scope: Scope,
     paths: Vec<PathBuf>,
     import_exts: &[String],
+    progress: &dyn Progress,
+    cancel: &Cancellation,
+) -> Result<ImportReport> {
+    run_with_options(
+        store,
+        scope,
+        paths,
+        import_exts,
+        ImportOptions::default(),
+        progress,
+        cancel,
+    )
+}
+
+/// [`run`], importing as `options` say.
+pub fn run_with_options<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    paths: Vec<PathBuf>,
+    import_exts: &[String],
+    options: ImportOptions,
+    progress: &dyn Progress,
+    cancel: &Cancellation,
 ) -> Result<ImportReport> {
     let mut sources = Vec::with_capacity(paths.len());
 
-    for path in paths {
+    for path in track(progress, "Importing", paths.into_iter()) {
+        cancel.check()?;
         if is_json_archive(&path) {
-            sources.push(match import_json_archive(store, scope, &path) {
+            let archive = match import_json_archive(store, scope, &path, progress, cancel) {
+                Err(PadzError::Interrupted) => return Err(PadzError::Interrupted),
+                archive => archive,
+            };
+            sources.push(match archive {
                 Ok(archive) => ImportSourceReport {
                     source: path,
                     source_kind: ImportSourceKind::JsonArchive,
@@ -184,12 +233,15 @@ pub fn run<S: DataStore>(
                     continue;
                 }
             };
-            let directory = import_directory_entries(
-                store,
-                scope,
-                entries.map(|entry| entry.map(|entry| entry.path())),
-                import_exts,
-            );
+            let entries = if options.recursive {
+                tree_entries(entries)
+            } else {
+                entries
+                    .map(|entry| entry.map(|entry| entry.path()))
+                    .collect()
+            };
+            let directory =
+                import_directory_entries(store, scope, entries, import_exts, options, cancel)?;
             sources.push(ImportSourceReport {
                 source: path,
                 source_kind: ImportSourceKind::Directory,
@@ -204,7 +256,7 @@ pub fn run<S: DataStore>(
                 diagnostics: directory.diagnostics,
             });
         } else if path.is_file() {
-            sources.push(match import_file(store, scope, &path) {
+            sources.push(match import_file(store, scope, &path, options) {
                 Ok(res) => {
                     let status = if res.imported > 0 {
                         ImportSourceStatus::Imported
@@ -259,6 +311,35 @@ pub fn run<S: DataStore>(
     })
 }
 
+/// The entries of a directory and, depth first, of its subdirectories, except
+/// hidden ones. Symlinked directories are not followed, so a link back up the
+/// tree cannot loop; a subdirectory that cannot be read is an entry error.
+fn tree_entries(entries: fs::ReadDir) -> Vec<std::io::Result<PathBuf>> {
+    let mut out = Vec::new();
+    for entry in entries {
+        let entry = match entry {
+            Ok(entry) => entry,
+            Err(error) => {
+                out.push(Err(error));
+                continue;
+            }
+        };
+        let path = entry.path();
+        let hidden = entry.file_name().to_string_lossy().starts_with('.');
+        if entry.file_type().is_ok_and(|kind| kind.is_dir()) {
+            if !hidden {
+                match fs::read_dir(&path) {
+                    Ok(sub) => out.extend(tree_entries(sub)),
+                    Err(error) => out.push(Err(error)),
+                }
+            }
+            continue;
+        }
+        out.push(Ok(path));
+    }
+    out
+}
+
 fn unreadable_directory_report(path: PathBuf, error: std::io::Error) -> ImportSourceReport {
     let detail = error.to_string();
     let status = source_error_status(&PadzError::Io(error));
@@ -280,13 +361,16 @@ struct DirectoryImportResult {
 }
 
 /// Import matching directory entries while retaining every recoverable entry
-/// failure as an ordered diagnostic.
+/// failure as an ordered diagnostic. Only cancellation fails the whole
+/// directory.
 fn import_directory_entries<S, I>(
     store: &mut S,
     scope: Scope,
     entries: I,
     import_exts: &[String],
-) -> DirectoryImportResult
+    options: ImportOptions,
+    cancel: &Cancellation,
+) -> Result<DirectoryImportResult>
 where
     S: DataStore,
     I: IntoIterator<Item = std::io::Result<PathBuf>>,
@@ -296,6 +380,7 @@ where
     let mut diagnostics = Vec::new();
 
     for entry in entries {
+        cancel.check()?;
         let sub_path = match entry {
             Ok(path) => path,
             Err(error) => {
@@ -326,7 +411,7 @@ where
                 continue;
             }
         }
-        match import_file(store, scope, &sub_path) {
+        match import_file(store, scope, &sub_path, options) {
             Ok(result) => {
                 imported += result.imported;
                 processed_files.push(sub_path);
@@ -340,11 +425,11 @@ where
         }
     }
 
-    DirectoryImportResult {
+    Ok(DirectoryImportResult {
         imported,
         processed_files,
         diagnostics,
-    }
+    })
 }
 
 fn source_error_status(error: &PadzError) -> ImportSourceStatus {
@@ -377,18 +462,37 @@ fn source_is_partial(source: &ImportSourceReport) -> bool {
         })
 }
 
-/// Heuristic: `.tar.gz` / `.tgz` files are candidates for JSON archive import.
-/// The actual detection (presence of `db.json`) is done by the importer.
+/// Heuristic: `.tar.gz` / `.tgz` and `.zip` files, and `.json` bundles, are
+/// candidates for JSON archive import. The actual detection (presence of
+/// `db.json`) is done by the importer.
 fn is_json_archive(path: &Path) -> bool {
-    if !path.is_file() {
-        return false;
-    }
+    path.is_file() && archive_container(path).is_some()
+}
+
+/// What holds an archive's `db.json`, by file name.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum ArchiveContainer {
+    TarGz,
+    Zip,
+    /// A bundle: `db.json` itself, its pads' text inline.
+    Bundle,
+}
+
+fn archive_container(path: &Path) -> Option<ArchiveContainer> {
     let name = path
         .file_name()
         .and_then(|n| n.to_str())
         .unwrap_or("")
         .to_lowercase();
-    name.ends_with(".tar.gz") || name.ends_with(".tgz")
+    if name.ends_with(".tar.gz") || name.ends_with(".tgz") {
+        Some(ArchiveContainer::TarGz)
+    } else if name.ends_with(".zip") {
+        Some(ArchiveContainer::Zip)
+    } else if name.ends_with(".json") {
+        Some(ArchiveContainer::Bundle)
+    } else {
+        None
+    }
 }
 
 /// Result of importing a single file: pads created + any per-field warnings.
@@ -417,7 +521,12 @@ impl FileImportResult {
     }
 }
 
-fn import_file<S: DataStore>(store: &mut S, scope: Scope, path: &Path) -> Result<FileImportResult> {
+fn import_file<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    path: &Path,
+    options: ImportOptions,
+) -> Result<FileImportResult> {
     let content_raw = fs::read_to_string(path).map_err(PadzError::Io)?;
     let ext = path
         .extension()
@@ -425,7 +534,26 @@ fn import_file<S: DataStore>(store: &mut S, scope: Scope, path: &Path) -> Result
         .map(str::to_ascii_lowercase)
         .unwrap_or_default();
     let label = path.display().to_string();
-    import_content(store, scope, &content_raw, &ext, &label)
+    let file = FileFacts {
+        modified: fs::metadata(path)
+            .and_then(|metadata| metadata.modified())
+            .ok()
+            .map(DateTime::<Utc>::from),
+        title: path
+            .file_stem()
+            .filter(|_| options.title_from_filename)
+            .map(|stem| stem.to_string_lossy().into_owned()),
+    };
+    import_content(store, scope, &content_raw, &ext, &label, &file)
+}
+
+/// What a pad takes from the file it is imported from, besides the text.
+#[derive(Default)]
+struct FileFacts {
+    /// When the file was last modified: the pad's creation time.
+    modified: Option<DateTime<Utc>>,
+    /// The title, when it comes from the file name.
+    title: Option<String>,
 }
 
 fn import_content<S: DataStore>(
@@ -434,6 +562,7 @@ fn import_content<S: DataStore>(
     content_raw: &str,
     ext: &str,
     source_label: &str,
+    file: &FileFacts,
 ) -> Result<FileImportResult> {
     // Try inline metadata first, picking the dialect by file extension.
     let detected = match ext {
@@ -452,7 +581,17 @@ fn import_content<S: DataStore>(
             });
         };
 
-        let mut pad = Pad::new(title.clone(), strip_title_from_body(&normalized, &title));
+        let (title, body) = match &file.title {
+            Some(name) => (name.clone(), normalized),
+            None => {
+                let body = strip_title_from_body(&normalized, &title);
+                (title, body)
+            }
+        };
+        let mut pad = Pad::new(title.clone(), body);
+        if let Some(modified) = file.modified {
+            pad.metadata.created_at = modified;
+        }
         let warnings = apply_metadata_defensively(
             &mut pad,
             &metadata_value,
@@ -481,23 +620,30 @@ fn import_content<S: DataStore>(
     }
 
     // No inline metadata — plain content path (backwards compatible).
-    if let Some((title, body)) = crate::model::extract_title_and_body(content_raw) {
-        crate::commands::create::run(store, scope, title, body, None)?;
-        Ok(FileImportResult {
-            imported: 1,
-            warnings: Vec::new(),
-            inline_metadata: None,
-        })
-    } else {
-        Ok(FileImportResult {
+    let Some((title, body)) = crate::model::extract_title_and_body(content_raw) else {
+        return Ok(FileImportResult {
             imported: 0,
             warnings: Vec::new(),
             inline_metadata: None,
-        })
+        });
+    };
+    let (title, body) = match &file.title {
+        Some(name) => (name.clone(), content_raw.trim().to_string()),
+        None => (title, body),
+    };
+    let mut pad = Pad::new(title, body);
+    if let Some(modified) = file.modified {
+        pad.metadata.created_at = modified;
     }
+    store.save_pad(&pad, scope, Bucket::Active)?;
+    Ok(FileImportResult {
+        imported: 1,
+        warnings: Vec::new(),
+        inline_metadata: None,
+    })
 }
 
-/// Import a `.tar.gz` JSON archive.
+/// Import a JSON archive: a `.tar.gz`, a `.zip` or a bundle.
 ///
 /// Returns imported counts and ordered semantic diagnostics. The function is
 /// optimistic: one bad entry does not prevent independent entries from landing.
@@ -505,34 +651,26 @@ fn import_json_archive<S: DataStore>(
     store: &mut S,
     scope: Scope,
     archive_path: &Path,
+    progress: &dyn Progress,
+    cancel: &Cancellation,
 ) -> Result<ArchiveImportResult> {
-    let file = fs::File::open(archive_path).map_err(PadzError::Io)?;
-    let decoder = GzDecoder::new(file);
-    let mut tar = tar::Archive::new(decoder);
-
     // 1. Extract all entries into memory.
     //
     // Pads are small enough that streaming to disk first would add complexity
     // without meaningful savings. Keep everything keyed by archive-relative
-    // path so we can cross-reference `db.json` against the pad files.
-    let mut files: HashMap<String, Vec<u8>> = HashMap::new();
-    for entry in tar
-        .entries()
-        .map_err(|error| PadzError::Api(format!("Invalid archive: {error}")))?
-    {
-        let mut entry =
-            entry.map_err(|error| PadzError::Api(format!("Invalid archive: {error}")))?;
-        let archive_path = entry
-            .path()
-            .map_err(|error| PadzError::Api(format!("Invalid archive path: {error}")))?
-            .to_string_lossy()
-            .to_string();
-        let mut buf = Vec::new();
-        entry
-            .read_to_end(&mut buf)
-            .map_err(|error| PadzError::Api(format!("Invalid archive entry: {error}")))?;
-        files.insert(archive_path, buf);
-    }
+    // path so we can cross-reference `db.json` against the pad files. A
+    // bundle is `db.json` alone.
+    let files: HashMap<String, Vec<u8>> = match archive_container(archive_path) {
+        Some(ArchiveContainer::Zip) => {
+            let bytes = fs::read(archive_path).map_err(PadzError::Io)?;
+            super::zip::read_entries(&bytes)?.into_iter().collect()
+        }
+        Some(ArchiveContainer::Bundle) => {
+            let bytes = fs::read(archive_path).map_err(PadzError::Io)?;
+            HashMap::from([("db.json".to_string(), bytes)])
+        }
+        _ => read_tar_gz(archive_path)?,
+    };
 
     // 2. Parse db.json. Accept either `padz/db.json` or bare `db.json`.
     let db_bytes = files
@@ -550,7 +688,8 @@ fn import_json_archive<S: DataStore>(
     let archive_ids: HashSet<Uuid> = archive.pads.iter().filter_map(pad_id_from_entry).collect();
 
     // 4. Import each pad entry.
-    for entry in &archive.pads {
+    for entry in track(progress, "Importing archive", archive.pads.iter()) {
+        cancel.check()?;
         match import_pad_entry(store, scope, entry, &files, &archive_ids) {
             Ok((id, entry_warnings)) => {
                 imported += 1;
@@ -635,6 +774,31 @@ fn import_json_archive<S: DataStore>(
     })
 }
 
+/// Every entry of the `.tar.gz` at `path`, by archive-relative path.
+fn read_tar_gz(path: &Path) -> Result<HashMap<String, Vec<u8>>> {
+    let file = fs::File::open(path).map_err(PadzError::Io)?;
+    let mut tar = tar::Archive::new(GzDecoder::new(file));
+    let mut files = HashMap::new();
+    for entry in tar
+        .entries()
+        .map_err(|error| PadzError::Api(format!("Invalid archive: {error}")))?
+    {
+        let mut entry =
+            entry.map_err(|error| PadzError::Api(format!("Invalid archive: {error}")))?;
+        let archive_path = entry
+            .path()
+            .map_err(|error| PadzError::Api(format!("Invalid archive path: {error}")))?
+            .to_string_lossy()
+            .to_string();
+        let mut buf = Vec::new();
+        entry
+            .read_to_end(&mut buf)
+            .map_err(|error| PadzError::Api(format!("Invalid archive entry: {error}")))?;
+        files.insert(archive_path, buf);
+    }
+    Ok(files)
+}
+
 struct ArchiveImportResult {
     imported: usize,
     diagnostics: Vec<ImportDiagnostic>,
@@ -659,10 +823,17 @@ fn import_pad_entry<S: DataStore>(
     archive_ids: &HashSet<Uuid>,
 ) -> std::result::Result<(Uuid, Vec<MetadataApplicationWarning>), ArchiveEntryError> {
     // Resolve file: db.json uses relative paths ("pads/pad-<uuid>.lex"); tar
-    // entries include the "padz/" prefix.
-    let content_bytes = files
-        .get(&format!("padz/{}", entry.file))
-        .or_else(|| files.get(&entry.file))
+    // entries include the "padz/" prefix. A bundle carries the text inline.
+    let content_bytes = entry
+        .content
+        .as_ref()
+        .map(String::as_bytes)
+        .or_else(|| {
+            files
+                .get(&format!("padz/{}", entry.file))
+                .map(Vec::as_slice)
+        })
+        .or_else(|| files.get(&entry.file).map(Vec::as_slice))
         .ok_or_else(|| ArchiveEntryError::MissingFile(entry.file.clone()))?;
 
     let raw = std::str::from_utf8(content_bytes)
@@ -731,6 +902,7 @@ pub(crate) fn strip_title_from_body(normalized: &str, title: &str) -> String {
 mod tests {
     use super::*;
     use crate::model::Scope;
+    use crate::progress::NoProgress;
     use crate::store::bucketed::BucketedStore;
     use crate::store::mem_backend::MemBackend;
 
@@ -748,7 +920,7 @@ mod tests {
         scope: Scope,
         raw: &str,
     ) -> FileImportResult {
-        import_content(store, scope, raw, "", "<test>").unwrap()
+        import_content(store, scope, raw, "", "<test>", &FileFacts::default()).unwrap()
     }
 
     #[test]
@@ -793,6 +965,8 @@ mod tests {
             Scope::Project,
             vec![temp_dir.path().to_path_buf()],
             &[".md".to_string(), ".txt".to_string()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -846,7 +1020,9 @@ mod tests {
                 Err(std::io::Error::other("entry vanished while listing")),
             ],
             &[".md".to_string()],
-        );
+            &Cancellation::new(),
+        )
+        .unwrap();
 
         assert_eq!(directory.imported, 1);
         assert_eq!(directory.processed_files, vec![imported_path]);
@@ -897,6 +1073,8 @@ mod tests {
             Scope::Project,
             vec![file_path],
             &[".md".to_string()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -922,6 +1100,8 @@ mod tests {
             Scope::Project,
             vec![invalid_path],
             &[".md".to_string()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -954,6 +1134,8 @@ mod tests {
             Scope::Project,
             vec![file_path],
             &[".md".to_string()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -974,6 +1156,8 @@ mod tests {
             Scope::Project,
             vec![temp_dir.path().to_path_buf()],
             &[".md".to_string()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -989,7 +1173,15 @@ mod tests {
     fn test_import_empty_paths_list() {
         let mut store = new_store();
 
-        let res = run(&mut store, Scope::Project, vec![], &[".md".to_string()]).unwrap();
+        let res = run(
+            &mut store,
+            Scope::Project,
+            vec![],
+            &[".md".to_string()],
+            &NoProgress,
+            &Cancellation::new(),
+        )
+        .unwrap();
 
         assert_eq!(res.status, ImportStatus::NoImports);
         assert!(res.sources.is_empty());
@@ -1014,6 +1206,8 @@ mod tests {
             Scope::Project,
             vec![valid_file, invalid_file],
             &[".md".to_string()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -1044,6 +1238,8 @@ mod tests {
             Scope::Project,
             vec![temp_dir.path().to_path_buf()],
             &[".md".to_string()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -1056,6 +1252,52 @@ mod tests {
         assert_eq!(pads[0].metadata.title, "Root");
     }
 
+    #[test]
+    fn recursive_import_takes_the_tree_file_names_and_modification_times() {
+        let mut store = new_store();
+        let temp_dir = tempfile::tempdir().unwrap();
+        let sub_dir = temp_dir.path().join("2023");
+        std::fs::create_dir_all(temp_dir.path().join(".git")).unwrap();
+        std::fs::create_dir(&sub_dir).unwrap();
+        std::fs::write(temp_dir.path().join(".git").join("HEAD.md"), "No").unwrap();
+        std::fs::write(temp_dir.path().join("root.md"), "Root\n\nContent").unwrap();
+        let nested = sub_dir.join("standup.md");
+        std::fs::write(&nested, "monday: deploy").unwrap();
+        let modified =
+            std::time::SystemTime::UNIX_EPOCH + std::time::Duration::from_secs(1_700_000_000);
+        std::fs::File::options()
+            .write(true)
+            .open(&nested)
+            .unwrap()
+            .set_modified(modified)
+            .unwrap();
+
+        let options = ImportOptions {
+            recursive: true,
+            title_from_filename: true,
+        };
+        let res = run_with_options(
+            &mut store,
+            Scope::Project,
+            vec![temp_dir.path().to_path_buf()],
+            &[".md".to_string()],
+            options,
+            &NoProgress,
+            &Cancellation::new(),
+        )
+        .unwrap();
+        assert_eq!(res.total_imported, 2);
+
+        let pads = store.list_pads(Scope::Project, Bucket::Active).unwrap();
+        let standup = pads
+            .iter()
+            .find(|p| p.metadata.title == "standup")
+            .expect("titled after its file");
+        assert!(standup.content.contains("monday: deploy"));
+        assert_eq!(standup.metadata.created_at, DateTime::<Utc>::from(modified));
+        assert!(pads.iter().any(|p| p.metadata.title == "root"));
+    }
+
     #[test]
     fn test_import_directory_file_with_empty_content() {
         let mut store = new_store();
@@ -1070,6 +1312,8 @@ mod tests {
             Scope::Project,
             vec![temp_dir.path().to_path_buf()],
             &[".md".to_string()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -1108,7 +1352,15 @@ mod tests {
         let path = temp.path().join("alpha.md");
         std::fs::write(&path, body).unwrap();
 
-        let res = run(&mut store, Scope::Project, vec![path], &[".md".into()]).unwrap();
+        let res = run(
+            &mut store,
+            Scope::Project,
+            vec![path],
+            &[".md".into()],
+            &NoProgress,
+            &Cancellation::new(),
+        )
+        .unwrap();
         assert_eq!(res.total_imported, 1);
         assert!(matches!(
             res.sources[0].diagnostics[0],
@@ -1145,7 +1397,15 @@ mod tests {
         let path = temp.path().join("beta.lex");
         std::fs::write(&path, body).unwrap();
 
-        let res = run(&mut store, Scope::Project, vec![path], &[".lex".into()]).unwrap();
+        let res = run(
+            &mut store,
+            Scope::Project,
+            vec![path],
+            &[".lex".into()],
+            &NoProgress,
+            &Cancellation::new(),
+        )
+        .unwrap();
         assert_eq!(res.total_imported, 1);
         assert!(matches!(
             res.sources[0].diagnostics[0],
@@ -1171,7 +1431,15 @@ mod tests {
         let path = temp.path().join("plain.md");
         std::fs::write(&path, "# Heading\n\nBody").unwrap();
 
-        run(&mut store, Scope::Project, vec![path], &[".md".into()]).unwrap();
+        run(
+            &mut store,
+            Scope::Project,
+            vec![path],
+            &[".md".into()],
+            &NoProgress,
+            &Cancellation::new(),
+        )
+        .unwrap();
 
         let pads = store.list_pads(Scope::Project, Bucket::Active).unwrap();
         assert_eq!(pads.len(), 1);
@@ -1187,7 +1455,15 @@ mod tests {
         let path = temp.path().join("blog.md");
         std::fs::write(&path, body).unwrap();
 
-        run(&mut store, Scope::Project, vec![path], &[".md".into()]).unwrap();
+        run(
+            &mut store,
+            Scope::Project,
+            vec![path],
+            &[".md".into()],
+            &NoProgress,
+            &Cancellation::new(),
+        )
+        .unwrap();
 
         // No padz.* keys -> treat as plain content; the frontmatter stays in
         // the body because our detector only fires when padz.* keys exist.
@@ -1207,7 +1483,15 @@ mod tests {
         let path = temp.path().join("bad.md");
         std::fs::write(&path, body).unwrap();
 
-        let res = run(&mut store, Scope::Project, vec![path], &[".md".into()]).unwrap();
+        let res = run(
+            &mut store,
+            Scope::Project,
+            vec![path],
+            &[".md".into()],
+            &NoProgress,
+            &Cancellation::new(),
+        )
+        .unwrap();
 
         assert_eq!(res.status, ImportStatus::PartialSuccess);
         assert!(res.sources[0].diagnostics.iter().any(|diagnostic| matches!(
@@ -1246,7 +1530,15 @@ mod tests {
     ) -> std::path::PathBuf {
         use std::io::Write as _;
 
-        let outcome = export::run_json(store, scope, selectors, NestingMode::Tree).unwrap();
+        let outcome = export::run_json(
+            store,
+            scope,
+            selectors,
+            NestingMode::Tree,
+            &NoProgress,
+            &Cancellation::new(),
+        )
+        .unwrap();
         let export::ExportOutcome::Artifact(artifact) = outcome else {
             panic!("expected JSON export artifact");
         };
@@ -1306,6 +1598,8 @@ mod tests {
             Scope::Project,
             vec![archive.clone()],
             &[".txt".into()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -1345,6 +1639,8 @@ mod tests {
             Scope::Project,
             vec![archive.clone()],
             &[".txt".into()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -1403,6 +1699,8 @@ mod tests {
             Scope::Project,
             vec![archive_path.clone()],
             &[".md".into(), ".txt".into(), ".lex".into()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
         assert_eq!(res.status, ImportStatus::FullSuccess);
@@ -1433,6 +1731,72 @@ mod tests {
         std::fs::remove_file(archive_path).ok();
     }
 
+    #[test]
+    fn zip_and_bundle_exports_round_trip_like_the_tar_archive() {
+        use std::io::Write as _;
+
+        let mut src = new_store();
+        create::run(
+            &mut src,
+            Scope::Project,
+            "Alpha".into(),
+            "body".into(),
+            None,
+        )
+        .unwrap();
+        let mut pad = src
+            .list_pads(Scope::Project, Bucket::Active)
+            .unwrap()
+            .remove(0);
+        pad.metadata.is_pinned = true;
+        pad.metadata.pinned_at = Some(Utc::now());
+        src.save_pad(&pad, Scope::Project, Bucket::Active).unwrap();
+
+        for (format, suffix) in [
+            (export::ArchiveFormat::Zip, ".zip"),
+            (export::ArchiveFormat::Json, ".json"),
+        ] {
+            let options = export::ArchiveOptions {
+                format,
+                project: Some("/home/me/app".into()),
+            };
+            let outcome = export::run_archive(
+                &src,
+                Scope::Project,
+                &[],
+                NestingMode::Tree,
+                &options,
+                &NoProgress,
+                &Cancellation::new(),
+            )
+            .unwrap();
+            let export::ExportOutcome::Artifact(artifact) = outcome else {
+                panic!("expected an export artifact");
+            };
+            assert!(artifact.suggested_filename.ends_with(suffix));
+            let mut temp = tempfile::NamedTempFile::with_suffix(suffix).unwrap();
+            temp.write_all(&artifact.bytes).unwrap();
+
+            let mut dst = new_store();
+            let res = run(
+                &mut dst,
+                Scope::Project,
+                vec![temp.path().to_path_buf()],
+                &[".md".into(), ".txt".into()],
+                &NoProgress,
+                &Cancellation::new(),
+            )
+            .unwrap();
+            assert_eq!(res.status, ImportStatus::FullSuccess, "{suffix}");
+            assert_eq!(res.sources[0].source_kind, ImportSourceKind::JsonArchive);
+            let pads = dst.list_pads(Scope::Project, Bucket::Active).unwrap();
+            assert_eq!(pads[0].metadata.id, pad.metadata.id);
+            assert_eq!(pads[0].metadata.created_at, pad.metadata.created_at);
+            assert!(pads[0].metadata.is_pinned);
+            assert_eq!(pads[0].content, pad.content);
+        }
+    }
+
     #[test]
     fn test_json_roundtrip_preserves_parent() {
         let mut src = new_store();
@@ -1470,6 +1834,8 @@ mod tests {
             Scope::Project,
             vec![archive_path.clone()],
             &[".md".into()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -1516,6 +1882,8 @@ mod tests {
             Scope::Project,
             vec![archive_path.clone()],
             &[".md".into()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -1594,6 +1962,8 @@ mod tests {
             Scope::Project,
             vec![archive_path.clone()],
             &[".md".into()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
         assert_eq!(res.status, ImportStatus::PartialSuccess);
@@ -1652,6 +2022,8 @@ mod tests {
             Scope::Project,
             vec![archive_path.clone()],
             &[".md".into()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -1677,6 +2049,8 @@ mod tests {
             Scope::Project,
             vec![file_path],
             &[".md".to_string()],
+            &NoProgress,
+            &Cancellation::new(),
         )
         .unwrap();
 
@@ -2,8 +2,16 @@
 //!
 //! This module groups the two file-IO commands together because they share
 //! archive schema, inline-metadata serialization, and roundtrip invariants.
+//! [`compile`] reuses export's single-file layout to turn tagged pads into a
+//! curated document, and [`csv`] writes the selected pads' metadata as a
+//! spreadsheet. [`print`] lays pads out on paper, as PostScript or PDF.
+//! [`zip`] is the container behind `export --format zip`.
 //!
 //! For moving pads *between stores* (clone/migrate), see [`crate::commands::transfer`].
 
+pub mod compile;
+pub mod csv;
 pub mod export;
 pub mod import;
+pub mod print;
+mod zip;
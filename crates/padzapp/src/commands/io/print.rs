@@ -0,0 +1,936 @@
+//! A pad laid out on paper (`padz print`), as PostScript or PDF.
+//!
+//! For the checklists that are easier to tick with a pen: the pad's markdown is
+//! read line by line and set in the printer's own Courier, so nothing has to
+//! be embedded and every line wraps at a known width. The title and `#`
+//! headings are set large and bold, list items hang from a bullet or their
+//! number, `- [ ]` tasks get an empty box to tick (`- [x]` a ticked one),
+//! quotes lean and fenced code keeps its spacing. Line breaks are kept as
+//! written, since a scratch is more often a list of lines than flowing prose;
+//! inline `**`, `__` and backticks are dropped.
+//!
+//! Each pad starts on a new page, and every page is footed with its pad's
+//! title and the page number. The layout is built once as [`Mark`]s on pages
+//! and written out by whichever format the destination asks for.
+//!
+//! Text is Latin-1, the standard fonts' encoding; typographic quotes and
+//! dashes become their plain forms, and anything else outside it a `?`.
+
+use super::export::{ExportArtifact, ExportFormat, ExportOutcome, ExportReport};
+use crate::commands::helpers::{pads_by_selectors, TitleBucket};
+use crate::error::Result;
+use crate::index::PadSelector;
+use crate::model::{Pad, Scope};
+use crate::store::DataStore;
+use std::fmt::Write as _;
+
+/// What the document is written as.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum PrintFormat {
+    /// What `lpr` takes, and the default.
+    PostScript,
+    Pdf,
+}
+
+impl PrintFormat {
+    /// `.pdf` writes PDF, anything else PostScript.
+    pub fn from_filename(filename: &str) -> Self {
+        if filename.to_lowercase().ends_with(".pdf") {
+            PrintFormat::Pdf
+        } else {
+            PrintFormat::PostScript
+        }
+    }
+}
+
+/// The sheet the document is laid out on.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub enum Paper {
+    #[default]
+    A4,
+    Letter,
+}
+
+impl Paper {
+    /// Width and height in points.
+    fn size(self) -> (f32, f32) {
+        match self {
+            Paper::A4 => (595.0, 842.0),
+            Paper::Letter => (612.0, 792.0),
+        }
+    }
+}
+
+#[derive(Debug, Clone)]
+pub struct PrintOptions {
+    pub format: PrintFormat,
+    pub paper: Paper,
+    /// The suggested destination.
+    pub destination: String,
+}
+
+pub fn run<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    options: &PrintOptions,
+) -> Result<ExportOutcome> {
+    let mut pads: Vec<Pad> = Vec::new();
+    for dp in pads_by_selectors(store, scope, selectors, false, TitleBucket::Active)? {
+        if !pads.iter().any(|p| p.metadata.id == dp.pad.metadata.id) {
+            pads.push(dp.pad);
+        }
+    }
+    if pads.is_empty() {
+        return Ok(ExportOutcome::Empty {
+            format: ExportFormat::Printed,
+        });
+    }
+
+    let document = Document::lay_out(&pads, options.paper);
+    let bytes = match options.format {
+        PrintFormat::PostScript => document.postscript(),
+        PrintFormat::Pdf => document.pdf(),
+    };
+    Ok(ExportOutcome::Artifact(ExportArtifact {
+        bytes,
+        suggested_filename: options.destination.clone(),
+        report: ExportReport {
+            format: ExportFormat::Printed,
+            exported: pads.len(),
+            warnings: Vec::new(),
+            signed: None,
+            streamed: None,
+            printed: None,
+        },
+    }))
+}
+
+const MARGIN: f32 = 54.0;
+const BODY: f32 = 11.0;
+const CODE: f32 = 10.0;
+const FOOTER: f32 = 8.0;
+const LEADING: f32 = 1.35;
+/// Every Courier glyph is this wide, in ems.
+const ADVANCE: f32 = 0.6;
+/// How far each level of list nesting steps in.
+const STEP: f32 = BODY * 1.8;
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum Font {
+    Regular,
+    Bold,
+    Oblique,
+}
+
+impl Font {
+    const ALL: [Font; 3] = [Font::Regular, Font::Bold, Font::Oblique];
+
+    fn base(self) -> &'static str {
+        match self {
+            Font::Regular => "Courier",
+            Font::Bold => "Courier-Bold",
+            Font::Oblique => "Courier-Oblique",
+        }
+    }
+
+    fn key(self) -> &'static str {
+        match self {
+            Font::Regular => "F0",
+            Font::Bold => "F1",
+            Font::Oblique => "F2",
+        }
+    }
+}
+
+/// Something drawn on a page, in points from its bottom-left corner.
+#[derive(Debug, Clone, PartialEq)]
+enum Mark {
+    /// Latin-1 text starting on the baseline at `x`, `y`.
+    Text {
+        x: f32,
+        y: f32,
+        font: Font,
+        size: f32,
+        text: String,
+    },
+    /// A task's box, its lower-left corner at `x`, `y`.
+    Box {
+        x: f32,
+        y: f32,
+        side: f32,
+        ticked: bool,
+    },
+    /// A bullet.
+    Dot { x: f32, y: f32, radius: f32 },
+    Line {
+        from: (f32, f32),
+        to: (f32, f32),
+        width: f32,
+    },
+}
+
+/// One line of a pad's markdown, as it is set.
+#[derive(Debug, PartialEq)]
+enum Block<'a> {
+    Heading(usize, &'a str),
+    Item {
+        depth: usize,
+        marker: Marker,
+        text: &'a str,
+    },
+    Quote(&'a str),
+    Code(&'a str),
+    Rule,
+    Text(&'a str),
+    Blank,
+}
+
+#[derive(Debug, PartialEq)]
+enum Marker {
+    Bullet,
+    Task(bool),
+    Number(String),
+}
+
+/// Reads a pad's body (the lines after its title).
+fn blocks(body: &str) -> Vec<Block<'_>> {
+    let mut blocks = Vec::new();
+    let mut fenced = false;
+    for line in body.lines() {
+        let trimmed = line.trim_start();
+        if trimmed.starts_with("```") || trimmed.starts_with("~~~") {
+            fenced = !fenced;
+            continue;
+        }
+        if fenced {
+            blocks.push(Block::Code(line));
+            continue;
+        }
+        if trimmed.is_empty() {
+            blocks.push(Block::Blank);
+            continue;
+        }
+        let depth = indentation(line) / 2;
+        let hashes = trimmed.chars().take_while(|c| *c == '#').count();
+        if (1..=6).contains(&hashes) && trimmed[hashes..].starts_with(' ') {
+            blocks.push(Block::Heading(hashes, trimmed[hashes..].trim()));
+        } else if is_rule(trimmed) {
+            blocks.push(Block::Rule);
+        } else if let Some(quote) = trimmed.strip_prefix('>') {
+            blocks.push(Block::Quote(quote.trim_start()));
+        } else if let Some((marker, text)) = item(trimmed) {
+            blocks.push(Block::Item {
+                depth,
+                marker,
+                text,
+            });
+        } else {
+            blocks.push(Block::Text(trimmed));
+        }
+    }
+    blocks
+}
+
+/// Leading whitespace in columns, a tab counting as four.
+fn indentation(line: &str) -> usize {
+    line.chars()
+        .take_while(|c| c.is_whitespace())
+        .map(|c| if c == '\t' { 4 } else { 1 })
+        .sum()
+}
+
+fn is_rule(line: &str) -> bool {
+    let compact: String = line.chars().filter(|c| !c.is_whitespace()).collect();
+    compact.len() >= 3
+        && ['-', '*', '_']
+            .iter()
+            .any(|m| compact.chars().all(|c| c == *m))
+}
+
+/// A list item's marker and the text after it.
+fn item(line: &str) -> Option<(Marker, &str)> {
+    if let Some(rest) = ["- ", "* ", "+ "].iter().find_map(|m| line.strip_prefix(m)) {
+        for (task, ticked) in [("[ ]", false), ("[x]", true), ("[X]", true)] {
+            if let Some(text) = rest.strip_prefix(task) {
+                return Some((Marker::Task(ticked), text.trim_start()));
+            }
+        }
+        return Some((Marker::Bullet, rest.trim_start()));
+    }
+    let digits = line.chars().take_while(|c| c.is_ascii_digit()).count();
+    if digits > 0 {
+        let rest = &line[digits..];
+        if let Some(text) = rest.strip_prefix(". ").or_else(|| rest.strip_prefix(") ")) {
+            return Some((
+                Marker::Number(line[..=digits].to_string()),
+                text.trim_start(),
+            ));
+        }
+    }
+    None
+}
+
+/// `text` without its inline markup, in the fonts' Latin-1.
+fn plain(text: &str) -> String {
+    latin1(&text.replace("**", "").replace("__", "").replace('`', ""))
+}
+
+fn latin1(text: &str) -> String {
+    let mut out = String::with_capacity(text.len());
+    for c in text.chars() {
+        match c {
+            '\t' => out.push_str("    "),
+            '‘' | '’' => out.push('\''),
+            '“' | '”' => out.push('"'),
+            '–' | '—' | '−' => out.push('-'),
+            '…' => out.push_str("..."),
+            '•' => out.push('*'),
+            '\u{20}'..='\u{7e}' | '\u{a0}'..='\u{ff}' => out.push(c),
+            _ => out.push('?'),
+        }
+    }
+    out
+}
+
+/// Breaks `text` into lines of at most `columns` characters, between words
+/// where it can.
+fn wrap(text: &str, columns: usize) -> Vec<String> {
+    let columns = columns.max(1);
+    let mut lines = Vec::new();
+    let mut line = String::new();
+    for word in text.split(' ') {
+        let mut word: Vec<char> = word.chars().collect();
+        let len = line.chars().count();
+        if len > 0 && len + 1 + word.len() > columns {
+            lines.push(std::mem::take(&mut line));
+        }
+        if !line.is_empty() {
+            line.push(' ');
+        }
+        while line.chars().count() + word.len() > columns {
+            let room = columns - line.chars().count();
+            line.extend(word.drain(..room));
+            lines.push(std::mem::take(&mut line));
+        }
+        line.extend(word);
+    }
+    lines.push(line);
+    lines
+}
+
+/// The laid-out pages, each with the title of the pad it belongs to.
+struct Document {
+    width: f32,
+    height: f32,
+    pages: Vec<(String, Vec<Mark>)>,
+    /// The baseline of the last line set.
+    y: f32,
+}
+
+impl Document {
+    fn lay_out(pads: &[Pad], paper: Paper) -> Self {
+        let (width, height) = paper.size();
+        let mut document = Document {
+            width,
+            height,
+            pages: Vec::new(),
+            y: 0.0,
+        };
+        for pad in pads {
+            // The content's first line is the title in full; the metadata's,
+            // shortened, foots the pages.
+            let (title, body) = pad
+                .content
+                .split_once('\n')
+                .unwrap_or((pad.content.as_str(), ""));
+            document.page(latin1(&pad.metadata.title));
+            document.heading(1, title.trim_start_matches('#').trim());
+            for block in blocks(body) {
+                document.block(block);
+            }
+        }
+        document
+    }
+
+    fn page(&mut self, title: String) {
+        self.pages.push((title, Vec::new()));
+        self.y = self.height - MARGIN;
+    }
+
+    /// Makes room for a line `height` tall and returns its baseline, on a new
+    /// page when this one is full.
+    fn advance(&mut self, height: f32) -> f32 {
+        if self.y - height < MARGIN {
+            let title = self
+                .pages
+                .last()
+                .map(|(t, _)| t.clone())
+                .unwrap_or_default();
+            self.page(title);
+        }
+        self.y -= height;
+        self.y
+    }
+
+    fn mark(&mut self, mark: Mark) {
+        self.mark_on(self.pages.len() - 1, mark);
+    }
+
+    fn mark_on(&mut self, page: usize, mark: Mark) {
+        if let Some((_, marks)) = self.pages.get_mut(page) {
+            marks.push(mark);
+        }
+    }
+
+    fn text(&mut self, x: f32, y: f32, font: Font, size: f32, text: String) {
+        if !text.is_empty() {
+            self.mark(Mark::Text {
+                x,
+                y,
+                font,
+                size,
+                text,
+            });
+        }
+    }
+
+    /// Sets `text` from `x`, wrapped to the right margin; returns the page
+    /// and baseline of its first line, where a list marker goes.
+    fn paragraph(&mut self, x: f32, font: Font, size: f32, text: &str) -> (usize, f32) {
+        let columns = ((self.width - MARGIN - x) / (size * ADVANCE)) as usize;
+        let mut first = None;
+        for line in wrap(text, columns) {
+            let y = self.advance(size * LEADING);
+            first.get_or_insert((self.pages.len() - 1, y));
+            self.text(x, y, font, size, line);
+        }
+        first.unwrap_or((self.pages.len() - 1, self.y))
+    }
+
+    fn heading(&mut self, level: usize, text: &str) {
+        let size = match level {
+            1 => 18.0,
+            2 => 15.0,
+            3 => 13.0,
+            _ => BODY,
+        };
+        self.advance(BODY * 0.4);
+        self.paragraph(MARGIN, Font::Bold, size, &plain(text));
+        self.advance(BODY * 0.2);
+    }
+
+    fn block(&mut self, block: Block) {
+        match block {
+            Block::Heading(level, text) => self.heading(level, text),
+            Block::Text(text) => {
+                self.paragraph(MARGIN, Font::Regular, BODY, &plain(text));
+            }
+            Block::Blank => {
+                self.advance(BODY * 0.6);
+            }
+            Block::Code(line) => {
+                self.paragraph(MARGIN + STEP / 2.0, Font::Regular, CODE, &latin1(line));
+            }
+            Block::Quote(text) => {
+                let pages = self.pages.len();
+                let top = self.y;
+                self.paragraph(MARGIN + STEP / 2.0, Font::Oblique, BODY, &plain(text));
+                // A quote that ran onto a new page is barred from its top.
+                let top = if self.pages.len() > pages {
+                    self.height - MARGIN
+                } else {
+                    top
+                };
+                self.mark(Mark::Line {
+                    from: (MARGIN + 2.0, top - BODY * 0.3),
+                    to: (MARGIN + 2.0, self.y - BODY * 0.3),
+                    width: 1.5,
+                });
+            }
+            Block::Rule => {
+                let y = self.advance(BODY) + BODY * 0.35;
+                self.mark(Mark::Line {
+                    from: (MARGIN, y),
+                    to: (self.width - MARGIN, y),
+                    width: 0.5,
+                });
+            }
+            Block::Item {
+                depth,
+                marker,
+                text,
+            } => {
+                let x = MARGIN + depth as f32 * STEP;
+                let text = plain(text);
+                match marker {
+                    Marker::Bullet => {
+                        let (page, y) = self.paragraph(x + STEP, Font::Regular, BODY, &text);
+                        self.mark_on(
+                            page,
+                            Mark::Dot {
+                                x: x + BODY * 0.6,
+                                y: y + BODY * 0.3,
+                                radius: BODY * 0.17,
+                            },
+                        );
+                    }
+                    Marker::Task(ticked) => {
+                        let (page, y) = self.paragraph(x + STEP, Font::Regular, BODY, &text);
+                        let side = BODY * 0.8;
+                        self.mark_on(
+                            page,
+                            Mark::Box {
+                                x: x + BODY * 0.2,
+                                y: y - BODY * 0.1,
+                                side,
+                                ticked,
+                            },
+                        );
+                    }
+                    Marker::Number(number) => {
+                        let (page, y) = self.paragraph(x + STEP, Font::Regular, BODY, &text);
+                        self.mark_on(
+                            page,
+                            Mark::Text {
+                                x,
+                                y,
+                                font: Font::Regular,
+                                size: BODY,
+                                text: number,
+                            },
+                        );
+                    }
+                }
+            }
+        }
+    }
+
+    /// Each page's marks, then its footer: the pad's title and `n / total`.
+    fn pages(&self) -> Vec<Vec<Mark>> {
+        let total = self.pages.len();
+        self.pages
+            .iter()
+            .enumerate()
+            .map(|(i, (title, marks))| {
+                let mut marks = marks.clone();
+                let y = MARGIN / 2.0;
+                marks.push(Mark::Text {
+                    x: MARGIN,
+                    y,
+                    font: Font::Regular,
+                    size: FOOTER,
+                    text: title.clone(),
+                });
+                let number = format!("{} / {}", i + 1, total);
+                let x = self.width - MARGIN - number.len() as f32 * FOOTER * ADVANCE;
+                marks.push(Mark::Text {
+                    x,
+                    y,
+                    font: Font::Regular,
+                    size: FOOTER,
+                    text: number,
+                });
+                marks
+            })
+            .collect()
+    }
+
+    fn postscript(&self) -> Vec<u8> {
+        let pages = self.pages();
+        let (w, h) = (self.width, self.height);
+        let mut out = String::new();
+        let _ = write!(
+            out,
+            "%!PS-Adobe-3.0\n\
+             %%Creator: padz\n\
+             %%Pages: {}\n\
+             %%BoundingBox: 0 0 {} {}\n\
+             %%EndComments\n\
+             %%BeginProlog\n\
+             /latin1 {{ findfont dup length dict begin\n\
+             {{ 1 index /FID ne {{ def }} {{ pop pop }} ifelse }} forall\n\
+             /Encoding ISOLatin1Encoding def currentdict end definefont pop }} bind def\n",
+            pages.len(),
+            w,
+            h
+        );
+        for font in Font::ALL {
+            let _ = writeln!(out, "/{} /{} latin1", font.key(), font.base());
+        }
+        let _ = write!(
+            out,
+            "%%EndProlog\n\
+             %%BeginSetup\n\
+             << /PageSize [{} {}] >> setpagedevice\n\
+             %%EndSetup\n",
+            w, h
+        );
+        for (i, marks) in pages.iter().enumerate() {
+            let _ = writeln!(out, "%%Page: {} {}", i + 1, i + 1);
+            for mark in marks {
+                match mark {
+                    Mark::Text {
+                        x,
+                        y,
+                        font,
+                        size,
+                        text,
+                    } => {
+                        let _ = writeln!(
+                            out,
+                            "/{} {} selectfont {:.1} {:.1} moveto {} show",
+                            font.key(),
+                            size,
+                            x,
+                            y,
+                            string(text)
+                        );
+                    }
+                    Mark::Box { x, y, side, ticked } => {
+                        let _ = writeln!(
+                            out,
+                            "0.8 setlinewidth {:.1} {:.1} {:.1} {:.1} rectstroke",
+                            x, y, side, side
+                        );
+                        if *ticked {
+                            let [a, b, c] = tick(*x, *y, *side);
+                            let _ = writeln!(
+                                out,
+                                "1.2 setlinewidth newpath {:.1} {:.1} moveto {:.1} {:.1} lineto {:.1} {:.1} lineto stroke",
+                                a.0, a.1, b.0, b.1, c.0, c.1
+                            );
+                        }
+                    }
+                    Mark::Dot { x, y, radius } => {
+                        let _ = writeln!(
+                            out,
+                            "newpath {:.1} {:.1} {:.1} 0 360 arc fill",
+                            x, y, radius
+                        );
+                    }
+                    Mark::Line { from, to, width } => {
+                        let _ = writeln!(
+                            out,
+                            "{} setlinewidth newpath {:.1} {:.1} moveto {:.1} {:.1} lineto stroke",
+                            width, from.0, from.1, to.0, to.1
+                        );
+                    }
+                }
+            }
+            out.push_str("showpage\n");
+        }
+        out.push_str("%%Trailer\n%%EOF\n");
+        out.into_bytes()
+    }
+
+    fn pdf(&self) -> Vec<u8> {
+        let pages = self.pages();
+        // 1 is the catalog, 2 the page tree, 3..=5 the fonts; then each page
+        // and its content stream.
+        let first_page = 3 + Font::ALL.len();
+        let mut objects: Vec<Vec<u8>> = Vec::new();
+        let kids: Vec<String> = (0..pages.len())
+            .map(|i| format!("{} 0 R", first_page + 2 * i))
+            .collect();
+        objects.push(b"<< /Type /Catalog /Pages 2 0 R >>".to_vec());
+        objects.push(
+            format!(
+                "<< /Type /Pages /Kids [{}] /Count {} >>",
+                kids.join(" "),
+                pages.len()
+            )
+            .into_bytes(),
+        );
+        for font in Font::ALL {
+            objects.push(
+                format!(
+                    "<< /Type /Font /Subtype /Type1 /BaseFont /{} /Encoding /WinAnsiEncoding >>",
+                    font.base()
+                )
+                .into_bytes(),
+            );
+        }
+        let fonts: Vec<String> = Font::ALL
+            .iter()
+            .enumerate()
+            .map(|(i, font)| format!("/{} {} 0 R", font.key(), 3 + i))
+            .collect();
+        for (i, marks) in pages.iter().enumerate() {
+            objects.push(
+                format!(
+                    "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 {} {}] \
+                     /Resources << /Font << {} >> >> /Contents {} 0 R >>",
+                    self.width,
+                    self.height,
+                    fonts.join(" "),
+                    first_page + 2 * i + 1
+                )
+                .into_bytes(),
+            );
+            let content = pdf_content(marks);
+            let mut stream = format!("<< /Length {} >>\nstream\n", content.len()).into_bytes();
+            stream.extend(content);
+            stream.extend(b"\nendstream");
+            objects.push(stream);
+        }
+
+        let mut out = b"%PDF-1.4\n%\xe2\xe3\xcf\xd3\n".to_vec();
+        let mut offsets = Vec::with_capacity(objects.len());
+        for (i, object) in objects.iter().enumerate() {
+            offsets.push(out.len());
+            out.extend(format!("{} 0 obj\n", i + 1).into_bytes());
+            out.extend(object);
+            out.extend(b"\nendobj\n");
+        }
+        let xref = out.len();
+        let mut table = format!("xref\n0 {}\n0000000000 65535 f \n", objects.len() + 1);
+        for offset in offsets {
+            let _ = write!(table, "{:010} 00000 n \n", offset);
+        }
+        let _ = write!(
+            table,
+            "trailer\n<< /Size {} /Root 1 0 R >>\nstartxref\n{}\n%%EOF\n",
+            objects.len() + 1,
+            xref
+        );
+        out.extend(table.into_bytes());
+        out
+    }
+}
+
+/// The three points of a tick inside the box at `x`, `y`.
+fn tick(x: f32, y: f32, side: f32) -> [(f32, f32); 3] {
+    [
+        (x + side * 0.2, y + side * 0.5),
+        (x + side * 0.42, y + side * 0.2),
+        (x + side * 0.82, y + side * 0.85),
+    ]
+}
+
+/// A page's marks as a PDF content stream.
+fn pdf_content(marks: &[Mark]) -> Vec<u8> {
+    let mut out = String::new();
+    for mark in marks {
+        match mark {
+            Mark::Text {
+                x,
+                y,
+                font,
+                size,
+                text,
+            } => {
+                let _ = writeln!(
+                    out,
+                    "BT /{} {} Tf {:.1} {:.1} Td {} Tj ET",
+                    font.key(),
+                    size,
+                    x,
+                    y,
+                    string(text)
+                );
+            }
+            Mark::Box { x, y, side, ticked } => {
+                let _ = writeln!(out, "0.8 w {:.1} {:.1} {:.1} {:.1} re S", x, y, side, side);
+                if *ticked {
+                    let [a, b, c] = tick(*x, *y, *side);
+                    let _ = writeln!(
+                        out,
+                        "1.2 w {:.1} {:.1} m {:.1} {:.1} l {:.1} {:.1} l S",
+                        a.0, a.1, b.0, b.1, c.0, c.1
+                    );
+                }
+            }
+            Mark::Dot { x, y, radius } => {
+                // A circle is four Bézier quarters.
+                let k = radius * 0.5523;
+                let (r, x, y) = (*radius, *x, *y);
+                let _ = writeln!(
+                    out,
+                    "{:.2} {:.2} m \
+                     {:.2} {:.2} {:.2} {:.2} {:.2} {:.2} c \
+                     {:.2} {:.2} {:.2} {:.2} {:.2} {:.2} c \
+                     {:.2} {:.2} {:.2} {:.2} {:.2} {:.2} c \
+                     {:.2} {:.2} {:.2} {:.2} {:.2} {:.2} c f",
+                    x + r,
+                    y,
+                    x + r,
+                    y + k,
+                    x + k,
+                    y + r,
+                    x,
+                    y + r,
+                    x - k,
+                    y + r,
+                    x - r,
+                    y + k,
+                    x - r,
+                    y,
+                    x - r,
+                    y - k,
+                    x - k,
+                    y - r,
+                    x,
+                    y - r,
+                    x + k,
+                    y - r,
+                    x + r,
+                    y - k,
+                    x + r,
+                    y
+                );
+            }
+            Mark::Line { from, to, width } => {
+                let _ = writeln!(
+                    out,
+                    "{} w {:.1} {:.1} m {:.1} {:.1} l S",
+                    width, from.0, from.1, to.0, to.1
+                );
+            }
+        }
+    }
+    out.into_bytes()
+}
+
+/// `text` as a string literal, which PostScript and PDF write alike. Text is
+/// Latin-1, so every character fits in the byte escaped for it.
+fn string(text: &str) -> String {
+    let mut out = String::with_capacity(text.len() + 2);
+    out.push('(');
+    for c in text.chars() {
+        match c {
+            '(' | ')' | '\\' => {
+                out.push('\\');
+                out.push(c);
+            }
+            ' '..='~' => out.push(c),
+            _ => {
+                let _ = write!(out, "\\{:03o}", c as u32 & 0xff);
+            }
+        }
+    }
+    out.push(')');
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::create;
+    use crate::index::DisplayIndex;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    #[test]
+    fn markdown_lines_become_blocks() {
+        let body = "## Produce\n- [ ] apples\n  - [x] pears\n* milk\n12. eggs\n> mind\n---\n```\n- [ ] not a task\n```\n\nplain";
+        assert_eq!(
+            blocks(body),
+            vec![
+                Block::Heading(2, "Produce"),
+                Block::Item {
+                    depth: 0,
+                    marker: Marker::Task(false),
+                    text: "apples"
+                },
+                Block::Item {
+                    depth: 1,
+                    marker: Marker::Task(true),
+                    text: "pears"
+                },
+                Block::Item {
+                    depth: 0,
+                    marker: Marker::Bullet,
+                    text: "milk"
+                },
+                Block::Item {
+                    depth: 0,
+                    marker: Marker::Number("12.".into()),
+                    text: "eggs"
+                },
+                Block::Quote("mind"),
+                Block::Rule,
+                Block::Code("- [ ] not a task"),
+                Block::Blank,
+                Block::Text("plain"),
+            ]
+        );
+    }
+
+    #[test]
+    fn long_lines_wrap_between_words_and_inside_long_ones() {
+        assert_eq!(
+            wrap("pick up the dry cleaning", 10),
+            ["pick up", "the dry", "cleaning"]
+        );
+        assert_eq!(wrap("abcdefghij", 4), ["abcd", "efgh", "ij"]);
+        assert_eq!(latin1("“café” — 1…2 ✓"), "\"café\" - 1...2 ?");
+        assert_eq!(string("(a\\b) é"), "(\\(a\\\\b\\) \\351)");
+    }
+
+    #[test]
+    fn a_checklist_prints_boxes_to_tick() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        let body = "- [ ] apples\n- [x] pears\n".to_string();
+        create::run(&mut store, Scope::Project, "Groceries".into(), body, None).unwrap();
+        let pads = store
+            .list_pads(Scope::Project, crate::store::Bucket::Active)
+            .unwrap();
+
+        let document = Document::lay_out(&pads, Paper::A4);
+        let boxes: Vec<bool> = document.pages[0]
+            .1
+            .iter()
+            .filter_map(|mark| match mark {
+                Mark::Box { ticked, .. } => Some(*ticked),
+                _ => None,
+            })
+            .collect();
+        assert_eq!(boxes, [false, true]);
+
+        let selectors = [PadSelector::Path(vec![DisplayIndex::Regular(1)])];
+        let options = |destination: &str| PrintOptions {
+            format: PrintFormat::from_filename(destination),
+            paper: Paper::A4,
+            destination: destination.into(),
+        };
+        let bytes = |outcome: ExportOutcome| match outcome {
+            ExportOutcome::Artifact(artifact) => artifact.bytes,
+            ExportOutcome::Empty { .. } => panic!("expected a document"),
+        };
+
+        let ps = bytes(run(&store, Scope::Project, &selectors, &options("list.ps")).unwrap());
+        let ps = String::from_utf8(ps).unwrap();
+        assert!(ps.starts_with("%!PS-Adobe-3.0\n"));
+        assert!(ps.contains("(Groceries) show"));
+        assert!(ps.contains("rectstroke"));
+        assert!(ps.ends_with("%%EOF\n"));
+
+        let pdf = bytes(run(&store, Scope::Project, &selectors, &options("list.pdf")).unwrap());
+        assert!(pdf.starts_with(b"%PDF-1.4\n"));
+        // The cross-reference table points at each object.
+        let text: String = pdf
+            .iter()
+            .map(|&b| if b.is_ascii() { b as char } else { '?' })
+            .collect();
+        let xref = text.find("xref\n").unwrap();
+        for line in text[xref..]
+            .lines()
+            .skip(3)
+            .take_while(|l| l.ends_with(" n "))
+        {
+            let offset: usize = line[..10].parse().unwrap();
+            assert!(text[offset..].starts_with(|c: char| c.is_ascii_digit()));
+            assert!(text[offset..]
+                .split('\n')
+                .next()
+                .unwrap()
+                .ends_with(" 0 obj"));
+        }
+    }
+}
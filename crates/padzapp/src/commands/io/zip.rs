@@ -0,0 +1,237 @@
+//! Just enough of the zip format for `export --format zip` and its import.
+//!
+//! Entries are deflated (or, on reading, stored), named in UTF-8, and listed
+//! in a central directory; no zip64, encryption or multi-disk archives. That
+//! covers what padz writes and what common tools make of small text files,
+//! without another dependency: deflate and CRC-32 come from `flate2`.
+
+use crate::error::{PadzError, Result};
+use chrono::{DateTime, Datelike, Timelike, Utc};
+use flate2::read::DeflateDecoder;
+use flate2::write::DeflateEncoder;
+use flate2::{Compression, Crc};
+use std::io::{Read, Write};
+
+const LOCAL_HEADER: u32 = 0x0403_4b50;
+const CENTRAL_HEADER: u32 = 0x0201_4b50;
+const END_OF_CENTRAL_DIRECTORY: u32 = 0x0605_4b50;
+/// Length of the end-of-central-directory record without its comment.
+const END_RECORD_LEN: usize = 22;
+const VERSION: u16 = 20;
+/// General-purpose flag: entry names are UTF-8.
+const UTF8_NAMES: u16 = 0x0800;
+const STORED: u16 = 0;
+const DEFLATED: u16 = 8;
+
+/// Builds a zip archive in memory, every entry stamped with one time.
+pub struct ZipWriter {
+    out: Vec<u8>,
+    central: Vec<u8>,
+    entries: u16,
+    time: u16,
+    date: u16,
+}
+
+impl ZipWriter {
+    pub fn new(modified: DateTime<Utc>) -> Self {
+        let (time, date) = dos_time(modified);
+        Self {
+            out: Vec::new(),
+            central: Vec::new(),
+            entries: 0,
+            time,
+            date,
+        }
+    }
+
+    /// Appends a file called `name` holding `data`.
+    pub fn add(&mut self, name: &str, data: &[u8]) -> Result<()> {
+        let too_large = || PadzError::Api("Too large for a zip archive".to_string());
+        self.entries = self.entries.checked_add(1).ok_or_else(too_large)?;
+
+        let mut crc = Crc::new();
+        crc.update(data);
+        let mut encoder = DeflateEncoder::new(Vec::new(), Compression::default());
+        encoder.write_all(data).map_err(PadzError::Io)?;
+        let compressed = encoder.finish().map_err(PadzError::Io)?;
+
+        let offset = u32::try_from(self.out.len()).map_err(|_| too_large())?;
+        let compressed_len = u32::try_from(compressed.len()).map_err(|_| too_large())?;
+        let len = u32::try_from(data.len()).map_err(|_| too_large())?;
+        let name_len = u16::try_from(name.len()).map_err(|_| too_large())?;
+
+        let out = &mut self.out;
+        put32(out, LOCAL_HEADER);
+        put16(out, VERSION);
+        put16(out, UTF8_NAMES);
+        put16(out, DEFLATED);
+        put16(out, self.time);
+        put16(out, self.date);
+        put32(out, crc.sum());
+        put32(out, compressed_len);
+        put32(out, len);
+        put16(out, name_len);
+        put16(out, 0);
+        out.extend_from_slice(name.as_bytes());
+        out.extend_from_slice(&compressed);
+
+        let central = &mut self.central;
+        put32(central, CENTRAL_HEADER);
+        put16(central, VERSION);
+        put16(central, VERSION);
+        put16(central, UTF8_NAMES);
+        put16(central, DEFLATED);
+        put16(central, self.time);
+        put16(central, self.date);
+        put32(central, crc.sum());
+        put32(central, compressed_len);
+        put32(central, len);
+        put16(central, name_len);
+        // Extra field, comment, starting disk, internal attributes.
+        put16(central, 0);
+        put16(central, 0);
+        put16(central, 0);
+        put16(central, 0);
+        // External attributes: a regular file, rw-r--r--.
+        put32(central, 0o100644 << 16);
+        put32(central, offset);
+        central.extend_from_slice(name.as_bytes());
+        Ok(())
+    }
+
+    /// The finished archive.
+    pub fn finish(mut self) -> Result<Vec<u8>> {
+        let too_large = || PadzError::Api("Too large for a zip archive".to_string());
+        let offset = u32::try_from(self.out.len()).map_err(|_| too_large())?;
+        let size = u32::try_from(self.central.len()).map_err(|_| too_large())?;
+        self.out.append(&mut self.central);
+        let out = &mut self.out;
+        put32(out, END_OF_CENTRAL_DIRECTORY);
+        put16(out, 0);
+        put16(out, 0);
+        put16(out, self.entries);
+        put16(out, self.entries);
+        put32(out, size);
+        put32(out, offset);
+        put16(out, 0);
+        Ok(self.out)
+    }
+}
+
+/// The files in zip archive `bytes`, as `(name, contents)` in archive order.
+/// Directories are left out.
+pub fn read_entries(bytes: &[u8]) -> Result<Vec<(String, Vec<u8>)>> {
+    let invalid = |what: &str| PadzError::Api(format!("Invalid zip archive: {}", what));
+
+    // The end record sits last, followed only by a comment of up to 64 KiB.
+    let end = (0..=bytes.len().saturating_sub(END_RECORD_LEN))
+        .rev()
+        .take(u16::MAX as usize + 1)
+        .find(|&at| get32(bytes, at) == Some(END_OF_CENTRAL_DIRECTORY))
+        .ok_or_else(|| invalid("no central directory"))?;
+    let count = get16(bytes, end + 10).ok_or_else(|| invalid("truncated"))?;
+    let mut at = get32(bytes, end + 16).ok_or_else(|| invalid("truncated"))? as usize;
+
+    let mut entries = Vec::with_capacity(count as usize);
+    for _ in 0..count {
+        let field16 = |offset| get16(bytes, at + offset).ok_or_else(|| invalid("truncated"));
+        let field32 = |offset| get32(bytes, at + offset).ok_or_else(|| invalid("truncated"));
+        if field32(0)? != CENTRAL_HEADER {
+            return Err(invalid("bad central directory entry"));
+        }
+        let method = field16(10)?;
+        let crc = field32(16)?;
+        let compressed_len = field32(20)? as usize;
+        let name_len = field16(28)? as usize;
+        let skip = name_len + field16(30)? as usize + field16(32)? as usize;
+        let local = field32(42)? as usize;
+        let name = bytes
+            .get(at + 46..at + 46 + name_len)
+            .ok_or_else(|| invalid("truncated"))?;
+        let name = String::from_utf8_lossy(name).into_owned();
+        at += 46 + skip;
+
+        if name.ends_with('/') {
+            continue;
+        }
+        if get32(bytes, local) != Some(LOCAL_HEADER) {
+            return Err(invalid("bad local header"));
+        }
+        let local_name_len = get16(bytes, local + 26).ok_or_else(|| invalid("truncated"))?;
+        let local_extra_len = get16(bytes, local + 28).ok_or_else(|| invalid("truncated"))?;
+        let start = local + 30 + local_name_len as usize + local_extra_len as usize;
+        let raw = bytes
+            .get(start..start + compressed_len)
+            .ok_or_else(|| invalid("truncated"))?;
+        let data = match method {
+            STORED => raw.to_vec(),
+            DEFLATED => {
+                let mut data = Vec::new();
+                DeflateDecoder::new(raw)
+                    .read_to_end(&mut data)
+                    .map_err(|e| invalid(&e.to_string()))?;
+                data
+            }
+            other => return Err(invalid(&format!("unsupported compression {}", other))),
+        };
+        let mut check = Crc::new();
+        check.update(&data);
+        if check.sum() != crc {
+            return Err(invalid(&format!("{} is corrupt", name)));
+        }
+        entries.push((name, data));
+    }
+    Ok(entries)
+}
+
+/// `modified` as MS-DOS time and date, which count from 1980 in two-second
+/// steps.
+fn dos_time(modified: DateTime<Utc>) -> (u16, u16) {
+    let time = (modified.hour() << 11) | (modified.minute() << 5) | (modified.second() / 2);
+    let year = modified.year().clamp(1980, 2107) as u32 - 1980;
+    let date = (year << 9) | (modified.month() << 5) | modified.day();
+    (time as u16, date as u16)
+}
+
+fn put16(out: &mut Vec<u8>, value: u16) {
+    out.extend_from_slice(&value.to_le_bytes());
+}
+
+fn put32(out: &mut Vec<u8>, value: u32) {
+    out.extend_from_slice(&value.to_le_bytes());
+}
+
+fn get16(bytes: &[u8], at: usize) -> Option<u16> {
+    Some(u16::from_le_bytes(bytes.get(at..at + 2)?.try_into().ok()?))
+}
+
+fn get32(bytes: &[u8], at: usize) -> Option<u32> {
+    Some(u32::from_le_bytes(bytes.get(at..at + 4)?.try_into().ok()?))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn what_is_written_reads_back() {
+        let mut zip = ZipWriter::new(Utc::now());
+        zip.add("padz/db.json", b"{}").unwrap();
+        zip.add("padz/pads/pad-1.md", "Ünïcode\n\nbody".as_bytes())
+            .unwrap();
+        let bytes = zip.finish().unwrap();
+
+        let entries = read_entries(&bytes).unwrap();
+        assert_eq!(
+            entries,
+            vec![
+                ("padz/db.json".to_string(), b"{}".to_vec()),
+                (
+                    "padz/pads/pad-1.md".to_string(),
+                    "Ünïcode\n\nbody".as_bytes().to_vec()
+                ),
+            ]
+        );
+        assert!(read_entries(b"not a zip").is_err());
+    }
+}
@@ -0,0 +1,196 @@
+//! Daily notes: `padz today`, `padz yesterday` and `padz journal ls`.
+//!
+//! A day's note is a pad titled with its date (`2026-10-15`), nested in the
+//! journal pad: the root pad titled as the `journal.parent` setting says
+//! ("Journal" unless set). Both are found by their exact title with
+//! [`find_by_title`], never by the fuzzy title search selectors use, so a day
+//! always opens the same pad however many others mention its date. Both are
+//! created the first time they are asked for.
+
+use crate::error::Result;
+use crate::index::{DisplayIndex, DisplayPad, PadSelector};
+use crate::model::{CreationContext, Scope};
+use crate::store::{Bucket, DataStore};
+use chrono::NaiveDate;
+use serde::Serialize;
+use uuid::Uuid;
+
+use super::helpers::indexed_pads;
+
+/// The title of the note for `date`.
+pub fn day_title(date: NaiveDate) -> String {
+    date.format("%Y-%m-%d").to_string()
+}
+
+/// The active pad titled exactly `title` among the children of `parent`, or
+/// among the root pads without one. When several share the title, the oldest
+/// wins, so the answer does not depend on listing order.
+pub fn find_by_title<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    parent: Option<Uuid>,
+    title: &str,
+) -> Result<Option<Uuid>> {
+    Ok(store
+        .list_metadata(scope, Bucket::Active)?
+        .into_iter()
+        .filter(|meta| meta.parent_id == parent && meta.title == title)
+        .min_by_key(|meta| (meta.created_at, meta.id))
+        .map(|meta| meta.id))
+}
+
+/// The note for `date` in the journal titled `journal`, creating the journal
+/// and the note as needed. New pads are stamped with `context` and `owner`,
+/// as `create` stamps them.
+pub fn open_day<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    journal: &str,
+    date: NaiveDate,
+    context: Option<CreationContext>,
+    owner: Option<String>,
+) -> Result<Uuid> {
+    let mut find_or_create = |parent: Option<Uuid>, title: String| -> Result<Uuid> {
+        if let Some(id) = find_by_title(store, scope, parent, &title)? {
+            return Ok(id);
+        }
+        let created = super::create::run_with_context(
+            store,
+            scope,
+            title,
+            String::new(),
+            parent.map(PadSelector::Uuid),
+            context.clone(),
+            owner.clone(),
+        )?;
+        Ok(created.affected_pads[0].pad.metadata.id)
+    };
+    let journal = find_or_create(None, journal.to_string())?;
+    find_or_create(Some(journal), day_title(date))
+}
+
+/// The journal's daily notes, newest day first.
+#[derive(Debug, Clone, Serialize)]
+pub struct JournalOutcome {
+    /// The journal pad's index, which the days' own indexes are nested in;
+    /// `None` before the first note.
+    pub journal: Option<DisplayIndex>,
+    pub days: Vec<DisplayPad>,
+}
+
+/// Lists the notes in the journal titled `journal`: its active children whose
+/// titles are dates. Anything else filed under it is left out.
+pub fn list<S: DataStore>(store: &S, scope: Scope, journal: &str) -> Result<JournalOutcome> {
+    let root = match find_by_title(store, scope, None, journal)? {
+        Some(id) => indexed_pads(store, scope)?
+            .into_iter()
+            .find(|dp| dp.pad.metadata.id == id && is_active(&dp.index)),
+        None => None,
+    };
+    let Some(root) = root else {
+        return Ok(JournalOutcome {
+            journal: None,
+            days: Vec::new(),
+        });
+    };
+
+    let mut days: Vec<(NaiveDate, DisplayPad)> = root
+        .children
+        .into_iter()
+        .filter(|dp| is_active(&dp.index))
+        .filter_map(|dp| {
+            let date = NaiveDate::parse_from_str(&dp.pad.metadata.title, "%Y-%m-%d").ok()?;
+            Some((date, dp))
+        })
+        .collect();
+    days.sort_by(|a, b| b.0.cmp(&a.0));
+    Ok(JournalOutcome {
+        journal: Some(root.index),
+        days: days.into_iter().map(|(_, dp)| dp).collect(),
+    })
+}
+
+fn is_active(index: &DisplayIndex) -> bool {
+    matches!(index, DisplayIndex::Regular(_) | DisplayIndex::Pinned(_))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::create;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn store() -> BucketedStore<MemBackend> {
+        BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        )
+    }
+
+    fn date(day: u32) -> NaiveDate {
+        NaiveDate::from_ymd_opt(2026, 10, day).unwrap()
+    }
+
+    #[test]
+    fn a_day_opens_the_same_note_every_time() {
+        let mut store = store();
+        // A pad that merely mentions the date is not the day's note.
+        create::run(
+            &mut store,
+            Scope::Project,
+            "Notes from 2026-10-15".into(),
+            "".into(),
+            None,
+        )
+        .unwrap();
+
+        let first = open_day(&mut store, Scope::Project, "Journal", date(15), None, None).unwrap();
+        let again = open_day(&mut store, Scope::Project, "Journal", date(15), None, None).unwrap();
+        let other = open_day(&mut store, Scope::Project, "Journal", date(14), None, None).unwrap();
+
+        assert_eq!(first, again);
+        assert_ne!(first, other);
+        let journal = find_by_title(&store, Scope::Project, None, "Journal")
+            .unwrap()
+            .unwrap();
+        let day = store
+            .get_pad(&first, Scope::Project, Bucket::Active)
+            .unwrap();
+        assert_eq!(day.metadata.title, "2026-10-15");
+        assert_eq!(day.metadata.parent_id, Some(journal));
+    }
+
+    #[test]
+    fn list_shows_the_days_newest_first() {
+        let mut store = store();
+        assert!(list(&store, Scope::Project, "Journal")
+            .unwrap()
+            .days
+            .is_empty());
+
+        for day in [14, 15, 13] {
+            open_day(&mut store, Scope::Project, "Journal", date(day), None, None).unwrap();
+        }
+        let journal = PadSelector::Path(vec![DisplayIndex::Regular(1)]);
+        create::run(
+            &mut store,
+            Scope::Project,
+            "Ideas".into(),
+            "".into(),
+            Some(journal),
+        )
+        .unwrap();
+
+        let outcome = list(&store, Scope::Project, "Journal").unwrap();
+        assert_eq!(outcome.journal, Some(DisplayIndex::Regular(1)));
+        let titles: Vec<_> = outcome
+            .days
+            .iter()
+            .map(|dp| dp.pad.metadata.title.as_str())
+            .collect();
+        assert_eq!(titles, ["2026-10-15", "2026-10-14", "2026-10-13"]);
+    }
+}
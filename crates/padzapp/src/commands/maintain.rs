@@ -0,0 +1,90 @@
+//! Store upkeep (`padz maintain`).
+//!
+//! Collects the garbage of one scope ([`crate::store::gc`]): files padz left
+//! behind that no pad refers to. Each removed file is reported with what it
+//! reclaimed, so the user sees exactly what went.
+
+use crate::error::{PadzError, Result};
+use crate::store::gc::{self, Garbage};
+use chrono::{DateTime, Utc};
+use serde::Serialize;
+use std::path::Path;
+
+/// Semantic result of `maintain`.
+///
+/// Serializes directly as the CLI/structured payload: the `status` tag plus
+/// the files collected and the bytes they took.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(tag = "status", rename_all = "snake_case")]
+pub enum MaintainOutcome {
+    /// Nothing to collect.
+    Clean,
+    /// Garbage was found and, a dry run aside, removed.
+    Collected {
+        removed: Vec<Garbage>,
+        reclaimed_bytes: u64,
+    },
+}
+
+/// Collects the garbage under `scope_root`. `dry_run` reports it without
+/// removing anything.
+pub fn run(scope_root: &Path, now: DateTime<Utc>, dry_run: bool) -> Result<MaintainOutcome> {
+    let removed = gc::find(scope_root, now).map_err(PadzError::Io)?;
+    if removed.is_empty() {
+        return Ok(MaintainOutcome::Clean);
+    }
+    if !dry_run {
+        gc::remove(&removed).map_err(PadzError::Io)?;
+    }
+    let reclaimed_bytes = removed.iter().map(|item| item.bytes).sum();
+    Ok(MaintainOutcome::Collected {
+        removed,
+        reclaimed_bytes,
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use chrono::Duration;
+    use std::fs;
+    use tempfile::TempDir;
+
+    #[test]
+    fn test_maintain_removes_and_totals_the_garbage() {
+        let temp = TempDir::new().unwrap();
+        let aside = temp.path().join(".journal-1.tmp");
+        fs::write(&aside, "{\"active\":{}}").unwrap();
+        let later = Utc::now() + Duration::hours(gc::STALE_AFTER_HOURS + 1);
+
+        let outcome = run(temp.path(), later, false).unwrap();
+
+        let MaintainOutcome::Collected {
+            removed,
+            reclaimed_bytes,
+        } = outcome
+        else {
+            panic!("expected garbage, got {:?}", outcome);
+        };
+        assert_eq!(removed.len(), 1);
+        assert_eq!(reclaimed_bytes, 13);
+        assert!(!aside.exists());
+        assert_eq!(
+            run(temp.path(), later, false).unwrap(),
+            MaintainOutcome::Clean
+        );
+    }
+
+    #[test]
+    fn test_maintain_dry_run_removes_nothing() {
+        let temp = TempDir::new().unwrap();
+        let aside = temp.path().join(".tags-1.tmp");
+        fs::write(&aside, "[]").unwrap();
+        let later = Utc::now() + Duration::hours(gc::STALE_AFTER_HOURS + 1);
+
+        let outcome = run(temp.path(), later, true).unwrap();
+
+        assert!(matches!(outcome, MaintainOutcome::Collected { .. }));
+        assert!(aside.exists());
+    }
+}
@@ -1,9 +1,13 @@
 //! Schema for the `--json` export/import format.
 //!
-//! The JSON archive is a `.tar.gz` containing:
+//! The JSON archive is a `.tar.gz` (or, with `--format zip`, a `.zip`)
+//! containing:
 //! - `padz/db.json` — this schema, with per-pad metadata and the referenced tag registry
 //! - `padz/pads/pad-<uuid>.<ext>` — raw pad files, preserving original extension
 //!
+//! A JSON bundle (`--format json`) is `db.json` alone, each [`PadEntry`]
+//! carrying its pad's text in [`PadEntry::content`] instead of a file.
+//!
 //! ## Versioning
 //!
 //! [`Archive::schema_version`] is the only required forward-compat hook.
@@ -40,6 +44,10 @@ pub struct Archive {
     pub schema_version: u32,
     pub exported_at: DateTime<Utc>,
     pub padz_version: String,
+    /// The project the pads were exported from: its directory, or `None`
+    /// for the global store.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub project: Option<String>,
     pub pads: Vec<PadEntry>,
     #[serde(default)]
     pub tags: Vec<TagRegistryEntry>,
@@ -56,6 +64,9 @@ pub struct PadEntry {
     pub bucket: String,
     /// Raw metadata as `serde_json::Value` to allow field-level defensive import.
     pub metadata: Value,
+    /// The pad's text, in a JSON bundle; `file` then only names its format.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub content: Option<String>,
 }
 
 fn default_bucket() -> String {
@@ -55,14 +55,23 @@
 //! - [`update`]: Modify existing pads
 //! - [`delete`]: Soft-delete pads
 //! - [`pinning`]: Pin/unpin pads
+//! - [`pinboard`]: Pinned pads gathered across scopes
+//! - [`publish`]: Where pads were published as gists and snippets, and which are stale
 //! - [`purge`]: Permanently remove deleted pads
+//! - [`recent`]: Access tracking and the recently-read listing
 //! - [`search`]: Full-text search
 //! - [`export`]: Export pads to archive
+//! - [`compile`]: Assemble tagged pads into one curated document
+//! - [`release_notes`]: The tagged pads written since the last release, for its notes
 //! - [`import`]: Import pads from files
 //! - [`paths`]: Get filesystem paths to pads
 //! - [`uuid`]: Resolve selected pads to durable UUID values
+//! - [`uri`]: Find the store a `padz://` URI points into
 //! - [`init`]: Initialize scope directories
 //! - [`doctor`]: Verify and fix data consistency
+//! - [`scopes`]: The scope registry: pruning gone projects, naming remotes
+//! - [`stats`]: Per-bucket pad counts, and the size of the trash
+//! - [`verify`]: Check content against recorded checksums
 //! - [`tags`]: List and mutate the tag registry
 //! - [`tagging`]: Assign and remove tags on selected pads
 //! - [`helpers`]: Shared utilities (index resolution, etc.)
@@ -111,6 +120,9 @@ pub enum CmdNotice {
     },
     /// A completed-pad deletion request found no completed pads.
     NoCompletedPads,
+    /// A pin past `max_pins` unpinned this pad, the longest pinned, to make
+    /// room (see [`pinning::PinLimit`]).
+    UnpinnedToMakeRoom { title: String },
 }
 
 /// How a pad's content reached the update command.
@@ -143,37 +155,67 @@ pub enum CmdOutcome {
         path: Vec<crate::index::DisplayIndex>,
         status: crate::model::TodoStatus,
     },
+    /// A pad moved to another store, where it is at `path`. `project` is the
+    /// store's project directory; `None` for the global store.
+    Relocated {
+        path: Vec<crate::index::DisplayIndex>,
+        title: String,
+        project: Option<PathBuf>,
+    },
 }
 
+pub mod anchors;
 pub mod archive;
 pub mod create;
 pub mod delete;
 pub mod doctor;
+pub mod encrypt;
 pub mod get;
+pub mod grouping;
 pub mod helpers;
 pub mod init;
 pub mod io;
+pub mod journal;
+pub mod maintain;
 pub mod move_pads;
+pub mod naming;
+pub mod ownership;
 
 // Preserve pre-split paths: `commands::export`, `commands::import`.
-pub use io::{export, import};
+pub use io::{compile, csv, export, import, print};
 
 pub mod inline_metadata;
 pub mod metadata_apply;
 pub mod metadata_schema;
 pub mod paths;
+pub mod pinboard;
 pub mod pinning;
+pub mod publish;
 pub mod purge;
+pub mod query;
+pub mod recent;
+pub mod refs;
+pub mod release_notes;
 pub mod restore;
+pub mod scopes;
+pub mod search_all;
+pub mod snip;
+pub mod stats;
 pub mod status;
 pub mod tagging;
 pub mod tags;
+pub mod timeline;
 pub mod transfer;
+pub mod tree;
 
 pub mod unarchive;
+pub mod undo;
 pub mod update;
+pub mod uri;
 pub mod uuid;
+pub mod verify;
 pub mod view;
+pub mod which;
 
 /// The filesystem locations a `PadzApi` operates against, supplied by the
 /// caller rather than discovered here.
@@ -213,6 +255,9 @@ pub struct CmdResult {
     pub outcomes: Vec<CmdOutcome>,
     /// The nesting mode used to produce listed_pads.
     pub nesting: NestingMode,
+    /// What the trash holds, counted by listings from the pads they read
+    /// anyway; `None` from every other command.
+    pub trash: Option<stats::TrashSize>,
 }
 
 impl CmdResult {
@@ -0,0 +1,253 @@
+//! Aliases: human names for pads.
+//!
+//! `padz name 3 runbook-tls` lets the pad be selected as `runbook-tls` anywhere
+//! an index is accepted. Selection needs nothing new: an alias arrives as a
+//! title selector, and resolution checks for an exact alias before searching
+//! titles (see `helpers::selector_resolve`).
+//!
+//! Aliases follow the tag-name grammar, are stored lowercase, and are unique
+//! within a scope across all buckets — archiving or deleting a pad keeps its
+//! name taken. One that would parse as an index, range or UUID prefix (`p2`,
+//! `ar1`, `beef`) is refused, since the parser would never let it reach
+//! resolution.
+
+use crate::commands::helpers::{indexed_pads, resolve_selectors, TitleBucket};
+use crate::commands::CmdResult;
+use crate::error::{PadzError, Result};
+use crate::index::{parse_index_or_range, DisplayPad, PadSelector};
+use crate::model::{Pad, Scope};
+use crate::store::{Bucket, DataStore};
+use crate::tags::validate_tag_name;
+use uuid::Uuid;
+
+/// Checks `alias` and returns it in its stored (lowercase) form.
+pub fn validate_alias(alias: &str) -> Result<String> {
+    let alias = alias.trim().to_lowercase();
+    validate_tag_name(&alias)
+        .map_err(|e| PadzError::Api(format!("Invalid alias '{}': {}", alias, e)))?;
+    if parse_index_or_range(&alias).is_ok() {
+        return Err(PadzError::Api(format!(
+            "Invalid alias '{}': it would be read as an index or UUID",
+            alias
+        )));
+    }
+    Ok(alias)
+}
+
+/// The pad in `scope` that carries `alias`, in any bucket.
+pub fn alias_owner<S: DataStore>(store: &S, scope: Scope, alias: &str) -> Result<Option<Uuid>> {
+    for bucket in [Bucket::Active, Bucket::Archived, Bucket::Deleted] {
+        let owner = store
+            .list_pads(scope, bucket)?
+            .into_iter()
+            .find(|pad| pad.metadata.alias.as_deref() == Some(alias));
+        if let Some(pad) = owner {
+            return Ok(Some(pad.metadata.id));
+        }
+    }
+    Ok(None)
+}
+
+/// Gives the selected pad an alias, or removes it when `alias` is `None`.
+///
+/// Exactly one pad must be selected. Renaming a pad replaces its alias; giving
+/// a pad the alias it already has is a no-op.
+pub fn run<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    alias: Option<&str>,
+) -> Result<CmdResult> {
+    let resolved = resolve_selectors(store, scope, selectors, false, TitleBucket::Active)?;
+    let id = match resolved.as_slice() {
+        [(_, id)] => *id,
+        [] => return Err(PadzError::Api("No pad selected".to_string())),
+        _ => {
+            return Err(PadzError::Api(
+                "An alias names one pad; select exactly one".to_string(),
+            ))
+        }
+    };
+
+    let alias = alias.map(validate_alias).transpose()?;
+    if let Some(alias) = &alias {
+        if let Some(owner) = alias_owner(store, scope, alias)? {
+            if owner != id {
+                return Err(PadzError::Api(format!(
+                    "Alias '{}' is already used by another pad",
+                    alias
+                )));
+            }
+        }
+    }
+
+    let (mut pad, bucket) = find_in_any_bucket(store, scope, id)?;
+    if pad.metadata.alias != alias {
+        pad.metadata.alias = alias;
+        store.save_pad(&pad, scope, bucket)?;
+    }
+
+    let indexed = indexed_pads(store, scope)?;
+    let mut result = CmdResult::default();
+    if let Some(dp) = super::helpers::find_pad_by_uuid(&indexed, id, |_| true) {
+        result.affected_pads.push(DisplayPad {
+            pad: dp.pad.clone(),
+            index: dp.index.clone(),
+            matches: None,
+            children: Vec::new(),
+        });
+    }
+    Ok(result)
+}
+
+fn find_in_any_bucket<S: DataStore>(store: &S, scope: Scope, id: Uuid) -> Result<(Pad, Bucket)> {
+    for bucket in [Bucket::Active, Bucket::Archived, Bucket::Deleted] {
+        if let Ok(pad) = store.get_pad(&id, scope, bucket) {
+            return Ok((pad, bucket));
+        }
+    }
+    Err(PadzError::PadNotFound(id))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{archive, create};
+    use crate::index::DisplayIndex;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn store_with(titles: &[&str]) -> BucketedStore<MemBackend> {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        for title in titles {
+            create::run(&mut store, Scope::Project, (*title).into(), "".into(), None).unwrap();
+        }
+        store
+    }
+
+    fn index(n: usize) -> Vec<PadSelector> {
+        vec![PadSelector::Path(vec![DisplayIndex::Regular(n)])]
+    }
+
+    fn by_name(name: &str) -> Vec<PadSelector> {
+        vec![PadSelector::Title(name.to_string())]
+    }
+
+    #[test]
+    fn test_alias_selects_its_pad() {
+        let mut store = store_with(&["TLS runbook", "Groceries"]);
+        run(&mut store, Scope::Project, &index(2), Some("Runbook-TLS")).unwrap();
+
+        let resolved = resolve_selectors(
+            &store,
+            Scope::Project,
+            &by_name("runbook-tls"),
+            false,
+            TitleBucket::Active,
+        )
+        .unwrap();
+        let pad = store
+            .get_pad(&resolved[0].1, Scope::Project, Bucket::Active)
+            .unwrap();
+        assert_eq!(pad.metadata.title, "TLS runbook");
+        assert_eq!(pad.metadata.alias.as_deref(), Some("runbook-tls"));
+    }
+
+    #[test]
+    fn test_alias_wins_over_title_matches() {
+        // "notes" is a substring of both titles, but names exactly one pad.
+        let mut store = store_with(&["Meeting notes", "Release notes"]);
+        run(&mut store, Scope::Project, &index(1), Some("notes")).unwrap();
+
+        let resolved = resolve_selectors(
+            &store,
+            Scope::Project,
+            &by_name("notes"),
+            false,
+            TitleBucket::Active,
+        )
+        .unwrap();
+        assert_eq!(resolved.len(), 1);
+        let pad = store
+            .get_pad(&resolved[0].1, Scope::Project, Bucket::Active)
+            .unwrap();
+        assert_eq!(pad.metadata.title, "Release notes");
+    }
+
+    #[test]
+    fn test_alias_still_selects_an_archived_pad() {
+        let mut store = store_with(&["Old plan"]);
+        run(&mut store, Scope::Project, &index(1), Some("plan")).unwrap();
+        archive::run(&mut store, Scope::Project, &index(1)).unwrap();
+
+        let resolved = resolve_selectors(
+            &store,
+            Scope::Project,
+            &by_name("plan"),
+            false,
+            TitleBucket::Active,
+        )
+        .unwrap();
+        assert_eq!(resolved[0].0, vec![DisplayIndex::Archived(1)]);
+    }
+
+    #[test]
+    fn test_alias_is_unique_per_scope() {
+        let mut store = store_with(&["A", "B"]);
+        run(&mut store, Scope::Project, &index(1), Some("shared")).unwrap();
+
+        let err = run(&mut store, Scope::Project, &index(2), Some("shared")).unwrap_err();
+        assert!(err.to_string().contains("already used"), "got: {err}");
+
+        // The same name is free in the other scope.
+        create::run(&mut store, Scope::Global, "C".into(), "".into(), None).unwrap();
+        run(&mut store, Scope::Global, &index(1), Some("shared")).unwrap();
+    }
+
+    #[test]
+    fn test_renaming_and_clearing() {
+        let mut store = store_with(&["A"]);
+        run(&mut store, Scope::Project, &index(1), Some("first")).unwrap();
+        // Re-applying the pad's own alias is not a conflict.
+        run(&mut store, Scope::Project, &index(1), Some("first")).unwrap();
+        run(&mut store, Scope::Project, &index(1), Some("second")).unwrap();
+        assert_eq!(alias_owner(&store, Scope::Project, "first").unwrap(), None);
+        assert!(alias_owner(&store, Scope::Project, "second")
+            .unwrap()
+            .is_some());
+
+        let result = run(&mut store, Scope::Project, &index(1), None).unwrap();
+        assert_eq!(result.affected_pads[0].pad.metadata.alias, None);
+    }
+
+    #[test]
+    fn test_alias_names_one_pad() {
+        let mut store = store_with(&["A", "B"]);
+        let range = vec![PadSelector::Range(
+            vec![DisplayIndex::Regular(1)],
+            vec![DisplayIndex::Regular(2)],
+        )];
+        assert!(run(&mut store, Scope::Project, &range, Some("both")).is_err());
+    }
+
+    #[test]
+    fn test_validate_alias() {
+        assert_eq!(validate_alias("Runbook-TLS").unwrap(), "runbook-tls");
+        assert_eq!(validate_alias(" notes_2 ").unwrap(), "notes_2");
+
+        // Not a valid name at all.
+        for bad in ["", "7up", "-x", "x-", "a b", "a--b"] {
+            assert!(validate_alias(bad).is_err(), "{bad:?} should be refused");
+        }
+        // Valid names the selector parser would read as something else.
+        for taken in ["p2", "ar1", "d3", "beef", "cafe01", "p1-p3"] {
+            let err = validate_alias(taken).unwrap_err().to_string();
+            assert!(err.contains("index or UUID"), "{taken:?}: {err}");
+        }
+    }
+}
@@ -0,0 +1,189 @@
+//! Who may change a pad in a shared scope (`owner_only_edit`).
+//!
+//! A global scope synced between several people, or a project store checked
+//! into a shared repository, holds everyone's pads. With `owner_only_edit` on,
+//! each pad records who created it ([`Metadata::owner`]), and editing or
+//! deleting a pad someone else created needs `--force`. So does moving it to
+//! another store, purging it, or undoing a change made to it. Deleting or
+//! purging a pad takes its children along, so every one of them is checked.
+//!
+//! Pads with no recorded owner, created before the mode was on or by a client
+//! that did not say who it was, are anyone's. Pins, tags and the other
+//! metadata commands are not guarded: what the mode protects is a pad's
+//! content and its existence.
+//!
+//! [`Metadata::owner`]: crate::model::Metadata::owner
+
+use crate::error::{PadzError, Result};
+use crate::index::PadSelector;
+use crate::model::{Metadata, Scope};
+use crate::store::undo::UndoRecord;
+use crate::store::{Bucket, DataStore};
+use uuid::Uuid;
+
+use super::helpers::{fmt_path, get_descendant_ids, resolve_selectors, TitleBucket};
+
+/// The rule a client asked for (see [`crate::api::PadzApi::set_ownership`]).
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct Ownership {
+    /// Who is running the command, stamped on the pads they create.
+    pub identity: Option<String>,
+    /// Whether pads others created are off limits.
+    pub owner_only_edit: bool,
+    /// Set by `--force`: act on them anyway.
+    pub force: bool,
+}
+
+impl Ownership {
+    /// Whether [`check`] has anything to refuse.
+    pub fn enforced(&self) -> bool {
+        self.owner_only_edit && !self.force
+    }
+}
+
+/// Fails unless `ownership` lets its identity change every active pad
+/// `selectors` name, and their descendants too when `with_descendants`.
+pub fn check<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    with_descendants: bool,
+    ownership: &Ownership,
+) -> Result<()> {
+    check_in(
+        store,
+        scope,
+        selectors,
+        with_descendants,
+        TitleBucket::Active,
+        ownership,
+    )
+}
+
+/// [`check`] for commands that select pads outside the active bucket: titles
+/// are matched in `title_bucket`, and a pad is checked wherever it lives.
+pub fn check_in<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    with_descendants: bool,
+    title_bucket: TitleBucket,
+    ownership: &Ownership,
+) -> Result<()> {
+    if !ownership.enforced() {
+        return Ok(());
+    }
+    for (path, id) in resolve_selectors(store, scope, selectors, false, title_bucket)? {
+        let mut ids = vec![id];
+        if with_descendants {
+            ids.extend(get_descendant_ids(store, scope, &[id])?);
+        }
+        for id in ids {
+            let metadata = find_metadata(store, scope, &id)?;
+            refuse_others(&format!("Pad {}", fmt_path(&path)), &metadata, ownership)?;
+        }
+    }
+    Ok(())
+}
+
+/// Fails unless `ownership` lets its identity take back `record`: every pad
+/// the change touched, as it was before and as it was left.
+pub fn check_undo(record: &UndoRecord, ownership: &Ownership) -> Result<()> {
+    if !ownership.enforced() {
+        return Ok(());
+    }
+    for change in &record.pads {
+        for state in change.before.iter().chain(&change.after) {
+            refuse_others("Pad", &state.metadata, ownership)?;
+        }
+    }
+    Ok(())
+}
+
+fn refuse_others(what: &str, metadata: &Metadata, ownership: &Ownership) -> Result<()> {
+    let identity = ownership.identity.as_deref().unwrap_or_default();
+    match &metadata.owner {
+        Some(owner) if owner != identity => Err(PadzError::Api(format!(
+            "{} ('{}') belongs to {}: use --force to change it anyway",
+            what, metadata.title, owner
+        ))),
+        _ => Ok(()),
+    }
+}
+
+fn find_metadata<S: DataStore>(store: &S, scope: Scope, id: &Uuid) -> Result<Metadata> {
+    for bucket in [Bucket::Active, Bucket::Archived] {
+        if let Ok(pad) = store.get_pad(id, scope, bucket) {
+            return Ok(pad.metadata);
+        }
+    }
+    Ok(store.get_pad(id, scope, Bucket::Deleted)?.metadata)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::create;
+    use crate::index::DisplayIndex;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn owned_by(owner: &str) -> Ownership {
+        Ownership {
+            identity: Some(owner.to_string()),
+            owner_only_edit: true,
+            force: false,
+        }
+    }
+
+    #[test]
+    fn only_the_owner_may_change_a_pad_unless_forced() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        let parent = create::run_with_context(
+            &mut store,
+            Scope::Project,
+            "Shared".into(),
+            "".into(),
+            None,
+            None,
+            None,
+        )
+        .unwrap();
+        let parent_id = parent.affected_pads[0].pad.metadata.id;
+        create::run_with_context(
+            &mut store,
+            Scope::Project,
+            "Mine".into(),
+            "".into(),
+            Some(PadSelector::Uuid(parent_id)),
+            None,
+            Some("ana".into()),
+        )
+        .unwrap();
+        let shared = [PadSelector::Path(vec![DisplayIndex::Regular(1)])];
+        let mine = [PadSelector::Title("Mine".into())];
+
+        // An unowned pad is anyone's; its child is ana's.
+        check(&store, Scope::Project, &shared, false, &owned_by("bo")).unwrap();
+        let err = check(&store, Scope::Project, &shared, true, &owned_by("bo")).unwrap_err();
+        assert!(err.to_string().contains("belongs to ana"));
+        assert!(check(&store, Scope::Project, &mine, false, &owned_by("bo")).is_err());
+        check(&store, Scope::Project, &mine, false, &owned_by("ana")).unwrap();
+
+        let forced = Ownership {
+            force: true,
+            ..owned_by("bo")
+        };
+        check(&store, Scope::Project, &mine, false, &forced).unwrap();
+        let off = Ownership {
+            owner_only_edit: false,
+            ..owned_by("bo")
+        };
+        check(&store, Scope::Project, &mine, false, &off).unwrap();
+    }
+}
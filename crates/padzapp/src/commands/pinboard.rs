@@ -0,0 +1,109 @@
+//! Cross-scope pinboard.
+//!
+//! Pinned pads are the handful of notes a user wants in front of them no matter
+//! where they are. The pinboard collects the root-level pinned pads of every
+//! requested scope into one list, each entry tagged with the scope it lives in
+//! so the pad's `pN` index stays meaningful: `p1` in the project and `p1` in the
+//! global store are different pads, and only the pair identifies one.
+
+use crate::error::Result;
+use crate::index::{DisplayIndex, DisplayPad};
+use crate::model::Scope;
+use crate::store::DataStore;
+use serde::Serialize;
+
+use super::helpers::indexed_pads;
+
+/// One pinned pad and the scope whose `pN` index it carries.
+#[derive(Debug, Clone, Serialize)]
+pub struct PinboardEntry {
+    pub scope: Scope,
+    pub pad: DisplayPad,
+}
+
+/// Pinned pads gathered across scopes, in scope order and then pinned order.
+#[derive(Debug, Clone, Serialize)]
+pub struct PinboardOutcome {
+    pub entries: Vec<PinboardEntry>,
+}
+
+/// Collect root-level pinned pads from each scope, in the order given.
+///
+/// Nested pinned pads (`1.p1`) are left out: they are pinned within their
+/// parent, not on the board. Children of a pinned root ride along untouched in
+/// the entry's `children`, exactly as `index_pads` built them.
+pub fn run<S: DataStore>(store: &S, scopes: &[Scope]) -> Result<PinboardOutcome> {
+    let mut entries = Vec::new();
+    for &scope in scopes {
+        for dp in indexed_pads(store, scope)? {
+            if matches!(dp.index, DisplayIndex::Pinned(_)) {
+                entries.push(PinboardEntry { scope, pad: dp });
+            }
+        }
+    }
+    Ok(PinboardOutcome { entries })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, pinning};
+    use crate::index::PadSelector;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn store() -> BucketedStore<MemBackend> {
+        BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        )
+    }
+
+    fn pin_first(store: &mut BucketedStore<MemBackend>, scope: Scope) {
+        let sel = PadSelector::Path(vec![DisplayIndex::Regular(1)]);
+        pinning::pin(store, scope, std::slice::from_ref(&sel)).unwrap();
+    }
+
+    #[test]
+    fn collects_pinned_pads_from_every_scope() {
+        let mut store = store();
+        create::run(&mut store, Scope::Project, "Proj".into(), "".into(), None).unwrap();
+        create::run(&mut store, Scope::Global, "Glob".into(), "".into(), None).unwrap();
+        create::run(&mut store, Scope::Global, "Loose".into(), "".into(), None).unwrap();
+        pin_first(&mut store, Scope::Project);
+        pin_first(&mut store, Scope::Global);
+
+        let outcome = run(&store, &[Scope::Project, Scope::Global]).unwrap();
+
+        assert_eq!(outcome.entries.len(), 2);
+        assert_eq!(outcome.entries[0].scope, Scope::Project);
+        assert_eq!(outcome.entries[0].pad.pad.metadata.title, "Proj");
+        assert_eq!(outcome.entries[0].pad.index, DisplayIndex::Pinned(1));
+        assert_eq!(outcome.entries[1].scope, Scope::Global);
+        assert_eq!(outcome.entries[1].pad.pad.metadata.title, "Loose");
+        assert_eq!(outcome.entries[1].pad.index, DisplayIndex::Pinned(1));
+    }
+
+    #[test]
+    fn nested_pins_stay_off_the_board() {
+        let mut store = store();
+        create::run(&mut store, Scope::Project, "Parent".into(), "".into(), None).unwrap();
+        let parent = PadSelector::Path(vec![DisplayIndex::Regular(1)]);
+        create::run(
+            &mut store,
+            Scope::Project,
+            "Child".into(),
+            "".into(),
+            Some(parent),
+        )
+        .unwrap();
+        let child = PadSelector::Path(vec![DisplayIndex::Regular(1), DisplayIndex::Regular(1)]);
+        pinning::pin(&mut store, Scope::Project, std::slice::from_ref(&child)).unwrap();
+
+        let outcome = run(&store, &[Scope::Project]).unwrap();
+
+        assert!(outcome.entries.is_empty());
+    }
+}
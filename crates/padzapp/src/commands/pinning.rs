@@ -1,38 +1,128 @@
+//! Pinning pads, within the `max_pins` limit.
+//!
+//! With `max_pins` set, a pin that would take a scope past the limit is
+//! refused, unless the caller asks (`pin --evict`) to make room by unpinning the
+//! scope's longest-pinned pads first. Unset, any number of pads may be pinned.
+
 use crate::attributes::AttrValue;
 use crate::commands::{CmdNotice, CmdResult};
-use crate::error::Result;
+use crate::error::{PadzError, Result};
 use crate::index::{DisplayIndex, DisplayPad, PadSelector};
 use crate::model::Scope;
 use crate::store::{Bucket, DataStore};
+use std::collections::HashSet;
 use uuid::Uuid;
 
 use super::helpers::{indexed_pads, resolve_selectors, TitleBucket};
 
+/// How many pads a scope may have pinned, and what to do about a pin past that.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub struct PinLimit {
+    /// `None` is no limit.
+    pub max: Option<usize>,
+    /// Unpin the longest-pinned pads to make room instead of refusing.
+    pub evict_oldest: bool,
+}
+
 pub fn pin<S: DataStore>(
     store: &mut S,
     scope: Scope,
     selectors: &[PadSelector],
 ) -> Result<CmdResult> {
-    pin_state(store, scope, selectors, true)
+    pin_within(store, scope, selectors, PinLimit::default())
 }
 
-pub fn unpin<S: DataStore>(
+/// Pins the selected pads, keeping the scope within `limit`.
+pub fn pin_within<S: DataStore>(
     store: &mut S,
     scope: Scope,
     selectors: &[PadSelector],
+    limit: PinLimit,
 ) -> Result<CmdResult> {
-    pin_state(store, scope, selectors, false)
+    // Resolved before any eviction renumbers the pinned pads.
+    let resolved = resolve_selectors(store, scope, selectors, false, TitleBucket::Active)?;
+    let mut result = CmdResult::default();
+    if let Some(max) = limit.max {
+        make_room(
+            store,
+            scope,
+            &resolved,
+            max,
+            limit.evict_oldest,
+            &mut result,
+        )?;
+    }
+    set_pinned(store, scope, resolved, true, result)
 }
 
-fn pin_state<S: DataStore>(
+pub fn unpin<S: DataStore>(
     store: &mut S,
     scope: Scope,
     selectors: &[PadSelector],
-    is_pinned: bool,
 ) -> Result<CmdResult> {
     let resolved = resolve_selectors(store, scope, selectors, false, TitleBucket::Active)?;
-    let mut result = CmdResult::default();
+    set_pinned(store, scope, resolved, false, CmdResult::default())
+}
 
+/// Fails if pinning `resolved` would leave more than `max` pads pinned,
+/// unless `evict` is set: then the longest-pinned pads not among them are
+/// unpinned until it would not.
+fn make_room<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    resolved: &[(Vec<DisplayIndex>, Uuid)],
+    max: usize,
+    evict: bool,
+    result: &mut CmdResult,
+) -> Result<()> {
+    let wanted: HashSet<Uuid> = resolved.iter().map(|(_, id)| *id).collect();
+    let mut pinned: Vec<_> = store
+        .list_metadata(scope, Bucket::Active)?
+        .into_iter()
+        .filter(|m| m.is_pinned)
+        .collect();
+    let adding = wanted
+        .iter()
+        .filter(|id| !pinned.iter().any(|m| m.id == **id))
+        .count();
+    let over = (pinned.len() + adding).saturating_sub(max);
+    if over == 0 {
+        return Ok(());
+    }
+    if wanted.len() > max {
+        return Err(PadzError::Api(format!(
+            "Cannot pin {} pads: max_pins is {}",
+            wanted.len(),
+            max
+        )));
+    }
+    if !evict {
+        return Err(PadzError::Api(format!(
+            "{} pads are pinned already and max_pins is {}: unpin one, or pass --evict to unpin the oldest",
+            pinned.len(),
+            max
+        )));
+    }
+
+    pinned.retain(|m| !wanted.contains(&m.id));
+    pinned.sort_by_key(|m| m.pinned_at.unwrap_or(m.created_at));
+    for mut metadata in pinned.into_iter().take(over) {
+        metadata.set_attr("pinned", AttrValue::Bool(false));
+        store.save_metadata(&metadata, scope, Bucket::Active)?;
+        result.notices.push(CmdNotice::UnpinnedToMakeRoom {
+            title: metadata.title,
+        });
+    }
+    Ok(())
+}
+
+fn set_pinned<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    resolved: Vec<(Vec<DisplayIndex>, Uuid)>,
+    is_pinned: bool,
+    mut result: CmdResult,
+) -> Result<CmdResult> {
     // Collect UUIDs and perform pin/unpin
     let mut affected_uuids: Vec<Uuid> = Vec::new();
     for (display_index, uuid) in resolved {
@@ -286,4 +376,50 @@ mod tests {
         assert_eq!(pinned_count, 2);
         assert_eq!(regular_count, 2);
     }
+
+    #[test]
+    fn a_pin_past_max_pins_fails_unless_the_oldest_makes_room() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        for title in ["Oldest", "Newer", "Extra"] {
+            create::run(&mut store, Scope::Project, title.into(), "".into(), None).unwrap();
+        }
+        let title = |t: &str| [PadSelector::Title(t.into())];
+        let refuse = PinLimit {
+            max: Some(2),
+            evict_oldest: false,
+        };
+        pin_within(&mut store, Scope::Project, &title("Oldest"), refuse).unwrap();
+        pin_within(&mut store, Scope::Project, &title("Newer"), refuse).unwrap();
+
+        let err = pin_within(&mut store, Scope::Project, &title("Extra"), refuse).unwrap_err();
+        assert!(err.to_string().contains("max_pins is 2"));
+        // Pinning one that is already pinned takes no room.
+        pin_within(&mut store, Scope::Project, &title("Newer"), refuse).unwrap();
+
+        let evict = PinLimit {
+            evict_oldest: true,
+            ..refuse
+        };
+        let result = pin_within(&mut store, Scope::Project, &title("Extra"), evict).unwrap();
+        assert_eq!(
+            result.notices,
+            vec![CmdNotice::UnpinnedToMakeRoom {
+                title: "Oldest".into()
+            }]
+        );
+        let mut pinned: Vec<String> = store
+            .list_metadata(Scope::Project, Bucket::Active)
+            .unwrap()
+            .into_iter()
+            .filter(|m| m.is_pinned)
+            .map(|m| m.title)
+            .collect();
+        pinned.sort();
+        assert_eq!(pinned, vec!["Extra", "Newer"]);
+    }
 }
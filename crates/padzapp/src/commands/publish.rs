@@ -0,0 +1,253 @@
+//! Publishing pads as gists and GitLab snippets (`padz publish`).
+//!
+//! The upload itself is the client's: this module decides what goes up and
+//! keeps track of where it went. [`uploads`] turns the selected pads into
+//! [`Upload`]s, the client sends them, and [`record`] stores the resulting
+//! [`Publication`] on the pad, one per service. A pad's `updated_at` is
+//! recorded with it, so [`stale`] later finds the pads edited since their
+//! upload, for `publish --update` to send again.
+
+use crate::commands::export::sanitize_filename;
+use crate::commands::helpers::{pads_by_selectors, TitleBucket};
+use crate::error::{PadzError, Result};
+use crate::index::PadSelector;
+use crate::model::{Pad, Publication, PublishService, Scope};
+use crate::store::{Bucket, DataStore};
+use chrono::{DateTime, Utc};
+use uuid::Uuid;
+
+/// One pad to send to a service.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Upload {
+    pub pad: Uuid,
+    pub title: String,
+    /// The file the pad is uploaded as, named after its title.
+    pub filename: String,
+    pub content: String,
+    /// The pad's `updated_at`, recorded as the published revision.
+    pub revision: DateTime<Utc>,
+    /// Where the pad already is, for an update.
+    pub previous: Option<Publication>,
+}
+
+impl Upload {
+    fn of(pad: &Pad, previous: Option<Publication>) -> Self {
+        let name = sanitize_filename(&pad.metadata.title);
+        Upload {
+            pad: pad.metadata.id,
+            title: pad.metadata.title.clone(),
+            filename: format!("{}.md", if name.is_empty() { "pad" } else { &name }),
+            content: pad.content.clone(),
+            revision: pad.metadata.updated_at,
+            previous,
+        }
+    }
+
+    /// The publication this upload makes, once the service named it.
+    pub fn published(&self, service: PublishService, id: String, url: String) -> Publication {
+        Publication {
+            service,
+            id,
+            url,
+            filename: self.filename.clone(),
+            revision: self.revision,
+        }
+    }
+}
+
+/// The selected pads, to publish on `service` for the first time. A pad
+/// already there is an error that points at `--update`.
+pub fn uploads<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    service: PublishService,
+) -> Result<Vec<Upload>> {
+    let mut uploads: Vec<Upload> = Vec::new();
+    for dp in pads_by_selectors(store, scope, selectors, false, TitleBucket::Active)? {
+        if uploads.iter().any(|u| u.pad == dp.pad.metadata.id) {
+            continue;
+        }
+        if let Some(existing) = publication(&dp.pad, service) {
+            return Err(PadzError::Api(format!(
+                "'{}' is already a {} at {}; send edits with `padz publish --update`",
+                dp.pad.metadata.title, service, existing.url
+            )));
+        }
+        uploads.push(Upload::of(&dp.pad, None));
+    }
+    Ok(uploads)
+}
+
+/// The published copies edited since their upload: of the selected pads, or
+/// with no selectors of every active pad. `service` narrows it to one service.
+/// A selected pad that was never published is an error.
+pub fn stale<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    service: Option<PublishService>,
+) -> Result<Vec<(PublishService, Upload)>> {
+    let pads: Vec<Pad> = if selectors.is_empty() {
+        store.list_pads(scope, Bucket::Active)?
+    } else {
+        let selected: Vec<Pad> =
+            pads_by_selectors(store, scope, selectors, false, TitleBucket::Active)?
+                .into_iter()
+                .map(|dp| dp.pad)
+                .collect();
+        if let Some(pad) = selected.iter().find(|pad| {
+            !pad.metadata
+                .published
+                .iter()
+                .any(|p| service.map_or(true, |s| p.service == s))
+        }) {
+            return Err(PadzError::Api(format!(
+                "'{}' is not published; publish it with `padz publish <id> --gist` or `--gitlab-snippet`",
+                pad.metadata.title
+            )));
+        }
+        selected
+    };
+
+    let mut stale = Vec::new();
+    for (i, pad) in pads.iter().enumerate() {
+        if pads[..i].iter().any(|p| p.metadata.id == pad.metadata.id) {
+            continue;
+        }
+        for published in &pad.metadata.published {
+            if service.is_some_and(|s| s != published.service) {
+                continue;
+            }
+            if published.revision != pad.metadata.updated_at {
+                stale.push((published.service, Upload::of(pad, Some(published.clone()))));
+            }
+        }
+    }
+    Ok(stale)
+}
+
+/// Records `publication` on pad `id`, in place of any earlier one on the same
+/// service. The pad's content and `updated_at` are left alone.
+pub fn record<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    id: Uuid,
+    publication: Publication,
+) -> Result<()> {
+    let mut metadata = store.get_pad(&id, scope, Bucket::Active)?.metadata;
+    metadata
+        .published
+        .retain(|p| p.service != publication.service);
+    metadata.published.push(publication);
+    store.save_metadata(&metadata, scope, Bucket::Active)
+}
+
+fn publication(pad: &Pad, service: PublishService) -> Option<&Publication> {
+    pad.metadata.published.iter().find(|p| p.service == service)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, update};
+    use crate::index::DisplayIndex;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn store_with(titles: &[&str]) -> BucketedStore<MemBackend> {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        for title in titles {
+            create::run(
+                &mut store,
+                Scope::Project,
+                title.to_string(),
+                "".into(),
+                None,
+            )
+            .unwrap();
+        }
+        store
+    }
+
+    fn index(n: usize) -> Vec<PadSelector> {
+        vec![PadSelector::Path(vec![DisplayIndex::Regular(n)])]
+    }
+
+    fn publish(store: &mut BucketedStore<MemBackend>, n: usize, service: PublishService) {
+        for upload in uploads(store, Scope::Project, &index(n), service).unwrap() {
+            let publication =
+                upload.published(service, "abc123".into(), "https://example.com".into());
+            record(store, Scope::Project, upload.pad, publication).unwrap();
+        }
+    }
+
+    #[test]
+    fn a_published_pad_is_recorded_and_not_published_twice() {
+        let mut store = store_with(&["Deploy notes"]);
+        let upload = &uploads(&store, Scope::Project, &index(1), PublishService::Gist).unwrap()[0];
+        assert_eq!(upload.filename, "Deploy notes.md");
+
+        publish(&mut store, 1, PublishService::Gist);
+        let pad = &store.list_pads(Scope::Project, Bucket::Active).unwrap()[0];
+        assert_eq!(pad.metadata.published.len(), 1);
+        assert_eq!(pad.metadata.published[0].revision, pad.metadata.updated_at);
+
+        let again = uploads(&store, Scope::Project, &index(1), PublishService::Gist);
+        assert!(again.unwrap_err().to_string().contains("--update"));
+        assert!(uploads(
+            &store,
+            Scope::Project,
+            &index(1),
+            PublishService::GitlabSnippet
+        )
+        .is_ok());
+    }
+
+    #[test]
+    fn only_pads_edited_since_their_upload_are_stale() {
+        let mut store = store_with(&["Deploy notes", "Runbook"]);
+        publish(&mut store, 1, PublishService::Gist);
+        publish(&mut store, 2, PublishService::Gist);
+        assert!(stale(&store, Scope::Project, &[], None).unwrap().is_empty());
+
+        let first = pads_by_selectors(
+            &store,
+            Scope::Project,
+            &index(1),
+            false,
+            TitleBucket::Active,
+        )
+        .unwrap()[0]
+            .pad
+            .metadata
+            .title
+            .clone();
+        std::thread::sleep(std::time::Duration::from_millis(5));
+        update::run_from_content(
+            &mut store,
+            Scope::Project,
+            &index(1),
+            &format!("{}\n\nRolled back twice", first),
+        )
+        .unwrap();
+
+        let stale = stale(&store, Scope::Project, &[], None).unwrap();
+        assert_eq!(stale.len(), 1);
+        assert_eq!(stale[0].0, PublishService::Gist);
+        assert_eq!(stale[0].1.title, first);
+        assert!(stale[0].1.previous.is_some());
+    }
+
+    #[test]
+    fn updating_a_pad_never_published_is_an_error() {
+        let store = store_with(&["Deploy notes"]);
+        let err = stale(&store, Scope::Project, &index(1), None).unwrap_err();
+        assert!(err.to_string().contains("not published"));
+    }
+}
@@ -0,0 +1,378 @@
+//! Filter expressions (`--where`).
+//!
+//! A query is a few conditions joined by `and`, each a field, an operator and
+//! a value:
+//!
+//! ```text
+//! project=webapp and created>30d and tag!=archive
+//! ```
+//!
+//! | Field                | Operators  | Value                                   |
+//! |----------------------|------------|-----------------------------------------|
+//! | `tag`                | `=` `!=`   | a tag name                              |
+//! | `status`             | `=` `!=`   | `planned`, `in-progress` or `done`      |
+//! | `pinned`             | `=`        | `yes` or `no`                           |
+//! | `project`            | `=` `!=`   | a project's directory name, or its path |
+//! | `branch`             | `=` `!=`   | the git branch the pad was created on   |
+//! | `created`, `updated` | `>` `<`    | an age: `12h`, `30d`, `2w`              |
+//!
+//! Ages read as "longer ago than" and "more recently than": `created>30d` is a
+//! pad created over 30 days ago. Tag, status and pinned conditions are
+//! [`AttrFilter`]s, the filters behind `--tag` and `--completed`; a pad's
+//! project is the one `list --group-by project` files it under (see
+//! [`super::grouping::project`]). A pad's branch is the one its creation
+//! context recorded ([`crate::model::CreationContext`]); a pad without one is
+//! on no branch.
+//!
+//! A listing keeps the parents of matching children, as the other listing
+//! filters do. The bulk commands act on every matching pad, at any depth, as
+//! if each had been named on the command line.
+
+use crate::attributes::{AttrFilter, AttrValue};
+use crate::error::{PadzError, Result};
+use crate::index::DisplayPad;
+use crate::model::{Metadata, TodoStatus};
+use chrono::{DateTime, Duration, Utc};
+use std::path::{Path, PathBuf};
+
+/// A parsed `--where` expression: a pad matches when every condition does.
+#[derive(Debug, Clone)]
+pub struct Query {
+    conditions: Vec<Condition>,
+}
+
+#[derive(Debug, Clone)]
+enum Condition {
+    Attr(AttrFilter),
+    Project {
+        name: String,
+        negated: bool,
+    },
+    Branch {
+        name: String,
+        negated: bool,
+    },
+    Age {
+        stamp: Stamp,
+        older: bool,
+        age: Duration,
+    },
+}
+
+#[derive(Debug, Clone, Copy)]
+enum Stamp {
+    Created,
+    Updated,
+}
+
+/// What a query is evaluated against besides the pad itself.
+#[derive(Debug, Clone, Copy)]
+pub struct QueryContext<'a> {
+    pub now: DateTime<Utc>,
+    /// The known project roots, as for [`super::grouping::group`].
+    pub projects: &'a [PathBuf],
+    /// The project pads without a creation context belong to, if any.
+    pub home: Option<&'a Path>,
+}
+
+impl Query {
+    pub fn parse(expression: &str) -> Result<Self> {
+        let words: Vec<&str> = expression.split_whitespace().collect();
+        let mut conditions = Vec::new();
+        for clause in words.split(|word| word.eq_ignore_ascii_case("and")) {
+            if clause.is_empty() {
+                return Err(PadzError::Api(format!(
+                    "Invalid query '{}': expected conditions joined by 'and'",
+                    expression
+                )));
+            }
+            conditions.push(Condition::parse(&clause.concat())?);
+        }
+        Ok(Self { conditions })
+    }
+
+    /// The pads created on git branch `name` (`branch=<name>`).
+    pub fn branch(name: &str) -> Self {
+        Self {
+            conditions: vec![Condition::Branch {
+                name: name.to_string(),
+                negated: false,
+            }],
+        }
+    }
+
+    /// The pads both this query and `other` match.
+    pub fn and(mut self, other: Query) -> Self {
+        self.conditions.extend(other.conditions);
+        self
+    }
+
+    pub fn matches(&self, metadata: &Metadata, context: &QueryContext) -> bool {
+        self.conditions
+            .iter()
+            .all(|condition| condition.matches(metadata, context))
+    }
+}
+
+impl Condition {
+    fn parse(text: &str) -> Result<Self> {
+        let invalid = |why: &str| PadzError::Api(format!("Invalid condition '{}': {}", text, why));
+        let at = text
+            .find(['=', '!', '<', '>'])
+            .ok_or_else(|| invalid("expected a field, an operator and a value, as in tag=work"))?;
+        let (field, rest) = text.split_at(at);
+        let (op, value) = ["!=", "=", ">", "<"]
+            .into_iter()
+            .find_map(|op| rest.strip_prefix(op).map(|value| (op, value)))
+            .ok_or_else(|| invalid("the operators are =, !=, > and <"))?;
+        if value.is_empty() {
+            return Err(invalid("the value is missing"));
+        }
+
+        match (field, op) {
+            ("tag", "=") => Ok(Self::Attr(AttrFilter::contains("tags", value.to_string()))),
+            ("tag", "!=") => Ok(Self::Attr(AttrFilter::excludes("tags", value.to_string()))),
+            ("status", "=" | "!=") => {
+                let status = parse_status(value)
+                    .ok_or_else(|| invalid("a status is planned, in-progress or done"))?;
+                let status = AttrValue::Enum(format!("{:?}", status));
+                Ok(Self::Attr(if op == "=" {
+                    AttrFilter::eq("status", status)
+                } else {
+                    AttrFilter::ne("status", status)
+                }))
+            }
+            ("pinned", "=") => {
+                let pinned = match value {
+                    "yes" | "true" => true,
+                    "no" | "false" => false,
+                    _ => return Err(invalid("pinned is yes or no")),
+                };
+                Ok(Self::Attr(AttrFilter::eq(
+                    "pinned",
+                    AttrValue::Bool(pinned),
+                )))
+            }
+            ("project", "=" | "!=") => Ok(Self::Project {
+                name: value.to_string(),
+                negated: op == "!=",
+            }),
+            ("branch", "=" | "!=") => Ok(Self::Branch {
+                name: value.to_string(),
+                negated: op == "!=",
+            }),
+            ("created" | "updated", ">" | "<") => Ok(Self::Age {
+                stamp: if field == "created" {
+                    Stamp::Created
+                } else {
+                    Stamp::Updated
+                },
+                older: op == ">",
+                age: parse_age(value).ok_or_else(|| {
+                    invalid("an age is a number of hours, days or weeks: 12h, 30d, 2w")
+                })?,
+            }),
+            ("tag" | "status" | "pinned" | "project" | "branch" | "created" | "updated", _) => {
+                Err(invalid(&format!("{} does not take {}", field, op)))
+            }
+            _ => Err(invalid(
+                "the fields are tag, status, pinned, project, branch, created and updated",
+            )),
+        }
+    }
+
+    fn matches(&self, metadata: &Metadata, context: &QueryContext) -> bool {
+        match self {
+            Self::Attr(filter) => filter.matches(metadata),
+            Self::Project { name, negated } => {
+                let project = super::grouping::project(metadata, context.projects, context.home);
+                let found = project.is_some_and(|dir| {
+                    dir.file_name()
+                        .is_some_and(|dir_name| dir_name == name.as_str())
+                        || dir == Path::new(name)
+                });
+                found != *negated
+            }
+            Self::Branch { name, negated } => {
+                let branch = metadata.context.as_ref().and_then(|c| c.branch.as_deref());
+                (branch == Some(name.as_str())) != *negated
+            }
+            Self::Age { stamp, older, age } => {
+                let at = match stamp {
+                    Stamp::Created => metadata.created_at,
+                    Stamp::Updated => metadata.updated_at,
+                };
+                let elapsed = context.now - at;
+                if *older {
+                    elapsed > *age
+                } else {
+                    elapsed < *age
+                }
+            }
+        }
+    }
+}
+
+fn parse_status(value: &str) -> Option<TodoStatus> {
+    match value.to_lowercase().replace(['-', '_'], "").as_str() {
+        "planned" => Some(TodoStatus::Planned),
+        "inprogress" => Some(TodoStatus::InProgress),
+        "done" => Some(TodoStatus::Done),
+        _ => None,
+    }
+}
+
+fn parse_age(value: &str) -> Option<Duration> {
+    let unit = value.chars().last()?;
+    let count: i64 = value[..value.len() - unit.len_utf8()].parse().ok()?;
+    match unit {
+        'h' => Some(Duration::hours(count)),
+        'd' => Some(Duration::days(count)),
+        'w' => Some(Duration::weeks(count)),
+        _ => None,
+    }
+}
+
+/// Keeps the pads `query` matches, with the parents of matching children.
+pub fn filter(pads: Vec<DisplayPad>, query: &Query, context: &QueryContext) -> Vec<DisplayPad> {
+    pads.into_iter()
+        .filter_map(|mut dp| {
+            dp.children = filter(std::mem::take(&mut dp.children), query, context);
+            (query.matches(&dp.pad.metadata, context) || !dp.children.is_empty()).then_some(dp)
+        })
+        .collect()
+}
+
+/// Every pad in `pads`, at any depth, that `query` matches itself.
+pub fn matching<'a>(
+    pads: &'a [DisplayPad],
+    query: &Query,
+    context: &QueryContext,
+) -> Vec<&'a DisplayPad> {
+    let mut found = Vec::new();
+    for dp in pads {
+        if query.matches(&dp.pad.metadata, context) {
+            found.push(dp);
+        }
+        found.extend(matching(&dp.children, query, context));
+    }
+    found
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::index::DisplayIndex;
+    use crate::model::{CreationContext, Pad};
+
+    fn pad(title: &str, days_old: i64, tags: &[&str], cwd: Option<&str>) -> DisplayPad {
+        let mut pad = Pad::new(title.to_string(), String::new());
+        pad.metadata.created_at = Utc::now() - Duration::days(days_old);
+        pad.metadata.tags = tags.iter().map(|tag| tag.to_string()).collect();
+        pad.metadata.context = cwd.map(|cwd| CreationContext {
+            cwd: cwd.to_string(),
+            commit: String::new(),
+            branch: None,
+            dirty: false,
+        });
+        DisplayPad {
+            pad,
+            index: DisplayIndex::Regular(1),
+            matches: None,
+            children: Vec::new(),
+        }
+    }
+
+    fn titles(pads: &[&DisplayPad]) -> Vec<String> {
+        pads.iter()
+            .map(|dp| dp.pad.metadata.title.clone())
+            .collect()
+    }
+
+    #[test]
+    fn conditions_are_joined_by_and() {
+        let projects = vec![PathBuf::from("/work/webapp")];
+        let context = QueryContext {
+            now: Utc::now(),
+            projects: &projects,
+            home: None,
+        };
+        let pads = vec![
+            pad("old", 40, &[], Some("/work/webapp/src")),
+            pad("archived", 40, &["archive"], Some("/work/webapp")),
+            pad("recent", 2, &[], Some("/work/webapp")),
+            pad("elsewhere", 40, &[], Some("/tmp")),
+        ];
+
+        let query = Query::parse("project=webapp and created>30d AND tag!=archive").unwrap();
+        assert_eq!(titles(&matching(&pads, &query, &context)), vec!["old"]);
+
+        let query = Query::parse("created < 1w").unwrap();
+        assert_eq!(titles(&matching(&pads, &query, &context)), vec!["recent"]);
+    }
+
+    #[test]
+    fn a_branch_is_the_one_the_pad_was_created_on() {
+        let context = QueryContext {
+            now: Utc::now(),
+            projects: &[],
+            home: None,
+        };
+        let mut on_branch = pad("login fix", 1, &[], Some("/work/webapp"));
+        if let Some(creation) = &mut on_branch.pad.metadata.context {
+            creation.branch = Some("fix/login".into());
+        }
+        let pads = vec![on_branch, pad("no context", 1, &[], None)];
+
+        let query = Query::parse("branch=fix/login").unwrap();
+        assert_eq!(
+            titles(&matching(&pads, &query, &context)),
+            vec!["login fix"]
+        );
+        let query = Query::parse("branch!=fix/login").unwrap();
+        assert_eq!(
+            titles(&matching(&pads, &query, &context)),
+            vec!["no context"]
+        );
+        let query = Query::branch("fix/login").and(Query::parse("created>1w").unwrap());
+        assert!(matching(&pads, &query, &context).is_empty());
+    }
+
+    #[test]
+    fn listings_keep_the_parents_of_matching_children() {
+        let context = QueryContext {
+            now: Utc::now(),
+            projects: &[],
+            home: None,
+        };
+        let mut parent = pad("parent", 1, &[], None);
+        parent.children = vec![pad("child", 1, &["work"], None), pad("other", 1, &[], None)];
+
+        let query = Query::parse("tag=work").unwrap();
+        let kept = filter(vec![parent.clone()], &query, &context);
+        assert_eq!(kept.len(), 1);
+        assert_eq!(kept[0].children.len(), 1);
+        assert_eq!(kept[0].children[0].pad.metadata.title, "child");
+        assert_eq!(
+            titles(&matching(&[parent], &query, &context)),
+            vec!["child"]
+        );
+    }
+
+    #[test]
+    fn malformed_conditions_are_rejected() {
+        for expression in [
+            "",
+            "tag=work and",
+            "tag",
+            "tag>work",
+            "colour=red",
+            "status=later",
+            "created>soon",
+            "pinned=maybe",
+            "tag=",
+        ] {
+            assert!(Query::parse(expression).is_err(), "{}", expression);
+        }
+    }
+}
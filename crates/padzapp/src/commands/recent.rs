@@ -0,0 +1,150 @@
+//! Access tracking: which pads were read most recently.
+//!
+//! Reading a pad (view, open, peek) stamps `last_accessed_at` on its metadata.
+//! The stamp is written to the index only — never to the content file — so an
+//! access does not count as an edit and leaves `updated_at` ordering alone.
+
+use crate::commands::CmdResult;
+use crate::error::Result;
+use crate::index::{DisplayIndex, DisplayPad};
+use crate::model::Scope;
+use crate::store::{Bucket, DataStore};
+use chrono::Utc;
+use std::cmp::Reverse;
+use uuid::Uuid;
+
+use super::helpers::indexed_pads;
+
+/// Stamp the given active pads as accessed now.
+///
+/// Ids that are not in the active bucket (archived or deleted pads that were
+/// viewed) are skipped: recency is a navigation aid for live pads only.
+pub fn touch<S: DataStore>(store: &mut S, scope: Scope, ids: &[Uuid]) -> Result<()> {
+    let now = Utc::now();
+    for id in ids {
+        let Ok(pad) = store.get_pad(id, scope, Bucket::Active) else {
+            continue;
+        };
+        let mut metadata = pad.metadata;
+        metadata.last_accessed_at = Some(now);
+        store.save_metadata(&metadata, scope, Bucket::Active)?;
+    }
+    Ok(())
+}
+
+/// List up to `limit` root-level active pads that have been accessed, most
+/// recent first, each under its canonical regular index.
+pub fn run<S: DataStore>(store: &S, scope: Scope, limit: usize) -> Result<CmdResult> {
+    let mut recent: Vec<DisplayPad> = indexed_pads(store, scope)?
+        .into_iter()
+        .filter(|dp| matches!(dp.index, DisplayIndex::Regular(_)))
+        .filter(|dp| dp.pad.metadata.last_accessed_at.is_some())
+        .collect();
+    recent.sort_by_key(|dp| Reverse(dp.pad.metadata.last_accessed_at));
+    recent.truncate(limit);
+    Ok(CmdResult::default().with_listed_pads(recent))
+}
+
+/// Reorder a root listing by access time, most recent first.
+///
+/// The sort is stable and never crosses a lifecycle section: pinned pads stay
+/// ahead of regular ones, and archived/deleted pads stay behind. Pads that were
+/// never accessed keep their canonical order at the end of their section.
+pub fn sort_by_access(pads: &mut [DisplayPad]) {
+    pads.sort_by_key(|dp| {
+        let section = match dp.index {
+            DisplayIndex::Pinned(_) => 0,
+            DisplayIndex::Regular(_) => 1,
+            DisplayIndex::Archived(_) => 2,
+            DisplayIndex::Deleted(_) => 3,
+        };
+        (section, Reverse(dp.pad.metadata.last_accessed_at))
+    });
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::create;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn store() -> BucketedStore<MemBackend> {
+        BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        )
+    }
+
+    fn create_pad(store: &mut BucketedStore<MemBackend>, title: &str) -> Uuid {
+        create::run(store, Scope::Project, title.into(), "".into(), None)
+            .unwrap()
+            .affected_pads[0]
+            .pad
+            .metadata
+            .id
+    }
+
+    #[test]
+    fn touch_stamps_access_without_changing_updated_at() {
+        let mut store = store();
+        let id = create_pad(&mut store, "Read me");
+        let before = store.get_pad(&id, Scope::Project, Bucket::Active).unwrap();
+
+        touch(&mut store, Scope::Project, &[id]).unwrap();
+
+        let after = store.get_pad(&id, Scope::Project, Bucket::Active).unwrap();
+        assert!(after.metadata.last_accessed_at.is_some());
+        assert_eq!(after.metadata.updated_at, before.metadata.updated_at);
+    }
+
+    #[test]
+    fn recent_lists_only_accessed_pads_newest_first() {
+        let mut store = store();
+        let first = create_pad(&mut store, "First");
+        let second = create_pad(&mut store, "Second");
+        create_pad(&mut store, "Never read");
+
+        touch(&mut store, Scope::Project, &[second]).unwrap();
+        std::thread::sleep(std::time::Duration::from_millis(5));
+        touch(&mut store, Scope::Project, &[first]).unwrap();
+
+        let result = run(&store, Scope::Project, 10).unwrap();
+
+        let titles: Vec<&str> = result
+            .listed_pads
+            .iter()
+            .map(|dp| dp.pad.metadata.title.as_str())
+            .collect();
+        assert_eq!(titles, vec!["First", "Second"]);
+    }
+
+    #[test]
+    fn recent_respects_the_limit() {
+        let mut store = store();
+        let a = create_pad(&mut store, "A");
+        let b = create_pad(&mut store, "B");
+        touch(&mut store, Scope::Project, &[a, b]).unwrap();
+
+        let result = run(&store, Scope::Project, 1).unwrap();
+
+        assert_eq!(result.listed_pads.len(), 1);
+    }
+
+    #[test]
+    fn sort_by_access_keeps_sections_apart() {
+        let mut store = store();
+        let old = create_pad(&mut store, "Old");
+        create_pad(&mut store, "New");
+        touch(&mut store, Scope::Project, &[old]).unwrap();
+
+        let mut pads = indexed_pads(&store, Scope::Project).unwrap();
+        sort_by_access(&mut pads);
+
+        assert_eq!(pads[0].pad.metadata.title, "Old");
+        assert_eq!(pads[0].index, DisplayIndex::Regular(2));
+        assert_eq!(pads[1].pad.metadata.title, "New");
+    }
+}
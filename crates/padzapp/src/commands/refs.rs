@@ -0,0 +1,182 @@
+//! References: global pads listed in a project (`padz ref add`).
+//!
+//! A reference is a project pad standing in for a global one, the way a
+//! symlink stands in for a file: its `source` names the global pad, and
+//! reading or editing it reaches the source. A runbook kept once in the global
+//! store can so appear in every project that needs it, and an edit made from
+//! any of them is an edit to the one copy. Otherwise the reference is an
+//! ordinary pad with its own index, to pin, tag or nest like any other;
+//! deleting it leaves the source alone.
+//!
+//! A reference keeps only the source's title, for listings that have not
+//! looked the source up; [`follow`] fills in the current title and content.
+//! When the source is gone, the reference shows that last known title and
+//! nothing else.
+
+use crate::commands::CmdResult;
+use crate::error::{PadzError, Result};
+use crate::index::{DisplayPad, PadSelector};
+use crate::model::{CreationContext, Metadata, Scope};
+use crate::store::{Bucket, DataStore};
+use uuid::Uuid;
+
+use super::helpers::{find_pad_by_uuid, indexed_pads, resolve_selectors, TitleBucket};
+
+/// Adds a reference in the project to each global pad `selectors` picks.
+/// A pad the project already refers to is not added twice: its existing
+/// reference is reported instead.
+pub fn add<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    context: Option<CreationContext>,
+    owner: Option<String>,
+) -> Result<CmdResult> {
+    if scope != Scope::Project {
+        return Err(PadzError::Api(
+            "References are added to a project; run `padz ref add` inside one".to_string(),
+        ));
+    }
+    let sources = resolve_selectors(store, Scope::Global, selectors, false, TitleBucket::Active)?;
+
+    let mut result = CmdResult::default();
+    for (_, source) in sources {
+        let existing = store
+            .list_metadata(scope, Bucket::Active)?
+            .into_iter()
+            .find(|meta| meta.source == Some(source));
+        if let Some(meta) = existing {
+            let indexed = indexed_pads(store, scope)?;
+            if let Some(dp) = find_pad_by_uuid(&indexed, meta.id, |_| true) {
+                result.affected_pads.push(DisplayPad {
+                    children: Vec::new(),
+                    ..dp.clone()
+                });
+            }
+            continue;
+        }
+
+        let title = store
+            .get_pad(&source, Scope::Global, Bucket::Active)?
+            .metadata
+            .title;
+        let mut created = super::create::run_with_context(
+            store,
+            scope,
+            title,
+            String::new(),
+            None,
+            context.clone(),
+            owner.clone(),
+        )?;
+        let mut meta = created.affected_pads[0].pad.metadata.clone();
+        meta.source = Some(source);
+        store.save_metadata(&meta, scope, Bucket::Active)?;
+        created.affected_pads[0].pad.metadata = meta;
+        result.affected_pads.append(&mut created.affected_pads);
+        result.pad_paths.append(&mut created.pad_paths);
+    }
+    Ok(result)
+}
+
+/// Where reads and writes of pad `meta` in `scope` go: the source of a
+/// reference, anything else to itself.
+pub fn target(meta: &Metadata, scope: Scope) -> (Scope, Uuid) {
+    match meta.source {
+        Some(source) if scope == Scope::Project => (Scope::Global, source),
+        _ => (scope, meta.id),
+    }
+}
+
+/// Shows each reference among `pads`, nested ones included, with its
+/// source's current title and content. The reference keeps its own id and
+/// index, so it is still selected as itself.
+pub fn follow<S: DataStore>(store: &S, scope: Scope, pads: &mut [DisplayPad]) -> Result<()> {
+    if scope != Scope::Project {
+        return Ok(());
+    }
+    for dp in pads {
+        let (source_scope, source) = target(&dp.pad.metadata, scope);
+        if source_scope != scope {
+            if let Ok(pad) = store.get_pad(&source, source_scope, Bucket::Active) {
+                dp.pad.metadata.title = pad.metadata.title;
+                dp.pad.content = pad.content;
+            }
+        }
+        follow(store, scope, &mut dp.children)?;
+    }
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, update, view, NestingMode};
+    use crate::index::DisplayIndex;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn store() -> BucketedStore<MemBackend> {
+        BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        )
+    }
+
+    fn first() -> Vec<PadSelector> {
+        vec![PadSelector::Path(vec![DisplayIndex::Regular(1)])]
+    }
+
+    #[test]
+    fn a_reference_reads_and_writes_its_source() {
+        let mut store = store();
+        create::run(
+            &mut store,
+            Scope::Global,
+            "Runbook".into(),
+            "Restart the queue".into(),
+            None,
+        )
+        .unwrap();
+
+        add(&mut store, Scope::Project, &first(), None, None).unwrap();
+        // Adding it again finds the reference already there.
+        add(&mut store, Scope::Project, &first(), None, None).unwrap();
+        assert_eq!(
+            store
+                .list_metadata(Scope::Project, Bucket::Active)
+                .unwrap()
+                .len(),
+            1
+        );
+
+        update::run_from_content(
+            &mut store,
+            Scope::Project,
+            &first(),
+            "Runbook\n\nRestart the queue, then the workers",
+        )
+        .unwrap();
+        let global = view::run(&store, Scope::Global, &first(), NestingMode::Flat).unwrap();
+        assert_eq!(
+            global.listed_pads[0].pad.content,
+            "Runbook\n\nRestart the queue, then the workers"
+        );
+
+        let mut project = view::run(&store, Scope::Project, &first(), NestingMode::Flat)
+            .unwrap()
+            .listed_pads;
+        follow(&store, Scope::Project, &mut project).unwrap();
+        assert_eq!(project[0].pad.content, global.listed_pads[0].pad.content);
+        assert!(project[0].pad.metadata.source.is_some());
+    }
+
+    #[test]
+    fn references_are_added_only_to_a_project() {
+        let mut store = store();
+        create::run(&mut store, Scope::Global, "Runbook".into(), "".into(), None).unwrap();
+        assert!(add(&mut store, Scope::Global, &first(), None, None).is_err());
+    }
+}
@@ -0,0 +1,154 @@
+//! Release notes drafted from tagged pads (`padz release-notes`).
+//!
+//! Notes worth telling users about get written while the change is made: "the
+//! rate limiter retries twice now", "`sync_dir` is renamed". Tagged as they are
+//! written (`release`, unless the command is told otherwise), they are there
+//! to gather when the release is cut: the active pads that carry every tag and
+//! were written or edited since the previous release, oldest first, each as
+//! its title and body.
+//!
+//! When the previous release was is git's business, so it is the client's:
+//! this module takes a time and hands back the notes, and the CLI adds the
+//! commits made since for context.
+
+use crate::commands::helpers::{find_pad_by_uuid, indexed_pads};
+use crate::error::{PadzError, Result};
+use crate::index::DisplayIndex;
+use crate::model::{extract_title_and_body, Scope};
+use crate::store::{Bucket, DataStore};
+use chrono::{DateTime, Utc};
+use serde::{Deserialize, Serialize};
+
+/// One pad gathered into the release notes.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ReleaseNote {
+    /// The pad's regular index; `None` when the store's index does not list
+    /// the pad, which the notes do not need.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub index: Option<DisplayIndex>,
+    pub title: String,
+    /// The pad without its title line; empty for a title-only pad.
+    pub body: String,
+    pub created_at: DateTime<Utc>,
+    pub updated_at: DateTime<Utc>,
+}
+
+/// The active pads carrying all of `tags` and updated at or after `since`
+/// (every one of them without it), oldest first.
+pub fn run<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    tags: &[String],
+    since: Option<DateTime<Utc>>,
+) -> Result<Vec<ReleaseNote>> {
+    if tags.is_empty() {
+        return Err(PadzError::Api(
+            "Release notes need a tag to gather pads by".to_string(),
+        ));
+    }
+    let mut pads = store.list_pads(scope, Bucket::Active)?;
+    pads.retain(|pad| {
+        tags.iter().all(|tag| pad.metadata.tags.contains(tag))
+            && since.map_or(true, |since| pad.metadata.updated_at >= since)
+    });
+    pads.sort_by_key(|pad| (pad.metadata.created_at, pad.metadata.id));
+
+    let indexed = indexed_pads(store, scope)?;
+    Ok(pads
+        .into_iter()
+        .map(|pad| {
+            let index = find_pad_by_uuid(&indexed, pad.metadata.id, |idx| {
+                matches!(idx, DisplayIndex::Regular(_))
+            })
+            .map(|dp| dp.index.clone());
+            let body = extract_title_and_body(&pad.content)
+                .map(|(_, body)| body)
+                .unwrap_or_default();
+            ReleaseNote {
+                index,
+                title: pad.metadata.title,
+                body,
+                created_at: pad.metadata.created_at,
+                updated_at: pad.metadata.updated_at,
+            }
+        })
+        .collect())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, tagging, tags, update};
+    use crate::index::PadSelector;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn release() -> Vec<String> {
+        vec!["release".to_string()]
+    }
+
+    fn store_with_notes() -> BucketedStore<MemBackend> {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        for (title, body) in [
+            ("Retries", "The rate limiter retries twice now."),
+            ("Scratch", "not for users"),
+            ("Renamed sync_dir", ""),
+        ] {
+            create::run(&mut store, Scope::Project, title.into(), body.into(), None).unwrap();
+        }
+        tags::create_tag(&mut store, Scope::Project, "release").unwrap();
+        // Newest first: 1 = Renamed sync_dir, 3 = Retries.
+        let notes = vec![
+            PadSelector::Path(vec![DisplayIndex::Regular(1)]),
+            PadSelector::Path(vec![DisplayIndex::Regular(3)]),
+        ];
+        tagging::add_tags(&mut store, Scope::Project, &notes, &release()).unwrap();
+        store
+    }
+
+    #[test]
+    fn tagged_pads_are_gathered_oldest_first() {
+        let store = store_with_notes();
+        let notes = run(&store, Scope::Project, &release(), None).unwrap();
+        let titles: Vec<&str> = notes.iter().map(|n| n.title.as_str()).collect();
+        assert_eq!(titles, ["Retries", "Renamed sync_dir"]);
+        assert_eq!(notes[0].index, Some(DisplayIndex::Regular(3)));
+        assert_eq!(notes[0].body, "The rate limiter retries twice now.");
+        assert_eq!(notes[1].body, "");
+    }
+
+    #[test]
+    fn only_pads_updated_since_the_last_release_are_gathered() {
+        let store = store_with_notes();
+        let later = Utc::now() + chrono::Duration::minutes(1);
+        assert!(run(&store, Scope::Project, &release(), Some(later))
+            .unwrap()
+            .is_empty());
+        assert!(run(&store, Scope::Project, &[], None).is_err());
+    }
+
+    #[test]
+    fn a_tagged_pad_edited_before_the_cutoff_is_left_out() {
+        let mut store = store_with_notes();
+        std::thread::sleep(std::time::Duration::from_millis(5));
+        let cutoff = Utc::now();
+        std::thread::sleep(std::time::Duration::from_millis(5));
+        update::run_from_content(
+            &mut store,
+            Scope::Project,
+            &[PadSelector::Path(vec![DisplayIndex::Regular(3)])],
+            "Retries\n\nThe rate limiter retries three times now.",
+        )
+        .unwrap();
+
+        let notes = run(&store, Scope::Project, &release(), Some(cutoff)).unwrap();
+        let titles: Vec<&str> = notes.iter().map(|n| n.title.as_str()).collect();
+        assert_eq!(titles, ["Retries"], "Renamed sync_dir predates the cutoff");
+        assert_eq!(notes[0].body, "The rate limiter retries three times now.");
+    }
+}
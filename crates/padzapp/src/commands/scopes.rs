@@ -0,0 +1,243 @@
+//! Cleaning up the scope registry ([`crate::store::registry`]).
+//!
+//! An orphan is a registered project store whose directory no longer exists.
+//! Pruning removes its registry entry and nothing else: the store's pads went
+//! with the directory, and global pads created from inside the project stay
+//! where they are. Those are counted per orphan, so the user can see what
+//! still refers to the project before dropping it.
+//!
+//! Remote stores ([`crate::store::remote`]) are registered here too, by name,
+//! so `--remote devbox` can stand for the full `ssh://` address.
+
+use crate::error::{PadzError, Result};
+use crate::model::Scope;
+use crate::store::registry::{self, ScopeEntry};
+use crate::store::remote::{self, RemoteStore};
+use crate::store::{Bucket, DataStore};
+use chrono::{DateTime, Utc};
+use serde::Serialize;
+use std::path::{Path, PathBuf};
+
+/// A registered project whose store no longer exists.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct OrphanScope {
+    /// The project directory that is gone.
+    pub project: PathBuf,
+    pub last_seen: DateTime<Utc>,
+    /// Global pads created from inside the project (any bucket).
+    pub global_pads: usize,
+}
+
+/// Semantic result of `scopes prune`.
+///
+/// Serializes directly as the CLI/structured payload: the `status` tag plus
+/// the orphans found, which are only removed when the prune was confirmed.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(tag = "status", rename_all = "snake_case")]
+pub enum PruneOutcome {
+    /// Every registered store still exists.
+    Clean,
+    /// Orphans were found and left alone, pending confirmation.
+    Found { orphans: Vec<OrphanScope> },
+    /// Orphans were found and removed from the registry.
+    Pruned { orphans: Vec<OrphanScope> },
+}
+
+/// Finds the registry entries in `global_dir` whose stores are gone and, when
+/// `confirmed`, removes them. `dry_run` reports a confirmed prune without
+/// writing the registry.
+pub fn prune<S: DataStore>(
+    store: &S,
+    global_dir: &Path,
+    confirmed: bool,
+    dry_run: bool,
+) -> Result<PruneOutcome> {
+    let entries = registry::load(global_dir).map_err(PadzError::Io)?;
+    // A remote's cache appears on first fetch; its absence says nothing about
+    // the remote store.
+    let (gone, kept): (Vec<ScopeEntry>, Vec<ScopeEntry>) = entries
+        .into_iter()
+        .partition(|entry| entry.remote.is_none() && !entry.path.is_dir());
+    if gone.is_empty() {
+        return Ok(PruneOutcome::Clean);
+    }
+
+    let orphans = gone
+        .iter()
+        .map(|entry| {
+            Ok(OrphanScope {
+                project: entry.project_root(),
+                last_seen: entry.last_seen,
+                global_pads: global_pads_from(store, &entry.project_root())?,
+            })
+        })
+        .collect::<Result<Vec<_>>>()?;
+    if !confirmed {
+        return Ok(PruneOutcome::Found { orphans });
+    }
+    if !dry_run {
+        registry::save(global_dir, &kept).map_err(PadzError::Io)?;
+    }
+    Ok(PruneOutcome::Pruned { orphans })
+}
+
+/// A remote store registered under a name.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct RemoteAdded {
+    pub name: String,
+    pub url: String,
+    /// Where its cached copy is kept.
+    pub cache: PathBuf,
+}
+
+/// Registers the remote store at `url` as `name`. `dry_run` validates and
+/// reports without writing the registry.
+pub fn add_remote(global_dir: &Path, name: &str, url: &str, dry_run: bool) -> Result<RemoteAdded> {
+    if name.is_empty() || name.contains(['/', ':']) || name.contains(char::is_whitespace) {
+        return Err(PadzError::Api(format!(
+            "Invalid remote name '{}': use letters, digits, '-' or '_'",
+            name
+        )));
+    }
+    let store = RemoteStore::parse(url)?;
+    let cache = store.cache_dir(global_dir);
+    if !dry_run {
+        registry::add_remote(global_dir, name, url, &cache).map_err(PadzError::Io)?;
+    }
+    Ok(RemoteAdded {
+        name: name.to_string(),
+        url: url.to_string(),
+        cache,
+    })
+}
+
+/// The remote store `target` stands for: an `ssh://` address, or the name of
+/// a registered remote.
+pub fn resolve_remote(global_dir: &Path, target: &str) -> Result<RemoteStore> {
+    if remote::is_remote_url(target) {
+        return RemoteStore::parse(target);
+    }
+    match registry::find_remote(global_dir, target).map_err(PadzError::Io)? {
+        Some(url) => RemoteStore::parse(&url),
+        None => Err(PadzError::Api(format!(
+            "No remote named '{}' (register one with `padz scopes add {} ssh://host/path`)",
+            target, target
+        ))),
+    }
+}
+
+fn global_pads_from<S: DataStore>(store: &S, project: &Path) -> Result<usize> {
+    let mut count = 0;
+    for bucket in [Bucket::Active, Bucket::Archived, Bucket::Deleted] {
+        count += store
+            .list_metadata(Scope::Global, bucket)?
+            .iter()
+            .filter_map(|meta| meta.context.as_ref())
+            .filter(|context| Path::new(&context.cwd).starts_with(project))
+            .count();
+    }
+    Ok(count)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::model::CreationContext;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+    use std::fs;
+    use tempfile::TempDir;
+
+    fn store() -> BucketedStore<MemBackend> {
+        BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        )
+    }
+
+    fn registered(temp: &TempDir, names: &[&str]) -> PathBuf {
+        let global = temp.path().join("global");
+        for name in names {
+            let padz = temp.path().join(name).join(".padz");
+            fs::create_dir_all(&padz).unwrap();
+            registry::register(&global, &padz).unwrap();
+        }
+        global
+    }
+
+    #[test]
+    fn test_nothing_to_prune_while_every_store_exists() {
+        let temp = TempDir::new().unwrap();
+        let global = registered(&temp, &["app"]);
+
+        let outcome = prune(&store(), &global, true, false).unwrap();
+
+        assert_eq!(outcome, PruneOutcome::Clean);
+        assert_eq!(registry::load(&global).unwrap().len(), 1);
+    }
+
+    #[test]
+    fn test_orphans_are_removed_only_when_confirmed() {
+        let temp = TempDir::new().unwrap();
+        let global = registered(&temp, &["app", "gone"]);
+        let gone = fs::canonicalize(temp.path().join("gone")).unwrap();
+        fs::remove_dir_all(&gone).unwrap();
+
+        let mut store = store();
+        crate::commands::create::run(&mut store, Scope::Global, "note".into(), "x".into(), None)
+            .unwrap();
+        let mut meta = store.list_metadata(Scope::Global, Bucket::Active).unwrap()[0].clone();
+        meta.context = Some(CreationContext {
+            cwd: gone.join("src").to_string_lossy().into_owned(),
+            commit: "abc123".into(),
+            branch: None,
+            dirty: false,
+        });
+        store
+            .save_metadata(&meta, Scope::Global, Bucket::Active)
+            .unwrap();
+
+        let PruneOutcome::Found { orphans } = prune(&store, &global, false, false).unwrap() else {
+            panic!("expected the orphan to be found");
+        };
+        assert_eq!(orphans.len(), 1);
+        assert_eq!(orphans[0].project, gone);
+        assert_eq!(orphans[0].global_pads, 1);
+        assert_eq!(registry::load(&global).unwrap().len(), 2);
+
+        assert!(matches!(
+            prune(&store, &global, true, true).unwrap(),
+            PruneOutcome::Pruned { .. }
+        ));
+        assert_eq!(registry::load(&global).unwrap().len(), 2);
+
+        prune(&store, &global, true, false).unwrap();
+        let left = registry::load(&global).unwrap();
+        assert_eq!(left.len(), 1);
+        assert!(left[0].path.ends_with("app/.padz"));
+    }
+
+    #[test]
+    fn test_remotes_resolve_by_name_or_address() {
+        let temp = TempDir::new().unwrap();
+        let global = temp.path().join("global");
+
+        let added = add_remote(&global, "devbox", "ssh://devbox/srv/app", false).unwrap();
+        assert_eq!(added.cache, global.join("remotes/devbox/srv_app/.padz"));
+        assert!(add_remote(&global, "dev box", "ssh://devbox/srv/app", false).is_err());
+        assert!(add_remote(&global, "bad", "devbox:/srv/app", false).is_err());
+
+        let by_name = resolve_remote(&global, "devbox").unwrap();
+        assert_eq!(by_name.path, "/srv/app/.padz");
+        let by_url = resolve_remote(&global, "ssh://other/x").unwrap();
+        assert_eq!(by_url.destination, "other");
+        assert!(resolve_remote(&global, "nope").is_err());
+        // Remotes are never orphans, even before their cache exists.
+        assert_eq!(
+            prune(&store(), &global, true, false).unwrap(),
+            PruneOutcome::Clean
+        );
+    }
+}
@@ -0,0 +1,297 @@
+//! Searching every store at once (`padz search --all-scopes`).
+//!
+//! The stores are those of [`super::tree`]: the global store, then each local
+//! project in the scope registry ([`crate::store::registry`]). Each is searched
+//! on a thread of its own, at most [`Bounds::workers`] at a time, so many
+//! registered projects cost about as long as the slowest few of them.
+//!
+//! One store cannot hold up the rest: a store not done within
+//! [`Bounds::timeout`] is given up on, reported as timed out, and its thread
+//! left to finish unheard. A store that fails to read is reported with its
+//! error. Either way the matches of the stores that answered are kept, and the
+//! outcome says which stores they do not cover.
+
+use super::get::{self, PadFilter};
+use crate::error::{PadzError, Result};
+use crate::index::DisplayPad;
+use crate::model::Scope;
+use crate::store::fs::FileStore;
+use crate::store::registry;
+use serde::Serialize;
+use std::path::{Path, PathBuf};
+use std::sync::mpsc;
+use std::sync::Arc;
+use std::thread;
+use std::time::{Duration, Instant};
+
+/// Stores searched at once.
+pub const WORKERS: usize = 8;
+
+/// How long one store may take to search before it is given up on.
+pub const SCOPE_TIMEOUT: Duration = Duration::from_secs(10);
+
+/// How wide and how long a search across stores may run.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct Bounds {
+    pub workers: usize,
+    /// Per store, counted from when its search starts.
+    pub timeout: Duration,
+}
+
+impl Default for Bounds {
+    fn default() -> Self {
+        Self {
+            workers: WORKERS,
+            timeout: SCOPE_TIMEOUT,
+        }
+    }
+}
+
+/// One store's matches.
+#[derive(Debug, Clone, Serialize)]
+pub struct ScopeMatches {
+    /// The project directory; `None` for the global store.
+    pub project: Option<PathBuf>,
+    pub pads: Vec<DisplayPad>,
+}
+
+/// Why a store's matches are not in the outcome.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(tag = "kind", rename_all = "snake_case")]
+pub enum Unsearched {
+    /// The registered project's store no longer exists.
+    Missing,
+    /// The search did not finish within [`Bounds::timeout`].
+    TimedOut,
+    /// The store could not be read.
+    Failed { error: String },
+}
+
+/// A store left out of the outcome, and why.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct UnsearchedScope {
+    /// The project directory; `None` for the global store.
+    pub project: Option<PathBuf>,
+    pub reason: Unsearched,
+}
+
+/// The stores searched, global first and then the projects in registration
+/// order, and those whose matches are missing from it.
+#[derive(Debug, Clone, Serialize)]
+pub struct AllScopesOutcome {
+    pub searched: Vec<ScopeMatches>,
+    pub unsearched: Vec<UnsearchedScope>,
+}
+
+pub fn run(global_dir: &Path, filter: PadFilter, bounds: Bounds) -> Result<AllScopesOutcome> {
+    // `None` stands for the global store, in both lists.
+    let mut projects = vec![None];
+    let mut stores = vec![None];
+    let mut unsearched = Vec::new();
+    for entry in registry::load(global_dir).map_err(PadzError::Io)? {
+        if entry.remote.is_some() {
+            continue;
+        }
+        if !entry.path.is_dir() {
+            unsearched.push(UnsearchedScope {
+                project: Some(entry.project_root()),
+                reason: Unsearched::Missing,
+            });
+            continue;
+        }
+        projects.push(Some(entry.project_root()));
+        stores.push(Some(entry.path.clone()));
+    }
+
+    let global = global_dir.to_path_buf();
+    let found = search_each(stores, bounds, move |store| {
+        let scope = if store.is_some() {
+            Scope::Project
+        } else {
+            Scope::Global
+        };
+        let store = FileStore::new_fs(store.map(Path::to_path_buf), global.clone());
+        Ok(get::run(&store, scope, filter.clone(), &[])?.listed_pads)
+    });
+
+    let mut searched = Vec::new();
+    for (project, found) in projects.into_iter().zip(found) {
+        match found {
+            Ok(pads) => searched.push(ScopeMatches { project, pads }),
+            Err(reason) => unsearched.push(UnsearchedScope { project, reason }),
+        }
+    }
+    Ok(AllScopesOutcome {
+        searched,
+        unsearched,
+    })
+}
+
+/// Runs `search` over each of `stores` within `bounds`, and returns what each
+/// found, in the order of `stores`.
+fn search_each<F>(
+    stores: Vec<Option<PathBuf>>,
+    bounds: Bounds,
+    search: F,
+) -> Vec<std::result::Result<Vec<DisplayPad>, Unsearched>>
+where
+    F: Fn(Option<&Path>) -> Result<Vec<DisplayPad>> + Send + Sync + 'static,
+{
+    let search = Arc::new(search);
+    let (sender, receiver) = mpsc::channel();
+    let mut found: Vec<_> = stores.iter().map(|_| None).collect();
+    let mut pending = stores.into_iter().enumerate();
+    // The stores being searched, with when each started; oldest first.
+    let mut running: Vec<(usize, Instant)> = Vec::new();
+
+    loop {
+        while running.len() < bounds.workers.max(1) {
+            let Some((i, store)) = pending.next() else {
+                break;
+            };
+            let (sender, search) = (sender.clone(), search.clone());
+            thread::spawn(move || {
+                let _ = sender.send((i, (*search)(store.as_deref())));
+            });
+            running.push((i, Instant::now()));
+        }
+        let Some(&(_, oldest)) = running.first() else {
+            break;
+        };
+        let wait = (oldest + bounds.timeout).saturating_duration_since(Instant::now());
+        match receiver.recv_timeout(wait) {
+            Ok((i, result)) => {
+                // A store given up on may still answer; it is not heard.
+                if let Some(at) = running.iter().position(|(j, _)| *j == i) {
+                    running.remove(at);
+                    found[i] = Some(result.map_err(|e| Unsearched::Failed {
+                        error: e.to_string(),
+                    }));
+                }
+            }
+            // Past the oldest store's deadline (a thread that died without
+            // answering ends up here too).
+            Err(_) => {
+                let now = Instant::now();
+                running.retain(|&(i, started)| {
+                    let expired = now >= started + bounds.timeout;
+                    if expired {
+                        found[i] = Some(Err(Unsearched::TimedOut));
+                    }
+                    !expired
+                });
+            }
+        }
+    }
+    found
+        .into_iter()
+        .map(|result| result.unwrap_or(Err(Unsearched::TimedOut)))
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::create;
+    use std::fs;
+    use std::sync::atomic::{AtomicUsize, Ordering};
+    use tempfile::TempDir;
+
+    #[test]
+    fn test_run_searches_the_global_store_and_every_project() {
+        let temp = TempDir::new().unwrap();
+        let global = temp.path().join("global");
+        let padz_dir = temp.path().join("work").join(".padz");
+        let gone = temp.path().join("gone").join(".padz");
+        fs::create_dir_all(&padz_dir).unwrap();
+        fs::create_dir_all(&gone).unwrap();
+        registry::register(&global, &padz_dir).unwrap();
+        registry::register(&global, &gone).unwrap();
+        fs::remove_dir_all(&gone).unwrap();
+
+        let mut store = FileStore::new_fs(Some(padz_dir.clone()), global.clone());
+        for (scope, title) in [
+            (Scope::Global, "Meeting with legal"),
+            (Scope::Project, "Meeting notes"),
+            (Scope::Project, "Groceries"),
+        ] {
+            create::run(&mut store, scope, title.into(), "".into(), None).unwrap();
+        }
+
+        let filter = PadFilter {
+            search_term: Some("meeting".into()),
+            ..Default::default()
+        };
+        let outcome = run(&global, filter, Bounds::default()).unwrap();
+
+        let titles: Vec<Vec<&str>> = outcome
+            .searched
+            .iter()
+            .map(|scope| {
+                scope
+                    .pads
+                    .iter()
+                    .map(|dp| dp.pad.metadata.title.as_str())
+                    .collect()
+            })
+            .collect();
+        assert_eq!(
+            titles,
+            vec![vec!["Meeting with legal"], vec!["Meeting notes"]]
+        );
+        assert_eq!(outcome.searched[0].project, None);
+        let root = fs::canonicalize(temp.path()).unwrap();
+        assert_eq!(
+            outcome.unsearched,
+            vec![UnsearchedScope {
+                project: Some(root.join("gone")),
+                reason: Unsearched::Missing,
+            }]
+        );
+    }
+
+    #[test]
+    fn test_a_slow_store_times_out_without_holding_up_the_rest() {
+        let stores = ["fast", "slow", "broken", "also fast"]
+            .iter()
+            .map(|name| Some(PathBuf::from(name)))
+            .collect();
+        let bounds = Bounds {
+            workers: 2,
+            timeout: Duration::from_millis(200),
+        };
+        let busy = Arc::new(AtomicUsize::new(0));
+        let most_busy = Arc::new(AtomicUsize::new(0));
+        let (now_busy, peak) = (busy.clone(), most_busy.clone());
+
+        let started = Instant::now();
+        let found = search_each(stores, bounds, move |store| {
+            let store = store.unwrap().to_str().unwrap().to_string();
+            if store == "slow" {
+                // Given up on, it no longer counts against the bound.
+                thread::sleep(Duration::from_secs(5));
+                return Ok(Vec::new());
+            }
+            peak.fetch_max(
+                now_busy.fetch_add(1, Ordering::SeqCst) + 1,
+                Ordering::SeqCst,
+            );
+            thread::sleep(Duration::from_millis(20));
+            now_busy.fetch_sub(1, Ordering::SeqCst);
+            if store == "broken" {
+                return Err(PadzError::Api("unreadable".into()));
+            }
+            Ok(Vec::new())
+        });
+
+        assert!(started.elapsed() < Duration::from_secs(2));
+        assert!(found[0].is_ok());
+        assert!(matches!(found[1], Err(Unsearched::TimedOut)));
+        assert!(matches!(
+            &found[2],
+            Err(Unsearched::Failed { error }) if error == "Api Error: unreadable"
+        ));
+        assert!(found[3].is_ok());
+        assert!(most_busy.load(Ordering::SeqCst) <= 2);
+    }
+}
@@ -0,0 +1,173 @@
+//! Snippet lookup: the best fuzzy title match among `snippet`-tagged pads.
+//!
+//! `padz snip <query>` is a one-step "find and copy", so it is built to be
+//! fast rather than thorough. Candidates come from the store's metadata-only
+//! listing ([`DataStore::list_metadata`]) — no reconciliation, no content
+//! reads — and only the winning pad's content is loaded.
+//!
+//! Matching is by title. Every query character must appear in the title, in
+//! order and ignoring case and whitespace (`dkrun` matches "docker run");
+//! characters that continue a run or start a word score extra. Ties go to the
+//! shorter title, then to the most recently updated pad.
+
+use crate::error::{PadzError, Result};
+use crate::model::{Metadata, Pad, Scope};
+use crate::store::{Bucket, DataStore};
+use serde::Serialize;
+use std::cmp::Reverse;
+
+/// The tag that makes a pad a snippet.
+pub const SNIPPET_TAG: &str = "snippet";
+
+/// The snippet a query picked.
+#[derive(Debug, Clone, Serialize)]
+pub struct SnipOutcome {
+    pub pad: Pad,
+    /// How many snippets matched the query, the chosen one included.
+    pub matched: usize,
+}
+
+pub fn run<S: DataStore>(store: &S, scope: Scope, query: &str) -> Result<SnipOutcome> {
+    let mut matches: Vec<(u32, Metadata)> = store
+        .list_metadata(scope, Bucket::Active)?
+        .into_iter()
+        .filter(|meta| meta.tags.iter().any(|tag| tag == SNIPPET_TAG))
+        .filter_map(|meta| score(query, &meta.title).map(|score| (score, meta)))
+        .collect();
+    matches.sort_by_key(|(score, meta)| {
+        (
+            Reverse(*score),
+            meta.title.chars().count(),
+            Reverse(meta.updated_at),
+        )
+    });
+
+    let matched = matches.len();
+    let Some((_, best)) = matches.into_iter().next() else {
+        return Err(PadzError::Api(format!(
+            "No snippet matches '{}' (snippets are pads tagged '{}')",
+            query, SNIPPET_TAG
+        )));
+    };
+    let pad = store.get_pad(&best.id, scope, Bucket::Active)?;
+    Ok(SnipOutcome { pad, matched })
+}
+
+/// How well `query` matches `title`, or `None` when it does not.
+///
+/// The best over every way of placing the query's characters in the title,
+/// not just the first: "drun" should find the "run" in "docker run", not the
+/// "r" of "docker".
+fn score(query: &str, title: &str) -> Option<u32> {
+    let query: Vec<char> = query
+        .to_lowercase()
+        .chars()
+        .filter(|c| !c.is_whitespace())
+        .collect();
+    let title: Vec<char> = title.to_lowercase().chars().collect();
+    if query.is_empty() {
+        return Some(0);
+    }
+
+    let worth = |j: usize| {
+        1 + if j == 0 || !title[j - 1].is_alphanumeric() {
+            3
+        } else {
+            0
+        }
+    };
+    // best[j]: the best score for the query so far with its last character
+    // placed at title[j].
+    let mut best: Vec<Option<u32>> = title
+        .iter()
+        .enumerate()
+        .map(|(j, c)| (*c == query[0]).then(|| worth(j)))
+        .collect();
+    for q in &query[1..] {
+        let mut next = vec![None; title.len()];
+        let mut before: Option<u32> = None; // best[k] for k < j - 1
+        for j in 1..title.len() {
+            if title[j] == *q {
+                let apart = before.map(|s| s + worth(j));
+                let adjacent = best[j - 1].map(|s| s + worth(j) + 4);
+                next[j] = apart.max(adjacent);
+            }
+            before = before.max(best[j - 1]);
+        }
+        best = next;
+    }
+    best.into_iter().flatten().max()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, tagging, tags};
+    use crate::index::{DisplayIndex, PadSelector};
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn store_with_snippets(titles: &[&str], snippets: &[usize]) -> BucketedStore<MemBackend> {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        for title in titles {
+            create::run(
+                &mut store,
+                Scope::Project,
+                title.to_string(),
+                format!("body of {title}"),
+                None,
+            )
+            .unwrap();
+        }
+        tags::create_tag(&mut store, Scope::Project, SNIPPET_TAG).unwrap();
+        let selected: Vec<_> = snippets
+            .iter()
+            .map(|i| PadSelector::Path(vec![DisplayIndex::Regular(*i)]))
+            .collect();
+        tagging::add_tags(
+            &mut store,
+            Scope::Project,
+            &selected,
+            &[SNIPPET_TAG.to_string()],
+        )
+        .unwrap();
+        store
+    }
+
+    #[test]
+    fn test_scores_in_order_subsequences_only() {
+        assert!(score("dkrun", "Docker run").is_some());
+        assert!(score("DOCKER", "docker run").is_some());
+        assert_eq!(score("rund", "Docker run"), None);
+        // The best placement counts, not the first one found.
+        assert_eq!(score("drun", "docker run"), score("drun", "d run"));
+        // A contiguous word-start match beats a scattered one.
+        assert!(score("run", "run tests") > score("run", "reindex unused"));
+    }
+
+    #[test]
+    fn test_picks_the_best_snippet_and_loads_its_content() {
+        // Newest first: 1 = "git undo", 2 = "docker run", 3 = "deploy runbook".
+        let store = store_with_snippets(&["deploy runbook", "docker run", "git undo"], &[2, 3]);
+
+        // Both match; "docker run" matches more of it in runs.
+        let outcome = run(&store, Scope::Project, "do run").unwrap();
+        assert_eq!(outcome.pad.metadata.title, "docker run");
+        assert_eq!(outcome.matched, 2);
+        assert!(outcome.pad.content.contains("body of docker run"));
+    }
+
+    #[test]
+    fn test_untagged_pads_are_never_snippets() {
+        let store = store_with_snippets(&["deploy runbook", "git undo"], &[1]);
+
+        assert!(run(&store, Scope::Project, "undo").is_ok());
+        let err = run(&store, Scope::Project, "deploy").unwrap_err();
+        assert!(err.to_string().contains("No snippet matches"));
+    }
+}
@@ -0,0 +1,191 @@
+//! Store statistics: how many pads each lifecycle bucket holds, and how much
+//! the trash (the deleted bucket) has piled up.
+
+use crate::config::TrashConfig;
+use crate::error::Result;
+use crate::index::{DisplayIndex, DisplayPad};
+use crate::model::Scope;
+use crate::store::{Bucket, DataStore};
+use serde::Serialize;
+
+/// Pad counts for one scope. Nested pads count like any other.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize)]
+pub struct PadCounts {
+    pub active: usize,
+    /// Active pads that are pinned; a subset of `active`.
+    pub pinned: usize,
+    pub archived: usize,
+    pub deleted: usize,
+}
+
+pub fn run<S: DataStore>(store: &S, scope: Scope) -> Result<PadCounts> {
+    let active = store.list_pads(scope, Bucket::Active)?;
+    Ok(PadCounts {
+        pinned: active.iter().filter(|p| p.metadata.is_pinned).count(),
+        active: active.len(),
+        archived: store.list_pads(scope, Bucket::Archived)?.len(),
+        deleted: store.list_pads(scope, Bucket::Deleted)?.len(),
+    })
+}
+
+/// The same counts, read from each bucket's index alone (see
+/// [`DataStore::list_metadata`]): no content is read and nothing is
+/// reconciled, so pad files added or removed outside padz are not seen. For
+/// callers that run often and must be quick, like a shell prompt.
+pub fn from_index<S: DataStore>(store: &S, scope: Scope) -> Result<PadCounts> {
+    let active = store.list_metadata(scope, Bucket::Active)?;
+    Ok(PadCounts {
+        pinned: active.iter().filter(|m| m.is_pinned).count(),
+        active: active.len(),
+        archived: store.list_metadata(scope, Bucket::Archived)?.len(),
+        deleted: store.list_metadata(scope, Bucket::Deleted)?.len(),
+    })
+}
+
+/// What the trash holds: deleted pads, nested ones included, and the bytes
+/// of their bodies.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize)]
+pub struct TrashSize {
+    pub pads: usize,
+    pub bytes: u64,
+}
+
+impl TrashSize {
+    /// Adds up the deleted pads of an indexed tree (see
+    /// [`crate::commands::helpers::indexed_pads`]). A listing has read them
+    /// already, so this costs nothing more.
+    pub fn of(indexed: &[DisplayPad]) -> Self {
+        let mut size = Self::default();
+        for dp in indexed {
+            match dp.index {
+                // A pinned pad is indexed again as a regular one; count its
+                // subtree there.
+                DisplayIndex::Pinned(_) => continue,
+                DisplayIndex::Deleted(_) => {
+                    size.pads += 1;
+                    size.bytes += dp.pad.content.len() as u64;
+                }
+                _ => {}
+            }
+            let children = Self::of(&dp.children);
+            size.pads += children.pads;
+            size.bytes += children.bytes;
+        }
+        size
+    }
+
+    /// Whether the trash is past either limit in `limits`, so it is worth
+    /// suggesting `padz purge`.
+    pub fn exceeds(&self, limits: &TrashConfig) -> bool {
+        (limits.warn_pads > 0 && self.pads > limits.warn_pads)
+            || (limits.warn_bytes > 0 && self.bytes > limits.warn_bytes)
+    }
+}
+
+/// The trash of `scope`, read from the deleted bucket alone.
+pub fn trash<S: DataStore>(store: &S, scope: Scope) -> Result<TrashSize> {
+    let deleted = store.list_pads(scope, Bucket::Deleted)?;
+    Ok(TrashSize {
+        pads: deleted.len(),
+        bytes: deleted.iter().map(|p| p.content.len() as u64).sum(),
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, delete, pinning};
+    use crate::index::{DisplayIndex, PadSelector};
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    #[test]
+    fn counts_every_bucket() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        for title in ["A", "B", "C"] {
+            create::run(&mut store, Scope::Project, title.into(), "".into(), None).unwrap();
+        }
+        let first = PadSelector::Path(vec![DisplayIndex::Regular(1)]);
+        delete::run(&mut store, Scope::Project, std::slice::from_ref(&first)).unwrap();
+        pinning::pin(&mut store, Scope::Project, std::slice::from_ref(&first)).unwrap();
+
+        let counts = run(&store, Scope::Project).unwrap();
+
+        assert_eq!(
+            counts,
+            PadCounts {
+                active: 2,
+                pinned: 1,
+                archived: 0,
+                deleted: 1,
+            }
+        );
+    }
+
+    #[test]
+    fn index_counts_match_a_full_listing() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        for title in ["A", "B"] {
+            create::run(&mut store, Scope::Project, title.into(), "".into(), None).unwrap();
+        }
+        let first = PadSelector::Path(vec![DisplayIndex::Regular(1)]);
+        pinning::pin(&mut store, Scope::Project, std::slice::from_ref(&first)).unwrap();
+
+        assert_eq!(
+            from_index(&store, Scope::Project).unwrap(),
+            run(&store, Scope::Project).unwrap()
+        );
+    }
+
+    #[test]
+    fn trash_size_counts_deleted_pads_once_and_checks_the_limits() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        let top = |n| PadSelector::Path(vec![DisplayIndex::Regular(n)]);
+        create::run(&mut store, Scope::Project, "Loose".into(), "".into(), None).unwrap();
+        create::run(&mut store, Scope::Project, "Parent".into(), "".into(), None).unwrap();
+        for title in ["Kept", "Gone"] {
+            create::run(
+                &mut store,
+                Scope::Project,
+                title.into(),
+                "".into(),
+                Some(top(1)),
+            )
+            .unwrap();
+        }
+        pinning::pin(&mut store, Scope::Project, &[top(1)]).unwrap();
+        // The deleted child shows under both of its pinned parent's indexes.
+        let child = PadSelector::Path(vec![DisplayIndex::Regular(1), DisplayIndex::Regular(1)]);
+        delete::run(&mut store, Scope::Project, &[child]).unwrap();
+        delete::run(&mut store, Scope::Project, &[top(2)]).unwrap();
+
+        let indexed = crate::commands::helpers::indexed_pads(&store, Scope::Project).unwrap();
+        let size = TrashSize::of(&indexed);
+        assert_eq!(size, trash(&store, Scope::Project).unwrap());
+        assert_eq!(size.pads, 2);
+
+        let limits = |warn_pads, warn_bytes| TrashConfig {
+            warn_pads,
+            warn_bytes,
+        };
+        assert!(!size.exceeds(&limits(2, size.bytes)));
+        assert!(size.exceeds(&limits(1, 0)));
+        assert!(size.exceeds(&limits(0, size.bytes - 1)));
+        assert!(!size.exceeds(&limits(0, 0)));
+    }
+}
@@ -43,6 +43,9 @@ pub enum TagRegistryOutcome {
         old_name: String,
         new_name: String,
         affected_pads: usize,
+        /// Titles of the pads retagged, so a dry run previews them.
+        #[serde(skip_serializing_if = "Vec::is_empty")]
+        pads: Vec<String>,
     },
 }
 
@@ -136,7 +139,8 @@ pub fn delete_tag<S: DataStore>(
     })
 }
 
-/// Rename a registry tag and update every active pad that carries it.
+/// Rename a registry tag and update every pad that carries it, archived and
+/// deleted ones included, so restoring a pad never brings the old name back.
 pub fn rename_tag<S: DataStore>(
     store: &mut S,
     scope: Scope,
@@ -158,19 +162,22 @@ pub fn rename_tag<S: DataStore>(
     tags[tag_idx].name = new_name.to_string();
     store.save_tags(scope, &tags)?;
 
-    let mut affected_pads = 0;
-    for mut pad in store.list_pads(scope, Bucket::Active)? {
-        if let Some(pos) = pad.metadata.tags.iter().position(|tag| tag == old_name) {
-            pad.metadata.tags[pos] = new_name.to_string();
-            store.save_pad(&pad, scope, Bucket::Active)?;
-            affected_pads += 1;
+    let mut pads = Vec::new();
+    for bucket in [Bucket::Active, Bucket::Archived, Bucket::Deleted] {
+        for mut pad in store.list_pads(scope, bucket)? {
+            if let Some(pos) = pad.metadata.tags.iter().position(|tag| tag == old_name) {
+                pad.metadata.tags[pos] = new_name.to_string();
+                store.save_pad(&pad, scope, bucket)?;
+                pads.push(pad.metadata.title);
+            }
         }
     }
 
     Ok(TagRegistryOutcome::Renamed {
         old_name: old_name.to_string(),
         new_name: new_name.to_string(),
-        affected_pads,
+        affected_pads: pads.len(),
+        pads,
     })
 }
 
@@ -292,6 +299,7 @@ mod tests {
                 old_name: "old".into(),
                 new_name: "new".into(),
                 affected_pads: 1,
+                pads: vec!["Pad".into()],
             }
         );
         assert_eq!(
@@ -302,6 +310,30 @@ mod tests {
         );
     }
 
+    #[test]
+    fn rename_reaches_archived_pads_too() {
+        let mut store = store();
+        create_tag(&mut store, Scope::Project, "old").unwrap();
+        create::run(&mut store, Scope::Project, "Kept".into(), "".into(), None).unwrap();
+        let first = [PadSelector::Path(vec![DisplayIndex::Regular(1)])];
+        tagging::add_tags(&mut store, Scope::Project, &first, &["old".into()]).unwrap();
+        crate::commands::archive::run(&mut store, Scope::Project, &first).unwrap();
+
+        let TagRegistryOutcome::Renamed { pads, .. } =
+            rename_tag(&mut store, Scope::Project, "old", "new").unwrap()
+        else {
+            panic!("expected a rename");
+        };
+
+        assert_eq!(pads, vec!["Kept"]);
+        assert_eq!(
+            store.list_pads(Scope::Project, Bucket::Archived).unwrap()[0]
+                .metadata
+                .tags,
+            vec!["new"]
+        );
+    }
+
     #[test]
     fn rename_preserves_validation_duplicate_and_not_found_errors() {
         let mut store = store();
@@ -0,0 +1,130 @@
+//! Per-pad activity timelines.
+//!
+//! A timeline is the pad's history (see [`crate::store::history`]) behind one
+//! synthesized `Created` event, oldest first. Pads written before history was
+//! kept show only their creation until something new happens to them.
+
+use crate::commands::helpers::{indexed_pads, resolve_selectors, TitleBucket};
+use crate::error::Result;
+use crate::index::{DisplayPad, PadSelector};
+use crate::model::{PadEvent, PadEventKind, Scope};
+use crate::store::DataStore;
+use serde::Serialize;
+
+/// One pad and what happened to it.
+#[derive(Debug, Clone, Serialize)]
+pub struct Timeline {
+    pub pad: DisplayPad,
+    pub events: Vec<PadEvent>,
+}
+
+/// The timelines of the selected pads, in selection order.
+#[derive(Debug, Clone, Serialize)]
+pub struct TimelineOutcome {
+    pub timelines: Vec<Timeline>,
+}
+
+pub fn run<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    selectors: &[PadSelector],
+) -> Result<TimelineOutcome> {
+    let resolved = resolve_selectors(store, scope, selectors, false, TitleBucket::Any)?;
+    let indexed = indexed_pads(store, scope)?;
+
+    let mut timelines = Vec::with_capacity(resolved.len());
+    for (_, uuid) in resolved {
+        let Some(dp) = super::helpers::find_pad_by_uuid(&indexed, uuid, |_| true) else {
+            continue;
+        };
+        let metadata = &dp.pad.metadata;
+        let mut events = vec![PadEvent {
+            at: metadata.created_at,
+            kind: PadEventKind::Created,
+        }];
+        events.extend(metadata.history.iter().cloned());
+
+        timelines.push(Timeline {
+            pad: DisplayPad {
+                pad: dp.pad.clone(),
+                index: dp.index.clone(),
+                matches: None,
+                children: Vec::new(),
+            },
+            events,
+        });
+    }
+    Ok(TimelineOutcome { timelines })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, delete, pinning, restore, update};
+    use crate::index::DisplayIndex;
+    use crate::model::TodoStatus;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn first() -> Vec<PadSelector> {
+        vec![PadSelector::Path(vec![DisplayIndex::Regular(1)])]
+    }
+
+    fn kinds(outcome: &TimelineOutcome) -> Vec<PadEventKind> {
+        outcome.timelines[0]
+            .events
+            .iter()
+            .map(|e| e.kind.clone())
+            .collect()
+    }
+
+    #[test]
+    fn test_timeline_follows_a_pad_through_its_life() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        create::run(&mut store, Scope::Project, "Plan".into(), "v1".into(), None).unwrap();
+        update::run_from_content(&mut store, Scope::Project, &first(), "Plan\n\nv2").unwrap();
+        pinning::pin(&mut store, Scope::Project, &first()).unwrap();
+        let pinned = vec![PadSelector::Path(vec![DisplayIndex::Pinned(1)])];
+        pinning::unpin(&mut store, Scope::Project, &pinned).unwrap();
+        delete::run(&mut store, Scope::Project, &first()).unwrap();
+        let deleted = vec![PadSelector::Path(vec![DisplayIndex::Deleted(1)])];
+        restore::run(&mut store, Scope::Project, &deleted).unwrap();
+
+        let outcome = run(&store, Scope::Project, &first()).unwrap();
+        assert_eq!(
+            kinds(&outcome),
+            vec![
+                PadEventKind::Created,
+                PadEventKind::Edited,
+                PadEventKind::Pinned,
+                PadEventKind::Unpinned,
+                PadEventKind::Deleted,
+                PadEventKind::Restored,
+            ]
+        );
+        let events = &outcome.timelines[0].events;
+        assert!(events.windows(2).all(|w| w[0].at <= w[1].at));
+    }
+
+    #[test]
+    fn test_timeline_of_an_untouched_pad_is_its_creation() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        create::run(&mut store, Scope::Project, "Quiet".into(), "".into(), None).unwrap();
+        let outcome = run(&store, Scope::Project, &first()).unwrap();
+        assert_eq!(kinds(&outcome), vec![PadEventKind::Created]);
+        assert_eq!(
+            outcome.timelines[0].pad.pad.metadata.status,
+            TodoStatus::Planned
+        );
+    }
+}
@@ -28,11 +28,13 @@
 //! This means `padz clone --to /tmp/work` works whether the user points at
 //! `/tmp/work`, `/tmp/work/.padz`, or a subdirectory of the project.
 
+use crate::cancel::Cancellation;
 use crate::config::PadzConfig;
 use crate::error::{PadzError, Result};
 use crate::index::{DisplayIndex, PadSelector};
 use crate::init::{find_padz_root, resolve_link};
 use crate::model::{Pad, Scope};
+use crate::progress::{track, Progress};
 use crate::store::fs::FileStore;
 use crate::store::{Bucket, DataStore};
 use crate::tags::TagEntry;
@@ -42,7 +44,8 @@ use std::collections::{HashMap, HashSet};
 use std::path::{Path, PathBuf};
 use uuid::Uuid;
 
-use super::helpers::{indexed_pads, resolve_selectors, TitleBucket};
+use super::helpers::{indexed_pads, linearize_tree, resolve_selectors, TitleBucket};
+use super::{CmdOutcome, CmdResult};
 
 /// Whether the source keeps or loses the pads after a transfer.
 #[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
@@ -235,6 +238,9 @@ pub fn open_target_store(padz_dir: &Path) -> Result<FileStore> {
 /// - `dest_scope`: Project or Global on the dest side
 /// - `selectors`: pad selectors, resolved against the source
 /// - `request`: operation, direction, resolved peer, and requested selection
+///
+/// Cancelling `cancel` stops the transfer with [`PadzError::Interrupted`];
+/// run it inside a transaction on both stores so neither keeps its half.
 pub fn run<Src: DataStore, Dst: DataStore>(
     source: &mut Src,
     source_scope: Scope,
@@ -242,6 +248,8 @@ pub fn run<Src: DataStore, Dst: DataStore>(
     dest_scope: Scope,
     selectors: &[PadSelector],
     request: TransferRequest,
+    progress: &dyn Progress,
+    cancel: &Cancellation,
 ) -> Result<TransferReport> {
     let TransferRequest {
         operation,
@@ -286,7 +294,12 @@ pub fn run<Src: DataStore, Dst: DataStore>(
     let mut copied: Vec<Uuid> = Vec::new();
     let mut referenced_tags: HashSet<String> = HashSet::new();
 
-    for (_, id) in &resolved {
+    let task = match operation {
+        TransferMode::Clone => "Cloning",
+        TransferMode::Migrate => "Migrating",
+    };
+    for (_, id) in track(progress, task, resolved.iter()) {
+        cancel.check()?;
         match copy_one_pad(source, source_scope, dest, dest_scope, *id, &known_ids) {
             Ok(CopyOutcome {
                 orphaned_parent,
@@ -326,6 +339,7 @@ pub fn run<Src: DataStore, Dst: DataStore>(
     // 5. For migrate: delete copies from source.
     if operation == TransferMode::Migrate {
         for id in &copied {
+            cancel.check()?;
             if let Err(e) = delete_from_source(source, source_scope, *id) {
                 diagnostics.push(TransferDiagnostic::SourceDeleteFailed {
                     pad_id: *id,
@@ -355,6 +369,61 @@ pub fn run<Src: DataStore, Dst: DataStore>(
     })
 }
 
+/// Reads a migrate as a relocation (`padz move --to`), which moves every pad
+/// or none: any diagnostic but an orphaned parent is an error, so the caller's
+/// transactions leave both stores as they were. Otherwise each moved pad is
+/// reported with its path in `dest` — pinned pads by their place in the list,
+/// not the pinned section — and `project` names that store, `None` for the
+/// global one.
+pub fn relocated<S: DataStore>(
+    report: &TransferReport,
+    dest: &S,
+    dest_scope: Scope,
+    project: Option<PathBuf>,
+) -> Result<CmdResult> {
+    let failures: Vec<String> = report
+        .diagnostics
+        .iter()
+        .filter_map(|diagnostic| match diagnostic {
+            TransferDiagnostic::ParentOrphaned { .. } => None,
+            TransferDiagnostic::CopyFailed { pad_id, detail, .. }
+            | TransferDiagnostic::SourceDeleteFailed { pad_id, detail } => {
+                Some(format!("pad {}: {}", pad_id, detail))
+            }
+            TransferDiagnostic::DestinationBucketEnumerationFailed { bucket, detail } => {
+                Some(format!("{:?} bucket: {}", bucket, detail))
+            }
+            TransferDiagnostic::TagRegistryMergeFailed { detail } => {
+                Some(format!("tags: {}", detail))
+            }
+        })
+        .collect();
+    if !failures.is_empty() {
+        return Err(PadzError::Api(format!(
+            "Nothing was moved: {}",
+            failures.join("; ")
+        )));
+    }
+
+    let indexed = indexed_pads(dest, dest_scope)?;
+    let located = linearize_tree(&indexed);
+    let mut result = CmdResult::default();
+    for id in &report.copied_pad_ids {
+        let found = located.iter().find(|(path, dp)| {
+            dp.pad.metadata.id == *id && !matches!(path[0], DisplayIndex::Pinned(_))
+        });
+        if let Some((path, dp)) = found {
+            result.outcomes.push(CmdOutcome::Relocated {
+                path: path.clone(),
+                title: dp.pad.metadata.title.clone(),
+                project: project.clone(),
+            });
+            result.affected_pads.push((*dp).clone());
+        }
+    }
+    Ok(result)
+}
+
 fn collect_all_ids<S: DataStore>(
     store: &S,
     scope: Scope,
@@ -444,7 +513,8 @@ fn read_source_pad_any_bucket<S: DataStore>(
 /// valid [`crate::model::Metadata`], so we just forward it. The only policy here is
 /// parent-orphan: if the pad's `parent_id` points outside the known set
 /// (the pads being moved + those already at the destination), we drop the
-/// link so the destination never has a dangling reference.
+/// link so the destination never has a dangling reference. Likewise an alias
+/// the destination already uses is dropped.
 ///
 /// Writing to the destination is the critical path; failure surfaces as
 /// `Err` and the caller reports the pad as failed.
@@ -468,6 +538,21 @@ fn copy_one_pad<Src: DataStore, Dst: DataStore>(
         })?;
     let tags = pad.metadata.tags.clone();
 
+    // Aliases are unique per scope; one the destination already uses for a
+    // different pad stays behind rather than failing the copy.
+    if let Some(alias) = pad.metadata.alias.clone() {
+        let owner =
+            crate::commands::naming::alias_owner(&*dest, dest_scope, &alias).map_err(|error| {
+                CopyFailure {
+                    category: CopyFailureCategory::DestinationWrite,
+                    detail: error.to_string(),
+                }
+            })?;
+        if owner.is_some_and(|owner| owner != id) {
+            pad.metadata.alias = None;
+        }
+    }
+
     let mut orphaned_parent = None;
     if let Some(pid) = pad.metadata.parent_id {
         if !known_ids.contains(&pid) {
@@ -542,6 +627,7 @@ mod tests {
     use super::*;
     use crate::commands::create;
     use crate::index::{DisplayIndex, PadSelector};
+    use crate::progress::NoProgress;
     use crate::store::bucketed::BucketedStore;
     use crate::store::mem_backend::MemBackend;
 
@@ -583,6 +669,15 @@ mod tests {
             self.inner.save_pad(pad, scope, bucket)
         }
 
+        fn save_metadata(
+            &mut self,
+            metadata: &crate::model::Metadata,
+            scope: Scope,
+            bucket: Bucket,
+        ) -> Result<()> {
+            self.inner.save_metadata(metadata, scope, bucket)
+        }
+
         fn get_pad(&self, id: &Uuid, scope: Scope, bucket: Bucket) -> Result<Pad> {
             if self.fail_get {
                 Err(Self::fault("source read"))
@@ -629,6 +724,29 @@ mod tests {
             self.inner.doctor(scope)
         }
 
+        fn check_integrity(
+            &self,
+            scope: Scope,
+        ) -> Result<crate::store::integrity::IntegrityReport> {
+            self.inner.check_integrity(scope)
+        }
+
+        fn accept_checksums(&mut self, scope: Scope) -> Result<usize> {
+            self.inner.accept_checksums(scope)
+        }
+
+        fn begin(&mut self) {
+            self.inner.begin()
+        }
+
+        fn commit(&mut self) -> Result<()> {
+            self.inner.commit()
+        }
+
+        fn rollback(&mut self) {
+            self.inner.rollback()
+        }
+
         fn load_tags(&self, scope: Scope) -> Result<Vec<TagEntry>> {
             self.inner.load_tags(scope)
         }
@@ -670,6 +788,8 @@ mod tests {
                 peer_store: peer_store.to_path_buf(),
                 requested_selection,
             },
+            &NoProgress,
+            &Cancellation::new(),
         )
     }
 
@@ -751,6 +871,53 @@ mod tests {
         );
     }
 
+    #[test]
+    fn test_copy_drops_an_alias_the_destination_already_uses() {
+        let mut src = store();
+        create::run(&mut src, Scope::Project, "Incoming".into(), "".into(), None).unwrap();
+        create::run(&mut src, Scope::Project, "Other".into(), "".into(), None).unwrap();
+        let mut ids = Vec::new();
+        for mut pad in src.list_pads(Scope::Project, Bucket::Active).unwrap() {
+            let alias = if pad.metadata.title == "Incoming" {
+                "runbook"
+            } else {
+                "other"
+            };
+            pad.metadata.alias = Some(alias.into());
+            src.save_pad(&pad, Scope::Project, Bucket::Active).unwrap();
+            ids.push(pad.metadata.id);
+        }
+
+        let mut dst = store();
+        create::run(&mut dst, Scope::Project, "Resident".into(), "".into(), None).unwrap();
+        crate::commands::naming::run(
+            &mut dst,
+            Scope::Project,
+            &[PadSelector::Path(vec![DisplayIndex::Regular(1)])],
+            Some("runbook"),
+        )
+        .unwrap();
+
+        let known: HashSet<Uuid> = ids.iter().copied().collect();
+        for id in &ids {
+            test_copy_one_pad(&src, Scope::Project, &mut dst, *id, &known).unwrap();
+        }
+
+        let dst_pads = dst.list_pads(Scope::Project, Bucket::Active).unwrap();
+        let alias_of = |title: &str| {
+            dst_pads
+                .iter()
+                .find(|p| p.metadata.title == title)
+                .unwrap()
+                .metadata
+                .alias
+                .clone()
+        };
+        assert_eq!(alias_of("Resident").as_deref(), Some("runbook"));
+        assert_eq!(alias_of("Incoming"), None, "a taken alias stays behind");
+        assert_eq!(alias_of("Other").as_deref(), Some("other"));
+    }
+
     #[test]
     fn test_copy_preserves_parent_inside_move_set() {
         let mut src = store();
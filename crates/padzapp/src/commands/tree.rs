@@ -0,0 +1,192 @@
+//! A map of every store (`padz tree`).
+//!
+//! The global store, then each project store in the scope registry
+//! ([`crate::store::registry`]), each with its pinned pads and its most
+//! recently updated ones underneath, under the indexes they have in that
+//! store. A registered project whose store is gone is listed as such, with
+//! nothing under it; remote stores are left out, as reading one means a fetch.
+//!
+//! Only the bucket indexes are read (see [`super::stats::from_index`]), so a
+//! map of many projects costs no pad bodies.
+
+use crate::error::{PadzError, Result};
+use crate::index::{current_ordering_key, index_pads, DisplayIndex, DisplayPad};
+use crate::model::{Pad, Scope};
+use crate::store::fs::FileStore;
+use crate::store::registry;
+use crate::store::{Bucket, DataStore};
+use chrono::{DateTime, Utc};
+use serde::Serialize;
+use std::cmp::Reverse;
+use std::path::{Path, PathBuf};
+
+/// Recently updated pads shown per store, besides the pinned ones.
+pub const RECENT_PER_STORE: usize = 3;
+
+/// One pad under its store.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct TreePad {
+    pub index: DisplayIndex,
+    pub title: String,
+    pub updated_at: DateTime<Utc>,
+}
+
+/// One store and what is under it.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct StoreBranch {
+    /// The project directory; `None` for the global store.
+    pub project: Option<PathBuf>,
+    /// The project's store no longer exists.
+    pub missing: bool,
+    /// Active pads in the store.
+    pub active: usize,
+    pub pinned: Vec<TreePad>,
+    /// The most recently updated pads that are not pinned, newest first.
+    pub recent: Vec<TreePad>,
+}
+
+/// The global store and the registered projects, oldest registration first.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct TreeOutcome {
+    pub global: StoreBranch,
+    pub projects: Vec<StoreBranch>,
+}
+
+pub fn run(global_dir: &Path) -> Result<TreeOutcome> {
+    let global_store = FileStore::new_fs(None, global_dir.to_path_buf());
+    let global = branch(&global_store, Scope::Global, None)?;
+
+    let mut projects = Vec::new();
+    for entry in registry::load(global_dir).map_err(PadzError::Io)? {
+        if entry.remote.is_some() {
+            continue;
+        }
+        let project = entry.project_root();
+        if !entry.path.is_dir() {
+            projects.push(StoreBranch {
+                project: Some(project),
+                missing: true,
+                active: 0,
+                pinned: Vec::new(),
+                recent: Vec::new(),
+            });
+            continue;
+        }
+        let store = FileStore::new_fs(Some(entry.path.clone()), global_dir.to_path_buf());
+        projects.push(branch(&store, Scope::Project, Some(project))?);
+    }
+    Ok(TreeOutcome { global, projects })
+}
+
+/// The branch of one store's `scope`.
+pub fn branch<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    project: Option<PathBuf>,
+) -> Result<StoreBranch> {
+    let active: Vec<Pad> = store
+        .list_metadata(scope, Bucket::Active)?
+        .into_iter()
+        .map(|metadata| Pad {
+            metadata,
+            content: String::new(),
+        })
+        .collect();
+    let count = active.len();
+    let roots = index_pads(active, Vec::new(), Vec::new(), current_ordering_key());
+
+    let tree_pad = |dp: &DisplayPad| TreePad {
+        index: dp.index.clone(),
+        title: dp.pad.metadata.title.clone(),
+        updated_at: dp.pad.metadata.updated_at,
+    };
+    let pinned = roots
+        .iter()
+        .filter(|dp| matches!(dp.index, DisplayIndex::Pinned(_)))
+        .map(tree_pad)
+        .collect();
+    let mut unpinned: Vec<_> = roots
+        .iter()
+        .filter(|dp| matches!(dp.index, DisplayIndex::Regular(_)) && !dp.pad.metadata.is_pinned)
+        .collect();
+    unpinned.sort_by_key(|dp| Reverse(dp.pad.metadata.updated_at));
+    let recent = unpinned
+        .into_iter()
+        .take(RECENT_PER_STORE)
+        .map(tree_pad)
+        .collect();
+
+    Ok(StoreBranch {
+        project,
+        missing: false,
+        active: count,
+        pinned,
+        recent,
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, pinning};
+    use crate::index::PadSelector;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+    use std::fs;
+    use tempfile::TempDir;
+
+    fn store() -> BucketedStore<MemBackend> {
+        BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        )
+    }
+
+    #[test]
+    fn test_branch_lists_pinned_then_recent_unpinned_pads() {
+        let mut store = store();
+        for title in ["One", "Two", "Three", "Four", "Five"] {
+            create::run(&mut store, Scope::Project, title.into(), "".into(), None).unwrap();
+        }
+        // "Five" is the newest, at regular index 1.
+        pinning::pin(
+            &mut store,
+            Scope::Project,
+            &[PadSelector::Path(vec![DisplayIndex::Regular(1)])],
+        )
+        .unwrap();
+
+        let branch = branch(&store, Scope::Project, Some(PathBuf::from("/work"))).unwrap();
+
+        assert_eq!(branch.active, 5);
+        assert_eq!(branch.pinned.len(), 1);
+        assert_eq!(branch.pinned[0].index, DisplayIndex::Pinned(1));
+        assert_eq!(branch.pinned[0].title, "Five");
+        assert_eq!(branch.recent.len(), RECENT_PER_STORE);
+        assert!(branch.recent.iter().all(|pad| pad.title != "Five"));
+    }
+
+    #[test]
+    fn test_run_marks_registered_projects_that_are_gone() {
+        let temp = TempDir::new().unwrap();
+        let global = temp.path().join("global");
+        let kept = temp.path().join("kept").join(".padz");
+        let gone = temp.path().join("gone").join(".padz");
+        fs::create_dir_all(&kept).unwrap();
+        fs::create_dir_all(&gone).unwrap();
+        registry::register(&global, &kept).unwrap();
+        registry::register(&global, &gone).unwrap();
+        fs::remove_dir_all(&gone).unwrap();
+
+        let outcome = run(&global).unwrap();
+
+        assert_eq!(outcome.global.project, None);
+        assert_eq!(outcome.projects.len(), 2);
+        assert!(!outcome.projects[0].missing);
+        let root = fs::canonicalize(temp.path()).unwrap();
+        assert_eq!(outcome.projects[1].project, Some(root.join("gone")));
+        assert!(outcome.projects[1].missing);
+    }
+}
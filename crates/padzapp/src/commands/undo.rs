@@ -0,0 +1,279 @@
+//! `padz undo`: take back the last change a command committed.
+//!
+//! The store keeps a record of every commit's changes, pad by pad (see
+//! [`crate::store::undo`]); undoing puts each pad back as the record says it
+//! was. A pad the change created is removed again, one it purged comes back
+//! with its body, one it moved between buckets goes back where it was, and
+//! one it rewrote — pinned, tagged, reparented, edited — gets its old entry
+//! back. A rewritten tag registry is restored whole.
+//!
+//! Undo is all or nothing. If any pad in the record has changed since, the
+//! undo is refused rather than half applied: later work is never overwritten.
+//! Run inside a transaction, the undo is itself a commit, so its record is the
+//! change it made, and undoing again redoes.
+
+use crate::error::{PadzError, Result};
+use crate::model::{Metadata, Pad, Scope};
+use crate::store::undo::{unchanged, PadChange, UndoRecord};
+use crate::store::{Bucket, DataStore};
+use chrono::{DateTime, Utc};
+use serde::Serialize;
+use std::collections::HashMap;
+use uuid::Uuid;
+
+/// What undoing did to one pad.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(tag = "kind", rename_all = "snake_case")]
+pub enum Undone {
+    /// The change created the pad; it is gone again.
+    Removed,
+    /// The change purged the pad; it is back in `bucket`.
+    Recreated { bucket: Bucket },
+    /// The change moved the pad from `to` to `from`; it is back in `to`.
+    Returned { from: Bucket, to: Bucket },
+    /// The change rewrote the pad in place; its old entry is back.
+    Reverted,
+}
+
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct UndonePad {
+    pub title: String,
+    #[serde(flatten)]
+    pub undone: Undone,
+}
+
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct UndoOutcome {
+    /// When the undone change was committed.
+    pub at: DateTime<Utc>,
+    /// By title.
+    pub pads: Vec<UndonePad>,
+    /// Whether the tag registry was restored too.
+    pub tags: bool,
+}
+
+pub fn run<S: DataStore>(store: &mut S, scope: Scope) -> Result<UndoOutcome> {
+    let Some(record) = store.last_change(scope)? else {
+        return Err(PadzError::Api("Nothing to undo".to_string()));
+    };
+    check(store, scope, &record)?;
+
+    let mut pads = Vec::with_capacity(record.pads.len());
+    for change in &record.pads {
+        pads.push(put_back(store, scope, change)?);
+    }
+    pads.sort_by(|a, b| a.title.cmp(&b.title));
+    if let Some(tags) = &record.tags {
+        store.save_tags(scope, &tags.before)?;
+    }
+    Ok(UndoOutcome {
+        at: record.at,
+        pads,
+        tags: record.tags.is_some(),
+    })
+}
+
+/// Fails unless every pad, and the tag registry, is still as the change left
+/// it.
+fn check<S: DataStore>(store: &S, scope: Scope, record: &UndoRecord) -> Result<()> {
+    let mut current: HashMap<Uuid, (Bucket, Metadata)> = HashMap::new();
+    for bucket in [Bucket::Active, Bucket::Archived, Bucket::Deleted] {
+        for metadata in store.list_metadata(scope, bucket)? {
+            current.insert(metadata.id, (bucket, metadata));
+        }
+    }
+    for change in &record.pads {
+        let as_left = match (&change.after, current.get(&change.id)) {
+            (None, None) => true,
+            (Some(after), Some((bucket, metadata))) => {
+                after.bucket == *bucket && unchanged(&after.metadata, metadata)
+            }
+            _ => false,
+        };
+        if !as_left {
+            return Err(PadzError::Api(format!(
+                "Cannot undo: '{}' has changed since",
+                title(change)
+            )));
+        }
+    }
+    if let Some(tags) = &record.tags {
+        if store.load_tags(scope)? != tags.after {
+            return Err(PadzError::Api(
+                "Cannot undo: the tags have changed since".to_string(),
+            ));
+        }
+    }
+    Ok(())
+}
+
+fn put_back<S: DataStore>(store: &mut S, scope: Scope, change: &PadChange) -> Result<UndonePad> {
+    let title = title(change).to_string();
+    let Some(before) = &change.before else {
+        if let Some(after) = &change.after {
+            store.delete_pad(&change.id, scope, after.bucket)?;
+        }
+        return Ok(UndonePad {
+            title,
+            undone: Undone::Removed,
+        });
+    };
+
+    let undone = match &change.after {
+        None => Undone::Recreated {
+            bucket: before.bucket,
+        },
+        Some(after) if after.bucket != before.bucket => {
+            store.move_pad(&change.id, scope, after.bucket, before.bucket)?;
+            Undone::Returned {
+                from: after.bucket,
+                to: before.bucket,
+            }
+        }
+        Some(_) => Undone::Reverted,
+    };
+    // A purged pad needs its body written whether or not one was kept.
+    let content = match (&before.content, &change.after) {
+        (Some(content), _) => Some(content.clone()),
+        (None, None) => Some(String::new()),
+        (None, Some(_)) => None,
+    };
+    match content {
+        Some(content) => {
+            let pad = Pad {
+                metadata: before.metadata.clone(),
+                content,
+            };
+            store.save_pad(&pad, scope, before.bucket)?
+        }
+        None => store.save_metadata(&before.metadata, scope, before.bucket)?,
+    }
+    Ok(UndonePad { title, undone })
+}
+
+fn title(change: &PadChange) -> &str {
+    change
+        .before
+        .as_ref()
+        .or(change.after.as_ref())
+        .map_or("", |state| state.metadata.title.as_str())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, delete, pinning, purge};
+    use crate::index::{DisplayIndex, PadSelector};
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+    use crate::store::transaction;
+
+    type Store = BucketedStore<MemBackend>;
+
+    fn store_with(titles: &[&str]) -> Store {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        for title in titles {
+            create::run(&mut store, Scope::Project, (*title).into(), "".into(), None).unwrap();
+        }
+        store
+    }
+
+    fn first() -> Vec<PadSelector> {
+        vec![PadSelector::Path(vec![DisplayIndex::Regular(1)])]
+    }
+
+    fn undo(store: &mut Store) -> Result<UndoOutcome> {
+        transaction(store, |store| run(store, Scope::Project))
+    }
+
+    fn titles(store: &Store, bucket: Bucket) -> Vec<String> {
+        let mut titles: Vec<String> = store
+            .list_metadata(Scope::Project, bucket)
+            .unwrap()
+            .into_iter()
+            .map(|m| m.title)
+            .collect();
+        titles.sort();
+        titles
+    }
+
+    #[test]
+    fn a_delete_is_undone_and_undoing_again_redoes_it() {
+        let mut store = store_with(&["Groceries"]);
+        transaction(&mut store, |s| delete::run(s, Scope::Project, &first())).unwrap();
+        assert!(titles(&store, Bucket::Active).is_empty());
+
+        let outcome = undo(&mut store).unwrap();
+        assert_eq!(
+            outcome.pads,
+            vec![UndonePad {
+                title: "Groceries".into(),
+                undone: Undone::Returned {
+                    from: Bucket::Deleted,
+                    to: Bucket::Active,
+                },
+            }]
+        );
+        assert_eq!(titles(&store, Bucket::Active), vec!["Groceries"]);
+        assert!(titles(&store, Bucket::Deleted).is_empty());
+
+        undo(&mut store).unwrap();
+        assert_eq!(titles(&store, Bucket::Deleted), vec!["Groceries"]);
+    }
+
+    #[test]
+    fn a_purged_pad_comes_back_with_its_body() {
+        let mut store = store_with(&[]);
+        create::run(
+            &mut store,
+            Scope::Project,
+            "Groceries".into(),
+            "Milk".into(),
+            None,
+        )
+        .unwrap();
+        transaction(&mut store, |s| delete::run(s, Scope::Project, &first())).unwrap();
+        let deleted = vec![PadSelector::Path(vec![DisplayIndex::Deleted(1)])];
+        transaction(&mut store, |s| {
+            purge::run(s, Scope::Project, &deleted, false, true, false)
+        })
+        .unwrap();
+
+        let outcome = undo(&mut store).unwrap();
+        assert_eq!(
+            outcome.pads[0].undone,
+            Undone::Recreated {
+                bucket: Bucket::Deleted
+            }
+        );
+        let pad = &store.list_pads(Scope::Project, Bucket::Deleted).unwrap()[0];
+        assert!(pad.content.contains("Milk"));
+    }
+
+    #[test]
+    fn a_pad_changed_since_is_not_overwritten() {
+        let mut store = store_with(&["Groceries", "Taxes"]);
+        transaction(&mut store, |s| pinning::pin(s, Scope::Project, &first())).unwrap();
+        let pinned = vec![PadSelector::Path(vec![DisplayIndex::Pinned(1)])];
+        pinning::unpin(&mut store, Scope::Project, &pinned).unwrap();
+
+        let err = undo(&mut store).unwrap_err();
+        assert!(err.to_string().contains("has changed since"));
+        assert!(store
+            .list_metadata(Scope::Project, Bucket::Active)
+            .unwrap()
+            .iter()
+            .all(|m| !m.is_pinned));
+    }
+
+    #[test]
+    fn nothing_to_undo_is_an_error() {
+        let mut store = store_with(&["Groceries"]);
+        assert!(undo(&mut store).is_err());
+    }
+}
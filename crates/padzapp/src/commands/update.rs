@@ -92,28 +92,76 @@ pub fn run_from_content<S: DataStore>(
     let (title, full_content) = parse_pad_content(raw_content)
         .ok_or_else(|| PadzError::Api("Piped content is empty or invalid".to_string()))?;
 
+    rewrite_content(store, scope, selectors, |_| {
+        Ok((title.clone(), full_content.clone()))
+    })
+}
+
+/// Which end of a pad [`run_amend`] adds its text to.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Amend {
+    Append,
+    Prepend,
+}
+
+/// Adds `text` to the end or the start of each selected pad, keeping what is
+/// already there. The title is read again from the result, so a prepended
+/// line becomes the pad's title. Backs `padz append` and `padz prepend`.
+pub fn run_amend<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    text: &str,
+    at: Amend,
+) -> Result<CmdResult> {
+    let text = text.trim();
+    if text.is_empty() {
+        return Err(PadzError::Api("Nothing to add".to_string()));
+    }
+    rewrite_content(store, scope, selectors, |content| {
+        let combined = match at {
+            Amend::Append => format!("{}\n{}", content.trim_end(), text),
+            Amend::Prepend => format!("{}\n{}", text, content.trim_start()),
+        };
+        parse_pad_content(&combined)
+            .ok_or_else(|| PadzError::Api("Pad content is empty".to_string()))
+    })
+}
+
+/// Replaces each selected pad's title and content with what `new_content`
+/// makes of its current content, reporting them as content updates. A
+/// reference's source is rewritten in its place (see [`super::refs`]).
+fn rewrite_content<S: DataStore>(
+    store: &mut S,
+    scope: Scope,
+    selectors: &[PadSelector],
+    new_content: impl Fn(&str) -> Result<(String, String)>,
+) -> Result<CmdResult> {
     // Resolve selectors to UUIDs
     let resolved = resolve_selectors(store, scope, selectors, false, TitleBucket::Active)?;
 
     let mut result = CmdResult::default();
 
     for (display_index, uuid) in resolved {
-        let mut pad = store.get_pad(&uuid, scope, Bucket::Active)?;
+        let selected = store.get_pad(&uuid, scope, Bucket::Active)?;
+        let (target_scope, target) = super::refs::target(&selected.metadata, scope);
+        let mut pad = store.get_pad(&target, target_scope, Bucket::Active)?;
 
         // Update the pad with the new content
-        pad.metadata.title = title.clone();
+        let (title, full_content) = new_content(&pad.content)?;
+        pad.metadata.title = title;
         pad.metadata.updated_at = Utc::now();
-        pad.content = full_content.clone();
+        pad.content = full_content;
 
         let parent_id = pad.metadata.parent_id;
-        store.save_pad(&pad, scope, Bucket::Active)?;
+        store.save_pad(&pad, target_scope, Bucket::Active)?;
 
         // Propagate status change to parent. The `updated_at` field on
         // ancestors is intentionally NOT bumped here — that field is the
         // content-mtime proxy used by store reconciliation. Subtree activity
         // is computed at index time via `effective_updated_at`, so a nested
         // edit still surfaces the parent under `ordering = updated_at`.
-        crate::todos::propagate_status_change(store, scope, parent_id)?;
+        crate::todos::propagate_status_change(store, target_scope, parent_id)?;
 
         result.outcomes.push(CmdOutcome::Updated {
             path: display_index.clone(),
@@ -121,6 +169,14 @@ pub fn run_from_content<S: DataStore>(
             update_kind: UpdateKind::Content,
         });
 
+        // A reference is reported as itself, showing what its source now says.
+        if target != uuid {
+            let mut reference = selected;
+            reference.metadata.title = pad.metadata.title;
+            reference.content = pad.content;
+            pad = reference;
+        }
+
         let local_index = display_index
             .last()
             .cloned()
@@ -698,4 +754,61 @@ mod tests {
         let result = run_from_content(&mut store, Scope::Project, &selectors, raw_content);
         assert!(result.is_err());
     }
+
+    // --- run_amend tests (append/prepend) ---
+
+    #[test]
+    fn run_amend_appends_to_the_body_and_prepends_a_new_title() {
+        let mut store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        create::run(&mut store, Scope::Project, "Log".into(), "".into(), None).unwrap();
+        let selectors = vec![PadSelector::Path(vec![DisplayIndex::Regular(1)])];
+
+        run_amend(
+            &mut store,
+            Scope::Project,
+            &selectors,
+            "first\n",
+            Amend::Append,
+        )
+        .unwrap();
+        let result = run_amend(
+            &mut store,
+            Scope::Project,
+            &selectors,
+            "second",
+            Amend::Append,
+        )
+        .unwrap();
+        assert_eq!(result.affected_pads[0].pad.metadata.title, "Log");
+        assert_eq!(result.affected_pads[0].pad.content, "Log\n\nfirst\nsecond");
+
+        let result = run_amend(
+            &mut store,
+            Scope::Project,
+            &selectors,
+            "Today",
+            Amend::Prepend,
+        )
+        .unwrap();
+        assert_eq!(result.affected_pads[0].pad.metadata.title, "Today");
+        assert_eq!(
+            result.affected_pads[0].pad.content,
+            "Today\n\nLog\n\nfirst\nsecond"
+        );
+        assert_eq!(
+            result.outcomes,
+            vec![CmdOutcome::Updated {
+                path: vec![DisplayIndex::Regular(1)],
+                title: "Today".to_string(),
+                update_kind: UpdateKind::Content,
+            }]
+        );
+
+        assert!(run_amend(&mut store, Scope::Project, &selectors, " \n", Amend::Append).is_err());
+    }
 }
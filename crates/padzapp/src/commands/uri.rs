@@ -0,0 +1,81 @@
+//! Finding the store a pad URI points into (`padz open-uri`).
+//!
+//! A URI ([`crate::index::pad_uri`]) names its scope but not its project: a
+//! link clicked in another app has no working directory to find one from. A
+//! global pad is in the global store. A project pad is looked for in each
+//! project store of the scope registry ([`crate::store::registry`]), every
+//! bucket, oldest registration first, until one holds it. Remote stores are
+//! left out, as reading one means a fetch.
+
+use crate::error::{PadzError, Result};
+use crate::model::Scope;
+use crate::store::fs::FileStore;
+use crate::store::registry;
+use crate::store::{Bucket, DataStore};
+use std::path::{Path, PathBuf};
+use uuid::Uuid;
+
+/// The `.padz` directory of the project store holding pad `id`, or `None`
+/// for a global pad.
+pub fn locate(global_dir: &Path, scope: Scope, id: &Uuid) -> Result<Option<PathBuf>> {
+    if scope == Scope::Global {
+        return Ok(None);
+    }
+    for entry in registry::load(global_dir).map_err(PadzError::Io)? {
+        if entry.remote.is_some() || !entry.path.is_dir() {
+            continue;
+        }
+        let store = FileStore::new_fs(Some(entry.path.clone()), global_dir.to_path_buf());
+        if holds(&store, id)? {
+            return Ok(Some(entry.path));
+        }
+    }
+    Err(PadzError::Api(format!(
+        "No registered project has pad {}; open its project with padz once to register it",
+        id
+    )))
+}
+
+fn holds<S: DataStore>(store: &S, id: &Uuid) -> Result<bool> {
+    for bucket in [Bucket::Active, Bucket::Archived, Bucket::Deleted] {
+        if store
+            .list_metadata(Scope::Project, bucket)?
+            .iter()
+            .any(|metadata| metadata.id == *id)
+        {
+            return Ok(true);
+        }
+    }
+    Ok(false)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::create;
+    use std::fs;
+    use tempfile::TempDir;
+
+    #[test]
+    fn a_project_pad_is_found_in_the_registered_store_that_holds_it() {
+        let temp = TempDir::new().unwrap();
+        let global = temp.path().join("global");
+        let mut found = None;
+        for project in ["app", "site"] {
+            let padz = temp.path().join(project).join(".padz");
+            fs::create_dir_all(&padz).unwrap();
+            registry::register(&global, &padz).unwrap();
+            let mut store = FileStore::new_fs(Some(padz.clone()), global.clone());
+            let pad = create::run(&mut store, Scope::Project, project.into(), "".into(), None)
+                .unwrap()
+                .affected_pads
+                .remove(0);
+            found = Some((fs::canonicalize(&padz).unwrap(), pad.pad.metadata.id));
+        }
+        let (padz, id) = found.unwrap();
+
+        assert_eq!(locate(&global, Scope::Project, &id).unwrap(), Some(padz));
+        assert_eq!(locate(&global, Scope::Global, &id).unwrap(), None);
+        assert!(locate(&global, Scope::Project, &Uuid::new_v4()).is_err());
+    }
+}
@@ -0,0 +1,72 @@
+//! Store integrity: compare content files against the checksums padz recorded.
+
+use crate::error::Result;
+use crate::model::Scope;
+pub use crate::store::integrity::{IntegrityIssue, IntegrityProblem, IntegrityReport};
+use crate::store::DataStore;
+
+pub fn run<S: DataStore>(store: &S, scope: Scope) -> Result<IntegrityReport> {
+    store.check_integrity(scope)
+}
+
+/// Adopt the current contents as correct, clearing checksum mismatches.
+pub fn accept<S: DataStore>(store: &mut S, scope: Scope) -> Result<usize> {
+    store.accept_checksums(scope)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, delete};
+    use crate::index::{DisplayIndex, PadSelector};
+    use crate::store::backend::StorageBackend;
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+    use crate::store::Bucket;
+
+    fn store() -> BucketedStore<MemBackend> {
+        BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        )
+    }
+
+    #[test]
+    fn clean_store_checks_every_bucket() {
+        let mut store = store();
+        for title in ["A", "B"] {
+            create::run(&mut store, Scope::Project, title.into(), "".into(), None).unwrap();
+        }
+        let first = PadSelector::Path(vec![DisplayIndex::Regular(1)]);
+        delete::run(&mut store, Scope::Project, std::slice::from_ref(&first)).unwrap();
+
+        let report = run(&store, Scope::Project).unwrap();
+
+        assert_eq!(report.checked, 2);
+        assert!(report.issues.is_empty());
+    }
+
+    #[test]
+    fn tampered_content_is_reported_until_accepted() {
+        let mut store = store();
+        create::run(&mut store, Scope::Project, "A".into(), "".into(), None).unwrap();
+        let id = store.list_pads(Scope::Project, Bucket::Active).unwrap()[0]
+            .metadata
+            .id;
+        store
+            .active_store_mut()
+            .backend
+            .write_content(&id, Scope::Project, "A\n\nEdited by hand")
+            .unwrap();
+
+        let report = run(&store, Scope::Project).unwrap();
+        assert_eq!(report.issues.len(), 1);
+        assert_eq!(report.issues[0].problem, IntegrityProblem::Mismatch);
+        assert_eq!(report.issues[0].bucket, Bucket::Active);
+
+        assert_eq!(accept(&mut store, Scope::Project).unwrap(), 1);
+        assert!(run(&store, Scope::Project).unwrap().issues.is_empty());
+    }
+}
@@ -1,5 +1,5 @@
 use crate::commands::{CmdResult, NestingMode};
-use crate::error::Result;
+use crate::error::{Result, StoreWarning};
 use crate::index::PadSelector;
 use crate::model::Scope;
 use crate::store::{Bucket, DataStore};
@@ -29,9 +29,17 @@ pub fn run<S: DataStore>(
     let paths: Vec<_> = nested
         .iter()
         .filter_map(|np| {
-            store
-                .get_pad_path(&np.pad.pad.metadata.id, scope, Bucket::Active)
-                .ok()
+            let pad = &np.pad.pad;
+            match store.get_pad_path(&pad.metadata.id, scope, Bucket::Active) {
+                Ok(path) => Some(path),
+                Err(e) => {
+                    store.warn(StoreWarning::PathUnavailable {
+                        title: pad.metadata.title.clone(),
+                        error: e.to_string(),
+                    });
+                    None
+                }
+            }
         })
         .collect();
 
@@ -0,0 +1,174 @@
+//! Where a pad comes from (`padz which`).
+//!
+//! For each selected pad: the index it resolved to, the scope and store that
+//! hold it, the file its content is in, and what padz recorded about it — the
+//! UUID, the creation context, the content's size and checksum. The answer to
+//! "which note am I actually editing?".
+//!
+//! Like [`super::timeline`], any pad can be asked about, deleted and archived
+//! ones included.
+
+use crate::commands::helpers::{bucket_for_index, indexed_pads, resolve_selectors, TitleBucket};
+use crate::error::Result;
+use crate::index::{DisplayIndex, PadSelector};
+use crate::model::{Metadata, Scope};
+use crate::store::integrity::content_checksum;
+use crate::store::{Bucket, DataStore};
+use serde::Serialize;
+use std::path::PathBuf;
+
+/// What is known about one pad and where it lives.
+#[derive(Debug, Clone, Serialize)]
+pub struct Provenance {
+    /// The index path the selector resolved to (`[p1]`, `[2, 1]`).
+    pub index: Vec<DisplayIndex>,
+    pub scope: Scope,
+    pub bucket: Bucket,
+    /// The store the pad is in: a project's `.padz`, or the global store.
+    pub store_dir: PathBuf,
+    /// The file holding the pad's content.
+    pub file: PathBuf,
+    /// Size of the content, in bytes.
+    pub size: usize,
+    /// Whether the content still matches the checksum padz recorded when it
+    /// last wrote it; `None` when none was recorded.
+    pub checksum_matches: Option<bool>,
+    /// Everything the index records: UUID, title, dates, checksum, context.
+    pub metadata: Metadata,
+}
+
+/// The provenance of the selected pads, in selection order.
+#[derive(Debug, Clone, Serialize)]
+pub struct WhichOutcome {
+    pub pads: Vec<Provenance>,
+}
+
+pub fn run<S: DataStore>(
+    store: &S,
+    scope: Scope,
+    store_dir: PathBuf,
+    selectors: &[PadSelector],
+) -> Result<WhichOutcome> {
+    let resolved = resolve_selectors(store, scope, selectors, false, TitleBucket::Any)?;
+    let indexed = indexed_pads(store, scope)?;
+
+    let mut pads = Vec::with_capacity(resolved.len());
+    for (index, uuid) in resolved {
+        let Some(dp) = super::helpers::find_pad_by_uuid(&indexed, uuid, |_| true) else {
+            continue;
+        };
+        let bucket = index.last().map(bucket_for_index).unwrap_or(Bucket::Active);
+        let metadata = dp.pad.metadata.clone();
+        let checksum_matches = metadata
+            .checksum
+            .as_ref()
+            .map(|recorded| *recorded == content_checksum(&dp.pad.content));
+        pads.push(Provenance {
+            file: store.get_pad_path(&uuid, scope, bucket)?,
+            index,
+            scope,
+            bucket,
+            store_dir: store_dir.clone(),
+            size: dp.pad.content.len(),
+            checksum_matches,
+            metadata,
+        });
+    }
+    Ok(WhichOutcome { pads })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::{create, delete, pinning};
+    use crate::store::bucketed::BucketedStore;
+    use crate::store::mem_backend::MemBackend;
+
+    fn store() -> BucketedStore<MemBackend> {
+        BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        )
+    }
+
+    fn select(index: DisplayIndex) -> Vec<PadSelector> {
+        vec![PadSelector::Path(vec![index])]
+    }
+
+    #[test]
+    fn test_which_reports_where_an_active_pad_lives() {
+        let mut store = store();
+        let created =
+            create::run(&mut store, Scope::Project, "Plan".into(), "v1".into(), None).unwrap();
+        let id = created.affected_pads[0].pad.metadata.id;
+
+        let outcome = run(
+            &store,
+            Scope::Project,
+            PathBuf::from("/work/.padz"),
+            &select(DisplayIndex::Regular(1)),
+        )
+        .unwrap();
+
+        let which = &outcome.pads[0];
+        assert_eq!(which.index, vec![DisplayIndex::Regular(1)]);
+        assert_eq!(which.metadata.id, id);
+        assert_eq!(which.scope, Scope::Project);
+        assert_eq!(which.bucket, Bucket::Active);
+        assert_eq!(which.store_dir, PathBuf::from("/work/.padz"));
+        assert_eq!(
+            which.file,
+            store
+                .get_pad_path(&id, Scope::Project, Bucket::Active)
+                .unwrap()
+        );
+        assert!(which.size > 0);
+        assert_eq!(which.checksum_matches, Some(true));
+    }
+
+    #[test]
+    fn test_which_keeps_the_index_a_pinned_pad_resolved_to() {
+        let mut store = store();
+        create::run(&mut store, Scope::Project, "Plan".into(), "".into(), None).unwrap();
+        pinning::pin(
+            &mut store,
+            Scope::Project,
+            &select(DisplayIndex::Regular(1)),
+        )
+        .unwrap();
+
+        let outcome = run(
+            &store,
+            Scope::Project,
+            PathBuf::new(),
+            &select(DisplayIndex::Pinned(1)),
+        )
+        .unwrap();
+        assert_eq!(outcome.pads[0].index, vec![DisplayIndex::Pinned(1)]);
+        assert_eq!(outcome.pads[0].bucket, Bucket::Active);
+    }
+
+    #[test]
+    fn test_which_finds_deleted_pads_in_their_bucket() {
+        let mut store = store();
+        create::run(&mut store, Scope::Project, "Old".into(), "".into(), None).unwrap();
+        delete::run(
+            &mut store,
+            Scope::Project,
+            &select(DisplayIndex::Regular(1)),
+        )
+        .unwrap();
+
+        let outcome = run(
+            &store,
+            Scope::Project,
+            PathBuf::new(),
+            &select(DisplayIndex::Deleted(1)),
+        )
+        .unwrap();
+        assert_eq!(outcome.pads[0].bucket, Bucket::Deleted);
+        assert_eq!(outcome.pads[0].metadata.title, "Old");
+    }
+}
@@ -19,6 +19,45 @@
 //! | `import_extensions` | `["md", "txt", "text", "lex"]` | Extensions for `padz import` |
 //! | `mode` | `notes` | UI mode: `notes` (clean) or `todos` (status icons, quick-create) |
 //! | `ordering` | `created_at` | Sort key for listings: `created_at` or `updated_at` |
+//! | `usage_stats` | `false` | Count commands and options locally for `padz stats --usage` (opt-in) |
+//! | `capture_context` | `false` | Record git commit, branch, dirty state and cwd on new pads |
+//! | `identity.name` | git `user.name`, else `$USER` | Who you are, recorded as the owner of each new pad |
+//! | `identity.email` | git `user.email` | Your address; `export --sign` signs with its gpg key |
+//! | `owner_only_edit` | `false` | Editing, deleting, moving, purging or undoing a pad someone else created needs `--force` |
+//! | `max_pins` | unset | Pads a scope may have pinned; `pin --evict` unpins the oldest to make room |
+//! | `clipboard` | unset | Clipboard provider: `auto`, `osc52`, or a tool such as `xclip` |
+//! | `lint_command` | unset | Linter run on a pad after the editor saves it |
+//! | `create_template` | unset | File new pads start from, relative to the store directory |
+//! | `on_duplicate_title` | `ask` | What `create` does with a title an active pad has: `ask`, `open`, `append` or `create` |
+//! | `event_command` | unset | Command that reads each change padz writes, as JSON lines on stdin |
+//! | `event_socket` | unset | Unix socket each change padz writes is sent to, as JSON lines |
+//! | `spell_language` | `en` | Dictionary used by `view --spell` and `open --spell` |
+//! | `global_store` | unset | `s3://bucket/prefix` the global store is synced with |
+//! | `global_store_endpoint` | unset | Endpoint of an S3-compatible service (MinIO, R2, ...) |
+//! | `editor` | `$EDITOR`, else `$VISUAL` | Editor padz opens pads in, ahead of the environment |
+//! | `editor_timeout` | `0` | Seconds to wait for the editor to close; `0` waits for as long as it takes |
+//! | `command_timeout` | `30` | Seconds the linter, `{{shell}}` directives, `create --from`, git and gpg may run; `0` is no limit |
+//! | `network_timeout` | `300` | Seconds one `aws` or `sftp` call may run; `0` is no limit |
+//! | `update_check` | `false` | Ask GitHub once a day whether a newer padz is out (opt-in) |
+//! | `compress_above` | unset | Keep pads larger than this many bytes gzipped |
+//! | `list.max_title` | unset | Cut listed titles longer than this many characters short with `…` |
+//! | `list.density` | `compact` | `compact` (one line per pad) or `comfortable` (a blank line between pads) |
+//! | `list.repeat_pinned` | `true` | Show pinned pads again, in place, below the pinned block |
+//! | `list.hyperlinks` | `true` | Link each listed pad to its `padz://` URI in terminals that open links |
+//! | `list.time_format` | `short` | Ages in listings, trees, the journal and stats: `short`, `humanized` or `absolute` |
+//! | `trash.warn_pads` | `100` | `list` and `maintain` suggest `padz purge` past this many deleted pads; `0` never |
+//! | `trash.warn_bytes` | `1048576` | The same past this many bytes of deleted pad bodies; `0` never |
+//! | `journal.parent` | `Journal` | Title of the root pad `padz today` files each day's note under |
+//! | `publish.github_token` | `$GITHUB_TOKEN` | Token `publish --gist` creates and updates gists with |
+//! | `publish.gitlab_token` | `$GITLAB_TOKEN` | Token `publish --gitlab-snippet` uses (`api` scope) |
+//! | `publish.gitlab_url` | `https://gitlab.com` | GitLab instance snippets are published on |
+//! | `experimental.sync` | `false` | Turn on the `global_store` bucket sync, still experimental |
+//!
+//! ## Experimental Features
+//!
+//! Subsystems that are not ready for everyone ship dark behind a flag in the
+//! `[experimental]` table ([`ExperimentalFlags`]); `padz config set
+//! experimental.sync true` turns one on.
 //!
 //! ## Extension Convention
 //!
@@ -91,6 +130,241 @@ impl std::fmt::Display for OrderingKey {
     }
 }
 
+/// Spacing of a listing.
+///
+/// - **Compact**: one line per pad.
+/// - **Comfortable**: a blank line between top-level pads, so a nested pad
+///   reads with its parent.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize, Default)]
+#[serde(rename_all = "lowercase")]
+pub enum ListDensity {
+    #[default]
+    Compact,
+    Comfortable,
+}
+
+impl std::fmt::Display for ListDensity {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self {
+            ListDensity::Compact => write!(f, "compact"),
+            ListDensity::Comfortable => write!(f, "comfortable"),
+        }
+    }
+}
+
+/// How a pad's age is shown wherever one is rendered.
+///
+/// - **Short**: the largest whole unit, abbreviated (`3h`, `2d`).
+/// - **Humanized**: the same count spelled out (`3 hours`, `2 days`).
+/// - **Absolute**: the local date (`2026-10-15`), or the time of day for
+///   today (`14:05`).
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize, Default)]
+#[serde(rename_all = "lowercase")]
+pub enum TimeFormat {
+    #[default]
+    Short,
+    Humanized,
+    Absolute,
+}
+
+impl std::fmt::Display for TimeFormat {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self {
+            TimeFormat::Short => write!(f, "short"),
+            TimeFormat::Humanized => write!(f, "humanized"),
+            TimeFormat::Absolute => write!(f, "absolute"),
+        }
+    }
+}
+
+/// What `create` does when an active pad already has the new pad's title.
+///
+/// - **Ask**: put the other three to the user; without a terminal to ask on,
+///   create.
+/// - **Open**: open the existing pad in the editor instead.
+/// - **Append**: add the new text to the end of the existing pad.
+/// - **Create**: create a second pad with the same title.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize, Default)]
+#[serde(rename_all = "lowercase")]
+pub enum DuplicateTitle {
+    #[default]
+    Ask,
+    Open,
+    Append,
+    Create,
+}
+
+impl std::fmt::Display for DuplicateTitle {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self {
+            DuplicateTitle::Ask => write!(f, "ask"),
+            DuplicateTitle::Open => write!(f, "open"),
+            DuplicateTitle::Append => write!(f, "append"),
+            DuplicateTitle::Create => write!(f, "create"),
+        }
+    }
+}
+
+/// How listings are laid out, the `[list]` table of `padz.toml`.
+#[derive(Config, Serialize, Deserialize, Debug, Clone, PartialEq, Eq)]
+pub struct ListConfig {
+    /// Titles longer than this many characters are cut short with `…`.
+    /// Unset, a title takes whatever room the terminal leaves it.
+    pub max_title: Option<usize>,
+
+    /// "compact" (one line per pad) or "comfortable" (a blank line between
+    /// top-level pads).
+    #[config(default = "compact")]
+    #[serde(default)]
+    pub density: ListDensity,
+
+    /// Whether a pinned pad appears twice: in the pinned block, and again at
+    /// its place in the list below it.
+    #[config(default = true)]
+    #[serde(default = "default_repeat_pinned")]
+    pub repeat_pinned: bool,
+
+    /// Whether a listed pad's line is a link to its `padz://` URI, in a
+    /// terminal known to open OSC 8 links.
+    #[config(default = true)]
+    #[serde(default = "default_hyperlinks")]
+    pub hyperlinks: bool,
+
+    /// "short" (`3h`), "humanized" (`3 hours`) or "absolute" (`2026-10-15`):
+    /// how ages read in listings and every other render that shows one.
+    #[config(default = "short")]
+    #[serde(default)]
+    pub time_format: TimeFormat,
+}
+
+fn default_repeat_pinned() -> bool {
+    true
+}
+
+fn default_hyperlinks() -> bool {
+    true
+}
+
+impl Default for ListConfig {
+    fn default() -> Self {
+        Self {
+            max_title: None,
+            density: ListDensity::default(),
+            repeat_pinned: default_repeat_pinned(),
+            hyperlinks: default_hyperlinks(),
+            time_format: TimeFormat::default(),
+        }
+    }
+}
+
+/// Who is running padz, the `[identity]` table of `padz.toml`. Each key left
+/// unset is read from git's `user.name` and `user.email` by the CLI.
+#[derive(Config, Serialize, Deserialize, Debug, Clone, PartialEq, Eq, Default)]
+pub struct IdentityConfig {
+    /// The name recorded as the owner of each pad created (see
+    /// [`crate::commands::ownership`]).
+    pub name: Option<String>,
+
+    /// The address whose gpg key signs exports.
+    pub email: Option<String>,
+}
+
+/// When the trash is worth mentioning, the `[trash]` table of `padz.toml`.
+/// Past either limit, `list` and `maintain` suggest `padz purge`; a limit of
+/// `0` never warns.
+#[derive(Config, Serialize, Deserialize, Debug, Clone, PartialEq, Eq)]
+pub struct TrashConfig {
+    /// Deleted pads, nested ones included.
+    #[config(default = 100)]
+    #[serde(default = "default_trash_warn_pads")]
+    pub warn_pads: usize,
+
+    /// Bytes of deleted pad bodies.
+    #[config(default = 1048576)]
+    #[serde(default = "default_trash_warn_bytes")]
+    pub warn_bytes: u64,
+}
+
+fn default_trash_warn_pads() -> usize {
+    100
+}
+
+fn default_trash_warn_bytes() -> u64 {
+    1024 * 1024
+}
+
+impl Default for TrashConfig {
+    fn default() -> Self {
+        Self {
+            warn_pads: default_trash_warn_pads(),
+            warn_bytes: default_trash_warn_bytes(),
+        }
+    }
+}
+
+/// Where daily notes go, the `[journal]` table of `padz.toml` (see
+/// [`crate::commands::journal`]).
+#[derive(Config, Serialize, Deserialize, Debug, Clone, PartialEq, Eq)]
+pub struct JournalConfig {
+    /// Title of the root pad the notes are nested in, created on first use.
+    #[config(default = "Journal")]
+    #[serde(default = "default_journal_parent")]
+    pub parent: String,
+}
+
+fn default_journal_parent() -> String {
+    "Journal".to_string()
+}
+
+impl Default for JournalConfig {
+    fn default() -> Self {
+        Self {
+            parent: default_journal_parent(),
+        }
+    }
+}
+
+/// Where `padz publish` uploads to, the `[publish]` table of `padz.toml`.
+/// An unset token is read from `GITHUB_TOKEN` or `GITLAB_TOKEN` by the CLI.
+#[derive(Config, Serialize, Deserialize, Debug, Clone, PartialEq, Eq)]
+pub struct PublishConfig {
+    /// GitHub token allowed to create gists.
+    pub github_token: Option<String>,
+
+    /// GitLab token with the `api` scope.
+    pub gitlab_token: Option<String>,
+
+    /// The GitLab instance snippets go to.
+    #[config(default = "https://gitlab.com")]
+    #[serde(default = "default_gitlab_url")]
+    pub gitlab_url: String,
+}
+
+fn default_gitlab_url() -> String {
+    "https://gitlab.com".to_string()
+}
+
+impl Default for PublishConfig {
+    fn default() -> Self {
+        Self {
+            github_token: None,
+            gitlab_token: None,
+            gitlab_url: default_gitlab_url(),
+        }
+    }
+}
+
+/// Switches for features that ship dark, the `[experimental]` table of
+/// `padz.toml`. Every flag is off unless the user turns it on.
+#[derive(Config, Serialize, Deserialize, Debug, Clone, PartialEq, Eq, Default)]
+pub struct ExperimentalFlags {
+    /// Sync the global store with the `global_store` bucket (see
+    /// [`crate::store::object`]). Off, `global_store` is ignored.
+    #[config(default = false)]
+    #[serde(default)]
+    pub sync: bool,
+}
+
 fn default_import_ext() -> Vec<String> {
     vec![
         "md".to_string(),
@@ -136,6 +410,159 @@ pub struct PadzConfig {
     #[config(default = "created_at")]
     #[serde(default)]
     pub ordering: OrderingKey,
+
+    /// Opt in to counting which commands are run, and with which options, for
+    /// `padz stats --usage`.
+    /// Counts stay in a local file; nothing is ever sent anywhere.
+    #[config(default = false)]
+    #[serde(default)]
+    pub usage_stats: bool,
+
+    /// Opt in to recording the git commit, branch, dirty state and working
+    /// directory on pads created inside a repository (see
+    /// [`crate::model::CreationContext`]).
+    #[config(default = false)]
+    #[serde(default)]
+    pub capture_context: bool,
+
+    /// In a scope several people share, refuse to edit, delete, move, purge
+    /// or undo a pad someone else created unless `--force` is given.
+    #[config(default = false)]
+    #[serde(default)]
+    pub owner_only_edit: bool,
+
+    /// How many pads a scope may have pinned. A pin past it fails, unless
+    /// `--force` unpins the longest-pinned pads to make room (see
+    /// [`crate::commands::pinning`]). Unset means no limit.
+    pub max_pins: Option<usize>,
+
+    /// How `copy` and the other clipboard writes reach the clipboard: "osc52"
+    /// for the terminal, a tool such as "xclip" or "pbcopy", or "auto" to
+    /// detect it. Unset means auto.
+    pub clipboard: Option<String>,
+
+    /// Command run on a pad's file after the editor saves it, e.g.
+    /// "markdownlint" or "vale". The file path is appended as its last
+    /// argument; a non-zero exit reports findings. Unset means no linting.
+    pub lint_command: Option<String>,
+
+    /// File whose text new pads start from, e.g. a bug-report skeleton:
+    /// relative to the store's directory (`.padz/`, or the global data
+    /// directory) unless absolute, so a project's `padz.toml` can point at a
+    /// template kept in its own store. `{{today}}` and `{{now}}` are filled in
+    /// when a pad is created from it; `create --blank` skips it.
+    pub create_template: Option<String>,
+
+    /// What `create` does when an active pad in the scope already has the
+    /// title: "ask" (the default), "open", "append" or "create".
+    #[config(default = "ask")]
+    #[serde(default)]
+    pub on_duplicate_title: DuplicateTitle,
+
+    /// Command told about every change a padz command writes (a pad created,
+    /// updated, pinned, deleted, ...), e.g. a status-bar refresh or a backup
+    /// trigger. It runs through `sh` after each change with the events, one
+    /// JSON object per line, on its stdin (see [`crate::store::events`]).
+    /// Unset means no command.
+    pub event_command: Option<String>,
+
+    /// Unix socket the same events are written to, for a listener that is
+    /// already running. Unset means no socket.
+    pub event_socket: Option<String>,
+
+    /// Language of the spell-check dictionary (`view --spell`), naming the
+    /// word list `dictionaries/<language>.dic` in the global config directory.
+    #[config(default = "en")]
+    #[serde(default = "default_spell_language")]
+    pub spell_language: String,
+
+    /// Bucket the global store is kept in, as `s3://bucket/prefix` (see
+    /// [`crate::store::object`]). Unset keeps it on this machine only.
+    pub global_store: Option<String>,
+
+    /// Endpoint URL of an S3-compatible service; unset means AWS S3.
+    pub global_store_endpoint: Option<String>,
+
+    /// Editor padz opens pads in, e.g. "nvim". Set, it is used ahead of
+    /// `$EDITOR` and `$VISUAL`, so padz can have an editor of its own.
+    pub editor: Option<String>,
+
+    /// Seconds to wait for the editor to close before giving up on the edit.
+    /// `0`, the default, waits for as long as the user takes.
+    #[config(default = 0)]
+    #[serde(default)]
+    pub editor_timeout: u64,
+
+    /// Seconds a short-lived program padz runs may take: the `lint_command`,
+    /// `{{shell "..."}}` directives, `create --from`, git, gpg. `0` means no
+    /// limit.
+    #[config(default = 30)]
+    #[serde(default = "default_command_timeout")]
+    pub command_timeout: u64,
+
+    /// Seconds one call to the network may take: an `aws` request of the
+    /// `global_store` sync, or a `--remote` fetch over sftp. `0` means no
+    /// limit.
+    #[config(default = 300)]
+    #[serde(default = "default_network_timeout")]
+    pub network_timeout: u64,
+
+    /// Opt in to asking GitHub, at most once a day, whether a newer padz has
+    /// been released, and to a one-line notice on stderr when one has (see
+    /// [`crate::update`]).
+    #[config(default = false)]
+    #[serde(default)]
+    pub update_check: bool,
+
+    /// Pads whose body is larger than this many bytes are kept gzipped, in
+    /// every bucket (see [`crate::store::compression`]). Unset never
+    /// compresses.
+    pub compress_above: Option<u64>,
+
+    /// Who is running padz (`identity.name`, `identity.email`).
+    #[config(nested)]
+    #[serde(default)]
+    pub identity: IdentityConfig,
+
+    /// Layout of listings (`list.max_title`, `list.density`,
+    /// `list.repeat_pinned`, `list.hyperlinks`, `list.time_format`).
+    #[config(nested)]
+    #[serde(default)]
+    pub list: ListConfig,
+
+    /// When `list` and `maintain` suggest emptying the trash
+    /// (`trash.warn_pads`, `trash.warn_bytes`).
+    #[config(nested)]
+    #[serde(default)]
+    pub trash: TrashConfig,
+
+    /// Where `today` and `yesterday` file daily notes (`journal.parent`).
+    #[config(nested)]
+    #[serde(default)]
+    pub journal: JournalConfig,
+
+    /// Where `publish` uploads to (`publish.github_token`,
+    /// `publish.gitlab_token`, `publish.gitlab_url`).
+    #[config(nested)]
+    #[serde(default)]
+    pub publish: PublishConfig,
+
+    /// Features that ship dark until they are stable (`experimental.sync`).
+    #[config(nested)]
+    #[serde(default)]
+    pub experimental: ExperimentalFlags,
+}
+
+fn default_spell_language() -> String {
+    "en".to_string()
+}
+
+fn default_command_timeout() -> u64 {
+    30
+}
+
+fn default_network_timeout() -> u64 {
+    300
 }
 
 impl Default for PadzConfig {
@@ -145,6 +572,31 @@ impl Default for PadzConfig {
             import_extensions: None,
             mode: PadzMode::default(),
             ordering: OrderingKey::default(),
+            usage_stats: false,
+            capture_context: false,
+            owner_only_edit: false,
+            max_pins: None,
+            clipboard: None,
+            lint_command: None,
+            create_template: None,
+            on_duplicate_title: DuplicateTitle::Ask,
+            event_command: None,
+            event_socket: None,
+            spell_language: default_spell_language(),
+            global_store: None,
+            global_store_endpoint: None,
+            editor: None,
+            editor_timeout: 0,
+            command_timeout: default_command_timeout(),
+            network_timeout: default_network_timeout(),
+            update_check: false,
+            compress_above: None,
+            identity: IdentityConfig::default(),
+            list: ListConfig::default(),
+            trash: TrashConfig::default(),
+            journal: JournalConfig::default(),
+            publish: PublishConfig::default(),
+            experimental: ExperimentalFlags::default(),
         }
     }
 }
@@ -275,6 +727,46 @@ mod tests {
         assert_eq!(config.format, "md");
     }
 
+    #[test]
+    fn test_list_table_defaults_and_overrides() {
+        let config: PadzConfig = toml::from_str(r#"format = "txt""#).unwrap();
+        assert_eq!(config.list, ListConfig::default());
+        assert!(config.list.repeat_pinned);
+
+        let toml_str = "format = \"txt\"\n[list]\nmax_title = 40\ndensity = \"comfortable\"\nrepeat_pinned = false";
+        let config: PadzConfig = toml::from_str(toml_str).unwrap();
+        assert_eq!(config.list.max_title, Some(40));
+        assert_eq!(config.list.density, ListDensity::Comfortable);
+        assert!(!config.list.repeat_pinned);
+        assert_eq!(config.list.time_format, TimeFormat::Short);
+
+        let config: PadzConfig =
+            toml::from_str("format = \"txt\"\n[list]\ntime_format = \"humanized\"").unwrap();
+        assert_eq!(config.list.time_format, TimeFormat::Humanized);
+    }
+
+    #[test]
+    fn test_trash_table_defaults_and_overrides() {
+        let config: PadzConfig = toml::from_str(r#"format = "txt""#).unwrap();
+        assert_eq!(config.trash, TrashConfig::default());
+        assert_eq!(config.trash.warn_pads, 100);
+
+        let config: PadzConfig =
+            toml::from_str("format = \"txt\"\n[trash]\nwarn_bytes = 0").unwrap();
+        assert_eq!(config.trash.warn_pads, 100);
+        assert_eq!(config.trash.warn_bytes, 0);
+    }
+
+    #[test]
+    fn test_journal_table_defaults_and_overrides() {
+        let config: PadzConfig = toml::from_str(r#"format = "txt""#).unwrap();
+        assert_eq!(config.journal.parent, "Journal");
+
+        let config: PadzConfig =
+            toml::from_str("format = \"txt\"\n[journal]\nparent = \"Log\"").unwrap();
+        assert_eq!(config.journal.parent, "Log");
+    }
+
     #[test]
     fn test_default_mode_is_notes() {
         let config = PadzConfig::default();
@@ -366,4 +858,100 @@ mod tests {
         assert_eq!(OrderingKey::CreatedAt.to_string(), "created_at");
         assert_eq!(OrderingKey::UpdatedAt.to_string(), "updated_at");
     }
+
+    #[test]
+    fn test_usage_stats_is_opt_in() {
+        assert!(!PadzConfig::default().usage_stats);
+        let config: PadzConfig = toml::from_str(r#"format = "txt""#).unwrap();
+        assert!(!config.usage_stats);
+    }
+
+    #[test]
+    fn test_update_check_is_opt_in() {
+        assert!(!PadzConfig::default().update_check);
+        let config: PadzConfig = toml::from_str("format = \"txt\"\nupdate_check = true").unwrap();
+        assert!(config.update_check);
+    }
+
+    #[test]
+    fn test_experimental_flags_are_off_by_default() {
+        assert!(!PadzConfig::default().experimental.sync);
+        let config: PadzConfig = toml::from_str("format = \"txt\"").unwrap();
+        assert_eq!(config.experimental, ExperimentalFlags::default());
+
+        let config: PadzConfig =
+            toml::from_str("format = \"txt\"\n\n[experimental]\nsync = true").unwrap();
+        assert!(config.experimental.sync);
+    }
+
+    #[test]
+    fn test_usage_stats_deserialize_enabled() {
+        let config: PadzConfig = toml::from_str("format = \"txt\"\nusage_stats = true").unwrap();
+        assert!(config.usage_stats);
+    }
+
+    #[test]
+    fn test_capture_context_is_opt_in() {
+        assert!(!PadzConfig::default().capture_context);
+        let config: PadzConfig =
+            toml::from_str("format = \"txt\"\ncapture_context = true").unwrap();
+        assert!(config.capture_context);
+    }
+
+    #[test]
+    fn test_spell_language_defaults_to_english() {
+        assert_eq!(PadzConfig::default().spell_language, "en");
+        let config: PadzConfig = toml::from_str("format = \"md\"").unwrap();
+        assert_eq!(config.spell_language, "en");
+    }
+
+    #[test]
+    fn test_global_store_is_local_by_default() {
+        assert_eq!(PadzConfig::default().global_store, None);
+        let config: PadzConfig =
+            toml::from_str("format = \"txt\"\nglobal_store = \"s3://notes/padz\"").unwrap();
+        assert_eq!(config.global_store.as_deref(), Some("s3://notes/padz"));
+        assert_eq!(config.global_store_endpoint, None);
+    }
+
+    #[test]
+    fn test_create_template_is_unset_by_default() {
+        assert_eq!(PadzConfig::default().create_template, None);
+        let config: PadzConfig = toml::from_str("create_template = \"templates/bug.md\"").unwrap();
+        assert_eq!(config.create_template.as_deref(), Some("templates/bug.md"));
+    }
+
+    #[test]
+    fn test_duplicate_titles_are_asked_about_by_default() {
+        assert_eq!(
+            PadzConfig::default().on_duplicate_title,
+            DuplicateTitle::Ask
+        );
+        let config: PadzConfig =
+            toml::from_str("format = \"txt\"\non_duplicate_title = \"append\"").unwrap();
+        assert_eq!(config.on_duplicate_title, DuplicateTitle::Append);
+        assert_eq!(DuplicateTitle::Append.to_string(), "append");
+    }
+
+    #[test]
+    fn test_lint_command_is_unset_by_default() {
+        assert_eq!(PadzConfig::default().lint_command, None);
+        let config: PadzConfig =
+            toml::from_str("format = \"txt\"\nlint_command = \"vale\"").unwrap();
+        assert_eq!(config.lint_command.as_deref(), Some("vale"));
+    }
+
+    #[test]
+    fn test_timeouts_default_to_no_limit_for_the_editor_only() {
+        let config: PadzConfig = toml::from_str("format = \"txt\"").unwrap();
+        assert_eq!(config, PadzConfig::default());
+        assert_eq!(config.editor_timeout, 0);
+        assert_eq!(config.command_timeout, 30);
+        assert_eq!(config.network_timeout, 300);
+
+        let config: PadzConfig =
+            toml::from_str("format = \"txt\"\ncommand_timeout = 5\neditor_timeout = 600").unwrap();
+        assert_eq!(config.command_timeout, 5);
+        assert_eq!(config.editor_timeout, 600);
+    }
 }
@@ -0,0 +1,178 @@
+//! # Body Directives
+//!
+//! A pad can carry `{{...}}` directives that are filled in when it is viewed,
+//! so a runbook shows current data instead of whatever was true when it was
+//! written:
+//!
+//! - `{{today}}` — the current date (`2026-03-14`),
+//! - `{{now}}` — the current date and time (`2026-03-14 09:30`),
+//! - `{{shell "kubectl get pods | head"}}` — the output of a shell command,
+//! - `{{placeholder:name}}` — a value supplied by whoever uses the pad, which
+//!   turns it into a reusable snippet (`padz fill`). Viewing shows it as is.
+//!
+//! This module only finds directives and splices in their values; producing a
+//! value is the client's business, through the resolver passed to [`expand`].
+//! Reading the clock and running commands are I/O, and running a command from
+//! a note is something the user must ask for explicitly, so the client decides
+//! which directives it is willing to resolve.
+//!
+//! Anything between `{{` and `}}` that is not a directive — a Go or Jinja
+//! template pasted into a note, say — is left exactly as written, as is a
+//! directive the resolver declines.
+
+/// A directive found in a pad body.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum Directive {
+    Today,
+    Now,
+    /// A shell command, as written between the quotes (escapes resolved).
+    Shell(String),
+    /// A named value to fill in.
+    Placeholder(String),
+}
+
+impl Directive {
+    /// Parses the text between `{{` and `}}`. `None` when it is not a directive.
+    pub fn parse(inner: &str) -> Option<Directive> {
+        let inner = inner.trim();
+        match inner {
+            "today" => return Some(Directive::Today),
+            "now" => return Some(Directive::Now),
+            _ => {}
+        }
+        if let Some(name) = inner.strip_prefix("placeholder:") {
+            let name = name.trim();
+            if name.is_empty() || name.contains(char::is_whitespace) {
+                return None;
+            }
+            return Some(Directive::Placeholder(name.to_string()));
+        }
+        let rest = inner.strip_prefix("shell")?;
+        if !rest.starts_with(char::is_whitespace) {
+            return None;
+        }
+        parse_quoted(rest.trim()).map(Directive::Shell)
+    }
+}
+
+/// Parses a whole `"..."` string, resolving `\"` and `\\`. `None` when `text`
+/// is not exactly one quoted string.
+fn parse_quoted(text: &str) -> Option<String> {
+    let body = text.strip_prefix('"')?.strip_suffix('"')?;
+    let mut out = String::with_capacity(body.len());
+    let mut chars = body.chars();
+    while let Some(c) = chars.next() {
+        match c {
+            '\\' => match chars.next()? {
+                e @ ('"' | '\\') => out.push(e),
+                other => {
+                    out.push('\\');
+                    out.push(other);
+                }
+            },
+            '"' => return None,
+            c => out.push(c),
+        }
+    }
+    Some(out)
+}
+
+/// The names of the placeholders in `text`, each once, in order of first use.
+pub fn placeholders(text: &str) -> Vec<String> {
+    let mut names: Vec<String> = Vec::new();
+    expand(text, |directive| {
+        if let Directive::Placeholder(name) = directive {
+            if !names.contains(name) {
+                names.push(name.clone());
+            }
+        }
+        None
+    });
+    names
+}
+
+/// Replaces each directive in `text` with what `resolve` returns for it.
+///
+/// A directive `resolve` returns `None` for stays as written.
+pub fn expand(text: &str, mut resolve: impl FnMut(&Directive) -> Option<String>) -> String {
+    let mut out = String::with_capacity(text.len());
+    let mut rest = text;
+    while let Some(open) = rest.find("{{") {
+        let Some(len) = rest[open + 2..].find("}}") else {
+            break;
+        };
+        let close = open + 2 + len;
+        out.push_str(&rest[..open]);
+        let written = &rest[open..close + 2];
+        match Directive::parse(&rest[open + 2..close]).and_then(|d| resolve(&d)) {
+            Some(value) => out.push_str(&value),
+            None => out.push_str(written),
+        }
+        rest = &rest[close + 2..];
+    }
+    out.push_str(rest);
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_parses_directives() {
+        assert_eq!(Directive::parse("today"), Some(Directive::Today));
+        assert_eq!(Directive::parse(" now "), Some(Directive::Now));
+        assert_eq!(
+            Directive::parse("placeholder: host "),
+            Some(Directive::Placeholder("host".into()))
+        );
+        assert_eq!(
+            Directive::parse(r#"shell "kubectl get pods | head""#),
+            Some(Directive::Shell("kubectl get pods | head".into()))
+        );
+        assert_eq!(
+            Directive::parse(r#"shell "echo \"hi\" \\ \n""#),
+            Some(Directive::Shell(r#"echo "hi" \ \n"#.into()))
+        );
+    }
+
+    #[test]
+    fn test_non_directives_are_not_parsed() {
+        assert_eq!(Directive::parse(".Values.image"), None);
+        assert_eq!(Directive::parse("todays"), None);
+        assert_eq!(Directive::parse("shell"), None);
+        assert_eq!(Directive::parse("shellx \"ls\""), None);
+        assert_eq!(Directive::parse("shell ls"), None);
+        assert_eq!(Directive::parse(r#"shell "a" "b""#), None);
+        assert_eq!(Directive::parse("placeholder:"), None);
+        assert_eq!(Directive::parse("placeholder:two words"), None);
+    }
+
+    #[test]
+    fn test_expand_splices_resolved_values() {
+        let text = "Status on {{today}}:\n{{shell \"uptime\"}}\n{{ .Values.x }}";
+        let expanded = expand(text, |d| match d {
+            Directive::Today => Some("2026-03-14".into()),
+            Directive::Shell(cmd) => Some(format!("<{cmd}>")),
+            _ => None,
+        });
+        assert_eq!(expanded, "Status on 2026-03-14:\n<uptime>\n{{ .Values.x }}");
+    }
+
+    #[test]
+    fn test_declined_directives_stay_as_written() {
+        let text = "{{today}} and {{shell \"ls\"}} and {{ unclosed";
+        let expanded = expand(text, |d| match d {
+            Directive::Today => Some("2026-03-14".into()),
+            _ => None,
+        });
+        assert_eq!(expanded, "2026-03-14 and {{shell \"ls\"}} and {{ unclosed");
+    }
+
+    #[test]
+    fn test_placeholders_are_listed_once_in_order() {
+        let text = "ssh {{placeholder:user}}@{{placeholder:host}}\n# as {{placeholder:user}}";
+        assert_eq!(placeholders(text), vec!["user", "host"]);
+        assert!(placeholders("{{today}}").is_empty());
+    }
+}
@@ -69,6 +69,23 @@ pub enum PadzError {
 
     #[error("Api Error: {0}")]
     Api(String),
+
+    /// A secret fence or the secret key could not be used.
+    #[error("Secret error: {0}")]
+    Secret(String),
+
+    /// An external program did not finish within the time `setting` (a
+    /// config key) allows it; it was killed.
+    #[error("{command} did not finish within {seconds}s; raise `{setting}` to wait longer")]
+    TimedOut {
+        command: String,
+        seconds: u64,
+        setting: String,
+    },
+
+    /// The operation was cancelled (see [`crate::cancel`]) before it finished.
+    #[error("Interrupted")]
+    Interrupted,
 }
 
 /// A non-fatal condition raised while initializing a padz context.
@@ -80,12 +97,23 @@ pub enum PadzError {
 /// surface them to the application.
 #[derive(Debug, Clone, PartialEq, Eq)]
 pub enum InitWarning {
-    /// A legacy flat layout was detected at `path` but could not be migrated
-    /// to the bucketed layout. The store is left as-is and remains readable.
+    /// The store at `path` could not be migrated to the current schema
+    /// (see [`crate::store::schema`]). It is left at the last version it
+    /// reached, and the next open tries again.
     MigrationFailed {
         path: std::path::PathBuf,
         error: String,
     },
+    /// A transaction journal left by an interrupted write could not be
+    /// replayed. The journal stays in place and is retried on the next open.
+    RecoveryFailed { error: String },
+    /// The project store was moved from `from` to `to` (see
+    /// [`crate::store::location`]). Paths recorded on its pads still point at
+    /// the old location until `doctor` remaps them.
+    Relocated {
+        from: std::path::PathBuf,
+        to: std::path::PathBuf,
+    },
 }
 
 impl fmt::Display for InitWarning {
@@ -94,6 +122,76 @@ impl fmt::Display for InitWarning {
             InitWarning::MigrationFailed { path, error } => {
                 write!(f, "migration of {} failed: {}", path.display(), error)
             }
+            InitWarning::RecoveryFailed { error } => {
+                write!(f, "an interrupted write could not be finished: {}", error)
+            }
+            InitWarning::Relocated { from, to } => write!(
+                f,
+                "this project moved from {} to {}; run doctor to update the paths recorded on its pads",
+                from.display(),
+                to.display()
+            ),
+        }
+    }
+}
+
+/// A non-fatal problem met while running a command.
+///
+/// Like [`InitWarning`], but raised by the store mid-command: the command
+/// carries on with what it has (the pads as last indexed, say), and the
+/// warning says why the result may be incomplete. Collected by the store
+/// ([`crate::store::DataStore::take_warnings`]) for the application to
+/// surface, so a stale or partial result is never mistaken for lost data.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum StoreWarning {
+    /// The index of a bucket could not be checked against the pad files, so
+    /// pads added or edited outside padz since the last listing are missing.
+    StaleListing {
+        scope: crate::model::Scope,
+        bucket: crate::store::Bucket,
+        error: String,
+    },
+    /// A listed pad's file path could not be resolved; it is left out of the
+    /// paths the command reports.
+    PathUnavailable { title: String, error: String },
+    /// A pad's file could not be read, so it was left out of a listing run
+    /// with errors ignored.
+    UnreadablePad { title: String, error: String },
+}
+
+impl fmt::Display for StoreWarning {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            StoreWarning::StaleListing {
+                scope,
+                bucket,
+                error,
+            } => {
+                let scope = match scope {
+                    crate::model::Scope::Project => "project",
+                    crate::model::Scope::Global => "global",
+                };
+                let bucket = match bucket {
+                    crate::store::Bucket::Active => "active",
+                    crate::store::Bucket::Archived => "archived",
+                    crate::store::Bucket::Deleted => "deleted",
+                };
+                write!(
+                    f,
+                    "could not check the {} {} pads against their files ({}); showing them as last indexed",
+                    scope, bucket, error
+                )
+            }
+            StoreWarning::PathUnavailable { title, error } => {
+                write!(f, "no file path for \"{}\": {}", title, error)
+            }
+            StoreWarning::UnreadablePad { title, error } => {
+                write!(
+                    f,
+                    "left out \"{}\": its file could not be read ({})",
+                    title, error
+                )
+            }
         }
     }
 }
@@ -23,6 +23,7 @@
 //! - Active/archived/deleted pads sorted by the configured [`OrderingKey`] descending
 //!   (either `created_at` or `updated_at`; newest = 1)
 //! - Pinned pads get an additional `p1`, `p2`... index (appear in both pinned and regular lists)
+//! - Archived pads: Separate bucket `ar1`, `ar2`...
 //! - Deleted pads: Separate bucket `d1`, `d2`...
 //!
 //! ## Pinned Pads Have Two Indexes
@@ -36,9 +37,12 @@
 //! ## Implementation
 //!
 //! - [`index_pads`]: Assigns canonical display indexes to a list of pads
-//! - [`DisplayIndex`]: The user-facing index enum (`Regular`, `Pinned`, `Deleted`)
+//! - [`DisplayIndex`]: The user-facing index enum (`Regular`, `Pinned`, `Archived`, `Deleted`)
+//! - [`INDEX_NAMESPACES`]: The registry of index prefixes (`p`, `ar`, `d`) that
+//!   rendering and parsing share
 //! - [`DisplayPad`]: Connects a `Pad` with its `DisplayIndex`
 //! - [`parse_index_or_range`]: Parses user input like `"1-3"` into `Vec<DisplayIndex>`
+//! - [`pad_uri`]: A pad's `padz://<scope>/<uuid>` URI, which selects it like its UUID
 //!
 //! **Developer Note**: When implementing list/view commands, always use [`index_pads`].
 //! Never manually enumerate a list of pads, as you will break the canonical ID association.
@@ -46,7 +50,7 @@
 //! For input resolution (mapping indexes to UUIDs), see the [`crate::api`] module.
 
 use crate::config::OrderingKey;
-use crate::model::Pad;
+use crate::model::{Pad, Scope};
 use serde::{Deserialize, Serialize};
 use std::cell::Cell;
 use std::collections::HashMap;
@@ -104,17 +108,86 @@ pub enum DisplayIndex {
     Deleted(usize),
 }
 
-impl std::fmt::Display for DisplayIndex {
-    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+impl DisplayIndex {
+    /// The namespace this index numbers within.
+    pub fn namespace(&self) -> &'static IndexNamespace {
+        let kind = match self {
+            DisplayIndex::Pinned(_) => "Pinned",
+            DisplayIndex::Regular(_) => "Regular",
+            DisplayIndex::Archived(_) => "Archived",
+            DisplayIndex::Deleted(_) => "Deleted",
+        };
+        INDEX_NAMESPACES
+            .iter()
+            .find(|ns| ns.kind == kind)
+            .expect("every DisplayIndex variant has a namespace")
+    }
+
+    /// The number within the namespace: `3` for `ar3`.
+    pub fn value(&self) -> usize {
         match self {
-            DisplayIndex::Pinned(i) => write!(f, "p{}", i),
-            DisplayIndex::Regular(i) => write!(f, "{}", i),
-            DisplayIndex::Archived(i) => write!(f, "ar{}", i),
-            DisplayIndex::Deleted(i) => write!(f, "d{}", i),
+            DisplayIndex::Pinned(i)
+            | DisplayIndex::Regular(i)
+            | DisplayIndex::Archived(i)
+            | DisplayIndex::Deleted(i) => *i,
         }
     }
 }
 
+impl std::fmt::Display for DisplayIndex {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        write!(f, "{}{}", self.namespace().prefix, self.value())
+    }
+}
+
+/// One numbering of pads: a pad state with its own sequence (`p1`, `p2`...) and
+/// the prefix that marks it in listings and in user input.
+#[derive(Debug, PartialEq, Eq)]
+pub struct IndexNamespace {
+    /// The [`DisplayIndex`] variant name, as it appears in serialized output
+    /// (`{"type": "Archived", "value": 1}`) and in templates.
+    pub kind: &'static str,
+    /// `""` for the bare regular numbering.
+    pub prefix: &'static str,
+    build: fn(usize) -> DisplayIndex,
+}
+
+/// Every index namespace, in listing order.
+///
+/// This is the one place prefixes are defined: [`DisplayIndex`]'s `Display`
+/// and `FromStr` both read it, so a new state with its own numbering is a new
+/// variant plus an entry here. Prefixes must stay unique, and must not be a
+/// bare number — see `test_index_namespaces_are_well_formed`.
+pub const INDEX_NAMESPACES: &[IndexNamespace] = &[
+    IndexNamespace {
+        kind: "Pinned",
+        prefix: "p",
+        build: DisplayIndex::Pinned,
+    },
+    IndexNamespace {
+        kind: "Regular",
+        prefix: "",
+        build: DisplayIndex::Regular,
+    },
+    IndexNamespace {
+        kind: "Archived",
+        prefix: "ar",
+        build: DisplayIndex::Archived,
+    },
+    IndexNamespace {
+        kind: "Deleted",
+        prefix: "d",
+        build: DisplayIndex::Deleted,
+    },
+];
+
+impl IndexNamespace {
+    /// The index numbered `n` in this namespace.
+    pub fn index(&self, n: usize) -> DisplayIndex {
+        (self.build)(n)
+    }
+}
+
 /// A user input to select a pad, either by its index, UUID, or a search term for its title.
 #[derive(Debug, Clone, PartialEq, Eq)]
 pub enum PadSelector {
@@ -354,35 +427,63 @@ impl std::str::FromStr for DisplayIndex {
     type Err = String;
 
     fn from_str(s: &str) -> Result<Self, Self::Err> {
-        // Check "ar" before single-char prefixes to avoid ambiguity
-        if let Some(rest) = s.strip_prefix("ar") {
-            if let Ok(n) = rest.parse() {
-                return Ok(DisplayIndex::Archived(n));
-            }
-        }
-        if let Some(rest) = s.strip_prefix('p') {
-            if let Ok(n) = rest.parse() {
-                return Ok(DisplayIndex::Pinned(n));
+        // Longest prefix first, so "ar1" is never read as some "a" namespace
+        // and the bare regular numbering is tried last.
+        let mut namespaces: Vec<&IndexNamespace> = INDEX_NAMESPACES.iter().collect();
+        namespaces.sort_by_key(|ns| std::cmp::Reverse(ns.prefix.len()));
+        for ns in namespaces {
+            if let Some(rest) = s.strip_prefix(ns.prefix) {
+                if let Ok(n) = rest.parse() {
+                    return Ok(ns.index(n));
+                }
             }
         }
-        if let Some(rest) = s.strip_prefix('d') {
-            if let Ok(n) = rest.parse() {
-                return Ok(DisplayIndex::Deleted(n));
-            }
-        }
-        if let Ok(n) = s.parse() {
-            return Ok(DisplayIndex::Regular(n));
-        }
         Err(format!("Invalid index format: {}", s))
     }
 }
 
+/// The scheme of a pad's URI, `padz://<scope>/<uuid>`.
+pub const URI_SCHEME: &str = "padz://";
+
+/// A pad's URI: `padz://global/<uuid>` or `padz://project/<uuid>`. Listings
+/// link a pad's line to it, `padz open-uri` opens the pad from another app, and
+/// it selects the pad wherever a UUID would.
+pub fn pad_uri(scope: Scope, id: &Uuid) -> String {
+    let scope = match scope {
+        Scope::Project => "project",
+        Scope::Global => "global",
+    };
+    format!("{}{}/{}", URI_SCHEME, scope, id)
+}
+
+/// Reads a pad URI ([`pad_uri`]) back into its scope and UUID.
+pub fn parse_pad_uri(s: &str) -> Result<(Scope, Uuid), String> {
+    let invalid = || format!("Invalid pad URI: {} (expected padz://<scope>/<uuid>)", s);
+    let rest = s.strip_prefix(URI_SCHEME).ok_or_else(invalid)?;
+    let (scope, id) = rest
+        .trim_end_matches('/')
+        .split_once('/')
+        .ok_or_else(invalid)?;
+    let scope = match scope {
+        "project" => Scope::Project,
+        "global" => Scope::Global,
+        _ => return Err(invalid()),
+    };
+    let id = Uuid::parse_str(id).map_err(|_| invalid())?;
+    Ok((scope, id))
+}
+
 /// Parses a single input string that may be either a path or a range of paths.
 ///
 /// Supports formats:
 /// - Path: "3", "3.1", "p1", "d2.1"
 /// - Range: "1-3", "1.1-1.3", "1.2-2.1"
+/// - UUID: a full UUID, or a pad URI carrying one ([`pad_uri`])
 pub fn parse_index_or_range(s: &str) -> Result<PadSelector, String> {
+    if s.starts_with(URI_SCHEME) {
+        return parse_pad_uri(s).map(|(_, id)| PadSelector::Uuid(id));
+    }
+
     // Try UUID first — UUIDs contain hyphens that would confuse the range parser.
     // Uuid::parse_str is strict and won't match any DI format (1, p1, 1-3, etc.)
     if let Ok(uuid) = Uuid::parse_str(s) {
@@ -513,6 +614,30 @@ mod tests {
             .any(|dp| dp.index == DisplayIndex::Regular(2)));
     }
 
+    #[test]
+    fn test_index_namespaces_are_well_formed() {
+        let mut prefixes = std::collections::HashSet::new();
+        for ns in INDEX_NAMESPACES {
+            assert!(
+                prefixes.insert(ns.prefix),
+                "duplicate prefix {:?}",
+                ns.prefix
+            );
+            assert!(
+                !ns.prefix.chars().any(|c| c.is_ascii_digit()),
+                "prefix {:?} would be read as part of the number",
+                ns.prefix
+            );
+
+            // Kind names the serialized variant, and both directions agree.
+            let index = ns.index(7);
+            assert_eq!(index.namespace(), ns);
+            assert_eq!(serde_json::to_value(&index).unwrap()["type"], ns.kind);
+            assert_eq!(index.to_string(), format!("{}7", ns.prefix));
+            assert_eq!(index.to_string().parse::<DisplayIndex>(), Ok(index));
+        }
+    }
+
     #[test]
     fn test_parsing() {
         use std::str::FromStr;
@@ -833,6 +958,17 @@ mod tests {
         assert_eq!(parse_index_or_range(uuid_str), Ok(PadSelector::Uuid(uuid)));
     }
 
+    #[test]
+    fn a_pad_uri_selects_its_uuid() {
+        let uuid = Uuid::parse_str("550e8400-e29b-41d4-a716-446655440000").unwrap();
+        let uri = pad_uri(Scope::Global, &uuid);
+        assert_eq!(uri, "padz://global/550e8400-e29b-41d4-a716-446655440000");
+        assert_eq!(parse_pad_uri(&uri), Ok((Scope::Global, uuid)));
+        assert_eq!(parse_index_or_range(&uri), Ok(PadSelector::Uuid(uuid)));
+        assert!(parse_pad_uri("padz://groceries").is_err());
+        assert!(parse_pad_uri("padz://work/550e8400-e29b-41d4-a716-446655440000").is_err());
+    }
+
     #[test]
     fn test_parse_uuid_does_not_interfere_with_indexes() {
         // Regular indexes should still parse correctly
@@ -1179,4 +1315,93 @@ mod tests {
         // Child C has newest updated_at, then B, then A.
         assert_eq!(children, vec!["Child C", "Child B", "Child A"]);
     }
+
+    // --- Generated inputs -------------------------------------------------------
+    //
+    // Selectors arrive straight from argv, so the parser must answer every string
+    // with a selector or an error, never a panic. These cases splice together the
+    // fragments index syntax is made of (prefixes, separators, numbers at and past
+    // `usize`, hex, multi-byte chars) with a fixed-seed xorshift, so a failing
+    // input fails on every run and can be copied into a named test.
+
+    const FRAGMENTS: &[&str] = &[
+        "",
+        "0",
+        "1",
+        "3",
+        "42",
+        "18446744073709551615",
+        "18446744073709551616",
+        "p",
+        "d",
+        "ar",
+        "a",
+        "f",
+        "-",
+        ".",
+        "..",
+        " ",
+        "+",
+        "x",
+        "é",
+        "⏲",
+        "\0",
+        "1-3",
+        "p1",
+        "ar2",
+        "d1.3",
+        "766d5dab",
+        "550e8400-e29b-41d4-a716-446655440000",
+    ];
+
+    fn generated_inputs(cases: usize) -> Vec<String> {
+        let mut state: u64 = 0x9e37_79b9_7f4a_7c15;
+        let mut next = move || {
+            state ^= state << 13;
+            state ^= state >> 7;
+            state ^= state << 17;
+            state as usize
+        };
+        (0..cases)
+            .map(|_| {
+                let len = next() % 6;
+                (0..len)
+                    .map(|_| FRAGMENTS[next() % FRAGMENTS.len()])
+                    .collect()
+            })
+            .collect()
+    }
+
+    #[test]
+    fn test_generated_inputs_parse_to_a_selector_that_survives_display() {
+        for input in generated_inputs(20_000) {
+            let Ok(selector) = parse_index_or_range(&input) else {
+                continue;
+            };
+            // What padz prints for a selector must select the same pads when
+            // typed back in.
+            assert_eq!(
+                parse_index_or_range(&selector.to_string()),
+                Ok(selector.clone()),
+                "{input:?} parsed to {selector:?}, whose display does not round-trip"
+            );
+            assert!(
+                !matches!(selector, PadSelector::Title(_)),
+                "{input:?}: titles are chosen by parse_selectors, not here"
+            );
+        }
+    }
+
+    #[test]
+    fn test_generated_inputs_only_name_display_indexes_in_index_syntax() {
+        for input in generated_inputs(20_000) {
+            if let Ok(index) = input.parse::<DisplayIndex>() {
+                assert!(
+                    !input.contains(['.', '-', ' ']),
+                    "{input:?} parsed as the single index {index:?}"
+                );
+                assert_eq!(index.to_string().parse::<DisplayIndex>(), Ok(index));
+            }
+        }
+    }
 }
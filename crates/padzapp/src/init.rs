@@ -81,9 +81,10 @@ use crate::config::PadzConfig;
 use crate::error::{InitWarning, PadzError};
 use crate::model::Scope;
 use crate::store::fs::FileStore;
+use crate::store::schema::{self, Upgrade};
+use crate::store::{location, registry};
 use clapfig::{Clapfig, SearchMode, SearchPath};
 use std::path::{Path, PathBuf};
-use uuid::Uuid;
 
 /// The environment inputs initialization depends on, resolved by the caller.
 ///
@@ -107,8 +108,8 @@ pub struct PadzContext {
     pub api: PadzApi<FileStore>,
     pub scope: Scope,
     pub config: PadzConfig,
-    /// Non-fatal conditions raised during initialization (e.g. a legacy layout
-    /// that could not be migrated). Initialization succeeded regardless; it is
+    /// Non-fatal conditions raised during initialization (e.g. a store that
+    /// could not be migrated to the current schema). Initialization succeeded regardless; it is
     /// the application's call whether and how to show these to the user.
     pub warnings: Vec<InitWarning>,
 }
@@ -124,6 +125,24 @@ pub fn create_bucket_layout(padz_dir: &Path) -> std::io::Result<()> {
     Ok(())
 }
 
+/// First-run setup of the global store, for the application to call before
+/// [`initialize`]. When `global_data_dir` shows no sign of padz having run (no
+/// bucket layout, no legacy flat index, no scope registry), it creates the
+/// bucket layout and an empty registry and returns `true`, so the caller can
+/// welcome the user. Otherwise it writes nothing and returns `false`: safe to
+/// call on every invocation.
+pub fn bootstrap(global_data_dir: &Path) -> crate::error::Result<bool> {
+    let used_before = global_data_dir.join("active").is_dir()
+        || global_data_dir.join("data.json").is_file()
+        || global_data_dir.join(registry::REGISTRY_FILE).is_file();
+    if used_before {
+        return Ok(false);
+    }
+    create_bucket_layout(global_data_dir)?;
+    registry::save(global_data_dir, &[])?;
+    Ok(true)
+}
+
 /// Walk upward from `cwd` looking for a directory that contains a `.padz/`
 /// subdirectory.
 ///
@@ -267,10 +286,11 @@ pub fn resolve_link(padz_dir: &Path) -> crate::error::Result<Option<PathBuf>> {
 ///
 /// # Warnings
 ///
-/// Best-effort work that fails without stopping the command (currently only
-/// legacy-layout migration) is reported as [`InitWarning`] values on the
-/// returned [`PadzContext::warnings`]. This function writes nothing to stderr;
-/// surfacing warnings is the caller's decision.
+/// Best-effort work that fails without stopping the command (schema
+/// migration, journal recovery) is reported as [`InitWarning`] values on the
+/// returned [`PadzContext::warnings`], as is a project store that moved since
+/// it last recorded its location ([`InitWarning::Relocated`]). This function
+/// writes nothing to stderr; surfacing warnings is the caller's decision.
 ///
 /// # Errors
 ///
@@ -284,6 +304,9 @@ pub fn resolve_link(padz_dir: &Path) -> crate::error::Result<Option<PathBuf>> {
 ///   to Global would drop the new pad somewhere the user almost certainly
 ///   did not mean; making the caller abort is the safer default.
 ///
+/// It also returns `Err` when the project or global store was written by a
+/// newer padz, at a schema version this build does not know.
+///
 /// All other paths (no `.padz` upward on a read, no `.git` on a write, etc.)
 /// fall back to `Scope::Global` successfully.
 ///
@@ -318,67 +341,36 @@ pub fn initialize(
     let global_data_dir = env.global_data_dir.clone();
     let home_dir = env.home_dir.as_deref();
 
-    // Determine project data directory and scope:
-    // 1. If use_global → Global scope, no project dir
-    // 2. If data_override provided → Project scope with explicit path
-    // 3. find_padz_root found something → Project scope (follow link if present,
-    //    propagate link-resolution errors rather than silently using the local path)
-    // 4. Else if auto_init_for_write and find_git_root found something → create
-    //    .padz at that git root and use it (Project scope), propagating bucket-
-    //    creation errors rather than silently dropping the pad into global
-    // 5. Else → fall back to Global scope
-    let (project_padz_dir, scope) = if use_global {
-        (None, Scope::Global)
-    } else {
-        match data_override {
-            Some(path) => {
-                let dir = if path.file_name().is_some_and(|name| name == ".padz") {
-                    path
-                } else {
-                    path.join(".padz")
-                };
-                (Some(dir), Scope::Project)
-            }
-            None => match find_padz_root(cwd, home_dir) {
-                Some(root) => {
-                    let detected = root.join(".padz");
-                    // Follow .padz/link if present. A broken/uninitialized/
-                    // chained link is the user's declared intent going wrong;
-                    // bubble the error up so it is visible instead of silently
-                    // operating on the local (unlinked) directory, which might
-                    // be a different store.
-                    let resolved = match resolve_link(&detected)? {
-                        Some(linked) => linked,
-                        None => detected,
-                    };
-                    (Some(resolved), Scope::Project)
-                }
-                None if auto_init_for_write => {
-                    // Write path: try to auto-init a project store at the
-                    // enclosing git root. If no git root is found, fall back
-                    // to Global (the user isn't clearly inside a project). If
-                    // a git root IS found but layout creation fails, surface
-                    // the error — the write would otherwise silently land in
-                    // Global despite the user sitting in a git repo.
-                    match find_git_root(cwd, home_dir) {
-                        Some(git_root) => {
-                            let new_padz = git_root.join(".padz");
-                            create_bucket_layout(&new_padz).map_err(|err| {
-                                PadzError::Store(format!(
-                                    "could not auto-init padz store at {}: {}. \
-                                     Run `padz init` there (or `-g` to force global) to proceed.",
-                                    new_padz.display(),
-                                    err
-                                ))
-                            })?;
-                            (Some(new_padz), Scope::Project)
-                        }
-                        None => (None, Scope::Global),
-                    }
+    // Determine project data directory and scope: an existing project store
+    // (see [`locate_project_store`]), else, on the write path, a fresh one at
+    // the enclosing git root, else Global.
+    let located = locate_project_store(env, cwd, use_global, data_override)?;
+    let (project_padz_dir, scope) = match located {
+        Some(dir) => (Some(dir), Scope::Project),
+        None if auto_init_for_write && !use_global => {
+            // Write path: try to auto-init a project store at the enclosing
+            // git root. If no git root is found, fall back to Global (the
+            // user isn't clearly inside a project). If a git root IS found but
+            // layout creation fails, surface the error — the write would
+            // otherwise silently land in Global despite the user sitting in a
+            // git repo.
+            match find_git_root(cwd, home_dir) {
+                Some(git_root) => {
+                    let new_padz = git_root.join(".padz");
+                    create_bucket_layout(&new_padz).map_err(|err| {
+                        PadzError::Store(format!(
+                            "could not auto-init padz store at {}: {}. \
+                             Run `padz init` there (or `-g` to force global) to proceed.",
+                            new_padz.display(),
+                            err
+                        ))
+                    })?;
+                    (Some(new_padz), Scope::Project)
                 }
                 None => (None, Scope::Global),
-            },
+            }
         }
+        None => (None, Scope::Global),
     };
 
     // Config search paths depend on scope:
@@ -406,12 +398,32 @@ pub fn initialize(
     // decides how to tell the user.
     let mut warnings = Vec::new();
     if let Some(ref project_dir) = project_padz_dir {
-        warnings.extend(migrate_if_needed(project_dir));
+        warnings.extend(migrate_if_needed(project_dir)?);
+        // Noticing a moved project is advisory; an unreadable location file
+        // must not stop the command.
+        if let Ok(Some(moved)) = location::check(project_dir) {
+            warnings.push(InitWarning::Relocated {
+                from: moved.from,
+                to: moved.to,
+            });
+        }
+        // The registry is bookkeeping for `scopes prune`; failing to update
+        // it is not worth a warning.
+        let _ = registry::register(&global_data_dir, project_dir);
+    }
+    warnings.extend(migrate_if_needed(&global_data_dir)?);
+
+    let mut store = FileStore::new_fs(project_padz_dir.clone(), global_data_dir.clone())
+        .with_format(&format_ext)
+        .with_compression(config.compress_above)
+        .with_encryption(crate::secrets::key_path(&global_data_dir));
+    // Finish any transaction a crash interrupted before anything reads the
+    // store; like migration, a failure is reported rather than fatal.
+    if let Err(e) = store.recover() {
+        warnings.push(InitWarning::RecoveryFailed {
+            error: e.to_string(),
+        });
     }
-    warnings.extend(migrate_if_needed(&global_data_dir));
-
-    let store = FileStore::new_fs(project_padz_dir.clone(), global_data_dir.clone())
-        .with_format(&format_ext);
     let paths = PadzPaths {
         project: project_padz_dir,
         global: global_data_dir,
@@ -427,149 +439,68 @@ pub fn initialize(
     })
 }
 
-/// Migrates a legacy flat `.padz/` layout to the bucketed layout.
-///
-/// Legacy layout:
-/// ```text
-/// .padz/
-///   data.json        # All pads (active + deleted via is_deleted flag)
-///   pad-{uuid}.txt   # Content files
-///   tags.json        # Tags (stays in place)
-/// ```
-///
-/// Bucketed layout:
-/// ```text
-/// .padz/
-///   tags.json        # Scope-level (shared)
-///   active/
-///     data.json      # Active pad metadata
-///     pad-{uuid}.txt
-///   archived/        # (empty after migration)
-///     data.json
-///   deleted/
-///     data.json      # Deleted pad metadata
-///     pad-{uuid}.txt
-/// ```
+/// The project store `cwd` resolves to, or `None` for Global scope.
 ///
-/// Detection: `data.json` exists at root AND `active/` does NOT exist.
-/// Idempotent: only runs if legacy layout detected.
+/// The lookup half of [`initialize`], without its side effects: nothing is
+/// created, migrated, recovered or registered, and no config is loaded. For
+/// callers that only read, and must be quick about it (`padz prompt-segment`).
 ///
-/// Best-effort: a failure is returned as an [`InitWarning`] for the caller to
-/// surface, not printed, and never aborts initialization — the legacy store is
-/// left untouched and stays readable.
-fn migrate_if_needed(scope_root: &Path) -> Option<InitWarning> {
-    let legacy_data = scope_root.join("data.json");
-    let active_dir = scope_root.join("active");
-
-    // Only migrate if legacy data.json exists and active/ doesn't
-    if !legacy_data.exists() || active_dir.exists() {
-        return None;
+/// 1. `use_global` → Global.
+/// 2. `data_override` → that store (`.padz` is appended unless the path ends
+///    in it).
+/// 3. A `.padz` found upward from `cwd` → that store, following its link if
+///    it has one. A broken, uninitialized or chained link is the user's
+///    declared intent going wrong, so it is an error rather than a silent
+///    fallback to the local (unlinked) directory, which might be a different
+///    store.
+/// 4. Otherwise → Global.
+pub fn locate_project_store(
+    env: &PadzEnv,
+    cwd: &Path,
+    use_global: bool,
+    data_override: Option<PathBuf>,
+) -> crate::error::Result<Option<PathBuf>> {
+    if use_global {
+        return Ok(None);
     }
-
-    migrate_flat_to_bucketed(scope_root)
-        .err()
-        .map(|e| InitWarning::MigrationFailed {
-            path: scope_root.to_path_buf(),
-            error: e.to_string(),
-        })
-}
-
-fn migrate_flat_to_bucketed(scope_root: &Path) -> std::io::Result<()> {
-    use std::collections::HashMap;
-    use std::fs;
-
-    let legacy_data_path = scope_root.join("data.json");
-    let content = fs::read_to_string(&legacy_data_path)?;
-
-    let entries: HashMap<Uuid, serde_json::Value> = serde_json::from_str(&content)
-        .map_err(|e| std::io::Error::new(std::io::ErrorKind::InvalidData, e))?;
-
-    // Partition entries by is_deleted flag
-    let mut active_entries: HashMap<Uuid, serde_json::Value> = HashMap::new();
-    let mut deleted_entries: HashMap<Uuid, serde_json::Value> = HashMap::new();
-
-    for (id, mut value) in entries {
-        let is_deleted = value
-            .get("is_deleted")
-            .and_then(|v| v.as_bool())
-            .unwrap_or(false);
-
-        // Strip legacy fields
-        if let Some(obj) = value.as_object_mut() {
-            obj.remove("is_deleted");
-            obj.remove("deleted_at");
-        }
-
-        if is_deleted {
-            deleted_entries.insert(id, value);
+    if let Some(path) = data_override {
+        let dir = if path.file_name().is_some_and(|name| name == ".padz") {
+            path
         } else {
-            active_entries.insert(id, value);
-        }
-    }
-
-    // Create bucket directories
-    let active_dir = scope_root.join("active");
-    let archived_dir = scope_root.join("archived");
-    let deleted_dir = scope_root.join("deleted");
-    fs::create_dir_all(&active_dir)?;
-    fs::create_dir_all(&archived_dir)?;
-    fs::create_dir_all(&deleted_dir)?;
-
-    // Write bucket data.json files
-    let active_json =
-        serde_json::to_string_pretty(&active_entries).map_err(std::io::Error::other)?;
-    fs::write(active_dir.join("data.json"), active_json)?;
-
-    let deleted_json =
-        serde_json::to_string_pretty(&deleted_entries).map_err(std::io::Error::other)?;
-    fs::write(deleted_dir.join("data.json"), deleted_json)?;
-
-    // Write empty archived data.json
-    fs::write(archived_dir.join("data.json"), "{}")?;
-
-    // Move content files to their respective bucket directories
-    let all_active_ids: std::collections::HashSet<Uuid> = active_entries.keys().copied().collect();
-    let all_deleted_ids: std::collections::HashSet<Uuid> =
-        deleted_entries.keys().copied().collect();
-
-    let dir_entries = fs::read_dir(scope_root)?;
-    for entry in dir_entries {
-        let entry = entry?;
-        let path = entry.path();
-
-        if !path.is_file() {
-            continue;
-        }
-        let Some(name) = path.file_name().and_then(|s| s.to_str()) else {
-            continue;
+            path.join(".padz")
         };
-        if !name.starts_with("pad-") {
-            continue;
+        return Ok(Some(dir));
+    }
+    match find_padz_root(cwd, env.home_dir.as_deref()) {
+        Some(root) => {
+            let detected = root.join(".padz");
+            Ok(Some(resolve_link(&detected)?.unwrap_or(detected)))
         }
-
-        // Extract UUID from filename: pad-{uuid}.ext
-        let stem = path.file_stem().and_then(|s| s.to_str()).unwrap_or("");
-        let uuid_part = stem.strip_prefix("pad-").unwrap_or("");
-        let Ok(id) = Uuid::parse_str(uuid_part) else {
-            continue;
-        };
-
-        let dest_dir = if all_deleted_ids.contains(&id) {
-            &deleted_dir
-        } else if all_active_ids.contains(&id) {
-            &active_dir
-        } else {
-            // Orphan file — move to active (doctor will handle it)
-            &active_dir
-        };
-
-        fs::rename(&path, dest_dir.join(name))?;
+        None => Ok(None),
     }
+}
 
-    // Remove legacy data.json
-    fs::remove_file(&legacy_data_path)?;
-
-    Ok(())
+/// Brings a scope's store up to the current schema (see [`crate::store::schema`]).
+///
+/// Best-effort: a failure is returned as an [`InitWarning`] for the caller to
+/// surface, not printed, and never aborts initialization — a failed step
+/// leaves the store at the last version it reached, still readable. A store
+/// written by a newer padz is the exception: this build may misread it, so
+/// it is refused.
+fn migrate_if_needed(scope_root: &Path) -> crate::error::Result<Option<InitWarning>> {
+    match schema::upgrade(scope_root) {
+        Ok(Upgrade::Newer { found }) => Err(PadzError::Store(format!(
+            "{} uses store schema {}, but this padz only understands up to {}; upgrade padz",
+            scope_root.display(),
+            found,
+            schema::CURRENT_VERSION
+        ))),
+        Ok(_) => Ok(None),
+        Err(e) => Ok(Some(InitWarning::MigrationFailed {
+            path: scope_root.to_path_buf(),
+            error: e.to_string(),
+        })),
+    }
 }
 
 #[cfg(test)]
@@ -577,6 +508,7 @@ mod tests {
     use super::*;
     use std::fs;
     use tempfile::TempDir;
+    use uuid::Uuid;
 
     /// A [`PadzEnv`] for tests.
     ///
@@ -601,6 +533,24 @@ mod tests {
 
     // --- find_padz_root tests ---
 
+    #[test]
+    fn bootstrap_sets_up_a_new_global_store_once() {
+        let temp = TempDir::new().unwrap();
+        let global = temp.path().join("global");
+
+        assert!(bootstrap(&global).unwrap());
+        assert!(global.join("active").is_dir());
+        assert!(registry::load(&global).unwrap().is_empty());
+        assert!(!bootstrap(&global).unwrap());
+
+        // A store from before the bucket layout is not a first run.
+        let legacy = temp.path().join("legacy");
+        fs::create_dir_all(&legacy).unwrap();
+        fs::write(legacy.join("data.json"), "{}").unwrap();
+        assert!(!bootstrap(&legacy).unwrap());
+        assert!(!legacy.join("active").exists());
+    }
+
     #[test]
     fn test_find_padz_root_at_cwd() {
         // `.padz` in the current directory is found immediately, no `.git` needed.
@@ -958,6 +908,25 @@ mod tests {
         assert_eq!(ctx.scope, Scope::Project);
     }
 
+    #[test]
+    fn test_locate_project_store_has_no_side_effects() {
+        // The lookup finds the same store `initialize` would, but leaves the
+        // global dir (registry, migrations) untouched.
+        let temp = TempDir::new().unwrap();
+        let project = temp.path().join("project");
+        fs::create_dir_all(project.join(".padz").join("active")).unwrap();
+        let env = test_env();
+
+        let located = locate_project_store(&env, &project, false, None).unwrap();
+
+        assert_eq!(located, Some(project.join(".padz")));
+        assert!(!env.global_data_dir.exists());
+        assert_eq!(
+            locate_project_store(&env, &project, true, None).unwrap(),
+            None
+        );
+    }
+
     // --- Auto-init-on-write discovery ---
 
     #[test]
@@ -1074,7 +1043,7 @@ mod tests {
         fs::write(root.join("tags.json"), "[]").unwrap();
 
         // Run migration
-        migrate_if_needed(&root);
+        migrate_if_needed(&root).unwrap();
 
         // Verify: legacy data.json removed
         assert!(!root.join("data.json").exists());
@@ -1128,7 +1097,7 @@ mod tests {
         // Create a data.json at root (should NOT trigger migration since active/ exists)
         fs::write(root.join("data.json"), "{}").unwrap();
 
-        migrate_if_needed(&root);
+        migrate_if_needed(&root).unwrap();
 
         // data.json should still exist (migration was skipped)
         assert!(root.join("data.json").exists());
@@ -1141,7 +1110,7 @@ mod tests {
         fs::create_dir_all(&root).unwrap();
 
         // No data.json — nothing to migrate
-        migrate_if_needed(&root);
+        migrate_if_needed(&root).unwrap();
 
         // No bucket directories should be created
         assert!(!root.join("active").exists());
@@ -1157,12 +1126,15 @@ mod tests {
         // Unparseable legacy data: detected as a legacy layout, fails to migrate.
         fs::write(root.join("data.json"), "{ not json").unwrap();
 
-        let warning = migrate_if_needed(&root).expect("expected a migration warning");
+        let warning = migrate_if_needed(&root)
+            .unwrap()
+            .expect("expected a migration warning");
         match &warning {
             InitWarning::MigrationFailed { path, error } => {
                 assert_eq!(path, &root);
                 assert!(!error.is_empty(), "warning should say what went wrong");
             }
+            other => panic!("expected MigrationFailed, got {other:?}"),
         }
         // Rendered for humans by the CLI, and it names the store.
         assert!(
@@ -1181,10 +1153,26 @@ mod tests {
         fs::create_dir_all(&root).unwrap();
         fs::write(root.join("data.json"), "{}").unwrap();
 
-        assert!(migrate_if_needed(&root).is_none());
+        assert!(migrate_if_needed(&root).unwrap().is_none());
         assert!(root.join("active").exists());
     }
 
+    /// A store from a newer padz is refused, not opened and misread.
+    #[test]
+    fn test_a_newer_schema_is_refused() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path().join(".padz");
+        fs::create_dir_all(root.join("active")).unwrap();
+        schema::write_version(&root, schema::CURRENT_VERSION + 1).unwrap();
+
+        let err = migrate_if_needed(&root).unwrap_err();
+        assert!(err.to_string().contains("upgrade padz"), "got: {err}");
+        assert_eq!(
+            schema::detect_version(&root).unwrap(),
+            Some(schema::CURRENT_VERSION + 1)
+        );
+    }
+
     /// `initialize` surfaces migration warnings on the context rather than
     /// printing them, so the caller decides whether the user hears about it.
     #[test]
@@ -1336,6 +1324,27 @@ mod tests {
         assert!(ctx.warnings.is_empty(), "got: {:?}", ctx.warnings);
     }
 
+    #[test]
+    fn test_initialize_reports_a_moved_project() {
+        let temp = TempDir::new().unwrap();
+        let project = temp.path().join("proj");
+        fs::create_dir_all(project.join(".padz").join("active")).unwrap();
+        initialize(&test_env(), &project, false, None, false).unwrap();
+
+        let moved = temp.path().join("renamed");
+        fs::rename(&project, &moved).unwrap();
+        let ctx = initialize(&test_env(), &moved, false, None, false).unwrap();
+
+        let base = fs::canonicalize(temp.path()).unwrap();
+        assert_eq!(
+            ctx.warnings,
+            vec![InitWarning::Relocated {
+                from: base.join("proj"),
+                to: base.join("renamed"),
+            }]
+        );
+    }
+
     #[test]
     fn test_migration_all_active() {
         let temp = TempDir::new().unwrap();
@@ -1360,7 +1369,7 @@ mod tests {
         .unwrap();
         fs::write(root.join(format!("pad-{}.txt", id)), "Content").unwrap();
 
-        migrate_if_needed(&root);
+        migrate_if_needed(&root).unwrap();
 
         // All pads should be in active
         let active_data: std::collections::HashMap<Uuid, serde_json::Value> =
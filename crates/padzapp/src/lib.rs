@@ -101,6 +101,14 @@
 //! - [`config`]: Configuration management
 //! - [`editor`]: The editor *buffer format* — parsing and rendering only
 //! - [`error`]: Error types
+//! - [`usage`]: Opt-in, local-only command usage counts
+//! - [`update`]: Release version comparison, checksums and the update-check schedule
+//! - [`queue`]: Failed network operations kept for a retry
+//! - [`progress`]: How long operations report their progress to the client
+//! - [`cancel`]: Stopping long operations cleanly when the client asks
+//! - [`secrets`]: Encryption at rest for `secret` fences in pad bodies
+//! - [`spell`]: Dictionary-based spell checking of pad text
+//! - [`directives`]: `{{today}}`-style directives and `{{placeholder:name}}` fill-ins in pad bodies
 //!
 //! # What lives outside this library
 //!
@@ -122,17 +130,25 @@
 
 pub mod api;
 pub mod attributes;
+pub mod cancel;
 pub mod commands;
 pub mod config;
+pub mod directives;
 pub mod editor;
 pub mod error;
 pub mod index;
 pub mod init;
 pub mod model;
 pub mod peek;
+pub mod progress;
+pub mod queue;
+pub mod secrets;
+pub mod spell;
 pub mod store;
 pub mod tags;
 pub mod todos;
+pub mod update;
+pub mod usage;
 
 #[cfg(test)]
 pub mod test_utils;
@@ -70,7 +70,136 @@ pub enum TodoStatus {
     Done,
 }
 
-#[derive(Debug, Clone, Serialize)]
+/// A place in the code a pad is about: `src/server.go:42`, or a whole file.
+///
+/// The path is kept as the client recorded it; padz never resolves or checks it
+/// (see [`crate::commands::anchors`]).
+#[derive(Debug, Clone, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub struct CodeAnchor {
+    pub path: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub line: Option<u32>,
+}
+
+impl std::fmt::Display for CodeAnchor {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self.line {
+            Some(line) => write!(f, "{}:{}", self.path, line),
+            None => write!(f, "{}", self.path),
+        }
+    }
+}
+
+impl std::str::FromStr for CodeAnchor {
+    type Err = String;
+
+    /// Parses `path:line` or a bare `path`. A trailing `:N` is only a line
+    /// number when `N` is all digits, so paths containing colons still parse.
+    fn from_str(s: &str) -> Result<Self, Self::Err> {
+        let s = s.trim();
+        let (path, line) = match s.rsplit_once(':') {
+            Some((path, line)) if !line.is_empty() && line.bytes().all(|b| b.is_ascii_digit()) => {
+                let line: u32 = line
+                    .parse()
+                    .map_err(|_| format!("Line number out of range in '{}'", s))?;
+                if line == 0 {
+                    return Err(format!("Line numbers start at 1 in '{}'", s));
+                }
+                (path, Some(line))
+            }
+            _ => (s, None),
+        };
+        if path.is_empty() {
+            return Err(format!("No file path in '{}'", s));
+        }
+        Ok(CodeAnchor {
+            path: path.to_string(),
+            line,
+        })
+    }
+}
+
+/// The state of the working tree a pad was created in, when the client
+/// captured it (see `capture_context` in [`crate::config`]). Lets a note be
+/// read against the exact code it was written about.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct CreationContext {
+    /// Directory the pad was created from.
+    pub cwd: String,
+    /// Full SHA of the checked-out commit.
+    pub commit: String,
+    /// Current branch; `None` on a detached HEAD.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub branch: Option<String>,
+    /// Whether the working tree had uncommitted changes.
+    #[serde(default)]
+    pub dirty: bool,
+}
+
+/// A service that hosts published pads (see [`crate::commands::publish`]).
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+#[serde(rename_all = "snake_case")]
+pub enum PublishService {
+    /// A GitHub gist.
+    Gist,
+    /// A GitLab snippet, on gitlab.com or a self-hosted instance.
+    GitlabSnippet,
+}
+
+impl std::fmt::Display for PublishService {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self {
+            PublishService::Gist => write!(f, "gist"),
+            PublishService::GitlabSnippet => write!(f, "GitLab snippet"),
+        }
+    }
+}
+
+/// Where a pad was published, and which version of it is up there.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct Publication {
+    pub service: PublishService,
+    /// The service's id for the upload, which updates are sent to.
+    pub id: String,
+    /// Where the published pad is read.
+    pub url: String,
+    /// The file the pad was uploaded as; an update renames it after the title.
+    pub filename: String,
+    /// The pad's `updated_at` when it was uploaded. An edit since makes the
+    /// published copy stale.
+    pub revision: DateTime<Utc>,
+}
+
+/// Something that happened to a pad, as recorded in its history (see
+/// [`crate::store::history`]).
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(tag = "event", rename_all = "snake_case")]
+pub enum PadEventKind {
+    /// Never stored: timelines derive it from `created_at`.
+    Created,
+    Edited,
+    Pinned,
+    Unpinned,
+    Status {
+        status: TodoStatus,
+    },
+    /// Re-parented, or moved to the root.
+    Moved,
+    Archived,
+    Unarchived,
+    Deleted,
+    Restored,
+}
+
+/// One entry of a pad's history.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct PadEvent {
+    pub at: DateTime<Utc>,
+    #[serde(flatten)]
+    pub kind: PadEventKind,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize)]
 pub struct Metadata {
     pub id: Uuid,
     pub created_at: DateTime<Utc>,
@@ -86,6 +215,41 @@ pub struct Metadata {
     /// Tags assigned to this pad (references tag names from the tag registry)
     #[serde(default)]
     pub tags: Vec<String>,
+    /// When the pad was last viewed, opened or peeked at. Unlike `updated_at`,
+    /// reading a pad moves this forward without touching its content file.
+    #[serde(default)]
+    pub last_accessed_at: Option<DateTime<Utc>>,
+    /// Checksum of the content file as padz last wrote it (see
+    /// [`crate::store::integrity`]). `None` for pads written before checksums
+    /// were recorded, until their next write.
+    #[serde(default)]
+    pub checksum: Option<String>,
+    /// A user-chosen name that selects this pad like an index does (see
+    /// [`crate::commands::naming`]). Unique within the pad's scope.
+    #[serde(default)]
+    pub alias: Option<String>,
+    /// Code locations this pad refers to, in the order they were added.
+    #[serde(default)]
+    pub anchors: Vec<CodeAnchor>,
+    /// Where the pad was created, if the client recorded it. Set once, at
+    /// creation; later edits leave it alone.
+    #[serde(default)]
+    pub context: Option<CreationContext>,
+    /// Who created the pad, if the client said (see
+    /// [`crate::commands::ownership`]). Set once, at creation.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub owner: Option<String>,
+    /// The global pad this project pad stands in for, when it is a reference
+    /// (see [`crate::commands::refs`]).
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub source: Option<Uuid>,
+    /// Where the pad was published, one entry per service.
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub published: Vec<Publication>,
+    /// What happened to the pad since it was created, oldest first. Kept by
+    /// the store, not by commands; capped at [`crate::store::history::HISTORY_LIMIT`].
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub history: Vec<PadEvent>,
 }
 
 // Custom deserializer to handle legacy data where `delete_protected` is missing.
@@ -110,6 +274,15 @@ impl<'de> Deserialize<'de> for Metadata {
             title: helper.title,
             status: helper.status.unwrap_or(TodoStatus::Planned),
             tags: helper.tags,
+            last_accessed_at: helper.last_accessed_at,
+            checksum: helper.checksum,
+            alias: helper.alias,
+            anchors: helper.anchors,
+            context: helper.context,
+            owner: helper.owner,
+            source: helper.source,
+            published: helper.published,
+            history: helper.history,
         })
     }
 }
@@ -130,6 +303,24 @@ struct MetadataHelper {
     status: Option<TodoStatus>,
     #[serde(default)]
     tags: Vec<String>,
+    #[serde(default)]
+    last_accessed_at: Option<DateTime<Utc>>,
+    #[serde(default)]
+    checksum: Option<String>,
+    #[serde(default)]
+    alias: Option<String>,
+    #[serde(default)]
+    anchors: Vec<CodeAnchor>,
+    #[serde(default)]
+    context: Option<CreationContext>,
+    #[serde(default)]
+    owner: Option<String>,
+    #[serde(default)]
+    source: Option<Uuid>,
+    #[serde(default)]
+    published: Vec<Publication>,
+    #[serde(default)]
+    history: Vec<PadEvent>,
 }
 
 impl Metadata {
@@ -146,6 +337,15 @@ impl Metadata {
             title,
             status: TodoStatus::Planned,
             tags: Vec::new(),
+            last_accessed_at: None,
+            checksum: None,
+            alias: None,
+            anchors: Vec::new(),
+            context: None,
+            owner: None,
+            source: None,
+            published: Vec::new(),
+            history: Vec::new(),
         }
     }
 
@@ -564,6 +764,26 @@ pub fn parse_pad_content(raw: &str) -> Option<(String, String)> {
 mod tests {
     use super::*;
 
+    #[test]
+    fn test_code_anchor_parse_and_display() {
+        let anchor: CodeAnchor = "src/server.go:42".parse().unwrap();
+        assert_eq!(anchor.path, "src/server.go");
+        assert_eq!(anchor.line, Some(42));
+        assert_eq!(anchor.to_string(), "src/server.go:42");
+
+        let whole: CodeAnchor = "README.md".parse().unwrap();
+        assert_eq!(whole.line, None);
+        assert_eq!(whole.to_string(), "README.md");
+
+        // A non-numeric suffix is part of the path.
+        let odd: CodeAnchor = "notes:draft".parse().unwrap();
+        assert_eq!((odd.path.as_str(), odd.line), ("notes:draft", None));
+
+        for bad in ["", ":12", "main.rs:0", "main.rs:99999999999"] {
+            assert!(bad.parse::<CodeAnchor>().is_err(), "{bad:?}");
+        }
+    }
+
     #[test]
     fn test_normalize_simple() {
         let (title, content) = normalize_pad_content("My Title", "My Content");
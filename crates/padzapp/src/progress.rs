@@ -0,0 +1,96 @@
+//! # Progress
+//!
+//! Operations that walk every pad in a store — import, export, clone and
+//! migrate, syncing the global store — can take long enough on a large store
+//! to look hung. They report their progress through [`Progress`], one call per
+//! item; how (and how often) to show it is the client's business, since this
+//! library never writes to the terminal.
+//!
+//! The API holds one reporter for every operation it runs
+//! ([`crate::api::PadzApi::set_progress`]); [`NoProgress`], the default,
+//! ignores it all.
+
+/// Receives progress from long-running operations.
+///
+/// Methods take `&self` so one reporter can be shared by the API and whatever
+/// else the client runs; implementations keep their state in a `Cell` or
+/// `RefCell`.
+pub trait Progress {
+    /// `done` of `total` items of `task` (a short verb: "Exporting") are
+    /// complete. Called after every item, so implementations should throttle
+    /// what they show.
+    fn update(&self, task: &str, done: usize, total: usize);
+
+    /// `task` is over, whether or not every item went through.
+    fn finish(&self, _task: &str) {}
+}
+
+/// A reporter that shows nothing.
+#[derive(Debug, Clone, Copy, Default)]
+pub struct NoProgress;
+
+impl Progress for NoProgress {
+    fn update(&self, _task: &str, _done: usize, _total: usize) {}
+}
+
+/// Reports each step of iterating `items` as `task`, finishing when the
+/// iterator is exhausted.
+pub fn track<'a, I>(
+    progress: &'a dyn Progress,
+    task: &'a str,
+    items: I,
+) -> impl Iterator<Item = I::Item> + 'a
+where
+    I: ExactSizeIterator + 'a,
+{
+    let total = items.len();
+    items
+        .enumerate()
+        .map(move |(n, item)| {
+            // Reported before the item is handled: a step that hangs shows
+            // where it hangs.
+            progress.update(task, n, total);
+            item
+        })
+        .chain(std::iter::from_fn(move || {
+            progress.update(task, total, total);
+            progress.finish(task);
+            None
+        }))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::cell::RefCell;
+
+    #[derive(Default)]
+    struct Recorder(RefCell<Vec<String>>);
+
+    impl Progress for Recorder {
+        fn update(&self, task: &str, done: usize, total: usize) {
+            self.0.borrow_mut().push(format!("{task} {done}/{total}"));
+        }
+
+        fn finish(&self, task: &str) {
+            self.0.borrow_mut().push(format!("{task} done"));
+        }
+    }
+
+    #[test]
+    fn track_reports_every_step_and_the_end() {
+        let recorder = Recorder::default();
+        let items: Vec<u32> = track(&recorder, "Counting", [10, 20].into_iter()).collect();
+
+        assert_eq!(items, vec![10, 20]);
+        assert_eq!(
+            *recorder.0.borrow(),
+            vec![
+                "Counting 0/2",
+                "Counting 1/2",
+                "Counting 2/2",
+                "Counting done"
+            ]
+        );
+    }
+}
@@ -0,0 +1,193 @@
+//! # Pending Operations
+//!
+//! Operations that reach another machine can fail for reasons that have
+//! nothing to do with the operation: the network is down, the laptop is on a
+//! train. Rather than drop them, the client records each failed one in
+//! `queue.json` next to the global store and retries it later — on the next
+//! command, or on demand with `padz flush-queue`.
+//!
+//! The only such operation today is uploading the global store to its bucket
+//! (`global_store`, see [`crate::store::object`]). An upload sends whatever
+//! differs from the bucket at the time it runs, so the queue holds one entry
+//! per bucket however many uploads failed: retrying it once catches up on all
+//! of them.
+//!
+//! Like [`crate::usage`], this module only keeps the file; running an
+//! operation is the client's business.
+
+use crate::error::{PadzError, Result};
+use chrono::{DateTime, Utc};
+use serde::{Deserialize, Serialize};
+use std::fs;
+use std::path::{Path, PathBuf};
+use uuid::Uuid;
+
+/// File name of the queue inside the global data directory.
+pub const QUEUE_FILE: &str = "queue.json";
+
+/// An operation that can be queued for a retry.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(tag = "kind", rename_all = "snake_case")]
+pub enum Operation {
+    /// Upload the global store to the bucket at `url`.
+    GlobalStorePush {
+        url: String,
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        endpoint: Option<String>,
+    },
+}
+
+impl Operation {
+    /// One line naming the operation, for listings and warnings.
+    pub fn describe(&self) -> String {
+        match self {
+            Operation::GlobalStorePush { url, .. } => format!("upload the global store to {}", url),
+        }
+    }
+}
+
+/// A failed operation waiting for its retry.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct PendingOperation {
+    #[serde(flatten)]
+    pub operation: Operation,
+    /// When it first failed.
+    pub queued_at: DateTime<Utc>,
+    /// How many times it has failed, the first attempt included.
+    pub attempts: u32,
+    /// What went wrong the last time.
+    pub last_error: String,
+}
+
+/// The persisted queue, oldest first.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize, Deserialize)]
+pub struct OperationQueue {
+    #[serde(default)]
+    pub pending: Vec<PendingOperation>,
+}
+
+impl OperationQueue {
+    /// Load the queue, treating a missing file as an empty queue.
+    pub fn load(path: &Path) -> Result<Self> {
+        match fs::read_to_string(path) {
+            Ok(content) => Ok(serde_json::from_str(&content)?),
+            Err(e) if e.kind() == std::io::ErrorKind::NotFound => Ok(Self::default()),
+            Err(e) => Err(PadzError::Io(e)),
+        }
+    }
+
+    /// Write the queue atomically (tmp file + rename). An empty queue removes
+    /// the file instead, so its absence is the cheap "nothing pending" check.
+    pub fn save(&self, path: &Path) -> Result<()> {
+        if self.pending.is_empty() {
+            return match fs::remove_file(path) {
+                Err(e) if e.kind() != std::io::ErrorKind::NotFound => Err(PadzError::Io(e)),
+                _ => Ok(()),
+            };
+        }
+        let dir = path
+            .parent()
+            .ok_or_else(|| PadzError::Store(format!("invalid queue path {}", path.display())))?;
+        fs::create_dir_all(dir)?;
+        let tmp = dir.join(format!(".queue-{}.tmp", Uuid::new_v4()));
+        fs::write(&tmp, serde_json::to_string_pretty(self)?)?;
+        fs::rename(&tmp, path)?;
+        Ok(())
+    }
+
+    /// Records that `operation` failed with `error`, queueing it if it is not
+    /// already pending.
+    pub fn record_failure(&mut self, operation: Operation, error: &str) {
+        match self.pending.iter_mut().find(|p| p.operation == operation) {
+            Some(pending) => {
+                pending.attempts += 1;
+                pending.last_error = error.to_string();
+            }
+            None => self.pending.push(PendingOperation {
+                operation,
+                queued_at: Utc::now(),
+                attempts: 1,
+                last_error: error.to_string(),
+            }),
+        }
+    }
+
+    /// Drops `operation` from the queue; returns whether it was pending.
+    pub fn complete(&mut self, operation: &Operation) -> bool {
+        let before = self.pending.len();
+        self.pending.retain(|p| &p.operation != operation);
+        self.pending.len() != before
+    }
+}
+
+/// What `flush-queue` reports.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(tag = "status", rename_all = "snake_case")]
+pub enum QueueReport {
+    /// Nothing is waiting.
+    Empty,
+    /// Operations waiting, listed without retrying them.
+    Pending { pending: Vec<PendingOperation> },
+    /// A retry ran: `sent` went through, `pending` failed again.
+    Flushed {
+        sent: Vec<PendingOperation>,
+        pending: Vec<PendingOperation>,
+    },
+}
+
+/// Where the queue lives for a given global data directory.
+pub fn queue_path(global_dir: &Path) -> PathBuf {
+    global_dir.join(QUEUE_FILE)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    fn push(url: &str) -> Operation {
+        Operation::GlobalStorePush {
+            url: url.to_string(),
+            endpoint: None,
+        }
+    }
+
+    #[test]
+    fn missing_file_loads_as_empty() {
+        let temp = TempDir::new().unwrap();
+        let queue = OperationQueue::load(&queue_path(temp.path())).unwrap();
+        assert_eq!(queue, OperationQueue::default());
+    }
+
+    #[test]
+    fn repeated_failures_are_one_entry() {
+        let mut queue = OperationQueue::default();
+        queue.record_failure(push("s3://notes/padz"), "no network");
+        queue.record_failure(push("s3://notes/padz"), "still no network");
+        queue.record_failure(push("s3://other/padz"), "no network");
+
+        assert_eq!(queue.pending.len(), 2);
+        assert_eq!(queue.pending[0].attempts, 2);
+        assert_eq!(queue.pending[0].last_error, "still no network");
+        assert!(queue.complete(&push("s3://notes/padz")));
+        assert!(!queue.complete(&push("s3://notes/padz")));
+        assert_eq!(queue.pending.len(), 1);
+    }
+
+    #[test]
+    fn save_round_trips_and_an_empty_queue_leaves_no_file() {
+        let temp = TempDir::new().unwrap();
+        let path = queue_path(temp.path());
+
+        let mut queue = OperationQueue::default();
+        queue.record_failure(push("s3://notes/padz"), "timeout");
+        queue.save(&path).unwrap();
+        assert_eq!(OperationQueue::load(&path).unwrap(), queue);
+
+        queue.complete(&push("s3://notes/padz"));
+        queue.save(&path).unwrap();
+        assert!(!path.exists());
+        // Saving an empty queue twice is fine.
+        queue.save(&path).unwrap();
+    }
+}
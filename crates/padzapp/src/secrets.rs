@@ -0,0 +1,351 @@
+//! # Secret Fences
+//!
+//! A pad can mix public context with a password or token by putting the sensitive
+//! part in a `secret` fence:
+//!
+//! ````text
+//! Staging database
+//!
+//! Host is db.staging.internal, user `app`.
+//!
+//! ```secret
+//! hunter2
+//! ```
+//! ````
+//!
+//! Whenever padz writes a pad, the body of every plaintext secret fence is
+//! encrypted (ChaCha20-Poly1305) and replaced by a single sealed line, so the file
+//! on disk reads:
+//!
+//! ````text
+//! ```secret
+//! padz-sealed:v1:<base64 nonce + ciphertext>
+//! ```
+//! ````
+//!
+//! Everything outside the fences stays plain text, so titles, listings and search
+//! keep working. [`reveal`] reverses the sealing for display.
+//!
+//! The key is a random 256-bit key kept in `secret.key` in the global data
+//! directory, created on first use (a dry run seals with a throwaway key
+//! instead of creating one). It protects pads that travel without it — a
+//! project `.padz/` committed to a repository, a synced folder, an export — not
+//! pads on a machine whose global directory is also readable.
+
+use crate::error::{PadzError, Result};
+use base64::engine::general_purpose::STANDARD as BASE64;
+use base64::Engine as _;
+use chacha20poly1305::aead::{Aead, AeadCore, KeyInit, OsRng};
+use chacha20poly1305::{ChaCha20Poly1305, Key, Nonce};
+use std::fmt;
+use std::fs;
+use std::io::Write;
+use std::path::{Path, PathBuf};
+
+/// File name of the secret key inside the global data directory.
+pub const SECRET_KEY_FILE: &str = "secret.key";
+
+const FENCE_OPEN: &str = "```secret";
+const FENCE_CLOSE: &str = "```";
+const SEALED_PREFIX: &str = "padz-sealed:v1:";
+const NONCE_LEN: usize = 12;
+
+/// The symmetric key secret fences are sealed with.
+#[derive(Clone, PartialEq, Eq)]
+pub struct SecretKey([u8; 32]);
+
+impl SecretKey {
+    /// A fresh random key.
+    pub fn generate() -> Self {
+        Self(ChaCha20Poly1305::generate_key(&mut OsRng).into())
+    }
+
+    /// Load the key at `path`, creating it (owner-readable only) when missing.
+    pub fn load_or_create(path: &Path) -> Result<Self> {
+        match fs::read_to_string(path) {
+            Ok(encoded) => Self::decode(encoded.trim()),
+            Err(e) if e.kind() == std::io::ErrorKind::NotFound => {
+                let key = Self::generate();
+                key.write_new(path)?;
+                Ok(key)
+            }
+            Err(e) => Err(PadzError::Io(e)),
+        }
+    }
+
+    fn decode(encoded: &str) -> Result<Self> {
+        let bytes = BASE64
+            .decode(encoded)
+            .map_err(|_| PadzError::Secret("secret key file is not valid base64".into()))?;
+        let key: [u8; 32] = bytes
+            .try_into()
+            .map_err(|_| PadzError::Secret("secret key file has the wrong length".into()))?;
+        Ok(Self(key))
+    }
+
+    fn write_new(&self, path: &Path) -> Result<()> {
+        if let Some(dir) = path.parent() {
+            fs::create_dir_all(dir)?;
+        }
+        let mut options = fs::OpenOptions::new();
+        options.write(true).create_new(true);
+        #[cfg(unix)]
+        {
+            use std::os::unix::fs::OpenOptionsExt;
+            options.mode(0o600);
+        }
+        let mut file = options.open(path)?;
+        file.write_all(BASE64.encode(self.0).as_bytes())?;
+        Ok(())
+    }
+
+    /// `plain` encrypted under a fresh nonce, which leads the result.
+    pub fn encrypt(&self, plain: &[u8]) -> Result<Vec<u8>> {
+        let nonce = ChaCha20Poly1305::generate_nonce(&mut OsRng);
+        let ciphertext = self
+            .cipher()
+            .encrypt(&nonce, plain)
+            .map_err(|_| PadzError::Secret("could not encrypt".into()))?;
+        let mut payload = nonce.to_vec();
+        payload.extend(ciphertext);
+        Ok(payload)
+    }
+
+    /// Reverses [`Self::encrypt`]; fails when `payload` was encrypted under
+    /// another key or has been tampered with.
+    pub fn decrypt(&self, payload: &[u8]) -> Result<Vec<u8>> {
+        if payload.len() < NONCE_LEN {
+            return Err(PadzError::Secret("the encrypted data is truncated".into()));
+        }
+        let (nonce, ciphertext) = payload.split_at(NONCE_LEN);
+        self.cipher()
+            .decrypt(Nonce::from_slice(nonce), ciphertext)
+            .map_err(|_| {
+                PadzError::Secret("the data could not be decrypted with this machine's key".into())
+            })
+    }
+
+    fn cipher(&self) -> ChaCha20Poly1305 {
+        ChaCha20Poly1305::new(Key::from_slice(&self.0))
+    }
+}
+
+impl fmt::Debug for SecretKey {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str("SecretKey(..)")
+    }
+}
+
+/// Where the secret key lives for a given global data directory.
+pub fn key_path(global_dir: &Path) -> PathBuf {
+    global_dir.join(SECRET_KEY_FILE)
+}
+
+/// Whether `content` has a secret fence that is still plain text.
+pub fn has_unsealed(content: &str) -> bool {
+    fence_bodies(content).any(needs_sealing)
+}
+
+/// Whether `content` has any secret fence at all.
+pub fn has_secrets(content: &str) -> bool {
+    fence_bodies(content).next().is_some()
+}
+
+/// Encrypt the body of every plaintext secret fence. Sealed fences are kept as-is.
+pub fn seal(content: &str, key: &SecretKey) -> Result<String> {
+    rewrite(content, |body| {
+        if !needs_sealing(body) {
+            return Ok(body.to_string());
+        }
+        // An unclosed fence's body may lack its final newline; seal it with one
+        // so the revealed text still ends before the closing fence line.
+        let body = if body.ends_with('\n') {
+            body.to_string()
+        } else {
+            format!("{}\n", body)
+        };
+        let payload = key.encrypt(body.as_bytes())?;
+        Ok(format!("{}{}\n", SEALED_PREFIX, BASE64.encode(payload)))
+    })
+}
+
+/// Decrypt the body of every sealed secret fence back to its plain text.
+pub fn reveal(content: &str, key: &SecretKey) -> Result<String> {
+    rewrite(content, |body| {
+        let Some(encoded) = sealed_payload(body) else {
+            return Ok(body.to_string());
+        };
+        let undecodable = || PadzError::Secret("a sealed secret fence is corrupt".into());
+        let payload = BASE64.decode(encoded).map_err(|_| undecodable())?;
+        if payload.len() < NONCE_LEN {
+            return Err(undecodable());
+        }
+        let plain = key.decrypt(&payload).map_err(|_| {
+            PadzError::Secret(
+                "a secret fence could not be decrypted with this machine's key".into(),
+            )
+        })?;
+        String::from_utf8(plain).map_err(|_| undecodable())
+    })
+}
+
+/// The base64 payload of a sealed fence body, or `None` for plain text.
+fn sealed_payload(body: &str) -> Option<&str> {
+    let line = body.strip_suffix('\n').unwrap_or(body);
+    if line.contains('\n') {
+        return None;
+    }
+    line.trim().strip_prefix(SEALED_PREFIX)
+}
+
+/// A run of content: either text outside any fence, or one fence's body.
+enum Segment<'a> {
+    Text(&'a str),
+    /// Body of a secret fence, and whether the fence was closed.
+    Secret(&'a str, bool),
+}
+
+/// Whether a fence body still holds plain text worth sealing.
+fn needs_sealing(body: &str) -> bool {
+    !body.trim().is_empty() && sealed_payload(body).is_none()
+}
+
+fn fence_bodies(content: &str) -> impl Iterator<Item = &str> {
+    segments(content).into_iter().filter_map(|s| match s {
+        Segment::Secret(body, _) => Some(body),
+        Segment::Text(_) => None,
+    })
+}
+
+/// Split `content` into text and fence bodies. Fence lines themselves belong to
+/// the surrounding text segments. An unclosed fence runs to the end of the
+/// content, as it does in Markdown.
+fn segments(content: &str) -> Vec<Segment<'_>> {
+    let mut segments = Vec::new();
+    let mut text_start = 0;
+    let mut body_start: Option<usize> = None;
+    let mut offset = 0;
+
+    for line in content.split_inclusive('\n') {
+        let end = offset + line.len();
+        match body_start {
+            None if line.trim() == FENCE_OPEN => {
+                segments.push(Segment::Text(&content[text_start..end]));
+                body_start = Some(end);
+            }
+            Some(start) if line.trim() == FENCE_CLOSE => {
+                segments.push(Segment::Secret(&content[start..offset], true));
+                body_start = None;
+                text_start = offset;
+            }
+            _ => {}
+        }
+        offset = end;
+    }
+
+    match body_start {
+        Some(start) => segments.push(Segment::Secret(&content[start..], false)),
+        None => segments.push(Segment::Text(&content[text_start..])),
+    }
+    segments
+}
+
+/// Rebuild `content` with every fence body passed through `f`.
+fn rewrite(content: &str, mut f: impl FnMut(&str) -> Result<String>) -> Result<String> {
+    let mut out = String::with_capacity(content.len());
+    for segment in segments(content) {
+        match segment {
+            Segment::Text(text) => out.push_str(text),
+            Segment::Secret(body, closed) => {
+                let body = f(body)?;
+                out.push_str(&body);
+                if !closed {
+                    if !body.ends_with('\n') {
+                        out.push('\n');
+                    }
+                    out.push_str(FENCE_CLOSE);
+                    out.push('\n');
+                }
+            }
+        }
+    }
+    Ok(out)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    const NOTE: &str = "Staging db\n\nHost is db.staging.\n\n```secret\nhunter2\n```\n\nAsk ops.\n";
+
+    #[test]
+    fn seal_hides_the_fence_body_only() {
+        let key = SecretKey::generate();
+
+        let sealed = seal(NOTE, &key).unwrap();
+
+        assert!(!sealed.contains("hunter2"));
+        assert!(sealed.contains(SEALED_PREFIX));
+        assert!(sealed.starts_with("Staging db\n\nHost is db.staging.\n\n```secret\n"));
+        assert!(sealed.ends_with("```\n\nAsk ops.\n"));
+        assert!(!has_unsealed(&sealed));
+    }
+
+    #[test]
+    fn reveal_round_trips() {
+        let key = SecretKey::generate();
+
+        let sealed = seal(NOTE, &key).unwrap();
+
+        assert_eq!(reveal(&sealed, &key).unwrap(), NOTE);
+    }
+
+    #[test]
+    fn sealing_twice_keeps_the_first_seal() {
+        let key = SecretKey::generate();
+        let sealed = seal(NOTE, &key).unwrap();
+
+        assert_eq!(seal(&sealed, &key).unwrap(), sealed);
+    }
+
+    #[test]
+    fn reveal_with_another_key_fails() {
+        let sealed = seal(NOTE, &SecretKey::generate()).unwrap();
+
+        let err = reveal(&sealed, &SecretKey::generate()).unwrap_err();
+
+        assert!(matches!(err, PadzError::Secret(_)));
+    }
+
+    #[test]
+    fn content_without_fences_is_untouched() {
+        let key = SecretKey::generate();
+        let plain = "Title\n\n```rust\nfn main() {}\n```\n";
+
+        assert!(!has_secrets(plain));
+        assert_eq!(seal(plain, &key).unwrap(), plain);
+    }
+
+    #[test]
+    fn unclosed_fence_runs_to_the_end_and_is_closed() {
+        let key = SecretKey::generate();
+
+        let sealed = seal("Title\n\n```secret\ntoken", &key).unwrap();
+
+        assert!(!sealed.contains("token"));
+        assert!(sealed.ends_with("\n```\n"));
+        assert!(reveal(&sealed, &key).unwrap().contains("token"));
+    }
+
+    #[test]
+    fn key_is_created_once_and_reloaded() {
+        let temp = TempDir::new().unwrap();
+        let path = key_path(temp.path());
+
+        let created = SecretKey::load_or_create(&path).unwrap();
+        let loaded = SecretKey::load_or_create(&path).unwrap();
+
+        assert_eq!(created, loaded);
+    }
+}
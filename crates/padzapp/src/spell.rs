@@ -0,0 +1,301 @@
+//! # Spell Checking
+//!
+//! A small, dependency-free spell checker: a [`Dictionary`] is a set of known
+//! words, and [`check`] reports the words of a text that are not in it. Where
+//! the dictionary comes from — a file in the config directory, the system word
+//! list, a personal list of project jargon — is the client's business; this
+//! module never touches the filesystem.
+//!
+//! The checker is deliberately conservative, because a note full of false
+//! alarms is worse than one missed typo. It skips:
+//!
+//! - fenced code blocks and inline `code` spans,
+//! - anything that looks like a path, URL, e-mail address or identifier
+//!   (a whitespace-separated chunk containing `/`, `\`, `_` or `@`),
+//! - words with a capital after the first letter (`HTTP`, `iPhone`, `camelCase`),
+//! - words attached to digits (`v2`, `3rd`), and single letters.
+//!
+//! Dictionaries are plain word lists, one per line. Hunspell `.dic` files load
+//! too — the count header and `/FLAGS` suffixes are ignored — but only their
+//! stems are known, since affix rules are not applied.
+
+use crate::index::MatchSegment;
+use serde::{Deserialize, Serialize};
+use std::collections::HashSet;
+
+/// A set of correctly spelled words, compared case-insensitively.
+#[derive(Debug, Clone, Default)]
+pub struct Dictionary {
+    words: HashSet<String>,
+}
+
+impl Dictionary {
+    /// Reads a word list: one word per line, `#` comments and blank lines
+    /// ignored, hunspell count headers and `/FLAGS` suffixes tolerated.
+    pub fn parse(list: &str) -> Self {
+        let words = list
+            .lines()
+            .map(str::trim)
+            .filter(|line| !line.is_empty() && !line.starts_with('#'))
+            .filter(|line| !line.chars().all(|c| c.is_ascii_digit()))
+            .map(|line| line.split('/').next().unwrap_or(line).to_lowercase())
+            .collect();
+        Self { words }
+    }
+
+    /// Adds every word of `other`, e.g. a personal dictionary.
+    pub fn extend(&mut self, other: Dictionary) {
+        self.words.extend(other.words);
+    }
+
+    pub fn contains(&self, word: &str) -> bool {
+        let lower = word.to_lowercase();
+        if self.words.contains(&lower) {
+            return true;
+        }
+        // Possessives are spelled as well as their owner.
+        ["'s", "’s"].iter().any(|s| {
+            lower
+                .strip_suffix(s)
+                .is_some_and(|w| self.words.contains(w))
+        })
+    }
+
+    pub fn len(&self) -> usize {
+        self.words.len()
+    }
+
+    pub fn is_empty(&self) -> bool {
+        self.words.is_empty()
+    }
+}
+
+/// A word the dictionary does not know, and where it is.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct Misspelling {
+    pub word: String,
+    /// 1-based line within the checked text.
+    pub line: usize,
+    /// 1-based column, in characters.
+    pub column: usize,
+}
+
+/// The result of checking a text.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct SpellCheck {
+    pub misspellings: Vec<Misspelling>,
+    /// The text line by line, split into plain runs and misspelled words
+    /// (as [`MatchSegment::Match`]), so a client can highlight in place.
+    pub lines: Vec<Vec<MatchSegment>>,
+}
+
+/// Checks `text` against `dictionary`.
+pub fn check(text: &str, dictionary: &Dictionary) -> SpellCheck {
+    let mut misspellings = Vec::new();
+    let mut lines = Vec::new();
+    let mut in_fence = false;
+
+    for (i, line) in text.lines().enumerate() {
+        let trimmed = line.trim_start();
+        let is_fence = trimmed.starts_with("```") || trimmed.starts_with("~~~");
+        if is_fence || in_fence {
+            in_fence ^= is_fence;
+            lines.push(vec![MatchSegment::Plain(line.to_string())]);
+            continue;
+        }
+
+        let found: Vec<(usize, usize)> = words(line)
+            .into_iter()
+            .filter(|&(start, end)| !dictionary.contains(&line[start..end]))
+            .collect();
+        lines.push(segments(line, &found));
+        misspellings.extend(found.into_iter().map(|(start, end)| Misspelling {
+            word: line[start..end].to_string(),
+            line: i + 1,
+            column: line[..start].chars().count() + 1,
+        }));
+    }
+
+    SpellCheck {
+        misspellings,
+        lines,
+    }
+}
+
+/// Byte ranges of the checkable words of `line`.
+fn words(line: &str) -> Vec<(usize, usize)> {
+    let mut out = Vec::new();
+    let mut in_code = false;
+    let mut offset = 0;
+    // Backticks split a line into alternating prose and code runs.
+    for run in line.split('`') {
+        if !in_code {
+            for (start, chunk) in chunks(run) {
+                if ["/", "\\", "_", "@"].iter().any(|c| chunk.contains(c)) {
+                    continue;
+                }
+                for (s, e) in chunk_words(chunk) {
+                    out.push((offset + start + s, offset + start + e));
+                }
+            }
+        }
+        in_code = !in_code;
+        offset += run.len() + 1;
+    }
+    out
+}
+
+/// Whitespace-separated chunks of `run`, with their byte offsets.
+fn chunks(run: &str) -> Vec<(usize, &str)> {
+    let mut out = Vec::new();
+    let mut start = None;
+    for (i, c) in run.char_indices() {
+        match (c.is_whitespace(), start) {
+            (true, Some(s)) => {
+                out.push((s, &run[s..i]));
+                start = None;
+            }
+            (false, None) => start = Some(i),
+            _ => {}
+        }
+    }
+    if let Some(s) = start {
+        out.push((s, &run[s..]));
+    }
+    out
+}
+
+/// Byte ranges of the words in `chunk` worth checking: letter runs joined by
+/// inner apostrophes, not touching a digit, lowercase after the first letter.
+fn chunk_words(chunk: &str) -> Vec<(usize, usize)> {
+    let chars: Vec<(usize, char)> = chunk.char_indices().collect();
+    let mut out = Vec::new();
+    let mut i = 0;
+    while i < chars.len() {
+        if !chars[i].1.is_alphabetic() {
+            i += 1;
+            continue;
+        }
+        let start = i;
+        while i < chars.len() {
+            let c = chars[i].1;
+            let joins =
+                (c == '\'' || c == '’') && chars.get(i + 1).is_some_and(|(_, n)| n.is_alphabetic());
+            if c.is_alphabetic() || joins {
+                i += 1;
+            } else {
+                break;
+            }
+        }
+        let touches_digit = |j: Option<&(usize, char)>| j.is_some_and(|(_, c)| c.is_ascii_digit());
+        let word = &chars[start..i];
+        let checkable = word.len() > 1
+            && !word[1..].iter().any(|(_, c)| c.is_uppercase())
+            && !touches_digit(start.checked_sub(1).and_then(|j| chars.get(j)))
+            && !touches_digit(chars.get(i));
+        if checkable {
+            let end = chars.get(i).map_or(chunk.len(), |(b, _)| *b);
+            out.push((chars[start].0, end));
+        }
+    }
+    out
+}
+
+/// Splits `line` at the misspelled byte ranges in `found`.
+fn segments(line: &str, found: &[(usize, usize)]) -> Vec<MatchSegment> {
+    let mut out = Vec::new();
+    let mut at = 0;
+    for &(start, end) in found {
+        if start > at {
+            out.push(MatchSegment::Plain(line[at..start].to_string()));
+        }
+        out.push(MatchSegment::Match(line[start..end].to_string()));
+        at = end;
+    }
+    if at < line.len() || out.is_empty() {
+        out.push(MatchSegment::Plain(line[at..].to_string()));
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn dictionary() -> Dictionary {
+        Dictionary::parse(
+            "# test words\n4\nthe\nquick/S\nbrown\nfox\njumps\nover\nlazy\ndog\nit\nsee\nand\n",
+        )
+    }
+
+    fn misspelled(text: &str) -> Vec<String> {
+        check(text, &dictionary())
+            .misspellings
+            .into_iter()
+            .map(|m| m.word)
+            .collect()
+    }
+
+    #[test]
+    fn parses_plain_and_hunspell_lists() {
+        let dict = dictionary();
+        assert_eq!(dict.len(), 11);
+        assert!(dict.contains("Quick"));
+        assert!(!dict.contains("4"));
+    }
+
+    #[test]
+    fn reports_unknown_words_with_positions() {
+        let result = check("The quick brwn fox\njumps ovr it", &dictionary());
+        assert_eq!(
+            result.misspellings,
+            vec![
+                Misspelling {
+                    word: "brwn".into(),
+                    line: 1,
+                    column: 11,
+                },
+                Misspelling {
+                    word: "ovr".into(),
+                    line: 2,
+                    column: 7,
+                },
+            ]
+        );
+        assert_eq!(
+            result.lines[0],
+            vec![
+                MatchSegment::Plain("The quick ".into()),
+                MatchSegment::Match("brwn".into()),
+                MatchSegment::Plain(" fox".into()),
+            ]
+        );
+    }
+
+    #[test]
+    fn skips_code_paths_identifiers_and_acronyms() {
+        let text = "the `frobnicate` fox\n\
+                    ```\nqwzx inside a fence\n```\n\
+                    see src/qwzx.rs and https://qwzx.example and me@qwzx.io\n\
+                    the HTTP iPhone fox_trot v2 3rd";
+        assert!(misspelled(text).is_empty(), "got {:?}", misspelled(text));
+    }
+
+    #[test]
+    fn possessives_and_contractions_are_words() {
+        assert!(misspelled("the dog's it").is_empty());
+        assert_eq!(misspelled("the dgo's"), vec!["dgo's"]);
+    }
+
+    #[test]
+    fn non_ascii_columns_count_characters() {
+        let result = check("café brwn", &Dictionary::parse("café"));
+        assert_eq!(result.misspellings[0].column, 6);
+    }
+
+    #[test]
+    fn an_empty_line_is_one_plain_segment() {
+        let result = check("the\n\nfox", &dictionary());
+        assert_eq!(result.lines[1], vec![MatchSegment::Plain(String::new())]);
+    }
+}
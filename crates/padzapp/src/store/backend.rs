@@ -26,6 +26,26 @@ pub trait StorageBackend {
     /// Save the tag registry
     fn save_tags(&self, scope: Scope, tags: &[TagEntry]) -> Result<()>;
 
+    // --- Transaction Journal ---
+
+    /// Load the journal of a commit that has not finished applying, if any.
+    fn load_journal(&self, scope: Scope) -> Result<Option<String>>;
+
+    /// Record a commit's writes before applying them.
+    /// MUST be atomic: a half-written journal would replay half a transaction.
+    fn save_journal(&self, scope: Scope, journal: &str) -> Result<()>;
+
+    /// Remove the journal once its writes are applied.
+    fn clear_journal(&self, scope: Scope) -> Result<()>;
+
+    // --- Undo Record ---
+
+    /// Load the undo record of the last commit (see [`super::undo`]), if any.
+    fn load_undo(&self, scope: Scope) -> Result<Option<String>>;
+
+    /// Replace the undo record. MUST be atomic, like the journal.
+    fn save_undo(&self, scope: Scope, record: &str) -> Result<()>;
+
     // --- Content Operations ---
 
     /// Read raw content string for a pad.
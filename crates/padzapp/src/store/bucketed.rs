@@ -5,37 +5,122 @@
 //! independent `data.json` and pad content files.
 //!
 //! Tags are stored at the scope root (shared across buckets) via a separate backend.
+//!
+//! Every backend sits behind a [`WriteGuard`], which is what lets the store group
+//! writes into transactions (see [`super::journal`]) and run whole invocations as
+//! dry runs.
 
 use super::backend::StorageBackend;
+use super::events::{self, EventKind, StoreEvent};
+use super::history;
+use super::integrity::IntegrityReport;
+use super::journal::Journal;
 use super::pad_store::PadStore;
+use super::undo::{self, UndoRecord};
+use super::write_guard::WriteGuard;
 use super::{Bucket, DataStore, DoctorReport};
-use crate::error::Result;
-use crate::model::{Pad, Scope};
+use crate::error::{PadzError, Result, StoreWarning};
+use crate::model::{Metadata, Pad, Scope};
 use crate::tags::TagEntry;
+use chrono::Utc;
+use std::cell::RefCell;
+use std::collections::HashMap;
 use std::path::PathBuf;
 use uuid::Uuid;
 
 /// A store that manages three lifecycle buckets (active, archived, deleted)
 /// plus a scope-level tag backend.
 pub struct BucketedStore<B: StorageBackend> {
-    pub(crate) active: PadStore<B>,
-    pub(crate) archived: PadStore<B>,
-    pub(crate) deleted: PadStore<B>,
+    pub(crate) active: PadStore<WriteGuard<B>>,
+    pub(crate) archived: PadStore<WriteGuard<B>>,
+    pub(crate) deleted: PadStore<WriteGuard<B>>,
     /// Separate backend at the scope root for tags (shared across buckets).
-    pub(crate) tag_backend: B,
+    /// Also holds the transaction journal.
+    pub(crate) tag_backend: WriteGuard<B>,
+    /// Open `begin` calls; only the outermost `commit` writes.
+    tx_depth: usize,
+    /// Problems noted since the last [`DataStore::take_warnings`].
+    warnings: RefCell<Vec<StoreWarning>>,
+    /// See [`DataStore::set_ignore_errors`].
+    ignore_errors: bool,
+    /// Events noted since the last [`DataStore::take_events`]; `None` until
+    /// [`DataStore::record_events`].
+    events: Option<Vec<StoreEvent>>,
+    /// How many events there were at the outermost `begin`; a rollback drops
+    /// the rest.
+    events_at_begin: usize,
 }
 
 impl<B: StorageBackend> BucketedStore<B> {
     pub fn new(active: B, archived: B, deleted: B, tag_backend: B) -> Self {
         Self {
-            active: PadStore::with_backend(active),
-            archived: PadStore::with_backend(archived),
-            deleted: PadStore::with_backend(deleted),
-            tag_backend,
+            active: PadStore::with_backend(WriteGuard::new(active)),
+            archived: PadStore::with_backend(WriteGuard::new(archived)),
+            deleted: PadStore::with_backend(WriteGuard::new(deleted)),
+            tag_backend: WriteGuard::new(tag_backend),
+            tx_depth: 0,
+            warnings: RefCell::new(Vec::new()),
+            ignore_errors: false,
+            events: None,
+            events_at_begin: 0,
+        }
+    }
+
+    /// Keep every further write in memory: commands run and report as usual,
+    /// but nothing reaches storage. There is no way back for this store value.
+    pub fn arm_dry_run(&mut self) {
+        self.active.backend.arm();
+        self.archived.backend.arm();
+        self.deleted.backend.arm();
+        self.tag_backend.arm();
+    }
+
+    pub fn is_dry_run(&self) -> bool {
+        self.tag_backend.is_armed()
+    }
+
+    /// Finish any commit a crash interrupted, in every available scope.
+    /// Returns how many journals were replayed.
+    pub fn recover(&mut self) -> Result<usize> {
+        let mut replayed = 0;
+        for scope in [Scope::Project, Scope::Global] {
+            if !self.tag_backend.scope_available(scope) {
+                continue;
+            }
+            if let Some(text) = self.tag_backend.load_journal(scope)? {
+                let journal: Journal =
+                    serde_json::from_str(&text).map_err(PadzError::Serialization)?;
+                self.apply_journal(scope, &journal)?;
+                self.tag_backend.clear_journal(scope)?;
+                replayed += 1;
+            }
         }
+        Ok(replayed)
+    }
+
+    fn apply_journal(&self, scope: Scope, journal: &Journal) -> Result<()> {
+        self.active.backend.apply(scope, &journal.active)?;
+        self.archived.backend.apply(scope, &journal.archived)?;
+        self.deleted.backend.apply(scope, &journal.deleted)?;
+        self.tag_backend.apply(scope, &journal.root)
     }
 
-    fn store(&self, bucket: Bucket) -> &PadStore<B> {
+    /// How to take back `journal` (see [`super::undo`]). The guards write
+    /// through again by now, so what they read is what the journal replaces.
+    fn undo_record(&self, scope: Scope, journal: &Journal) -> Result<UndoRecord> {
+        undo::record(
+            scope,
+            [
+                (Bucket::Active, &self.active.backend, &journal.active),
+                (Bucket::Archived, &self.archived.backend, &journal.archived),
+                (Bucket::Deleted, &self.deleted.backend, &journal.deleted),
+            ],
+            (&self.tag_backend, &journal.root),
+            Utc::now(),
+        )
+    }
+
+    fn store(&self, bucket: Bucket) -> &PadStore<WriteGuard<B>> {
         match bucket {
             Bucket::Active => &self.active,
             Bucket::Archived => &self.archived,
@@ -43,7 +128,22 @@ impl<B: StorageBackend> BucketedStore<B> {
         }
     }
 
-    fn store_mut(&mut self, bucket: Bucket) -> &mut PadStore<B> {
+    /// The index entry of `id` in `bucket`, if there is one.
+    fn indexed(&self, id: &Uuid, scope: Scope, bucket: Bucket) -> Result<Option<Metadata>> {
+        Ok(self.store(bucket).backend.load_index(scope)?.remove(id))
+    }
+
+    fn note(&mut self, kinds: &[EventKind], metadata: &Metadata, scope: Scope) {
+        if let Some(events) = &mut self.events {
+            events.extend(
+                kinds
+                    .iter()
+                    .map(|kind| StoreEvent::new(*kind, metadata, scope)),
+            );
+        }
+    }
+
+    fn store_mut(&mut self, bucket: Bucket) -> &mut PadStore<WriteGuard<B>> {
         match bucket {
             Bucket::Active => &mut self.active,
             Bucket::Archived => &mut self.archived,
@@ -52,12 +152,12 @@ impl<B: StorageBackend> BucketedStore<B> {
     }
 
     /// Access the active inner store (for testing/internal use)
-    pub fn active_store(&self) -> &PadStore<B> {
+    pub fn active_store(&self) -> &PadStore<WriteGuard<B>> {
         &self.active
     }
 
     /// Access the active inner store mutably
-    pub fn active_store_mut(&mut self) -> &mut PadStore<B> {
+    pub fn active_store_mut(&mut self) -> &mut PadStore<WriteGuard<B>> {
         &mut self.active
     }
 
@@ -72,7 +172,25 @@ impl<B: StorageBackend> BucketedStore<B> {
 
 impl<B: StorageBackend> DataStore for BucketedStore<B> {
     fn save_pad(&mut self, pad: &Pad, scope: Scope, bucket: Bucket) -> Result<()> {
-        self.store_mut(bucket).save_pad(pad, scope)
+        if self.events.is_none() {
+            return self.store_mut(bucket).save_pad(pad, scope);
+        }
+        let previous = self.indexed(&pad.metadata.id, scope, bucket)?;
+        self.store_mut(bucket).save_pad(pad, scope)?;
+        let kinds = events::on_save(previous.as_ref(), &pad.metadata, Some(&pad.content));
+        self.note(&kinds, &pad.metadata, scope);
+        Ok(())
+    }
+
+    fn save_metadata(&mut self, metadata: &Metadata, scope: Scope, bucket: Bucket) -> Result<()> {
+        if self.events.is_none() {
+            return self.store_mut(bucket).save_metadata(metadata, scope);
+        }
+        let previous = self.indexed(&metadata.id, scope, bucket)?;
+        self.store_mut(bucket).save_metadata(metadata, scope)?;
+        let kinds = events::on_save(previous.as_ref(), metadata, None);
+        self.note(&kinds, metadata, scope);
+        Ok(())
     }
 
     fn get_pad(&self, id: &Uuid, scope: Scope, bucket: Bucket) -> Result<Pad> {
@@ -80,11 +198,46 @@ impl<B: StorageBackend> DataStore for BucketedStore<B> {
     }
 
     fn list_pads(&self, scope: Scope, bucket: Bucket) -> Result<Vec<Pad>> {
-        self.store(bucket).list_pads(scope)
+        let store = self.store(bucket);
+        if !self.ignore_errors {
+            return store.list_pads(scope).map_err(|e| {
+                PadzError::Store(format!(
+                    "cannot list pads: {}; run `padz doctor`, or pass --ignore-errors to list what can be read",
+                    e
+                ))
+            });
+        }
+        // A failed sync leaves the index as it was: list that, and say so.
+        if let Err(e) = store.sync(scope) {
+            self.warn(StoreWarning::StaleListing {
+                scope,
+                bucket,
+                error: e.to_string(),
+            });
+        }
+        store.list_indexed_skipping(scope, |metadata, e| {
+            self.warn(StoreWarning::UnreadablePad {
+                title: metadata.title.clone(),
+                error: e.to_string(),
+            });
+            Ok(())
+        })
+    }
+
+    fn list_metadata(&self, scope: Scope, bucket: Bucket) -> Result<Vec<Metadata>> {
+        self.store(bucket).list_metadata(scope)
     }
 
     fn delete_pad(&mut self, id: &Uuid, scope: Scope, bucket: Bucket) -> Result<()> {
-        self.store_mut(bucket).delete_pad(id, scope)
+        if self.events.is_none() {
+            return self.store_mut(bucket).delete_pad(id, scope);
+        }
+        let previous = self.indexed(id, scope, bucket)?;
+        self.store_mut(bucket).delete_pad(id, scope)?;
+        if let Some(metadata) = previous {
+            self.note(&[EventKind::Purged], &metadata, scope);
+        }
+        Ok(())
     }
 
     fn move_pad(&mut self, id: &Uuid, scope: Scope, from: Bucket, to: Bucket) -> Result<Pad> {
@@ -92,8 +245,9 @@ impl<B: StorageBackend> DataStore for BucketedStore<B> {
             return self.get_pad(id, scope, from);
         }
 
-        // 1. Read from source
-        let pad = self.store(from).get_pad(id, scope)?;
+        // 1. Read from source, logging where it is going
+        let mut pad = self.store(from).get_pad(id, scope)?;
+        history::record_move(&mut pad.metadata, from, to, Utc::now());
 
         // 2. Write to destination (content first = orphan-safe)
         self.store_mut(to).save_pad(&pad, scope)?;
@@ -102,6 +256,9 @@ impl<B: StorageBackend> DataStore for BucketedStore<B> {
         // Crash between 2 and 3: pad in both. Source doctor cleans the zombie. Safe.
         self.store_mut(from).delete_pad(id, scope)?;
 
+        if let Some(kind) = events::on_move(from, to) {
+            self.note(&[kind], &pad.metadata, scope);
+        }
         Ok(pad)
     }
 
@@ -141,6 +298,19 @@ impl<B: StorageBackend> DataStore for BucketedStore<B> {
         })
     }
 
+    fn check_integrity(&self, scope: Scope) -> Result<IntegrityReport> {
+        let mut report = self.active.check_integrity(scope, Bucket::Active)?;
+        report.merge(self.archived.check_integrity(scope, Bucket::Archived)?);
+        report.merge(self.deleted.check_integrity(scope, Bucket::Deleted)?);
+        Ok(report)
+    }
+
+    fn accept_checksums(&mut self, scope: Scope) -> Result<usize> {
+        Ok(self.active.accept_checksums(scope)?
+            + self.archived.accept_checksums(scope)?
+            + self.deleted.accept_checksums(scope)?)
+    }
+
     fn load_tags(&self, scope: Scope) -> Result<Vec<TagEntry>> {
         self.tag_backend.load_tags(scope)
     }
@@ -148,6 +318,101 @@ impl<B: StorageBackend> DataStore for BucketedStore<B> {
     fn save_tags(&mut self, scope: Scope, tags: &[TagEntry]) -> Result<()> {
         self.tag_backend.save_tags(scope, tags)
     }
+
+    fn begin(&mut self) {
+        if self.tx_depth == 0 {
+            self.events_at_begin = self.events.as_ref().map_or(0, Vec::len);
+            self.active.backend.stage();
+            self.archived.backend.stage();
+            self.deleted.backend.stage();
+            self.tag_backend.stage();
+        }
+        self.tx_depth += 1;
+    }
+
+    fn commit(&mut self) -> Result<()> {
+        if self.tx_depth == 0 {
+            return Ok(());
+        }
+        self.tx_depth -= 1;
+        if self.tx_depth > 0 {
+            return Ok(());
+        }
+
+        let mut journals: HashMap<Scope, Journal> = HashMap::new();
+        for (scope, writes) in self.active.backend.take_staged() {
+            journals.entry(scope).or_default().active = writes;
+        }
+        for (scope, writes) in self.archived.backend.take_staged() {
+            journals.entry(scope).or_default().archived = writes;
+        }
+        for (scope, writes) in self.deleted.backend.take_staged() {
+            journals.entry(scope).or_default().deleted = writes;
+        }
+        for (scope, writes) in self.tag_backend.take_staged() {
+            journals.entry(scope).or_default().root = writes;
+        }
+
+        for (scope, journal) in journals {
+            if journal.is_empty() {
+                continue;
+            }
+            let change = self.undo_record(scope, &journal)?;
+            if !change.is_empty() {
+                let text = serde_json::to_string(&change).map_err(PadzError::Serialization)?;
+                self.tag_backend.save_undo(scope, &text)?;
+            }
+            let text = serde_json::to_string(&journal).map_err(PadzError::Serialization)?;
+            self.tag_backend.save_journal(scope, &text)?;
+            self.apply_journal(scope, &journal)?;
+            self.tag_backend.clear_journal(scope)?;
+        }
+        Ok(())
+    }
+
+    fn rollback(&mut self) {
+        self.tx_depth = 0;
+        self.active.backend.discard_staged();
+        self.archived.backend.discard_staged();
+        self.deleted.backend.discard_staged();
+        self.tag_backend.discard_staged();
+        if let Some(events) = &mut self.events {
+            events.truncate(self.events_at_begin);
+        }
+    }
+
+    fn last_change(&self, scope: Scope) -> Result<Option<UndoRecord>> {
+        match self.tag_backend.load_undo(scope)? {
+            Some(text) => serde_json::from_str(&text)
+                .map(Some)
+                .map_err(PadzError::Serialization),
+            None => Ok(None),
+        }
+    }
+
+    fn warn(&self, warning: StoreWarning) {
+        // Listings repeat within one command; one warning per problem is enough.
+        let mut warnings = self.warnings.borrow_mut();
+        if !warnings.contains(&warning) {
+            warnings.push(warning);
+        }
+    }
+
+    fn take_warnings(&self) -> Vec<StoreWarning> {
+        self.warnings.take()
+    }
+
+    fn set_ignore_errors(&mut self, ignore: bool) {
+        self.ignore_errors = ignore;
+    }
+
+    fn record_events(&mut self) {
+        self.events.get_or_insert_with(Vec::new);
+    }
+
+    fn take_events(&mut self) -> Vec<StoreEvent> {
+        self.events.as_mut().map(std::mem::take).unwrap_or_default()
+    }
 }
 
 #[cfg(test)]
@@ -360,4 +625,182 @@ mod tests {
         assert_eq!(loaded.len(), 2);
         assert_eq!(loaded[0].name, "work");
     }
+
+    // --- Transactions ---
+
+    fn move_to_deleted_in_transaction(store: &mut BucketedInMemoryStore) -> Uuid {
+        let pad = Pad::new("Moving".into(), "Content".into());
+        let id = pad.metadata.id;
+        store
+            .save_pad(&pad, Scope::Project, Bucket::Active)
+            .unwrap();
+        store.begin();
+        store
+            .move_pad(&id, Scope::Project, Bucket::Active, Bucket::Deleted)
+            .unwrap();
+        id
+    }
+
+    /// What the deleted bucket's storage holds, beneath any held writes.
+    fn stored_in_deleted(store: &BucketedInMemoryStore) -> usize {
+        (*store.deleted.backend)
+            .load_index(Scope::Project)
+            .unwrap()
+            .len()
+    }
+
+    #[test]
+    fn test_transaction_applies_nothing_until_commit() {
+        let mut store = make_store();
+        let id = move_to_deleted_in_transaction(&mut store);
+
+        assert!(store.get_pad(&id, Scope::Project, Bucket::Deleted).is_ok());
+        assert_eq!(stored_in_deleted(&store), 0);
+
+        store.commit().unwrap();
+
+        assert_eq!(stored_in_deleted(&store), 1);
+        assert!(store.get_pad(&id, Scope::Project, Bucket::Active).is_err());
+        assert!(store
+            .tag_backend
+            .load_journal(Scope::Project)
+            .unwrap()
+            .is_none());
+    }
+
+    /// A store with one indexed pad and one file whose indexing will fail.
+    fn store_that_cannot_sync() -> BucketedInMemoryStore {
+        let mut store = make_store();
+        let pad = Pad::new("Indexed".into(), "Content".into());
+        store
+            .save_pad(&pad, Scope::Project, Bucket::Active)
+            .unwrap();
+        store
+            .active
+            .backend
+            .write_content(&Uuid::new_v4(), Scope::Project, "Orphan")
+            .unwrap();
+        store.active.backend.set_simulate_write_error(true);
+        store
+    }
+
+    #[test]
+    fn test_failed_sync_fails_the_listing() {
+        let store = store_that_cannot_sync();
+
+        let err = store
+            .list_pads(Scope::Project, Bucket::Active)
+            .expect_err("an unsynced store must not pass for a complete one");
+
+        assert!(err.to_string().contains("--ignore-errors"), "got: {err}");
+        assert!(store.take_warnings().is_empty());
+    }
+
+    #[test]
+    fn test_ignoring_errors_lists_the_index_and_warns_once() {
+        let mut store = store_that_cannot_sync();
+        store.set_ignore_errors(true);
+
+        for _ in 0..2 {
+            let pads = store.list_pads(Scope::Project, Bucket::Active).unwrap();
+            assert_eq!(pads.len(), 1);
+            assert_eq!(pads[0].metadata.title, "Indexed");
+        }
+
+        let warnings = store.take_warnings();
+        assert_eq!(warnings.len(), 1);
+        assert!(matches!(
+            warnings[0],
+            StoreWarning::StaleListing {
+                scope: Scope::Project,
+                bucket: Bucket::Active,
+                ..
+            }
+        ));
+        assert!(store.take_warnings().is_empty());
+    }
+
+    #[test]
+    fn test_rollback_drops_every_write() {
+        let mut store = make_store();
+        let id = move_to_deleted_in_transaction(&mut store);
+
+        store.rollback();
+
+        assert!(store.get_pad(&id, Scope::Project, Bucket::Active).is_ok());
+        assert!(store.get_pad(&id, Scope::Project, Bucket::Deleted).is_err());
+    }
+
+    fn event_kinds(store: &mut BucketedInMemoryStore) -> Vec<EventKind> {
+        store.take_events().iter().map(|event| event.kind).collect()
+    }
+
+    #[test]
+    fn test_recorded_events_follow_the_writes() {
+        let mut store = make_store();
+        let mut pad = Pad::new("Noted".into(), "Content".into());
+        let id = pad.metadata.id;
+        store
+            .save_pad(&pad, Scope::Project, Bucket::Active)
+            .unwrap();
+        // Not recording yet.
+        assert!(store.take_events().is_empty());
+
+        store.record_events();
+        pad.metadata.is_pinned = true;
+        store
+            .save_metadata(&pad.metadata, Scope::Project, Bucket::Active)
+            .unwrap();
+        pad.content = "Noted\n\nMore".into();
+        store
+            .save_pad(&pad, Scope::Project, Bucket::Active)
+            .unwrap();
+        store
+            .move_pad(&id, Scope::Project, Bucket::Active, Bucket::Deleted)
+            .unwrap();
+        store
+            .delete_pad(&id, Scope::Project, Bucket::Deleted)
+            .unwrap();
+        assert_eq!(
+            event_kinds(&mut store),
+            vec![
+                EventKind::Pinned,
+                EventKind::Updated,
+                EventKind::Deleted,
+                EventKind::Purged
+            ]
+        );
+
+        store.begin();
+        store
+            .save_pad(&pad, Scope::Project, Bucket::Active)
+            .unwrap();
+        store.rollback();
+        assert!(event_kinds(&mut store).is_empty());
+    }
+
+    #[test]
+    fn test_interrupted_commit_is_finished_by_recover() {
+        let mut store = make_store();
+        let id = move_to_deleted_in_transaction(&mut store);
+        store.deleted.backend.set_simulate_write_error(true);
+
+        assert!(store.commit().is_err());
+        assert!(store
+            .tag_backend
+            .load_journal(Scope::Project)
+            .unwrap()
+            .is_some());
+
+        store.deleted.backend.set_simulate_write_error(false);
+        assert_eq!(store.recover().unwrap(), 1);
+
+        assert!(store.get_pad(&id, Scope::Project, Bucket::Deleted).is_ok());
+        assert!(store.get_pad(&id, Scope::Project, Bucket::Active).is_err());
+        assert!(store
+            .tag_backend
+            .load_journal(Scope::Project)
+            .unwrap()
+            .is_none());
+    }
 }
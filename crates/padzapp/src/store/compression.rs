@@ -0,0 +1,130 @@
+//! # Compression at Rest
+//!
+//! With `compress_above` set, pad bodies larger than that many bytes are kept
+//! gzipped, as `pad-{uuid}.{ext}.gz`, in every bucket: a large pasted log is
+//! as likely to sit in active as in the archive. A body is compressed when
+//! padz writes it (create, update, archive, delete) and written plain again
+//! once it shrinks below the threshold. A store that may hold compressed
+//! bodies is at schema version 2 (see [`super::schema`]), so a padz that
+//! cannot read them refuses it rather than seeing empty pads.
+//!
+//! The editor opens a compressed active pad through a working copy, as it
+//! does an encrypted one (see [`crate::api::PadzApi::editor_file`]); `padz
+//! path` names the `.gz` file itself.
+//!
+//! [`FsBackend`](super::fs_backend::FsBackend) does the work, transparently:
+//! every read hands back the text. [`usage`] reports what it saves, for
+//! `padz stats`.
+
+use flate2::read::GzDecoder;
+use flate2::write::GzEncoder;
+use flate2::Compression;
+use serde::Serialize;
+use std::fs;
+use std::io::{self, Read, Seek, SeekFrom, Write};
+use std::path::Path;
+
+/// Suffix of a compressed pad file, after the pad's own extension.
+pub const COMPRESSED_EXT: &str = ".gz";
+
+/// The buckets whose pads may be compressed.
+const COMPRESSED_BUCKETS: [&str; 3] = ["active", "archived", "deleted"];
+
+/// Whether `path` is a compressed pad file.
+pub fn is_compressed(path: &Path) -> bool {
+    path.to_string_lossy().ends_with(COMPRESSED_EXT)
+}
+
+pub fn compress(content: &str) -> io::Result<Vec<u8>> {
+    let mut encoder = GzEncoder::new(Vec::new(), Compression::default());
+    encoder.write_all(content.as_bytes())?;
+    encoder.finish()
+}
+
+pub fn decompress(bytes: &[u8]) -> io::Result<String> {
+    let mut content = String::new();
+    GzDecoder::new(bytes).read_to_string(&mut content)?;
+    Ok(content)
+}
+
+/// What compression saves in one scope.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize)]
+pub struct CompressionStats {
+    /// Pads kept compressed.
+    pub pads: usize,
+    /// Bytes their files take on disk.
+    pub stored_bytes: u64,
+    /// Bytes their bodies would take uncompressed.
+    pub original_bytes: u64,
+}
+
+/// Sums up the compressed pads under `scope_root`. Each body's size is read
+/// from its gzip trailer, so no pad is decompressed to count it.
+pub fn usage(scope_root: &Path) -> io::Result<CompressionStats> {
+    let mut stats = CompressionStats::default();
+    for bucket in COMPRESSED_BUCKETS {
+        let Ok(entries) = fs::read_dir(scope_root.join(bucket)) else {
+            continue;
+        };
+        for entry in entries {
+            let path = entry?.path();
+            let name = path.file_name().unwrap_or_default().to_string_lossy();
+            if !name.starts_with("pad-") || !is_compressed(&path) {
+                continue;
+            }
+            stats.pads += 1;
+            stats.stored_bytes += fs::metadata(&path)?.len();
+            stats.original_bytes += original_size(&path)?;
+        }
+    }
+    Ok(stats)
+}
+
+/// The uncompressed size gzip records in a file's last four bytes (modulo
+/// 4 GiB, which no pad body reaches).
+fn original_size(path: &Path) -> io::Result<u64> {
+    let mut file = fs::File::open(path)?;
+    file.seek(SeekFrom::End(-4))?;
+    let mut trailer = [0u8; 4];
+    file.read_exact(&mut trailer)?;
+    Ok(u32::from_le_bytes(trailer) as u64)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    #[test]
+    fn compress_round_trips() {
+        let body = "Build log\n\n".to_string() + &"error: flaky test\n".repeat(200);
+        let packed = compress(&body).unwrap();
+        assert!(packed.len() < body.len());
+        assert_eq!(decompress(&packed).unwrap(), body);
+    }
+
+    #[test]
+    fn usage_counts_compressed_pads_in_every_bucket() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path();
+        let body = "x".repeat(10_000);
+        for bucket in ["active", "archived", "deleted"] {
+            fs::create_dir_all(root.join(bucket)).unwrap();
+        }
+        fs::write(root.join("archived/pad-a.txt.gz"), compress(&body).unwrap()).unwrap();
+        fs::write(root.join("deleted/pad-b.txt"), &body).unwrap();
+        fs::write(root.join("active/pad-c.txt.gz"), compress(&body).unwrap()).unwrap();
+
+        let stats = usage(root).unwrap();
+
+        assert_eq!(stats.pads, 2);
+        assert_eq!(stats.original_bytes, 20_000);
+        assert_eq!(
+            stats.stored_bytes,
+            fs::metadata(root.join("archived/pad-a.txt.gz"))
+                .unwrap()
+                .len()
+                * 2
+        );
+    }
+}
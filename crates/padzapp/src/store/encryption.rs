@@ -0,0 +1,95 @@
+//! # Encryption at Rest
+//!
+//! A scope can keep its pad bodies encrypted on disk (`padz encrypt enable`),
+//! for a store that travels where others can read it: a synced folder, a
+//! bucket, a backup. Each body is encrypted on its own with the secret key of
+//! the global data directory (ChaCha20-Poly1305, as for secret fences, see
+//! [`crate::secrets`]) and kept as `pad-{uuid}.{ext}.enc`.
+//!
+//! The mode is a property of the store, not of the configuration: a scope is
+//! encrypted while the marker file [`MARKER_FILE`] sits at its root, so every
+//! padz that opens it writes bodies the same way. Turning the mode on or off
+//! rewrites every body in the new form. A store that may hold either is at
+//! schema version 3 (see [`super::schema`]), which an older padz refuses.
+//!
+//! [`FsBackend`](super::fs_backend::FsBackend) does the work, transparently:
+//! every read hands back the text, whichever form the file is in. What stays
+//! readable is the index (`data.json`): titles, tags and dates, which listings
+//! need without a key. An encrypted body is not also compressed.
+//!
+//! The key never leaves the machine on its own. Reading the store elsewhere
+//! takes a copy of `secret.key` in that machine's global data directory.
+
+use crate::error::{PadzError, Result};
+use crate::secrets::SecretKey;
+use std::fs;
+use std::path::Path;
+
+/// Suffix of an encrypted pad file, after the pad's own extension.
+pub const ENCRYPTED_EXT: &str = ".enc";
+
+/// File at a scope's root whose presence turns encryption on for that scope.
+pub const MARKER_FILE: &str = "encrypted";
+
+/// Whether `path` is an encrypted pad file.
+pub fn is_encrypted(path: &Path) -> bool {
+    path.to_string_lossy().ends_with(ENCRYPTED_EXT)
+}
+
+/// Whether the scope at `scope_root` keeps its bodies encrypted.
+pub fn is_enabled(scope_root: &Path) -> bool {
+    scope_root.join(MARKER_FILE).is_file()
+}
+
+/// Turns encryption on or off for the scope at `scope_root`. Bodies already
+/// written keep their form until they are rewritten.
+pub fn set_enabled(scope_root: &Path, enabled: bool) -> Result<()> {
+    let marker = scope_root.join(MARKER_FILE);
+    if enabled {
+        fs::create_dir_all(scope_root)?;
+        fs::write(
+            marker,
+            "Pad bodies in this store are encrypted; see `padz encrypt`.\n",
+        )?;
+    } else if marker.exists() {
+        fs::remove_file(marker)?;
+    }
+    Ok(())
+}
+
+pub fn encrypt(content: &str, key: &SecretKey) -> Result<Vec<u8>> {
+    key.encrypt(content.as_bytes())
+}
+
+pub fn decrypt(bytes: &[u8], key: &SecretKey) -> Result<String> {
+    let plain = key.decrypt(bytes)?;
+    String::from_utf8(plain)
+        .map_err(|_| PadzError::Secret("an encrypted pad is not valid text".into()))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    #[test]
+    fn encrypt_round_trips_only_with_the_same_key() {
+        let key = SecretKey::generate();
+        let sealed = encrypt("Groceries\n\nmilk", &key).unwrap();
+        assert!(!String::from_utf8_lossy(&sealed).contains("milk"));
+        assert_eq!(decrypt(&sealed, &key).unwrap(), "Groceries\n\nmilk");
+        assert!(decrypt(&sealed, &SecretKey::generate()).is_err());
+    }
+
+    #[test]
+    fn the_marker_turns_the_mode_on_and_off() {
+        let temp = TempDir::new().unwrap();
+        assert!(!is_enabled(temp.path()));
+        set_enabled(temp.path(), true).unwrap();
+        assert!(is_enabled(temp.path()));
+        set_enabled(temp.path(), false).unwrap();
+        assert!(!is_enabled(temp.path()));
+        // Turning off what is off is not an error.
+        set_enabled(temp.path(), false).unwrap();
+    }
+}
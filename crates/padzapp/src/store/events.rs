@@ -0,0 +1,169 @@
+//! # Mutation Events
+//!
+//! What a command changed, as a client outside padz wants to hear it: a status
+//! bar refreshing its count, a backup kicked off after a delete. Like the pad
+//! history ([`super::history`]), events are noted by the store rather than the
+//! commands, by comparing each write with the index entry it replaces, so every
+//! command that writes is covered. Recording is off until a client asks for it
+//! ([`DataStore::record_events`](super::DataStore::record_events)), so a store
+//! nobody listens to pays nothing for it.
+//!
+//! What counts:
+//!
+//! - **Created** — a pad written where none was.
+//! - **Updated** — its content, title, status, tags, parent, alias or anchors
+//!   changed. Reading a pad, which moves its access time, is not an update.
+//! - **Pinned / Unpinned** — the pin flipped.
+//! - **Archived / Unarchived / Deleted / Restored** — bucket moves.
+//! - **Purged** — the pad is gone for good.
+//!
+//! Events held back by a transaction are dropped with it on rollback.
+
+use super::integrity::content_checksum;
+use super::Bucket;
+use crate::model::{Metadata, Scope};
+use chrono::{DateTime, Utc};
+use serde::Serialize;
+use uuid::Uuid;
+
+/// What happened to a pad.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "snake_case")]
+pub enum EventKind {
+    Created,
+    Updated,
+    Pinned,
+    Unpinned,
+    Archived,
+    Unarchived,
+    Deleted,
+    Restored,
+    Purged,
+}
+
+/// One change to one pad.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct StoreEvent {
+    #[serde(rename = "event")]
+    pub kind: EventKind,
+    pub id: Uuid,
+    pub title: String,
+    #[serde(serialize_with = "serialize_scope")]
+    pub scope: Scope,
+    pub at: DateTime<Utc>,
+}
+
+impl StoreEvent {
+    pub fn new(kind: EventKind, metadata: &Metadata, scope: Scope) -> Self {
+        Self {
+            kind,
+            id: metadata.id,
+            title: metadata.title.clone(),
+            scope,
+            at: Utc::now(),
+        }
+    }
+}
+
+fn serialize_scope<S: serde::Serializer>(scope: &Scope, serializer: S) -> Result<S::Ok, S::Error> {
+    serializer.serialize_str(match scope {
+        Scope::Project => "project",
+        Scope::Global => "global",
+    })
+}
+
+/// The events of writing `next`, whose content is `content` when the write
+/// carries one, over `previous`, the index entry it replaces.
+pub fn on_save(
+    previous: Option<&Metadata>,
+    next: &Metadata,
+    content: Option<&str>,
+) -> Vec<EventKind> {
+    let Some(previous) = previous else {
+        return vec![EventKind::Created];
+    };
+    let mut kinds = Vec::new();
+    if previous.is_pinned != next.is_pinned {
+        kinds.push(if next.is_pinned {
+            EventKind::Pinned
+        } else {
+            EventKind::Unpinned
+        });
+    }
+    let rewritten = content.is_some_and(|content| {
+        previous.checksum.as_deref() != Some(content_checksum(content).as_str())
+    });
+    if rewritten
+        || previous.title != next.title
+        || previous.status != next.status
+        || previous.tags != next.tags
+        || previous.parent_id != next.parent_id
+        || previous.alias != next.alias
+        || previous.anchors != next.anchors
+    {
+        kinds.push(EventKind::Updated);
+    }
+    kinds
+}
+
+/// The event of a move from `from` to `to`, if the move means one.
+pub fn on_move(from: Bucket, to: Bucket) -> Option<EventKind> {
+    match (from, to) {
+        (Bucket::Active, Bucket::Archived) => Some(EventKind::Archived),
+        (Bucket::Archived, Bucket::Active) => Some(EventKind::Unarchived),
+        (_, Bucket::Deleted) => Some(EventKind::Deleted),
+        (Bucket::Deleted, _) => Some(EventKind::Restored),
+        _ => None,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::model::TodoStatus;
+
+    fn written(content: &str) -> Metadata {
+        let mut metadata = Metadata::new("Pad".into());
+        metadata.checksum = Some(content_checksum(content));
+        metadata
+    }
+
+    #[test]
+    fn writes_are_created_updated_or_pinned() {
+        let previous = written("one");
+        assert_eq!(
+            on_save(None, &previous, Some("one")),
+            vec![EventKind::Created]
+        );
+
+        let mut next = previous.clone();
+        next.is_pinned = true;
+        next.status = TodoStatus::Done;
+        assert_eq!(
+            on_save(Some(&previous), &next, None),
+            vec![EventKind::Pinned, EventKind::Updated]
+        );
+        assert_eq!(
+            on_save(Some(&previous), &previous, Some("two")),
+            vec![EventKind::Updated]
+        );
+    }
+
+    #[test]
+    fn reading_a_pad_is_not_an_update() {
+        let previous = written("one");
+        let mut next = previous.clone();
+        next.last_accessed_at = Some(Utc::now());
+        assert!(on_save(Some(&previous), &next, None).is_empty());
+        assert!(on_save(Some(&previous), &next, Some("one")).is_empty());
+    }
+
+    #[test]
+    fn events_serialize_as_flat_objects() {
+        let event = StoreEvent::new(EventKind::Purged, &written("x"), Scope::Global);
+        let value = serde_json::to_value(&event).unwrap();
+        assert_eq!(value["event"], "purged");
+        assert_eq!(value["scope"], "global");
+        assert_eq!(value["title"], "Pad");
+    }
+}
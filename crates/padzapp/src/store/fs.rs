@@ -1,6 +1,9 @@
+use super::backend::StorageBackend;
 use super::bucketed::BucketedStore;
+use super::encryption;
 use super::fs_backend::FsBackend;
-use super::pad_store::PadStore;
+use crate::error::Result;
+use crate::model::Scope;
 use std::path::PathBuf;
 
 pub type FileStore = BucketedStore<FsBackend>;
@@ -33,10 +36,8 @@ impl FileStore {
     }
 
     pub fn with_format(mut self, ext: &str) -> Self {
-        self.active = PadStore::with_backend(self.active.backend.with_format(ext));
-        self.archived = PadStore::with_backend(self.archived.backend.with_format(ext));
-        self.deleted = PadStore::with_backend(self.deleted.backend.with_format(ext));
         // tag_backend doesn't need format (no content files)
+        self.set_format(ext);
         self
     }
 
@@ -46,7 +47,270 @@ impl FileStore {
         self.deleted.backend.set_format(ext);
     }
 
+    /// Keep bodies over `compress_above` bytes gzipped (see
+    /// [`super::compression`]).
+    pub fn with_compression(mut self, compress_above: Option<u64>) -> Self {
+        self.active.backend.set_compression(compress_above);
+        self.archived.backend.set_compression(compress_above);
+        self.deleted.backend.set_compression(compress_above);
+        self
+    }
+
+    /// Read encrypted bodies with the key at `key_path`, and write them
+    /// encrypted in every scope whose store is marked for it (see
+    /// [`super::encryption`]).
+    pub fn with_encryption(mut self, key_path: PathBuf) -> Self {
+        for backend in self.pad_backends_mut() {
+            backend.set_key_path(key_path.clone());
+        }
+        for scope in [Scope::Project, Scope::Global] {
+            let enabled = self
+                .tag_backend
+                .scope_root(scope)
+                .is_some_and(|root| encryption::is_enabled(&root));
+            self.set_encrypted(scope, enabled);
+        }
+        self
+    }
+
+    /// Write `scope`'s bodies encrypted from now on, or in the clear. Bodies
+    /// already written keep their form until [`Self::rewrite_bodies`].
+    pub fn set_encrypted(&mut self, scope: Scope, encrypted: bool) {
+        for backend in self.pad_backends_mut() {
+            backend.set_encrypted(scope, encrypted);
+        }
+    }
+
+    /// Rewrites every body of `scope`, in every bucket, that is not in the
+    /// form the store now writes, and returns how many there were. A dry run
+    /// only counts them.
+    pub fn rewrite_bodies(&self, scope: Scope, dry_run: bool) -> Result<usize> {
+        let mut rewritten = 0;
+        for backend in [
+            &self.active.backend,
+            &self.archived.backend,
+            &self.deleted.backend,
+        ] {
+            if !backend.scope_available(scope) {
+                continue;
+            }
+            for id in backend.list_content_ids(scope)? {
+                if backend.needs_rewrite(&id, scope)? {
+                    if !dry_run {
+                        backend.rewrite(&id, scope)?;
+                    }
+                    rewritten += 1;
+                }
+            }
+        }
+        Ok(rewritten)
+    }
+
+    /// The files the writes held so far would touch, sorted: after a dry
+    /// run, what it would have written or removed.
+    pub fn held_files(&self) -> Result<Vec<PathBuf>> {
+        let mut files = Vec::new();
+        for guard in [
+            &self.active.backend,
+            &self.archived.backend,
+            &self.deleted.backend,
+            &self.tag_backend,
+        ] {
+            for (scope, writes) in guard.held_writes() {
+                let Some(root) = guard.scope_root(scope) else {
+                    continue;
+                };
+                if writes.index.is_some() {
+                    files.push(root.join("data.json"));
+                }
+                if writes.tags.is_some() {
+                    files.push(root.join("tags.json"));
+                }
+                for id in writes.content.keys() {
+                    files.push(guard.content_path(id, scope)?);
+                }
+            }
+        }
+        files.sort();
+        files.dedup();
+        Ok(files)
+    }
+
+    fn pad_backends_mut(&mut self) -> [&mut FsBackend; 3] {
+        [
+            &mut *self.active.backend,
+            &mut *self.archived.backend,
+            &mut *self.deleted.backend,
+        ]
+    }
+
     pub fn format_ext(&self) -> &str {
         self.active.backend.format_ext()
     }
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::error::StoreWarning;
+    use crate::model::{Pad, Scope};
+    use crate::store::{Bucket, DataStore};
+
+    #[test]
+    fn an_unreadable_pad_fails_the_listing_unless_errors_are_ignored() {
+        let temp = tempfile::tempdir().unwrap();
+        let mut store = FileStore::new_fs(
+            Some(temp.path().join("project")),
+            temp.path().join("global"),
+        );
+        let readable = Pad::new("Readable".into(), "Body".into());
+        let broken = Pad::new("Broken".into(), "Body".into());
+        store
+            .save_pad(&readable, Scope::Project, Bucket::Active)
+            .unwrap();
+        store
+            .save_pad(&broken, Scope::Project, Bucket::Active)
+            .unwrap();
+        let path = store
+            .get_pad_path(&broken.metadata.id, Scope::Project, Bucket::Active)
+            .unwrap();
+        std::fs::write(path, [0xff, 0xfe, 0x00]).unwrap();
+
+        assert!(store.list_pads(Scope::Project, Bucket::Active).is_err());
+
+        store.set_ignore_errors(true);
+        let pads = store.list_pads(Scope::Project, Bucket::Active).unwrap();
+        assert_eq!(pads.len(), 1);
+        assert_eq!(pads[0].metadata.title, "Readable");
+        assert!(store.take_warnings().iter().any(|w| matches!(
+            w,
+            StoreWarning::UnreadablePad { title, .. } if title == "Broken"
+        )));
+    }
+
+    #[test]
+    fn a_dry_run_names_the_files_it_would_have_written() {
+        let temp = tempfile::tempdir().unwrap();
+        let project = temp.path().join("project");
+        let mut store = FileStore::new_fs(Some(project.clone()), temp.path().join("global"));
+        assert!(store.held_files().unwrap().is_empty());
+
+        store.arm_dry_run();
+        let pad = Pad::new("Imagined".into(), "Body".into());
+        store
+            .save_pad(&pad, Scope::Project, Bucket::Active)
+            .unwrap();
+
+        assert_eq!(
+            store.held_files().unwrap(),
+            vec![
+                project.join("active").join("data.json"),
+                project
+                    .join("active")
+                    .join(format!("pad-{}.txt", pad.metadata.id)),
+            ]
+        );
+        assert!(!project.join("active").join("data.json").exists());
+    }
+
+    #[test]
+    fn large_bodies_are_compressed_in_every_bucket() {
+        let temp = tempfile::tempdir().unwrap();
+        let mut store = FileStore::new_fs(
+            Some(temp.path().join("project")),
+            temp.path().join("global"),
+        )
+        .with_compression(Some(100));
+        let body = "error: flaky test\n".repeat(50);
+        let mut pad = Pad::new("Build log".into(), body);
+        let id = pad.metadata.id;
+        store
+            .save_pad(&pad, Scope::Project, Bucket::Active)
+            .unwrap();
+        let path =
+            |store: &FileStore, bucket| store.get_pad_path(&id, Scope::Project, bucket).unwrap();
+        assert!(path(&store, Bucket::Active).ends_with(format!("pad-{}.txt.gz", id)));
+        assert_eq!(
+            store
+                .get_pad(&id, Scope::Project, Bucket::Active)
+                .unwrap()
+                .content,
+            pad.content
+        );
+
+        store
+            .move_pad(&id, Scope::Project, Bucket::Active, Bucket::Archived)
+            .unwrap();
+        assert!(path(&store, Bucket::Archived).ends_with(format!("pad-{}.txt.gz", id)));
+        let archived = store
+            .get_pad(&id, Scope::Project, Bucket::Archived)
+            .unwrap();
+        assert_eq!(archived.content, pad.content);
+        assert_eq!(
+            store
+                .list_pads(Scope::Project, Bucket::Archived)
+                .unwrap()
+                .len(),
+            1
+        );
+
+        store
+            .move_pad(&id, Scope::Project, Bucket::Archived, Bucket::Active)
+            .unwrap();
+        assert!(path(&store, Bucket::Active).ends_with(format!("pad-{}.txt.gz", id)));
+        assert!(!path(&store, Bucket::Archived).exists());
+
+        // Shrunk below the threshold, the body is written plain again.
+        pad.content = "Build log\n\nfixed".into();
+        store
+            .save_pad(&pad, Scope::Project, Bucket::Active)
+            .unwrap();
+        assert!(path(&store, Bucket::Active).ends_with(format!("pad-{}.txt", id)));
+    }
+
+    #[test]
+    fn bodies_are_rewritten_encrypted_and_back() {
+        let temp = tempfile::tempdir().unwrap();
+        let mut store = FileStore::new_fs(
+            Some(temp.path().join("project")),
+            temp.path().join("global"),
+        )
+        .with_encryption(temp.path().join("secret.key"));
+        let pad = Pad::new("Plans".into(), "Launch on friday".into());
+        let id = pad.metadata.id;
+        store
+            .save_pad(&pad, Scope::Project, Bucket::Active)
+            .unwrap();
+        let path = |store: &FileStore| {
+            store
+                .get_pad_path(&id, Scope::Project, Bucket::Active)
+                .unwrap()
+        };
+        let plain = path(&store);
+        // Reading settles the pad's time on its file's; rewriting keeps both.
+        let updated = |store: &FileStore| {
+            store.list_pads(Scope::Project, Bucket::Active).unwrap()[0]
+                .metadata
+                .updated_at
+        };
+        let settled = updated(&store);
+
+        store.set_encrypted(Scope::Project, true);
+        assert_eq!(store.rewrite_bodies(Scope::Project, true).unwrap(), 1);
+        assert!(plain.exists());
+        assert_eq!(store.rewrite_bodies(Scope::Project, false).unwrap(), 1);
+        let sealed = path(&store);
+        assert!(sealed.ends_with(format!("pad-{}.txt.enc", id)));
+        assert!(!plain.exists());
+        assert!(!String::from_utf8_lossy(&std::fs::read(&sealed).unwrap()).contains("friday"));
+        let read = store.get_pad(&id, Scope::Project, Bucket::Active).unwrap();
+        assert_eq!(read.content, pad.content);
+        assert_eq!(updated(&store), settled);
+        assert_eq!(store.rewrite_bodies(Scope::Project, false).unwrap(), 0);
+
+        store.set_encrypted(Scope::Project, false);
+        assert_eq!(store.rewrite_bodies(Scope::Project, false).unwrap(), 1);
+        assert_eq!(path(&store), plain);
+        assert!(!sealed.exists());
+    }
+}
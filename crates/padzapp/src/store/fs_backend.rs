@@ -1,18 +1,38 @@
 use super::backend::StorageBackend;
+use super::compression::{self, COMPRESSED_EXT};
+use super::encryption::{self, ENCRYPTED_EXT};
 use crate::error::{PadzError, Result};
 use crate::model::{Metadata, Scope};
+use crate::secrets::SecretKey;
 use crate::tags::TagEntry;
 use chrono::{DateTime, Utc};
+use std::cell::OnceCell;
 use std::collections::HashMap;
 use std::fs;
+use std::io::{BufReader, BufWriter, Write};
 use std::path::{Path, PathBuf};
 use std::time::SystemTime;
 use uuid::Uuid;
 
+/// Name of the transaction journal at a scope root (see [`super::journal`]).
+pub const JOURNAL_FILE: &str = "journal.json";
+
+/// Name of the last commit's undo record at a scope root (see [`super::undo`]).
+pub const UNDO_FILE: &str = "undo.json";
+
 pub struct FsBackend {
     project_root: Option<PathBuf>,
     global_root: PathBuf,
     format: String,
+    /// Bodies larger than this many bytes are written gzipped (see
+    /// [`super::compression`]); `None` never compresses.
+    compress_above: Option<u64>,
+    /// Where the key for encrypted bodies is kept (see
+    /// [`super::encryption`]), loaded the first time one is read or written.
+    key_path: Option<PathBuf>,
+    key: OnceCell<SecretKey>,
+    /// Scopes whose bodies are written encrypted.
+    encrypted: Vec<Scope>,
 }
 
 impl FsBackend {
@@ -21,7 +41,79 @@ impl FsBackend {
             project_root,
             global_root,
             format: ".txt".to_string(),
+            compress_above: None,
+            key_path: None,
+            key: OnceCell::new(),
+            encrypted: Vec::new(),
+        }
+    }
+
+    pub fn with_compression(mut self, compress_above: Option<u64>) -> Self {
+        self.set_compression(compress_above);
+        self
+    }
+
+    pub fn set_compression(&mut self, compress_above: Option<u64>) {
+        self.compress_above = compress_above;
+    }
+
+    /// Read encrypted bodies with the key at `key_path`.
+    pub fn set_key_path(&mut self, key_path: PathBuf) {
+        self.key_path = Some(key_path);
+    }
+
+    /// Write `scope`'s bodies encrypted, or in the clear.
+    pub fn set_encrypted(&mut self, scope: Scope, encrypted: bool) {
+        self.encrypted.retain(|s| *s != scope);
+        if encrypted {
+            self.encrypted.push(scope);
+        }
+    }
+
+    /// The directory holding `scope`'s files, if the scope is available.
+    pub fn scope_root(&self, scope: Scope) -> Option<PathBuf> {
+        self.get_store_path_by_scope(scope).ok()
+    }
+
+    fn key(&self) -> Result<&SecretKey> {
+        if let Some(key) = self.key.get() {
+            return Ok(key);
         }
+        let path = self.key_path.as_ref().ok_or_else(|| {
+            PadzError::Secret("a pad is encrypted, but no secret key is configured".into())
+        })?;
+        let key = SecretKey::load_or_create(path)?;
+        Ok(self.key.get_or_init(|| key))
+    }
+
+    /// Whether pad `id`'s file is in a different form, encrypted or in the
+    /// clear, from the one [`StorageBackend::write_content`] would write now.
+    pub fn needs_rewrite(&self, id: &Uuid, scope: Scope) -> Result<bool> {
+        let root = self.get_store_path_by_scope(scope)?;
+        Ok(self
+            .find_pad_file(&root, id)
+            .is_some_and(|path| encryption::is_encrypted(&path) != self.encrypted.contains(&scope)))
+    }
+
+    /// Rewrites pad `id`'s body in the form the backend writes now, keeping
+    /// the file's modification time: the pad has not changed, only its form.
+    pub fn rewrite(&self, id: &Uuid, scope: Scope) -> Result<()> {
+        let root = self.get_store_path_by_scope(scope)?;
+        let Some(path) = self.find_pad_file(&root, id) else {
+            return Ok(());
+        };
+        let modified = fs::metadata(&path).and_then(|meta| meta.modified())?;
+        let Some(content) = self.read_content(id, scope)? else {
+            return Ok(());
+        };
+        self.write_content(id, scope, &content)?;
+        if let Some(path) = self.find_pad_file(&root, id) {
+            fs::File::options()
+                .write(true)
+                .open(path)
+                .and_then(|file| file.set_modified(modified))?;
+        }
+        Ok(())
     }
 
     pub fn with_format(mut self, ext: &str) -> Self {
@@ -68,11 +160,17 @@ impl FsBackend {
     /// for any file matching `pad-{uuid}.*`. This supports mixed-format stores
     /// where different pads may have been created with different format settings.
     fn find_pad_file(&self, root: &Path, id: &Uuid) -> Option<PathBuf> {
-        // 1. Try configured format (fast path)
+        // 1. Try configured format (fast path), plain, compressed or encrypted
         let path = root.join(self.pad_filename(id));
         if path.exists() {
             return Some(path);
         }
+        for suffix in [COMPRESSED_EXT, ENCRYPTED_EXT] {
+            let packed = root.join(format!("{}{}", self.pad_filename(id), suffix));
+            if packed.exists() {
+                return Some(packed);
+            }
+        }
 
         // 2. Scan directory for any matching file (mixed-format support)
         let prefix = format!("pad-{}", id);
@@ -96,9 +194,11 @@ impl StorageBackend for FsBackend {
         if !data_file.exists() {
             return Ok(HashMap::new());
         }
-        let content = fs::read_to_string(data_file).map_err(PadzError::Io)?;
+        // Parsed straight from the file: a large index is never held twice,
+        // once as text and once as metadata.
+        let file = fs::File::open(data_file).map_err(PadzError::Io)?;
         let meta: HashMap<Uuid, Metadata> =
-            serde_json::from_str(&content).map_err(PadzError::Serialization)?;
+            serde_json::from_reader(BufReader::new(file)).map_err(PadzError::Serialization)?;
         Ok(meta)
     }
 
@@ -107,11 +207,21 @@ impl StorageBackend for FsBackend {
         self.ensure_dir(&root)?;
 
         let data_file = root.join("data.json");
-        let content = serde_json::to_string_pretty(index).map_err(PadzError::Serialization)?;
 
-        // Atomic write for index too
+        // Atomic write for index too, serialized straight into the file
         let tmp_file = root.join(format!(".data-{}.tmp", Uuid::new_v4()));
-        fs::write(&tmp_file, content).map_err(PadzError::Io)?;
+        let written = fs::File::create(&tmp_file)
+            .map_err(PadzError::Io)
+            .and_then(|file| {
+                let mut writer = BufWriter::new(file);
+                serde_json::to_writer_pretty(&mut writer, index)
+                    .map_err(PadzError::Serialization)?;
+                writer.flush().map_err(PadzError::Io)
+            });
+        if let Err(e) = written {
+            let _ = fs::remove_file(&tmp_file);
+            return Err(e);
+        }
         fs::rename(&tmp_file, &data_file).map_err(PadzError::Io)?;
 
         Ok(())
@@ -144,10 +254,71 @@ impl StorageBackend for FsBackend {
         Ok(())
     }
 
+    fn load_journal(&self, scope: Scope) -> Result<Option<String>> {
+        let root = self.get_store_path_by_scope(scope)?;
+        let journal_file = root.join(JOURNAL_FILE);
+        if !journal_file.exists() {
+            return Ok(None);
+        }
+        let content = fs::read_to_string(journal_file).map_err(PadzError::Io)?;
+        Ok(Some(content))
+    }
+
+    fn save_journal(&self, scope: Scope, journal: &str) -> Result<()> {
+        let root = self.get_store_path_by_scope(scope)?;
+        self.ensure_dir(&root)?;
+
+        // Atomic write
+        let tmp_file = root.join(format!(".journal-{}.tmp", Uuid::new_v4()));
+        fs::write(&tmp_file, journal).map_err(PadzError::Io)?;
+        fs::rename(&tmp_file, root.join(JOURNAL_FILE)).map_err(PadzError::Io)?;
+
+        Ok(())
+    }
+
+    fn clear_journal(&self, scope: Scope) -> Result<()> {
+        let root = self.get_store_path_by_scope(scope)?;
+        let journal_file = root.join(JOURNAL_FILE);
+        if journal_file.exists() {
+            fs::remove_file(journal_file).map_err(PadzError::Io)?;
+        }
+        Ok(())
+    }
+
+    fn load_undo(&self, scope: Scope) -> Result<Option<String>> {
+        let root = self.get_store_path_by_scope(scope)?;
+        let undo_file = root.join(UNDO_FILE);
+        if !undo_file.exists() {
+            return Ok(None);
+        }
+        let content = fs::read_to_string(undo_file).map_err(PadzError::Io)?;
+        Ok(Some(content))
+    }
+
+    fn save_undo(&self, scope: Scope, record: &str) -> Result<()> {
+        let root = self.get_store_path_by_scope(scope)?;
+        self.ensure_dir(&root)?;
+
+        // Atomic write
+        let tmp_file = root.join(format!(".undo-{}.tmp", Uuid::new_v4()));
+        fs::write(&tmp_file, record).map_err(PadzError::Io)?;
+        fs::rename(&tmp_file, root.join(UNDO_FILE)).map_err(PadzError::Io)?;
+
+        Ok(())
+    }
+
     fn read_content(&self, id: &Uuid, scope: Scope) -> Result<Option<String>> {
         let root = self.get_store_path_by_scope(scope)?;
         if let Some(path) = self.find_pad_file(&root, id) {
-            let content = fs::read_to_string(path).map_err(PadzError::Io)?;
+            let content = if compression::is_compressed(&path) {
+                let packed = fs::read(path).map_err(PadzError::Io)?;
+                compression::decompress(&packed).map_err(PadzError::Io)?
+            } else if encryption::is_encrypted(&path) {
+                let sealed = fs::read(path).map_err(PadzError::Io)?;
+                encryption::decrypt(&sealed, self.key()?)?
+            } else {
+                fs::read_to_string(path).map_err(PadzError::Io)?
+            };
             Ok(Some(content))
         } else {
             Ok(None)
@@ -160,16 +331,40 @@ impl StorageBackend for FsBackend {
 
         // Use existing file path if pad already exists (preserves original extension).
         // Otherwise use configured format for new pads.
-        let target_path = if let Some(existing) = self.find_pad_file(&root, id) {
-            existing
+        let existing = self.find_pad_file(&root, id);
+        let plain_path = match &existing {
+            Some(path) => super::plain_path(path),
+            None => root.join(self.pad_filename(id)),
+        };
+        let with_suffix = |suffix: &str| {
+            let mut name = plain_path.clone().into_os_string();
+            name.push(suffix);
+            PathBuf::from(name)
+        };
+        let packed;
+        let (target_path, bytes) = if self.encrypted.contains(&scope) {
+            packed = encryption::encrypt(content, self.key()?)?;
+            (with_suffix(ENCRYPTED_EXT), packed.as_slice())
+        } else if self
+            .compress_above
+            .is_some_and(|limit| content.len() as u64 > limit)
+        {
+            packed = compression::compress(content).map_err(PadzError::Io)?;
+            (with_suffix(COMPRESSED_EXT), packed.as_slice())
         } else {
-            root.join(self.pad_filename(id))
+            (plain_path, content.as_bytes())
         };
 
         // Atomic Write
         let tmp_path = root.join(format!(".pad-{}.tmp", Uuid::new_v4()));
-        fs::write(&tmp_path, content).map_err(PadzError::Io)?;
-        fs::rename(&tmp_path, target_path).map_err(PadzError::Io)?;
+        fs::write(&tmp_path, bytes).map_err(PadzError::Io)?;
+        fs::rename(&tmp_path, &target_path).map_err(PadzError::Io)?;
+
+        // A body that crossed the threshold, or was encrypted or decrypted,
+        // leaves its other form behind.
+        if let Some(old) = existing.filter(|old| *old != target_path) {
+            fs::remove_file(old).map_err(PadzError::Io)?;
+        }
 
         Ok(())
     }
@@ -196,9 +391,10 @@ impl StorageBackend for FsBackend {
             let path = entry.path();
             if path.is_file() {
                 if let Some(name) = path.file_name().and_then(|s| s.to_str()) {
-                    if name.starts_with("pad-") {
-                        let stem = path.file_stem().and_then(|s| s.to_str()).unwrap_or("");
-                        let uuid_part = stem.strip_prefix("pad-").unwrap_or("");
+                    if let Some(rest) = name.strip_prefix("pad-") {
+                        // `pad-{uuid}.{ext}`, `pad-{uuid}.{ext}.gz` compressed,
+                        // or `pad-{uuid}.{ext}.enc` encrypted
+                        let uuid_part = rest.split('.').next().unwrap_or("");
                         if let Ok(id) = Uuid::parse_str(uuid_part) {
                             ids.push(id);
                         }
@@ -0,0 +1,172 @@
+//! # Garbage Collection
+//!
+//! Files in a store that nothing refers to any more, which `padz maintain`
+//! finds and removes:
+//!
+//! - **Interrupted writes.** Every file padz writes is written aside, as
+//!   `.{name}-{uuid}.tmp`, and renamed into place; a crash in between leaves
+//!   the aside file behind. One older than [`STALE_AFTER_HOURS`] belongs to no
+//!   running write.
+//! - **Superseded bodies.** A pad crossing the compression threshold, or
+//!   encrypted or decrypted, is written in its new form before the old one is
+//!   removed (see the compression and encryption modules); a crash in between
+//!   leaves both, and the older is stale.
+//!
+//! Pad files the index does not list are not garbage: files are truth, and the
+//! next listing adopts them (see [`reconcile_index`](super::pad_store)).
+
+use super::plain_path;
+use chrono::{DateTime, Duration, Utc};
+use serde::Serialize;
+use std::collections::HashMap;
+use std::fs;
+use std::io;
+use std::path::{Path, PathBuf};
+
+/// How old an aside file must be before it is taken for a crashed write.
+pub const STALE_AFTER_HOURS: i64 = 1;
+
+/// The directories of a scope that hold pad files.
+const BUCKET_DIRS: [&str; 3] = ["active", "archived", "deleted"];
+
+/// Why a file is garbage.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "snake_case")]
+pub enum GarbageKind {
+    InterruptedWrite,
+    SupersededBody,
+}
+
+/// One file nothing refers to.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct Garbage {
+    pub path: PathBuf,
+    pub kind: GarbageKind,
+    /// What removing it reclaims.
+    pub bytes: u64,
+}
+
+/// The garbage under `scope_root`: its own files and its buckets'.
+pub fn find(scope_root: &Path, now: DateTime<Utc>) -> io::Result<Vec<Garbage>> {
+    let mut garbage = Vec::new();
+    let dirs = std::iter::once(scope_root.to_path_buf())
+        .chain(BUCKET_DIRS.iter().map(|bucket| scope_root.join(bucket)));
+    for dir in dirs {
+        let Ok(entries) = fs::read_dir(&dir) else {
+            continue;
+        };
+        // Pad files by the name of their plain form, to pair a body with its
+        // compressed or encrypted copy.
+        let mut bodies: HashMap<PathBuf, Vec<(PathBuf, fs::Metadata)>> = HashMap::new();
+        for entry in entries {
+            let path = entry?.path();
+            let meta = fs::metadata(&path)?;
+            if !meta.is_file() {
+                continue;
+            }
+            let name = path.file_name().unwrap_or_default().to_string_lossy();
+            if name.starts_with('.') && name.ends_with(".tmp") {
+                let modified: DateTime<Utc> = meta.modified()?.into();
+                if now - modified > Duration::hours(STALE_AFTER_HOURS) {
+                    garbage.push(Garbage {
+                        bytes: meta.len(),
+                        path,
+                        kind: GarbageKind::InterruptedWrite,
+                    });
+                }
+            } else if name.starts_with("pad-") {
+                bodies
+                    .entry(plain_path(&path))
+                    .or_default()
+                    .push((path, meta));
+            }
+        }
+        for mut forms in bodies.into_values().filter(|forms| forms.len() > 1) {
+            forms.sort_by_key(|(_, meta)| meta.modified().ok());
+            let (path, meta) = forms.remove(0);
+            garbage.push(Garbage {
+                path,
+                kind: GarbageKind::SupersededBody,
+                bytes: meta.len(),
+            });
+        }
+    }
+    garbage.sort_by(|a, b| a.path.cmp(&b.path));
+    Ok(garbage)
+}
+
+/// Removes what [`find`] found. A file already gone is not an error: another
+/// padz may have collected it first.
+pub fn remove(garbage: &[Garbage]) -> io::Result<()> {
+    for item in garbage {
+        match fs::remove_file(&item.path) {
+            Err(e) if e.kind() != io::ErrorKind::NotFound => return Err(e),
+            _ => {}
+        }
+    }
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::store::compression;
+    use std::time::SystemTime;
+    use tempfile::TempDir;
+
+    fn age(path: &Path, hours: u64) {
+        let then = SystemTime::now() - std::time::Duration::from_secs(hours * 3600);
+        fs::File::options()
+            .write(true)
+            .open(path)
+            .unwrap()
+            .set_modified(then)
+            .unwrap();
+    }
+
+    #[test]
+    fn stale_aside_files_are_garbage_and_fresh_ones_are_not() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path();
+        fs::create_dir_all(root.join("active")).unwrap();
+        let stale = root.join("active/.data-1.tmp");
+        fs::write(&stale, "{}").unwrap();
+        age(&stale, 2);
+        fs::write(root.join(".tags-2.tmp"), "[]").unwrap();
+        fs::write(root.join("tags.json"), "[]").unwrap();
+
+        let garbage = find(root, Utc::now()).unwrap();
+
+        assert_eq!(
+            garbage,
+            vec![Garbage {
+                path: stale,
+                kind: GarbageKind::InterruptedWrite,
+                bytes: 2,
+            }]
+        );
+    }
+
+    #[test]
+    fn the_older_form_of_a_body_is_superseded() {
+        let temp = TempDir::new().unwrap();
+        let archived = temp.path().join("archived");
+        fs::create_dir_all(&archived).unwrap();
+        let plain = archived.join("pad-a.txt");
+        let packed = archived.join("pad-a.txt.gz");
+        fs::write(&plain, "old body").unwrap();
+        fs::write(&packed, compression::compress("new body").unwrap()).unwrap();
+        age(&plain, 1);
+        fs::write(archived.join("pad-b.txt"), "alone").unwrap();
+
+        let garbage = find(temp.path(), Utc::now()).unwrap();
+        assert_eq!(garbage.len(), 1);
+        assert_eq!(garbage[0].path, plain);
+        assert_eq!(garbage[0].kind, GarbageKind::SupersededBody);
+
+        remove(&garbage).unwrap();
+        assert!(!plain.exists());
+        assert!(packed.exists());
+        remove(&garbage).unwrap();
+    }
+}
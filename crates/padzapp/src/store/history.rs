@@ -0,0 +1,179 @@
+//! # Pad History
+//!
+//! Every pad carries a short log of what happened to it
+//! ([`Metadata::history`](crate::model::Metadata::history)), which `padz
+//! timeline` reads back. The store keeps it, not the commands: each write
+//! compares the incoming metadata with the index entry it replaces and appends
+//! an event for every change it sees, and a bucket move records where the pad
+//! went. A command therefore cannot forget to log, and a new command is logged
+//! for free.
+//!
+//! The index entry is the source of truth for the log. A caller that saves a
+//! stale copy of a pad twice does not erase the event its first save added.
+//!
+//! What counts:
+//!
+//! - **Edited** — the content checksum changed. A pad written for the first
+//!   time (the editor filling a just-created empty pad) is not an edit.
+//! - **Pinned / Unpinned**, **Status**, **Moved** (new parent) — metadata flips.
+//! - **Archived / Unarchived / Deleted / Restored** — bucket moves.
+//!
+//! Reads, tag changes and renames are not logged. The log keeps the latest
+//! [`HISTORY_LIMIT`] events; creation is never lost, as it is `created_at`.
+
+use super::integrity::content_checksum;
+use super::Bucket;
+use crate::model::{Metadata, PadEvent, PadEventKind};
+use chrono::{DateTime, Utc};
+
+/// Events kept per pad; older ones are dropped first.
+pub const HISTORY_LIMIT: usize = 100;
+
+/// Carries `previous`'s history into `next` and appends what changed between them.
+///
+/// `previous` is the index entry being replaced, `None` when the pad is new to
+/// this bucket — then `next` keeps the history it arrived with (a bucket move or
+/// an import brings its own).
+pub fn record(previous: Option<&Metadata>, next: &mut Metadata, at: DateTime<Utc>) {
+    let Some(previous) = previous else {
+        return;
+    };
+    next.history = previous.history.clone();
+
+    let mut changes = Vec::new();
+    let first_write = previous.checksum.as_deref() == Some(content_checksum("").as_str());
+    if previous.checksum.is_some() && previous.checksum != next.checksum && !first_write {
+        changes.push(PadEventKind::Edited);
+    }
+    if previous.is_pinned != next.is_pinned {
+        changes.push(if next.is_pinned {
+            PadEventKind::Pinned
+        } else {
+            PadEventKind::Unpinned
+        });
+    }
+    if previous.status != next.status {
+        changes.push(PadEventKind::Status {
+            status: next.status,
+        });
+    }
+    if previous.parent_id != next.parent_id {
+        changes.push(PadEventKind::Moved);
+    }
+    for kind in changes {
+        push(next, kind, at);
+    }
+}
+
+/// Appends the event for a move from `from` to `to`, if the move means one.
+pub fn record_move(metadata: &mut Metadata, from: Bucket, to: Bucket, at: DateTime<Utc>) {
+    let kind = match (from, to) {
+        (Bucket::Active, Bucket::Archived) => PadEventKind::Archived,
+        (Bucket::Archived, Bucket::Active) => PadEventKind::Unarchived,
+        (_, Bucket::Deleted) => PadEventKind::Deleted,
+        (Bucket::Deleted, _) => PadEventKind::Restored,
+        _ => return,
+    };
+    push(metadata, kind, at);
+}
+
+fn push(metadata: &mut Metadata, kind: PadEventKind, at: DateTime<Utc>) {
+    metadata.history.push(PadEvent { at, kind });
+    if metadata.history.len() > HISTORY_LIMIT {
+        let excess = metadata.history.len() - HISTORY_LIMIT;
+        metadata.history.drain(..excess);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::model::TodoStatus;
+
+    fn written(content: &str) -> Metadata {
+        let mut metadata = Metadata::new("Pad".into());
+        metadata.checksum = Some(content_checksum(content));
+        metadata
+    }
+
+    fn kinds(metadata: &Metadata) -> Vec<PadEventKind> {
+        metadata.history.iter().map(|e| e.kind.clone()).collect()
+    }
+
+    #[test]
+    fn new_pads_keep_the_history_they_arrive_with() {
+        let mut next = written("body");
+        record_move(&mut next, Bucket::Active, Bucket::Deleted, Utc::now());
+        record(None, &mut next, Utc::now());
+        assert_eq!(kinds(&next), vec![PadEventKind::Deleted]);
+    }
+
+    #[test]
+    fn content_and_metadata_changes_are_logged() {
+        let previous = written("one");
+        let mut next = previous.clone();
+        next.checksum = Some(content_checksum("two"));
+        next.is_pinned = true;
+        next.status = TodoStatus::Done;
+        next.parent_id = Some(uuid::Uuid::new_v4());
+
+        record(Some(&previous), &mut next, Utc::now());
+
+        assert_eq!(
+            kinds(&next),
+            vec![
+                PadEventKind::Edited,
+                PadEventKind::Pinned,
+                PadEventKind::Status {
+                    status: TodoStatus::Done
+                },
+                PadEventKind::Moved,
+            ]
+        );
+    }
+
+    #[test]
+    fn filling_an_empty_pad_is_not_an_edit() {
+        let previous = written("");
+        let mut next = previous.clone();
+        next.checksum = Some(content_checksum("first words"));
+        record(Some(&previous), &mut next, Utc::now());
+        assert!(next.history.is_empty());
+    }
+
+    #[test]
+    fn the_index_entry_owns_the_log() {
+        let mut previous = written("one");
+        record_move(&mut previous, Bucket::Deleted, Bucket::Active, Utc::now());
+        // A stale copy, without the Restored event, saved unchanged.
+        let mut stale = written("one");
+        record(Some(&previous), &mut stale, Utc::now());
+        assert_eq!(kinds(&stale), vec![PadEventKind::Restored]);
+    }
+
+    #[test]
+    fn bucket_moves_map_to_lifecycle_events() {
+        let cases = [
+            (Bucket::Active, Bucket::Archived, PadEventKind::Archived),
+            (Bucket::Archived, Bucket::Active, PadEventKind::Unarchived),
+            (Bucket::Archived, Bucket::Deleted, PadEventKind::Deleted),
+            (Bucket::Deleted, Bucket::Active, PadEventKind::Restored),
+        ];
+        for (from, to, expected) in cases {
+            let mut metadata = written("x");
+            record_move(&mut metadata, from, to, Utc::now());
+            assert_eq!(kinds(&metadata), vec![expected], "{from:?} -> {to:?}");
+        }
+    }
+
+    #[test]
+    fn history_is_capped_keeping_the_latest() {
+        let mut metadata = written("x");
+        for _ in 0..HISTORY_LIMIT + 5 {
+            push(&mut metadata, PadEventKind::Edited, Utc::now());
+        }
+        push(&mut metadata, PadEventKind::Pinned, Utc::now());
+        assert_eq!(metadata.history.len(), HISTORY_LIMIT);
+        assert_eq!(metadata.history.last().unwrap().kind, PadEventKind::Pinned);
+    }
+}
@@ -0,0 +1,75 @@
+//! # Content Integrity
+//!
+//! Every pad write records a checksum of the content file in the pad's index
+//! entry ([`Metadata::checksum`](crate::model::Metadata::checksum)). Checking the
+//! store recomputes them against what is on disk, without reconciling first —
+//! reconciliation would quietly adopt a changed or vanished file, which is
+//! exactly what a check must report instead.
+//!
+//! A mismatch means the file changed outside padz: disk or sync corruption, or
+//! a hand edit. Padz cannot tell those apart, so it reports and lets the user
+//! decide; [`DataStore::accept_checksums`](super::DataStore::accept_checksums)
+//! records the current contents as the new baseline.
+
+use super::Bucket;
+use serde::Serialize;
+use sha2::{Digest, Sha256};
+use uuid::Uuid;
+
+/// The checksum recorded for `content`: `sha256:<hex>`.
+pub fn content_checksum(content: &str) -> String {
+    format!("sha256:{:x}", Sha256::digest(content.as_bytes()))
+}
+
+/// What is wrong with one pad's stored content.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "snake_case")]
+pub enum IntegrityProblem {
+    /// The content no longer matches the recorded checksum.
+    Mismatch,
+    /// The index lists the pad but its content file is gone.
+    MissingContent,
+    /// A content file the index does not list.
+    Untracked,
+}
+
+/// One pad that failed the check.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct IntegrityIssue {
+    pub id: Uuid,
+    pub bucket: Bucket,
+    /// The indexed title; `None` for untracked files.
+    pub title: Option<String>,
+    pub problem: IntegrityProblem,
+}
+
+/// The result of checking one scope.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize)]
+pub struct IntegrityReport {
+    /// Indexed pads whose content was checked against a recorded checksum.
+    pub checked: usize,
+    /// Indexed pads with no checksum yet (written before checksums existed).
+    pub unrecorded: usize,
+    pub issues: Vec<IntegrityIssue>,
+}
+
+impl IntegrityReport {
+    /// Fold another bucket's report into this one.
+    pub fn merge(&mut self, other: IntegrityReport) {
+        self.checked += other.checked;
+        self.unrecorded += other.unrecorded;
+        self.issues.extend(other.issues);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn checksum_is_stable_and_content_sensitive() {
+        assert_eq!(content_checksum("Title"), content_checksum("Title"));
+        assert_ne!(content_checksum("Title"), content_checksum("Title "));
+        assert!(content_checksum("").starts_with("sha256:"));
+    }
+}
@@ -0,0 +1,46 @@
+//! # Transactions
+//!
+//! Compound operations — moving a pad between buckets, deleting a parent with its
+//! children, purging, tagging many pads — make several store writes. Run inside
+//! [`transaction`](super::transaction), those writes are held by each bucket's
+//! [`WriteGuard`](super::write_guard::WriteGuard) and reach disk together on
+//! commit, or not at all on error.
+//!
+//! "Together" is made crash-safe with a redo journal:
+//!
+//! 1. On commit, every held write of a scope is serialized into one [`Journal`]
+//!    and saved atomically as `journal.json` at the scope root.
+//! 2. The writes are applied to the buckets.
+//! 3. The journal is removed.
+//!
+//! A crash before step 1 loses the whole operation; a crash after it leaves a
+//! journal behind, which the next open replays
+//! ([`BucketedStore::recover`](super::bucketed::BucketedStore::recover)).
+//! Replaying is idempotent — the journal holds whole index files and whole
+//! contents, not edits — so a crash during replay is harmless too.
+
+use super::write_guard::StagedWrites;
+use serde::{Deserialize, Serialize};
+
+/// Everything one commit writes to one scope.
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+pub struct Journal {
+    #[serde(default, skip_serializing_if = "StagedWrites::is_empty")]
+    pub active: StagedWrites,
+    #[serde(default, skip_serializing_if = "StagedWrites::is_empty")]
+    pub archived: StagedWrites,
+    #[serde(default, skip_serializing_if = "StagedWrites::is_empty")]
+    pub deleted: StagedWrites,
+    /// Writes at the scope root: the tag registry.
+    #[serde(default, skip_serializing_if = "StagedWrites::is_empty")]
+    pub root: StagedWrites,
+}
+
+impl Journal {
+    pub fn is_empty(&self) -> bool {
+        self.active.is_empty()
+            && self.archived.is_empty()
+            && self.deleted.is_empty()
+            && self.root.is_empty()
+    }
+}
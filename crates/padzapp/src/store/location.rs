@@ -0,0 +1,184 @@
+//! # Store Location
+//!
+//! A project store records where it lives:
+//!
+//! ```text
+//! .padz/
+//! └── location.json       # {"path": "/home/me/code/app/.padz"}
+//! ```
+//!
+//! Pads carry absolute paths — the directory a pad was created from
+//! ([`crate::model::CreationContext::cwd`]) — that go stale when the project
+//! directory is moved or renamed. Comparing the recorded location with the
+//! one the store was opened at is how a move is noticed: [`check`] runs on
+//! every project open (from [`crate::init`]), and a mismatch comes back as a
+//! [`Relocation`] for the caller to report. Remapping the stale paths is
+//! `doctor`'s job ([`crate::commands::doctor`]), which then calls [`record`].
+//!
+//! A mismatch whose recorded location still holds a store is a copy, not a
+//! move: the old paths still point at a real project, so nothing is stale and
+//! the copy simply starts recording its own location. A store that has never
+//! recorded one (created before this file existed) records it on first open.
+
+use serde::{Deserialize, Serialize};
+use std::fs;
+use std::io;
+use std::path::{Path, PathBuf};
+
+/// Name of the location file at a project store's root.
+pub const LOCATION_FILE: &str = "location.json";
+
+#[derive(Serialize, Deserialize)]
+struct Location {
+    path: PathBuf,
+}
+
+/// A project that moved since its store last recorded where it was.
+///
+/// `from` and `to` are project roots (the directories holding the store), the
+/// prefix that paths recorded on pads start with.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct Relocation {
+    pub from: PathBuf,
+    pub to: PathBuf,
+}
+
+impl Relocation {
+    /// `path` moved along with the project, or `None` when it was not inside it.
+    pub fn remap(&self, path: &Path) -> Option<PathBuf> {
+        let rest = path.strip_prefix(&self.from).ok()?;
+        if rest.as_os_str().is_empty() {
+            Some(self.to.clone())
+        } else {
+            Some(self.to.join(rest))
+        }
+    }
+}
+
+/// Compares the store at `padz_dir` with where it last recorded being.
+///
+/// Records the current location when there is nothing to report (first open,
+/// or a copy), so only a real move is returned — and keeps being returned on
+/// every open until `doctor` handles it.
+pub fn check(padz_dir: &Path) -> io::Result<Option<Relocation>> {
+    let here = canonical(padz_dir);
+    let Some(recorded) = read(padz_dir)? else {
+        record(padz_dir)?;
+        return Ok(None);
+    };
+    if canonical(&recorded) == here {
+        return Ok(None);
+    }
+    if recorded.is_dir() {
+        record(padz_dir)?;
+        return Ok(None);
+    }
+    Ok(Some(Relocation {
+        from: project_root(&recorded),
+        to: project_root(&here),
+    }))
+}
+
+/// Records `padz_dir` as the store's location.
+pub fn record(padz_dir: &Path) -> io::Result<()> {
+    let location = Location {
+        path: canonical(padz_dir),
+    };
+    let json = serde_json::to_string_pretty(&location).map_err(io::Error::other)?;
+    fs::write(padz_dir.join(LOCATION_FILE), json)
+}
+
+fn read(padz_dir: &Path) -> io::Result<Option<PathBuf>> {
+    match fs::read_to_string(padz_dir.join(LOCATION_FILE)) {
+        Ok(json) => {
+            let location: Location = serde_json::from_str(&json)
+                .map_err(|e| io::Error::new(io::ErrorKind::InvalidData, e))?;
+            Ok(Some(location.path))
+        }
+        Err(e) if e.kind() == io::ErrorKind::NotFound => Ok(None),
+        Err(e) => Err(e),
+    }
+}
+
+/// `path` with symlinks resolved, so `/tmp` and `/private/tmp` are one place.
+/// A path that no longer exists is kept as written.
+fn canonical(path: &Path) -> PathBuf {
+    fs::canonicalize(path).unwrap_or_else(|_| path.to_path_buf())
+}
+
+fn project_root(padz_dir: &Path) -> PathBuf {
+    padz_dir
+        .parent()
+        .map(Path::to_path_buf)
+        .unwrap_or_else(|| padz_dir.to_path_buf())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    fn store_at(root: &Path) -> PathBuf {
+        let padz = root.join(".padz");
+        fs::create_dir_all(&padz).unwrap();
+        padz
+    }
+
+    #[test]
+    fn test_first_open_records_and_an_unmoved_store_is_quiet() {
+        let temp = TempDir::new().unwrap();
+        let padz = store_at(&temp.path().join("app"));
+
+        assert_eq!(check(&padz).unwrap(), None);
+        assert!(padz.join(LOCATION_FILE).exists());
+        assert_eq!(check(&padz).unwrap(), None);
+    }
+
+    #[test]
+    fn test_a_moved_store_reports_until_recorded() {
+        let temp = TempDir::new().unwrap();
+        let old_root = temp.path().join("app");
+        check(&store_at(&old_root)).unwrap();
+        let new_root = temp.path().join("renamed");
+        fs::rename(&old_root, &new_root).unwrap();
+        let padz = new_root.join(".padz");
+
+        let relocation = check(&padz).unwrap().expect("the move is noticed");
+        assert_eq!(relocation.from, canonical(temp.path()).join("app"));
+        assert_eq!(relocation.to, canonical(&new_root));
+        // Still reported on the next open: nothing has handled it yet.
+        assert!(check(&padz).unwrap().is_some());
+
+        record(&padz).unwrap();
+        assert_eq!(check(&padz).unwrap(), None);
+    }
+
+    #[test]
+    fn test_a_copied_store_starts_its_own_record() {
+        let temp = TempDir::new().unwrap();
+        let original = store_at(&temp.path().join("app"));
+        check(&original).unwrap();
+        let copy = store_at(&temp.path().join("app-copy"));
+        fs::copy(original.join(LOCATION_FILE), copy.join(LOCATION_FILE)).unwrap();
+
+        assert_eq!(check(&copy).unwrap(), None);
+        assert_eq!(read(&copy).unwrap(), Some(canonical(&copy)));
+    }
+
+    #[test]
+    fn test_remap_moves_paths_inside_the_project_only() {
+        let relocation = Relocation {
+            from: PathBuf::from("/code/app"),
+            to: PathBuf::from("/work/app"),
+        };
+        assert_eq!(
+            relocation.remap(Path::new("/code/app/src")),
+            Some(PathBuf::from("/work/app/src"))
+        );
+        assert_eq!(
+            relocation.remap(Path::new("/code/app")),
+            Some(PathBuf::from("/work/app"))
+        );
+        assert_eq!(relocation.remap(Path::new("/code/application")), None);
+    }
+}
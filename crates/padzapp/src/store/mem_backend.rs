@@ -23,6 +23,8 @@ pub struct MemBackend {
     index: RefCell<HashMap<Scope, HashMap<Uuid, Metadata>>>,
     tags: RefCell<HashMap<Scope, Vec<TagEntry>>>,
     content: RefCell<HashMap<(Scope, Uuid), ContentEntry>>,
+    journal: RefCell<HashMap<Scope, String>>,
+    undo: RefCell<HashMap<Scope, String>>,
     simulate_write_error: RefCell<bool>,
 }
 
@@ -32,6 +34,8 @@ impl Default for MemBackend {
             index: RefCell::new(HashMap::new()),
             tags: RefCell::new(HashMap::new()),
             content: RefCell::new(HashMap::new()),
+            journal: RefCell::new(HashMap::new()),
+            undo: RefCell::new(HashMap::new()),
             simulate_write_error: RefCell::new(false),
         }
     }
@@ -89,6 +93,35 @@ impl StorageBackend for MemBackend {
         Ok(())
     }
 
+    fn load_journal(&self, scope: Scope) -> Result<Option<String>> {
+        Ok(self.journal.borrow().get(&scope).cloned())
+    }
+
+    fn save_journal(&self, scope: Scope, journal: &str) -> Result<()> {
+        if *self.simulate_write_error.borrow() {
+            return Err(PadzError::Store("Simulated write error".to_string()));
+        }
+        self.journal.borrow_mut().insert(scope, journal.to_string());
+        Ok(())
+    }
+
+    fn clear_journal(&self, scope: Scope) -> Result<()> {
+        self.journal.borrow_mut().remove(&scope);
+        Ok(())
+    }
+
+    fn load_undo(&self, scope: Scope) -> Result<Option<String>> {
+        Ok(self.undo.borrow().get(&scope).cloned())
+    }
+
+    fn save_undo(&self, scope: Scope, record: &str) -> Result<()> {
+        if *self.simulate_write_error.borrow() {
+            return Err(PadzError::Store("Simulated write error".to_string()));
+        }
+        self.undo.borrow_mut().insert(scope, record.to_string());
+        Ok(())
+    }
+
     fn read_content(&self, id: &Uuid, scope: Scope) -> Result<Option<String>> {
         let content = self.content.borrow();
         Ok(content.get(&(scope, *id)).map(|e| e.text.clone()))
@@ -25,6 +25,20 @@
 //! 3. **Staleness Check**: File `mtime` > DB `updated_at` → Re-parse to update cached title.
 //! 4. **Garbage Collection**: Empty/whitespace-only file → Delete file and DB entry.
 //!
+//! ## Loading the Index
+//!
+//! `data.json` is parsed straight from the file and written straight into
+//! one, so even a large index is held in memory once, as metadata, never also
+//! as its text. A listing parses it once per bucket: reconciliation hands the
+//! index it read on to the listing. Lookups that need no content use
+//! [`DataStore::list_metadata`], which reads the index alone.
+//!
+//! The index stays a single JSON object. An index split into chunks, or led
+//! by a summary header, would let a count skip the entries, but every
+//! listing needs every entry's title, dates and tags anyway, and either
+//! layout would be a schema change for all stores to save a parse that is
+//! small next to reading the pad files.
+//!
 //! ## Deletion Lifecycle
 //!
 //! - **Soft Delete**: Moves the pad from the Active bucket to the Deleted bucket.
@@ -43,6 +57,8 @@
 //! - `is_pinned`, `pinned_at`: Pin state
 //! - `delete_protected`: Protection flag
 //! - `title`: Cached title for fast listing
+//! - `checksum`: Hash of the content as padz last wrote it (see [`integrity`])
+//! - `history`: What happened to the pad, logged on every write (see [`history`])
 //!
 //! ## Architecture
 //!
@@ -57,32 +73,61 @@
 //!    - Generic over any `StorageBackend`
 //!
 //! For convenience, type aliases are provided:
-//! - [`fs::FileStore`]: `PadStore<FsBackend>` - production use
+//! - [`fs::FileStore`]: `BucketedStore<FsBackend>` - production use
 //! - [`memory::InMemoryStore`]: `PadStore<MemBackend>` - testing
 //!
+//! [`bucketed::BucketedStore`] puts every backend behind a [`write_guard::WriteGuard`],
+//! which holds writes back for transactions ([`journal`]) and `--dry-run`.
+//!
 //! ## Storage Layout
 //!
 //! ```text
 //! .padz/
 //! ├── data.json           # Metadata Cache
 //! ├── config.json         # Scope configuration
+//! ├── journal.json        # Only while a transaction commits (or after a crash)
+//! ├── location.json       # Where a project store was last seen (see the location module)
+//! ├── prompt-segment      # `padz prompt-segment`'s badge, cached for a few seconds
+//! ├── schema.json         # Layout version (see the schema module)
+//! ├── undo.json           # How to take back the last commit (see [`undo`])
 //! └── pad-{uuid}.{ext}    # Pad content files
 //! ```
+//!
+//! The global store also keeps `scopes.json`, the project stores padz has
+//! opened (see the registry module), and `remotes/`, local copies of stores
+//! on other machines (see the remote module). A global store kept in a bucket
+//! also has `objects.json`, what was last synced (see the object module).
 
-use crate::error::Result;
-use crate::model::{Pad, Scope};
+use crate::error::{Result, StoreWarning};
+use crate::model::{Metadata, Pad, Scope};
 use crate::tags::TagEntry;
+use events::StoreEvent;
+use integrity::IntegrityReport;
 use serde::{Deserialize, Serialize};
-use std::path::PathBuf;
+use std::path::{Path, PathBuf};
 use uuid::Uuid;
 
 pub mod backend;
 pub mod bucketed;
+pub mod compression;
+pub mod encryption;
+pub mod events;
 pub mod fs;
 pub mod fs_backend;
+pub mod gc;
+pub mod history;
+pub mod integrity;
+pub mod journal;
+pub mod location;
 pub mod mem_backend;
 pub mod memory;
+pub mod object;
 pub mod pad_store;
+pub mod registry;
+pub mod remote;
+pub mod schema;
+pub mod undo;
+pub mod write_guard;
 
 /// Which lifecycle bucket a pad lives in.
 ///
@@ -111,12 +156,26 @@ pub trait DataStore {
     /// Save a pad (create or update) in a specific bucket
     fn save_pad(&mut self, pad: &Pad, scope: Scope, bucket: Bucket) -> Result<()>;
 
+    /// Update a pad's metadata in a specific bucket, leaving its content as-is
+    fn save_metadata(&mut self, metadata: &Metadata, scope: Scope, bucket: Bucket) -> Result<()>;
+
     /// Get a pad by ID from a specific bucket
     fn get_pad(&self, id: &Uuid, scope: Scope, bucket: Bucket) -> Result<Pad>;
 
     /// List all pads in a given scope and bucket
     fn list_pads(&self, scope: Scope, bucket: Bucket) -> Result<Vec<Pad>>;
 
+    /// List the metadata of all pads in a scope and bucket, without reading
+    /// their content. The fast path for lookups that only need titles and
+    /// tags; stores with a separate index should override it.
+    fn list_metadata(&self, scope: Scope, bucket: Bucket) -> Result<Vec<Metadata>> {
+        Ok(self
+            .list_pads(scope, bucket)?
+            .into_iter()
+            .map(|pad| pad.metadata)
+            .collect())
+    }
+
     /// Delete a pad permanently from a specific bucket
     fn delete_pad(&mut self, id: &Uuid, scope: Scope, bucket: Bucket) -> Result<()>;
 
@@ -139,6 +198,12 @@ pub trait DataStore {
     /// Verify and fix consistency issues across all buckets
     fn doctor(&mut self, scope: Scope) -> Result<DoctorReport>;
 
+    /// Check content files against their recorded checksums across all buckets
+    fn check_integrity(&self, scope: Scope) -> Result<IntegrityReport>;
+
+    /// Record current content as the checksum baseline; returns how many changed
+    fn accept_checksums(&mut self, scope: Scope) -> Result<usize>;
+
     // --- Tag Registry Operations (scope-level, not bucket-specific) ---
 
     /// Load all tags from the registry
@@ -146,4 +211,76 @@ pub trait DataStore {
 
     /// Save the tag registry
     fn save_tags(&mut self, scope: Scope, tags: &[TagEntry]) -> Result<()>;
+
+    // --- Transactions (see [`journal`]); prefer the [`transaction`] helper ---
+
+    /// Start holding writes back; reads still see them. Nests.
+    fn begin(&mut self);
+
+    /// Apply the writes held since the outermost `begin` as one unit.
+    fn commit(&mut self) -> Result<()>;
+
+    /// Drop every write held since the outermost `begin`.
+    fn rollback(&mut self);
+
+    /// What the last commit to `scope` changed, as [`undo`] records it.
+    /// `None` when nothing was recorded; the default records nothing.
+    fn last_change(&self, _scope: Scope) -> Result<Option<undo::UndoRecord>> {
+        Ok(None)
+    }
+
+    // --- Warnings (see [`StoreWarning`]) ---
+
+    /// Note a problem the current command carried on past. The default drops it.
+    fn warn(&self, _warning: StoreWarning) {}
+
+    /// The warnings noted since the last call, oldest first.
+    fn take_warnings(&self) -> Vec<StoreWarning> {
+        Vec::new()
+    }
+
+    /// Let listings carry on past a store they cannot fully read, noting each
+    /// problem as a warning, instead of failing. Off by default: a store that
+    /// cannot be read must not pass for an empty one. For recovery only.
+    fn set_ignore_errors(&mut self, _ignore: bool) {}
+
+    // --- Events (see [`events`]) ---
+
+    /// Note what each write changes from now on. The default notes nothing.
+    fn record_events(&mut self) {}
+
+    /// The events noted since the last call, oldest first.
+    fn take_events(&mut self) -> Vec<StoreEvent> {
+        Vec::new()
+    }
+}
+
+/// The name pad file `path` has in the clear: without the `.gz` of a
+/// compressed body or the `.enc` of an encrypted one.
+pub fn plain_path(path: &Path) -> PathBuf {
+    if compression::is_compressed(path) || encryption::is_encrypted(path) {
+        path.with_extension("")
+    } else {
+        path.to_path_buf()
+    }
+}
+
+/// Run `f` as one transaction: its writes are all applied if it succeeds, and
+/// none are if it fails.
+pub fn transaction<S, T, F>(store: &mut S, f: F) -> Result<T>
+where
+    S: DataStore + ?Sized,
+    F: FnOnce(&mut S) -> Result<T>,
+{
+    store.begin();
+    match f(store) {
+        Ok(value) => {
+            store.commit()?;
+            Ok(value)
+        }
+        Err(e) => {
+            store.rollback();
+            Err(e)
+        }
+    }
 }
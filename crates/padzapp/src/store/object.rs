@@ -0,0 +1,674 @@
+//! # Object Storage
+//!
+//! The global store can live in an S3-compatible bucket, so the same global
+//! pads follow the user across machines. The local global directory stays the
+//! store padz reads and writes — a write-through cache of the bucket:
+//!
+//! 1. [`pull`] before a command brings in what other machines wrote,
+//! 2. the command runs against the local files as usual,
+//! 3. [`push`] after it uploads what changed.
+//!
+//! Each object's key is its path under the global directory
+//! (`active/pad-{uuid}.txt`, `active/data.json`, `tags.json`, ...). Only pad
+//! data is synced: bucket directories and the tag registry, never local state
+//! such as the journal, usage counts or this module's manifest.
+//!
+//! ## Conflicts
+//!
+//! `objects.json` records the ETag and content hash of every object as last
+//! synced, which is how both sides of a change are told apart. Uploads are
+//! conditional on the ETag still being the synced one, so a write another
+//! machine made in the meantime is never overwritten. When both sides changed
+//! the same object:
+//!
+//! - an index (`data.json`) or the tag registry is merged entry by entry, the
+//!   more recently updated pad winning,
+//! - a pad's content keeps both: the local version stays, and the other
+//!   machine's becomes a new pad file next to it, which the store adopts as a
+//!   pad of its own on the next read (see the reconciliation in [`super`]),
+//! - anything else keeps the local version.
+//!
+//! This module does no network I/O: the bucket is reached through an
+//! [`ObjectClient`] the caller supplies (the padz CLI wraps the `aws` CLI).
+
+use super::fs_backend::JOURNAL_FILE;
+use crate::cancel::Cancellation;
+use crate::error::{PadzError, Result};
+use crate::model::Metadata;
+use crate::progress::{track, Progress};
+use crate::tags::TagEntry;
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use std::collections::{BTreeMap, HashMap};
+use std::fs;
+use std::path::Path;
+use uuid::Uuid;
+
+/// Name of the sync manifest at the global store's root.
+pub const MANIFEST_FILE: &str = "objects.json";
+
+const BUCKET_DIRS: [&str; 3] = ["active", "archived", "deleted"];
+const TAGS_FILE: &str = "tags.json";
+
+/// Where a global store is kept: `s3://bucket/prefix`.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ObjectLocation {
+    pub bucket: String,
+    /// Key prefix, without a trailing `/`; may be empty.
+    pub prefix: String,
+}
+
+impl ObjectLocation {
+    pub fn parse(url: &str) -> Result<ObjectLocation> {
+        let rest = url.strip_prefix("s3://").ok_or_else(|| {
+            PadzError::Api(format!(
+                "Invalid global_store '{}': expected s3://bucket/prefix",
+                url
+            ))
+        })?;
+        let (bucket, prefix) = rest.split_once('/').unwrap_or((rest, ""));
+        if bucket.is_empty() {
+            return Err(PadzError::Api(format!(
+                "Invalid global_store '{}': missing the bucket",
+                url
+            )));
+        }
+        Ok(ObjectLocation {
+            bucket: bucket.to_string(),
+            prefix: prefix.trim_matches('/').to_string(),
+        })
+    }
+
+    /// The full object key for a store-relative `key`.
+    pub fn key(&self, key: &str) -> String {
+        if self.prefix.is_empty() {
+            key.to_string()
+        } else {
+            format!("{}/{}", self.prefix, key)
+        }
+    }
+}
+
+/// An object's body and the ETag it was read at.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Object {
+    pub body: Vec<u8>,
+    pub etag: String,
+}
+
+/// Access to the bucket (and prefix) holding a global store.
+pub trait ObjectClient {
+    /// Every object's key and ETag.
+    fn list(&self) -> Result<Vec<(String, String)>>;
+
+    /// The object at `key`, or `None` when there is none.
+    fn get(&self, key: &str) -> Result<Option<Object>>;
+
+    /// Writes `body` at `key` if the object's ETag is still `expected` (`None`:
+    /// if there is no object). Returns the new ETag, or `None` when the
+    /// precondition failed because someone else wrote first.
+    fn put(&self, key: &str, body: &[u8], expected: Option<&str>) -> Result<Option<String>>;
+
+    fn delete(&self, key: &str) -> Result<()>;
+}
+
+/// How a change made on both sides was settled.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(tag = "resolution", rename_all = "snake_case")]
+pub enum Conflict {
+    /// Both versions' entries were merged into one.
+    Merged { key: String },
+    /// The local version was kept and the other saved as a new pad at `copy`.
+    KeptBoth { key: String, copy: String },
+    /// The local version was kept and the other dropped.
+    KeptLocal { key: String },
+}
+
+/// What a [`pull`] or [`push`] did.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize)]
+pub struct SyncReport {
+    /// Objects written locally (pull) or to the bucket (push).
+    pub transferred: usize,
+    /// Objects deleted locally (pull) or from the bucket (push).
+    pub removed: usize,
+    pub conflicts: Vec<Conflict>,
+}
+
+#[derive(Debug, Clone, Serialize, Deserialize)]
+struct Synced {
+    etag: String,
+    sha256: String,
+}
+
+type Manifest = BTreeMap<String, Synced>;
+
+/// Brings the objects other machines changed into the store at `root`.
+pub fn pull(
+    root: &Path,
+    client: &impl ObjectClient,
+    progress: &dyn Progress,
+    cancel: &Cancellation,
+) -> Result<SyncReport> {
+    let mut manifest = load_manifest(root)?;
+    let mut report = SyncReport::default();
+    let remote: HashMap<String, String> = client
+        .list()?
+        .into_iter()
+        .filter(|(key, _)| is_synced(key))
+        .collect();
+
+    for (key, etag) in track(progress, "Downloading", remote.iter()) {
+        if cancel.is_cancelled() {
+            return interrupted(root, &manifest);
+        }
+        if manifest.get(key).is_some_and(|synced| &synced.etag == etag) {
+            continue;
+        }
+        let Some(object) = client.get(key)? else {
+            continue;
+        };
+        let local = read_local(root, key)?;
+        let local_changed = match (&local, manifest.get(key)) {
+            (None, _) => false,
+            (Some(body), Some(synced)) => digest(body) != synced.sha256,
+            // Created on both sides since the last sync.
+            (Some(_), None) => true,
+        };
+        match local {
+            Some(body) if local_changed && body != object.body => {
+                report
+                    .conflicts
+                    .push(resolve(root, key, &body, &object.body)?);
+            }
+            Some(body) if body == object.body => {}
+            _ => {
+                write_local(root, key, &object.body)?;
+                report.transferred += 1;
+            }
+        }
+        // Synced as the bucket has it; a merged local version differs from
+        // this and is uploaded by the next push.
+        manifest.insert(
+            key.clone(),
+            Synced {
+                etag: object.etag,
+                sha256: digest(&object.body),
+            },
+        );
+    }
+
+    let deleted: Vec<String> = manifest
+        .keys()
+        .filter(|key| !remote.contains_key(*key))
+        .cloned()
+        .collect();
+    for key in deleted {
+        let Some(synced) = manifest.remove(&key) else {
+            continue;
+        };
+        // Deleted elsewhere. A local change since the last sync wins, and is
+        // uploaded again as a new object by the next push.
+        if let Some(body) = read_local(root, &key)? {
+            if digest(&body) == synced.sha256 {
+                fs::remove_file(root.join(&key)).map_err(PadzError::Io)?;
+                report.removed += 1;
+            }
+        }
+    }
+
+    save_manifest(root, &manifest)?;
+    Ok(report)
+}
+
+/// Uploads the objects changed in the store at `root` since the last sync.
+pub fn push(
+    root: &Path,
+    client: &impl ObjectClient,
+    progress: &dyn Progress,
+    cancel: &Cancellation,
+) -> Result<SyncReport> {
+    let mut manifest = load_manifest(root)?;
+    let mut report = SyncReport::default();
+
+    let mut keys = local_keys(root)?;
+    let mut next = 0;
+    while next < keys.len() {
+        // Most keys are unchanged and skipped; the count is of keys checked.
+        progress.update("Uploading", next, keys.len());
+        if cancel.is_cancelled() {
+            progress.finish("Uploading");
+            return interrupted(root, &manifest);
+        }
+        let key = keys[next].clone();
+        next += 1;
+        let Some(mut body) = read_local(root, &key)? else {
+            continue;
+        };
+        if manifest
+            .get(&key)
+            .is_some_and(|synced| synced.sha256 == digest(&body))
+        {
+            continue;
+        }
+        let expected = manifest.get(&key).map(|synced| synced.etag.clone());
+        let mut etag = client.put(&key, &body, expected.as_deref())?;
+        if etag.is_none() {
+            // Someone wrote it since our last pull: settle it now and retry
+            // once against what they wrote.
+            let theirs = client.get(&key)?;
+            if let Some(object) = &theirs {
+                let conflict = resolve(root, &key, &body, &object.body)?;
+                if let Conflict::KeptBoth { copy, .. } = &conflict {
+                    keys.push(copy.clone());
+                }
+                report.conflicts.push(conflict);
+                body = read_local(root, &key)?.unwrap_or_default();
+            }
+            let expected = theirs.map(|object| object.etag);
+            etag = client.put(&key, &body, expected.as_deref())?;
+        }
+        // Still contended: left as a local change for the next sync.
+        let Some(etag) = etag else {
+            continue;
+        };
+        manifest.insert(
+            key,
+            Synced {
+                etag,
+                sha256: digest(&body),
+            },
+        );
+        report.transferred += 1;
+    }
+    progress.update("Uploading", keys.len(), keys.len());
+    progress.finish("Uploading");
+
+    let deleted: Vec<String> = manifest
+        .keys()
+        .filter(|key| !root.join(key).exists())
+        .cloned()
+        .collect();
+    for key in deleted {
+        client.delete(&key)?;
+        manifest.remove(&key);
+        report.removed += 1;
+    }
+
+    save_manifest(root, &manifest)?;
+    Ok(report)
+}
+
+/// Stops a cancelled sync. The manifest keeps what already went through, so
+/// the next sync picks up from there.
+fn interrupted(root: &Path, manifest: &Manifest) -> Result<SyncReport> {
+    save_manifest(root, manifest)?;
+    Err(PadzError::Interrupted)
+}
+
+/// Settles an object both sides changed; leaves the outcome in the local file.
+fn resolve(root: &Path, key: &str, local: &[u8], remote: &[u8]) -> Result<Conflict> {
+    let key = key.to_string();
+    let (dir, name) = key.rsplit_once('/').unwrap_or(("", key.as_str()));
+
+    if name == "data.json" {
+        let mut merged: HashMap<Uuid, Metadata> = serde_json::from_slice(local)?;
+        let theirs: HashMap<Uuid, Metadata> = serde_json::from_slice(remote)?;
+        for (id, meta) in theirs {
+            let theirs_is_newer = match merged.get(&id) {
+                Some(ours) => ours.updated_at < meta.updated_at,
+                None => true,
+            };
+            if theirs_is_newer {
+                merged.insert(id, meta);
+            }
+        }
+        write_local(
+            root,
+            &key,
+            serde_json::to_string_pretty(&merged)?.as_bytes(),
+        )?;
+        return Ok(Conflict::Merged { key });
+    }
+    if key == TAGS_FILE {
+        let mut merged: Vec<TagEntry> = serde_json::from_slice(local)?;
+        let theirs: Vec<TagEntry> = serde_json::from_slice(remote)?;
+        for tag in theirs {
+            if !merged.iter().any(|ours| ours.name == tag.name) {
+                merged.push(tag);
+            }
+        }
+        write_local(
+            root,
+            &key,
+            serde_json::to_string_pretty(&merged)?.as_bytes(),
+        )?;
+        return Ok(Conflict::Merged { key });
+    }
+    if let Some(id_and_suffix) = name.strip_prefix("pad-") {
+        // Everything after the id: the format and any `.gz` or `.enc`, which
+        // the store needs to read the copy back.
+        let suffix = id_and_suffix
+            .find('.')
+            .map_or("", |dot| &id_and_suffix[dot..]);
+        let copy = format!("{}/pad-{}{}", dir, Uuid::new_v4(), suffix);
+        write_local(root, &copy, remote)?;
+        return Ok(Conflict::KeptBoth { key, copy });
+    }
+    Ok(Conflict::KeptLocal { key })
+}
+
+/// Whether `key` names pad data (as opposed to local state).
+fn is_synced(key: &str) -> bool {
+    if key == TAGS_FILE {
+        return true;
+    }
+    match key.split_once('/') {
+        Some((dir, name)) => {
+            BUCKET_DIRS.contains(&dir)
+                && !name.is_empty()
+                && !name.contains('/')
+                && !name.starts_with('.')
+                && name != JOURNAL_FILE
+        }
+        None => false,
+    }
+}
+
+/// The keys of the synced files present locally.
+fn local_keys(root: &Path) -> Result<Vec<String>> {
+    let mut keys = Vec::new();
+    if root.join(TAGS_FILE).is_file() {
+        keys.push(TAGS_FILE.to_string());
+    }
+    for dir in BUCKET_DIRS {
+        let Ok(entries) = fs::read_dir(root.join(dir)) else {
+            continue;
+        };
+        for entry in entries {
+            let entry = entry.map_err(PadzError::Io)?;
+            if !entry.path().is_file() {
+                continue;
+            }
+            let key = format!("{}/{}", dir, entry.file_name().to_string_lossy());
+            if is_synced(&key) {
+                keys.push(key);
+            }
+        }
+    }
+    keys.sort();
+    Ok(keys)
+}
+
+fn read_local(root: &Path, key: &str) -> Result<Option<Vec<u8>>> {
+    match fs::read(root.join(key)) {
+        Ok(body) => Ok(Some(body)),
+        Err(e) if e.kind() == std::io::ErrorKind::NotFound => Ok(None),
+        Err(e) => Err(PadzError::Io(e)),
+    }
+}
+
+fn write_local(root: &Path, key: &str, body: &[u8]) -> Result<()> {
+    let path = root.join(key);
+    if let Some(parent) = path.parent() {
+        fs::create_dir_all(parent).map_err(PadzError::Io)?;
+    }
+    let tmp = path.with_file_name(format!(".sync-{}.tmp", Uuid::new_v4()));
+    fs::write(&tmp, body).map_err(PadzError::Io)?;
+    fs::rename(&tmp, &path).map_err(PadzError::Io)
+}
+
+fn load_manifest(root: &Path) -> Result<Manifest> {
+    match fs::read_to_string(root.join(MANIFEST_FILE)) {
+        Ok(json) => Ok(serde_json::from_str(&json)?),
+        Err(e) if e.kind() == std::io::ErrorKind::NotFound => Ok(Manifest::new()),
+        Err(e) => Err(PadzError::Io(e)),
+    }
+}
+
+fn save_manifest(root: &Path, manifest: &Manifest) -> Result<()> {
+    fs::create_dir_all(root).map_err(PadzError::Io)?;
+    let json = serde_json::to_string_pretty(manifest)?;
+    write_local(root, MANIFEST_FILE, json.as_bytes())
+}
+
+fn digest(body: &[u8]) -> String {
+    format!("{:x}", Sha256::digest(body))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::progress::NoProgress;
+    use crate::store::compression::{compress, decompress};
+    use crate::test_utils::CancelAfter;
+    use std::cell::RefCell;
+    use tempfile::TempDir;
+
+    /// A bucket in memory; ETags count writes.
+    #[derive(Default)]
+    struct MemBucket {
+        objects: RefCell<HashMap<String, Object>>,
+        writes: RefCell<usize>,
+    }
+
+    impl ObjectClient for MemBucket {
+        fn list(&self) -> Result<Vec<(String, String)>> {
+            Ok(self
+                .objects
+                .borrow()
+                .iter()
+                .map(|(key, object)| (key.clone(), object.etag.clone()))
+                .collect())
+        }
+
+        fn get(&self, key: &str) -> Result<Option<Object>> {
+            Ok(self.objects.borrow().get(key).cloned())
+        }
+
+        fn put(&self, key: &str, body: &[u8], expected: Option<&str>) -> Result<Option<String>> {
+            let mut objects = self.objects.borrow_mut();
+            if objects.get(key).map(|object| object.etag.as_str()) != expected {
+                return Ok(None);
+            }
+            *self.writes.borrow_mut() += 1;
+            let etag = format!("e{}", self.writes.borrow());
+            objects.insert(
+                key.to_string(),
+                Object {
+                    body: body.to_vec(),
+                    etag: etag.clone(),
+                },
+            );
+            Ok(Some(etag))
+        }
+
+        fn delete(&self, key: &str) -> Result<()> {
+            self.objects.borrow_mut().remove(key);
+            Ok(())
+        }
+    }
+
+    fn write(root: &Path, key: &str, body: &str) {
+        write_local(root, key, body.as_bytes()).unwrap();
+    }
+
+    fn read(root: &Path, key: &str) -> Option<String> {
+        read_local(root, key)
+            .unwrap()
+            .map(|body| String::from_utf8(body).unwrap())
+    }
+
+    #[test]
+    fn test_parses_bucket_and_prefix() {
+        let location = ObjectLocation::parse("s3://notes/me/padz/").unwrap();
+        assert_eq!(location.bucket, "notes");
+        assert_eq!(location.key("tags.json"), "me/padz/tags.json");
+        let bare = ObjectLocation::parse("s3://notes").unwrap();
+        assert_eq!(bare.key("tags.json"), "tags.json");
+        assert!(ObjectLocation::parse("notes/padz").is_err());
+        assert!(ObjectLocation::parse("s3:///padz").is_err());
+    }
+
+    #[test]
+    fn test_changes_travel_between_machines() {
+        let (laptop, desktop) = (TempDir::new().unwrap(), TempDir::new().unwrap());
+        let bucket = MemBucket::default();
+        write(laptop.path(), "active/pad-a.txt", "from the laptop");
+        write(laptop.path(), "active/journal.json", "{}");
+        write(laptop.path(), "usage.json", "{}");
+
+        assert_eq!(
+            push(laptop.path(), &bucket, &NoProgress, &Cancellation::new())
+                .unwrap()
+                .transferred,
+            1
+        );
+        assert_eq!(bucket.list().unwrap().len(), 1, "local state stays local");
+
+        assert_eq!(
+            pull(desktop.path(), &bucket, &NoProgress, &Cancellation::new())
+                .unwrap()
+                .transferred,
+            1
+        );
+        assert_eq!(
+            read(desktop.path(), "active/pad-a.txt").unwrap(),
+            "from the laptop"
+        );
+
+        // A deletion travels too.
+        fs::remove_file(desktop.path().join("active/pad-a.txt")).unwrap();
+        assert_eq!(
+            push(desktop.path(), &bucket, &NoProgress, &Cancellation::new())
+                .unwrap()
+                .removed,
+            1
+        );
+        assert_eq!(
+            pull(laptop.path(), &bucket, &NoProgress, &Cancellation::new())
+                .unwrap()
+                .removed,
+            1
+        );
+        assert_eq!(read(laptop.path(), "active/pad-a.txt"), None);
+    }
+
+    #[test]
+    fn test_a_pad_changed_on_both_sides_keeps_both_versions() {
+        let (laptop, desktop) = (TempDir::new().unwrap(), TempDir::new().unwrap());
+        let bucket = MemBucket::default();
+        write(laptop.path(), "active/pad-a.txt", "v1");
+        push(laptop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+        pull(desktop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+
+        write(laptop.path(), "active/pad-a.txt", "laptop edit");
+        write(desktop.path(), "active/pad-a.txt", "desktop edit");
+        push(laptop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+        let report = push(desktop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+
+        let [Conflict::KeptBoth { key, copy }] = report.conflicts.as_slice() else {
+            panic!(
+                "expected one kept-both conflict, got {:?}",
+                report.conflicts
+            );
+        };
+        assert_eq!(key, "active/pad-a.txt");
+        assert_eq!(read(desktop.path(), key).unwrap(), "desktop edit");
+        assert_eq!(read(desktop.path(), copy).unwrap(), "laptop edit");
+
+        pull(laptop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+        assert_eq!(read(laptop.path(), key).unwrap(), "desktop edit");
+        assert_eq!(read(laptop.path(), copy).unwrap(), "laptop edit");
+    }
+
+    #[test]
+    fn test_a_conflict_copy_keeps_the_compressed_suffix() {
+        let (laptop, desktop) = (TempDir::new().unwrap(), TempDir::new().unwrap());
+        let bucket = MemBucket::default();
+        let key = format!("archived/pad-{}.txt.gz", Uuid::new_v4());
+        let gz = |body: &str| compress(body).unwrap();
+        write_local(laptop.path(), &key, &gz("v1")).unwrap();
+        push(laptop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+        pull(desktop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+
+        write_local(laptop.path(), &key, &gz("laptop edit")).unwrap();
+        write_local(desktop.path(), &key, &gz("desktop edit")).unwrap();
+        push(laptop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+        let report = push(desktop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+
+        let [Conflict::KeptBoth { copy, .. }] = report.conflicts.as_slice() else {
+            panic!(
+                "expected one kept-both conflict, got {:?}",
+                report.conflicts
+            );
+        };
+        assert!(copy.starts_with("archived/pad-"), "{copy}");
+        assert!(copy.ends_with(".txt.gz"), "{copy}");
+        assert_ne!(copy, &key);
+        let body = read_local(desktop.path(), copy).unwrap().unwrap();
+        assert_eq!(decompress(&body).unwrap(), "laptop edit");
+    }
+
+    #[test]
+    fn test_indexes_changed_on_both_sides_are_merged() {
+        let (laptop, desktop) = (TempDir::new().unwrap(), TempDir::new().unwrap());
+        let bucket = MemBucket::default();
+        let index = |metas: &[&Metadata]| {
+            let map: HashMap<Uuid, &Metadata> = metas.iter().map(|m| (m.id, *m)).collect();
+            serde_json::to_string(&map).unwrap()
+        };
+        let shared = Metadata::new("shared".into());
+        write(laptop.path(), "active/data.json", &index(&[&shared]));
+        push(laptop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+        pull(desktop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+
+        let from_laptop = Metadata::new("from laptop".into());
+        let mut renamed = shared.clone();
+        renamed.title = "renamed on desktop".into();
+        renamed.updated_at = shared.updated_at + chrono::Duration::seconds(5);
+        let from_desktop = Metadata::new("from desktop".into());
+        write(
+            laptop.path(),
+            "active/data.json",
+            &index(&[&shared, &from_laptop]),
+        );
+        write(
+            desktop.path(),
+            "active/data.json",
+            &index(&[&renamed, &from_desktop]),
+        );
+        push(laptop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+        pull(desktop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+
+        let merged: HashMap<Uuid, Metadata> =
+            serde_json::from_str(&read(desktop.path(), "active/data.json").unwrap()).unwrap();
+        assert_eq!(merged.len(), 3);
+        assert_eq!(merged[&shared.id].title, "renamed on desktop");
+        assert!(merged.contains_key(&from_laptop.id));
+
+        // The merge is the desktop's local change, uploaded by its next push.
+        assert_eq!(
+            push(desktop.path(), &bucket, &NoProgress, &Cancellation::new())
+                .unwrap()
+                .transferred,
+            1
+        );
+    }
+
+    #[test]
+    fn test_a_cancelled_push_resumes_where_it_stopped() {
+        let laptop = TempDir::new().unwrap();
+        let bucket = MemBucket::default();
+        write(laptop.path(), "active/pad-a.txt", "a");
+        write(laptop.path(), "active/pad-b.txt", "b");
+
+        let ctrl_c = CancelAfter::new(1);
+        let err = push(laptop.path(), &bucket, &ctrl_c, &ctrl_c.cancel).unwrap_err();
+        assert!(matches!(err, PadzError::Interrupted));
+        assert_eq!(bucket.list().unwrap().len(), 1);
+
+        let report = push(laptop.path(), &bucket, &NoProgress, &Cancellation::new()).unwrap();
+        assert_eq!(report.transferred, 1, "the first upload is not redone");
+        assert_eq!(bucket.list().unwrap().len(), 2);
+    }
+}
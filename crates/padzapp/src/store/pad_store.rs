@@ -1,7 +1,11 @@
 use super::backend::StorageBackend;
-use super::DoctorReport;
+use super::history;
+use super::integrity::{content_checksum, IntegrityIssue, IntegrityProblem, IntegrityReport};
+use super::{Bucket, DoctorReport};
 use crate::error::{PadzError, Result};
 use crate::model::{Metadata, Pad, Scope};
+use chrono::Utc;
+use std::collections::HashMap;
 use std::path::PathBuf;
 use uuid::Uuid;
 
@@ -28,8 +32,19 @@ impl<B: StorageBackend> PadStore<B> {
     /// Internal reconciliation logic used by both sync and doctor
     /// Takes &self because StorageBackend handles internal mutability (or is stateless i/o)
     fn reconcile(&self, scope: Scope) -> Result<(DoctorReport, bool)> {
+        let (report, changes, _) = self.reconcile_index(scope)?;
+        Ok((report, changes))
+    }
+
+    /// [`reconcile`](Self::reconcile), also handing back the reconciled index
+    /// so a listing need not parse it a second time. `None` when the scope is
+    /// not available.
+    fn reconcile_index(
+        &self,
+        scope: Scope,
+    ) -> Result<(DoctorReport, bool, Option<HashMap<Uuid, Metadata>>)> {
         if !self.backend.scope_available(scope) {
-            return Ok((DoctorReport::default(), false));
+            return Ok((DoctorReport::default(), false, None));
         }
 
         let mut meta_map = self.backend.load_index(scope)?;
@@ -93,6 +108,15 @@ impl<B: StorageBackend> PadStore<B> {
                             title,
                             status: crate::model::TodoStatus::Planned,
                             tags: Vec::new(),
+                            last_accessed_at: None,
+                            checksum: None,
+                            alias: None,
+                            anchors: Vec::new(),
+                            context: None,
+                            owner: None,
+                            source: None,
+                            published: Vec::new(),
+                            history: Vec::new(),
                         };
                         meta_map.insert(*id, new_meta);
                         report.recovered_files += 1;
@@ -126,7 +150,7 @@ impl<B: StorageBackend> PadStore<B> {
             self.backend.save_index(scope, &meta_map)?;
         }
 
-        Ok((report, changes))
+        Ok((report, changes, Some(meta_map)))
     }
 }
 
@@ -138,14 +162,33 @@ impl<B: StorageBackend> PadStore<B> {
         self.backend
             .write_content(&pad.metadata.id, scope, &pad.content)?;
 
-        // Update Index
+        // Update Index, recording what was just written
+        let mut metadata = pad.metadata.clone();
+        metadata.checksum = Some(content_checksum(&pad.content));
         let mut index = self.backend.load_index(scope)?;
-        index.insert(pad.metadata.id, pad.metadata.clone());
+        history::record(index.get(&pad.metadata.id), &mut metadata, Utc::now());
+        index.insert(pad.metadata.id, metadata);
         self.backend.save_index(scope, &index)?;
 
         Ok(())
     }
 
+    /// Rewrite a pad's index entry without touching its content file.
+    ///
+    /// For metadata that must not look like an edit: rewriting content moves the
+    /// file's mtime, which reconciliation reads as a change to `updated_at`.
+    pub fn save_metadata(&mut self, metadata: &Metadata, scope: Scope) -> Result<()> {
+        let mut index = self.backend.load_index(scope)?;
+        let Some(previous) = index.get(&metadata.id) else {
+            return Err(PadzError::PadNotFound(metadata.id));
+        };
+        let mut metadata = metadata.clone();
+        history::record(Some(previous), &mut metadata, Utc::now());
+        index.insert(metadata.id, metadata);
+        self.backend.save_index(scope, &index)?;
+        Ok(())
+    }
+
     pub fn get_pad(&self, id: &Uuid, scope: Scope) -> Result<Pad> {
         let index = self.backend.load_index(scope)?;
         let metadata = index.get(id).ok_or(PadzError::PadNotFound(*id))?.clone();
@@ -154,16 +197,60 @@ impl<B: StorageBackend> PadStore<B> {
     }
 
     pub fn list_pads(&self, scope: Scope) -> Result<Vec<Pad>> {
-        let _ = self.reconcile(scope);
+        let index = match self.reconcile_index(scope)? {
+            (_, _, Some(index)) => index,
+            (_, _, None) => self.backend.load_index(scope)?,
+        };
+        self.read_pads(index, scope, |_, e| Err(e))
+    }
+
+    /// The pads the index lists, without syncing it with the pad files first.
+    pub fn list_indexed(&self, scope: Scope) -> Result<Vec<Pad>> {
+        self.list_indexed_skipping(scope, |_, e| Err(e))
+    }
+
+    /// [`list_indexed`](Self::list_indexed), handing each pad whose file cannot
+    /// be read to `unreadable`, which either fails the listing or leaves the
+    /// pad out of it.
+    pub fn list_indexed_skipping(
+        &self,
+        scope: Scope,
+        unreadable: impl FnMut(&Metadata, PadzError) -> Result<()>,
+    ) -> Result<Vec<Pad>> {
         let index = self.backend.load_index(scope)?;
-        let mut pads = Vec::new();
+        self.read_pads(index, scope, unreadable)
+    }
+
+    /// The pads of `index`, their content read from the backend.
+    fn read_pads(
+        &self,
+        index: HashMap<Uuid, Metadata>,
+        scope: Scope,
+        mut unreadable: impl FnMut(&Metadata, PadzError) -> Result<()>,
+    ) -> Result<Vec<Pad>> {
+        let mut pads = Vec::with_capacity(index.len());
         for (id, metadata) in index {
-            let content = self.backend.read_content(&id, scope)?.unwrap_or_default();
-            pads.push(Pad { metadata, content });
+            match self.backend.read_content(&id, scope) {
+                Ok(content) => pads.push(Pad {
+                    metadata,
+                    content: content.unwrap_or_default(),
+                }),
+                Err(e) => unreadable(&metadata, e)?,
+            }
         }
         Ok(pads)
     }
 
+    /// The index entries of every pad, straight from the index.
+    ///
+    /// Unlike [`list_pads`](Self::list_pads) this neither reconciles nor reads
+    /// content files, so its cost does not grow with the size of the pads. The
+    /// price is that a pad file edited or added outside padz is not seen until
+    /// the next full listing reconciles it.
+    pub fn list_metadata(&self, scope: Scope) -> Result<Vec<Metadata>> {
+        Ok(self.backend.load_index(scope)?.into_values().collect())
+    }
+
     pub fn delete_pad(&mut self, id: &Uuid, scope: Scope) -> Result<()> {
         let mut index = self.backend.load_index(scope)?;
         if index.remove(id).is_none() {
@@ -183,6 +270,80 @@ impl<B: StorageBackend> PadStore<B> {
         Ok(report)
     }
 
+    /// Compare every content file against its recorded checksum.
+    ///
+    /// Reads the index and files directly, without reconciling: a check reports
+    /// drift rather than repairing it. `bucket` only labels the issues.
+    pub fn check_integrity(&self, scope: Scope, bucket: Bucket) -> Result<IntegrityReport> {
+        let mut report = IntegrityReport::default();
+        if !self.backend.scope_available(scope) {
+            return Ok(report);
+        }
+
+        let index = self.backend.load_index(scope)?;
+        let found_ids = self.backend.list_content_ids(scope)?;
+
+        for (id, meta) in &index {
+            let issue = |problem| IntegrityIssue {
+                id: *id,
+                bucket,
+                title: Some(meta.title.clone()),
+                problem,
+            };
+            let Some(content) = self.backend.read_content(id, scope)? else {
+                report.issues.push(issue(IntegrityProblem::MissingContent));
+                continue;
+            };
+            match &meta.checksum {
+                None => report.unrecorded += 1,
+                Some(recorded) => {
+                    report.checked += 1;
+                    if *recorded != content_checksum(&content) {
+                        report.issues.push(issue(IntegrityProblem::Mismatch));
+                    }
+                }
+            }
+        }
+
+        for id in found_ids.iter().filter(|id| !index.contains_key(id)) {
+            report.issues.push(IntegrityIssue {
+                id: *id,
+                bucket,
+                title: None,
+                problem: IntegrityProblem::Untracked,
+            });
+        }
+
+        report.issues.sort_by_key(|issue| issue.id);
+        Ok(report)
+    }
+
+    /// Record the current content of every indexed pad as its checksum.
+    ///
+    /// Pads whose content file is missing are left alone (that is `doctor`'s
+    /// job). Returns how many checksums changed.
+    pub fn accept_checksums(&mut self, scope: Scope) -> Result<usize> {
+        if !self.backend.scope_available(scope) {
+            return Ok(0);
+        }
+        let mut index = self.backend.load_index(scope)?;
+        let mut changed = 0;
+        for (id, meta) in index.iter_mut() {
+            let Some(content) = self.backend.read_content(id, scope)? else {
+                continue;
+            };
+            let checksum = Some(content_checksum(&content));
+            if meta.checksum != checksum {
+                meta.checksum = checksum;
+                changed += 1;
+            }
+        }
+        if changed > 0 {
+            self.backend.save_index(scope, &index)?;
+        }
+        Ok(changed)
+    }
+
     pub fn load_tags(&self, scope: Scope) -> Result<Vec<crate::tags::TagEntry>> {
         self.backend.load_tags(scope)
     }
@@ -273,6 +434,15 @@ mod tests {
                 title: "Zombie".to_string(),
                 status: crate::model::TodoStatus::Planned,
                 tags: Vec::new(),
+                last_accessed_at: None,
+                checksum: None,
+                alias: None,
+                anchors: Vec::new(),
+                context: None,
+                owner: None,
+                source: None,
+                published: Vec::new(),
+                history: Vec::new(),
             },
         );
         backend.save_index(Scope::Project, &index).unwrap();
@@ -419,6 +589,80 @@ mod tests {
 
     // --- Basic CRUD Tests ---
 
+    #[test]
+    fn test_save_records_checksum() {
+        let mut store = make_store();
+        let pad = Pad::new("Title".into(), "Body".into());
+        store.save_pad(&pad, Scope::Project).unwrap();
+
+        let saved = store.get_pad(&pad.metadata.id, Scope::Project).unwrap();
+
+        assert_eq!(
+            saved.metadata.checksum,
+            Some(content_checksum(&saved.content))
+        );
+        let report = store
+            .check_integrity(Scope::Project, Bucket::Active)
+            .unwrap();
+        assert_eq!(report.checked, 1);
+        assert!(report.issues.is_empty());
+    }
+
+    #[test]
+    fn test_check_integrity_reports_changed_and_missing_content() {
+        let mut store = make_store();
+        let edited = Pad::new("Edited".into(), "Body".into());
+        let gone = Pad::new("Gone".into(), "Body".into());
+        store.save_pad(&edited, Scope::Project).unwrap();
+        store.save_pad(&gone, Scope::Project).unwrap();
+        store
+            .backend
+            .write_content(&edited.metadata.id, Scope::Project, "Edited\n\nTampered")
+            .unwrap();
+        store
+            .backend
+            .delete_content(&gone.metadata.id, Scope::Project)
+            .unwrap();
+
+        let report = store
+            .check_integrity(Scope::Project, Bucket::Active)
+            .unwrap();
+
+        let problem_of = |id| {
+            report
+                .issues
+                .iter()
+                .find(|issue| issue.id == id)
+                .map(|issue| issue.problem)
+        };
+        assert_eq!(
+            problem_of(edited.metadata.id),
+            Some(IntegrityProblem::Mismatch)
+        );
+        assert_eq!(
+            problem_of(gone.metadata.id),
+            Some(IntegrityProblem::MissingContent)
+        );
+    }
+
+    #[test]
+    fn test_accept_checksums_clears_mismatch() {
+        let mut store = make_store();
+        let pad = Pad::new("Title".into(), "Body".into());
+        store.save_pad(&pad, Scope::Project).unwrap();
+        store
+            .backend
+            .write_content(&pad.metadata.id, Scope::Project, "Title\n\nHand edit")
+            .unwrap();
+
+        assert_eq!(store.accept_checksums(Scope::Project).unwrap(), 1);
+
+        let report = store
+            .check_integrity(Scope::Project, Bucket::Active)
+            .unwrap();
+        assert!(report.issues.is_empty());
+    }
+
     #[test]
     fn test_save_and_get_pad() {
         let mut store = make_store();
@@ -0,0 +1,203 @@
+//! # Scope Registry
+//!
+//! The global store keeps a list of the project stores padz has opened:
+//!
+//! ```text
+//! <global data dir>/
+//! └── scopes.json         # [{"path": "/home/me/code/app/.padz", "last_seen": ...}]
+//! ```
+//!
+//! Every project open records its store here (from [`crate::init`]), so the
+//! registry knows about each project that has been used since it existed.
+//! `last_seen` is kept to within a day ([`SEEN_EVERY_HOURS`]): a store opened
+//! again sooner leaves the file alone, so most commands write nothing here. A
+//! project directory that is deleted (or moved without padz noticing, see
+//! [`super::location`]) leaves its entry behind; `scopes prune`
+//! ([`crate::commands::scopes`]) finds and removes those.
+//!
+//! Entries hold canonical `.padz` paths, so the same store opened through a
+//! symlink is one entry.
+//!
+//! Remote stores ([`super::remote`]) are registered by name (`scopes add`),
+//! with their cache as the path. Opening a cache does not register it: it is
+//! padz's own copy, not a project the user opened.
+
+use chrono::{DateTime, Duration, Utc};
+use serde::{Deserialize, Serialize};
+use std::fs;
+use std::io;
+use std::path::{Path, PathBuf};
+
+use super::remote::REMOTES_DIR;
+
+/// Name of the registry file at the global store's root.
+pub const REGISTRY_FILE: &str = "scopes.json";
+
+/// How stale, in hours, `last_seen` may get before an open refreshes it.
+pub const SEEN_EVERY_HOURS: i64 = 24;
+
+/// One project store padz has opened.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ScopeEntry {
+    /// The project's `.padz` directory.
+    pub path: PathBuf,
+    /// When the store was last opened, to within [`SEEN_EVERY_HOURS`] (for a
+    /// remote, when it was registered).
+    pub last_seen: DateTime<Utc>,
+    /// Set for a remote store; `path` is then its local cache.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub remote: Option<RemoteEntry>,
+}
+
+/// The name and address a remote store was registered under.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct RemoteEntry {
+    pub name: String,
+    pub url: String,
+}
+
+impl ScopeEntry {
+    /// The project directory holding the store.
+    pub fn project_root(&self) -> PathBuf {
+        self.path
+            .parent()
+            .map(Path::to_path_buf)
+            .unwrap_or_else(|| self.path.clone())
+    }
+}
+
+/// The registered stores, oldest registration first. A missing file is an
+/// empty registry.
+pub fn load(global_dir: &Path) -> io::Result<Vec<ScopeEntry>> {
+    match fs::read_to_string(global_dir.join(REGISTRY_FILE)) {
+        Ok(json) => {
+            serde_json::from_str(&json).map_err(|e| io::Error::new(io::ErrorKind::InvalidData, e))
+        }
+        Err(e) if e.kind() == io::ErrorKind::NotFound => Ok(Vec::new()),
+        Err(e) => Err(e),
+    }
+}
+
+/// Replaces the registry with `entries`.
+pub fn save(global_dir: &Path, entries: &[ScopeEntry]) -> io::Result<()> {
+    fs::create_dir_all(global_dir)?;
+    let json = serde_json::to_string_pretty(entries).map_err(io::Error::other)?;
+    fs::write(global_dir.join(REGISTRY_FILE), json)
+}
+
+/// Records that the store at `padz_dir` was opened now. Writes only when the
+/// store is new to the registry or its `last_seen` is older than
+/// [`SEEN_EVERY_HOURS`].
+pub fn register(global_dir: &Path, padz_dir: &Path) -> io::Result<()> {
+    if padz_dir.starts_with(global_dir.join(REMOTES_DIR)) {
+        return Ok(());
+    }
+    let path = fs::canonicalize(padz_dir).unwrap_or_else(|_| padz_dir.to_path_buf());
+    let mut entries = load(global_dir)?;
+    let now = Utc::now();
+    match entries.iter_mut().find(|entry| entry.path == path) {
+        Some(entry) if now - entry.last_seen < Duration::hours(SEEN_EVERY_HOURS) => return Ok(()),
+        Some(entry) => entry.last_seen = now,
+        None => entries.push(ScopeEntry {
+            path,
+            last_seen: now,
+            remote: None,
+        }),
+    }
+    save(global_dir, &entries)
+}
+
+/// Registers the remote store at `url` as `name`, replacing any remote of
+/// that name.
+pub fn add_remote(global_dir: &Path, name: &str, url: &str, cache_dir: &Path) -> io::Result<()> {
+    let mut entries = load(global_dir)?;
+    entries.retain(|entry| !matches!(&entry.remote, Some(remote) if remote.name == name));
+    entries.push(ScopeEntry {
+        path: cache_dir.to_path_buf(),
+        last_seen: Utc::now(),
+        remote: Some(RemoteEntry {
+            name: name.to_string(),
+            url: url.to_string(),
+        }),
+    });
+    save(global_dir, &entries)
+}
+
+/// The address of the remote registered as `name`.
+pub fn find_remote(global_dir: &Path, name: &str) -> io::Result<Option<String>> {
+    Ok(load(global_dir)?
+        .into_iter()
+        .filter_map(|entry| entry.remote)
+        .find(|remote| remote.name == name)
+        .map(|remote| remote.url))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    #[test]
+    fn test_register_adds_each_store_once() {
+        let temp = TempDir::new().unwrap();
+        let global = temp.path().join("global");
+        let app = temp.path().join("app/.padz");
+        fs::create_dir_all(&app).unwrap();
+
+        assert!(load(&global).unwrap().is_empty());
+        register(&global, &app).unwrap();
+        let first = load(&global).unwrap();
+        register(&global, &app).unwrap();
+        let second = load(&global).unwrap();
+
+        assert_eq!(second.len(), 1);
+        assert_eq!(second[0].path, fs::canonicalize(&app).unwrap());
+        assert!(second[0].last_seen >= first[0].last_seen);
+        assert_eq!(
+            second[0].project_root(),
+            fs::canonicalize(temp.path().join("app")).unwrap()
+        );
+    }
+
+    #[test]
+    fn test_register_refreshes_last_seen_only_once_it_is_stale() {
+        let temp = TempDir::new().unwrap();
+        let global = temp.path().join("global");
+        let app = temp.path().join("app/.padz");
+        fs::create_dir_all(&app).unwrap();
+        let seen_at = |ago| {
+            let mut entries = load(&global).unwrap();
+            entries[0].last_seen = Utc::now() - ago;
+            save(&global, &entries).unwrap();
+            entries[0].last_seen
+        };
+        register(&global, &app).unwrap();
+
+        let recent = seen_at(Duration::hours(1));
+        register(&global, &app).unwrap();
+        assert_eq!(load(&global).unwrap()[0].last_seen, recent);
+
+        let stale = seen_at(Duration::hours(SEEN_EVERY_HOURS + 1));
+        register(&global, &app).unwrap();
+        assert!(load(&global).unwrap()[0].last_seen > stale);
+    }
+
+    #[test]
+    fn test_remotes_are_found_by_name_and_their_caches_not_registered() {
+        let temp = TempDir::new().unwrap();
+        let global = temp.path().join("global");
+        let cache = global.join(REMOTES_DIR).join("devbox/app/.padz");
+        fs::create_dir_all(&cache).unwrap();
+
+        add_remote(&global, "devbox", "ssh://devbox/old", &cache).unwrap();
+        add_remote(&global, "devbox", "ssh://devbox/app", &cache).unwrap();
+        register(&global, &cache).unwrap();
+
+        assert_eq!(load(&global).unwrap().len(), 1);
+        assert_eq!(
+            find_remote(&global, "devbox").unwrap().as_deref(),
+            Some("ssh://devbox/app")
+        );
+        assert_eq!(find_remote(&global, "other").unwrap(), None);
+    }
+}
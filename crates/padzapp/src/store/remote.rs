@@ -0,0 +1,139 @@
+//! # Remote Stores
+//!
+//! A project store on another machine, addressed as
+//! `ssh://[user@]host[:port]/path/to/project/.padz` (a path not ending in
+//! `.padz` gets it appended, as with `--data`).
+//!
+//! padz never reads a remote store in place. The client copies it into a
+//! local cache and opens the copy like any project store, so listing and
+//! viewing work unchanged — and keep working from the last copy when the host
+//! is unreachable:
+//!
+//! ```text
+//! <global data dir>/
+//! └── remotes/
+//!     └── devbox/
+//!         └── home_me_app/
+//!             └── .padz/      # the cached copy
+//! ```
+//!
+//! This module only parses addresses and places caches; fetching is the
+//! client's business (the padz CLI uses `sftp`). A cached copy is a snapshot,
+//! so remote scopes are read-only: changes would be lost on the next fetch.
+
+use crate::error::{PadzError, Result};
+use std::path::{Path, PathBuf};
+
+/// Directory under the global data dir holding cached remote stores.
+pub const REMOTES_DIR: &str = "remotes";
+
+/// A parsed `ssh://` store address.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct RemoteStore {
+    /// The address as given.
+    pub url: String,
+    /// `[user@]host`, as ssh takes it.
+    pub destination: String,
+    pub port: Option<u16>,
+    /// Absolute path of the `.padz` directory on the host.
+    pub path: String,
+}
+
+impl RemoteStore {
+    pub fn parse(url: &str) -> Result<RemoteStore> {
+        let invalid = |why: &str| PadzError::Api(format!("Invalid remote '{}': {}", url, why));
+        let rest = url
+            .strip_prefix("ssh://")
+            .ok_or_else(|| invalid("expected ssh://host/path"))?;
+        let (authority, path) = match rest.find('/') {
+            Some(slash) => rest.split_at(slash),
+            None => return Err(invalid("missing the store path")),
+        };
+        let (destination, port) = match authority.rsplit_once(':') {
+            Some((destination, port)) => {
+                let port = port.parse().map_err(|_| invalid("bad port"))?;
+                (destination, Some(port))
+            }
+            None => (authority, None),
+        };
+        let host = destination.rsplit('@').next().unwrap_or_default();
+        if host.is_empty() {
+            return Err(invalid("missing the host"));
+        }
+        let path = path.trim_end_matches('/');
+        if path.is_empty() {
+            return Err(invalid("missing the store path"));
+        }
+        let path = if path.ends_with("/.padz") {
+            path.to_string()
+        } else {
+            format!("{}/.padz", path)
+        };
+        Ok(RemoteStore {
+            url: url.to_string(),
+            destination: destination.to_string(),
+            port,
+            path,
+        })
+    }
+
+    /// Where the cached copy of this store lives under `global_dir`.
+    pub fn cache_dir(&self, global_dir: &Path) -> PathBuf {
+        let host = self
+            .destination
+            .rsplit('@')
+            .next()
+            .unwrap_or(&self.destination);
+        let project = self
+            .path
+            .trim_end_matches("/.padz")
+            .trim_matches('/')
+            .replace('/', "_");
+        global_dir
+            .join(REMOTES_DIR)
+            .join(host)
+            .join(if project.is_empty() { "_" } else { &project })
+            .join(".padz")
+    }
+}
+
+/// Whether `target` is a remote address rather than a registered name.
+pub fn is_remote_url(target: &str) -> bool {
+    target.starts_with("ssh://")
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_parses_host_user_port_and_path() {
+        let remote = RemoteStore::parse("ssh://me@devbox:2222/home/me/app/.padz").unwrap();
+        assert_eq!(remote.destination, "me@devbox");
+        assert_eq!(remote.port, Some(2222));
+        assert_eq!(remote.path, "/home/me/app/.padz");
+
+        let remote = RemoteStore::parse("ssh://devbox/srv/app/").unwrap();
+        assert_eq!(remote.destination, "devbox");
+        assert_eq!(remote.port, None);
+        assert_eq!(remote.path, "/srv/app/.padz");
+    }
+
+    #[test]
+    fn test_rejects_incomplete_addresses() {
+        assert!(RemoteStore::parse("devbox:/srv/app").is_err());
+        assert!(RemoteStore::parse("ssh://devbox").is_err());
+        assert!(RemoteStore::parse("ssh://devbox/").is_err());
+        assert!(RemoteStore::parse("ssh:///srv/app").is_err());
+        assert!(RemoteStore::parse("ssh://devbox:ssh/srv/app").is_err());
+    }
+
+    #[test]
+    fn test_cache_dir_is_per_host_and_project() {
+        let remote = RemoteStore::parse("ssh://me@devbox/home/me/app").unwrap();
+        assert_eq!(
+            remote.cache_dir(Path::new("/g")),
+            PathBuf::from("/g/remotes/devbox/home_me_app/.padz")
+        );
+    }
+}
@@ -0,0 +1,631 @@
+//! # Schema Versions and Migrations
+//!
+//! Every scope root records the version of the on-disk layout it was last
+//! written in:
+//!
+//! ```text
+//! .padz/
+//! └── schema.json         # {"version": 3}
+//! ```
+//!
+//! [`MIGRATIONS`] is the ordered list of steps between versions. Each step has
+//! an `up` (version − 1 → version) and a `down` (version → version − 1), and
+//! works on the files directly: a migration exists precisely because the
+//! current model can no longer read the old layout.
+//!
+//! [`upgrade`] runs on every open (from [`crate::init`]) and applies whatever
+//! `up` steps a store is missing, recording the version after each one — a
+//! step that fails leaves the store at the last version it reached, and the
+//! next open picks up from there. A store written by a *newer* padz is left
+//! alone and refused: it may hold files this build cannot read, which it
+//! would misread rather than fail on. It is never downgraded behind the
+//! user's back.
+//!
+//! Stores older than versioning carry no `schema.json`; their version is
+//! inferred from the layout ([`detect_version`]).
+//!
+//! ## Adding a migration
+//!
+//! 1. Write the `up` and `down` functions in this module.
+//! 2. Append a [`Migration`] to [`MIGRATIONS`]; its `version` is the new
+//!    [`CURRENT_VERSION`].
+//! 3. Test the round trip: `up` then `down` returns the original files.
+
+use super::{compression, encryption};
+use serde::{Deserialize, Serialize};
+use std::collections::{HashMap, HashSet};
+use std::fs;
+use std::io;
+use std::path::Path;
+use uuid::Uuid;
+
+/// Name of the schema version file at a scope root.
+pub const SCHEMA_FILE: &str = "schema.json";
+
+/// The layout version this build reads and writes.
+pub const CURRENT_VERSION: u32 = 3;
+
+/// One step between adjacent schema versions.
+pub struct Migration {
+    /// The version this step migrates *to*.
+    pub version: u32,
+    pub description: &'static str,
+    /// `version - 1` → `version`.
+    pub up: fn(&Path) -> io::Result<()>,
+    /// `version` → `version - 1`.
+    pub down: fn(&Path) -> io::Result<()>,
+}
+
+/// Every migration, in version order. Version 0 is the original flat layout.
+pub const MIGRATIONS: &[Migration] = &[
+    Migration {
+        version: 1,
+        description: "split the flat store into active/archived/deleted buckets",
+        up: flat_to_bucketed,
+        down: bucketed_to_flat,
+    },
+    Migration {
+        version: 2,
+        description: "allow gzipped pad bodies (pad-{uuid}.{ext}.gz)",
+        up: allow_compressed,
+        down: inflate_compressed,
+    },
+    Migration {
+        version: 3,
+        description: "allow encrypted pad bodies (pad-{uuid}.{ext}.enc) and the encryption marker",
+        up: allow_encrypted,
+        down: forbid_encrypted,
+    },
+];
+
+/// What [`upgrade`] found and did.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Upgrade {
+    /// Nothing at this path is a padz store yet.
+    NoStore,
+    /// Already at [`CURRENT_VERSION`].
+    Current,
+    /// Migrated up from `from`.
+    Migrated { from: u32 },
+    /// Written by a newer padz; left untouched, and not to be opened.
+    Newer { found: u32 },
+}
+
+#[derive(Serialize, Deserialize)]
+struct SchemaFile {
+    version: u32,
+}
+
+/// The schema version of the store at `scope_root`, or `None` when there is
+/// no store there.
+///
+/// Without a `schema.json`, a root `data.json` and no `active/` is the
+/// version-0 flat layout; an `active/` directory is the bucketed layout that
+/// predates versioning (version 1).
+pub fn detect_version(scope_root: &Path) -> io::Result<Option<u32>> {
+    let schema_path = scope_root.join(SCHEMA_FILE);
+    if schema_path.exists() {
+        let content = fs::read_to_string(&schema_path)?;
+        let schema: SchemaFile = serde_json::from_str(&content)
+            .map_err(|e| io::Error::new(io::ErrorKind::InvalidData, e))?;
+        return Ok(Some(schema.version));
+    }
+    if scope_root.join("active").is_dir() {
+        Ok(Some(1))
+    } else if scope_root.join("data.json").exists() {
+        Ok(Some(0))
+    } else {
+        Ok(None)
+    }
+}
+
+/// Records `version` for the store at `scope_root`. Version 0 predates
+/// `schema.json`, so recording it removes the file.
+pub fn write_version(scope_root: &Path, version: u32) -> io::Result<()> {
+    let schema_path = scope_root.join(SCHEMA_FILE);
+    if version == 0 {
+        if schema_path.exists() {
+            fs::remove_file(schema_path)?;
+        }
+        return Ok(());
+    }
+    let json = serde_json::to_string_pretty(&SchemaFile { version }).map_err(io::Error::other)?;
+    let tmp_file = scope_root.join(format!(".schema-{}.tmp", Uuid::new_v4()));
+    fs::write(&tmp_file, json)?;
+    fs::rename(&tmp_file, schema_path)
+}
+
+/// Brings the store at `scope_root` up to [`CURRENT_VERSION`].
+///
+/// Also stamps `schema.json` on current stores that lack one, so the version
+/// no longer has to be inferred.
+pub fn upgrade(scope_root: &Path) -> io::Result<Upgrade> {
+    let Some(found) = detect_version(scope_root)? else {
+        return Ok(Upgrade::NoStore);
+    };
+    if found > CURRENT_VERSION {
+        return Ok(Upgrade::Newer { found });
+    }
+    if found == CURRENT_VERSION {
+        if !scope_root.join(SCHEMA_FILE).exists() {
+            write_version(scope_root, CURRENT_VERSION)?;
+        }
+        return Ok(Upgrade::Current);
+    }
+    migrate_to(scope_root, CURRENT_VERSION)?;
+    Ok(Upgrade::Migrated { from: found })
+}
+
+/// Moves the store at `scope_root` to `target`, running `up` or `down` steps
+/// as needed, and returns the version it started at.
+///
+/// The version is recorded after every step. Errors if there is no store, or
+/// if either end is outside the versions this build knows.
+pub fn migrate_to(scope_root: &Path, target: u32) -> io::Result<u32> {
+    let Some(from) = detect_version(scope_root)? else {
+        return Err(io::Error::new(
+            io::ErrorKind::NotFound,
+            format!("no padz store at {}", scope_root.display()),
+        ));
+    };
+    for version in [from, target] {
+        if version > CURRENT_VERSION {
+            return Err(io::Error::new(
+                io::ErrorKind::Unsupported,
+                format!(
+                    "schema version {} is newer than this padz supports ({})",
+                    version, CURRENT_VERSION
+                ),
+            ));
+        }
+    }
+
+    let mut current = from;
+    while current < target {
+        let step = migration(current + 1);
+        (step.up)(scope_root)?;
+        current = step.version;
+        write_version(scope_root, current)?;
+    }
+    while current > target {
+        let step = migration(current);
+        (step.down)(scope_root)?;
+        current = step.version - 1;
+        write_version(scope_root, current)?;
+    }
+    Ok(from)
+}
+
+fn migration(version: u32) -> &'static Migration {
+    MIGRATIONS
+        .iter()
+        .find(|m| m.version == version)
+        .expect("MIGRATIONS covers every version up to CURRENT_VERSION")
+}
+
+// --- 1: flat → bucketed ---
+//
+// Version 0 (flat):
+// ```text
+// .padz/
+//   data.json        # All pads (active + deleted via is_deleted flag)
+//   pad-{uuid}.txt   # Content files
+//   tags.json        # Tags (stays in place)
+// ```
+//
+// Version 1 (bucketed):
+// ```text
+// .padz/
+//   tags.json        # Scope-level (shared)
+//   active/
+//     data.json      # Active pad metadata
+//     pad-{uuid}.txt
+//   archived/        # (empty after migration)
+//     data.json
+//   deleted/
+//     data.json      # Deleted pad metadata
+//     pad-{uuid}.txt
+// ```
+
+fn flat_to_bucketed(scope_root: &Path) -> io::Result<()> {
+    let legacy_data_path = scope_root.join("data.json");
+    let content = fs::read_to_string(&legacy_data_path)?;
+
+    let entries: HashMap<Uuid, serde_json::Value> = serde_json::from_str(&content)
+        .map_err(|e| io::Error::new(io::ErrorKind::InvalidData, e))?;
+
+    // Partition entries by is_deleted flag
+    let mut active_entries: HashMap<Uuid, serde_json::Value> = HashMap::new();
+    let mut deleted_entries: HashMap<Uuid, serde_json::Value> = HashMap::new();
+
+    for (id, mut value) in entries {
+        let is_deleted = value
+            .get("is_deleted")
+            .and_then(|v| v.as_bool())
+            .unwrap_or(false);
+
+        // Strip legacy fields
+        if let Some(obj) = value.as_object_mut() {
+            obj.remove("is_deleted");
+            obj.remove("deleted_at");
+        }
+
+        if is_deleted {
+            deleted_entries.insert(id, value);
+        } else {
+            active_entries.insert(id, value);
+        }
+    }
+
+    // Create bucket directories
+    let active_dir = scope_root.join("active");
+    let archived_dir = scope_root.join("archived");
+    let deleted_dir = scope_root.join("deleted");
+    fs::create_dir_all(&active_dir)?;
+    fs::create_dir_all(&archived_dir)?;
+    fs::create_dir_all(&deleted_dir)?;
+
+    // Write bucket data.json files
+    let active_json = serde_json::to_string_pretty(&active_entries).map_err(io::Error::other)?;
+    fs::write(active_dir.join("data.json"), active_json)?;
+
+    let deleted_json = serde_json::to_string_pretty(&deleted_entries).map_err(io::Error::other)?;
+    fs::write(deleted_dir.join("data.json"), deleted_json)?;
+
+    // Write empty archived data.json
+    fs::write(archived_dir.join("data.json"), "{}")?;
+
+    // Move content files to their respective bucket directories
+    let all_active_ids: HashSet<Uuid> = active_entries.keys().copied().collect();
+    let all_deleted_ids: HashSet<Uuid> = deleted_entries.keys().copied().collect();
+
+    let dir_entries = fs::read_dir(scope_root)?;
+    for entry in dir_entries {
+        let entry = entry?;
+        let path = entry.path();
+
+        if !path.is_file() {
+            continue;
+        }
+        let Some(name) = path.file_name().and_then(|s| s.to_str()) else {
+            continue;
+        };
+        if !name.starts_with("pad-") {
+            continue;
+        }
+
+        // Extract UUID from filename: pad-{uuid}.ext
+        let stem = path.file_stem().and_then(|s| s.to_str()).unwrap_or("");
+        let uuid_part = stem.strip_prefix("pad-").unwrap_or("");
+        let Ok(id) = Uuid::parse_str(uuid_part) else {
+            continue;
+        };
+
+        let dest_dir = if all_deleted_ids.contains(&id) {
+            &deleted_dir
+        } else if all_active_ids.contains(&id) {
+            &active_dir
+        } else {
+            // Orphan file — move to active (doctor will handle it)
+            &active_dir
+        };
+
+        fs::rename(&path, dest_dir.join(name))?;
+    }
+
+    // Remove legacy data.json
+    fs::remove_file(&legacy_data_path)?;
+
+    Ok(())
+}
+
+/// The inverse of [`flat_to_bucketed`]. Version 0 has no archive, so archived
+/// pads come back as ordinary active pads.
+fn bucketed_to_flat(scope_root: &Path) -> io::Result<()> {
+    let mut entries: HashMap<Uuid, serde_json::Value> = HashMap::new();
+
+    for bucket in ["active", "archived", "deleted"] {
+        let bucket_dir = scope_root.join(bucket);
+        if !bucket_dir.is_dir() {
+            continue;
+        }
+
+        let data_path = bucket_dir.join("data.json");
+        if data_path.exists() {
+            let content = fs::read_to_string(&data_path)?;
+            let bucket_entries: HashMap<Uuid, serde_json::Value> =
+                serde_json::from_str(&content)
+                    .map_err(|e| io::Error::new(io::ErrorKind::InvalidData, e))?;
+            for (id, mut value) in bucket_entries {
+                if let Some(obj) = value.as_object_mut() {
+                    obj.insert(
+                        "is_deleted".to_string(),
+                        serde_json::Value::Bool(bucket == "deleted"),
+                    );
+                }
+                entries.insert(id, value);
+            }
+        }
+
+        // Content files go back to the root
+        for entry in fs::read_dir(&bucket_dir)? {
+            let path = entry?.path();
+            let Some(name) = path.file_name().and_then(|s| s.to_str()) else {
+                continue;
+            };
+            if path.is_file() && name.starts_with("pad-") {
+                fs::rename(&path, scope_root.join(name))?;
+            }
+        }
+    }
+
+    let json = serde_json::to_string_pretty(&entries).map_err(io::Error::other)?;
+    fs::write(scope_root.join("data.json"), json)?;
+
+    // Remove the emptied buckets; anything unexpected left inside makes
+    // remove_dir fail rather than silently disappear.
+    for bucket in ["active", "archived", "deleted"] {
+        let bucket_dir = scope_root.join(bucket);
+        if !bucket_dir.is_dir() {
+            continue;
+        }
+        let data_path = bucket_dir.join("data.json");
+        if data_path.exists() {
+            fs::remove_file(data_path)?;
+        }
+        fs::remove_dir(bucket_dir)?;
+    }
+
+    Ok(())
+}
+
+// --- 2: compressed bodies ---
+//
+// Version 2 lets any bucket hold a body gzipped, as `pad-{uuid}.{ext}.gz`
+// (see [`super::compression`]). A version-1 padz would take the gzip bytes
+// for the body, so the version marks the store as one it must not open. Nothing on disk changes on the way up; on the way down
+// every compressed body is written plain again.
+
+fn allow_compressed(_scope_root: &Path) -> io::Result<()> {
+    Ok(())
+}
+
+fn inflate_compressed(scope_root: &Path) -> io::Result<()> {
+    for bucket in ["active", "archived", "deleted"] {
+        let Ok(entries) = fs::read_dir(scope_root.join(bucket)) else {
+            continue;
+        };
+        for entry in entries {
+            let path = entry?.path();
+            let name = path.file_name().unwrap_or_default().to_string_lossy();
+            if !name.starts_with("pad-") || !compression::is_compressed(&path) {
+                continue;
+            }
+            let content = compression::decompress(&fs::read(&path)?)?;
+            fs::write(path.with_extension(""), content)?;
+            fs::remove_file(&path)?;
+        }
+    }
+    Ok(())
+}
+
+// --- 3: encrypted bodies ---
+//
+// Version 3 lets a bucket hold a body encrypted, as `pad-{uuid}.{ext}.enc`,
+// and a scope root hold the marker that turns encryption on (see
+// [`super::encryption`]). A version-2 padz would take the ciphertext for the
+// body and ignore the marker, writing new bodies in the clear. Nothing on
+// disk changes on the way up. On the way down the marker goes; encrypted
+// bodies would need the secret key, which a migration does not have, so a
+// store holding any is left at version 3 until `padz encrypt disable` has
+// decrypted them.
+
+fn allow_encrypted(_scope_root: &Path) -> io::Result<()> {
+    Ok(())
+}
+
+fn forbid_encrypted(scope_root: &Path) -> io::Result<()> {
+    for bucket in ["active", "archived", "deleted"] {
+        let Ok(entries) = fs::read_dir(scope_root.join(bucket)) else {
+            continue;
+        };
+        for entry in entries {
+            let path = entry?.path();
+            let name = path.file_name().unwrap_or_default().to_string_lossy();
+            if name.starts_with("pad-") && encryption::is_encrypted(&path) {
+                return Err(io::Error::other(format!(
+                    "{} holds encrypted pads; run `padz encrypt disable` first",
+                    scope_root.display()
+                )));
+            }
+        }
+    }
+    let marker = scope_root.join(encryption::MARKER_FILE);
+    if marker.exists() {
+        fs::remove_file(marker)?;
+    }
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    fn flat_store(root: &Path) -> (Uuid, Uuid) {
+        fs::create_dir_all(root).unwrap();
+        let live = Uuid::new_v4();
+        let gone = Uuid::new_v4();
+        let data = serde_json::json!({
+            live.to_string(): {"id": live.to_string(), "title": "Live", "is_deleted": false},
+            gone.to_string(): {"id": gone.to_string(), "title": "Gone", "is_deleted": true},
+        });
+        fs::write(root.join("data.json"), data.to_string()).unwrap();
+        fs::write(root.join(format!("pad-{}.txt", live)), "live").unwrap();
+        fs::write(root.join(format!("pad-{}.txt", gone)), "gone").unwrap();
+        (live, gone)
+    }
+
+    fn read_index(path: &Path) -> HashMap<Uuid, serde_json::Value> {
+        serde_json::from_str(&fs::read_to_string(path).unwrap()).unwrap()
+    }
+
+    #[test]
+    fn migrations_are_contiguous_up_to_current() {
+        let versions: Vec<u32> = MIGRATIONS.iter().map(|m| m.version).collect();
+        let expected: Vec<u32> = (1..=CURRENT_VERSION).collect();
+        assert_eq!(versions, expected);
+    }
+
+    #[test]
+    fn detect_version_infers_unversioned_layouts() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path().join(".padz");
+        assert_eq!(detect_version(&root).unwrap(), None);
+
+        flat_store(&root);
+        assert_eq!(detect_version(&root).unwrap(), Some(0));
+
+        fs::create_dir_all(root.join("active")).unwrap();
+        assert_eq!(detect_version(&root).unwrap(), Some(1));
+
+        write_version(&root, 7).unwrap();
+        assert_eq!(detect_version(&root).unwrap(), Some(7));
+    }
+
+    #[test]
+    fn upgrade_migrates_and_records_the_version() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path().join(".padz");
+        let (live, gone) = flat_store(&root);
+
+        assert_eq!(upgrade(&root).unwrap(), Upgrade::Migrated { from: 0 });
+        assert!(root.join(SCHEMA_FILE).exists());
+        assert_eq!(detect_version(&root).unwrap(), Some(CURRENT_VERSION));
+        assert!(read_index(&root.join("active/data.json")).contains_key(&live));
+        assert!(read_index(&root.join("deleted/data.json")).contains_key(&gone));
+
+        assert_eq!(upgrade(&root).unwrap(), Upgrade::Current);
+    }
+
+    #[test]
+    fn upgrade_stamps_current_stores_without_a_schema_file() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path().join(".padz");
+        fs::create_dir_all(root.join("active")).unwrap();
+
+        assert_eq!(upgrade(&root).unwrap(), Upgrade::Current);
+        assert!(root.join(SCHEMA_FILE).exists());
+    }
+
+    #[test]
+    fn upgrade_leaves_nothing_behind_where_there_is_no_store() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path().join(".padz");
+        fs::create_dir_all(&root).unwrap();
+
+        assert_eq!(upgrade(&root).unwrap(), Upgrade::NoStore);
+        assert!(!root.join(SCHEMA_FILE).exists());
+    }
+
+    #[test]
+    fn upgrade_leaves_newer_stores_alone() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path().join(".padz");
+        fs::create_dir_all(root.join("active")).unwrap();
+        write_version(&root, CURRENT_VERSION + 1).unwrap();
+
+        assert_eq!(
+            upgrade(&root).unwrap(),
+            Upgrade::Newer {
+                found: CURRENT_VERSION + 1
+            }
+        );
+        assert!(migrate_to(&root, 0).is_err());
+    }
+
+    #[test]
+    fn down_reverses_up() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path().join(".padz");
+        let (live, gone) = flat_store(&root);
+        let original = read_index(&root.join("data.json"));
+
+        migrate_to(&root, CURRENT_VERSION).unwrap();
+        assert_eq!(migrate_to(&root, 0).unwrap(), CURRENT_VERSION);
+
+        assert_eq!(detect_version(&root).unwrap(), Some(0));
+        assert!(!root.join(SCHEMA_FILE).exists());
+        assert!(!root.join("active").exists());
+        assert!(!root.join("deleted").exists());
+        assert_eq!(read_index(&root.join("data.json")), original);
+        assert_eq!(
+            fs::read_to_string(root.join(format!("pad-{}.txt", live))).unwrap(),
+            "live"
+        );
+        assert_eq!(
+            fs::read_to_string(root.join(format!("pad-{}.txt", gone))).unwrap(),
+            "gone"
+        );
+    }
+
+    #[test]
+    fn down_from_2_inflates_compressed_bodies() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path().join(".padz");
+        fs::create_dir_all(root.join("active")).unwrap();
+        fs::create_dir_all(root.join("archived")).unwrap();
+        migrate_to(&root, 2).unwrap();
+        let id = Uuid::new_v4();
+        let body = "error: flaky test\n".repeat(50);
+        fs::write(
+            root.join(format!("archived/pad-{}.txt.gz", id)),
+            compression::compress(&body).unwrap(),
+        )
+        .unwrap();
+
+        migrate_to(&root, 1).unwrap();
+
+        assert_eq!(detect_version(&root).unwrap(), Some(1));
+        assert!(!root.join(format!("archived/pad-{}.txt.gz", id)).exists());
+        assert_eq!(
+            fs::read_to_string(root.join(format!("archived/pad-{}.txt", id))).unwrap(),
+            body
+        );
+    }
+
+    #[test]
+    fn down_from_3_needs_the_bodies_decrypted_first() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path().join(".padz");
+        fs::create_dir_all(root.join("active")).unwrap();
+        migrate_to(&root, 3).unwrap();
+        fs::write(root.join(encryption::MARKER_FILE), "").unwrap();
+        let sealed = root.join(format!("active/pad-{}.txt.enc", Uuid::new_v4()));
+        fs::write(&sealed, "ciphertext").unwrap();
+
+        assert!(migrate_to(&root, 2).is_err());
+        assert_eq!(detect_version(&root).unwrap(), Some(3));
+
+        fs::remove_file(&sealed).unwrap();
+        migrate_to(&root, 2).unwrap();
+        assert_eq!(detect_version(&root).unwrap(), Some(2));
+        assert!(!root.join(encryption::MARKER_FILE).exists());
+    }
+
+    #[test]
+    fn down_folds_archived_pads_into_active() {
+        let temp = TempDir::new().unwrap();
+        let root = temp.path().join(".padz");
+        fs::create_dir_all(root.join("archived")).unwrap();
+        let id = Uuid::new_v4();
+        let data = serde_json::json!({ id.to_string(): {"id": id.to_string(), "title": "Old"} });
+        fs::write(root.join("archived/data.json"), data.to_string()).unwrap();
+        fs::create_dir_all(root.join("active")).unwrap();
+
+        migrate_to(&root, 0).unwrap();
+
+        let index = read_index(&root.join("data.json"));
+        assert_eq!(index[&id]["is_deleted"], serde_json::Value::Bool(false));
+    }
+}
@@ -0,0 +1,217 @@
+//! # Undo Records
+//!
+//! Every commit (see [`super::journal`]) also notes how to take itself back:
+//! before applying its writes, [`BucketedStore`](super::bucketed::BucketedStore)
+//! compares them with what they replace and saves an [`UndoRecord`] as
+//! `undo.json` at the scope root, replacing the last one. `padz undo` reads it
+//! back (see [`crate::commands::undo`]).
+//!
+//! The record is per pad, not per command: each pad the commit created,
+//! rewrote, moved between buckets or removed, with its index entry before and
+//! after, and the body it had when the commit wrote or removed it. A rewritten
+//! tag registry is kept whole, before and after. Whatever the command was —
+//! delete, purge, pin, tag, move — undoing it is the same: check every pad is
+//! still as the commit left it, then put back what was there before.
+//!
+//! Only commits are recorded. A write made outside a transaction, such as
+//! noting that a pad was read, leaves the record alone. The record is saved
+//! before the journal, so a crash in between leaves a record of writes that
+//! never happened; the check refuses to undo those, as it would any pad
+//! changed since.
+
+use super::backend::StorageBackend;
+use super::write_guard::StagedWrites;
+use super::Bucket;
+use crate::error::Result;
+use crate::model::{Metadata, Scope};
+use crate::tags::TagEntry;
+use chrono::{DateTime, Utc};
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeMap, BTreeSet};
+use uuid::Uuid;
+
+/// What one commit changed in one scope.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct UndoRecord {
+    pub at: DateTime<Utc>,
+    pub pads: Vec<PadChange>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub tags: Option<TagsChange>,
+}
+
+impl UndoRecord {
+    pub fn is_empty(&self) -> bool {
+        self.pads.is_empty() && self.tags.is_none()
+    }
+}
+
+/// One pad, as it was before the commit and as the commit left it. `None`
+/// on either side means the pad was not in the scope at all.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct PadChange {
+    pub id: Uuid,
+    pub before: Option<PadState>,
+    pub after: Option<PadState>,
+}
+
+/// Where a pad was and what its index entry said.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct PadState {
+    pub bucket: Bucket,
+    pub metadata: Metadata,
+    /// The body, kept only on the `before` side and only when the commit
+    /// rewrote or removed it; otherwise the body on disk is still the one.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub content: Option<String>,
+}
+
+/// The tag registry before and after a commit that rewrote it.
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct TagsChange {
+    pub before: Vec<TagEntry>,
+    pub after: Vec<TagEntry>,
+}
+
+/// Compares a commit's writes to `scope` with what the backends hold now,
+/// before the writes are applied. `buckets` pairs each bucket's backend with
+/// its writes; `root` is the scope-root backend with the tag registry's.
+pub fn record<B: StorageBackend>(
+    scope: Scope,
+    buckets: [(Bucket, &B, &StagedWrites); 3],
+    root: (&B, &StagedWrites),
+    at: DateTime<Utc>,
+) -> Result<UndoRecord> {
+    let mut pads: BTreeMap<Uuid, PadChange> = BTreeMap::new();
+    for (bucket, backend, writes) in buckets {
+        let Some(after) = &writes.index else {
+            continue;
+        };
+        let before = backend.load_index(scope)?;
+        let ids: BTreeSet<&Uuid> = before.keys().chain(after.keys()).collect();
+        for id in ids {
+            let (was, is) = (before.get(id), after.get(id));
+            if was == is {
+                continue;
+            }
+            let change = pads.entry(*id).or_insert(PadChange {
+                id: *id,
+                before: None,
+                after: None,
+            });
+            if let Some(metadata) = was {
+                let content = if writes.content.contains_key(id) {
+                    backend.read_content(id, scope)?
+                } else {
+                    None
+                };
+                change.before = Some(PadState {
+                    bucket,
+                    metadata: metadata.clone(),
+                    content,
+                });
+            }
+            if let Some(metadata) = is {
+                change.after = Some(PadState {
+                    bucket,
+                    metadata: metadata.clone(),
+                    content: None,
+                });
+            }
+        }
+    }
+
+    let (backend, writes) = root;
+    let tags = match &writes.tags {
+        Some(after) => {
+            let before = backend.load_tags(scope)?;
+            (&before != after).then(|| TagsChange {
+                before,
+                after: after.clone(),
+            })
+        }
+        None => None,
+    };
+    Ok(UndoRecord {
+        at,
+        pads: pads.into_values().collect(),
+        tags,
+    })
+}
+
+/// Whether `found` is the pad `expected` describes, leaving aside what reads
+/// move on their own: the access time, the modification time reconciliation
+/// settles on the file's, and the history.
+pub fn unchanged(expected: &Metadata, found: &Metadata) -> bool {
+    let settle = |m: &Metadata| Metadata {
+        updated_at: m.created_at,
+        last_accessed_at: None,
+        history: Vec::new(),
+        ..m.clone()
+    };
+    settle(expected) == settle(found)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::store::mem_backend::MemBackend;
+    use std::collections::HashMap;
+
+    #[test]
+    fn a_move_between_buckets_is_one_change_with_the_body_it_had() {
+        let active = MemBackend::new();
+        let deleted = MemBackend::new();
+        let archived = MemBackend::new();
+        let root = MemBackend::new();
+        let metadata = Metadata::new("Groceries".into());
+        let id = metadata.id;
+        active
+            .save_index(Scope::Project, &HashMap::from([(id, metadata.clone())]))
+            .unwrap();
+        active
+            .write_content(&id, Scope::Project, "Groceries")
+            .unwrap();
+
+        // A delete: out of active, into deleted.
+        let out = StagedWrites {
+            index: Some(HashMap::new()),
+            content: HashMap::from([(id, None)]),
+            ..Default::default()
+        };
+        let into = StagedWrites {
+            index: Some(HashMap::from([(id, metadata.clone())])),
+            content: HashMap::from([(id, Some("Groceries".into()))]),
+            ..Default::default()
+        };
+        let untouched = StagedWrites::default();
+        let record = record(
+            Scope::Project,
+            [
+                (Bucket::Active, &active, &out),
+                (Bucket::Archived, &archived, &untouched),
+                (Bucket::Deleted, &deleted, &into),
+            ],
+            (&root, &untouched),
+            Utc::now(),
+        )
+        .unwrap();
+
+        assert_eq!(record.pads.len(), 1);
+        let change = &record.pads[0];
+        let before = change.before.as_ref().unwrap();
+        assert_eq!(before.bucket, Bucket::Active);
+        assert_eq!(before.content.as_deref(), Some("Groceries"));
+        assert_eq!(change.after.as_ref().unwrap().bucket, Bucket::Deleted);
+        assert!(record.tags.is_none());
+    }
+
+    #[test]
+    fn reading_a_pad_does_not_change_it() {
+        let expected = Metadata::new("Groceries".into());
+        let mut found = expected.clone();
+        found.last_accessed_at = Some(Utc::now());
+        assert!(unchanged(&expected, &found));
+        found.is_pinned = true;
+        assert!(!unchanged(&expected, &found));
+    }
+}
@@ -0,0 +1,460 @@
+//! # Write Guard
+//!
+//! [`WriteGuard`] wraps a [`StorageBackend`] and can hold writes in memory instead
+//! of passing them through. Reads see the held writes layered over the real
+//! storage, so a command runs exactly as it would — resolving selectors,
+//! validating, re-reading what it just wrote — while the underlying storage is
+//! untouched. The guard has two uses:
+//!
+//! - **Dry runs** ([`WriteGuard::arm`]): writes are held for the rest of the
+//!   guard's life and never applied. This is how `--dry-run` works for every
+//!   command at once: the commands do not know they are being simulated.
+//! - **Transactions** ([`WriteGuard::stage`]): writes are held until the
+//!   [`BucketedStore`](super::bucketed::BucketedStore) commits them, as one
+//!   journaled unit, or drops them on rollback. See [`super::journal`].
+//!
+//! Otherwise the guard is a pass-through.
+
+use super::backend::StorageBackend;
+use crate::error::Result;
+use crate::model::{Metadata, Scope};
+use crate::tags::TagEntry;
+use chrono::{DateTime, Utc};
+use serde::{Deserialize, Serialize};
+use std::cell::RefCell;
+use std::collections::HashMap;
+use std::ops::{Deref, DerefMut};
+use std::path::PathBuf;
+use uuid::Uuid;
+
+/// Writes held back while the guard is armed or staging.
+///
+/// A content entry of `None` records a deletion, so the file disappears from
+/// reads and listings even though it is still on disk.
+#[derive(Clone, Default)]
+struct Overlay {
+    index: HashMap<Scope, HashMap<Uuid, Metadata>>,
+    tags: HashMap<Scope, Vec<TagEntry>>,
+    content: HashMap<(Scope, Uuid), Option<(String, DateTime<Utc>)>>,
+}
+
+/// The writes a transaction staged in one scope of one backend, in the form
+/// they are journaled and applied.
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+pub struct StagedWrites {
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub index: Option<HashMap<Uuid, Metadata>>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub tags: Option<Vec<TagEntry>>,
+    /// New content per pad; `None` deletes the pad's file.
+    #[serde(default, skip_serializing_if = "HashMap::is_empty")]
+    pub content: HashMap<Uuid, Option<String>>,
+}
+
+impl StagedWrites {
+    pub fn is_empty(&self) -> bool {
+        self.index.is_none() && self.tags.is_none() && self.content.is_empty()
+    }
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum Mode {
+    /// Writes go straight through.
+    Direct,
+    /// Writes are held until taken by a commit or discarded by a rollback.
+    Staging,
+    /// Writes are held for good.
+    DryRun,
+}
+
+/// A [`StorageBackend`] that can hold its writes back (see the module docs).
+///
+/// Derefs to the wrapped backend so backend-specific configuration (e.g. the file
+/// format of an `FsBackend`) stays reachable.
+pub struct WriteGuard<B: StorageBackend> {
+    inner: B,
+    overlay: RefCell<Overlay>,
+    mode: Mode,
+}
+
+impl<B: StorageBackend> WriteGuard<B> {
+    /// A pass-through guard: every call goes straight to `inner`.
+    pub fn new(inner: B) -> Self {
+        Self {
+            inner,
+            overlay: RefCell::new(Overlay::default()),
+            mode: Mode::Direct,
+        }
+    }
+
+    /// Hold all further writes in memory for good. Writes made before arming are
+    /// real; writes staged by an open transaction stay held.
+    pub fn arm(&mut self) {
+        self.mode = Mode::DryRun;
+    }
+
+    pub fn is_armed(&self) -> bool {
+        self.mode == Mode::DryRun
+    }
+
+    /// Start holding writes for a transaction. No effect on an armed guard,
+    /// whose writes are held anyway.
+    pub fn stage(&mut self) {
+        if self.mode == Mode::Direct {
+            self.mode = Mode::Staging;
+        }
+    }
+
+    /// Hand over the writes held since [`stage`](Self::stage), per scope, and go
+    /// back to passing writes through. An armed guard hands over nothing: its
+    /// writes must never be applied.
+    pub fn take_staged(&mut self) -> HashMap<Scope, StagedWrites> {
+        if self.mode != Mode::Staging {
+            return HashMap::new();
+        }
+        self.mode = Mode::Direct;
+        by_scope(self.overlay.take())
+    }
+
+    /// The writes held so far, per scope, left where they are: on an armed
+    /// guard, everything the dry run would have written.
+    pub fn held_writes(&self) -> HashMap<Scope, StagedWrites> {
+        match self.held() {
+            Some(overlay) => by_scope(overlay.borrow().clone()),
+            None => HashMap::new(),
+        }
+    }
+
+    /// Drop the writes held since [`stage`](Self::stage).
+    pub fn discard_staged(&mut self) {
+        if self.mode == Mode::Staging {
+            self.mode = Mode::Direct;
+            self.overlay.take();
+        }
+    }
+
+    /// Write `writes` straight to the wrapped backend, whatever the mode.
+    ///
+    /// Content is written before the index and deletions come last, the same
+    /// orphan-safe order as a direct save. Applying the same writes twice is
+    /// harmless, which is what lets an interrupted commit be replayed.
+    pub fn apply(&self, scope: Scope, writes: &StagedWrites) -> Result<()> {
+        for (id, text) in &writes.content {
+            if let Some(text) = text {
+                self.inner.write_content(id, scope, text)?;
+            }
+        }
+        if let Some(index) = &writes.index {
+            self.inner.save_index(scope, index)?;
+        }
+        if let Some(tags) = &writes.tags {
+            self.inner.save_tags(scope, tags)?;
+        }
+        for (id, text) in &writes.content {
+            if text.is_none() {
+                self.inner.delete_content(id, scope)?;
+            }
+        }
+        Ok(())
+    }
+
+    /// The overlay, when writes are being held.
+    fn held(&self) -> Option<&RefCell<Overlay>> {
+        (self.mode != Mode::Direct).then_some(&self.overlay)
+    }
+}
+
+/// `overlay`'s writes per scope, in the form they are journaled.
+fn by_scope(overlay: Overlay) -> HashMap<Scope, StagedWrites> {
+    let mut staged: HashMap<Scope, StagedWrites> = HashMap::new();
+    for (scope, index) in overlay.index {
+        staged.entry(scope).or_default().index = Some(index);
+    }
+    for (scope, tags) in overlay.tags {
+        staged.entry(scope).or_default().tags = Some(tags);
+    }
+    for ((scope, id), entry) in overlay.content {
+        staged
+            .entry(scope)
+            .or_default()
+            .content
+            .insert(id, entry.map(|(text, _)| text));
+    }
+    staged
+}
+
+impl<B: StorageBackend> Deref for WriteGuard<B> {
+    type Target = B;
+
+    fn deref(&self) -> &B {
+        &self.inner
+    }
+}
+
+impl<B: StorageBackend> DerefMut for WriteGuard<B> {
+    fn deref_mut(&mut self) -> &mut B {
+        &mut self.inner
+    }
+}
+
+impl<B: StorageBackend> StorageBackend for WriteGuard<B> {
+    fn load_index(&self, scope: Scope) -> Result<HashMap<Uuid, Metadata>> {
+        if let Some(overlay) = self.held() {
+            if let Some(index) = overlay.borrow().index.get(&scope) {
+                return Ok(index.clone());
+            }
+        }
+        self.inner.load_index(scope)
+    }
+
+    fn save_index(&self, scope: Scope, index: &HashMap<Uuid, Metadata>) -> Result<()> {
+        match self.held() {
+            Some(overlay) => {
+                overlay.borrow_mut().index.insert(scope, index.clone());
+                Ok(())
+            }
+            None => self.inner.save_index(scope, index),
+        }
+    }
+
+    fn load_tags(&self, scope: Scope) -> Result<Vec<TagEntry>> {
+        if let Some(overlay) = self.held() {
+            if let Some(tags) = overlay.borrow().tags.get(&scope) {
+                return Ok(tags.clone());
+            }
+        }
+        self.inner.load_tags(scope)
+    }
+
+    fn save_tags(&self, scope: Scope, tags: &[TagEntry]) -> Result<()> {
+        match self.held() {
+            Some(overlay) => {
+                overlay.borrow_mut().tags.insert(scope, tags.to_vec());
+                Ok(())
+            }
+            None => self.inner.save_tags(scope, tags),
+        }
+    }
+
+    // The journal is how held writes become real, so it is never held itself.
+
+    fn load_journal(&self, scope: Scope) -> Result<Option<String>> {
+        self.inner.load_journal(scope)
+    }
+
+    fn save_journal(&self, scope: Scope, journal: &str) -> Result<()> {
+        self.inner.save_journal(scope, journal)
+    }
+
+    fn clear_journal(&self, scope: Scope) -> Result<()> {
+        self.inner.clear_journal(scope)
+    }
+
+    // Saved only by a commit, never by a dry run, so never held either.
+
+    fn load_undo(&self, scope: Scope) -> Result<Option<String>> {
+        self.inner.load_undo(scope)
+    }
+
+    fn save_undo(&self, scope: Scope, record: &str) -> Result<()> {
+        self.inner.save_undo(scope, record)
+    }
+
+    fn read_content(&self, id: &Uuid, scope: Scope) -> Result<Option<String>> {
+        if let Some(overlay) = self.held() {
+            if let Some(entry) = overlay.borrow().content.get(&(scope, *id)) {
+                return Ok(entry.as_ref().map(|(text, _)| text.clone()));
+            }
+        }
+        self.inner.read_content(id, scope)
+    }
+
+    fn write_content(&self, id: &Uuid, scope: Scope, content: &str) -> Result<()> {
+        match self.held() {
+            Some(overlay) => {
+                overlay
+                    .borrow_mut()
+                    .content
+                    .insert((scope, *id), Some((content.to_string(), Utc::now())));
+                Ok(())
+            }
+            None => self.inner.write_content(id, scope, content),
+        }
+    }
+
+    fn delete_content(&self, id: &Uuid, scope: Scope) -> Result<()> {
+        match self.held() {
+            Some(overlay) => {
+                overlay.borrow_mut().content.insert((scope, *id), None);
+                Ok(())
+            }
+            None => self.inner.delete_content(id, scope),
+        }
+    }
+
+    fn list_content_ids(&self, scope: Scope) -> Result<Vec<Uuid>> {
+        let mut ids = self.inner.list_content_ids(scope)?;
+        if let Some(overlay) = self.held() {
+            let overlay = overlay.borrow();
+            let kept = |id: &Uuid| match overlay.content.get(&(scope, *id)) {
+                Some(entry) => entry.is_some(),
+                None => true,
+            };
+            ids.retain(kept);
+            for ((entry_scope, id), entry) in &overlay.content {
+                if *entry_scope == scope && entry.is_some() && !ids.contains(id) {
+                    ids.push(*id);
+                }
+            }
+        }
+        Ok(ids)
+    }
+
+    fn content_mtime(&self, id: &Uuid, scope: Scope) -> Result<Option<DateTime<Utc>>> {
+        if let Some(overlay) = self.held() {
+            if let Some(entry) = overlay.borrow().content.get(&(scope, *id)) {
+                return Ok(entry.as_ref().map(|(_, mtime)| *mtime));
+            }
+        }
+        self.inner.content_mtime(id, scope)
+    }
+
+    fn content_path(&self, id: &Uuid, scope: Scope) -> Result<PathBuf> {
+        self.inner.content_path(id, scope)
+    }
+
+    fn scope_available(&self, scope: Scope) -> bool {
+        self.inner.scope_available(scope)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::store::mem_backend::MemBackend;
+
+    fn armed_over(inner: MemBackend) -> WriteGuard<MemBackend> {
+        let mut guard = WriteGuard::new(inner);
+        guard.arm();
+        guard
+    }
+
+    #[test]
+    fn disarmed_guard_passes_writes_through() {
+        let guard = WriteGuard::new(MemBackend::new());
+        let id = Uuid::new_v4();
+
+        guard.write_content(&id, Scope::Project, "Title").unwrap();
+
+        assert!(!guard.is_armed());
+        assert_eq!(
+            guard.inner.read_content(&id, Scope::Project).unwrap(),
+            Some("Title".to_string())
+        );
+    }
+
+    #[test]
+    fn armed_guard_reads_its_own_writes_but_leaves_storage_alone() {
+        let guard = armed_over(MemBackend::new());
+        let id = Uuid::new_v4();
+
+        guard.write_content(&id, Scope::Project, "Title").unwrap();
+        guard
+            .save_index(
+                Scope::Project,
+                &HashMap::from([(id, Metadata::new("Title".into()))]),
+            )
+            .unwrap();
+
+        assert_eq!(
+            guard.read_content(&id, Scope::Project).unwrap(),
+            Some("Title".to_string())
+        );
+        assert_eq!(guard.list_content_ids(Scope::Project).unwrap(), vec![id]);
+        assert_eq!(guard.load_index(Scope::Project).unwrap().len(), 1);
+        assert!(guard
+            .inner
+            .read_content(&id, Scope::Project)
+            .unwrap()
+            .is_none());
+        assert!(guard.inner.load_index(Scope::Project).unwrap().is_empty());
+    }
+
+    #[test]
+    fn staged_writes_are_held_until_taken_and_applied() {
+        let inner = MemBackend::new();
+        let kept = Uuid::new_v4();
+        inner.write_content(&kept, Scope::Project, "Kept").unwrap();
+        let mut guard = WriteGuard::new(inner);
+        let id = Uuid::new_v4();
+
+        guard.stage();
+        guard.write_content(&id, Scope::Project, "Title").unwrap();
+        guard.delete_content(&kept, Scope::Project).unwrap();
+        assert!(guard
+            .inner
+            .read_content(&id, Scope::Project)
+            .unwrap()
+            .is_none());
+
+        let staged = guard.take_staged().remove(&Scope::Project).unwrap();
+        guard.apply(Scope::Project, &staged).unwrap();
+
+        assert_eq!(
+            guard.inner.read_content(&id, Scope::Project).unwrap(),
+            Some("Title".to_string())
+        );
+        assert!(guard
+            .inner
+            .read_content(&kept, Scope::Project)
+            .unwrap()
+            .is_none());
+    }
+
+    #[test]
+    fn discarded_writes_never_reach_storage() {
+        let mut guard = WriteGuard::new(MemBackend::new());
+        let id = Uuid::new_v4();
+
+        guard.stage();
+        guard.write_content(&id, Scope::Project, "Title").unwrap();
+        guard.discard_staged();
+
+        assert!(guard.read_content(&id, Scope::Project).unwrap().is_none());
+        assert!(guard
+            .inner
+            .read_content(&id, Scope::Project)
+            .unwrap()
+            .is_none());
+    }
+
+    #[test]
+    fn armed_guard_hands_over_nothing() {
+        let mut guard = armed_over(MemBackend::new());
+        guard.stage();
+        guard
+            .write_content(&Uuid::new_v4(), Scope::Project, "Title")
+            .unwrap();
+
+        assert!(guard.take_staged().is_empty());
+        assert!(guard.is_armed());
+    }
+
+    #[test]
+    fn armed_deletion_hides_the_file_without_removing_it() {
+        let inner = MemBackend::new();
+        let id = Uuid::new_v4();
+        inner.write_content(&id, Scope::Project, "Title").unwrap();
+        let guard = armed_over(inner);
+
+        guard.delete_content(&id, Scope::Project).unwrap();
+
+        assert!(guard.read_content(&id, Scope::Project).unwrap().is_none());
+        assert!(guard.list_content_ids(Scope::Project).unwrap().is_empty());
+        assert!(guard
+            .inner
+            .read_content(&id, Scope::Project)
+            .unwrap()
+            .is_some());
+    }
+}
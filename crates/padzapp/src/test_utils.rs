@@ -1,3 +1,5 @@
+use crate::cancel::Cancellation;
+use crate::progress::Progress;
 use crate::store::fs::FileStore;
 use std::path::PathBuf;
 use tempfile::TempDir;
@@ -27,3 +29,27 @@ impl TestEnv {
         }
     }
 }
+
+/// A progress reporter that stands in for a user hitting Ctrl-C: it cancels
+/// `cancel` once `after` items of any task have been reported done.
+pub struct CancelAfter {
+    pub after: usize,
+    pub cancel: Cancellation,
+}
+
+impl CancelAfter {
+    pub fn new(after: usize) -> Self {
+        Self {
+            after,
+            cancel: Cancellation::new(),
+        }
+    }
+}
+
+impl Progress for CancelAfter {
+    fn update(&self, _task: &str, done: usize, _total: usize) {
+        if done >= self.after {
+            self.cancel.cancel();
+        }
+    }
+}
@@ -0,0 +1,204 @@
+//! # Releases and Updates
+//!
+//! What `padz self-update` and the opt-in update check need to decide, without
+//! fetching anything: fetching is the application's job (the CLI asks GitHub
+//! with `curl`), and this module takes the bytes it got back.
+//!
+//! - [`is_newer`] compares release tags (`v1.9.0`) by their numbers.
+//! - [`verify_checksum`] checks a downloaded archive against the `.sha256` file
+//!   published beside it; an archive is never installed without one.
+//! - [`extract_binary`] takes the executable out of a release `.tar.gz`.
+//! - [`UpdateCheck`] remembers when the check last ran, in `update-check.json`
+//!   next to the global store, so it asks at most once a day ([`CHECK_EVERY_HOURS`]).
+
+use crate::error::{PadzError, Result};
+use chrono::{DateTime, Duration, Utc};
+use flate2::read::GzDecoder;
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use std::fs;
+use std::io::Read;
+use std::path::{Path, PathBuf};
+
+/// File name of the check record inside the global data directory.
+pub const UPDATE_CHECK_FILE: &str = "update-check.json";
+
+/// The least time, in hours, between two update checks.
+pub const CHECK_EVERY_HOURS: i64 = 24;
+
+/// The numbers of a release tag: `v1.9.0` or `1.9.0` is `[1, 9, 0]`.
+/// `None` for anything else, including pre-release suffixes.
+fn version_numbers(tag: &str) -> Option<Vec<u64>> {
+    let tag = tag.trim();
+    let tag = tag.strip_prefix('v').unwrap_or(tag);
+    tag.split('.').map(|part| part.parse().ok()).collect()
+}
+
+/// Whether release `latest` is newer than `current`. A tag that is not a plain
+/// version is never newer, so a malformed answer cannot trigger an update.
+pub fn is_newer(latest: &str, current: &str) -> bool {
+    match (version_numbers(latest), version_numbers(current)) {
+        (Some(latest), Some(current)) => latest > current,
+        _ => false,
+    }
+}
+
+/// Checks `archive` against a checksum file: the hex SHA-256, optionally
+/// followed by the file name (`sha256sum` output).
+pub fn verify_checksum(archive: &[u8], checksum_file: &str) -> Result<()> {
+    let expected = checksum_file
+        .split_whitespace()
+        .next()
+        .ok_or_else(|| PadzError::Api("The release checksum file is empty".into()))?
+        .to_ascii_lowercase();
+    let actual = format!("{:x}", Sha256::digest(archive));
+    if actual != expected {
+        return Err(PadzError::Api(format!(
+            "The downloaded release does not match its checksum (expected {}, got {})",
+            expected, actual
+        )));
+    }
+    Ok(())
+}
+
+/// The contents of the file named `bin_name` in a release `.tar.gz`, wherever
+/// in the archive it is (releases put it in a `<bin>-<target>/` directory).
+pub fn extract_binary(archive: &[u8], bin_name: &str) -> Result<Vec<u8>> {
+    let mut tar = tar::Archive::new(GzDecoder::new(archive));
+    for entry in tar.entries()? {
+        let mut entry = entry?;
+        let is_binary = entry.header().entry_type().is_file()
+            && entry
+                .path()?
+                .file_name()
+                .is_some_and(|name| name == bin_name);
+        if is_binary {
+            let mut bytes = Vec::new();
+            entry.read_to_end(&mut bytes)?;
+            return Ok(bytes);
+        }
+    }
+    Err(PadzError::Api(format!(
+        "The release archive has no '{}' binary",
+        bin_name
+    )))
+}
+
+/// What `padz self-update` did. Versions are plain numbers (`1.9.0`).
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(tag = "status", rename_all = "snake_case")]
+pub enum UpdateOutcome {
+    /// The running padz is the latest release.
+    UpToDate { current: String },
+    /// A newer release exists and was not installed (`--check`, or a dry run).
+    Available { current: String, latest: String },
+    /// The newer release replaced the binary at `path`.
+    Installed {
+        previous: String,
+        installed: String,
+        path: PathBuf,
+    },
+}
+
+/// The last update check: when it ran and the newest release it saw.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct UpdateCheck {
+    pub checked_at: DateTime<Utc>,
+    pub latest: Option<String>,
+}
+
+impl UpdateCheck {
+    /// Load the record; a missing or unreadable one means no check has run.
+    pub fn load(path: &Path) -> Option<Self> {
+        let content = fs::read_to_string(path).ok()?;
+        serde_json::from_str(&content).ok()
+    }
+
+    /// Write the record, creating its directory.
+    pub fn save(&self, path: &Path) -> Result<()> {
+        if let Some(dir) = path.parent() {
+            fs::create_dir_all(dir)?;
+        }
+        fs::write(path, serde_json::to_string_pretty(self)?)?;
+        Ok(())
+    }
+
+    /// Whether a check recorded as `last` leaves another one due at `now`.
+    pub fn due(last: Option<&Self>, now: DateTime<Utc>) -> bool {
+        match last {
+            None => true,
+            Some(last) => now - last.checked_at >= Duration::hours(CHECK_EVERY_HOURS),
+        }
+    }
+}
+
+/// Where the check record lives for a given global data directory.
+pub fn update_check_path(global_dir: &Path) -> PathBuf {
+    global_dir.join(UPDATE_CHECK_FILE)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use flate2::write::GzEncoder;
+    use flate2::Compression;
+    use tempfile::TempDir;
+
+    #[test]
+    fn only_a_higher_plain_version_is_newer() {
+        assert!(is_newer("v1.10.0", "1.9.3"));
+        assert!(is_newer("2.0.0", "v1.99.99"));
+        assert!(!is_newer("v1.9.0", "1.9.0"));
+        assert!(!is_newer("v1.8.9", "1.9.0"));
+        assert!(!is_newer("v2.0.0-rc1", "1.9.0"));
+        assert!(!is_newer("<html>", "1.9.0"));
+    }
+
+    #[test]
+    fn checksum_accepts_sha256sum_output_and_rejects_a_mismatch() {
+        let archive = b"release bytes";
+        let hex = format!("{:x}", Sha256::digest(archive));
+
+        verify_checksum(archive, &format!("{}  padz.tar.gz\n", hex)).unwrap();
+        verify_checksum(archive, &hex.to_uppercase()).unwrap();
+        assert!(verify_checksum(b"tampered", &hex).is_err());
+        assert!(verify_checksum(archive, "").is_err());
+    }
+
+    #[test]
+    fn extracts_the_binary_from_its_release_directory() {
+        let mut builder = tar::Builder::new(GzEncoder::new(Vec::new(), Compression::default()));
+        for (path, body) in [
+            ("padz-x86_64-linux-gnu/README.md", b"readme".as_slice()),
+            ("padz-x86_64-linux-gnu/padz", b"\x7fELF".as_slice()),
+        ] {
+            let mut header = tar::Header::new_gnu();
+            header.set_size(body.len() as u64);
+            header.set_mode(0o755);
+            header.set_cksum();
+            builder.append_data(&mut header, path, body).unwrap();
+        }
+        let archive = builder.into_inner().unwrap().finish().unwrap();
+
+        assert_eq!(extract_binary(&archive, "padz").unwrap(), b"\x7fELF");
+        assert!(extract_binary(&archive, "other").is_err());
+    }
+
+    #[test]
+    fn a_check_is_due_once_a_day() {
+        let temp = TempDir::new().unwrap();
+        let path = update_check_path(temp.path());
+        let now = Utc::now();
+        assert!(UpdateCheck::due(UpdateCheck::load(&path).as_ref(), now));
+
+        let check = UpdateCheck {
+            checked_at: now,
+            latest: Some("v1.9.0".into()),
+        };
+        check.save(&path).unwrap();
+        let last = UpdateCheck::load(&path);
+        assert_eq!(last.as_ref(), Some(&check));
+        assert!(!UpdateCheck::due(last.as_ref(), now + Duration::hours(23)));
+        assert!(UpdateCheck::due(last.as_ref(), now + Duration::hours(24)));
+    }
+}
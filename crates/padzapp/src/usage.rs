@@ -0,0 +1,208 @@
+//! # Local Usage Counts
+//!
+//! An opt-in tally of which commands a user runs, and which options they pass
+//! (by name, never their values), kept in `usage.json` next to the global
+//! store. It never leaves the machine: this module does no network I/O, only
+//! reads and writes that one file, and nothing else in padz sends it anywhere.
+//! Sharing it is something a user does by hand, if at all.
+//!
+//! Whether to record is the application's decision (the `usage_stats` config
+//! key); this module only knows how to count and report.
+
+use crate::error::{PadzError, Result};
+use chrono::{DateTime, Utc};
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+use uuid::Uuid;
+
+/// File name of the usage log inside the global data directory.
+pub const USAGE_FILE: &str = "usage.json";
+
+/// The persisted tally: invocation counts per command name, and per option.
+#[derive(Debug, Clone, Default, PartialEq, Eq, Serialize, Deserialize)]
+pub struct UsageLog {
+    /// When counting started (the first recorded invocation).
+    #[serde(default)]
+    pub since: Option<DateTime<Utc>>,
+    #[serde(default)]
+    pub commands: BTreeMap<String, u64>,
+    /// Options by long name (`--peek`), counted once per invocation that
+    /// passed them. Logs written before options were counted have none.
+    #[serde(default)]
+    pub options: BTreeMap<String, u64>,
+}
+
+impl UsageLog {
+    /// Load the log, treating a missing file as an empty log.
+    pub fn load(path: &Path) -> Result<Self> {
+        match fs::read_to_string(path) {
+            Ok(content) => Ok(serde_json::from_str(&content)?),
+            Err(e) if e.kind() == std::io::ErrorKind::NotFound => Ok(Self::default()),
+            Err(e) => Err(PadzError::Io(e)),
+        }
+    }
+
+    /// Write the log atomically (tmp file + rename), creating its directory.
+    pub fn save(&self, path: &Path) -> Result<()> {
+        let dir = path
+            .parent()
+            .ok_or_else(|| PadzError::Store(format!("invalid usage path {}", path.display())))?;
+        fs::create_dir_all(dir)?;
+        let tmp = dir.join(format!(".usage-{}.tmp", Uuid::new_v4()));
+        fs::write(&tmp, serde_json::to_string_pretty(self)?)?;
+        fs::rename(&tmp, path)?;
+        Ok(())
+    }
+
+    /// Count one invocation of `command`, passed `options`.
+    pub fn record(&mut self, command: &str, options: &[String]) {
+        self.since.get_or_insert_with(Utc::now);
+        *self.commands.entry(command.to_string()).or_insert(0) += 1;
+        for option in options {
+            *self.options.entry(option.clone()).or_insert(0) += 1;
+        }
+    }
+}
+
+/// One command's share of the tally.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct UsageCount {
+    pub command: String,
+    pub count: u64,
+}
+
+/// How many invocations passed one option.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct OptionCount {
+    pub option: String,
+    pub count: u64,
+}
+
+/// What `stats --usage` reports.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(tag = "status", rename_all = "snake_case")]
+pub enum UsageReport {
+    /// Counting is off; nothing is being recorded.
+    Disabled,
+    /// Counting is on but nothing has been recorded yet.
+    Empty,
+    /// Counts, most-used first (ties broken by name).
+    Recorded {
+        since: Option<DateTime<Utc>>,
+        total: u64,
+        commands: Vec<UsageCount>,
+        options: Vec<OptionCount>,
+    },
+}
+
+impl UsageReport {
+    pub fn from_log(log: UsageLog) -> Self {
+        if log.commands.is_empty() {
+            return Self::Empty;
+        }
+        let total = log.commands.values().sum();
+        let mut commands: Vec<UsageCount> = log
+            .commands
+            .into_iter()
+            .map(|(command, count)| UsageCount { command, count })
+            .collect();
+        // BTreeMap iteration is by name, so a stable sort keeps ties alphabetical.
+        commands.sort_by(|a, b| b.count.cmp(&a.count));
+        let mut options: Vec<OptionCount> = log
+            .options
+            .into_iter()
+            .map(|(option, count)| OptionCount { option, count })
+            .collect();
+        options.sort_by(|a, b| b.count.cmp(&a.count));
+        Self::Recorded {
+            since: log.since,
+            total,
+            commands,
+            options,
+        }
+    }
+}
+
+/// Where the usage log lives for a given global data directory.
+pub fn usage_path(global_dir: &Path) -> PathBuf {
+    global_dir.join(USAGE_FILE)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use tempfile::TempDir;
+
+    #[test]
+    fn missing_file_loads_as_empty() {
+        let temp = TempDir::new().unwrap();
+        let log = UsageLog::load(&usage_path(temp.path())).unwrap();
+        assert_eq!(log, UsageLog::default());
+    }
+
+    #[test]
+    fn record_and_save_round_trip() {
+        let temp = TempDir::new().unwrap();
+        let path = usage_path(&temp.path().join("nested"));
+
+        let mut log = UsageLog::load(&path).unwrap();
+        log.record("list", &["--peek".to_string()]);
+        log.record("list", &[]);
+        log.record("view", &["--peek".to_string(), "--output".to_string()]);
+        log.save(&path).unwrap();
+
+        let loaded = UsageLog::load(&path).unwrap();
+        assert_eq!(loaded.commands.get("list"), Some(&2));
+        assert_eq!(loaded.commands.get("view"), Some(&1));
+        assert_eq!(loaded.options.get("--peek"), Some(&2));
+        assert_eq!(loaded.options.get("--output"), Some(&1));
+        assert!(loaded.since.is_some());
+    }
+
+    #[test]
+    fn report_orders_by_count_then_name() {
+        let mut log = UsageLog::default();
+        for cmd in ["view", "list", "list", "create"] {
+            log.record(cmd, &[]);
+        }
+        log.record("list", &["--peek".to_string()]);
+        log.record("list", &["--peek".to_string(), "--all".to_string()]);
+
+        let UsageReport::Recorded {
+            total,
+            commands,
+            options,
+            ..
+        } = UsageReport::from_log(log)
+        else {
+            panic!("expected recorded counts");
+        };
+
+        assert_eq!(total, 6);
+        let order: Vec<&str> = commands.iter().map(|c| c.command.as_str()).collect();
+        assert_eq!(order, vec!["list", "create", "view"]);
+        let order: Vec<&str> = options.iter().map(|o| o.option.as_str()).collect();
+        assert_eq!(order, vec!["--peek", "--all"]);
+    }
+
+    #[test]
+    fn a_log_from_before_options_were_counted_still_loads() {
+        let temp = TempDir::new().unwrap();
+        let path = usage_path(temp.path());
+        fs::write(&path, r#"{"since":null,"commands":{"list":3}}"#).unwrap();
+
+        let log = UsageLog::load(&path).unwrap();
+        assert_eq!(log.commands.get("list"), Some(&3));
+        assert!(log.options.is_empty());
+    }
+
+    #[test]
+    fn empty_log_reports_empty() {
+        assert_eq!(
+            UsageReport::from_log(UsageLog::default()),
+            UsageReport::Empty
+        );
+    }
+}
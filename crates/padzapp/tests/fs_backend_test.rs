@@ -384,3 +384,33 @@ fn test_fs_backend_tags_scope_isolation() {
     assert_eq!(loaded_global.len(), 1);
     assert_eq!(loaded_global[0].name, "global-tag");
 }
+
+#[test]
+fn test_fs_backend_corrupt_index_is_a_serialization_error() {
+    let (proj, _glob, backend) = setup();
+    fs::write(proj.path().join("data.json"), "{\"not\": ").unwrap();
+
+    let err = backend.load_index(Scope::Project).unwrap_err();
+    assert!(matches!(err, padzapp::error::PadzError::Serialization(_)));
+}
+
+#[test]
+fn test_fs_backend_large_index_round_trips() {
+    let (proj, _glob, backend) = setup();
+    let index: HashMap<Uuid, Metadata> = (0..2_000)
+        .map(|n| Metadata::new(format!("Pad {}", n)))
+        .map(|meta| (meta.id, meta))
+        .collect();
+
+    backend.save_index(Scope::Project, &index).unwrap();
+
+    assert_eq!(backend.load_index(Scope::Project).unwrap().len(), 2_000);
+    let leftovers = fs::read_dir(proj.path())
+        .unwrap()
+        .filter(|entry| {
+            let name = entry.as_ref().unwrap().file_name();
+            name.to_string_lossy().ends_with(".tmp")
+        })
+        .count();
+    assert_eq!(leftovers, 0);
+}
@@ -0,0 +1,202 @@
+//! Generated operation sequences against both store backends.
+//!
+//! The in-memory store the unit tests use and the filesystem store the CLI uses
+//! share `BucketedStore` but not their backends, and nothing else checks that
+//! they agree. Each test here drives a fixed-seed sequence of create, update,
+//! delete and restore through the public API, selecting pads by the indexes the
+//! previous listing showed, and after every step compares the active and deleted
+//! listings with a plain model of what they should contain:
+//!
+//! - the same titles, in the same buckets, as the model;
+//! - indexes numbered `1..=n` and `d1..=dn` with no gaps;
+//! - the same index naming the same pad when the listing is read again (and, for
+//!   the filesystem, when a fresh store is opened on the same directories).
+
+use padzapp::api::{PadFilter, PadStatusFilter, PadzApi, PadzPaths};
+use padzapp::index::DisplayIndex;
+use padzapp::model::Scope;
+use padzapp::store::bucketed::BucketedStore;
+use padzapp::store::fs::FileStore;
+use padzapp::store::mem_backend::MemBackend;
+use padzapp::store::DataStore;
+use std::path::PathBuf;
+use tempfile::TempDir;
+
+const STEPS: usize = 200;
+
+/// One listing as the user sees it: index, title, content.
+type Listing = Vec<(DisplayIndex, String, String)>;
+
+/// What the listings should hold, by title.
+#[derive(Default)]
+struct Model {
+    active: Vec<String>,
+    deleted: Vec<String>,
+}
+
+fn listing<S: DataStore>(api: &PadzApi<S>, status: PadStatusFilter) -> Listing {
+    let filter = PadFilter {
+        status,
+        ..PadFilter::default()
+    };
+    api.get_pads(Scope::Project, filter, &[] as &[String])
+        .unwrap()
+        .listed_pads
+        .into_iter()
+        .map(|dp| (dp.index, dp.pad.metadata.title, dp.pad.content))
+        .collect()
+}
+
+fn sorted_titles(listing: &Listing) -> Vec<String> {
+    let mut titles: Vec<String> = listing.iter().map(|(_, t, _)| t.clone()).collect();
+    titles.sort();
+    titles
+}
+
+fn sorted(titles: &[String]) -> Vec<String> {
+    let mut titles = titles.to_vec();
+    titles.sort();
+    titles
+}
+
+/// Checks both listings against the model and returns them.
+fn observe<S: DataStore>(api: &PadzApi<S>, model: &Model, step: usize) -> (Listing, Listing) {
+    let active = listing(api, PadStatusFilter::Active);
+    let deleted = listing(api, PadStatusFilter::Deleted);
+
+    assert_eq!(
+        sorted_titles(&active),
+        sorted(&model.active),
+        "active, step {step}"
+    );
+    assert_eq!(
+        sorted_titles(&deleted),
+        sorted(&model.deleted),
+        "deleted, step {step}"
+    );
+
+    let expected: Vec<DisplayIndex> = (1..=active.len()).map(DisplayIndex::Regular).collect();
+    let actual: Vec<DisplayIndex> = active.iter().map(|(i, _, _)| i.clone()).collect();
+    assert_eq!(actual, expected, "active indexes, step {step}");
+    let expected: Vec<DisplayIndex> = (1..=deleted.len()).map(DisplayIndex::Deleted).collect();
+    let actual: Vec<DisplayIndex> = deleted.iter().map(|(i, _, _)| i.clone()).collect();
+    assert_eq!(actual, expected, "deleted indexes, step {step}");
+
+    assert_eq!(
+        listing(api, PadStatusFilter::Active),
+        active,
+        "re-read, step {step}"
+    );
+    assert_eq!(
+        listing(api, PadStatusFilter::Deleted),
+        deleted,
+        "re-read, step {step}"
+    );
+    (active, deleted)
+}
+
+/// Runs [`STEPS`] generated operations, checking the listings after each.
+fn run_sequence<S: DataStore>(api: &mut PadzApi<S>, seed: u64) {
+    let mut state = seed;
+    let mut next = move || {
+        state ^= state << 13;
+        state ^= state >> 7;
+        state ^= state << 17;
+        state as usize
+    };
+    let mut model = Model::default();
+    let mut created = 0;
+
+    for step in 0..STEPS {
+        let (active, deleted) = observe(api, &model, step);
+        match next() % 4 {
+            0 => {
+                created += 1;
+                let title = format!("pad {created}");
+                api.create_pad(
+                    Scope::Project,
+                    title.clone(),
+                    format!("body {created}"),
+                    None,
+                )
+                .unwrap();
+                model.active.push(title);
+            }
+            1 if !active.is_empty() => {
+                let (index, title, _) = &active[next() % active.len()];
+                let renamed = format!("{title} edited");
+                api.update_pads_from_content(
+                    Scope::Project,
+                    &[index.to_string()],
+                    &format!("{renamed}\n\nedited at step {step}"),
+                )
+                .unwrap();
+                let slot = model.active.iter().position(|t| t == title).unwrap();
+                model.active[slot] = renamed;
+            }
+            2 if !active.is_empty() => {
+                let (index, title, _) = &active[next() % active.len()];
+                api.delete_pads(Scope::Project, &[index.to_string()])
+                    .unwrap();
+                let slot = model.active.iter().position(|t| t == title).unwrap();
+                model.deleted.push(model.active.remove(slot));
+            }
+            3 if !deleted.is_empty() => {
+                let (index, title, _) = &deleted[next() % deleted.len()];
+                api.restore_pads(Scope::Project, &[index.to_string()])
+                    .unwrap();
+                let slot = model.deleted.iter().position(|t| t == title).unwrap();
+                model.active.push(model.deleted.remove(slot));
+            }
+            _ => {}
+        }
+    }
+    observe(api, &model, STEPS);
+}
+
+fn paths(project: PathBuf, global: PathBuf) -> PadzPaths {
+    PadzPaths {
+        project: Some(project),
+        global,
+        home: None,
+    }
+}
+
+#[test]
+fn memory_store_listings_follow_generated_operations() {
+    for seed in [0x9e37_79b9_7f4a_7c15, 0x2545_f491_4f6c_dd1d, 0xdead_beef] {
+        let store = BucketedStore::new(
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+            MemBackend::new(),
+        );
+        let mut api = PadzApi::new(store, paths(".padz".into(), ".padz".into()));
+        run_sequence(&mut api, seed);
+    }
+}
+
+#[test]
+fn file_store_listings_follow_generated_operations_and_survive_reopening() {
+    for seed in [0x9e37_79b9_7f4a_7c15, 0x2545_f491_4f6c_dd1d, 0xdead_beef] {
+        let temp = TempDir::new().unwrap();
+        let project = temp.path().join("project").join(".padz");
+        let global = temp.path().join("global");
+        let open = || {
+            let store = FileStore::new_fs(Some(project.clone()), global.clone());
+            PadzApi::new(store, paths(project.clone(), global.clone()))
+        };
+
+        let mut api = open();
+        run_sequence(&mut api, seed);
+
+        let reopened = open();
+        for status in [PadStatusFilter::Active, PadStatusFilter::Deleted] {
+            assert_eq!(
+                listing(&reopened, status),
+                listing(&api, status),
+                "a fresh store on the same directories lists differently (seed {seed:#x})"
+            );
+        }
+    }
+}
@@ -137,12 +137,12 @@ main() {
 	info "installed ${bin_dir}/${BIN_NAME}"
 
 	# Install shell completions if the binary supports it. padz's CLI is
-	# `<bin> completion install`; for other projects this may need tweaking
+	# `<bin> completion --install`; for other projects this may need tweaking
 	# or can simply be removed.
-	if "${bin_dir}/${BIN_NAME}" completion install 2>/dev/null; then
+	if "${bin_dir}/${BIN_NAME}" completion --install 2>/dev/null; then
 		: # success message already printed by padz
 	else
-		warn "could not auto-install shell completions; run \`${BIN_NAME} completion install\` manually if desired"
+		warn "could not auto-install shell completions; run \`${BIN_NAME} completion --install\` manually if desired"
 	fi
 
 	# PATH hint if the install dir isn't already on $PATH.